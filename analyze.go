@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// transferCounter type.
+// This will count the matched + literal blocks of a Delta as they are generated, without storing the Delta.
+// transferCounter will satisfy the `sync.DeltaWriter` interface.
+type transferCounter struct {
+	matchedBlocks int
+	literalBlocks int
+	matchedBytes  int64
+	literalBytes  int64
+}
+
+// WriteBlock() will add a Delta block to the matched or literal counts.
+func (c *transferCounter) WriteBlock(position int64, block models.Block) error {
+	if block.IsModified {
+		c.literalBlocks++
+		c.literalBytes += int64(len(block.Value))
+	} else {
+		c.matchedBlocks++
+		c.matchedBytes += block.Tail - block.Head + 1
+	}
+
+	return nil
+}
+
+// formatTransfer() will format the counted blocks as a human readable report of the bytes which would be transferred.
+// EG: `Would transfer 10 of 100 bytes (10.00%)`.
+func formatTransfer(counter transferCounter) []string {
+	total := counter.matchedBytes + counter.literalBytes
+	percentage := 0.0
+	if total > 0 {
+		percentage = float64(counter.literalBytes) / float64(total) * 100
+	}
+
+	return []string{
+		fmt.Sprintf("Literal: %d bytes (%d blocks)", counter.literalBytes, counter.literalBlocks),
+		fmt.Sprintf("Matched: %d bytes (%d blocks)", counter.matchedBytes, counter.matchedBlocks),
+		fmt.Sprintf("Would transfer %d of %d bytes (%.2f%%)", counter.literalBytes, total, percentage),
+	}
+}
+
+// runAnalysis() will report how many bytes of the Updated file would need to be transferred to sync with the Original file (described by the Signature file).
+// Delta will be generated block by block without being stored or written to file.
+// Function returns `counter, nil` when successful (including when Updated file has no changes).
+// Function returns `emptyCounter, error` when unable to open the Signature or Updated file (EG ChunkSizeMismatchError, see openDeltaSignature()).
+// Function returns `emptyCounter, UnableToGenerateDeltaError` when unable to generate Delta.
+func runAnalysis(cmd models.CMD) (transferCounter, error) {
+	signature, err := openDeltaSignature(cmd)
+	if err != nil {
+		return transferCounter{}, err
+	}
+
+	reader, err := openUpdated(cmd.UpdatedFile)
+	if err != nil {
+		return transferCounter{}, err
+	}
+
+	counter := transferCounter{}
+	err = generateDeltaTo(reader, signature, &counter, sync.Hooks{}, cmd.Verbose)
+	if err != nil && err.Error() != constants.UpdatedFileHasNoChangesError {
+		return transferCounter{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	for _, line := range formatTransfer(counter) {
+		logger(utils.Stat(line), true)
+	}
+
+	return counter, nil
+}
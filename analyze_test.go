@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatTransfer(t *testing.T) {
+	t.Run("should return literal + matched counts and percentage transferred", func(t *testing.T) {
+		// Setup
+		counter := transferCounter{matchedBlocks: 2, literalBlocks: 1, matchedBytes: 75, literalBytes: 25}
+		expectedLines := []string{
+			"Literal: 25 bytes (1 blocks)",
+			"Matched: 75 bytes (2 blocks)",
+			"Would transfer 25 of 100 bytes (25.00%)",
+		}
+
+		// Run
+		lines := formatTransfer(counter)
+		// Verify
+		require.Equal(t, expectedLines, lines)
+	})
+
+	t.Run("should return zero percentage when Updated file is empty", func(t *testing.T) {
+		// Run
+		lines := formatTransfer(transferCounter{})
+		// Verify
+		require.Equal(t, "Would transfer 0 of 0 bytes (0.00%)", lines[2])
+	})
+}
+
+func TestRunAnalysis(t *testing.T) {
+	t.Run("should return counted blocks without storing Delta", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{AnalyzeMode: true, SignatureFile: file, UpdatedFile: file}
+		expectedCounter := transferCounter{matchedBlocks: 1, literalBlocks: 1, matchedBytes: 16, literalBytes: 3}
+		// Mock
+		logger = func(message string, verbose bool) {}
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return testSignature, models.SignatureMetadata{}, nil
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			require.Equal(t, nil, writer.WriteBlock(0, models.Block{IsModified: true, Value: []byte("new")}))
+			return writer.WriteBlock(3, models.Block{Head: 0, Tail: 15})
+		}
+
+		// Run
+		counter, err := runAnalysis(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedCounter, counter)
+	})
+
+	t.Run("should return `counter, nil` when Updated file has no changes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{AnalyzeMode: true, SignatureFile: file, UpdatedFile: file}
+		expectedCounter := transferCounter{matchedBlocks: 1, matchedBytes: 16}
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			require.Equal(t, nil, writer.WriteBlock(0, models.Block{Head: 0, Tail: 15}))
+			return errors.New(constants.UpdatedFileHasNoChangesError)
+		}
+
+		// Run
+		counter, err := runAnalysis(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedCounter, counter)
+	})
+
+	t.Run("should return `UnableToGenerateDeltaError` when Delta generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{AnalyzeMode: true, SignatureFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		counter, err := runAnalysis(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+
+	t.Run("should return error when unable to open Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{AnalyzeMode: true, SignatureFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.SignatureFileDoesNotExistError)
+		// Mock
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return models.Signature{}, models.SignatureMetadata{}, expectedError
+		}
+
+		// Run
+		counter, err := runAnalysis(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+
+	t.Run("should return `ChunkSizeMismatchError` when `-chunk` does not match the Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{AnalyzeMode: true, SignatureFile: file, UpdatedFile: file, Chunk: 64}
+		expectedError := errors.New(constants.ChunkSizeMismatchError)
+		// Mock
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return testSignature, models.SignatureMetadata{ChunkSize: 4096}, nil
+		}
+
+		// Run
+		counter, err := runAnalysis(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+}
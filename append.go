@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+var detectAppend = sync.AppendDelta
+var detectTruncate = sync.TruncateDelta
+
+// prefixDelta() will check whether the Updated file is the Original file with data appended or truncated, so the rolling scan can be skipped (see sync.AppendDelta() + sync.TruncateDelta()).
+// Check will be skipped when `-range` or `-minSimilarity` is set, as the Delta would not honour either flag (or `-capture`, as the Updated file would not be recorded).
+// Function returns `delta, true, nil` when Updated file only appends data to, or truncates, the Original file.
+// Function returns `emptyDelta, false, nil` when Updated file changes the Original file (or unable to check), so a Delta should be generated as normal.
+// Function returns `emptyDelta, true, UpdatedFileHasNoChangesError` when Updated file matches the Original file.
+func prefixDelta(cmd models.CMD, signature models.Signature) (models.Delta, bool, error) {
+	if cmd.Range != "" || cmd.MinSimilarity > 0 || cmd.Capture != "" {
+		return models.Delta{}, false, nil
+	}
+
+	size, err := fileSize(cmd.UpdatedFile)
+	if err != nil {
+		return models.Delta{}, false, nil
+	}
+
+	file, err := openFileAt(cmd.UpdatedFile)
+	if err != nil {
+		return models.Delta{}, false, nil
+	}
+
+	defer file.Close()
+	if delta, truncated := detectTruncate(file, size, signature); truncated {
+		logger(utils.Success(fmt.Sprintf("Updated file truncates Original file to %d bytes, skipped rolling scan", size)), cmd.Verbose)
+		return delta, true, nil
+	}
+
+	delta, appended := detectAppend(file, size, signature)
+	if !appended {
+		return models.Delta{}, false, nil
+	}
+
+	// A single matched block (EG nothing appended) will contain no changes
+	if len(delta) == 1 {
+		return models.Delta{}, true, errors.New(constants.UpdatedFileHasNoChangesError)
+	}
+
+	_, literal := deltaStats(delta)
+	logger(utils.Success(fmt.Sprintf("Updated file appends %d bytes to Original file, skipped rolling scan", literal)), cmd.Verbose)
+	return delta, true, nil
+}
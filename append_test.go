@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixDelta(t *testing.T) {
+	signature := models.Signature{1: models.StrongSignature{Hash: "a", Head: 0, Tail: 15}}
+	appended := models.Delta{
+		0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+		16: models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("tail")},
+	}
+
+	t.Run("should return `delta, true, nil` when Updated file only appends to Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 20, nil
+		}
+
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return readerAtCloserMock{bytes.NewReader(make([]byte, 20))}, nil
+		}
+
+		detectAppend = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return appended, true
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, found)
+		require.Equal(t, appended, delta)
+	})
+
+	t.Run("should return `delta, true, nil` when Updated file truncates Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		truncated := models.Delta{0: models.Block{Head: 0, Tail: 9, IsModified: false, Value: []byte{}}}
+		// Mock
+		detectTruncate = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return truncated, true
+		}
+
+		detectAppend = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return models.Delta{}, false
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, found)
+		require.Equal(t, truncated, delta)
+		// Restore, so later cases check for appended data
+		detectTruncate = sync.TruncateDelta
+	})
+
+	t.Run("should return `emptyDelta, true, UpdatedFileHasNoChangesError` when Updated file matches Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		detectAppend = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return models.Delta{0: appended[0]}, true
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, true, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false, nil` when Updated file changes Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		// Mock
+		detectAppend = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return models.Delta{}, false
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false, nil` when range or min similarity set", func(t *testing.T) {
+		// Mock
+		detectAppend = func(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+			return appended, true
+		}
+
+		for _, cmd := range []models.CMD{{UpdatedFile: file, Range: "0:4"}, {UpdatedFile: file, MinSimilarity: 50}} {
+			// Run
+			delta, found, err := prefixDelta(cmd, signature)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, false, found)
+			require.Equal(t, models.Delta{}, delta)
+		}
+	})
+
+	t.Run("should return `emptyDelta, false, nil` when unable to open Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false, nil` when unable to get size of Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		// Run
+		delta, found, err := prefixDelta(cmd, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	// Restore, so later tests generate Deltas from real files
+	detectAppend = sync.AppendDelta
+	detectTruncate = sync.TruncateDelta
+	openFileAt = openReaderAt
+	fileSize = files.FileSize
+}
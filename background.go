@@ -0,0 +1,26 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const backgroundMaxProcs int = 1 // Limit background runs to a single CPU
+
+var (
+	lowerPriority = setLowPriority
+	setMaxProcs   = runtime.GOMAXPROCS
+)
+
+// enterBackground() will lower the CPU + I/O scheduling priority of the process, and throttle Go to a single CPU.
+// This allows scheduled jobs (EG nightly Delta generation) to run without impacting interactive workloads.
+// Note: a warning will be logged (and the run will continue) when unable to lower priority.
+// Note: must be called before worker goroutines start, as Linux lowers priority per thread (see setLowPriority()).
+func enterBackground() {
+	setMaxProcs(backgroundMaxProcs)
+	if err := lowerPriority(); err != nil {
+		errorLogger(utils.Warning(constants.UnableToLowerPriorityError))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnterBackground(t *testing.T) {
+	t.Run("should lower priority + throttle to a single CPU", func(t *testing.T) {
+		// Setup
+		procs := 0
+		lowered := false
+		logged := false
+		// Mock
+		setMaxProcs = func(n int) int {
+			procs = n
+			return 8
+		}
+
+		lowerPriority = func() error {
+			lowered = true
+			return nil
+		}
+
+		errorLogger = func(message string) {
+			logged = true
+		}
+
+		// Run
+		enterBackground()
+		// Verify
+		require.Equal(t, 1, procs)
+		require.Equal(t, true, lowered)
+		require.Equal(t, false, logged)
+	})
+
+	t.Run("should log warning and continue when unable to lower priority", func(t *testing.T) {
+		// Setup
+		logged := ""
+		// Mock
+		setMaxProcs = func(n int) int {
+			return 8
+		}
+
+		lowerPriority = func() error {
+			return errors.New(errorMessage)
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		// Run
+		enterBackground()
+		// Verify
+		require.Equal(t, utils.Warning(constants.UnableToLowerPriorityError), logged)
+	})
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const (
+	batchStdin          string = "-"      // Read file pairs from stdin
+	batchDeltaExtension string = ".delta" // Appended to the Updated file path to name each Delta
+)
+
+var (
+	stdin       io.Reader  = os.Stdin
+	readFile               = os.ReadFile
+	statFile               = os.Stat
+	confirmLock sync.Mutex // Prevents Batch workers prompting to overwrite Deltas at the same time
+)
+
+// batchResult type.
+// This will contain the result of a file pair processed by a Batch worker, or the reason the pair was skipped.
+type batchResult struct {
+	deltaName string
+	reason    string
+	err       error
+}
+
+// batchDeltaName() will return the name (within Outputs folder) of the Delta generated for an Updated file in Batch mode.
+// The Updated file path will be mirrored within Outputs folder, so pairs from different folders do not collide.
+// EG: `../releases/v2/app.bin` -> `releases/v2/app.bin.delta`.
+// Note: leading `/`, `..` + volume names will be removed so Deltas are always written within Outputs folder.
+func batchDeltaName(updatedFile string) string {
+	path := strings.TrimPrefix(updatedFile, filepath.VolumeName(updatedFile))
+	path = filepath.Clean(string(filepath.Separator) + path)
+	return strings.TrimPrefix(path, string(filepath.Separator)) + batchDeltaExtension
+}
+
+// batchWorkers() will return the number of file pairs processed in parallel in Batch mode, bounded by the number of pairs.
+// Note: 0 will use a worker per CPU core.
+// Note: a single worker will be used when `-capture` is set, so the Original + Updated streams of each pair are recorded in list order (see replayStreams()).
+func batchWorkers(cmd models.CMD, pairs int) int {
+	workers := cmd.BatchWorkers
+	if cmd.Capture != "" {
+		workers = 1
+	} else if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+
+	if workers > pairs {
+		return pairs
+	}
+
+	return workers
+}
+
+// batchPair() will generate a Signature of the Original file + a Delta of the Updated file, and write the Delta to Outputs folder.
+// Function returns `deltaName, nil` when successful.
+// Function returns `deltaName, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file (no Delta written).
+// Function returns `deltaName, error` when unable to generate Signature or Delta, or unable to write Delta file (see writeDelta()).
+func batchPair(cmd models.CMD, originalFile string, updatedFile string) (string, error) {
+	deltaName := batchDeltaName(updatedFile)
+	confirmLock.Lock()
+	err := confirmOverwrite(cmd, deltaName)
+	confirmLock.Unlock()
+	if err != nil {
+		return deltaName, err
+	}
+
+	reader, err := openOriginal(originalFile)
+	if err != nil {
+		return deltaName, err
+	}
+
+	signature, err := generateSignature(reader, cmd.Verbose)
+	if err != nil {
+		return deltaName, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	reader, err = openUpdated(updatedFile)
+	if err != nil {
+		return deltaName, err
+	}
+
+	delta, err := generateDelta(reader, signature, cmd.Verbose)
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return deltaName, err
+		}
+
+		return deltaName, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	path := outputPath(deltaName)
+	if err := makeDir(filepath.Dir(path), files.DirMode()); err != nil {
+		return deltaName, errors.New(constants.UnableToCreateDeltaFileError)
+	}
+
+	// Write Delta in the same way as a single Delta file (EG `-encoding`, `-format`, `-compress` + Updated file hash)
+	pairCmd := cmd
+	pairCmd.DeltaFile = deltaName
+	pairCmd.UpdatedFile = updatedFile
+	_, err = writeDelta(pairCmd, delta)
+	return deltaName, err
+}
+
+// parseFilePairs() will split a list of Original + Updated file pairs (EG `original-1 updated-1 original-2 updated-2 ...`).
+// Entries will be NUL delimited when the list contains a NUL byte (EG `find -print0`), otherwise newline delimited.
+// Note: empty entries (EG trailing delimiters + blank lines) will be skipped.
+// Function returns `pairs, nil` when successful.
+// Function returns `nil, BatchPairIncompleteError` when an Original file has no matching Updated file.
+func parseFilePairs(list []byte) ([][2]string, error) {
+	delimiter := "\n"
+	if bytes.IndexByte(list, 0) >= 0 {
+		delimiter = "\x00"
+	}
+
+	entries := []string{}
+	for _, entry := range strings.Split(string(list), delimiter) {
+		if delimiter == "\n" {
+			entry = strings.TrimSuffix(entry, "\r")
+		}
+
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries)%2 != 0 {
+		return nil, errors.New(constants.BatchPairIncompleteError)
+	}
+
+	pairs := make([][2]string, 0, len(entries)/2)
+	for index := 0; index < len(entries); index += 2 {
+		pairs = append(pairs, [2]string{entries[index], entries[index+1]})
+	}
+
+	return pairs, nil
+}
+
+// readFilePairs() will read the list of Original + Updated file pairs from stdin (when `-`), or from the provided file.
+// Function returns `pairs, nil` when successful.
+// Function returns `nil, UnableToReadFilesFromError` when unable to read the list.
+// Function returns `nil, BatchPairIncompleteError` when an Original file has no matching Updated file.
+func readFilePairs(filesFrom string) ([][2]string, error) {
+	var list []byte
+	var err error
+	if filesFrom == batchStdin {
+		list, err = readAll(stdin)
+	} else {
+		list, err = readFile(filesFrom)
+	}
+
+	if err != nil {
+		return nil, errors.New(constants.UnableToReadFilesFromError)
+	}
+
+	return parseFilePairs(list)
+}
+
+// skipReason() will check the Updated file of a file pair against the `-minSize`, `-maxSize` + `-newerThan` filters.
+// Note: a pair will not be skipped when unable to get info of the Updated file, so the pair reports the error instead.
+// Function returns `""` when Updated file passes every filter (or no filters are set).
+// Function returns `reason` describing the first filter Updated file fails (EG `larger than -maxSize (1048576 bytes)`).
+func skipReason(cmd models.CMD, updatedFile string) string {
+	if cmd.MinSize == 0 && cmd.MaxSize == 0 && cmd.NewerThan == "" {
+		return ""
+	}
+
+	info, err := statFile(updatedFile)
+	if err != nil {
+		return ""
+	}
+
+	if cmd.MinSize > 0 && info.Size() < cmd.MinSize {
+		return fmt.Sprintf("smaller than -minSize (%d bytes)", cmd.MinSize)
+	}
+
+	if cmd.MaxSize > 0 && info.Size() > cmd.MaxSize {
+		return fmt.Sprintf("larger than -maxSize (%d bytes)", cmd.MaxSize)
+	}
+
+	// Duration has already been validated by cmd.VerifyCMD()
+	if duration, err := time.ParseDuration(cmd.NewerThan); err == nil && info.ModTime().Before(now().Add(-duration)) {
+		return fmt.Sprintf("not modified within -newerThan (%s)", cmd.NewerThan)
+	}
+
+	return ""
+}
+
+// runBatch() will generate a Delta for each Original + Updated file pair listed by `-filesFrom`, logging a result line per pair.
+// Each Delta will be written to Outputs folder (see batchDeltaName()), and pairs with no changes will be skipped.
+// Pairs whose Updated file fails the `-minSize`, `-maxSize` or `-newerThan` filters will be skipped, and listed in the summary.
+// Pairs will be processed in parallel by `-batchWorkers` workers (see batchWorkers()), with result lines logged in list order as pairs complete.
+// Note: a failed pair will not stop the remaining pairs from being processed.
+// Function returns `nil` when all pairs succeed (including pairs with no changes).
+// Function returns `BatchFailedError` when any pair fails.
+// Function returns `error` when unable to read the list of file pairs.
+func runBatch(cmd models.CMD, summary *models.Summary) error {
+	pairs, err := readFilePairs(cmd.FilesFrom)
+	if err != nil {
+		return err
+	}
+
+	// Each pair returns its result on its own channel, so results can be logged in list order
+	results := make([]chan batchResult, len(pairs))
+	for index := range pairs {
+		results[index] = make(chan batchResult, 1)
+	}
+
+	jobs := make(chan int)
+	for worker := 0; worker < batchWorkers(cmd, len(pairs)); worker++ {
+		go func() {
+			for index := range jobs {
+				deltaName, err := batchPair(cmd, pairs[index][0], pairs[index][1])
+				results[index] <- batchResult{deltaName: deltaName, err: err}
+			}
+		}()
+	}
+
+	// Pass pairs to workers on a separate goroutine, so results can be logged while later pairs are processed
+	go func() {
+		defer close(jobs)
+		for index, pair := range pairs {
+			if reason := skipReason(cmd, pair[1]); reason != "" {
+				results[index] <- batchResult{reason: reason}
+				continue
+			}
+
+			jobs <- index
+		}
+	}()
+
+	failed := 0
+	for index, pair := range pairs {
+		result := <-results[index]
+		if result.reason != "" {
+			summary.Skipped = append(summary.Skipped, models.SkippedFile{Path: pair[1], Reason: result.reason})
+			logger(utils.Warning(fmt.Sprintf("%s -> %s: skipped, %s", pair[0], pair[1], result.reason)), true)
+			continue
+		}
+
+		switch {
+		case result.err == nil:
+			logger(utils.Success(fmt.Sprintf("%s -> %s: Delta written to %s", pair[0], pair[1], outputPath(result.deltaName))), true)
+		case result.err.Error() == constants.UpdatedFileHasNoChangesError:
+			logger(utils.Success(fmt.Sprintf("%s -> %s: no changes", pair[0], pair[1])), true)
+		default:
+			failed++
+			errorLogger(utils.Failure(fmt.Sprintf("%s -> %s: %s", pair[0], pair[1], result.err.Error())))
+		}
+	}
+
+	logger(utils.Stat(fmt.Sprintf("Batch: %d pairs, %d failed, %d skipped", len(pairs), failed, len(summary.Skipped))), true)
+	if failed > 0 {
+		return errors.New(constants.BatchFailedError)
+	}
+
+	return nil
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for os.FileInfo interface
+type fileInfoMock struct {
+	// Include FileInfo props to fulfill interface
+	os.FileInfo
+	// Set test props
+	size    int64
+	modTime time.Time
+}
+
+// Overwrite fileInfoMock.Size() to consider test prop
+func (m fileInfoMock) Size() int64 { return m.size }
+
+// Overwrite fileInfoMock.ModTime() to consider test prop
+func (m fileInfoMock) ModTime() time.Time { return m.modTime }
+
+func TestBatchDeltaName(t *testing.T) {
+	t.Run("should mirror relative Updated file path", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, filepath.Join("releases", "v2", "app.bin.delta"), batchDeltaName(filepath.Join("releases", "v2", "app.bin")))
+	})
+
+	t.Run("should keep Delta within Outputs folder when Updated file path is absolute or leaves current folder", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, filepath.Join("srv", "app.bin.delta"), batchDeltaName(string(filepath.Separator)+filepath.Join("srv", "app.bin")))
+		require.Equal(t, filepath.Join("v2", "app.bin.delta"), batchDeltaName(filepath.Join("..", "..", "v2", "app.bin")))
+	})
+}
+
+func TestBatchWorkers(t *testing.T) {
+	t.Run("should return workers bounded by the number of pairs, using a worker per CPU core when 0", func(t *testing.T) {
+		// Verify
+		require.Equal(t, 4, batchWorkers(models.CMD{BatchWorkers: 4}, 10))
+		require.Equal(t, 2, batchWorkers(models.CMD{BatchWorkers: 4}, 2))
+		require.Equal(t, 0, batchWorkers(models.CMD{BatchWorkers: 4}, 0))
+		require.Equal(t, runtime.NumCPU(), batchWorkers(models.CMD{}, runtime.NumCPU()+1))
+	})
+
+	t.Run("should return a single worker when `-capture` is set", func(t *testing.T) {
+		// Verify
+		require.Equal(t, 1, batchWorkers(models.CMD{BatchWorkers: 4, Capture: "run.cap"}, 10))
+	})
+}
+
+func TestBatchPair(t *testing.T) {
+	t.Run("should return `deltaName, nil` after writing Delta of file pair", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: batchStdin, Yes: true}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}
+		updatedHash := fmt.Sprintf("%x", sha256.Sum256([]byte("v2/app.bin")))
+		written := ""
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		makeDir = func(path string, perm os.FileMode) error {
+			return nil
+		}
+
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+			require.Equal(t, expectedDelta, delta)
+			require.Equal(t, updatedHash, metadata.UpdatedHash)
+			written = fileName
+			return nil
+		}
+
+		// Run
+		deltaName, err := batchPair(cmd, "v1/app.bin", "v2/app.bin")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, batchDeltaName("v2/app.bin"), deltaName)
+		require.Equal(t, deltaName, written)
+	})
+
+	t.Run("should write Delta with the encoding set by `-encoding`", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: batchStdin, Yes: true, Encoding: constants.EncodingJSON}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}
+		var written any
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		_, err := batchPair(cmd, "v1/app.bin", "v2/app.bin")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, files.JSONDelta(expectedDelta), written)
+		// Mock
+		writeStructToFile = files.WriteStructToFile
+	})
+
+	t.Run("should return `deltaName, UpdatedFileHasNoChangesError` when file pair has no changes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: batchStdin, Yes: true}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, expectedError
+		}
+
+		// Run
+		_, err := batchPair(cmd, "v1/app.bin", "v2/app.bin")
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `deltaName, UnableToGenerateSignatureError` when unable to generate Signature of Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: batchStdin, Yes: true}
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		_, err := batchPair(cmd, "v1/app.bin", "v2/app.bin")
+		// Verify
+		require.Equal(t, expectedError, err)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+	})
+
+	t.Run("should return `deltaName, UnableToCreateDeltaFileError` when unable to create Delta file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: batchStdin, Yes: true}
+		expectedError := errors.New(constants.UnableToCreateDeltaFileError)
+		// Mock
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}, nil
+		}
+
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+			return errors.New(constants.UnableToCreateFileError)
+		}
+
+		// Run
+		_, err := batchPair(cmd, "v1/app.bin", "v2/app.bin")
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestParseFilePairs(t *testing.T) {
+	t.Run("should return pairs from newline delimited list", func(t *testing.T) {
+		// Setup
+		list := []byte("v1/a.bin\nv2/a.bin\r\n\nv1/b bin\nv2/b bin\n")
+		expectedPairs := [][2]string{{"v1/a.bin", "v2/a.bin"}, {"v1/b bin", "v2/b bin"}}
+		// Run
+		pairs, err := parseFilePairs(list)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedPairs, pairs)
+	})
+
+	t.Run("should return pairs from NUL delimited list (EG file names containing newlines)", func(t *testing.T) {
+		// Setup
+		list := []byte("v1/a\nb.bin\x00v2/a\nb.bin\x00")
+		expectedPairs := [][2]string{{"v1/a\nb.bin", "v2/a\nb.bin"}}
+		// Run
+		pairs, err := parseFilePairs(list)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedPairs, pairs)
+	})
+
+	t.Run("should return `nil, BatchPairIncompleteError` when list contains an odd number of files", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.BatchPairIncompleteError)
+		// Run
+		pairs, err := parseFilePairs([]byte("v1/a.bin\nv2/a.bin\nv1/b.bin\n"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, pairs)
+	})
+}
+
+func TestReadFilePairs(t *testing.T) {
+	t.Run("should read pairs from stdin when `-`", func(t *testing.T) {
+		// Mock
+		readAll = func(reader io.Reader) ([]byte, error) {
+			return []byte("v1/a.bin\nv2/a.bin\n"), nil
+		}
+
+		// Run
+		pairs, err := readFilePairs(batchStdin)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, [][2]string{{"v1/a.bin", "v2/a.bin"}}, pairs)
+	})
+
+	t.Run("should read pairs from provided file", func(t *testing.T) {
+		// Setup
+		readName := ""
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			readName = name
+			return []byte("v1/a.bin\x00v2/a.bin\x00"), nil
+		}
+
+		// Run
+		pairs, err := readFilePairs(file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, file, readName)
+		require.Equal(t, [][2]string{{"v1/a.bin", "v2/a.bin"}}, pairs)
+	})
+
+	t.Run("should return `nil, UnableToReadFilesFromError` when unable to read list", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToReadFilesFromError)
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		pairs, err := readFilePairs(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, pairs)
+	})
+}
+
+func TestSkipReason(t *testing.T) {
+	current := time.Unix(1000000, 0)
+	info := fileInfoMock{size: 2048, modTime: current.Add(-48 * time.Hour)}
+	// Mock
+	statFile = func(name string) (os.FileInfo, error) {
+		return info, nil
+	}
+
+	now = func() time.Time {
+		return current
+	}
+
+	t.Run("should return `\"\"` when Updated file passes every filter", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{MinSize: 1024, MaxSize: 4096, NewerThan: "72h"}
+		// Run + Verify
+		require.Equal(t, "", skipReason(cmd, "v2/app.bin"))
+	})
+
+	t.Run("should return `reason` when Updated file is smaller than min size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{MinSize: 4096}
+		// Run + Verify
+		require.Equal(t, "smaller than -minSize (4096 bytes)", skipReason(cmd, "v2/app.bin"))
+	})
+
+	t.Run("should return `reason` when Updated file is larger than max size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{MaxSize: 1024}
+		// Run + Verify
+		require.Equal(t, "larger than -maxSize (1024 bytes)", skipReason(cmd, "v2/app.bin"))
+	})
+
+	t.Run("should return `reason` when Updated file was not modified within newer than duration", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{NewerThan: "24h"}
+		// Run + Verify
+		require.Equal(t, "not modified within -newerThan (24h)", skipReason(cmd, "v2/app.bin"))
+	})
+
+	t.Run("should return `\"\"` when unable to get info of Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{MaxSize: 1024}
+		// Mock
+		statFile = func(name string) (os.FileInfo, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run + Verify
+		require.Equal(t, "", skipReason(cmd, "v2/app.bin"))
+	})
+
+	// Restore, so later tests read real files + clocks
+	statFile = os.Stat
+	now = time.Now
+}
+
+func TestRunBatch(t *testing.T) {
+	t.Run("should return `nil` after processing every pair", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true}
+		logged := []string{}
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return []byte("v1/a.bin\nv2/a.bin\nv1/b.bin\nv2/b.bin\n"), nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}, nil
+		}
+
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+			return nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		// Run
+		err := runBatch(cmd, &models.Summary{})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 3, len(logged))
+		require.Equal(t, true, strings.Contains(logged[2], "Batch: 2 pairs, 0 failed"))
+	})
+
+	t.Run("should return `BatchFailedError` after processing every pair when any pair fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true}
+		expectedError := errors.New(constants.BatchFailedError)
+		logged := []string{}
+		failures := []string{}
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			if fileName == "v1/a.bin" {
+				return nil, errors.New(constants.FileDoesNotExistError)
+			}
+
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		errorLogger = func(message string) {
+			failures = append(failures, message)
+		}
+
+		// Run
+		err := runBatch(cmd, &models.Summary{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, len(failures))
+		require.Equal(t, true, strings.Contains(failures[0], constants.OriginalFileDoesNotExistError))
+		require.Equal(t, true, strings.Contains(logged[len(logged)-1], "Batch: 2 pairs, 1 failed"))
+	})
+
+	t.Run("should return `nil` + list skipped pairs in summary when Updated file fails a filter", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true, MaxSize: 1024}
+		summary := &models.Summary{}
+		logged := []string{}
+		expectedSkipped := []models.SkippedFile{{Path: "v2/b.bin", Reason: "larger than -maxSize (1024 bytes)"}}
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		statFile = func(name string) (os.FileInfo, error) {
+			if name == "v2/b.bin" {
+				return fileInfoMock{size: 2048}, nil
+			}
+
+			return fileInfoMock{size: 512}, nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		// Run
+		err := runBatch(cmd, summary)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSkipped, summary.Skipped)
+		require.Equal(t, true, strings.Contains(logged[1], "v1/b.bin -> v2/b.bin: skipped, larger than -maxSize (1024 bytes)"))
+		require.Equal(t, true, strings.Contains(logged[len(logged)-1], "Batch: 2 pairs, 0 failed, 1 skipped"))
+		// Mock
+		statFile = os.Stat
+	})
+
+	t.Run("should process pairs in parallel, logging a result line per pair in list order", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true, BatchWorkers: 4}
+		logged := []string{}
+		failures := []string{}
+		release := make(chan struct{})
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return []byte("v1/a.bin\nv2/a.bin\nv1/b.bin\nv2/b.bin\nv1/c.bin\nv2/c.bin\nv1/d.bin\nv2/d.bin\n"), nil
+		}
+
+		// First pair will only complete once the last pair has started, so fails when pairs are processed one at a time
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			switch fileName {
+			case "v1/a.bin":
+				select {
+				case <-release:
+				case <-time.After(5 * time.Second):
+					return nil, errors.New(constants.FileDoesNotExistError)
+				}
+			case "v1/d.bin":
+				close(release)
+			}
+
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}, nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		errorLogger = func(message string) {
+			failures = append(failures, message)
+		}
+
+		// Run
+		err := runBatch(cmd, &models.Summary{})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 0, len(failures))
+		require.Equal(t, 5, len(logged))
+		for index, name := range []string{"a", "b", "c", "d"} {
+			require.Equal(t, true, strings.Contains(logged[index], "v1/"+name+".bin -> v2/"+name+".bin: Delta written"))
+		}
+
+		require.Equal(t, true, strings.Contains(logged[4], "Batch: 4 pairs, 0 failed"))
+	})
+
+	t.Run("should process pairs in parallel when `-v` is set, without racing on shared log sampling (run with -race)", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true, BatchWorkers: 4, Verbose: true}
+		logged := []string{}
+		utils.SetLogSampling(1000000, 0)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		// Each worker samples high-volume debug logs in the same way as Signature + Delta generation
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			for index := 0; index < 1000; index++ {
+				utils.SampleLog(verbose)
+			}
+
+			return testSignature, nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		// Run
+		err := runBatch(cmd, &models.Summary{})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, strings.Contains(logged[len(logged)-1], "Batch: 4 pairs, 0 failed"))
+		// Mock
+		utils.SetLogSampling(1, 0)
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+	})
+
+	t.Run("should return `BatchPairIncompleteError` when list contains an odd number of files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: file, Yes: true}
+		expectedError := errors.New(constants.BatchPairIncompleteError)
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return []byte("v1/a.bin\n"), nil
+		}
+
+		// Run
+		err := runBatch(cmd, &models.Summary{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		// Mock
+		readFile = os.ReadFile
+		readAll = io.ReadAll
+	})
+}
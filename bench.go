@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const (
+	megabyte            int   = 1024 * 1024
+	benchmarkSeed       int64 = 42   // Fixed seed so synthetic files are comparable between runs
+	benchmarkMutateStep int   = 4096 // Modify 1 byte per 4KB when generating Updated file
+)
+
+var (
+	readMemStats = runtime.ReadMemStats
+	peakRSS      = getPeakRSS
+	now          = time.Now
+)
+
+// formatBenchmarkResult() will format a BenchmarkResult as a human readable string for logging.
+// EG: `Signature: 1.00 MB in 500ms (2.00 MB/s), 1024 allocs (0.06 MB allocated)`.
+func formatBenchmarkResult(result models.BenchmarkResult) string {
+	size := float64(result.Bytes) / float64(megabyte)
+	throughput := 0.0
+	if result.Duration > 0 {
+		throughput = size / result.Duration.Seconds()
+	}
+
+	return fmt.Sprintf("%s: %.2f MB in %s (%.2f MB/s), %d allocs (%.2f MB allocated)", result.Phase, size, result.Duration.Round(time.Millisecond), throughput, result.Allocations, float64(result.AllocatedBytes)/float64(megabyte))
+}
+
+// generateBenchmarkInput() will generate a synthetic file of the provided size (MB) filled with pseudo-random data.
+// Data is generated from a fixed seed so the same file is produced on every run.
+func generateBenchmarkInput(size int) []byte {
+	data := make([]byte, size*megabyte)
+	random := rand.New(rand.NewSource(benchmarkSeed))
+	random.Read(data)
+	return data
+}
+
+// getBenchmarkInput() will return the data used as the Original file in Benchmark mode.
+// When an Original file is provided, function will read the full contents of the file.
+// When no Original file is provided, function will generate a synthetic file based on BenchSize.
+// Function returns `data, nil` when successful.
+// Function returns `nil, error` when unable to read the Original file.
+func getBenchmarkInput(cmd models.CMD) ([]byte, error) {
+	if cmd.OriginalFile == "" {
+		return generateBenchmarkInput(cmd.BenchSize), nil
+	}
+
+	return readOriginal(cmd.OriginalFile)
+}
+
+// measure() will run the provided function and record its duration + heap allocations.
+// Function returns `BenchmarkResult, error`, where error is returned from the provided function.
+func measure(phase string, size int, run func() error) (models.BenchmarkResult, error) {
+	var before, after runtime.MemStats
+	readMemStats(&before)
+	start := now()
+	err := run()
+	duration := now().Sub(start)
+	readMemStats(&after)
+	result := models.BenchmarkResult{
+		Phase:          phase,
+		Bytes:          int64(size),
+		Duration:       duration,
+		Allocations:    after.Mallocs - before.Mallocs,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+
+	return result, err
+}
+
+// mutateBenchmarkInput() will create the Updated file used in Benchmark mode.
+// Updated file will be a copy of the provided data with 1 byte modified per `benchmarkMutateStep` bytes.
+func mutateBenchmarkInput(data []byte) []byte {
+	updated := make([]byte, len(data))
+	copy(updated, data)
+	for index := benchmarkMutateStep / 2; index < len(updated); index += benchmarkMutateStep {
+		updated[index] = ^updated[index]
+	}
+
+	return updated
+}
+
+// runBenchmark() will run Signature + Delta generation against a synthetic (or provided) file and report throughput.
+// Report will contain MB/s, heap allocations, and peak RSS of the process.
+// Function returns `results, nil` when successful.
+// Function returns `results, error` when unable to read the Benchmark input, or when Signature/Delta generation fails.
+func runBenchmark(cmd models.CMD) ([]models.BenchmarkResult, error) {
+	results := []models.BenchmarkResult{}
+	original, err := getBenchmarkInput(cmd)
+	if err != nil {
+		return results, err
+	}
+
+	updated := mutateBenchmarkInput(original)
+	logger(utils.Stat(fmt.Sprintf("Benchmark input: %.2f MB", float64(len(original))/float64(megabyte))), true)
+
+	// Benchmark Signature generation
+	var signature models.Signature
+	result, err := measure("Signature", len(original), func() error {
+		var err error
+		signature, err = generateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		return err
+	})
+
+	if err != nil {
+		return results, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	results = append(results, result)
+	logger(utils.Stat(formatBenchmarkResult(result)), true)
+
+	// Benchmark Delta generation
+	result, err = measure("Delta", len(updated), func() error {
+		_, err := generateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+		return err
+	})
+
+	if err != nil && err.Error() != constants.UpdatedFileHasNoChangesError {
+		return results, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	results = append(results, result)
+	logger(utils.Stat(formatBenchmarkResult(result)), true)
+	logger(utils.Stat(fmt.Sprintf("Peak RSS: %.2f MB", float64(peakRSS())/float64(megabyte))), true)
+	return results, nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBenchmarkResult(t *testing.T) {
+	t.Run("should return formatted throughput + allocation stats", func(t *testing.T) {
+		// Setup
+		result := models.BenchmarkResult{Phase: "Signature", Bytes: int64(2 * megabyte), Duration: time.Second, Allocations: 10, AllocatedBytes: uint64(megabyte)}
+		expectedResult := "Signature: 2.00 MB in 1s (2.00 MB/s), 10 allocs (1.00 MB allocated)"
+		// Run
+		output := formatBenchmarkResult(result)
+		// Verify
+		require.Equal(t, expectedResult, output)
+	})
+
+	t.Run("should return zero throughput when duration is zero", func(t *testing.T) {
+		// Setup
+		result := models.BenchmarkResult{Phase: "Delta", Bytes: int64(megabyte)}
+		expectedResult := "Delta: 1.00 MB in 0s (0.00 MB/s), 0 allocs (0.00 MB allocated)"
+		// Run
+		output := formatBenchmarkResult(result)
+		// Verify
+		require.Equal(t, expectedResult, output)
+	})
+}
+
+func TestGenerateBenchmarkInput(t *testing.T) {
+	t.Run("should return consistent synthetic data of the requested size", func(t *testing.T) {
+		// Run
+		data := generateBenchmarkInput(1)
+		anotherData := generateBenchmarkInput(1)
+		// Verify
+		require.Equal(t, megabyte, len(data))
+		require.Equal(t, data, anotherData)
+	})
+}
+
+func TestGetBenchmarkInput(t *testing.T) {
+	t.Run("should return synthetic data when no Original file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, BenchSize: 1}
+		// Run
+		data, err := getBenchmarkInput(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, megabyte, len(data))
+	})
+
+	t.Run("should return file contents when Original file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, OriginalFile: file}
+		expectedData := []byte("some file contents")
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader(expectedData)), nil
+		}
+
+		// Run
+		data, err := getBenchmarkInput(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedData, data)
+	})
+}
+
+func TestMeasure(t *testing.T) {
+	t.Run("should return duration + allocation stats recorded while running function", func(t *testing.T) {
+		// Setup
+		start := time.Unix(0, 0)
+		calls := 0
+		expectedError := errors.New(errorMessage)
+		// Mock
+		now = func() time.Time {
+			calls++
+			return start.Add(time.Duration(calls-1) * time.Second)
+		}
+
+		readMemStats = func(stats *runtime.MemStats) {
+			stats.Mallocs = uint64(calls * 10)
+			stats.TotalAlloc = uint64(calls * 100)
+		}
+
+		// Run
+		result, err := measure("Signature", megabyte, func() error {
+			return expectedError
+		})
+
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "Signature", result.Phase)
+		require.Equal(t, int64(megabyte), result.Bytes)
+		require.Equal(t, time.Second, result.Duration)
+		require.Equal(t, uint64(20), result.Allocations)
+		require.Equal(t, uint64(200), result.AllocatedBytes)
+	})
+}
+
+func TestMutateBenchmarkInput(t *testing.T) {
+	t.Run("should return modified copy of provided data without changing the original", func(t *testing.T) {
+		// Setup
+		data := make([]byte, benchmarkMutateStep*2)
+		// Run
+		updated := mutateBenchmarkInput(data)
+		// Verify
+		require.Equal(t, len(data), len(updated))
+		require.Equal(t, make([]byte, benchmarkMutateStep*2), data)
+		require.Equal(t, byte(255), updated[benchmarkMutateStep/2])
+		require.Equal(t, byte(255), updated[benchmarkMutateStep+benchmarkMutateStep/2])
+	})
+}
+
+func TestRunBenchmark(t *testing.T) {
+	t.Run("should return Signature + Delta results when benchmark completes successfully", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, OriginalFile: file}
+		// Mock
+		logger = func(message string, verbose bool) {}
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, nil
+		}
+
+		peakRSS = func() int64 {
+			return int64(megabyte)
+		}
+
+		// Run
+		results, err := runBenchmark(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 2, len(results))
+		require.Equal(t, "Signature", results[0].Phase)
+		require.Equal(t, "Delta", results[1].Phase)
+	})
+
+	t.Run("should return error when unable to read Benchmark input", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, OriginalFile: file}
+		expectedError := errors.New(constants.OriginalFileDoesNotExistError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.FileDoesNotExistError)
+		}
+
+		// Run
+		results, err := runBenchmark(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 0, len(results))
+	})
+
+	t.Run("should return `UnableToGenerateSignatureError` when Signature generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, OriginalFile: file}
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		logger = func(message string, verbose bool) {}
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		results, err := runBenchmark(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 0, len(results))
+	})
+
+	t.Run("should return `UnableToGenerateDeltaError` when Delta generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true, OriginalFile: file}
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		logger = func(message string, verbose bool) {}
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(errorMessage)
+		}
+
+		// Run
+		results, err := runBenchmark(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, len(results))
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const deltaCacheExtension string = ".delta"
+
+var (
+	makeDir    = os.MkdirAll
+	renameFile = os.Rename
+	getPid     = os.Getpid
+)
+
+// cacheDelta() will store a generated Delta in the Delta cache, so repeat requests for the same Original + Updated pair can skip Delta generation.
+// Delta will be written to a temp file before being renamed into place, so concurrent runs never read a partially written Delta.
+// Note: a warning will be logged (and the run will continue) when unable to write to the Delta cache.
+func cacheDelta(path string, delta models.Delta) {
+	if err := makeDir(filepath.Dir(path), files.DirMode()); err != nil {
+		errorLogger(utils.Warning(constants.UnableToWriteDeltaCacheError))
+		return
+	}
+
+	tempPath := fmt.Sprintf("%s.%d.tmp", path, getPid())
+	if err := writeStructToPath(delta, tempPath); err != nil {
+		_ = removeAll(tempPath)
+		errorLogger(utils.Warning(constants.UnableToWriteDeltaCacheError))
+		return
+	}
+
+	if err := renameFile(tempPath, path); err != nil {
+		_ = removeAll(tempPath)
+		errorLogger(utils.Warning(constants.UnableToWriteDeltaCacheError))
+	}
+}
+
+// deltaCachePath() will return the path of the cached Delta for the files provided in CMD.
+// Cache key is built from SHA256 fingerprints of the Original input (Original file in Signature mode, otherwise the Signature file) + the Updated file.
+// Function returns `path, nil` when successful.
+// Function returns `"", error` when unable to fingerprint either file.
+func deltaCachePath(cmd models.CMD) (string, error) {
+	originalInput := cmd.SignatureFile
+	if cmd.SignatureMode {
+		originalInput = cmd.OriginalFile
+	}
+
+	originalHash, err := hashFile(originalInput)
+	if err != nil {
+		return "", err
+	}
+
+	updatedHash, err := hashFile(cmd.UpdatedFile)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cmd.DeltaCache, originalHash+"-"+updatedHash+deltaCacheExtension), nil
+}
+
+// getCachedDelta() will attempt to read a previously generated Delta for the files provided in CMD from the Delta cache.
+// Function returns `delta, path, true` when a cached Delta was found.
+// Function returns `emptyDelta, path, false` when no cached Delta was found (generated Delta should be stored at path).
+// Function returns `emptyDelta, "", false` when unable to fingerprint the input files (Delta should not be cached).
+func getCachedDelta(cmd models.CMD) (models.Delta, string, bool) {
+	path, err := deltaCachePath(cmd)
+	if err != nil {
+		return models.Delta{}, "", false
+	}
+
+	delta, err := openDelta(path, cmd.Verbose)
+	if err != nil {
+		return models.Delta{}, path, false
+	}
+
+	logger(fmt.Sprintf("Delta cache hit: %s", path), cmd.Verbose)
+	return delta, path, true
+}
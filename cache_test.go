@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var cachedDelta = models.Delta{
+	0: {Head: 0, Tail: 6, IsModified: true, Value: []byte("updated")},
+}
+
+// resetCacheMocks() will restore the file functions used by the Delta cache.
+func resetCacheMocks() {
+	openFile = files.OpenFile
+	openDelta = files.OpenDelta
+	writeStructToPath = files.WriteStructToPath
+	removeAll = os.RemoveAll
+	makeDir = os.MkdirAll
+	renameFile = os.Rename
+}
+
+// writeCacheInputs() will create Original + Signature + Updated files in a temp folder, and return a CMD referencing them.
+func writeCacheInputs(t *testing.T, updated string) models.CMD {
+	folder := t.TempDir()
+	cmd := models.CMD{
+		DeltaMode:     true,
+		OriginalFile:  filepath.Join(folder, "original.txt"),
+		SignatureFile: filepath.Join(folder, "sig.txt"),
+		UpdatedFile:   filepath.Join(folder, "updated.txt"),
+		DeltaCache:    filepath.Join(folder, "cache"),
+	}
+
+	require.Equal(t, nil, os.WriteFile(cmd.OriginalFile, []byte("original"), 0600))
+	require.Equal(t, nil, os.WriteFile(cmd.SignatureFile, []byte("signature"), 0600))
+	require.Equal(t, nil, os.WriteFile(cmd.UpdatedFile, []byte(updated), 0600))
+	return cmd
+}
+
+func TestCacheDelta(t *testing.T) {
+	t.Run("should write Delta to cache when successful", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		cmd := writeCacheInputs(t, "updated")
+		path, err := deltaCachePath(cmd)
+		require.Equal(t, nil, err)
+		// Run
+		cacheDelta(path, cachedDelta)
+		// Verify
+		delta, _, found := getCachedDelta(cmd)
+		require.Equal(t, true, found)
+		require.Equal(t, cachedDelta, delta)
+		entries, err := os.ReadDir(cmd.DeltaCache)
+		require.Equal(t, nil, err)
+		require.Equal(t, 1, len(entries))
+	})
+
+	t.Run("should log warning and remove temp file when unable to write to cache", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		logged := ""
+		removed := ""
+		// Mock
+		writeStructToPath = func(model any, path string) error {
+			return errors.New(constants.UnableToCreateFileError)
+		}
+
+		removeAll = func(path string) error {
+			removed = path
+			return nil
+		}
+
+		getPid = func() int {
+			return 42
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		// Run
+		cacheDelta(filepath.Join(t.TempDir(), "key.delta"), cachedDelta)
+		// Verify
+		require.Equal(t, utils.Warning(constants.UnableToWriteDeltaCacheError), logged)
+		require.Equal(t, "key.delta.42.tmp", filepath.Base(removed))
+		getPid = os.Getpid
+	})
+
+	t.Run("should log warning when unable to create cache folder", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		logged := ""
+		// Mock
+		makeDir = func(path string, perm os.FileMode) error {
+			return errors.New(errorMessage)
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		// Run
+		cacheDelta(filepath.Join(t.TempDir(), "key.delta"), cachedDelta)
+		// Verify
+		require.Equal(t, utils.Warning(constants.UnableToWriteDeltaCacheError), logged)
+	})
+}
+
+func TestDeltaCachePath(t *testing.T) {
+	t.Run("should return a different path when Updated file changes", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		first := writeCacheInputs(t, "updated")
+		second := writeCacheInputs(t, "updated again")
+		second.DeltaCache = first.DeltaCache
+		// Run
+		firstPath, firstErr := deltaCachePath(first)
+		secondPath, secondErr := deltaCachePath(second)
+		// Verify
+		require.Equal(t, nil, firstErr)
+		require.Equal(t, nil, secondErr)
+		require.NotEqual(t, firstPath, secondPath)
+		require.Equal(t, first.DeltaCache, filepath.Dir(firstPath))
+	})
+
+	t.Run("should fingerprint Original file instead of Signature file in Signature mode", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		cmd := writeCacheInputs(t, "updated")
+		// Run
+		deltaPath, _ := deltaCachePath(cmd)
+		cmd.SignatureMode = true
+		signaturePath, err := deltaCachePath(cmd)
+		originalHash, _ := hashFile(cmd.OriginalFile)
+		// Verify
+		require.Equal(t, nil, err)
+		require.NotEqual(t, deltaPath, signaturePath)
+		require.Equal(t, originalHash, filepath.Base(signaturePath)[:len(originalHash)])
+	})
+
+	t.Run("should return error when unable to fingerprint Updated file", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		cmd := writeCacheInputs(t, "updated")
+		cmd.UpdatedFile = filepath.Join(t.TempDir(), "missing.txt")
+		// Run
+		path, err := deltaCachePath(cmd)
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Equal(t, "", path)
+	})
+}
+
+func TestGetCachedDelta(t *testing.T) {
+	t.Run("should return `emptyDelta, path, false` when Delta has not been cached", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		cmd := writeCacheInputs(t, "updated")
+		expectedPath, _ := deltaCachePath(cmd)
+		// Run
+		delta, path, found := getCachedDelta(cmd)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, expectedPath, path)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, \"\", false` when unable to fingerprint input files", func(t *testing.T) {
+		// Setup
+		resetCacheMocks()
+		cmd := writeCacheInputs(t, "updated")
+		cmd.SignatureFile = filepath.Join(t.TempDir(), "missing.txt")
+		// Run
+		delta, path, found := getCachedDelta(cmd)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, "", path)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
@@ -0,0 +1,100 @@
+// Package main exposes the Signature, Delta, and Patch functions to non-Go applications (EG C/C++, Python via ctypes).
+// Build: `go build -buildmode=c-shared -o libgofd.so ./capi` (generates `libgofd.h` alongside the library).
+// Signatures + Deltas use the same (gob) format as Signature + Delta files.
+// Output buffers are allocated by the library and must be released with `gofd_free()`.
+package main
+
+/*
+#include <stdlib.h>
+
+#define GOFD_OK 0
+#define GOFD_ERROR 1
+#define GOFD_NO_CHANGES 2
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/engine"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// Return codes shared with C callers (see GOFD_* defines above).
+const (
+	codeOK        int = 0 // GOFD_OK
+	codeError     int = 1 // GOFD_ERROR
+	codeNoChanges int = 2 // GOFD_NO_CHANGES
+)
+
+func init() {
+	// Embedding applications own stdout, so suppress all logging
+	utils.SetQuiet(true)
+}
+
+// errorCode() will convert an error into the return code shared with C callers.
+func errorCode(err error) int {
+	if err == nil {
+		return codeOK
+	}
+
+	if err.Error() == constants.UpdatedFileHasNoChangesError {
+		return codeNoChanges
+	}
+
+	return codeError
+}
+
+// goBytes() will view a C buffer as a byte slice without copying.
+// Note: slice must not be retained after the exported function returns.
+func goBytes(buffer unsafe.Pointer, length C.size_t) []byte {
+	if buffer == nil || length == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*byte)(buffer), int(length))
+}
+
+// output() will copy data into a C allocated buffer, returning the C return code for the provided error.
+// Note: `out` will be set to NULL when an error is provided.
+func output(data []byte, err error, out *unsafe.Pointer, outLength *C.size_t) C.int {
+	*out = nil
+	*outLength = 0
+	if err != nil {
+		return C.int(errorCode(err))
+	}
+
+	// Allocate at least 1 byte, so a successful result is never NULL
+	buffer := C.malloc(C.size_t(len(data) + 1))
+	copy(unsafe.Slice((*byte)(buffer), len(data)), data)
+	*out = buffer
+	*outLength = C.size_t(len(data))
+	return C.int(codeOK)
+}
+
+//export gofd_delta
+func gofd_delta(signatureData unsafe.Pointer, signatureLength C.size_t, updated unsafe.Pointer, updatedLength C.size_t, out *unsafe.Pointer, outLength *C.size_t) C.int {
+	data, err := engine.Delta(goBytes(signatureData, signatureLength), goBytes(updated, updatedLength))
+	return output(data, err, out, outLength)
+}
+
+//export gofd_free
+func gofd_free(buffer unsafe.Pointer) {
+	C.free(buffer)
+}
+
+//export gofd_patch
+func gofd_patch(original unsafe.Pointer, originalLength C.size_t, deltaData unsafe.Pointer, deltaLength C.size_t, out *unsafe.Pointer, outLength *C.size_t) C.int {
+	data, err := engine.Patch(goBytes(original, originalLength), goBytes(deltaData, deltaLength))
+	return output(data, err, out, outLength)
+}
+
+//export gofd_signature
+func gofd_signature(original unsafe.Pointer, originalLength C.size_t, out *unsafe.Pointer, outLength *C.size_t) C.int {
+	data, err := engine.Signature(goBytes(original, originalLength))
+	return output(data, err, out, outLength)
+}
+
+// main() is required for `-buildmode=c-shared`, but is never called.
+func main() {}
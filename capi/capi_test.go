@@ -0,0 +1,20 @@
+//go:build cgo
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCode(t *testing.T) {
+	t.Run("should return C return code for error", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, codeOK, errorCode(nil))
+		require.Equal(t, codeNoChanges, errorCode(errors.New(constants.UpdatedFileHasNoChangesError)))
+		require.Equal(t, codeError, errorCode(errors.New(constants.InvalidDeltaError)))
+	})
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+var (
+	openCapture = files.OpenCapture
+	recorder    *captureRecorder // nil when `-capture` is not set
+)
+
+// captureRecorder type.
+// This will record every input stream read during a run (see `-capture`), keeping up to limit bytes of each stream.
+type captureRecorder struct {
+	limit    int64
+	hashOnly bool
+	lock     sync.Mutex // Prevents streams opened in parallel (EG Batch workers) being added at the same time
+	streams  []*captureStream
+}
+
+// captureStream type.
+// This will record the bytes read from a single input stream, so it can be fed by io.TeeReader.
+type captureStream struct {
+	stream   models.CaptureStream
+	hash     hash.Hash
+	limit    int64
+	hashOnly bool
+}
+
+// captureReader() will record every byte read from the provided reader as a named input stream when `-capture` is set.
+// Note: this is safe to call from multiple goroutines, but each returned reader must only be read by one goroutine.
+// Function returns `reader` unchanged when `-capture` is not set.
+func captureReader(name string, path string, reader *bufio.Reader) *bufio.Reader {
+	if recorder == nil {
+		return reader
+	}
+
+	stream := &captureStream{stream: models.CaptureStream{Name: name, Path: path}, hash: sha256.New(), limit: recorder.limit, hashOnly: recorder.hashOnly}
+	recorder.lock.Lock()
+	recorder.streams = append(recorder.streams, stream)
+	recorder.lock.Unlock()
+	return bufio.NewReader(io.TeeReader(reader, stream))
+}
+
+// finishCapture() will write the input streams recorded during the run to the `-capture` file in Outputs folder.
+// Note: the Capture will be written even when the run failed (EG the runs worth replaying), but not when no streams were read.
+// Function returns `nil` when successful, or when `-capture` is not set.
+// Function returns `UnableToWriteCaptureFileError` when unable to write the Capture file.
+func finishCapture(cmd models.CMD) error {
+	if recorder == nil {
+		return nil
+	}
+
+	streams := recorder.streams
+	recorder = nil
+	if len(streams) == 0 {
+		return nil
+	}
+
+	capture := models.Capture{Streams: make([]models.CaptureStream, 0, len(streams))}
+	for _, stream := range streams {
+		stream.stream.Hash = hex.EncodeToString(stream.hash.Sum(nil))
+		capture.Streams = append(capture.Streams, stream.stream)
+	}
+
+	if err := writeStructToFile(capture, cmd.Capture); err != nil {
+		return errors.New(constants.UnableToWriteCaptureFileError)
+	}
+
+	return nil
+}
+
+// replayStreams() will return the first Original + Updated streams of a Capture.
+// Note: a warning will be logged when either stream was truncated by the capture limit, as the replay may not reproduce the original run.
+// Function returns `original, updated, nil` when successful.
+// Function returns `emptyStream, emptyStream, CaptureHasNoDataError` when either stream is missing, or was captured as a hash only.
+func replayStreams(capture models.Capture) (models.CaptureStream, models.CaptureStream, error) {
+	found := map[string]models.CaptureStream{}
+	for _, stream := range capture.Streams {
+		if _, exists := found[stream.Name]; !exists {
+			found[stream.Name] = stream
+		}
+	}
+
+	original, hasOriginal := found["original"]
+	updated, hasUpdated := found["updated"]
+	if !hasOriginal || !hasUpdated || (original.Size > 0 && len(original.Data) == 0) || (updated.Size > 0 && len(updated.Data) == 0) {
+		return models.CaptureStream{}, models.CaptureStream{}, errors.New(constants.CaptureHasNoDataError)
+	}
+
+	for _, stream := range []models.CaptureStream{original, updated} {
+		if stream.Truncated {
+			errorLogger(utils.Warning(fmt.Sprintf("Warning: captured %s stream was truncated to %d of %d bytes (see -captureLimit)", stream.Name, len(stream.Data), stream.Size)))
+		}
+	}
+
+	return original, updated, nil
+}
+
+// runReplay() will regenerate a Signature + Delta from the Original + Updated streams of a Capture (see `-replay`), and verify patching reproduces the Updated stream.
+// Function returns `nil` when patched data matches the captured Updated stream.
+// Function returns `error` when unable to open the Capture file, or run the Signature -> Delta -> Patch pipeline.
+// Function returns `CaptureHasNoDataError` when the Capture does not contain the data of an Original + Updated file.
+// Function returns `ReplayFailedError` when patched data does not match the captured Updated stream.
+func runReplay(cmd models.CMD) error {
+	capture, err := openCapture(cmd.Replay)
+	if err != nil {
+		return err
+	}
+
+	original, updated, err := replayStreams(capture)
+	if err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("Replaying %s (%d bytes) -> %s (%d bytes)", original.Path, len(original.Data), updated.Path, len(updated.Data)), true)
+	patched, delta, err := roundTrip(original.Data, updated.Data)
+	if err != nil {
+		return err
+	}
+
+	matched, literal := deltaStats(delta)
+	logger(utils.Stat(fmt.Sprintf("Replay: %d blocks, %d matched bytes, %d literal bytes", len(delta), matched, literal)), true)
+	if !bytes.Equal(patched, updated.Data) {
+		return errors.New(constants.ReplayFailedError)
+	}
+
+	logger(utils.Success("Replay passed"), true)
+	return nil
+}
+
+// startCapture() will start recording input streams when `-capture` is set (see captureReader()).
+func startCapture(cmd models.CMD) {
+	recorder = nil
+	if cmd.Capture != "" {
+		recorder = &captureRecorder{limit: cmd.CaptureLimit, hashOnly: cmd.CaptureHashes}
+	}
+}
+
+// Write() will hash the provided bytes, keeping them in the capture until the capture limit is reached.
+// Note: this will never fail, so capturing can not change the result of a run.
+func (s *captureStream) Write(p []byte) (int, error) {
+	s.hash.Write(p)
+	s.stream.Size += int64(len(p))
+	if s.hashOnly {
+		return len(p), nil
+	}
+
+	keep := int64(len(p))
+	if remaining := s.limit - int64(len(s.stream.Data)); keep > remaining {
+		keep = remaining
+		s.stream.Truncated = true
+	}
+
+	s.stream.Data = append(s.stream.Data, p[:keep]...)
+	return len(p), nil
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256Hex() will return the hex encoded SHA256 hash of the provided data.
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestCaptureReader(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+
+	t.Run("should return reader unchanged when capture not set", func(t *testing.T) {
+		// Setup
+		startCapture(models.CMD{})
+		reader := bufio.NewReader(bytes.NewReader(data))
+		// Run + Verify
+		require.Equal(t, reader, captureReader("original", "original.txt", reader))
+	})
+
+	t.Run("should write every byte read + its hash to Capture file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 1024}
+		var written any
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		startCapture(cmd)
+		read, err := io.ReadAll(captureReader("original", "original.txt", bufio.NewReader(bytes.NewReader(data))))
+		require.Equal(t, nil, err)
+		err = finishCapture(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, data, read)
+		require.Equal(t, models.Capture{Streams: []models.CaptureStream{{Name: "original", Path: "original.txt", Size: int64(len(data)), Hash: sha256Hex(data), Data: data}}}, written)
+	})
+
+	t.Run("should truncate stream data at capture limit, keeping hash of full stream", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 9}
+		var written any
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		startCapture(cmd)
+		_, _ = io.ReadAll(captureReader("updated", "updated.txt", bufio.NewReader(bytes.NewReader(data))))
+		err := finishCapture(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Capture{Streams: []models.CaptureStream{{Name: "updated", Path: "updated.txt", Size: int64(len(data)), Hash: sha256Hex(data), Data: data[:9], Truncated: true}}}, written)
+	})
+
+	t.Run("should record only hash + size when capturing hashes only", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 1024, CaptureHashes: true}
+		var written any
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		startCapture(cmd)
+		_, _ = io.ReadAll(captureReader("updated", "updated.txt", bufio.NewReader(bytes.NewReader(data))))
+		err := finishCapture(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Capture{Streams: []models.CaptureStream{{Name: "updated", Path: "updated.txt", Size: int64(len(data)), Hash: sha256Hex(data)}}}, written)
+	})
+
+	t.Run("should return `UnableToWriteCaptureFileError` when unable to write Capture file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 1024}
+		expectedError := errors.New(constants.UnableToWriteCaptureFileError)
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			return errors.New(constants.UnableToCreateFileError)
+		}
+
+		// Run
+		startCapture(cmd)
+		_, _ = io.ReadAll(captureReader("original", "original.txt", bufio.NewReader(bytes.NewReader(data))))
+		err := finishCapture(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `nil` without writing Capture file when no streams read", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 1024}
+		called := false
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			called = true
+			return nil
+		}
+
+		// Run
+		startCapture(cmd)
+		err := finishCapture(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, called)
+	})
+
+	t.Run("should record every stream when readers are opened from multiple goroutines (run with -race)", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Capture: "run.cap", CaptureLimit: 1024}
+		var written any
+		done := make(chan struct{})
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		startCapture(cmd)
+		for index := 0; index < 8; index++ {
+			go func() {
+				_, _ = io.ReadAll(captureReader("updated", "updated.txt", bufio.NewReader(bytes.NewReader(data))))
+				done <- struct{}{}
+			}()
+		}
+
+		for index := 0; index < 8; index++ {
+			<-done
+		}
+
+		err := finishCapture(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 8, len(written.(models.Capture).Streams))
+	})
+
+	// Restore, so later tests write real files
+	writeStructToFile = files.WriteStructToFile
+}
+
+func TestRunReplay(t *testing.T) {
+	original := []byte("the quick brown fox jumps over the lazy dog")
+	updated := []byte("the quick brown cat jumps over the lazy dog!")
+
+	t.Run("should return `nil` when replayed Delta reproduces Updated stream", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Replay: "run.cap"}
+		// Mock
+		logger = func(message string, verbose bool) {}
+		generateSignature = sync.GenerateSignature
+		generateDelta = sync.GenerateDelta
+		applyDelta = sync.Apply
+		openCapture = func(fileName string) (models.Capture, error) {
+			return models.Capture{Streams: []models.CaptureStream{
+				{Name: "original", Size: int64(len(original)), Data: original},
+				{Name: "updated", Size: int64(len(updated)), Data: updated},
+			}}, nil
+		}
+
+		// Run
+		err := runReplay(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+	})
+
+	t.Run("should return `ReplayFailedError` when replayed Delta does not reproduce Updated stream", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Replay: "run.cap"}
+		expectedError := errors.New(constants.ReplayFailedError)
+		// Mock
+		applyDelta = func(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+			_, err := out.Write([]byte("corrupted"))
+			return err
+		}
+
+		// Run
+		err := runReplay(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		// Mock
+		applyDelta = sync.Apply
+	})
+
+	t.Run("should return `CaptureHasNoDataError` when Capture contains hashes only", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Replay: "run.cap"}
+		expectedError := errors.New(constants.CaptureHasNoDataError)
+		// Mock
+		openCapture = func(fileName string) (models.Capture, error) {
+			return models.Capture{Streams: []models.CaptureStream{
+				{Name: "original", Size: int64(len(original)), Hash: "a"},
+				{Name: "updated", Size: int64(len(updated)), Hash: "b"},
+			}}, nil
+		}
+
+		// Run
+		err := runReplay(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `CaptureHasNoDataError` when Capture has no Updated stream", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Replay: "run.cap"}
+		expectedError := errors.New(constants.CaptureHasNoDataError)
+		// Mock
+		openCapture = func(fileName string) (models.Capture, error) {
+			return models.Capture{Streams: []models.CaptureStream{{Name: "original", Size: int64(len(original)), Data: original}}}, nil
+		}
+
+		// Run
+		err := runReplay(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `error` when unable to open Capture file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Replay: "run.cap"}
+		expectedError := errors.New(constants.CaptureFileDoesNotExistError)
+		// Mock
+		openCapture = func(fileName string) (models.Capture, error) {
+			return models.Capture{}, expectedError
+		}
+
+		// Run
+		err := runReplay(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// signatureEntry type.
+// This will contain a single block of a Signature (including candidates), along with the Weak hash it is indexed by.
+type signatureEntry struct {
+	weakHash int64
+	block    models.StrongSignature
+}
+
+// runCatSignature() will decode a Signature file and print every entry as a tab separated line (EG `weak strong head tail`).
+// Entries will be sorted by their position in the Original file, so output can be paged (EG `less`) or searched (EG `grep`).
+// Note: output is written directly to stdout without color, and write errors (EG closed pipe from `head`) are ignored.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to open the Signature file.
+func runCatSignature(cmd models.CMD) error {
+	signature, err := openSignature(cmd.SignatureFile, cmd.Verbose)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(stdout)
+	fmt.Fprintln(writer, "# weak\tstrong\thead\ttail")
+	for _, entry := range signatureEntries(signature) {
+		fmt.Fprintf(writer, "%d\t%s\t%d\t%d\n", entry.weakHash, entry.block.Hash, entry.block.Head, entry.block.Tail)
+	}
+
+	_ = writer.Flush()
+	return nil
+}
+
+// signatureEntries() will flatten a Signature (including candidates) into entries sorted by Head position, then Weak hash.
+func signatureEntries(signature models.Signature) []signatureEntry {
+	entries := []signatureEntry{}
+	for weakHash, item := range signature {
+		for _, block := range append([]models.StrongSignature{item}, item.Candidates...) {
+			entries = append(entries, signatureEntry{weakHash: weakHash, block: models.StrongSignature{Hash: block.Hash, Head: block.Head, Tail: block.Tail}})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].block.Head != entries[j].block.Head {
+			return entries[i].block.Head < entries[j].block.Head
+		}
+
+		return entries[i].weakHash < entries[j].weakHash
+	})
+
+	return entries
+}
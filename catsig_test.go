@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCatSignature(t *testing.T) {
+	t.Run("should print Signature entries sorted by position", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{CatSignature: true, SignatureFile: file}
+		signature := models.Signature{
+			7: models.StrongSignature{Hash: "bbb", Head: 4, Tail: 7, Candidates: []models.StrongSignature{{Hash: "ccc", Head: 8, Tail: 11}}},
+			3: models.StrongSignature{Hash: "aaa", Head: 0, Tail: 3},
+		}
+		out := bytes.Buffer{}
+		expected := "# weak\tstrong\thead\ttail\n3\taaa\t0\t3\n7\tbbb\t4\t7\n7\tccc\t8\t11\n"
+		// Mock
+		stdout = &out
+		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
+			return signature, nil
+		}
+
+		// Run
+		err := runCatSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("should return `error` when unable to open Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{CatSignature: true, SignatureFile: file}
+		expectedError := errors.New(errorMessage)
+		out := bytes.Buffer{}
+		// Mock
+		stdout = &out
+		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
+			return models.Signature{}, expectedError
+		}
+
+		// Run
+		err := runCatSignature(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "", out.String())
+	})
+}
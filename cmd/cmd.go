@@ -3,19 +3,47 @@ package cmd
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
 	"github.com/curtismenmuir/go-file-diff/models"
 	"github.com/curtismenmuir/go-file-diff/utils"
 )
 
 var (
 	logger       = utils.Logger
+	errorLogger  = utils.ErrorLogger
 	defineBool   = flag.Bool
 	defineString = flag.String
+	defineInt    = flag.Int
+	defineInt64  = flag.Int64
+	defineFloat  = flag.Float64
+	getArgs      = flag.Args
 )
 
+const (
+	gitDiffDriverArgs int    = 7   // path old-file old-hex old-mode new-file new-hex new-mode
+	deltaStdout       string = "-" // Stream JSON Lines Delta to stdout
+)
+
+// inferMode() will enable Signature, Delta and/or Patch mode based on the files provided, when no mode flags have been set.
+// EG `-original` + `-signature` will enable Signature mode, `-signature` + `-updated` + `-delta` will enable Delta mode, and all 4 files will enable both.
+// EG `-original` + `-delta` + `-output` will enable Patch mode, so all 4 files + `-output` will run the full Signature -> Delta -> Patch workflow.
+// Note: explicitly set mode flags will override inference (EG CMD returned unchanged).
+func inferMode(cmd models.CMD) models.CMD {
+	if cmd.SignatureMode || cmd.DeltaMode || cmd.BenchMode || cmd.GenMode || cmd.Replay != "" || cmd.SimulateMode || cmd.SelftestMode || cmd.GitDiffDriver || cmd.AnalyzeMode || cmd.StatsMode || cmd.FilesFrom != "" || cmd.CatSignature || cmd.Similarity || cmd.ConvertMode || cmd.PatchMode {
+		return cmd
+	}
+
+	cmd.SignatureMode = cmd.OriginalFile != "" && cmd.SignatureFile != ""
+	cmd.DeltaMode = cmd.SignatureFile != "" && cmd.UpdatedFile != "" && cmd.DeltaFile != ""
+	cmd.PatchMode = cmd.OriginalFile != "" && cmd.DeltaFile != "" && cmd.OutputFile != ""
+	return cmd
+}
+
 // ParseCMD will read CMD flags and will return values in CMD struct.
+// Signature + Delta modes will be inferred from the provided files when no mode flags are set.
 func ParseCMD() models.CMD {
 	// Define CMD flags
 	verbose := defineBool("v", false, "Enable extended logging")
@@ -25,6 +53,80 @@ func ParseCMD() models.CMD {
 	signatureFile := defineString("signature", "", "Signature file")
 	updatedFile := defineString("updated", "", "Updated file")
 	deltaFile := defineString("delta", "", "Delta file")
+	benchMode := defineBool("benchMode", false, "Enable Benchmark mode")
+	benchSize := defineInt("benchSize", 1, "Size (MB) of synthetic file generated in Benchmark mode")
+	genMode := defineBool("genMode", false, "Enable Generate mode (write a synthetic Original file, plus a mutated Updated file when provided)")
+	genSize := defineInt64("genSize", 1048576, "Size (bytes) of synthetic Original file generated in Generate mode")
+	pattern := defineString("pattern", constants.PatternRandom, "Content pattern of synthetic files generated in Generate mode: random, compressible, or text")
+	simulateMode := defineBool("simulateMode", false, "Enable Simulate mode")
+	simulations := defineInt("simulations", 1, "Number of simulations to run in Simulate mode")
+	insertions := defineInt("insertions", 1, "Number of random insertions applied per simulation")
+	deletions := defineInt("deletions", 1, "Number of random deletions applied per simulation")
+	moves := defineInt("moves", 1, "Number of random block moves applied per simulation")
+	mutationSize := defineInt("mutationSize", 64, "Max size (bytes) of each random mutation")
+	seed := defineInt64("seed", 1, "Seed used to generate random mutations")
+	selftestMode := defineBool("selftestMode", false, "Enable Selftest mode")
+	yes := defineBool("yes", false, "Skip confirmation prompts (EG overwriting existing output files)")
+	noColor := defineBool("noColor", false, "Disable colored output")
+	progress := defineBool("progress", false, "Report progress (rate + ETA) while processing files")
+	quiet := defineBool("quiet", false, "Suppress all non-error output")
+	logEvery := defineInt("logEvery", 1, "Log every Nth rolled buffer in verbose mode")
+	logRate := defineInt("logRate", 0, "Max rolled buffers logged per second in verbose mode (0 = no limit)")
+	summaryJSON := defineString("summaryJSON", "", "Write a JSON summary of the run to file (use - for stdout)")
+	strongHash := defineString("strongHash", "sha256", "Strong hash algorithm used for Signature + Delta generation")
+	weakHash := defineString("weakHash", "", "Weak (rolling) hash algorithm used for Signature + Delta generation (rabin-karp, adler32 or buzhash, defaults to rabin-karp). Delta mode uses the algorithm recorded in the Signature file, so must match when set")
+	legacyHash := defineString("legacyStrongHash", "", "Second Strong hash algorithm carried by Signatures + accepted by Deltas while migrating algorithms")
+	analyzeMode := defineBool("analyzeMode", false, "Enable Analyze mode (report bytes which would be transferred to sync)")
+	convertMode := defineBool("convertMode", false, "Enable Convert mode (rewrite a Signature or Delta file in the format selected by -format)")
+	convertTo := defineString("convertTo", "", "Output file written by Convert mode")
+	similarity := defineBool("similarityMode", false, "Enable Similarity mode (report percentage of Updated file bytes shared with Original file)")
+	catSignature := defineBool("catSig", false, "Enable Cat Signature mode (print every Signature entry, sorted by position)")
+	statsMode := defineBool("statsMode", false, "Enable Stats mode (report Weak hash bucket occupancy + duplicate blocks of a Signature)")
+	memStats := defineBool("memStats", false, "Report peak heap usage + sizes of major structures (Signature, Delta literals) at the end of a run")
+	background := defineBool("background", false, "Run at low CPU + I/O priority on a single CPU (EG scheduled jobs)")
+	filesFrom := defineString("filesFrom", "", "Enable Batch mode, reading newline or NUL delimited Original + Updated file pairs from file (use - for stdin)")
+	minSize := defineInt64("minSize", 0, "Skip Batch mode pairs whose Updated file is smaller than this size in bytes (0 = disabled)")
+	maxSize := defineInt64("maxSize", 0, "Skip Batch mode pairs whose Updated file is larger than this size in bytes (0 = disabled)")
+	newerThan := defineString("newerThan", "", "Skip Batch mode pairs whose Updated file was last modified longer ago than this duration (EG 72h)")
+	batchWorkers := defineInt("batchWorkers", 0, "Batch mode pairs processed in parallel (0 = one per CPU core, 1 = one pair at a time)")
+	deltaFormat := defineString("format", constants.DeltaFormatGob, "Delta file format: gob, jsonl (one JSON object per operation, use -delta=- for stdout), rdiff (librsync compatible Signature + Delta files), or bsdiff (Delta only, applied by bspatch)")
+	encoding := defineString("encoding", constants.EncodingGob, "Signature + Delta file encoding for the gob format: gob (binary), json (EG inspected or consumed by non-Go tooling), protobuf (EG consumed by other languages), or cbor (compact, EG consumed by other languages)")
+	streamDelta := defineBool("streamDelta", false, "Encode gob Delta blocks to the Delta file as they are generated, rather than holding the full Delta in memory (EG multi-GB Updated files). Skips -deltaCache, -fallbackFullCopy, -minSimilarity, -refine + -fineChunk")
+	streamSignature := defineBool("streamSignature", false, "Encode gob Signature entries to the Signature file as they are generated, rather than holding the full Signature in memory (EG multi-GB Original files). Not supported with -sparse, -signatureStride or -maxSignatureEntries")
+	compress := defineString("compress", constants.CompressNone, "Compress gob Signature + Delta files: none, gzip or zstd (recorded in the file header, so compressed files are decompressed automatically when opened)")
+	deltaCache := defineString("deltaCache", "", "Folder used to cache generated Deltas, keyed by Original + Updated file fingerprints")
+	patchReport := defineString("patchReport", "", "Write a JSON report of every patched block (source, checksum + verification status) to file")
+	dirMode := defineString("dirMode", "0755", "Permissions (octal) of created folders, narrowed by umask")
+	fileMode := defineString("fileMode", "0644", "Permissions (octal) of created files, narrowed by umask")
+	keepPartial := defineBool("keepPartial", false, "Keep incomplete outputs (suffixed .partial) when a run fails or is interrupted")
+	fallbackFullCopy := defineBool("fallbackFullCopy", false, "Replace the Delta with a full copy of the Updated file when the Delta would be larger than the file")
+	minSimilarity := defineInt("minSimilarity", 0, "Send a full copy of the Updated file instead of a Delta when less than this percentage of its bytes match (0 = disabled)")
+	byteRange := defineString("range", "", "Only generate a Delta for the region start:end of the Updated file, copying bytes outside the region from the Original file")
+	strict := defineBool("strict", false, "Verify matched blocks against the Signature when patching")
+	workers := defineInt("signatureWorkers", 0, "Goroutines hashing segments of the Original file in parallel when generating Signatures (0 = one per CPU core, 1 = single-threaded)")
+	readRetries := defineInt("readRetries", 0, "Re-read a matched block this many times before failing when it does not match the Signature (EG network filesystems)")
+	capture := defineString("capture", "", "Record every byte read from the Original + Updated files to a Capture file, so Delta generation can be replayed (see -replay)")
+	captureLimit := defineInt64("captureLimit", 64*1024*1024, "Max bytes of each input stream kept in the Capture file (hashes always cover the full stream)")
+	captureHashes := defineBool("captureHashOnly", false, "Record only the SHA256 hash + size of each input stream in the Capture file (EG private data)")
+	replay := defineString("replay", "", "Enable Replay mode, regenerating a Delta from the Original + Updated streams of a Capture file")
+	stride := defineInt("signatureStride", 1, "Keep only Signature blocks at every Nth offset of the Original file (EG 16 keeps chunk-aligned blocks), reducing Signature size")
+	maxEntries := defineInt64("maxSignatureEntries", 0, "Prune the Signature to at most this many blocks, plus the final block (0 = disabled)")
+	sparse := defineInt("sparse", 0, "Hash only every Nth window of the Original file when generating a Signature (EG 64), expanding matches against the Original file when it is available to Delta mode (0 = disabled)")
+	refine := defineBool("refine", false, "Re-scan literal regions of the Delta against a full Signature of the mismatched Original file region (EG two-pass with -sparse), when the Original file is available to Delta mode")
+	fineChunk := defineInt64("fineChunk", 0, "Re-scan literal regions of the Delta against a Signature of the mismatched Original file region with this smaller chunk size (1 up to -chunk), recovering short matches inside changed regions (0 = disabled)")
+	doctor := defineBool("doctor", false, "Check the environment + configuration of a run (EG conflicting flags, unreadable inputs, unwritable Outputs folder, free space) without running it")
+	noSpaceCheck := defineBool("noSpaceCheck", false, "Skip checking the Outputs + temp folders have enough free space for the estimated output files before writing them")
+	tempDirs := defineString("tempDirs", "", "Comma separated folders for temp files (EG Selftest mode), choosing the folder with the most free space (defaults to the system temp folder)")
+	patchMode := defineBool("patchMode", false, "Enable Patch mode, applying the Delta (generated by Delta mode, or read from -delta) to the Original file")
+	outputFile := defineString("output", "", "Patched file written by Patch mode")
+	chunk := defineInt64("chunk", 0, "Chunk size (bytes) of Signature blocks, from 1 to 131072 (0 = default of 16). Larger chunks speed up large files, but send more literal data around each change. Delta mode uses the chunk size recorded in the Signature file, so must match when set")
+	auditLog := defineString("auditLog", "", "Write an NDJSON audit log of every patched block (source, checksum, matched Signature blocks + Strong hash verification) to file")
+	chaos := defineFloat("chaos", 0, "Developer: inject read + write errors and short reads through the files layer at this rate (EG 0.01), seeded by -seed")
+	gitDiffDriver := defineBool("gitDiffDriver", false, "Enable Git diff driver mode (EG GIT_EXTERNAL_DIFF or diff.<driver>.command)")
+	remote := defineString("remote", "", "Stream the Delta over SSH to a remote host (EG deploy@example.com:22:/srv/app.bin), patching the Original file there with Patch mode of a remote copy of go-file-diff")
+	remoteBin := defineString("remoteBin", "go-file-diff", "Path of go-file-diff on the remote host, run by -remote")
+	sshKey := defineString("sshKey", "", "SSH private key used by -remote (defaults to the keys of the SSH agent)")
+	knownHosts := defineString("knownHosts", "", "SSH known_hosts file used to verify the remote host key by -remote (defaults to ~/.ssh/known_hosts)")
 
 	// Parse CMD flags
 	flag.Parse()
@@ -38,38 +140,381 @@ func ParseCMD() models.CMD {
 		SignatureFile: *signatureFile,
 		UpdatedFile:   *updatedFile,
 		DeltaFile:     *deltaFile,
+		BenchMode:     *benchMode,
+		BenchSize:     *benchSize,
+		SimulateMode:  *simulateMode,
+		Simulations:   *simulations,
+		Mutations: models.Mutations{
+			Insertions: *insertions,
+			Deletions:  *deletions,
+			Moves:      *moves,
+			MaxSize:    *mutationSize,
+		},
+		Seed:          *seed,
+		SelftestMode:  *selftestMode,
+		Yes:           *yes,
+		NoColor:       *noColor,
+		Progress:      *progress,
+		Quiet:         *quiet,
+		SummaryJSON:   *summaryJSON,
+		LogEvery:      *logEvery,
+		LogRate:       *logRate,
+		GitDiffDriver: *gitDiffDriver,
+		Args:          getArgs(),
+		StrongHash:    *strongHash,
+		WeakHash:      *weakHash,
+		Strict:        *strict,
+		AnalyzeMode:   *analyzeMode,
+		Background:    *background,
+		DeltaCache:    *deltaCache,
+		StatsMode:     *statsMode,
+		DeltaFormat:   *deltaFormat,
+		Encoding:      *encoding,
+		StreamDelta:   *streamDelta,
+		StreamSig:     *streamSignature,
+		Compress:      *compress,
+		Workers:       *workers,
+		Remote:        *remote,
+		RemoteBin:     *remoteBin,
+		SSHKey:        *sshKey,
+		KnownHosts:    *knownHosts,
+		FilesFrom:     *filesFrom,
+		PatchReport:   *patchReport,
+		CatSignature:  *catSignature,
+		DirMode:       *dirMode,
+		FileMode:      *fileMode,
+		KeepPartial:   *keepPartial,
+		FullCopy:      *fallbackFullCopy,
+		MinSimilarity: *minSimilarity,
+		Similarity:    *similarity,
+		LegacyHash:    *legacyHash,
+		ConvertMode:   *convertMode,
+		ConvertTo:     *convertTo,
+		ReadRetries:   *readRetries,
+		MemStats:      *memStats,
+		Range:         *byteRange,
+		MinSize:       *minSize,
+		MaxSize:       *maxSize,
+		NewerThan:     *newerThan,
+		BatchWorkers:  *batchWorkers,
+		GenMode:       *genMode,
+		GenSize:       *genSize,
+		Pattern:       *pattern,
+		Chaos:         *chaos,
+		Capture:       *capture,
+		CaptureLimit:  *captureLimit,
+		CaptureHashes: *captureHashes,
+		Replay:        *replay,
+		AuditLog:      *auditLog,
+		Stride:        *stride,
+		MaxEntries:    *maxEntries,
+		Sparse:        *sparse,
+		Refine:        *refine,
+		FineChunk:     *fineChunk,
+		Doctor:        *doctor,
+		NoSpaceCheck:  *noSpaceCheck,
+		TempDirs:      *tempDirs,
+		PatchMode:     *patchMode,
+		OutputFile:    *outputFile,
+		Chunk:         *chunk,
 	}
 
+	cmd = inferMode(cmd)
 	logger(fmt.Sprintf("CMD: %+v\n", cmd), *verbose)
 	return cmd
 }
 
+// verifyDeltaFormat() will check the `-format` + `-encoding` flags are a supported format + encoding (empty flags will default to gob).
+// Function returns `true` when format + encoding are supported.
+// Function returns `false` when format or encoding is unknown, or an encoding other than gob is set with a format other than gob.
+// Function returns `false` when `-streamDelta` or `-compress` is set with a format or encoding other than gob.
+func verifyDeltaFormat(cmd models.CMD) bool {
+	if cmd.DeltaFormat != "" && cmd.DeltaFormat != constants.DeltaFormatGob && cmd.DeltaFormat != constants.DeltaFormatJSONL && cmd.DeltaFormat != constants.DeltaFormatRdiff && cmd.DeltaFormat != constants.DeltaFormatBsdiff {
+		errorLogger(utils.Failure(constants.InvalidDeltaFormatError))
+		return false
+	}
+
+	if cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob && cmd.Encoding != constants.EncodingJSON && cmd.Encoding != constants.EncodingProtobuf && cmd.Encoding != constants.EncodingCBOR {
+		errorLogger(utils.Failure(constants.InvalidEncodingError))
+		return false
+	}
+
+	if cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob && cmd.DeltaFormat != "" && cmd.DeltaFormat != constants.DeltaFormatGob {
+		errorLogger(utils.Failure(constants.EncodingFormatError))
+		return false
+	}
+
+	if cmd.StreamDelta && ((cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob) || (cmd.DeltaFormat != "" && cmd.DeltaFormat != constants.DeltaFormatGob)) {
+		errorLogger(utils.Failure(constants.StreamDeltaFormatError))
+		return false
+	}
+
+	if cmd.Compress != "" && cmd.Compress != constants.CompressNone && ((cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob) || (cmd.DeltaFormat != "" && cmd.DeltaFormat != constants.DeltaFormatGob)) {
+		errorLogger(utils.Failure(constants.CompressionFormatError))
+		return false
+	}
+
+	return true
+}
+
 // VerifyCMD will parse a CMD struct and ensure correct flags have been set based on mode selection.
 // Function returns `true` when correct CMD flags have been set.
 // Note: this does not include considering if files exist etc.
 // Function returns `false` when user has not provided the correct CMD flags.
 func VerifyCMD(cmd models.CMD) bool {
+	// Doctor mode reports missing + conflicting flags as findings
+	if cmd.Doctor {
+		return true
+	}
+
 	// Verify mode set
-	if !cmd.SignatureMode && !cmd.DeltaMode {
-		logger(constants.ModeFlagMissingError, true)
+	if !cmd.SignatureMode && !cmd.DeltaMode && !cmd.BenchMode && !cmd.GenMode && cmd.Replay == "" && !cmd.SimulateMode && !cmd.SelftestMode && !cmd.GitDiffDriver && !cmd.AnalyzeMode && !cmd.StatsMode && cmd.FilesFrom == "" && !cmd.CatSignature && !cmd.Similarity && !cmd.ConvertMode && !cmd.PatchMode {
+		errorLogger(utils.Failure(constants.ModeFlagMissingError))
+		return false
+	}
+
+	// Verify Benchmark mode has an input (Original file is optional)
+	if cmd.BenchMode {
+		if cmd.OriginalFile == "" && cmd.BenchSize <= 0 {
+			errorLogger(utils.Failure(constants.BenchSizeInvalidError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify capture limit when recording input streams
+	if cmd.Capture != "" && cmd.CaptureLimit <= 0 {
+		errorLogger(utils.Failure(constants.InvalidCaptureLimitError))
 		return false
 	}
 
+	// Replay mode reads its files from the `-replay` Capture file
+	if cmd.Replay != "" {
+		return true
+	}
+
+	// Verify Original file, size + pattern set for Generate mode
+	if cmd.GenMode {
+		if cmd.OriginalFile == "" {
+			errorLogger(utils.Failure(constants.GenerateFlagsMissingError))
+			return false
+		}
+
+		if cmd.GenSize <= 0 {
+			errorLogger(utils.Failure(constants.GenerateSizeInvalidError))
+			return false
+		}
+
+		if cmd.Pattern != constants.PatternRandom && cmd.Pattern != constants.PatternCompressible && cmd.Pattern != constants.PatternText {
+			errorLogger(utils.Failure(constants.InvalidPatternError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify Original file set for Simulate mode
+	if cmd.SimulateMode {
+		if cmd.OriginalFile == "" {
+			errorLogger(utils.Failure(constants.SimulateFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify Original + Updated files set for Selftest mode
+	if cmd.SelftestMode {
+		if cmd.OriginalFile == "" || cmd.UpdatedFile == "" {
+			errorLogger(utils.Failure(constants.SelftestFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify Signature + Updated files set for Analyze mode
+	if cmd.AnalyzeMode {
+		if cmd.SignatureFile == "" || cmd.UpdatedFile == "" {
+			errorLogger(utils.Failure(constants.AnalyzeFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify a single input file + output file set for Convert mode
+	if cmd.ConvertMode {
+		if (cmd.SignatureFile == "") == (cmd.DeltaFile == "") || cmd.ConvertTo == "" {
+			errorLogger(utils.Failure(constants.ConvertFlagsMissingError))
+			return false
+		}
+
+		if cmd.SignatureFile != "" && cmd.DeltaFormat == constants.DeltaFormatBsdiff {
+			errorLogger(utils.Failure(constants.BsdiffSignatureError))
+			return false
+		}
+
+		return verifyDeltaFormat(cmd)
+	}
+
+	// Verify Original + Updated files set for Similarity mode
+	if cmd.Similarity {
+		if cmd.OriginalFile == "" || cmd.UpdatedFile == "" {
+			errorLogger(utils.Failure(constants.SimilarityFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Batch mode reads its files from the `-filesFrom` list, verify filters applied to each pair + the format of each Delta file
+	if cmd.FilesFrom != "" {
+		if cmd.MinSize < 0 || cmd.MaxSize < 0 || (cmd.MaxSize > 0 && cmd.MinSize > cmd.MaxSize) {
+			errorLogger(utils.Failure(constants.InvalidSizeFilterError))
+			return false
+		}
+
+		if duration, err := time.ParseDuration(cmd.NewerThan); cmd.NewerThan != "" && (err != nil || duration <= 0) {
+			errorLogger(utils.Failure(constants.InvalidNewerThanError))
+			return false
+		}
+
+		if cmd.BatchWorkers < 0 {
+			errorLogger(utils.Failure(constants.InvalidBatchWorkersError))
+			return false
+		}
+
+		if !verifyDeltaFormat(cmd) {
+			return false
+		}
+
+		// Each Delta is written to a file in the same way as Delta mode (see writeDelta())
+		if cmd.DeltaFormat == constants.DeltaFormatJSONL || cmd.StreamDelta {
+			errorLogger(utils.Failure(constants.BatchStreamingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify Signature file set for Cat Signature mode
+	if cmd.CatSignature {
+		if cmd.SignatureFile == "" {
+			errorLogger(utils.Failure(constants.CatSignatureFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify Signature file set for Stats mode
+	if cmd.StatsMode {
+		if cmd.SignatureFile == "" {
+			errorLogger(utils.Failure(constants.StatsFlagsMissingError))
+			return false
+		}
+
+		return true
+	}
+
+	// Verify git's diff driver arguments provided for Git diff driver mode
+	if cmd.GitDiffDriver {
+		if len(cmd.Args) != gitDiffDriverArgs {
+			errorLogger(utils.Failure(constants.GitDiffDriverArgsError))
+			return false
+		}
+
+		return true
+	}
+
 	// Verify files set for Signature mode
 	if cmd.SignatureMode && (cmd.OriginalFile == "" || cmd.SignatureFile == "") {
-		logger(constants.SignatureFlagsMissingError, true)
+		errorLogger(utils.Failure(constants.SignatureFlagsMissingError))
+		return false
+	}
+
+	// Verify Signature pruning options for Signature mode
+	if cmd.SignatureMode && (cmd.Stride < 0 || cmd.MaxEntries < 0) {
+		errorLogger(utils.Failure(constants.InvalidSignaturePruningError))
+		return false
+	}
+
+	// Verify sparse sampling interval for Signature mode
+	if cmd.SignatureMode && cmd.Sparse < 0 {
+		errorLogger(utils.Failure(constants.InvalidSparseError))
+		return false
+	}
+
+	// Verify Signature file format for Signature mode
+	if cmd.SignatureMode && !verifyDeltaFormat(cmd) {
+		return false
+	}
+
+	// Verify Signature can be streamed (EG gob Signature file, without pruning + sampling which need the full Signature)
+	if cmd.SignatureMode && cmd.StreamSig && (cmd.DeltaFormat == constants.DeltaFormatRdiff || (cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob) || cmd.Sparse > 1 || cmd.Stride > 1 || cmd.MaxEntries > 0) {
+		errorLogger(utils.Failure(constants.StreamSignatureFlagsError))
 		return false
 	}
 
 	// Verify files set for Delta mode
 	if cmd.DeltaMode {
+		if cmd.MinSimilarity < 0 || cmd.MinSimilarity > 100 {
+			errorLogger(utils.Failure(constants.InvalidMinSimilarityError))
+			return false
+		}
+
+		// Fine chunk size must not exceed the chunk size of the Signature
+		chunkSize := rolling.DefaultChunkSize
+		if cmd.Chunk != 0 {
+			chunkSize = cmd.Chunk
+		}
+
+		if cmd.FineChunk < 0 || cmd.FineChunk > chunkSize {
+			errorLogger(utils.Failure(constants.InvalidChunkSizeError))
+			return false
+		}
+
+		if !verifyDeltaFormat(cmd) {
+			return false
+		}
+
+		if cmd.Range != "" && (cmd.DeltaFormat == constants.DeltaFormatJSONL || cmd.StreamDelta) {
+			errorLogger(utils.Failure(constants.RangeStreamingError))
+			return false
+		} else if _, _, err := utils.ParseRange(cmd.Range); cmd.Range != "" && err != nil {
+			errorLogger(utils.Failure(err.Error()))
+			return false
+		}
+
 		if cmd.SignatureMode && (cmd.UpdatedFile == "" || cmd.DeltaFile == "") {
-			logger(constants.SignatureDeltaFlagsMissingError, true)
+			errorLogger(utils.Failure(constants.SignatureDeltaFlagsMissingError))
 			return false
 		} else if !cmd.SignatureMode && (cmd.SignatureFile == "" || cmd.UpdatedFile == "" || cmd.DeltaFile == "") {
-			logger(constants.DeltaFlagsMissingError, true)
+			errorLogger(utils.Failure(constants.DeltaFlagsMissingError))
+			return false
+		}
+	}
+
+	// Verify files set for Patch mode (Delta is read back from the Delta file, or from stdin when not generated by Delta mode)
+	if cmd.PatchMode {
+		if cmd.OriginalFile == "" || cmd.DeltaFile == "" || (cmd.DeltaMode && cmd.DeltaFile == deltaStdout) || cmd.OutputFile == "" {
+			errorLogger(utils.Failure(constants.PatchFlagsMissingError))
 			return false
 		}
+
+		// Verifying matched blocks needs the Signature, which is only in memory when Signature or Delta mode set
+		if !cmd.SignatureMode && !cmd.DeltaMode && cmd.SignatureFile == "" && (cmd.Strict || cmd.PatchReport != "" || cmd.AuditLog != "") {
+			errorLogger(utils.Failure(constants.PatchSignatureMissingError))
+			return false
+		}
+	}
+
+	// Verify Delta file is written by Delta mode for the remote host to patch (the patched file is written on the remote host instead)
+	if cmd.Remote != "" && (!cmd.DeltaMode || cmd.DeltaFile == deltaStdout || cmd.OutputFile == "" || cmd.PatchMode) {
+		errorLogger(utils.Failure(constants.RemoteFlagsError))
+		return false
 	}
 
 	return true
@@ -3,12 +3,94 @@ package cmd
 import (
 	"testing"
 
+	"github.com/curtismenmuir/go-file-diff/constants"
 	"github.com/curtismenmuir/go-file-diff/models"
 	"github.com/stretchr/testify/require"
 )
 
 const file string = "some-file.txt"
 
+func TestInferMode(t *testing.T) {
+	t.Run("should enable Signature mode when Original + Signature files provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{OriginalFile: file, SignatureFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, true, result.SignatureMode)
+		require.Equal(t, false, result.DeltaMode)
+	})
+
+	t.Run("should enable Delta mode when Signature + Updated + Delta files provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, false, result.SignatureMode)
+		require.Equal(t, true, result.DeltaMode)
+	})
+
+	t.Run("should enable Signature + Delta modes when all files provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{OriginalFile: file, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, true, result.SignatureMode)
+		require.Equal(t, true, result.DeltaMode)
+	})
+
+	t.Run("should not enable any mode when files are incomplete", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureFile: file, UpdatedFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, cmd, result)
+	})
+
+	t.Run("should enable Signature, Delta + Patch modes when all files + output file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{OriginalFile: file, SignatureFile: file, UpdatedFile: file, DeltaFile: file, OutputFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, true, result.SignatureMode)
+		require.Equal(t, true, result.DeltaMode)
+		require.Equal(t, true, result.PatchMode)
+	})
+
+	t.Run("should enable only Patch mode when Original + Delta + output files provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{OriginalFile: file, DeltaFile: file, OutputFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, false, result.SignatureMode)
+		require.Equal(t, false, result.DeltaMode)
+		require.Equal(t, true, result.PatchMode)
+	})
+
+	t.Run("should not infer modes when mode flag explicitly set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SelftestMode: true, OriginalFile: file, SignatureFile: file, UpdatedFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, cmd, result)
+	})
+
+	t.Run("should not infer modes when Batch mode enabled", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{FilesFrom: "-", OriginalFile: file, SignatureFile: file}
+		// Run
+		result := inferMode(cmd)
+		// Verify
+		require.Equal(t, cmd, result)
+	})
+}
+
 func TestParseCMD(t *testing.T) {
 	t.Run("should return correct types for CMD args", func(t *testing.T) {
 		// Mock
@@ -22,6 +104,25 @@ func TestParseCMD(t *testing.T) {
 			return &result
 		}
 
+		defineInt = func(name string, value int, usage string) *int {
+			result := 2
+			return &result
+		}
+
+		defineInt64 = func(name string, value int64, usage string) *int64 {
+			result := int64(3)
+			return &result
+		}
+
+		defineFloat = func(name string, value float64, usage string) *float64 {
+			result := 0.5
+			return &result
+		}
+
+		getArgs = func() []string {
+			return []string{file}
+		}
+
 		// Run
 		cmd := ParseCMD()
 		// Verify
@@ -32,6 +133,74 @@ func TestParseCMD(t *testing.T) {
 		require.Equal(t, file, cmd.SignatureFile)
 		require.Equal(t, file, cmd.UpdatedFile)
 		require.Equal(t, file, cmd.DeltaFile)
+		require.Equal(t, file, cmd.SummaryJSON)
+		require.Equal(t, file, cmd.StrongHash)
+		require.Equal(t, file, cmd.WeakHash)
+		require.Equal(t, true, cmd.BenchMode)
+		require.Equal(t, 2, cmd.BenchSize)
+		require.Equal(t, true, cmd.SimulateMode)
+		require.Equal(t, 2, cmd.Simulations)
+		require.Equal(t, models.Mutations{Insertions: 2, Deletions: 2, Moves: 2, MaxSize: 2}, cmd.Mutations)
+		require.Equal(t, int64(3), cmd.Seed)
+		require.Equal(t, true, cmd.SelftestMode)
+		require.Equal(t, true, cmd.Yes)
+		require.Equal(t, true, cmd.NoColor)
+		require.Equal(t, true, cmd.Progress)
+		require.Equal(t, true, cmd.Quiet)
+		require.Equal(t, 2, cmd.LogEvery)
+		require.Equal(t, 2, cmd.LogRate)
+		require.Equal(t, true, cmd.GitDiffDriver)
+		require.Equal(t, true, cmd.Strict)
+		require.Equal(t, true, cmd.AnalyzeMode)
+		require.Equal(t, true, cmd.Background)
+		require.Equal(t, file, cmd.DeltaCache)
+		require.Equal(t, true, cmd.StatsMode)
+		require.Equal(t, file, cmd.DeltaFormat)
+		require.Equal(t, file, cmd.Encoding)
+		require.Equal(t, true, cmd.StreamDelta)
+		require.Equal(t, true, cmd.StreamSig)
+		require.Equal(t, file, cmd.Compress)
+		require.Equal(t, file, cmd.FilesFrom)
+		require.Equal(t, file, cmd.PatchReport)
+		require.Equal(t, true, cmd.CatSignature)
+		require.Equal(t, file, cmd.DirMode)
+		require.Equal(t, file, cmd.FileMode)
+		require.Equal(t, true, cmd.KeepPartial)
+		require.Equal(t, true, cmd.FullCopy)
+		require.Equal(t, 2, cmd.MinSimilarity)
+		require.Equal(t, true, cmd.Similarity)
+		require.Equal(t, file, cmd.LegacyHash)
+		require.Equal(t, true, cmd.ConvertMode)
+		require.Equal(t, file, cmd.ConvertTo)
+		require.Equal(t, 2, cmd.ReadRetries)
+		require.Equal(t, 2, cmd.Workers)
+		require.Equal(t, true, cmd.MemStats)
+		require.Equal(t, file, cmd.Range)
+		require.Equal(t, int64(3), cmd.MinSize)
+		require.Equal(t, int64(3), cmd.MaxSize)
+		require.Equal(t, file, cmd.NewerThan)
+		require.Equal(t, 2, cmd.BatchWorkers)
+		require.Equal(t, true, cmd.GenMode)
+		require.Equal(t, int64(3), cmd.GenSize)
+		require.Equal(t, file, cmd.Pattern)
+		require.Equal(t, 0.5, cmd.Chaos)
+		require.Equal(t, file, cmd.Capture)
+		require.Equal(t, int64(3), cmd.CaptureLimit)
+		require.Equal(t, true, cmd.CaptureHashes)
+		require.Equal(t, file, cmd.Replay)
+		require.Equal(t, file, cmd.AuditLog)
+		require.Equal(t, 2, cmd.Stride)
+		require.Equal(t, int64(3), cmd.MaxEntries)
+		require.Equal(t, 2, cmd.Sparse)
+		require.Equal(t, true, cmd.Refine)
+		require.Equal(t, int64(3), cmd.FineChunk)
+		require.Equal(t, true, cmd.Doctor)
+		require.Equal(t, true, cmd.NoSpaceCheck)
+		require.Equal(t, file, cmd.TempDirs)
+		require.Equal(t, true, cmd.PatchMode)
+		require.Equal(t, file, cmd.OutputFile)
+		require.Equal(t, int64(3), cmd.Chunk)
+		require.Equal(t, []string{file}, cmd.Args)
 	})
 }
 
@@ -233,4 +402,835 @@ func TestVerifyCMD(t *testing.T) {
 		// Verify
 		require.Equal(t, false, result)
 	})
+	t.Run("should return true when bench mode set without Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			BenchMode: true,
+			BenchSize: 1,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true when bench mode set with Original file and no bench size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			BenchMode:    true,
+			BenchSize:    0,
+			OriginalFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when bench mode set without Original file or bench size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			BenchMode: true,
+			BenchSize: 0,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+	t.Run("should return true when replay mode set with Capture file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			Replay: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when capture set with invalid capture limit", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			OriginalFile:  file,
+			SignatureFile: file,
+			Capture:       file,
+			CaptureLimit:  0,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when signature mode set with negative pruning or sparse options", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{SignatureMode: true, OriginalFile: file, SignatureFile: file, Stride: -1},
+			{SignatureMode: true, OriginalFile: file, SignatureFile: file, MaxEntries: -1},
+			{SignatureMode: true, OriginalFile: file, SignatureFile: file, Sparse: -1},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when signature mode set with stream signature", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when signature mode set with stream signature + sparse, pruning, rdiff format or an encoding other than gob", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file, Sparse: 4},
+			{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file, Stride: 2},
+			{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file, MaxEntries: 1024},
+			{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file, DeltaFormat: constants.DeltaFormatRdiff},
+			{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file, Encoding: constants.EncodingCBOR},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when generate mode set with Original file, size + pattern", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			GenMode:      true,
+			OriginalFile: file,
+			UpdatedFile:  file,
+			GenSize:      1024,
+			Pattern:      constants.PatternText,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when generate mode set without Original file, a valid size or a valid pattern", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{GenMode: true, GenSize: 1024, Pattern: constants.PatternRandom},
+			{GenMode: true, OriginalFile: file, GenSize: 0, Pattern: constants.PatternRandom},
+			{GenMode: true, OriginalFile: file, GenSize: 1024, Pattern: "zeros"},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when simulate mode set with Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+			OriginalFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when simulate mode set without Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+	t.Run("should return true when selftest mode set with Original + Updated files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SelftestMode: true,
+			OriginalFile: file,
+			UpdatedFile:  file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when selftest mode set without Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SelftestMode: true,
+			OriginalFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+	t.Run("should return true when git diff driver mode set with git's 7 arguments", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			GitDiffDriver: true,
+			Args:          []string{file, file, "abc1234", "100644", file, "def5678", "100644"},
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when git diff driver mode set without git's 7 arguments", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			GitDiffDriver: true,
+			Args:          []string{file},
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+	t.Run("should return true when analyze mode set with Signature + Updated files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			AnalyzeMode:   true,
+			SignatureFile: file,
+			UpdatedFile:   file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when analyze mode set without Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			AnalyzeMode: true,
+			UpdatedFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when delta mode set with JSON Lines format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			DeltaFormat:   constants.DeltaFormatJSONL,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true when signature + delta mode set with rdiff format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			DeltaMode:     true,
+			DeltaFormat:   constants.DeltaFormatRdiff,
+			OriginalFile:  file,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when signature mode set with unknown format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			DeltaFormat:   "xml",
+			OriginalFile:  file,
+			SignatureFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when delta mode set with unknown format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			DeltaFormat:   "xml",
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when signature + delta mode set with json encoding", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			DeltaMode:     true,
+			Encoding:      constants.EncodingJSON,
+			OriginalFile:  file,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when delta mode set with unknown encoding", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			Encoding:      "xml",
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when delta mode set with json encoding + a format other than gob", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			DeltaFormat:   constants.DeltaFormatRdiff,
+			Encoding:      constants.EncodingJSON,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when delta mode set with stream delta + gob format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, StreamDelta: true, DeltaFormat: constants.DeltaFormatGob, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when delta mode set with stream delta + a format or encoding other than gob", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{DeltaMode: true, StreamDelta: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, StreamDelta: true, Encoding: constants.EncodingCBOR, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, StreamDelta: true, Range: "1024:4096", SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when delta mode set with compression + gob format (including streamed Delta)", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{DeltaMode: true, Compress: constants.CompressZstd, DeltaFormat: constants.DeltaFormatGob, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, Compress: constants.CompressGzip, StreamDelta: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, Compress: constants.CompressNone, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, true, result)
+		}
+	})
+
+	t.Run("should return false when delta mode set with compression + a format or encoding other than gob", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{DeltaMode: true, Compress: constants.CompressZstd, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, Compress: constants.CompressGzip, DeltaFormat: constants.DeltaFormatBsdiff, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+			{DeltaMode: true, Compress: constants.CompressGzip, Encoding: constants.EncodingJSON, SignatureFile: file, UpdatedFile: file, DeltaFile: file},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return false when delta mode set with min similarity above 100", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			MinSimilarity: 101,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when delta mode set with fine chunk size outside 0-16", func(t *testing.T) {
+		for _, fineChunk := range []int64{-1, 17} {
+			// Setup
+			cmd := models.CMD{DeltaMode: true, FineChunk: fineChunk, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when delta mode set with fine chunk size up to the chunk size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, Chunk: 64, FineChunk: 64, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true when delta mode set with a valid range", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			Range:         "1024:4096",
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when delta mode set with an invalid range", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			Range:         "4096:1024",
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when delta mode set with a range + JSON Lines format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			DeltaMode:     true,
+			Range:         "1024:4096",
+			DeltaFormat:   constants.DeltaFormatJSONL,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when convert mode set with Signature file + output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			ConvertMode:   true,
+			SignatureFile: file,
+			ConvertTo:     file,
+			DeltaFormat:   constants.DeltaFormatJSONL,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when convert mode set with Signature file + bsdiff format", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			ConvertMode:   true,
+			SignatureFile: file,
+			ConvertTo:     file,
+			DeltaFormat:   constants.DeltaFormatBsdiff,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when convert mode set with both Signature + Delta files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			ConvertMode:   true,
+			SignatureFile: file,
+			DeltaFile:     file,
+			ConvertTo:     file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when convert mode set without output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			ConvertMode: true,
+			DeltaFile:   file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when similarity mode set with Original + Updated files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			Similarity:   true,
+			OriginalFile: file,
+			UpdatedFile:  file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when similarity mode set without Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			Similarity:   true,
+			OriginalFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when batch mode set without other files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			FilesFrom: "-",
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true when batch mode set with valid filters", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			FilesFrom:    "-",
+			MinSize:      1024,
+			MaxSize:      4096,
+			NewerThan:    "72h",
+			BatchWorkers: 4,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when batch mode set with invalid size filters", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{FilesFrom: "-", MinSize: -1},
+			{FilesFrom: "-", MaxSize: -1},
+			{FilesFrom: "-", MinSize: 4096, MaxSize: 1024},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return false when batch mode set with invalid newer than duration", func(t *testing.T) {
+		for _, newerThan := range []string{"3 days", "-1h", "0s"} {
+			// Setup
+			cmd := models.CMD{
+				FilesFrom: "-",
+				NewerThan: newerThan,
+			}
+
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
+
+	t.Run("should return true when batch mode set with a supported Delta format, encoding + compression", func(t *testing.T) {
+		for _, cmd := range []models.CMD{
+			{FilesFrom: "-", DeltaFormat: constants.DeltaFormatRdiff},
+			{FilesFrom: "-", Encoding: constants.EncodingCBOR},
+			{FilesFrom: "-", Compress: constants.CompressZstd},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, true, result)
+		}
+	})
+
+	t.Run("should return false when batch mode set with an unsupported Delta format, encoding or compression", func(t *testing.T) {
+		// Setup
+		logged := []string{}
+		defaultErrorLogger := errorLogger
+		// Mock
+		errorLogger = func(message string) {
+			logged = append(logged, message)
+		}
+
+		for _, cmd := range []models.CMD{
+			{FilesFrom: "-", DeltaFormat: "some-format"},
+			{FilesFrom: "-", Encoding: constants.EncodingJSON, DeltaFormat: constants.DeltaFormatRdiff},
+			{FilesFrom: "-", Compress: constants.CompressZstd, Encoding: constants.EncodingJSON},
+			{FilesFrom: "-", DeltaFormat: constants.DeltaFormatJSONL},
+			{FilesFrom: "-", StreamDelta: true},
+		} {
+			// Run
+			result := VerifyCMD(cmd)
+			// Verify
+			require.Equal(t, false, result)
+		}
+
+		require.Contains(t, logged[len(logged)-1], constants.BatchStreamingError)
+		// Mock
+		errorLogger = defaultErrorLogger
+	})
+
+	t.Run("should return false when batch mode set with negative batch workers", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			FilesFrom:    "-",
+			BatchWorkers: -1,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when cat signature mode set with Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			CatSignature:  true,
+			SignatureFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when cat signature mode set without Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			CatSignature: true,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when stats mode set with Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			StatsMode:     true,
+			SignatureFile: file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when stats mode set without Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			StatsMode: true,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when patch mode set with Original, Delta + output files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			PatchMode:    true,
+			OriginalFile: file,
+			DeltaFile:    file,
+			OutputFile:   file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when patch mode set without output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			PatchMode:    true,
+			OriginalFile: file,
+			DeltaFile:    file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when signature, delta & patch modes set with Delta streamed to stdout", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			DeltaMode:     true,
+			PatchMode:     true,
+			DeltaFormat:   constants.DeltaFormatJSONL,
+			OriginalFile:  file,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     "-",
+			OutputFile:    file,
+		}
+
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when only patch mode set with -strict but without Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{PatchMode: true, Strict: true, OriginalFile: file, DeltaFile: file, OutputFile: file}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, false, result)
+		// Setup
+		cmd.SignatureFile = file
+		// Run
+		result = VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+	t.Run("should return true when only patch mode set with Delta read from stdin", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{PatchMode: true, OriginalFile: file, DeltaFile: "-", OutputFile: file}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true when delta mode set with -remote + Output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, OutputFile: file, Remote: "deploy@example.com:/srv/app.bin"}
+		// Run
+		result := VerifyCMD(cmd)
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return false when -remote set without Delta mode, Delta file or Output file, or with Patch mode", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, OutputFile: file, Remote: "deploy@example.com:/srv/app.bin"}
+		for _, update := range []func(cmd models.CMD) models.CMD{
+			func(cmd models.CMD) models.CMD {
+				cmd.DeltaMode, cmd.SignatureMode = false, true
+				cmd.OriginalFile = file
+				return cmd
+			},
+			func(cmd models.CMD) models.CMD {
+				cmd.DeltaFile, cmd.DeltaFormat = "-", constants.DeltaFormatJSONL
+				return cmd
+			},
+			func(cmd models.CMD) models.CMD { cmd.OutputFile = ""; return cmd },
+			func(cmd models.CMD) models.CMD { cmd.PatchMode, cmd.OriginalFile = true, file; return cmd },
+		} {
+			// Run
+			result := VerifyCMD(update(cmd))
+			// Verify
+			require.Equal(t, false, result)
+		}
+	})
 }
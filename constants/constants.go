@@ -1,8 +1,38 @@
 package constants
 
+// Delta formats
+const (
+	DeltaFormatGob    string = "gob"    // Binary Delta file (default)
+	DeltaFormatJSONL  string = "jsonl"  // One JSON object per Delta operation
+	DeltaFormatRdiff  string = "rdiff"  // librsync rdiff Signature + Delta files
+	DeltaFormatBsdiff string = "bsdiff" // bsdiff 4.x patch files (Delta only)
+)
+
+// Signature + Delta file encodings (for the gob format)
+const (
+	EncodingGob      string = "gob"      // Binary Signature + Delta files (default)
+	EncodingJSON     string = "json"     // JSON Signature + Delta files (EG inspected or consumed by non-Go tooling)
+	EncodingProtobuf string = "protobuf" // Protobuf Signature + Delta files (EG consumed by other languages, see files/filediff.proto)
+	EncodingCBOR     string = "cbor"     // Compact CBOR Signature + Delta files (EG consumed by other languages)
+)
+
+// Signature + Delta file compression methods (for the gob format)
+const (
+	CompressNone string = "none" // Uncompressed Signature + Delta files (default)
+	CompressGzip string = "gzip" // gzip compressed Signature + Delta files (EG widely supported)
+	CompressZstd string = "zstd" // Zstandard compressed Signature + Delta files (EG faster, with a better ratio)
+)
+
+// Test data patterns
+const (
+	PatternRandom       string = "random"       // Pseudo-random bytes (incompressible)
+	PatternCompressible string = "compressible" // Runs of repeated bytes
+	PatternText         string = "text"         // Lines of space separated words
+)
+
 // Error messages
 const (
-	ModeFlagMissingError                 string = "Error: Must set at least one mode"
+	ModeFlagMissingError                 string = "Error: Must set at least one mode (or provide the files required by Signature/Delta mode)"
 	SignatureFlagsMissingError           string = "Error: Must provide Original & Signature files when enabling Signature mode"
 	DeltaFlagsMissingError               string = "Error: Must provide Signature, Updated & Delta files when enabling Delta mode"
 	SignatureDeltaFlagsMissingError      string = "Error: Must provide Updated & Delta files when enabling Signature & Delta modes"
@@ -31,4 +61,97 @@ const (
 	DeltaFileDoesNotExistError           string = "Error: Delta file does not exist"
 	UnableToOpenDeltaFileError           string = "Error: Unable to open Delta file"
 	UnableToDecodeDeltaFromFileError     string = "Error: Unable to decode Delta from file"
+	BenchSizeInvalidError                string = "Error: Benchmark size must be greater than 0 when no Original file provided"
+	UnableToReadOriginalFileError        string = "Error: Unable to read Original file"
+	InvalidDeltaError                    string = "Error: Delta contains gaps or overlapping blocks"
+	UnableToWriteOutputFileError         string = "Error: Unable to write to Output file"
+	SimulateFlagsMissingError            string = "Error: Must provide Original file when enabling Simulate mode"
+	SimulationFailedError                string = "Error: Simulation failed, patched file does not match Updated file"
+	SelftestFlagsMissingError            string = "Error: Must provide Original + Updated files when enabling Selftest mode"
+	SelftestFailedError                  string = "Error: Self-test failed"
+	SelftestHashMismatchError            string = "Error: Reconstructed file does not match Updated file"
+	UnableToCreateTempFolderError        string = "Error: Unable to create temp folder"
+	OverwriteDeclinedError               string = "Error: Output file already exists, overwrite declined"
+	UnableToWriteSummaryError            string = "Error: Unable to write run summary"
+	InvalidHashError                     string = "Error: Hash algorithm must have a name + implementation"
+	HashAlreadyRegisteredError           string = "Error: Hash algorithm already registered"
+	HashNotRegisteredError               string = "Error: Hash algorithm not registered"
+	OriginalFileChangedError             string = "Error: Original file does not match Signature (EG modified since Signature was generated)"
+	InvalidArgumentsError                string = "Error: Invalid number of arguments"
+	UnableToLowerPriorityError           string = "Warning: Unable to lower process priority, continuing at normal priority"
+	UnableToWriteDeltaCacheError         string = "Warning: Unable to write Delta to cache, continuing without caching"
+	UnableToReadFilesFromError           string = "Error: Unable to read list of file pairs"
+	BatchPairIncompleteError             string = "Error: List of file pairs must contain an Updated file for every Original file"
+	BatchFailedError                     string = "Error: Batch failed for one or more file pairs"
+	UnableToWritePatchReportError        string = "Error: Unable to write patch report"
+	InvalidOpKindError                   string = "Error: Delta operation kind must be one of: copy, literal"
+	InvalidDeltaFormatError              string = "Error: Delta format must be one of: gob, jsonl, rdiff, bsdiff"
+	DeltaLargerThanUpdatedFileError      string = "Warning: Delta is larger than Updated file (use -fallbackFullCopy to ship the full file instead)"
+	UnableToReadUpdatedFileError         string = "Error: Unable to read Updated file"
+	SimilarityBelowThresholdError        string = "Error: Updated file is below the minimum similarity"
+	InvalidMinSimilarityError            string = "Error: Minimum similarity must be a percentage between 0 and 100"
+	InvalidPermissionsError              string = "Error: Permissions must be an octal mode between 0000 and 0777 (EG 0750)"
+	CatSignatureFlagsMissingError        string = "Error: Must provide Signature file when enabling Cat Signature mode"
+	StatsFlagsMissingError               string = "Error: Must provide Signature file when enabling Stats mode"
+	ConvertFlagsMissingError             string = "Error: Must provide a Signature or Delta file (not both) + output file when enabling Convert mode"
+	SimilarityFlagsMissingError          string = "Error: Must provide Original + Updated files when enabling Similarity mode"
+	AnalyzeFlagsMissingError             string = "Error: Must provide Signature + Updated files when enabling Analyze mode"
+	ChecksumMismatchError                string = "Error: File checksum does not match its contents (EG corrupted or incompletely transferred)"
+	InvalidReadRetriesError              string = "Error: Read retries must be 0 or greater"
+	InvalidSignatureWorkersError         string = "Error: Signature workers must be 0 or greater"
+	InvalidRangeError                    string = "Error: Range must be in the format start:end, where 0 <= start < end (EG 1024:4096)"
+	RangeOutsideFilesError               string = "Error: Range must be within the Updated file, and bytes outside the range must be present in the Original file"
+	RangeStreamingError                  string = "Error: Range can not be used when streaming the Delta (EG -format=jsonl or -streamDelta)"
+	InvalidSizeFilterError               string = "Error: Size filters must be 0 or greater, and -minSize must not exceed -maxSize"
+	InvalidNewerThanError                string = "Error: Newer than must be a positive duration (EG 72h)"
+	InvalidBatchWorkersError             string = "Error: Batch workers must be 0 or greater"
+	BatchStreamingError                  string = "Error: Batch mode can not stream Deltas (EG -format=jsonl or -streamDelta), as each Delta is written to a file"
+	GenerateFlagsMissingError            string = "Error: Must provide Original file (+ optional Updated file) when enabling Generate mode"
+	GenerateSizeInvalidError             string = "Error: Generate size must be greater than 0"
+	InvalidPatternError                  string = "Error: Pattern must be one of: random, compressible, text"
+	InvalidChaosRateError                string = "Error: Chaos rate must be between 0 and 1 (EG 0.01)"
+	ChaosInjectedError                   string = "Error: Injected fault (chaos mode)"
+	CaptureFileDoesNotExistError         string = "Error: Capture file does not exist"
+	UnableToOpenCaptureFileError         string = "Error: Unable to open Capture file"
+	UnableToDecodeCaptureFromFileError   string = "Error: Unable to decode Capture from file"
+	UnableToWriteCaptureFileError        string = "Error: Unable to write Capture file"
+	InvalidCaptureLimitError             string = "Error: Capture limit must be greater than 0"
+	CaptureHasNoDataError                string = "Error: Capture must contain the data of an Original + Updated file to replay (EG captured without -captureHashOnly)"
+	ReplayFailedError                    string = "Error: Replayed Delta did not reproduce the captured Updated file"
+	UnableToWriteAuditLogError           string = "Error: Unable to write audit log"
+	InvalidSignaturePruningError         string = "Error: Signature stride + max Signature entries must not be negative"
+	InvalidSparseError                   string = "Error: Sparse sampling interval must not be negative"
+	InvalidChunkSizeError                string = "Error: Fine chunk size must be between 1 and the chunk size (default 16)"
+	UnableToCheckFreeSpaceError          string = "Error: Unable to check free space on this platform"
+	InsufficientSpaceError               string = "Error: Not enough free space to write output files"
+	UnableToReadSnapshotError            string = "Error: Unable to read Snapshot history"
+	UnableToWriteSnapshotError           string = "Error: Unable to write Snapshot history"
+	SnapshotVersionNotFoundError         string = "Error: Snapshot version not found"
+	SnapshotChecksumMismatchError        string = "Error: Restored Snapshot does not match the checksum of the version (history may be corrupted)"
+	DoctorFoundProblemsError             string = "Error: Doctor found problems which would cause the run to fail (see findings above)"
+	PatchFlagsMissingError               string = "Error: Must provide Original, Delta (not streamed to stdout) & Output files when enabling Patch mode"
+	PatchSignatureMissingError           string = "Error: Must provide Signature file for -strict, -patchReport or -auditLog when enabling Patch mode without Signature or Delta mode"
+	ChunkSizeOutOfRangeError             string = "Error: Chunk size must be between 1 and 131072 (bytes)"
+	ChunkSizeMismatchError               string = "Error: Chunk size does not match the chunk size recorded in the Signature file"
+	WeakHashMismatchError                string = "Error: Weak hash does not match the Weak hash recorded in the Signature file"
+	RdiffUnsupportedHashError            string = "Error: rdiff Signatures require the rollsum or rdiff-rabin-karp Weak hash, and the md4 or blake2b Strong hash"
+	RdiffMissingBlockError               string = "Error: rdiff Signatures require a block at every chunk of the Original file (EG -sparse set to the chunk size, without pruning)"
+	InvalidBsdiffPatchError              string = "Error: bsdiff patch is corrupt (controls read beyond the diff or extra blocks, or the size of the Updated file)"
+	BsdiffSignatureError                 string = "Error: bsdiff format only supports Delta files"
+	InvalidEncodingError                 string = "Error: Encoding must be one of: gob, json, protobuf, cbor"
+	EncodingFormatError                  string = "Error: Encodings other than gob are only supported with the gob format (EG -encoding=json -format=gob)"
+	StreamDeltaFormatError               string = "Error: Streaming the Delta is only supported with the gob format + encoding (use -format=jsonl to stream JSON Lines)"
+	StreamSignatureFlagsError            string = "Error: Streaming the Signature is only supported with the gob format + encoding, without -sparse, -signatureStride or -maxSignatureEntries"
+	InvalidCompressionError              string = "Error: Compression must be one of: none, gzip, zstd"
+	CompressionFormatError               string = "Error: Compression is only supported for Signature + Delta files with the gob format + encoding"
+	PatchedFileHashMismatchError         string = "Error: Patched file does not match the SHA-256 of the Updated file recorded in the Delta (EG corrupted Delta, or a different Original file)"
+	GitDiffDriverArgsError               string = "Error: Git diff driver mode requires 7 arguments (path old-file old-hex old-mode new-file new-hex new-mode)"
+	UnableToReadDeltaStdinError          string = "Error: Unable to read Delta from stdin"
+	RemoteFlagsError                     string = "Error: Must provide Delta (not streamed to stdout) & Output files when enabling -remote, which can not be used with Patch mode"
+	InvalidRemoteError                   string = "Error: Remote must be in the format user@host[:port]:/path/to/original"
+	SSHAuthMissingError                  string = "Error: No SSH credentials found for -remote (set -sshKey, or run an SSH agent)"
+	UnableToReadSSHKeyError              string = "Error: Unable to read SSH private key"
+	UnableToReadKnownHostsError          string = "Error: Unable to read SSH known_hosts file"
+	UnableToConnectRemoteError           string = "Error: Unable to connect to remote host"
+	RemotePatchFailedError               string = "Error: Remote Patch mode failed (see remote output above)"
 )
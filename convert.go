@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+// signatureLine type.
+// This will contain a single Signature item (including candidates) along with the Weak hash it is indexed by, encoded as one JSON Lines entry.
+// EG: {"weak":123,"hash":"some-strong-hash","head":0,"tail":15}.
+type signatureLine struct {
+	Weak int64 `json:"weak"`
+	models.StrongSignature
+}
+
+// decodeDeltaJSONL() will decode a Delta from JSON Lines (one operation per line, see `models.Op`).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToDecodeDeltaFromFileError` when any line is not a valid operation.
+func decodeDeltaJSONL(data []byte) (models.Delta, error) {
+	delta := models.Delta{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var op models.Op
+		if err := decoder.Decode(&op); err == io.EOF {
+			return delta, nil
+		} else if err != nil {
+			return models.Delta{}, errors.New(constants.UnableToDecodeDeltaFromFileError)
+		}
+
+		delta[op.Position] = op.Block()
+	}
+}
+
+// decodeSignatureJSONL() will decode a Signature from JSON Lines (one Signature item per line, see `signatureLine`).
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, UnableToDecodeSignatureFromFileError` when any line is not a valid Signature item.
+func decodeSignatureJSONL(data []byte) (models.Signature, error) {
+	signature := models.Signature{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var line signatureLine
+		if err := decoder.Decode(&line); err == io.EOF {
+			return signature, nil
+		} else if err != nil {
+			return models.Signature{}, errors.New(constants.UnableToDecodeSignatureFromFileError)
+		}
+
+		signature[line.Weak] = line.StrongSignature
+	}
+}
+
+// encodeJSONL() will encode a Signature (one item per line, sorted by Weak hash) or a Delta (one operation per line, sorted by position) as JSON Lines.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to write to the provided writer.
+func encodeJSONL(model any, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	if delta, ok := model.(models.Delta); ok {
+		return delta.Ops(func(op models.Op) error {
+			return encoder.Encode(op)
+		})
+	}
+
+	signature := model.(models.Signature)
+	weakHashes := make([]int64, 0, len(signature))
+	for weakHash := range signature {
+		weakHashes = append(weakHashes, weakHash)
+	}
+
+	sort.Slice(weakHashes, func(i, j int) bool { return weakHashes[i] < weakHashes[j] })
+	for _, weakHash := range weakHashes {
+		if err := encoder.Encode(signatureLine{Weak: weakHash, StrongSignature: signature[weakHash]}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isJSONL() will check if file contents look like JSON Lines (EG first line is a JSON object), rather than a gob encoded file.
+func isJSONL(data []byte) bool {
+	line := bytes.TrimSpace(data)
+	if index := bytes.IndexByte(line, '\n'); index >= 0 {
+		line = line[:index]
+	}
+
+	return len(line) > 0 && line[0] == '{' && json.Valid(line)
+}
+
+// readConvertInput() will read the Signature or Delta file provided in CMD, detecting whether it is gob, JSON, JSON Lines, protobuf, CBOR or rdiff encoded.
+// Note: metadata will be recorded from gob, JSON, protobuf, CBOR + rdiff Signature files, otherwise the current settings will be used (EG `-chunk` + `-weakHash`).
+// Function returns `signature, metadata, nil` or `delta, emptyMetadata, nil` when successful.
+// Function returns `nil, emptyMetadata, error` when unable to read or decode the file.
+func readConvertInput(cmd models.CMD) (any, models.SignatureMetadata, error) {
+	path := cmd.DeltaFile
+	if cmd.SignatureFile != "" {
+		path = cmd.SignatureFile
+	}
+
+	data, err := readFile(path)
+	if err != nil && cmd.SignatureFile != "" {
+		return nil, models.SignatureMetadata{}, errors.New(constants.UnableToOpenSignatureFileError)
+	} else if err != nil {
+		return nil, models.SignatureMetadata{}, errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	var model any
+	metadata := models.SignatureMetadata{}
+	switch {
+	case cmd.SignatureFile != "" && isJSONL(data):
+		model, err = decodeSignatureJSONL(data)
+		metadata = sync.Metadata()
+	case cmd.SignatureFile != "":
+		model, metadata, err = openSignatureMeta(path, cmd.Verbose)
+	case isJSONL(data):
+		model, err = decodeDeltaJSONL(data)
+	default:
+		model, err = openDelta(path, cmd.Verbose)
+	}
+
+	if err != nil {
+		return nil, models.SignatureMetadata{}, err
+	}
+
+	return model, metadata, nil
+}
+
+// runConvert() will read a Signature or Delta file (gob, JSON, JSON Lines, protobuf, CBOR or rdiff), then write it in the format selected by `-format` (+ `-encoding`) to the `-convertTo` file in the Outputs folder.
+// This allows existing Signature + Delta files to be migrated when formats change.
+// Note: output will be written to a `.partial` file, which is renamed into place once complete.
+// Function returns `nil` when successful.
+// Function returns `OverwriteDeclinedError` when user declines overwriting an existing output file.
+// Function returns `UnableToCreateFileError` when unable to create the output file.
+// Function returns `UnableToWriteToFileError` when unable to write to the output file.
+// Function returns `error` when unable to read or decode the input file.
+func runConvert(cmd models.CMD) error {
+	model, metadata, err := readConvertInput(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cmd.DeltaFormat == constants.DeltaFormatRdiff {
+		model = rdiffModel(cmd, model, metadata)
+	} else if delta, isDelta := model.(models.Delta); isDelta && cmd.DeltaFormat == constants.DeltaFormatBsdiff {
+		model = files.BsdiffDelta(delta)
+	} else {
+		model = encodingModel(cmd, model, metadata)
+	}
+
+	if err = confirmOverwrite(cmd, cmd.ConvertTo); err != nil {
+		return err
+	}
+
+	if cmd.DeltaFormat != constants.DeltaFormatJSONL {
+		return writeStructToFile(model, cmd.ConvertTo)
+	}
+
+	file, err := createOutputFile(cmd.ConvertTo)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	failed := encodeJSONL(model, writer) != nil || writer.Flush() != nil
+	file.Close()
+	if err = finishPartial(outputPath(cmd.ConvertTo), failed); err != nil || failed {
+		return errors.New(constants.UnableToWriteToFileError)
+	}
+
+	logger(fmt.Sprintf("%s created: %s\n", cmd.ConvertTo, outputPath(cmd.ConvertTo)), true)
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+var convertDelta = models.Delta{
+	0:  models.Block{Head: 0, Tail: 2, IsModified: true, Value: []byte("new")},
+	3:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+	19: models.Block{Head: 19, Tail: 19, IsModified: true, Value: []byte("!")},
+}
+
+func TestDecodeDeltaJSONL(t *testing.T) {
+	t.Run("should return `delta, nil` matching the encoded Delta", func(t *testing.T) {
+		// Setup
+		out := bytes.Buffer{}
+		require.Equal(t, nil, encodeJSONL(convertDelta, &out))
+		// Run
+		delta, err := decodeDeltaJSONL(out.Bytes())
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, convertDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when line is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError)
+		// Run
+		delta, err := decodeDeltaJSONL([]byte("{\"kind\":\"copy\",\"position\":0,\"head\":0,\"tail\":15}\nnot json\n"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
+
+func TestDecodeSignatureJSONL(t *testing.T) {
+	t.Run("should return `signature, nil` matching the encoded Signature", func(t *testing.T) {
+		// Setup
+		out := bytes.Buffer{}
+		require.Equal(t, nil, encodeJSONL(testSignature, &out))
+		// Run
+		signature, err := decodeSignatureJSONL(out.Bytes())
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testSignature, signature)
+	})
+
+	t.Run("should return `emptySignature, UnableToDecodeSignatureFromFileError` when line is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError)
+		// Run
+		signature, err := decodeSignatureJSONL([]byte("{\"weak\":\"abc\"}\n"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+}
+
+func TestEncodeJSONL(t *testing.T) {
+	t.Run("should encode Signature items sorted by Weak hash", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{
+			7: models.StrongSignature{Hash: "bbb", Head: 16, Tail: 31},
+			3: models.StrongSignature{Hash: "aaa", Head: 0, Tail: 15},
+		}
+
+		out := bytes.Buffer{}
+		expected := "{\"weak\":3,\"hash\":\"aaa\",\"head\":0,\"tail\":15}\n{\"weak\":7,\"hash\":\"bbb\",\"head\":16,\"tail\":31}\n"
+		// Run
+		err := encodeJSONL(signature, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, out.String())
+	})
+}
+
+func TestIsJSONL(t *testing.T) {
+	t.Run("should return true when first line is a JSON object", func(t *testing.T) {
+		require.Equal(t, true, isJSONL([]byte("{\"kind\":\"copy\"}\n{\"kind\":")))
+	})
+
+	t.Run("should return false for gob encoded file", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		require.Equal(t, nil, files.WriteStructToPath(convertDelta, path))
+		data, _ := os.ReadFile(path)
+		// Run
+		result := isJSONL(data)
+		// Verify
+		require.Equal(t, false, result)
+	})
+}
+
+func TestRunConvert(t *testing.T) {
+	t.Run("should convert gob Delta file to JSON Lines", func(t *testing.T) {
+		// Setup
+		input := filepath.Join(t.TempDir(), "delta")
+		require.Equal(t, nil, files.WriteStructToPath(convertDelta, input))
+		output, err := os.CreateTemp(t.TempDir(), "delta-*.jsonl")
+		require.Equal(t, nil, err)
+		cmd := models.CMD{ConvertMode: true, DeltaFile: input, ConvertTo: file, DeltaFormat: constants.DeltaFormatJSONL, Yes: true}
+		finished := false
+		// Mock
+		readFile = os.ReadFile
+		openDelta = files.OpenDelta
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finishPartial = func(path string, failed bool) error {
+			finished = !failed
+			return nil
+		}
+
+		// Run
+		err = runConvert(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(output.Name())
+		delta, err := decodeDeltaJSONL(contents)
+		require.Equal(t, nil, err)
+		require.Equal(t, convertDelta, delta)
+	})
+
+	t.Run("should convert JSON Lines Signature file to gob", func(t *testing.T) {
+		// Setup
+		out := bytes.Buffer{}
+		require.Equal(t, nil, encodeJSONL(testSignature, &out))
+		input := filepath.Join(t.TempDir(), "signature.jsonl")
+		require.Equal(t, nil, os.WriteFile(input, out.Bytes(), 0o600))
+		cmd := models.CMD{ConvertMode: true, SignatureFile: input, ConvertTo: file, Yes: true}
+		var written any
+		// Mock
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		err := runConvert(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testSignature, written)
+	})
+
+	t.Run("should return `UnableToOpenSignatureFileError` when unable to read Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{ConvertMode: true, SignatureFile: filepath.Join(t.TempDir(), "missing"), ConvertTo: file, Yes: true}
+		expectedError := errors.New(constants.UnableToOpenSignatureFileError)
+		// Run
+		err := runConvert(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToWriteToFileError` when unable to rename output into place", func(t *testing.T) {
+		// Setup
+		input := filepath.Join(t.TempDir(), "delta")
+		require.Equal(t, nil, files.WriteStructToPath(convertDelta, input))
+		output, err := os.CreateTemp(t.TempDir(), "delta-*.jsonl")
+		require.Equal(t, nil, err)
+		cmd := models.CMD{ConvertMode: true, DeltaFile: input, ConvertTo: file, DeltaFormat: constants.DeltaFormatJSONL, Yes: true}
+		expectedError := errors.New(constants.UnableToWriteToFileError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finishPartial = func(path string, failed bool) error {
+			return errors.New(constants.UnableToWriteToFileError)
+		}
+
+		// Run
+		err = runConvert(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
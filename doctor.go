@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const (
+	findingPass string = "PASS"
+	findingWarn string = "WARN"
+	findingFail string = "FAIL"
+)
+
+var (
+	openInput  = os.Open
+	createTemp = os.CreateTemp
+	removeFile = os.Remove
+)
+
+// finding type.
+// This will describe the result of a single doctor check, with an actionable message when the check does not pass.
+// EG: finding{level: "FAIL", message: "Inputs: -original `a.txt` does not exist"}.
+type finding struct {
+	level   string
+	message string
+}
+
+// runDoctor() will check the environment + configuration of a run (EG `-doctor -signatureMode -deltaMode ...`) without running it, logging a finding for each check.
+// Checks cover conflicting flags, readable input files, a writable Outputs folder, and free space for output + temp files.
+// Function returns `findings, nil` when no check fails (findings may contain warnings).
+// Function returns `findings, DoctorFoundProblemsError` when any check fails.
+func runDoctor(cmd models.CMD) ([]finding, error) {
+	findings := checkFlags(cmd)
+	findings = append(findings, checkInputs(cmd)...)
+	findings = append(findings, checkOutputs(cmd)...)
+	findings = append(findings, checkSpace(cmd)...)
+	failed := false
+	for _, result := range findings {
+		switch result.level {
+		case findingPass:
+			logger(utils.Success(fmt.Sprintf("%s %s", result.level, result.message)), true)
+		case findingWarn:
+			logger(utils.Warning(fmt.Sprintf("%s %s", result.level, result.message)), true)
+		default:
+			logger(utils.Failure(fmt.Sprintf("%s %s", result.level, result.message)), true)
+			failed = true
+		}
+	}
+
+	if failed {
+		return findings, errors.New(constants.DoctorFoundProblemsError)
+	}
+
+	return findings, nil
+}
+
+// checkFlags() will verify the CMD flags as they would be verified for the run (errors logged by VerifyCMD), and warn about flags which would have no effect.
+func checkFlags(cmd models.CMD) []finding {
+	cmd.Doctor = false
+	findings := []finding{}
+	if getMode(cmd) == "" {
+		return append(findings, finding{findingWarn, "Flags: no mode set, so only the environment was checked (EG add -signatureMode + -deltaMode with their files)"})
+	}
+
+	if !verifyCMD(cmd) {
+		findings = append(findings, finding{findingFail, "Flags: missing or conflicting flags for the selected mode(s) (see errors above)"})
+	} else {
+		findings = append(findings, finding{findingPass, fmt.Sprintf("Flags: valid for %s mode", getMode(cmd))})
+	}
+
+	if cmd.DeltaMode && cmd.OriginalFile == "" && (cmd.Sparse > 1 || cmd.Refine || cmd.FineChunk > 0) {
+		findings = append(findings, finding{findingWarn, "Flags: -sparse expansion, -refine + -fineChunk need the Original file (-original) in Delta mode, so will have no effect"})
+	}
+
+	if !cmd.SelftestMode && !cmd.PatchMode && (cmd.PatchReport != "" || cmd.AuditLog != "") {
+		findings = append(findings, finding{findingWarn, "Flags: -patchReport + -auditLog are only written by Selftest + Patch modes, so will have no effect"})
+	}
+
+	return findings
+}
+
+// doctorInputs() will return the input files read by the enabled mode(s), named by flag.
+// Note: files written by the enabled mode(s) (EG `-signature` in Signature mode) are not inputs.
+func doctorInputs(cmd models.CMD) []models.SummaryFile {
+	inputs := []models.SummaryFile{}
+	if cmd.OriginalFile != "" && !cmd.GenMode {
+		inputs = append(inputs, models.SummaryFile{Name: "-original", Path: cmd.OriginalFile})
+	}
+
+	if cmd.UpdatedFile != "" && !cmd.GenMode {
+		inputs = append(inputs, models.SummaryFile{Name: "-updated", Path: cmd.UpdatedFile})
+	}
+
+	if cmd.SignatureFile != "" && !cmd.SignatureMode {
+		inputs = append(inputs, models.SummaryFile{Name: "-signature", Path: cmd.SignatureFile})
+	}
+
+	if cmd.DeltaFile != "" && cmd.DeltaFile != patchStdin && (cmd.ConvertMode || (cmd.PatchMode && !cmd.DeltaMode)) {
+		inputs = append(inputs, models.SummaryFile{Name: "-delta", Path: cmd.DeltaFile})
+	}
+
+	if cmd.FilesFrom != "" {
+		inputs = append(inputs, models.SummaryFile{Name: "-filesFrom", Path: cmd.FilesFrom})
+	}
+
+	return inputs
+}
+
+// checkInputs() will check each input file exists, is a file, and can be opened for reading.
+func checkInputs(cmd models.CMD) []finding {
+	findings := []finding{}
+	for _, input := range doctorInputs(cmd) {
+		file, err := openInput(input.Path)
+		if os.IsNotExist(err) {
+			findings = append(findings, finding{findingFail, fmt.Sprintf("Inputs: %s `%s` does not exist", input.Name, input.Path)})
+			continue
+		} else if os.IsPermission(err) {
+			findings = append(findings, finding{findingFail, fmt.Sprintf("Inputs: %s `%s` is not readable (check file permissions)", input.Name, input.Path)})
+			continue
+		} else if err != nil {
+			findings = append(findings, finding{findingFail, fmt.Sprintf("Inputs: %s `%s` can not be opened (%s)", input.Name, input.Path, err)})
+			continue
+		}
+
+		info, err := file.Stat()
+		file.Close()
+		if err == nil && info.IsDir() {
+			findings = append(findings, finding{findingFail, fmt.Sprintf("Inputs: %s `%s` is a folder, not a file", input.Name, input.Path)})
+			continue
+		}
+
+		findings = append(findings, finding{findingPass, fmt.Sprintf("Inputs: %s `%s` is readable", input.Name, input.Path)})
+	}
+
+	return findings
+}
+
+// outputFolder() will return the folder output files will be written to, or the current folder when the Outputs folder will be created by the run.
+func outputFolder() string {
+	if _, err := statFile(outputPath("")); os.IsNotExist(err) {
+		return "."
+	}
+
+	return outputPath("")
+}
+
+// checkOutputs() will check the Outputs folder is writable (by creating + removing a probe file), and warn about output files which would be overwritten.
+func checkOutputs(cmd models.CMD) []finding {
+	findings := []finding{}
+	folder := outputFolder()
+	if info, err := statFile(folder); err == nil && !info.IsDir() {
+		findings = append(findings, finding{findingFail, fmt.Sprintf("Outputs: `%s` is a file, not a folder (move or rename it)", folder)})
+	} else if probe, err := createTemp(folder, ".doctor-*"); err != nil {
+		findings = append(findings, finding{findingFail, fmt.Sprintf("Outputs: `%s` is not writable (check folder permissions)", folder)})
+	} else {
+		probe.Close()
+		_ = removeFile(probe.Name())
+		findings = append(findings, finding{findingPass, fmt.Sprintf("Outputs: `%s` is writable", folder)})
+	}
+
+	outputs := []string{}
+	if cmd.SignatureMode && cmd.SignatureFile != "" {
+		outputs = append(outputs, cmd.SignatureFile)
+	}
+
+	if cmd.DeltaMode && cmd.DeltaFile != "" {
+		outputs = append(outputs, cmd.DeltaFile)
+	}
+
+	if cmd.PatchMode && cmd.OutputFile != "" {
+		outputs = append(outputs, cmd.OutputFile)
+	}
+
+	for _, output := range outputs {
+		if exists, _ := outputFileExists(output); exists && !cmd.Yes {
+			findings = append(findings, finding{findingWarn, fmt.Sprintf("Outputs: `%s` already exists, and will prompt before overwriting (see -yes)", outputPath(output))})
+		}
+	}
+
+	return findings
+}
+
+// checkSpace() will check the filesystems of the Outputs folder + temp folder have enough free space for the estimated output + temp files (see requiredSpace()).
+func checkSpace(cmd models.CMD) []finding {
+	findings := []finding{}
+	for _, check := range spaceChecks(cmd) {
+		free, err := freeSpace(check.folder)
+		if err != nil {
+			findings = append(findings, finding{findingWarn, fmt.Sprintf("%s: unable to check free space of `%s`", check.name, check.folder)})
+		} else if free < check.required {
+			findings = append(findings, finding{findingFail, fmt.Sprintf("%s: `%s` has %d bytes free, but up to %d bytes are needed (free space or choose another folder)", check.name, check.folder, free, check.required)})
+		} else {
+			findings = append(findings, finding{findingPass, fmt.Sprintf("%s: `%s` has %d bytes free (up to %d bytes needed)", check.name, check.folder, free, check.required)})
+		}
+	}
+
+	return findings
+}
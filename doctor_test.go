@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDoctor(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.txt")
+	updated := filepath.Join(dir, "updated.txt")
+	require.Equal(t, nil, os.WriteFile(original, []byte("some-original-content"), 0o644))
+	require.Equal(t, nil, os.WriteFile(updated, []byte("some-updated-content"), 0o644))
+	t.Run("should return `findings, nil` when no check fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Doctor: true, SignatureMode: true, DeltaMode: true, OriginalFile: original, UpdatedFile: updated, SignatureFile: "sig", DeltaFile: "delta", Yes: true}
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 1024 * 1024, nil
+		}
+
+		// Run
+		findings, err := runDoctor(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		for _, result := range findings {
+			require.Equal(t, findingPass, result.level, result.message)
+		}
+	})
+
+	t.Run("should return `findings, DoctorFoundProblemsError` when an input file does not exist", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Doctor: true, SignatureMode: true, OriginalFile: filepath.Join(dir, "missing.txt"), SignatureFile: "sig", Yes: true}
+		expectedError := errors.New(constants.DoctorFoundProblemsError)
+		// Run
+		findings, err := runDoctor(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Contains(t, findings, finding{findingFail, "Inputs: -original `" + cmd.OriginalFile + "` does not exist"})
+	})
+
+	t.Run("should return `findings, DoctorFoundProblemsError` when flags are missing for the selected mode", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Doctor: true, DeltaMode: true, UpdatedFile: updated}
+		expectedError := errors.New(constants.DoctorFoundProblemsError)
+		// Run
+		findings, err := runDoctor(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, findingFail, findings[0].level)
+	})
+
+	t.Run("should return `findings, DoctorFoundProblemsError` when there is not enough free space for output files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Doctor: true, DeltaMode: true, SignatureFile: original, UpdatedFile: updated, DeltaFile: "delta", Yes: true}
+		expectedError := errors.New(constants.DoctorFoundProblemsError)
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 1, nil
+		}
+
+		// Run
+		findings, err := runDoctor(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, findingFail, findings[len(findings)-1].level)
+		// Restore, so later tests check free space of real filesystems
+		freeSpace = files.FreeSpace
+	})
+}
+
+func TestCheckFlags(t *testing.T) {
+	t.Run("should warn when no mode set", func(t *testing.T) {
+		// Run
+		findings := checkFlags(models.CMD{Doctor: true})
+		// Verify
+		require.Equal(t, 1, len(findings))
+		require.Equal(t, findingWarn, findings[0].level)
+	})
+
+	t.Run("should warn when flags would have no effect for the selected mode", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Doctor: true, DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, Refine: true, AuditLog: file}
+		// Run
+		findings := checkFlags(cmd)
+		// Verify
+		require.Equal(t, 3, len(findings))
+		require.Equal(t, findingPass, findings[0].level)
+		require.Equal(t, findingWarn, findings[1].level)
+		require.Equal(t, findingWarn, findings[2].level)
+	})
+}
+
+func TestCheckInputs(t *testing.T) {
+	t.Run("should fail when an input file is a folder", func(t *testing.T) {
+		// Setup
+		dir := t.TempDir()
+		cmd := models.CMD{StatsMode: true, SignatureFile: dir}
+		// Run
+		findings := checkInputs(cmd)
+		// Verify
+		require.Equal(t, []finding{{findingFail, "Inputs: -signature `" + dir + "` is a folder, not a file"}}, findings)
+	})
+
+	t.Run("should not check files written by the selected mode", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GenMode: true, OriginalFile: file, UpdatedFile: file}
+		// Run
+		findings := checkInputs(cmd)
+		// Verify
+		require.Equal(t, []finding{}, findings)
+	})
+}
+
+func TestCheckOutputs(t *testing.T) {
+	t.Run("should fail when Outputs folder is not writable", func(t *testing.T) {
+		// Mock
+		createTemp = func(dir string, pattern string) (*os.File, error) {
+			return nil, os.ErrPermission
+		}
+
+		// Run
+		findings := checkOutputs(models.CMD{})
+		// Verify
+		require.Equal(t, 1, len(findings))
+		require.Equal(t, findingFail, findings[0].level)
+	})
+
+	t.Run("should warn when an output file already exists and `-yes` not set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, SignatureFile: file}
+		// Mock
+		createTemp = os.CreateTemp
+		outputFileExists = func(fileName string) (bool, error) {
+			return true, nil
+		}
+
+		// Run
+		findings := checkOutputs(cmd)
+		// Verify
+		require.Equal(t, 2, len(findings))
+		require.Equal(t, finding{findingWarn, "Outputs: `" + outputPath(file) + "` already exists, and will prompt before overwriting (see -yes)"}, findings[1])
+		// Restore, so later tests check real output files
+		outputFileExists = files.OutputFileExists
+	})
+}
@@ -0,0 +1,72 @@
+// Package engine provides in-memory Signature, Delta, and Patch functions for embedding the engine (EG C shared library, WebAssembly).
+// Signatures + Deltas are gob encoded, using the same format as Signature + Delta files.
+// Note: functions are byte slice wrappers of the `filediff` library API.
+package engine
+
+import (
+	"bytes"
+
+	"github.com/curtismenmuir/go-file-diff/filediff"
+)
+
+// Delta() will generate a gob encoded Delta of how to update the Original file (described by a gob encoded Signature) to match the Updated data.
+// Note: the Delta will be generated with the chunk size + hashes recorded in the Signature.
+// Function returns `delta, nil` when successful.
+// Function returns `nil, UnableToDecodeSignatureFromFileError` when unable to decode Signature.
+// Function returns `nil, UpdatedFileHasNoChangesError` when Updated data has no changes from Original.
+// Function returns `nil, UnableToGenerateDeltaError` when unable to generate Delta.
+// Function returns `nil, UnableToWriteToFileError` when unable to encode Delta.
+func Delta(encodedSignature []byte, updated []byte) ([]byte, error) {
+	signature, options, err := filediff.ReadSignature(bytes.NewReader(encodedSignature))
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := filediff.GenerateDelta(bytes.NewReader(updated), signature, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	if err := filediff.WriteDelta(&output, delta); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// Patch() will apply a gob encoded Delta to the Original data, returning the reconstructed Updated data.
+// Function returns `updated, nil` when successful.
+// Function returns `nil, UnableToDecodeDeltaFromFileError` when unable to decode Delta.
+// Function returns `nil, error` when unable to apply Delta (EG InvalidDeltaError).
+func Patch(original []byte, encodedDelta []byte) ([]byte, error) {
+	delta, err := filediff.ReadDelta(bytes.NewReader(encodedDelta))
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	if err := filediff.Apply(bytes.NewReader(original), delta, &output); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// Signature() will generate a gob encoded Signature of the Original data (EG the same format as Signature files).
+// Function returns `signature, nil` when successful.
+// Function returns `nil, UnableToGenerateSignatureError` when unable to generate Signature.
+// Function returns `nil, UnableToWriteToFileError` when unable to encode Signature.
+func Signature(original []byte) ([]byte, error) {
+	signature, err := filediff.GenerateSignature(bytes.NewReader(original), filediff.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	if err := filediff.WriteSignature(&output, signature, filediff.Options{}); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testOriginal = []byte("The quick brown fox jumps over the lazy dog, and runs far away..")
+	testUpdated  = []byte("The quick brown fox leaps over the lazy dog, and runs far away!!")
+)
+
+func TestDelta(t *testing.T) {
+	t.Run("should return `nil, UpdatedFileHasNoChangesError` when Updated data matches Original", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		encodedSignature, err := Signature(testOriginal)
+		require.Equal(t, nil, err)
+		// Run
+		encodedDelta, err := Delta(encodedSignature, testOriginal)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []byte(nil), encodedDelta)
+	})
+
+	t.Run("should return `nil, UnableToDecodeSignatureFromFileError` when Signature is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError)
+		// Run
+		encodedDelta, err := Delta([]byte("invalid"), testUpdated)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []byte(nil), encodedDelta)
+	})
+}
+
+func TestPatch(t *testing.T) {
+	t.Run("should reconstruct Updated data from Signature -> Delta -> Patch", func(t *testing.T) {
+		// Setup
+		encodedSignature, err := Signature(testOriginal)
+		require.Equal(t, nil, err)
+		encodedDelta, err := Delta(encodedSignature, testUpdated)
+		require.Equal(t, nil, err)
+		// Run
+		patched, err := Patch(testOriginal, encodedDelta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testUpdated, patched)
+	})
+
+	t.Run("should return `nil, UnableToDecodeDeltaFromFileError` when Delta is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError)
+		// Run
+		patched, err := Patch(testOriginal, []byte("invalid"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []byte(nil), patched)
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+var (
+	encodedSize = files.EncodedSize
+	fullCopy    = sync.FullCopy
+)
+
+// checkDeltaSize() will compare the encoded size of a generated Delta with the size of the Updated file.
+// When the Delta is larger (EG heavily rewritten file), a warning will be logged, or the Delta will be replaced with a full copy of the Updated file when `-fallbackFullCopy` flag set.
+// Note: the Delta will be returned unchanged when unable to get either size.
+// Function returns `delta, nil` when Delta is no larger than the Updated file, or no fallback requested.
+// Function returns `fullCopyDelta, nil` when Delta is larger than the Updated file and `-fallbackFullCopy` flag set.
+// Function returns `emptyDelta, UnableToReadUpdatedFileError` when unable to read the Updated file for the full copy.
+func checkDeltaSize(cmd models.CMD, delta models.Delta) (models.Delta, error) {
+	updatedSize, err := fileSize(cmd.UpdatedFile)
+	if err != nil {
+		return delta, nil
+	}
+
+	deltaSize, err := encodedSize(delta)
+	if err != nil || deltaSize <= updatedSize {
+		return delta, nil
+	}
+
+	if !cmd.FullCopy {
+		errorLogger(utils.Warning(constants.DeltaLargerThanUpdatedFileError))
+		return delta, nil
+	}
+
+	logger(fmt.Sprintf("Delta (%d bytes) larger than Updated file (%d bytes), using full copy", deltaSize, updatedSize), cmd.Verbose)
+	return readFullCopy(cmd)
+}
+
+// readFullCopy() will read the Updated file into a Delta containing its full contents as a single literal block.
+// Function returns `fullCopyDelta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileDoesNotExistError` when Updated file cannot be found.
+// Function returns `emptyDelta, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `emptyDelta, UnableToReadUpdatedFileError` when unable to read the Updated file.
+func readFullCopy(cmd models.CMD) (models.Delta, error) {
+	reader, err := openUpdated(cmd.UpdatedFile)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	contents, err := readAll(reader)
+	if err != nil {
+		return models.Delta{}, errors.New(constants.UnableToReadUpdatedFileError)
+	}
+
+	return fullCopy(contents), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDeltaSize(t *testing.T) {
+	delta := models.Delta{0: models.Block{Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}
+	t.Run("should return `fullCopyDelta, nil` when Delta larger than Updated file and `-fallbackFullCopy` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, FullCopy: true}
+		contents := []byte("rewritten")
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return int64(len(contents)), nil
+		}
+
+		encodedSize = func(model any) (int64, error) {
+			return 100, nil
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader(contents)), nil
+		}
+
+		readAll = func(r io.Reader) ([]byte, error) {
+			return io.ReadAll(r)
+		}
+
+		// Run
+		result, err := checkDeltaSize(cmd, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, sync.FullCopy(contents), result)
+	})
+
+	t.Run("should return `emptyDelta, UnableToReadUpdatedFileError` when unable to read Updated file for full copy", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, FullCopy: true}
+		expectedError := errors.New(constants.UnableToReadUpdatedFileError)
+		// Mock
+		readAll = func(r io.Reader) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		result, err := checkDeltaSize(cmd, delta)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+		readAll = io.ReadAll
+	})
+
+	t.Run("should log warning + return `delta, nil` when Delta larger than Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file}
+		logged := ""
+		// Mock
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		// Run
+		result, err := checkDeltaSize(cmd, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+		require.Equal(t, utils.Warning(constants.DeltaLargerThanUpdatedFileError), logged)
+	})
+
+	t.Run("should return `delta, nil` when Delta no larger than Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, FullCopy: true}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 1000, nil
+		}
+
+		// Run
+		result, err := checkDeltaSize(cmd, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+}
@@ -0,0 +1,198 @@
+// Package filediff provides a stable library API for embedding Signature, Delta, and Patch in other Go applications (EG backup tools).
+// Functions only read + write the provided io interfaces: nothing is logged, and nothing is written to the `Outputs/` folder.
+// Returned errors will be one of the exported errors below, so they can be compared with errors.Is() (messages match the CLI).
+// Note: Signatures + Deltas are gob encoded in the same format as Signature + Delta files, so files generated by the CLI can be read (and vice versa).
+// Note: the chunk size + hash algorithms are passed to each call (see Options), rather than read from the settings of the whole process (EG sync.SetChunkSize()), so functions can be called from multiple goroutines with different Options.
+package filediff
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+// Signature type.
+// This will describe the blocks of an Original file, indexed by Weak hash (see models.Signature).
+type Signature = models.Signature
+
+// Delta type.
+// This will describe how to update an Original file to match an Updated file, indexed by position in the Updated file (see models.Delta).
+type Delta = models.Delta
+
+// Options type.
+// This will contain the chunk size + hash algorithms used to generate Signatures + Deltas, passed to each call so callers never share settings.
+// Empty fields will use the defaults: 16 byte chunks, the Rabin–Karp Weak hash + SHA256 Strong hash (EG `Options{}`).
+// Note: a Delta must be generated (+ strictly applied) with the Options of its Signature, as returned by ReadSignature().
+// EG: Options{ChunkSize: 4096, WeakHash: sync.Adler32WeakHash}.
+type Options struct {
+	ChunkSize  int64  // Bytes per block (up to rolling.MaxChunkSize)
+	WeakHash   string // Registered Weak hash (EG sync.BuzhashWeakHash)
+	StrongHash string // Registered Strong hash (EG sync.DefaultStrongHash)
+}
+
+// metadata() will return the Signature metadata describing the Options (empty fields will use the defaults when generating).
+func (o Options) metadata() models.SignatureMetadata {
+	return models.SignatureMetadata{ChunkSize: o.ChunkSize, WeakHash: o.WeakHash, StrongHash: o.StrongHash}
+}
+
+// Errors returned by the library API, with the same messages as the CLI errors (see constants).
+var (
+	ErrNoChanges         = errors.New(constants.UpdatedFileHasNoChangesError)
+	ErrGenerateSignature = errors.New(constants.UnableToGenerateSignatureError)
+	ErrGenerateDelta     = errors.New(constants.UnableToGenerateDeltaError)
+	ErrInvalidDelta      = errors.New(constants.InvalidDeltaError)
+	ErrReadOriginal      = errors.New(constants.UnableToReadOriginalFileError)
+	ErrOriginalChanged   = errors.New(constants.OriginalFileChangedError)
+	ErrWriteOutput       = errors.New(constants.UnableToWriteOutputFileError)
+	ErrDecodeSignature   = errors.New(constants.UnableToDecodeSignatureFromFileError)
+	ErrDecodeDelta       = errors.New(constants.UnableToDecodeDeltaFromFileError)
+	ErrEncode            = errors.New(constants.UnableToWriteToFileError)
+	ErrChunkSizeRange    = errors.New(constants.ChunkSizeOutOfRangeError)
+	ErrHashNotRegistered = errors.New(constants.HashNotRegisteredError)
+)
+
+var knownErrors = []error{ErrNoChanges, ErrGenerateSignature, ErrGenerateDelta, ErrInvalidDelta, ErrReadOriginal, ErrOriginalChanged, ErrWriteOutput, ErrDecodeSignature, ErrDecodeDelta, ErrEncode, ErrChunkSizeRange, ErrHashNotRegistered}
+
+// stableError() will replace an error returned by the engine with the exported error of the same message, or the provided fallback when the error is not exported.
+func stableError(err error, fallback error) error {
+	for _, known := range knownErrors {
+		if err.Error() == known.Error() {
+			return known
+		}
+	}
+
+	return fallback
+}
+
+// GenerateSignature() will read the Original data, returning a Signature of every block generated with the provided Options.
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, ErrChunkSizeRange` when the chunk size is negative or larger than rolling.MaxChunkSize.
+// Function returns `emptySignature, ErrHashNotRegistered` when the Weak or Strong hash has not been registered.
+// Function returns `emptySignature, ErrGenerateSignature` when unable to read the Original data, or generate the Signature.
+func GenerateSignature(original io.Reader, options Options) (Signature, error) {
+	signature, err := sync.GenerateSignatureWith(bufio.NewReader(original), options.metadata(), false)
+	if err != nil {
+		return Signature{}, stableError(err, ErrGenerateSignature)
+	}
+
+	return signature, nil
+}
+
+// GenerateDelta() will read the Updated data, returning a Delta of how to update the Original data (described by its Signature, generated with the provided Options) to match.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, ErrNoChanges` when the Updated data has no changes from the Original data.
+// Function returns `emptyDelta, ErrChunkSizeRange` or `ErrHashNotRegistered` when the Options are invalid.
+// Function returns `emptyDelta, ErrGenerateDelta` when unable to read the Updated data, or generate the Delta.
+func GenerateDelta(updated io.Reader, signature Signature, options Options) (Delta, error) {
+	delta, err := sync.GenerateDeltaWith(bufio.NewReader(updated), signature, options.metadata(), false)
+	if err != nil {
+		return Delta{}, stableError(err, ErrGenerateDelta)
+	}
+
+	return delta, nil
+}
+
+// Apply() will patch the Original data with a Delta, writing the reconstructed Updated data to the provided writer.
+// Function returns `nil` when successful.
+// Function returns `ErrInvalidDelta` when Delta blocks contain gaps or overlap (EG a corrupt Delta).
+// Function returns `ErrReadOriginal` when unable to read a matched block from the Original data.
+// Function returns `ErrWriteOutput` when unable to write to the provided writer.
+func Apply(original io.ReaderAt, delta Delta, out io.Writer) error {
+	if err := sync.Apply(original, delta, out); err != nil {
+		return stableError(err, ErrWriteOutput)
+	}
+
+	return nil
+}
+
+// ApplyStrict() will patch the Original data in the same way as Apply(), verifying each matched block against the Signature of the Original data (generated with the provided Options) before writing.
+// Function returns `nil` when successful.
+// Function returns `ErrOriginalChanged` when a matched block does not match the Signature (EG Original data modified since the Signature was generated).
+// Function returns `ErrChunkSizeRange` or `ErrHashNotRegistered` when the Options are invalid.
+// Function returns `error` in the same cases as Apply().
+func ApplyStrict(original io.ReaderAt, delta Delta, signature Signature, options Options, out io.Writer) error {
+	if err := sync.ApplyStrictWith(original, delta, signature, options.metadata(), out); err != nil {
+		return stableError(err, ErrWriteOutput)
+	}
+
+	return nil
+}
+
+// ReadSignature() will decode a gob encoded Signature (EG a Signature file), along with the Options it was generated with (recorded after the Signature).
+// Note: Signatures without metadata (EG written by older versions) will return empty Options, as they were generated with the default chunk size + hashes.
+// Function returns `signature, options, nil` when successful.
+// Function returns `emptySignature, emptyOptions, ErrDecodeSignature` when unable to decode the Signature.
+func ReadSignature(reader io.Reader) (Signature, Options, error) {
+	signatureReader := files.NewGobSignatureReader(reader)
+	signature, err := signatureReader.ReadSignature()
+	if err != nil {
+		return Signature{}, Options{}, ErrDecodeSignature
+	}
+
+	metadata, err := signatureReader.ReadMetadata()
+	if err != nil {
+		return Signature{}, Options{}, ErrDecodeSignature
+	}
+
+	return signature, Options{ChunkSize: metadata.ChunkSize, WeakHash: metadata.WeakHash, StrongHash: metadata.StrongHash}, nil
+}
+
+// WriteSignature() will gob encode a Signature to the provided writer, followed by the Options it was generated with (so ReadSignature() returns the same Options).
+// Note: the chunk size, Weak hash + Strong hash will always be recorded (defaults when empty), matching Signature files written by the CLI.
+// Function returns `nil` when successful.
+// Function returns `ErrEncode` when unable to encode the Signature.
+func WriteSignature(writer io.Writer, signature Signature, options Options) error {
+	metadata := options.metadata()
+	if metadata.ChunkSize == 0 {
+		metadata.ChunkSize = rolling.DefaultChunkSize
+	}
+
+	if metadata.WeakHash == "" {
+		metadata.WeakHash = sync.DefaultWeakHash
+	}
+
+	if metadata.StrongHash == "" {
+		metadata.StrongHash = sync.DefaultStrongHash
+	}
+
+	signatureWriter := files.NewGobSignatureWriter(writer)
+	if err := signatureWriter.WriteSignature(signature); err != nil {
+		return ErrEncode
+	}
+
+	if err := signatureWriter.WriteMetadata(metadata); err != nil {
+		return ErrEncode
+	}
+
+	return nil
+}
+
+// ReadDelta() will decode a gob encoded Delta (EG a Delta file).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, ErrDecodeDelta` when unable to decode the Delta (EG file written by a newer version).
+func ReadDelta(reader io.Reader) (Delta, error) {
+	delta, err := files.DecodeGobDelta(reader)
+	if err != nil {
+		return Delta{}, ErrDecodeDelta
+	}
+
+	return delta, nil
+}
+
+// WriteDelta() will gob encode a Delta to the provided writer.
+// Function returns `nil` when successful.
+// Function returns `ErrEncode` when unable to encode the Delta.
+func WriteDelta(writer io.Writer, delta Delta) error {
+	if err := gob.NewEncoder(writer).Encode(delta); err != nil {
+		return ErrEncode
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package filediff
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	stdsync "sync"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testOriginal = []byte("The quick brown fox jumps over the lazy dog, and runs far away..")
+	testUpdated  = []byte("The quick brown fox leaps over the lazy dog, and runs far away!!")
+)
+
+// failingReader type.
+// This will fail every read.
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("some-error")
+}
+
+// failingWriter type.
+// This will fail every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("some-error")
+}
+
+func TestGenerateDelta(t *testing.T) {
+	t.Run("should return `delta, nil` which reconstructs Updated data when applied", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		var output bytes.Buffer
+		// Run
+		delta, err := GenerateDelta(bytes.NewReader(testUpdated), signature, Options{})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, Apply(bytes.NewReader(testOriginal), delta, &output))
+		require.Equal(t, testUpdated, output.Bytes())
+	})
+
+	t.Run("should return `emptyDelta, ErrNoChanges` when Updated data matches Original", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		// Run
+		delta, err := GenerateDelta(bytes.NewReader(testOriginal), signature, Options{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrNoChanges))
+		require.Equal(t, Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, ErrGenerateDelta` when unable to read Updated data", func(t *testing.T) {
+		// Run
+		delta, err := GenerateDelta(failingReader{}, Signature{}, Options{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrGenerateDelta))
+		require.Equal(t, Delta{}, delta)
+	})
+}
+
+func TestGenerateSignature(t *testing.T) {
+	t.Run("should return `emptySignature, ErrChunkSizeRange` + `ErrHashNotRegistered` when Options are invalid", func(t *testing.T) {
+		// Run
+		chunkSignature, chunkErr := GenerateSignature(bytes.NewReader(testOriginal), Options{ChunkSize: -1})
+		hashSignature, hashErr := GenerateSignature(bytes.NewReader(testOriginal), Options{WeakHash: "some-hash"})
+		// Verify
+		require.Equal(t, true, errors.Is(chunkErr, ErrChunkSizeRange))
+		require.Equal(t, true, errors.Is(hashErr, ErrHashNotRegistered))
+		require.Equal(t, Signature{}, chunkSignature)
+		require.Equal(t, Signature{}, hashSignature)
+	})
+
+	t.Run("should generate Signatures + Deltas with different Options from multiple goroutines", func(t *testing.T) {
+		// Setup
+		options := []Options{{}, {ChunkSize: 8}, {ChunkSize: 32, WeakHash: sync.Adler32WeakHash}, {WeakHash: sync.BuzhashWeakHash}}
+		outputs := make([][]byte, len(options))
+		errs := make([]error, len(options))
+		var group stdsync.WaitGroup
+		// Run
+		for i := range options {
+			group.Add(1)
+			go func(i int) {
+				defer group.Done()
+				signature, err := GenerateSignature(bytes.NewReader(testOriginal), options[i])
+				if err != nil {
+					errs[i] = err
+					return
+				}
+
+				delta, err := GenerateDelta(bytes.NewReader(testUpdated), signature, options[i])
+				if err != nil {
+					errs[i] = err
+					return
+				}
+
+				var output bytes.Buffer
+				errs[i] = ApplyStrict(bytes.NewReader(testOriginal), delta, signature, options[i], &output)
+				outputs[i] = output.Bytes()
+			}(i)
+		}
+
+		group.Wait()
+		// Verify
+		for i := range options {
+			require.Equal(t, nil, errs[i])
+			require.Equal(t, testUpdated, outputs[i])
+		}
+	})
+
+	t.Run("should return `emptySignature, ErrGenerateSignature` when unable to read Original data", func(t *testing.T) {
+		// Run
+		signature, err := GenerateSignature(failingReader{}, Options{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrGenerateSignature))
+		require.Equal(t, Signature{}, signature)
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("should return `ErrInvalidDelta` when Delta blocks contain gaps", func(t *testing.T) {
+		// Setup
+		delta := Delta{4: {Head: 0, Tail: 3, IsModified: false, Value: []byte{}}}
+		// Run
+		err := Apply(bytes.NewReader(testOriginal), delta, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrInvalidDelta))
+	})
+
+	t.Run("should return `ErrWriteOutput` when unable to write to the writer", func(t *testing.T) {
+		// Setup
+		delta := Delta{0: {Head: 0, Tail: 3, IsModified: false, Value: []byte{}}}
+		// Run
+		err := Apply(bytes.NewReader(testOriginal), delta, failingWriter{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrWriteOutput))
+	})
+}
+
+func TestApplyStrict(t *testing.T) {
+	t.Run("should return `ErrOriginalChanged` when Original data does not match the Signature", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		delta, err := GenerateDelta(bytes.NewReader(testUpdated), signature, Options{})
+		require.Equal(t, nil, err)
+		// Run
+		err = ApplyStrict(bytes.NewReader(bytes.ToUpper(testOriginal)), delta, signature, Options{}, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, true, errors.Is(err, ErrOriginalChanged))
+	})
+}
+
+func TestEncoding(t *testing.T) {
+	t.Run("should decode the Signature + Delta which were encoded", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		delta, err := GenerateDelta(bytes.NewReader(testUpdated), signature, Options{})
+		require.Equal(t, nil, err)
+		var encodedSignature, encodedDelta bytes.Buffer
+		// Run
+		require.Equal(t, nil, WriteSignature(&encodedSignature, signature, Options{}))
+		require.Equal(t, nil, WriteDelta(&encodedDelta, delta))
+		decodedSignature, options, signatureErr := ReadSignature(&encodedSignature)
+		decodedDelta, deltaErr := ReadDelta(&encodedDelta)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		require.Equal(t, signature, decodedSignature)
+		require.Equal(t, Options{ChunkSize: 16, WeakHash: sync.DefaultWeakHash, StrongHash: sync.DefaultStrongHash}, options)
+		// Empty Values of matched blocks are decoded as nil, so compare the patched data
+		var output bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(testOriginal), decodedDelta, &output))
+		require.Equal(t, testUpdated, output.Bytes())
+	})
+
+	t.Run("should return `ErrDecodeSignature` + `ErrDecodeDelta` when data is invalid", func(t *testing.T) {
+		// Run
+		_, _, signatureErr := ReadSignature(bytes.NewReader([]byte("invalid")))
+		_, deltaErr := ReadDelta(bytes.NewReader([]byte("invalid")))
+		// Verify
+		require.Equal(t, true, errors.Is(signatureErr, ErrDecodeSignature))
+		require.Equal(t, true, errors.Is(deltaErr, ErrDecodeDelta))
+	})
+
+	t.Run("should decode Signature files written by the CLI, with or without metadata", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		path := filepath.Join(t.TempDir(), "signature")
+		legacyPath := filepath.Join(t.TempDir(), "legacy")
+		require.Equal(t, nil, files.WriteStructToPath(signature, legacyPath))
+		require.Equal(t, nil, files.WriteSignatureToPath(signature, sync.Metadata(), path))
+		expected := map[string]Options{path: {ChunkSize: 16, WeakHash: sync.DefaultWeakHash, StrongHash: sync.DefaultStrongHash}, legacyPath: {}}
+		for signaturePath, expectedOptions := range expected {
+			data, err := os.ReadFile(signaturePath)
+			require.Equal(t, nil, err)
+			// Run
+			decodedSignature, options, err := ReadSignature(bytes.NewReader(data))
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, signature, decodedSignature)
+			require.Equal(t, expectedOptions, options)
+		}
+	})
+
+	t.Run("should decode Delta files written by the CLI (EG with header + checksum trailer)", func(t *testing.T) {
+		// Setup
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), Options{})
+		require.Equal(t, nil, err)
+		delta, err := GenerateDelta(bytes.NewReader(testUpdated), signature, Options{})
+		require.Equal(t, nil, err)
+		path := filepath.Join(t.TempDir(), "delta")
+		require.Equal(t, nil, files.WriteStructToPath(delta, path))
+		data, err := os.ReadFile(path)
+		require.Equal(t, nil, err)
+		// Run
+		decodedDelta, err := ReadDelta(bytes.NewReader(data))
+		// Verify
+		require.Equal(t, nil, err)
+		var output bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(testOriginal), decodedDelta, &output))
+		require.Equal(t, testUpdated, output.Bytes())
+	})
+
+	t.Run("should return the Options the Signature was written with, which generate a Delta matching the Signature", func(t *testing.T) {
+		// Setup
+		written := Options{ChunkSize: 32, WeakHash: sync.Adler32WeakHash, StrongHash: sync.DefaultStrongHash}
+		signature, err := GenerateSignature(bytes.NewReader(testOriginal), written)
+		require.Equal(t, nil, err)
+		var encodedSignature bytes.Buffer
+		require.Equal(t, nil, WriteSignature(&encodedSignature, signature, written))
+		// Run
+		decodedSignature, options, err := ReadSignature(&encodedSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, written, options)
+		delta, err := GenerateDelta(bytes.NewReader(testUpdated), decodedSignature, options)
+		require.Equal(t, nil, err)
+		var output bytes.Buffer
+		require.Equal(t, nil, ApplyStrict(bytes.NewReader(testOriginal), delta, decodedSignature, options, &output))
+		require.Equal(t, testUpdated, output.Bytes())
+		// Process settings should not be changed
+		require.Equal(t, Options{ChunkSize: 16, WeakHash: sync.DefaultWeakHash, StrongHash: sync.DefaultStrongHash}, Options{ChunkSize: sync.Metadata().ChunkSize, WeakHash: sync.Metadata().WeakHash, StrongHash: sync.Metadata().StrongHash})
+	})
+
+	t.Run("should return `ErrEncode` when unable to write to the writer", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, true, errors.Is(WriteSignature(failingWriter{}, Signature{}, Options{}), ErrEncode))
+		require.Equal(t, true, errors.Is(WriteDelta(failingWriter{}, Delta{}), ErrEncode))
+	})
+}
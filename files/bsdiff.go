@@ -0,0 +1,208 @@
+package files
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	dsnetbzip2 "github.com/dsnet/compress/bzip2"
+)
+
+const (
+	BsdiffMagic      string = "BSDIFF40" // Magic of bsdiff 4.x patch files
+	bsdiffHeaderSize int    = 32         // Magic, followed by the control block size, diff block size + Updated file size
+	bsdiffControlLen int    = 24         // Add, extra + seek lengths of a control, 8 bytes each
+)
+
+// bsdiffZeros type.
+// This will read an endless stream of zeros (EG the diff block of matched blocks).
+type bsdiffZeros struct{}
+
+// Implement bsdiffZeros.Read()
+func (bsdiffZeros) Read(p []byte) (int, error) {
+	for index := range p {
+		p[index] = 0
+	}
+
+	return len(p), nil
+}
+
+// BsdiffDelta type.
+// This will contain a Delta to be written in the bsdiff 4.x patch format (see WriteStructToFile()), so it can be applied by `bspatch`.
+type BsdiffDelta models.Delta
+
+// DecodeBsdiff() will decode a bsdiff 4.x patch (EG generated by `bsdiff`).
+// Note: the diff + extra blocks will be decompressed into memory.
+// Function returns `patch, nil` when successful.
+// Function returns `emptyPatch, UnableToDecodeDeltaFromFileError` when unable to decode the patch (EG wrong format, or truncated file).
+func DecodeBsdiff(reader io.Reader) (models.BsdiffPatch, error) {
+	header := make([]byte, bsdiffHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil || string(header[:8]) != BsdiffMagic {
+		return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "wrong format: not a bsdiff patch")
+	}
+
+	controlSize, diffSize, size := bsdiffInt(header[8:]), bsdiffInt(header[16:]), bsdiffInt(header[24:])
+	if controlSize < 0 || diffSize < 0 || size < 0 {
+		return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "corrupt bsdiff header")
+	}
+
+	controlBlock, err := readBsdiffBlock(io.LimitReader(reader, controlSize), controlSize)
+	if err != nil || len(controlBlock)%bsdiffControlLen != 0 {
+		return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "corrupt bsdiff control block")
+	}
+
+	diff, err := readBsdiffBlock(io.LimitReader(reader, diffSize), diffSize)
+	if err != nil {
+		return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "corrupt bsdiff diff block")
+	}
+
+	extra, err := readBsdiffBlock(reader, -1)
+	if err != nil {
+		return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "corrupt bsdiff extra block")
+	}
+
+	patch := models.BsdiffPatch{Controls: make([]models.BsdiffControl, 0, len(controlBlock)/bsdiffControlLen), Diff: diff, Extra: extra, Size: size}
+	for offset := 0; offset < len(controlBlock); offset += bsdiffControlLen {
+		control := models.BsdiffControl{Add: bsdiffInt(controlBlock[offset:]), Extra: bsdiffInt(controlBlock[offset+8:]), Seek: bsdiffInt(controlBlock[offset+16:])}
+		if control.Add < 0 || control.Extra < 0 {
+			return models.BsdiffPatch{}, decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("corrupt bsdiff control %d", offset/bsdiffControlLen))
+		}
+
+		patch.Controls = append(patch.Controls, control)
+	}
+
+	return patch, nil
+}
+
+// EncodeBsdiffDelta() will encode a Delta in the bsdiff 4.x patch format, so it can be applied by `bspatch`.
+// Matched blocks will be written as controls adding zeros to the Original file, and literal blocks as extra bytes.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to compress the patch, or write to the provided writer.
+func EncodeBsdiffDelta(delta models.Delta, writer io.Writer) error {
+	var controlBlock, extra bytes.Buffer
+	control := models.BsdiffControl{}
+	// Position of the Original file after the pending control's added bytes
+	position := int64(0)
+	size, added := int64(0), int64(0)
+	_ = delta.Ops(func(op models.Op) error {
+		size += op.Len()
+		if op.Kind == models.OpLiteral {
+			control.Extra += op.Len()
+			extra.Write(op.Value)
+			return nil
+		}
+
+		// Extend the pending control when the match continues from its added bytes
+		if control.Extra == 0 && position == op.Head && control.Add > 0 {
+			control.Add += op.Len()
+			position += op.Len()
+			added += op.Len()
+			return nil
+		}
+
+		if control.Add > 0 || control.Extra > 0 || op.Head != position {
+			control.Seek = op.Head - position
+			writeBsdiffControl(&controlBlock, control)
+		}
+
+		control = models.BsdiffControl{Add: op.Len()}
+		position = op.Head + op.Len()
+		added += op.Len()
+		return nil
+	})
+
+	if control.Add > 0 || control.Extra > 0 {
+		writeBsdiffControl(&controlBlock, control)
+	}
+
+	// Diff block only contains zeros (EG matched blocks are unchanged), so stream it rather than allocating every matched byte
+	blocks := make([][]byte, 3)
+	for index, block := range []io.Reader{&controlBlock, io.LimitReader(bsdiffZeros{}, added), &extra} {
+		var err error
+		if blocks[index], err = compressBsdiffBlock(block); err != nil {
+			return err
+		}
+	}
+
+	header := make([]byte, bsdiffHeaderSize)
+	copy(header, BsdiffMagic)
+	putBsdiffInt(header[8:], int64(len(blocks[0])))
+	putBsdiffInt(header[16:], int64(len(blocks[1])))
+	putBsdiffInt(header[24:], size)
+	for _, block := range append([][]byte{header}, blocks...) {
+		if _, err := writer.Write(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bsdiffInt() will decode a bsdiff integer (EG 8 bytes little-endian, with the sign in the top bit rather than two's complement).
+func bsdiffInt(buffer []byte) int64 {
+	value := int64(binary.LittleEndian.Uint64(buffer) &^ (1 << 63))
+	if buffer[7]&0x80 != 0 {
+		return -value
+	}
+
+	return value
+}
+
+// compressBsdiffBlock() will compress a block of a bsdiff patch with bzip2.
+// Function returns `compressed, nil` when successful.
+// Function returns `nil, error` when unable to compress the block.
+func compressBsdiffBlock(block io.Reader) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer, err := dsnetbzip2.NewWriter(&compressed, &dsnetbzip2.WriterConfig{Level: dsnetbzip2.BestCompression})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = io.Copy(writer, block); err != nil {
+		return nil, err
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// putBsdiffInt() will encode a bsdiff integer into the first 8 bytes of buffer (see bsdiffInt()).
+func putBsdiffInt(buffer []byte, value int64) {
+	if value < 0 {
+		binary.LittleEndian.PutUint64(buffer, uint64(-value))
+		buffer[7] |= 0x80
+		return
+	}
+
+	binary.LittleEndian.PutUint64(buffer, uint64(value))
+}
+
+// readBsdiffBlock() will decompress a bzip2 block of a bsdiff patch.
+// Note: size will be -1 for the final (extra) block, which continues to the end of the file.
+// Function returns `block, nil` when successful.
+// Function returns `nil, error` when the block is truncated or not bzip2 compressed.
+func readBsdiffBlock(reader io.Reader, size int64) ([]byte, error) {
+	var compressed bytes.Buffer
+	copied, err := io.Copy(&compressed, reader)
+	if err != nil || (size >= 0 && copied != size) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return io.ReadAll(bzip2.NewReader(&compressed))
+}
+
+// writeBsdiffControl() will encode a control of a bsdiff patch.
+func writeBsdiffControl(buffer *bytes.Buffer, control models.BsdiffControl) {
+	encoded := make([]byte, bsdiffControlLen)
+	putBsdiffInt(encoded, control.Add)
+	putBsdiffInt(encoded[8:], control.Extra)
+	putBsdiffInt(encoded[16:], control.Seek)
+	buffer.Write(encoded)
+}
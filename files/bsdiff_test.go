@@ -0,0 +1,114 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBsdiffDelta(t *testing.T) {
+	t.Run("should encode matched blocks as controls (seeking between them) + literal blocks as extra bytes", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		delta := models.Delta{
+			0:  {Head: 0, Tail: 1, IsModified: true, Value: []byte("ab")},
+			2:  {Head: 10, Tail: 13, IsModified: false, Value: []byte{}},
+			6:  {Head: 14, Tail: 15, IsModified: false, Value: []byte{}},
+			8:  {Head: 4, Tail: 5, IsModified: false, Value: []byte{}},
+			10: {Head: 10, Tail: 10, IsModified: true, Value: []byte("z")},
+		}
+
+		expectedPatch := models.BsdiffPatch{
+			Controls: []models.BsdiffControl{{Add: 0, Extra: 2, Seek: 10}, {Add: 6, Extra: 0, Seek: -12}, {Add: 2, Extra: 1, Seek: 0}},
+			Diff:     make([]byte, 8),
+			Extra:    []byte("abz"),
+			Size:     11,
+		}
+
+		// Run
+		err := EncodeBsdiffDelta(delta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte(BsdiffMagic), buffer.Bytes()[:8])
+		patch, err := DecodeBsdiff(&buffer)
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedPatch, patch)
+	})
+
+	t.Run("should encode a single control when Delta starts with a matched block", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		delta := models.Delta{0: {Head: 0, Tail: 15, IsModified: false, Value: []byte{}}}
+		// Run
+		err := EncodeBsdiffDelta(delta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		patch, err := DecodeBsdiff(&buffer)
+		require.Equal(t, nil, err)
+		require.Equal(t, []models.BsdiffControl{{Add: 16}}, patch.Controls)
+		require.Equal(t, int64(16), patch.Size)
+	})
+}
+
+func TestDecodeBsdiff(t *testing.T) {
+	t.Run("should return `emptyPatch, UnableToDecodeDeltaFromFileError` when file is not a bsdiff patch", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a bsdiff patch)")
+		// Run
+		patch, err := DecodeBsdiff(bytes.NewReader(bytes.Repeat([]byte("invalid"), 8)))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.BsdiffPatch{}, patch)
+	})
+
+	t.Run("should return `emptyPatch, UnableToDecodeDeltaFromFileError` when bsdiff patch is truncated", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, EncodeBsdiffDelta(models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")}}, &buffer))
+		expectedErrors := map[int]error{
+			bsdiffHeaderSize + 4:  errors.New(constants.UnableToDecodeDeltaFromFileError + " (corrupt bsdiff control block)"),
+			buffer.Len() - 4:      errors.New(constants.UnableToDecodeDeltaFromFileError + " (corrupt bsdiff extra block)"),
+			bsdiffHeaderSize - 16: errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a bsdiff patch)"),
+		}
+
+		for size, expectedError := range expectedErrors {
+			// Run
+			patch, err := DecodeBsdiff(bytes.NewReader(buffer.Bytes()[:size]))
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.BsdiffPatch{}, patch)
+		}
+	})
+
+	t.Run("should return `emptyPatch, UnableToDecodeDeltaFromFileError` when bsdiff header contains negative sizes", func(t *testing.T) {
+		// Setup
+		header := make([]byte, bsdiffHeaderSize)
+		copy(header, BsdiffMagic)
+		putBsdiffInt(header[8:], -1)
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (corrupt bsdiff header)")
+		// Run
+		patch, err := DecodeBsdiff(bytes.NewReader(header))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.BsdiffPatch{}, patch)
+	})
+}
+
+func TestBsdiffInt(t *testing.T) {
+	t.Run("should encode integers as little-endian with the sign in the top bit", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, 8)
+		// Run
+		putBsdiffInt(buffer, -12)
+		// Verify
+		require.Equal(t, []byte{0x0c, 0, 0, 0, 0, 0, 0, 0x80}, buffer)
+		require.Equal(t, int64(-12), bsdiffInt(buffer))
+		putBsdiffInt(buffer, 300)
+		require.Equal(t, []byte{0x2c, 0x01, 0, 0, 0, 0, 0, 0}, buffer)
+		require.Equal(t, int64(300), bsdiffInt(buffer))
+	})
+}
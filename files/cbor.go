@@ -0,0 +1,232 @@
+package files
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Magic of CBOR Signature + Delta files (EG the self-described CBOR tag 55799, see RFC 8949), which CBOR decoders will ignore
+var cborMagic = []byte{0xd9, 0xd9, 0xf7}
+
+// Deterministic encoding (EG sorted map keys), so encoding the same Signature or Delta will always create the same file
+var cborEncoder, _ = cbor.CoreDetEncOptions().EncMode()
+
+// Signatures + Deltas can contain an item per byte of a file, so the default limits (131072 items) would reject files larger than 128 KB
+// Note: the whole file is read before decoding, so each item is still bounded by the size of the file
+var cborDecoder, _ = cbor.DecOptions{MaxArrayElements: math.MaxInt32, MaxMapPairs: math.MaxInt32}.DecMode()
+
+// CBORSignature type.
+// This will contain a Signature (along with the metadata it was generated with) to be written as compact CBOR (see WriteStructToFile()), so it can be consumed by other languages.
+// Signature files will contain the array `[{weakHash: [hash, head, tail, [candidates...], legacyHash]}, [chunkSize, weakHash, strongHash]]`.
+// Note: hex encoded hashes will be written as byte strings, and other hashes as text strings.
+type CBORSignature struct {
+	Signature models.Signature
+	Metadata  models.SignatureMetadata
+}
+
+// CBORDelta type.
+// This will contain a Delta to be written as compact CBOR (see WriteStructToFile()), so it can be consumed by other languages.
+// Delta files will contain the map `{position: [head, tail, isModified, value]}`.
+type CBORDelta models.Delta
+
+// cborBlock type.
+// This will contain a Delta block encoded as a CBOR array (see CBORDelta).
+type cborBlock struct {
+	_          struct{} `cbor:",toarray"`
+	Head       int64
+	Tail       int64
+	IsModified bool
+	Value      []byte
+}
+
+// cborHash type.
+// This will contain a Strong hash, encoded as a byte string when hex encoded (EG half the size), otherwise as a text string.
+type cborHash string
+
+// cborMetadata type.
+// This will contain Signature metadata encoded as a CBOR array (see CBORSignature).
+type cborMetadata struct {
+	_          struct{} `cbor:",toarray"`
+	ChunkSize  int64
+	WeakHash   string
+	StrongHash string
+}
+
+// cborSignature type.
+// This will contain a Signature file encoded as a CBOR array (see CBORSignature).
+type cborSignature struct {
+	_         struct{} `cbor:",toarray"`
+	Signature map[int64]cborStrongSignature
+	Metadata  cborMetadata
+}
+
+// cborStrongSignature type.
+// This will contain a Signature item (including candidates) encoded as a CBOR array (see CBORSignature).
+type cborStrongSignature struct {
+	_          struct{} `cbor:",toarray"`
+	Hash       cborHash
+	Head       int64
+	Tail       int64
+	Candidates []cborStrongSignature
+	LegacyHash cborHash
+}
+
+// Implement cborHash.MarshalCBOR()
+func (hash cborHash) MarshalCBOR() ([]byte, error) {
+	decoded, err := hex.DecodeString(string(hash))
+	if err != nil || hex.EncodeToString(decoded) != string(hash) {
+		return cborEncoder.Marshal(string(hash))
+	}
+
+	return cborEncoder.Marshal(decoded)
+}
+
+// Implement cborHash.UnmarshalCBOR()
+func (hash *cborHash) UnmarshalCBOR(data []byte) error {
+	var value any
+	if err := cborDecoder.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case []byte:
+		*hash = cborHash(hex.EncodeToString(value))
+	case string:
+		*hash = cborHash(value)
+	default:
+		return &cbor.UnmarshalTypeError{CBORType: "unknown", GoType: "hash"}
+	}
+
+	return nil
+}
+
+// DecodeCBORDelta() will decode a CBOR Delta (see CBORDelta).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToDecodeDeltaFromFileError` when unable to decode the Delta (EG wrong format, or truncated file).
+func DecodeCBORDelta(reader io.Reader) (models.Delta, error) {
+	blocks := map[int64]cborBlock{}
+	if err := decodeCBOR(reader, &blocks); err != nil {
+		return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("wrong format: not a CBOR Delta, %s", err))
+	}
+
+	delta := make(models.Delta, len(blocks))
+	for position, block := range blocks {
+		// Matched blocks will have an empty Value (EG the same as blocks generated by Delta mode)
+		if block.Value == nil {
+			block.Value = []byte{}
+		}
+
+		delta[position] = models.Block{Head: block.Head, Tail: block.Tail, IsModified: block.IsModified, Value: block.Value}
+	}
+
+	return delta, nil
+}
+
+// DecodeCBORSignature() will decode a CBOR Signature, along with the metadata it records (see CBORSignature).
+// Function returns `signature, metadata, nil` when successful.
+// Function returns `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode the Signature (EG wrong format, or truncated file).
+func DecodeCBORSignature(reader io.Reader) (models.Signature, models.SignatureMetadata, error) {
+	encoded := cborSignature{}
+	if err := decodeCBOR(reader, &encoded); err != nil {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, fmt.Sprintf("wrong format: not a CBOR Signature, %s", err))
+	}
+
+	signature := make(models.Signature, len(encoded.Signature))
+	for weakHash, item := range encoded.Signature {
+		signature[weakHash] = fromCBORStrongSignature(item)
+	}
+
+	metadata := models.SignatureMetadata{ChunkSize: encoded.Metadata.ChunkSize, WeakHash: encoded.Metadata.WeakHash, StrongHash: encoded.Metadata.StrongHash}
+	return signature, metadata, nil
+}
+
+// EncodeCBORDelta() will encode a Delta as compact CBOR (see CBORDelta).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to encode the Delta, or write to the provided writer.
+func EncodeCBORDelta(delta models.Delta, writer io.Writer) error {
+	blocks := make(map[int64]cborBlock, len(delta))
+	for position, block := range delta {
+		blocks[position] = cborBlock{Head: block.Head, Tail: block.Tail, IsModified: block.IsModified, Value: block.Value}
+	}
+
+	return encodeCBOR(blocks, writer)
+}
+
+// EncodeCBORSignature() will encode a Signature as compact CBOR, followed by the provided metadata (see CBORSignature).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to encode the Signature, or write to the provided writer.
+func EncodeCBORSignature(signature models.Signature, metadata models.SignatureMetadata, writer io.Writer) error {
+	encoded := cborSignature{
+		Signature: make(map[int64]cborStrongSignature, len(signature)),
+		Metadata:  cborMetadata{ChunkSize: metadata.ChunkSize, WeakHash: metadata.WeakHash, StrongHash: metadata.StrongHash},
+	}
+
+	for weakHash, item := range signature {
+		encoded.Signature[weakHash] = toCBORStrongSignature(item)
+	}
+
+	return encodeCBOR(encoded, writer)
+}
+
+// decodeCBOR() will decode a CBOR file (see cborMagic) into model.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to read the file, the file does not start with the CBOR magic, or is unable to decode into model (EG wrong model, or truncated file).
+func decodeCBOR(reader io.Reader, model any) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(data, cborMagic) {
+		return errors.New("missing CBOR magic")
+	}
+
+	return cborDecoder.Unmarshal(data[len(cborMagic):], model)
+}
+
+// encodeCBOR() will encode model as a CBOR file, starting with the CBOR magic (see cborMagic).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to encode model, or write to the provided writer.
+func encodeCBOR(model any, writer io.Writer) error {
+	encoded, err := cborEncoder.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(append(append([]byte{}, cborMagic...), encoded...))
+	return err
+}
+
+// fromCBORStrongSignature() will convert a decoded Signature item (including candidates) back into a StrongSignature.
+func fromCBORStrongSignature(item cborStrongSignature) models.StrongSignature {
+	strong := models.StrongSignature{Hash: string(item.Hash), LegacyHash: string(item.LegacyHash), Head: item.Head, Tail: item.Tail}
+	for _, candidate := range item.Candidates {
+		strong.Candidates = append(strong.Candidates, fromCBORStrongSignature(candidate))
+	}
+
+	return strong
+}
+
+// isCBORFile() will check if a local file is CBOR encoded (EG starts with the CBOR magic), rather than gob encoded.
+func isCBORFile(fileName string) bool {
+	magic := readMagic(fileName)
+	return bytes.Equal([]byte{byte(magic >> 24), byte(magic >> 16), byte(magic >> 8)}, cborMagic)
+}
+
+// toCBORStrongSignature() will convert a StrongSignature (including candidates) into a Signature item to be encoded.
+func toCBORStrongSignature(item models.StrongSignature) cborStrongSignature {
+	encoded := cborStrongSignature{Hash: cborHash(item.Hash), LegacyHash: cborHash(item.LegacyHash), Head: item.Head, Tail: item.Tail}
+	for _, candidate := range item.Candidates {
+		encoded.Candidates = append(encoded.Candidates, toCBORStrongSignature(candidate))
+	}
+
+	return encoded
+}
@@ -0,0 +1,148 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCBORDelta(t *testing.T) {
+	t.Run("should encode Delta as a map of block arrays, starting with the CBOR magic", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		// Magic, followed by a map of 2 blocks sorted by position (EG `{0: [0, 2, true, h'616263'], 3: [4, 19, false, h'']}`)
+		expected := []byte{0xd9, 0xd9, 0xf7, 0xa2, 0x00, 0x84, 0x00, 0x02, 0xf5, 0x43, 'a', 'b', 'c', 0x03, 0x84, 0x04, 0x13, 0xf4, 0x40}
+		// Run
+		err := EncodeCBORDelta(testProtoDelta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, buffer.Bytes())
+		delta, err := DecodeCBORDelta(&buffer)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoDelta, delta)
+	})
+}
+
+func TestCBORSignature(t *testing.T) {
+	t.Run("should decode the Signature + metadata which were encoded, writing hex encoded hashes as byte strings", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		signature := models.Signature{
+			-5: {Hash: "0a0b", Head: 0, Tail: 15},
+			7:  {Hash: "not-hex", Head: 16, Tail: 31, LegacyHash: "0c", Candidates: []models.StrongSignature{{Hash: "0D", Head: 32, Tail: 47}}},
+		}
+
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "adler32", StrongHash: "sha256"}
+		// Run
+		err := EncodeCBORSignature(signature, metadata, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, bytes.Contains(buffer.Bytes(), []byte{0x42, 0x0a, 0x0b}))
+		require.Equal(t, true, bytes.Contains(buffer.Bytes(), []byte{0x62, '0', 'D'}))
+		decodedSignature, decodedMetadata, err := DecodeCBORSignature(&buffer)
+		require.Equal(t, nil, err)
+		require.Equal(t, signature, decodedSignature)
+		require.Equal(t, metadata, decodedMetadata)
+	})
+
+	t.Run("should return `UnableToDecodeSignatureFromFileError` + `UnableToDecodeDeltaFromFileError` when CBOR files contain the wrong model", func(t *testing.T) {
+		// Setup
+		var signatureBuffer, deltaBuffer bytes.Buffer
+		require.Equal(t, nil, EncodeCBORSignature(testProtoSignature, models.SignatureMetadata{}, &signatureBuffer))
+		require.Equal(t, nil, EncodeCBORDelta(testProtoDelta, &deltaBuffer))
+		expectedSignatureError := constants.UnableToDecodeSignatureFromFileError + " (wrong format: not a CBOR Signature, cbor: cannot unmarshal map"
+		expectedDeltaError := constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a CBOR Delta, cbor: cannot unmarshal array"
+		// Run
+		signature, metadata, signatureErr := DecodeCBORSignature(&deltaBuffer)
+		delta, deltaErr := DecodeCBORDelta(&signatureBuffer)
+		// Verify
+		require.Equal(t, true, strings.HasPrefix(signatureErr.Error(), expectedSignatureError))
+		require.Equal(t, models.Signature{}, signature)
+		require.Equal(t, models.SignatureMetadata{}, metadata)
+		require.Equal(t, true, strings.HasPrefix(deltaErr.Error(), expectedDeltaError))
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when CBOR Delta is truncated or missing the CBOR magic", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, EncodeCBORDelta(testProtoDelta, &buffer))
+		expectedErrors := map[string]error{
+			string(buffer.Bytes()[:buffer.Len()-1]): errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a CBOR Delta, unexpected EOF)"),
+			string(buffer.Bytes()[3:]):              errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a CBOR Delta, missing CBOR magic)"),
+		}
+
+		for data, expectedError := range expectedErrors {
+			// Run
+			delta, err := DecodeCBORDelta(bytes.NewReader([]byte(data)))
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Delta{}, delta)
+		}
+	})
+
+	t.Run("should write CBOR models as CBOR, and detect them when opening Signature + Delta files", func(t *testing.T) {
+		// Setup
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "rabin-karp"}
+		signaturePath := filepath.Join(t.TempDir(), "signature.cbor")
+		deltaPath := filepath.Join(t.TempDir(), "delta.cbor")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createFile = createWithMode
+		createNewWriter = createWriter
+		newWriter = bufio.NewWriter
+		readMagic = rdiffMagic
+		// Run
+		signatureErr := WriteStructToPath(CBORSignature{Signature: testProtoSignature, Metadata: metadata}, signaturePath)
+		deltaErr := WriteStructToPath(CBORDelta(testProtoDelta), deltaPath)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		cborSize, err := EncodedSize(CBORDelta(testProtoDelta))
+		require.Equal(t, nil, err)
+		gobSize, err := EncodedSize(testProtoDelta)
+		require.Equal(t, nil, err)
+		require.Less(t, cborSize, gobSize)
+		signature, decodedMetadata, err := OpenSignatureMetadata(signaturePath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoSignature, signature)
+		require.Equal(t, metadata, decodedMetadata)
+		delta, err := OpenDelta(deltaPath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoDelta, delta)
+	})
+	t.Run("should decode Signature + Delta files of an Original file larger than 128 KB (EG more items than the default CBOR limits)", func(t *testing.T) {
+		// Setup
+		size := int64(300 * 1024)
+		signature := make(models.Signature, size)
+		delta := make(models.Delta, size)
+		for offset := int64(0); offset < size; offset++ {
+			signature[offset] = models.StrongSignature{Hash: hex.EncodeToString([]byte{byte(offset), byte(offset >> 8)}), Head: offset, Tail: offset + 15}
+			delta[offset] = models.Block{Head: offset, Tail: offset, IsModified: true, Value: []byte{byte(offset)}}
+		}
+
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "rabin-karp", StrongHash: "sha256"}
+		var signatureBuffer, deltaBuffer bytes.Buffer
+		require.Equal(t, nil, EncodeCBORSignature(signature, metadata, &signatureBuffer))
+		require.Equal(t, nil, EncodeCBORDelta(delta, &deltaBuffer))
+		// Run
+		decodedSignature, decodedMetadata, signatureErr := DecodeCBORSignature(&signatureBuffer)
+		decodedDelta, deltaErr := DecodeCBORDelta(&deltaBuffer)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		require.Equal(t, signature, decodedSignature)
+		require.Equal(t, metadata, decodedMetadata)
+		require.Equal(t, delta, decodedDelta)
+	})
+}
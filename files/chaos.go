@@ -0,0 +1,116 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// Fault injection settings, nil when disabled (see SetChaos())
+var chaos *chaosSource
+
+// chaosSource type.
+// This will decide (at the configured rate) whether each read or write through the files layer should fail.
+// Note: decisions are drawn from a seeded random source, guarded by a mutex as reads may happen in a read-ahead goroutine.
+type chaosSource struct {
+	rate   float64
+	random *rand.Rand
+	mutex  sync.Mutex
+}
+
+// chaosReader type.
+// This will wrap a file, returning injected read errors + short reads.
+type chaosReader struct {
+	io.ReadCloser
+	source *chaosSource
+}
+
+// chaosWriter type.
+// This will wrap a file, returning injected write errors (after writing part of the data).
+type chaosWriter struct {
+	writer io.Writer
+	source *chaosSource
+}
+
+// chaosRead() will wrap the provided file so reads fail at the configured rate.
+// Function returns `file` unchanged when fault injection is disabled.
+func chaosRead(file io.ReadCloser) io.ReadCloser {
+	if chaos == nil {
+		return file
+	}
+
+	return &chaosReader{ReadCloser: file, source: chaos}
+}
+
+// chaosWrite() will wrap the provided file so writes fail at the configured rate.
+// Function returns `file` unchanged when fault injection is disabled.
+func chaosWrite(file io.Writer) io.Writer {
+	if chaos == nil {
+		return file
+	}
+
+	return &chaosWriter{writer: file, source: chaos}
+}
+
+// inject() will decide whether an operation of size bytes should fail.
+// Function returns `false, size` when the operation should succeed.
+// Function returns `true, n` when the operation should fail after n bytes (EG a short read or partial write), where n < size.
+func (c *chaosSource) inject(size int) (bool, int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if size == 0 || c.random.Float64() >= c.rate {
+		return false, size
+	}
+
+	return true, c.random.Intn(size)
+}
+
+// Read() will read from the wrapped file, injecting a short read (or an error when no bytes are read) at the configured rate.
+func (r *chaosReader) Read(p []byte) (int, error) {
+	failed, n := r.source.inject(len(p))
+	if !failed {
+		return r.ReadCloser.Read(p)
+	}
+
+	if n == 0 {
+		return 0, errors.New(constants.ChaosInjectedError)
+	}
+
+	return r.ReadCloser.Read(p[:n])
+}
+
+// SetChaos() will enable fault injection, failing reads + writes through the files layer at the provided rate (EG 0.01 fails 1% of operations).
+// Failures will be drawn from the provided seed, so a failing run can be reproduced.
+// Note: a rate of 0 will disable fault injection.
+// Function returns `nil` when successful.
+// Function returns `InvalidChaosRateError` when rate is not between 0 and 1.
+func SetChaos(rate float64, seed int64) error {
+	if rate < 0 || rate > 1 {
+		return errors.New(constants.InvalidChaosRateError)
+	}
+
+	chaos = nil
+	if rate > 0 {
+		chaos = &chaosSource{rate: rate, random: rand.New(rand.NewSource(seed))}
+	}
+
+	return nil
+}
+
+// Write() will write to the wrapped file, injecting a partial write + error at the configured rate.
+func (w *chaosWriter) Write(p []byte) (int, error) {
+	failed, n := w.source.inject(len(p))
+	if !failed {
+		return w.writer.Write(p)
+	}
+
+	written, err := w.writer.Write(p[:n])
+	if err != nil {
+		return written, err
+	}
+
+	return written, errors.New(constants.ChaosInjectedError)
+}
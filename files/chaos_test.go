@@ -0,0 +1,112 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetChaos(t *testing.T) {
+	t.Run("should return `nil` + enable fault injection when rate is between 0 and 1", func(t *testing.T) {
+		// Run
+		err := SetChaos(0.5, 1)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 0.5, chaos.rate)
+	})
+
+	t.Run("should return `nil` + disable fault injection when rate is 0", func(t *testing.T) {
+		// Run
+		err := SetChaos(0, 1)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Nil(t, chaos)
+	})
+
+	t.Run("should return `InvalidChaosRateError` when rate is not between 0 and 1", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidChaosRateError)
+		for _, rate := range []float64{-0.1, 1.5} {
+			// Run
+			err := SetChaos(rate, 1)
+			// Verify
+			require.Equal(t, expectedError, err)
+		}
+	})
+}
+
+func TestChaosRead(t *testing.T) {
+	t.Run("should return file unchanged when fault injection is disabled", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader([]byte("abc"))}
+		_ = SetChaos(0, 1)
+		// Run + Verify
+		require.Equal(t, file, chaosRead(file))
+	})
+
+	t.Run("should inject short reads + errors when every read fails", func(t *testing.T) {
+		// Setup
+		data := bytes.Repeat([]byte("abcdefgh"), 128)
+		_ = SetChaos(1, 1)
+		reader := chaosRead(&readCloserMock{Reader: bytes.NewReader(data)})
+		read := []byte{}
+		// Run
+		for len(read) < len(data) {
+			buffer := make([]byte, 64)
+			n, err := reader.Read(buffer)
+			require.Less(t, n, len(buffer))
+			read = append(read, buffer[:n]...)
+			if err != nil {
+				require.Equal(t, errors.New(constants.ChaosInjectedError), err)
+			}
+		}
+
+		// Verify bytes that were read are not corrupted
+		require.Equal(t, data, read)
+		_ = SetChaos(0, 1)
+	})
+
+	t.Run("should return `0, ChaosInjectedError` when injected read returns no bytes", func(t *testing.T) {
+		// Setup
+		_ = SetChaos(1, 1)
+		reader := chaosRead(&readCloserMock{Reader: bytes.NewReader([]byte("abc"))})
+		// Run
+		n, err := reader.Read(make([]byte, 1))
+		// Verify
+		require.Equal(t, 0, n)
+		require.Equal(t, errors.New(constants.ChaosInjectedError), err)
+		_ = SetChaos(0, 1)
+	})
+}
+
+func TestChaosWrite(t *testing.T) {
+	t.Run("should inject partial writes + errors when every write fails", func(t *testing.T) {
+		// Setup
+		var out bytes.Buffer
+		_ = SetChaos(1, 1)
+		writer := chaosWrite(&out)
+		// Run
+		n, err := writer.Write([]byte("abcdefgh"))
+		// Verify
+		require.Equal(t, errors.New(constants.ChaosInjectedError), err)
+		require.Less(t, n, 8)
+		require.Equal(t, []byte("abcdefgh")[:n], out.Bytes())
+		_ = SetChaos(0, 1)
+	})
+
+	t.Run("should write all data when fault injection is disabled", func(t *testing.T) {
+		// Setup
+		var out bytes.Buffer
+		writer := chaosWrite(&out)
+		// Run
+		n, err := io.WriteString(writer, "abcdefgh")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 8, n)
+		require.Equal(t, "abcdefgh", out.String())
+	})
+}
@@ -0,0 +1,133 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// Signature + Delta files end with a trailer containing a CRC32 checksum of the encoded payload.
+// EG: <gob payload><"GFDC"><CRC32 (big endian)>.
+// Files without a trailer (EG written before checksums were added) will still be decoded, without verification.
+const (
+	trailerMagic string = "GFDC"
+	trailerSize  int    = 8
+)
+
+// checksumEncoder type.
+// This will write a header (see headerMagic), gob encode a struct, then append a checksum trailer of the header + encoded payload.
+// Note: the payload of Signature + Delta files will be compressed when compression is enabled (see SetCompression()).
+// checksumEncoder will satisfy the `Encoder` interface.
+type checksumEncoder struct {
+	writer io.Writer
+}
+
+// checksumDecoder type.
+// This will verify the checksum trailer + header (when present) of a file, before gob decoding the payload.
+// Later calls will decode the next struct of the payload (see `sequence`).
+// checksumDecoder will satisfy the `Decoder` interface.
+type checksumDecoder struct {
+	reader  io.Reader
+	decoder Decoder
+}
+
+// sequence type.
+// This will contain multiple structs which checksumEncoder encodes one after another as a single payload (EG a Signature followed by its metadata).
+// Readers which decode a single struct will only decode the first struct, so structs can be appended without breaking older readers.
+type sequence []any
+
+// Encode() will write a header, then gob encode the provided struct (or each struct of a `sequence`) to the underlying writer, followed by a checksum trailer.
+// Function will return `nil` when successful.
+// Function will return `error` when unable to write header, encode struct or write trailer.
+func (e *checksumEncoder) Encode(model any) error {
+	values, ok := model.(sequence)
+	if !ok {
+		values = sequence{model}
+	}
+
+	kind := fileKind(model)
+	method := compressionOf(kind)
+	checksum := crc32.NewIEEE()
+	writer := io.MultiWriter(e.writer, checksum)
+	if _, err := writer.Write(compressedHeader(kind, method)); err != nil {
+		return err
+	}
+
+	compressor := newCompressor(writer, method)
+	encoder := newEncoder(compressor)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+	}
+
+	if err := compressor.Close(); err != nil {
+		return err
+	}
+
+	_, err := e.writer.Write(trailer(checksum.Sum32()))
+	return err
+}
+
+// Decode() will read the underlying reader, verify its checksum trailer + header (when present), then gob decode the payload (decompressed when compressed, see SetCompression()) into the provided struct.
+// Later calls will decode the next struct of the payload, without reading or verifying the file again.
+// Note: every block of a streamed Delta file will be decoded when decoding a Delta (see DeltaStreamWriter).
+// Function will return `nil` when successful.
+// Function will return `io.EOF` when the payload contains no more structs.
+// Function will return `ChecksumMismatchError` when the payload does not match the checksum trailer.
+// Function will return `error` when the header can not be decoded into the provided struct (EG file written by a newer version, see checkHeader()).
+// Function will return `error` when unable to decompress the payload (EG unknown compression method, see checkCompression()).
+// Function will return `error` when unable to read or decode the payload.
+func (d *checksumDecoder) Decode(model any) error {
+	if d.decoder != nil {
+		return d.decoder.Decode(model)
+	}
+
+	data, err := io.ReadAll(d.reader)
+	if err != nil {
+		return err
+	}
+
+	payload, expected, found := splitTrailer(data)
+	if found && crc32.ChecksumIEEE(payload) != expected {
+		return errors.New(constants.ChecksumMismatchError)
+	}
+
+	method := headerCompression(payload)
+	payload, version, kind, _ := splitHeader(payload)
+	if detail := checkHeader(version, kind, found, model); detail != "" {
+		return errors.New(detail)
+	} else if detail := checkCompression(method); detail != "" {
+		return errors.New(detail)
+	}
+
+	if payload, err = decompress(payload, method); err != nil {
+		return err
+	}
+
+	d.decoder = newDecoder(bytes.NewReader(payload))
+	return decodePayload(d.decoder, kind, model)
+}
+
+// splitTrailer() will split file contents into the encoded payload and its checksum trailer.
+// Function returns `payload, checksum, true` when file contents end with a checksum trailer.
+// Function returns `data, 0, false` when no trailer found (EG file written before checksums were added).
+func splitTrailer(data []byte) ([]byte, uint32, bool) {
+	if len(data) < trailerSize || string(data[len(data)-trailerSize:len(data)-len(trailerMagic)]) != trailerMagic {
+		return data, 0, false
+	}
+
+	return data[:len(data)-trailerSize], binary.BigEndian.Uint32(data[len(data)-len(trailerMagic):]), true
+}
+
+// trailer() will return the checksum trailer appended to a file for the provided checksum.
+func trailer(checksum uint32) []byte {
+	buffer := make([]byte, trailerSize)
+	copy(buffer, trailerMagic)
+	binary.BigEndian.PutUint32(buffer[len(trailerMagic):], checksum)
+	return buffer
+}
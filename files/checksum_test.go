@@ -0,0 +1,151 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumEncoderDecoder(t *testing.T) {
+	delta := models.Delta{0: models.Block{Head: 0, Tail: 4, IsModified: true, Value: []byte("hello")}}
+
+	t.Run("should write header, followed by encoded payload + checksum trailer", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		payload := bytes.NewBuffer(header(kindDelta))
+		require.Equal(t, nil, gob.NewEncoder(payload).Encode(delta))
+		// Mock
+		newEncoder = gob.NewEncoder
+		// Run
+		err := (&checksumEncoder{writer: &buffer}).Encode(delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte{'G', 'F', 'D', 'F', 0x00, 0x01, kindDelta, 0x00}, buffer.Bytes()[:headerSize])
+		require.Equal(t, append(payload.Bytes(), trailer(crc32.ChecksumIEEE(payload.Bytes()))...), buffer.Bytes())
+	})
+
+	t.Run("should return `error` when unable to encode struct", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		// Run
+		err := (&checksumEncoder{writer: &buffer}).Encode(func() {})
+		// Verify
+		require.NotEqual(t, nil, err)
+	})
+
+	t.Run("should decode struct written by checksumEncoder", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Delta{}
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(delta))
+		// Mock
+		newDecoder = gob.NewDecoder
+		// Run
+		err := (&checksumDecoder{reader: &buffer}).Decode(&result)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should decode struct without checksum trailer (EG file written before checksums were added)", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Delta{}
+		require.Equal(t, nil, gob.NewEncoder(&buffer).Encode(delta))
+		// Run
+		err := (&checksumDecoder{reader: &buffer}).Decode(&result)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `error` when header was written by a newer version, or for a different kind of file", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(delta))
+		newer := append([]byte{}, buffer.Bytes()...)
+		newer[5] = 0x02
+		payload, _, _ := splitTrailer(newer)
+		newer = append(payload, trailer(crc32.ChecksumIEEE(payload))...)
+		// Run
+		newerErr := (&checksumDecoder{reader: bytes.NewReader(newer)}).Decode(&models.Delta{})
+		kindErr := (&checksumDecoder{reader: &buffer}).Decode(&models.Signature{})
+		// Verify
+		require.Equal(t, errors.New("written by a newer version: file format version 2, this version reads up to 1"), newerErr)
+		require.Equal(t, errors.New("wrong format: file is a Delta file"), kindErr)
+	})
+
+	t.Run("should decode each struct of a sequence, with a single checksum trailer", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		metadata := models.SignatureMetadata{ChunkSize: 4096}
+		result := models.Delta{}
+		resultMetadata := models.SignatureMetadata{}
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(sequence{delta, metadata}))
+		decoder := &checksumDecoder{reader: &buffer}
+		// Run
+		err := decoder.Decode(&result)
+		metadataErr := decoder.Decode(&resultMetadata)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, metadataErr)
+		require.Equal(t, delta, result)
+		require.Equal(t, metadata, resultMetadata)
+		require.Equal(t, io.EOF, decoder.Decode(&resultMetadata))
+	})
+
+	t.Run("should return `ChecksumMismatchError` when payload does not match checksum trailer", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Delta{}
+		expectedError := errors.New(constants.ChecksumMismatchError)
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(delta))
+		corrupted := bytes.Replace(buffer.Bytes(), []byte("hello"), []byte("jello"), 1)
+		// Run
+		err := (&checksumDecoder{reader: bytes.NewReader(corrupted)}).Decode(&result)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestSplitTrailer(t *testing.T) {
+	t.Run("should return `payload, checksum, true` when data ends with checksum trailer", func(t *testing.T) {
+		// Setup
+		data := append([]byte("some-payload"), trailer(1234)...)
+		// Run
+		payload, checksum, found := splitTrailer(data)
+		// Verify
+		require.Equal(t, []byte("some-payload"), payload)
+		require.Equal(t, uint32(1234), checksum)
+		require.Equal(t, true, found)
+	})
+
+	t.Run("should return `data, 0, false` when data does not end with checksum trailer", func(t *testing.T) {
+		// Setup
+		data := []byte("some-payload")
+		// Run
+		payload, checksum, found := splitTrailer(data)
+		// Verify
+		require.Equal(t, data, payload)
+		require.Equal(t, uint32(0), checksum)
+		require.Equal(t, false, found)
+	})
+
+	t.Run("should return `data, 0, false` when data is smaller than checksum trailer", func(t *testing.T) {
+		// Setup
+		data := []byte("GFDC")
+		// Run
+		payload, checksum, found := splitTrailer(data)
+		// Verify
+		require.Equal(t, data, payload)
+		require.Equal(t, uint32(0), checksum)
+		require.Equal(t, false, found)
+	})
+}
@@ -0,0 +1,162 @@
+package files
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Signature + Delta files can be compressed (see SetCompression()), with the compression method recorded in the reserved byte of the header.
+// EG: <"GFDF"><format version><kind><compression method><compressed gob payload><checksum trailer>.
+// Note: the checksum trailer will cover the compressed payload, so corruption is found before decompressing.
+const (
+	compressNone byte = iota
+	compressGzip
+	compressZstd
+)
+
+var compressionMethods = map[string]byte{
+	"":                     compressNone,
+	constants.CompressNone: compressNone,
+	constants.CompressGzip: compressGzip,
+	constants.CompressZstd: compressZstd,
+}
+
+var compression = compressNone
+
+// nopWriteCloser type.
+// This will wrap a writer which does not need closing (EG uncompressed payloads).
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close() will do nothing, as the wrapped writer is not closed.
+func (w nopWriteCloser) Close() error {
+	return nil
+}
+
+// checkCompression() will check the compression method recorded in a file header can be decompressed by this version.
+// Function returns `""` when the compression method is known.
+// Function returns `detail` when the compression method is unknown (EG file written by a newer version).
+func checkCompression(method byte) string {
+	if method > compressZstd {
+		return fmt.Sprintf("written by a newer version: unknown compression method %d", method)
+	}
+
+	return ""
+}
+
+// compressionOf() will return the compression method used when writing a file of the provided kind.
+// Note: only Signature + Delta files (including streamed files) will be compressed.
+func compressionOf(kind byte) byte {
+	if kind == kindDelta || kind == kindDeltaStream || kind == kindSignature || kind == kindSignatureStream {
+		return compression
+	}
+
+	return compressNone
+}
+
+// compressedHeader() will return the header written at the start of a file for the provided kind, recording the compression method of its payload.
+func compressedHeader(kind byte, method byte) []byte {
+	buffer := header(kind)
+	buffer[len(headerMagic)+3] = method
+	return buffer
+}
+
+// decompress() will decompress a payload read from a file with the provided compression method.
+// Function returns `payload, nil` when successful (EG the payload itself when not compressed).
+// Function returns `nil, error` when unable to decompress the payload (EG corrupted payload).
+func decompress(payload []byte, method byte) ([]byte, error) {
+	if method == compressNone {
+		return payload, nil
+	}
+
+	reader, err := newDecompressor(bytes.NewReader(payload), method)
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, decompressError(method, err)
+	}
+
+	return data, nil
+}
+
+// decompressError() will create an error describing why a payload could not be decompressed.
+func decompressError(method byte, err error) error {
+	return fmt.Errorf("corrupted: unable to decompress payload (method %d): %s", method, err)
+}
+
+// headerCompression() will return the compression method recorded in the header of a file.
+// Function returns `compressNone` when the file has no header (EG file written before headers were added).
+func headerCompression(data []byte) byte {
+	if _, _, _, found := splitHeader(data); !found {
+		return compressNone
+	}
+
+	return data[len(headerMagic)+3]
+}
+
+// newCompressor() will init and return a writer which compresses the payload written to it with the provided compression method.
+// Note: caller must close the returned writer to flush the compressed payload (the underlying writer will not be closed).
+func newCompressor(writer io.Writer, method byte) io.WriteCloser {
+	switch method {
+	case compressGzip:
+		return gzip.NewWriter(writer)
+	case compressZstd:
+		// Encode on the calling goroutine, as Signature + Delta files are written one at a time
+		encoder, _ := zstd.NewWriter(writer, zstd.WithEncoderConcurrency(1))
+		return encoder
+	}
+
+	return nopWriteCloser{writer}
+}
+
+// newDecompressor() will init and return a reader which decompresses the payload read from the provided reader with the provided compression method.
+// Note: caller is responsible for closing the returned reader (the underlying reader will not be closed).
+// Function returns `reader, nil` when successful.
+// Function returns `nil, error` when unable to read the start of the compressed payload (EG corrupted payload), or the compression method is unknown.
+func newDecompressor(reader io.Reader, method byte) (io.ReadCloser, error) {
+	switch method {
+	case compressNone:
+		return io.NopCloser(reader), nil
+	case compressGzip:
+		decompressor, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, decompressError(method, err)
+		}
+
+		return decompressor, nil
+	case compressZstd:
+		decompressor, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, decompressError(method, err)
+		}
+
+		return decompressor.IOReadCloser(), nil
+	}
+
+	return nil, errors.New(checkCompression(method))
+}
+
+// SetCompression() will set the compression method used when writing Signature + Delta files (EG `gzip` or `zstd`), recording it in the header so files are decompressed automatically when opened.
+// Note: an empty string (or `none`) will disable compression.
+// Function returns `nil` when successful.
+// Function returns `InvalidCompressionError` when method is unknown.
+func SetCompression(method string) error {
+	value, found := compressionMethods[method]
+	if !found {
+		return errors.New(constants.InvalidCompressionError)
+	}
+
+	compression = value
+	return nil
+}
@@ -0,0 +1,159 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCompression(t *testing.T) {
+	t.Run("should set compression method, leaving it unchanged when method is unknown", func(t *testing.T) {
+		// Run
+		err := SetCompression(constants.CompressZstd)
+		invalidErr := SetCompression("lz4")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, errors.New(constants.InvalidCompressionError), invalidErr)
+		require.Equal(t, compressZstd, compression)
+		require.Equal(t, nil, SetCompression(""))
+		require.Equal(t, compressNone, compression)
+	})
+}
+
+func TestCompression(t *testing.T) {
+	// Literal blocks of repeated bytes, which compress well
+	delta := models.Delta{
+		0:    {Head: 0, Tail: 4095, IsModified: true, Value: bytes.Repeat([]byte("abcd"), 1024)},
+		4096: {Head: 4096, Tail: 8191, IsModified: true, Value: bytes.Repeat([]byte("efgh"), 1024)},
+	}
+
+	metadata := models.DeltaMetadata{UpdatedHash: "some-updated-hash"}
+	// Mock
+	getFileInfo = os.Stat
+	checkNotExists = os.IsNotExist
+	open = os.Open
+	createNewDecoder = createDecoder
+	readMagic = rdiffMagic
+	readFile = os.ReadFile
+	newEncoder = gob.NewEncoder
+	newDecoder = gob.NewDecoder
+
+	t.Run("should compress Delta files, recording the compression method in the header so they are decompressed when opened", func(t *testing.T) {
+		uncompressed, err := EncodedSize(delta)
+		require.Equal(t, nil, err)
+		for _, method := range []string{constants.CompressGzip, constants.CompressZstd} {
+			// Setup
+			var buffer bytes.Buffer
+			path := filepath.Join(t.TempDir(), "delta")
+			require.Equal(t, nil, SetCompression(method))
+			// Run
+			err := (&checksumEncoder{writer: &buffer}).Encode(sequence{delta, metadata})
+			size, sizeErr := EncodedSize(delta)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, nil, sizeErr)
+			require.Equal(t, compression, headerCompression(buffer.Bytes()))
+			require.Less(t, size, uncompressed)
+			require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+			decoded, decodedMetadata, err := OpenDeltaMetadata(path, false)
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, decoded)
+			require.Equal(t, metadata, decodedMetadata)
+		}
+
+		require.Equal(t, nil, SetCompression(""))
+	})
+
+	t.Run("should compress Signature files + streamed Signature files, decompressing them when opened", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{7: {Hash: "ab", Head: 0, Tail: 15}, -5: {Hash: "cd", Head: 16, Tail: 31}}
+		signatureMetadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "adler32"}
+		path := filepath.Join(t.TempDir(), "signature")
+		streamPath := filepath.Join(t.TempDir(), "stream")
+		var buffer, streamBuffer bytes.Buffer
+		require.Equal(t, nil, SetCompression(constants.CompressGzip))
+		// Run
+		err := (&checksumEncoder{writer: &buffer}).Encode(sequence{signature, signatureMetadata})
+		writer := NewSignatureStreamWriter(&streamBuffer, signatureMetadata)
+		require.Equal(t, nil, writer.WriteEntry(7, signature[7]))
+		require.Equal(t, nil, writer.WriteEntry(-5, signature[-5]))
+		streamErr := writer.Close()
+		// Verify
+		require.Equal(t, nil, SetCompression(""))
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, streamErr)
+		require.Equal(t, compressedHeader(kindSignature, compressGzip), buffer.Bytes()[:headerSize])
+		require.Equal(t, compressedHeader(kindSignatureStream, compressGzip), streamBuffer.Bytes()[:headerSize])
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		require.Equal(t, nil, os.WriteFile(streamPath, streamBuffer.Bytes(), 0644))
+		for _, fileName := range []string{path, streamPath} {
+			decoded, decodedMetadata, err := OpenSignatureMetadata(fileName, false)
+			require.Equal(t, nil, err)
+			require.Equal(t, signature, decoded)
+			require.Equal(t, signatureMetadata, decodedMetadata)
+		}
+	})
+
+	t.Run("should not compress Capture files", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, SetCompression(constants.CompressZstd))
+		// Run
+		err := (&checksumEncoder{writer: &buffer}).Encode(models.Capture{})
+		// Verify
+		require.Equal(t, nil, SetCompression(""))
+		require.Equal(t, nil, err)
+		require.Equal(t, header(kindCapture), buffer.Bytes()[:headerSize])
+	})
+
+	t.Run("should compress streamed Delta files, decompressing blocks as they are scanned", func(t *testing.T) {
+		for _, method := range []string{constants.CompressGzip, constants.CompressZstd} {
+			// Setup
+			path := filepath.Join(t.TempDir(), "delta")
+			require.Equal(t, nil, SetCompression(method))
+			var buffer bytes.Buffer
+			writer := NewDeltaStreamWriter(&buffer)
+			require.Equal(t, nil, writer.WriteBlock(0, delta[0]))
+			require.Equal(t, nil, writer.WriteBlock(4096, delta[4096]))
+			writer.SetMetadata(metadata)
+			require.Equal(t, nil, writer.Close())
+			require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+			// Run
+			scanner, err := OpenDeltaScanner(path, false)
+			// Verify
+			require.Equal(t, nil, err)
+			ops, scanErr := scanOps(scanner)
+			require.Equal(t, nil, scanErr)
+			require.Equal(t, 2, len(ops))
+			require.Equal(t, metadata, scanner.Metadata())
+			require.Equal(t, nil, scanner.Close())
+			decoded, err := OpenDelta(path, false)
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, decoded)
+		}
+
+		require.Equal(t, nil, SetCompression(""))
+	})
+
+	t.Run("should return `UnableToDecodeDeltaFromFileError` when compression method is unknown", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		path := filepath.Join(t.TempDir(), "delta")
+		buffer.Write(compressedHeader(kindDelta, 9))
+		require.Equal(t, nil, gob.NewEncoder(&buffer).Encode(delta))
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (written by a newer version: unknown compression method 9)")
+		// Run
+		result, err := OpenDelta(path, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+	})
+}
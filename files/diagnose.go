@@ -0,0 +1,124 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+var readFile = os.ReadFile
+
+// decodeError() will create a decode error from the provided message, appending detail (when provided) describing why decoding failed.
+// EG: `Error: Unable to decode Delta from file (truncated: file ends after 120 bytes)`.
+func decodeError(message string, detail string) error {
+	if detail == "" {
+		return errors.New(message)
+	}
+
+	return fmt.Errorf("%s (%s)", message, detail)
+}
+
+// diagnoseDecode() will re-read a file which failed to decode, and describe where + why decoding failed.
+// The file will be decoded again from memory so the exact offset of the failure is known (gob will read ahead when decoding from a file).
+// Function returns `""` when the file can not be re-read, or when the file decodes successfully.
+func diagnoseDecode(fileName string, model interface{}) string {
+	data, err := readFile(fileName)
+	if err != nil {
+		return ""
+	}
+
+	if len(data) == 0 {
+		return "file is empty"
+	}
+
+	if data[0] == '{' {
+		return "wrong format: file looks like JSON Lines, see -convertMode"
+	}
+
+	payload, expected, found := splitTrailer(data)
+	if found && crc32.ChecksumIEEE(payload) != expected {
+		return fmt.Sprintf("corrupted: checksum %08x does not match expected %08x", crc32.ChecksumIEEE(payload), expected)
+	}
+
+	method := headerCompression(payload)
+	payload, version, kind, _ := splitHeader(payload)
+	if detail := checkHeader(version, kind, found, model); detail != "" {
+		return detail
+	} else if detail := checkCompression(method); detail != "" {
+		return detail
+	}
+
+	if payload, err = decompress(payload, method); err != nil {
+		return err.Error()
+	}
+
+	reader := bytes.NewReader(payload)
+	err = decodePayload(gob.NewDecoder(reader), kind, model)
+	offset := len(payload) - reader.Len()
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Sprintf("truncated: file ends after %d bytes", len(data))
+	} else if err != nil && strings.Contains(err.Error(), "local type") {
+		return fmt.Sprintf("wrong format: %s", err)
+	} else if err != nil {
+		return fmt.Sprintf("corrupted at or before byte %d of %d: %s", offset, len(payload), err)
+	}
+
+	return ""
+}
+
+// validateDelta() will check a decoded Delta is well formed, as gob will decode any struct sharing a field name (EG a Signature file decoded as a Delta).
+// Function returns `""` when every block starts where the previous block finished, and matched blocks have a valid range.
+// Function returns `detail` describing the first invalid block.
+func validateDelta(delta models.Delta) string {
+	position := int64(0)
+	detail := ""
+	delta.Ops(func(op models.Op) error {
+		if detail = validateOp(op, position); detail != "" {
+			return errors.New(detail)
+		}
+
+		position += op.Len()
+		return nil
+	})
+
+	return detail
+}
+
+// validateOp() will check a decoded Delta operation starts at the provided position (EG where the previous operation finished), and has a valid range.
+// Function returns `""` when the operation is well formed.
+// Function returns `detail` describing why the operation is invalid.
+func validateOp(op models.Op, position int64) string {
+	if op.Position != position {
+		return fmt.Sprintf("wrong format or corrupted: blocks contain a gap or overlap at position %d", position)
+	}
+
+	if op.Kind == models.OpCopy && (op.Head < 0 || op.Tail < op.Head) {
+		return fmt.Sprintf("corrupted: block at position %d has an invalid range", op.Position)
+	}
+
+	return ""
+}
+
+// validateSignature() will check a decoded Signature is well formed, as gob will decode any struct sharing a field name (EG a Delta file decoded as a Signature).
+// Function returns `""` when every block has a Strong hash and a valid range.
+// Function returns `detail` describing the first invalid block.
+func validateSignature(signature models.Signature) string {
+	for weakHash, item := range signature {
+		if item.Hash == "" {
+			return "wrong format: blocks have no Strong hash, file may be a Delta"
+		}
+
+		if item.Head < 0 || item.Tail < item.Head {
+			return fmt.Sprintf("corrupted: block with Weak hash %d has an invalid range", weakHash)
+		}
+	}
+
+	return ""
+}
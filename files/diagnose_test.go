@@ -0,0 +1,224 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeError(t *testing.T) {
+	t.Run("should return message when detail is empty", func(t *testing.T) {
+		// Run
+		err := decodeError(constants.UnableToDecodeDeltaFromFileError, "")
+		// Verify
+		require.Equal(t, errors.New(constants.UnableToDecodeDeltaFromFileError), err)
+	})
+
+	t.Run("should append detail to message when provided", func(t *testing.T) {
+		// Run
+		err := decodeError(constants.UnableToDecodeDeltaFromFileError, "file is empty")
+		// Verify
+		require.Equal(t, constants.UnableToDecodeDeltaFromFileError+" (file is empty)", err.Error())
+	})
+}
+
+func TestDiagnoseDecode(t *testing.T) {
+	var encoded bytes.Buffer
+	err := gob.NewEncoder(&encoded).Encode(models.Delta{0: models.Block{Head: 0, Tail: 4, IsModified: true, Value: []byte("hello")}})
+	require.Equal(t, nil, err)
+
+	t.Run("should return empty detail when unable to read file", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return nil, errors.New("some-error")
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, "", detail)
+	})
+
+	t.Run("should return empty file detail when file is empty", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return []byte{}, nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, "file is empty", detail)
+	})
+
+	t.Run("should return wrong format detail when file looks like JSON Lines", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return []byte(`{"kind":"copy","position":0,"head":0,"tail":4}`), nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, "wrong format: file looks like JSON Lines, see -convertMode", detail)
+	})
+
+	t.Run("should return checksum detail when file does not match checksum trailer", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return append(encoded.Bytes(), trailer(1234)...), nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, fmt.Sprintf("corrupted: checksum %08x does not match expected 000004d2", crc32.ChecksumIEEE(encoded.Bytes())), detail)
+	})
+
+	t.Run("should return newer version detail when file header has a newer format version", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			data := append(header(kindDelta), encoded.Bytes()...)
+			data[5] = 0x07
+			return data, nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, "written by a newer version: file format version 7, this version reads up to 1", detail)
+	})
+
+	t.Run("should return wrong format detail when file header is for a different kind of file", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return append(header(kindDelta), encoded.Bytes()...), nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Signature{})
+		// Verify
+		require.Equal(t, "wrong format: file is a Delta file", detail)
+	})
+
+	t.Run("should return truncated detail when file ends early", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			return encoded.Bytes()[:20], nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.Equal(t, "truncated: file ends after 20 bytes", detail)
+	})
+
+	t.Run("should return corrupted detail when file contains invalid data", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			corrupted := append([]byte{}, encoded.Bytes()...)
+			corrupted[3] = 0xff
+			return corrupted, nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Delta{})
+		// Verify
+		require.True(t, strings.HasPrefix(detail, fmt.Sprintf("corrupted at or before byte 23 of %d: gob: ", encoded.Len())))
+	})
+
+	t.Run("should return wrong format detail when file contains a different type", func(t *testing.T) {
+		// Mock
+		readFile = func(name string) ([]byte, error) {
+			var other bytes.Buffer
+			gob.NewEncoder(&other).Encode([]string{"some-value"})
+			return other.Bytes(), nil
+		}
+		// Run
+		detail := diagnoseDecode("some-file", &models.Signature{})
+		// Verify
+		require.True(t, strings.HasPrefix(detail, "wrong format: gob: "))
+	})
+
+	t.Run("should return empty detail when file decodes successfully, with or without header", func(t *testing.T) {
+		for _, data := range [][]byte{encoded.Bytes(), append(header(kindDelta), encoded.Bytes()...)} {
+			// Mock
+			readFile = func(name string) ([]byte, error) {
+				return data, nil
+			}
+			// Run
+			detail := diagnoseDecode("some-file", &models.Delta{})
+			// Verify
+			require.Equal(t, "", detail)
+		}
+	})
+
+	// Restore, so OpenDelta() + OpenSignature() tests do not diagnose mocked file contents
+	readFile = os.ReadFile
+}
+
+func TestValidateDelta(t *testing.T) {
+	t.Run("should return empty detail when Delta is valid", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}},
+			5: models.Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")},
+			7: models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+		}
+		// Run
+		detail := validateDelta(delta)
+		// Verify
+		require.Equal(t, "", detail)
+	})
+
+	t.Run("should return gap detail when blocks do not start where previous block finished", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0:      models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+			123456: models.Block{Head: 5, Tail: 9, IsModified: false, Value: []byte{}},
+		}
+		// Run
+		detail := validateDelta(delta)
+		// Verify
+		require.Equal(t, "wrong format or corrupted: blocks contain a gap or overlap at position 5", detail)
+	})
+
+	t.Run("should return invalid range detail when matched block Tail is before Head", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 4, Tail: 0, IsModified: false, Value: []byte{}}}
+		// Run
+		detail := validateDelta(delta)
+		// Verify
+		require.Equal(t, "corrupted: block at position 0 has an invalid range", detail)
+	})
+}
+
+func TestValidateSignature(t *testing.T) {
+	t.Run("should return empty detail when Signature is valid", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{123: models.StrongSignature{Hash: "some-strong-hash", Head: 0, Tail: 15}}
+		// Run
+		detail := validateSignature(signature)
+		// Verify
+		require.Equal(t, "", detail)
+	})
+
+	t.Run("should return wrong format detail when blocks have no Strong hash", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{0: models.StrongSignature{Head: 0, Tail: 4}}
+		// Run
+		detail := validateSignature(signature)
+		// Verify
+		require.Equal(t, "wrong format: blocks have no Strong hash, file may be a Delta", detail)
+	})
+
+	t.Run("should return invalid range detail when block Tail is before Head", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{123: models.StrongSignature{Hash: "some-strong-hash", Head: 15, Tail: 0}}
+		// Run
+		detail := validateSignature(signature)
+		// Verify
+		require.Equal(t, "corrupted: block with Weak hash 123 has an invalid range", detail)
+	})
+}
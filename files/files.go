@@ -2,7 +2,9 @@ package files
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/gob"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,18 +16,20 @@ import (
 )
 
 var (
-	open             = os.Open
-	getFileInfo      = os.Stat
-	checkNotExists   = os.IsNotExist
-	mkdir            = os.Mkdir
-	createFile       = os.Create
-	logger           = utils.Logger
-	newWriter        = bufio.NewWriter
-	createNewWriter  = createWriter
-	createNewEncoder = createEncoder
-	newEncoder       = gob.NewEncoder
-	newDecoder       = gob.NewDecoder
-	createNewDecoder = createDecoder
+	open                  = os.Open
+	getFileInfo           = os.Stat
+	checkNotExists        = os.IsNotExist
+	mkdir                 = os.Mkdir
+	createFile            = createWithMode
+	logger                = utils.Logger
+	newWriter             = bufio.NewWriter
+	createNewWriter       = createWriter
+	createNewEncoder      = createEncoder
+	newEncoder            = gob.NewEncoder
+	newDecoder            = gob.NewDecoder
+	createNewDecoder      = createDecoder
+	createReadAheadReader = createReadAhead
+	readMagic             = rdiffMagic
 )
 
 // Encoder interface for mocking gob.NewEncoder.
@@ -47,33 +51,69 @@ type Writer interface {
 
 const outputDir string = "./Outputs/"
 
+// byteCounter type.
+// This will count the bytes written to it, discarding their contents.
+type byteCounter struct {
+	size int64
+}
+
+// Write() will count the bytes written.
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.size += int64(len(p))
+	return len(p), nil
+}
+
 // createFolder() will attempt to create a folder based on provided folderName prop.
+// Folder will be created with the permissions configured by SetPermissions().
 // Function will return `nil` when folder is created successfully.
 // Function will return `unable to create folder` error when unable to create folder dir.
 func createFolder(folderName string) error {
-	if err := mkdir(folderName, os.ModePerm); err != nil {
+	if err := mkdir(folderName, dirMode); err != nil {
 		return errors.New(constants.UnableToCreateNewFolderError)
 	}
 
 	return nil
 }
 
-// createDecoder() will init and return a new gob file decoder.
+// createDecoder() will init and return a new gob file decoder, which verifies the checksum trailer of the file (when present).
 // Returned file decoder will satisfy the `Decoder` interface.
 func createDecoder(file *os.File) Decoder {
-	return newDecoder(file)
+	return &checksumDecoder{reader: chaosRead(file)}
 }
 
-// createEncoder() will init and return a new gob file encoder.
+// createEncoder() will init and return a new gob file encoder, which appends a checksum trailer to the file.
 // Returned file encoder will satisfy the `Encoder` interface.
 func createEncoder(file *os.File) Encoder {
-	return newEncoder(file)
+	return &checksumEncoder{writer: chaosWrite(file)}
+}
+
+// CreateOutputFile() will create a partial file in Outputs folder (based on provided fileName) for streaming output to.
+// Note: caller is responsible for closing the returned file, then calling FinishPartial() with OutputPath(fileName).
+// Function will return `file, nil` when file has been created successfully.
+// Function will return `nil, UnableToCreateFileError` error when unable to create file.
+// Function will return `nil, error` when unable to verify if Output folder exists.
+func CreateOutputFile(fileName string) (*os.File, error) {
+	// Verify `Outputs` folder exists
+	err := verifyOutputDirExists()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create partial file
+	partial := startPartial(outputDir + fileName)
+	file, err := createFile(partial)
+	if err != nil {
+		_ = FinishPartial(outputDir+fileName, true)
+		return nil, errors.New(constants.UnableToCreateFileError)
+	}
+
+	return file, nil
 }
 
 // createWriter() will init and return a new bufio file writer.
 // Returned file writer will satisfy the `Writer` interface.
 func createWriter(file *os.File) Writer {
-	return newWriter(file)
+	return newWriter(chaosWrite(file))
 }
 
 // doesExist() checks if a file/folder exists and returns `true, nil` if specified file/folder is found.
@@ -102,43 +142,195 @@ func doesExist(path string, isFile bool) (bool, error) {
 	return true, nil
 }
 
-// OpenDelta() will attempt to open a local file and decode a Delta from it.
+// EncodedSize() will return the size (bytes) of a struct once encoded, without writing it to a file.
+// Note: this will match the size of the file created by WriteStructToFile() (including header + checksum trailer, compression of Signature + Delta files, or in the rdiff, bsdiff, JSON, protobuf + CBOR formats for their models).
+// Function will return `size, nil` when successful.
+// Function will return `0, error` when unable to encode the struct.
+func EncodedSize(model any) (int64, error) {
+	if payload, isFormat, err := encodeFormat(model); isFormat {
+		return int64(len(payload)), err
+	}
+
+	counter := &byteCounter{}
+	if err := (&checksumEncoder{writer: counter}).Encode(model); err != nil {
+		return 0, err
+	}
+
+	return counter.size, nil
+}
+
+// encodeFormat() will encode an rdiff model (EG RdiffSignature or RdiffDelta), BsdiffDelta, JSON model (EG JSONSignature or JSONDelta), protobuf model (EG ProtoSignature or ProtoDelta) or CBOR model (EG CBORSignature or CBORDelta) in its format.
+// Function returns `payload, true, nil` when successful.
+// Function returns `nil, false, nil` when model is not an rdiff, bsdiff, JSON, protobuf or CBOR model (EG gob encoded instead).
+// Function returns `nil, true, error` when unable to encode the model (see EncodeRdiffSignature()).
+func encodeFormat(model any) ([]byte, bool, error) {
+	var payload bytes.Buffer
+	var err error
+	switch model := model.(type) {
+	case RdiffSignature:
+		err = EncodeRdiffSignature(model.Signature, model.Metadata, &payload)
+	case RdiffDelta:
+		err = EncodeRdiffDelta(models.Delta(model), &payload)
+	case BsdiffDelta:
+		err = EncodeBsdiffDelta(models.Delta(model), &payload)
+	case JSONSignature:
+		err = encodeJSON(model, &payload)
+	case JSONDelta:
+		err = encodeJSON(models.Delta(model), &payload)
+	case ProtoSignature:
+		err = EncodeProtoSignature(model.Signature, model.Metadata, &payload)
+	case ProtoDelta:
+		err = EncodeProtoDelta(models.Delta(model), &payload)
+	case CBORSignature:
+		err = EncodeCBORSignature(model.Signature, model.Metadata, &payload)
+	case CBORDelta:
+		err = EncodeCBORDelta(models.Delta(model), &payload)
+	default:
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, true, err
+	}
+
+	return payload.Bytes(), true, nil
+}
+
+// FileSize() will return the size (bytes) of a local file.
+// Function will return `size, nil` when successful.
+// Function will return `0, error` when unable to get file info.
+func FileSize(fileName string) (int64, error) {
+	fileInfo, err := getFileInfo(fileName)
+	if err != nil {
+		return 0, err
+	}
+
+	return fileInfo.Size(), nil
+}
+
+// OpenDelta() will attempt to open a local file and decode a Delta from it (see OpenDeltaMetadata()).
 // Note: this will be used for the `patch` process.
 // Function will return `Delta, nil` when successfully retrieve Delta from file.
-// Function will return `emptyDelta, error` when unable to check existence of Delta file.
-// Function will return `emptyDelta, DeltaFileDoesNotExistError` when Delta file not found.
-// Function will return `emptyDelta, UnableToOpenDeltaFileError` when unable to open Delta file.
-// Function will return `emptyDelta, UnableToDecodeDeltaFromFileError` when unable to decode Delta from file (EG invalid file).
+// Function will return `emptyDelta, error` in the same cases as OpenDeltaMetadata().
 func OpenDelta(fileName string, verbose bool) (models.Delta, error) {
+	delta, _, err := OpenDeltaMetadata(fileName, verbose)
+	return delta, err
+}
+
+// OpenDeltaMetadata() will attempt to open a local file and decode a Delta from it, along with the metadata recorded after the Delta (see models.DeltaMetadata).
+// Note: rdiff Delta files (EG generated by `rdiff delta`) will be detected by their magic number (see DecodeRdiffDelta()).
+// Note: JSON Delta files (EG written as JSONDelta) will be detected by their first character, protobuf Delta files (EG written as ProtoDelta) by their version field, and CBOR Delta files (EG written as CBORDelta) by their magic number.
+// Note: metadata will only be recorded by gob Delta files, so will be empty for other formats (and gob Delta files generated before metadata was recorded).
+// Note: compressed gob Delta files (see SetCompression()) will be decompressed using the compression method recorded in their header.
+// Function will return `Delta, metadata, nil` when successfully retrieve Delta from file.
+// Function will return `emptyDelta, emptyMetadata, error` when unable to check existence of Delta file.
+// Function will return `emptyDelta, emptyMetadata, DeltaFileDoesNotExistError` when Delta file not found.
+// Function will return `emptyDelta, emptyMetadata, UnableToOpenDeltaFileError` when unable to open Delta file.
+// Function will return `emptyDelta, emptyMetadata, UnableToDecodeDeltaFromFileError` when unable to decode Delta or metadata from file (EG invalid file).
+// Note: decode errors will describe where + why decoding failed when possible (EG truncated, wrong format or corrupted file).
+func OpenDeltaMetadata(fileName string, verbose bool) (models.Delta, models.DeltaMetadata, error) {
 	delta := models.Delta{}
+	metadata := models.DeltaMetadata{}
 	// Check if Delta file exists
 	exists, err := doesExist(fileName, true)
 	if err != nil {
-		return delta, err
+		return delta, metadata, err
 	} else if !exists {
-		return delta, errors.New(constants.DeltaFileDoesNotExistError)
+		return delta, metadata, errors.New(constants.DeltaFileDoesNotExistError)
 	}
 
 	// Open Delta file
 	file, err := open(fileName)
 	if err != nil {
-		return delta, errors.New(constants.UnableToOpenDeltaFileError)
+		return delta, metadata, errors.New(constants.UnableToOpenDeltaFileError)
 	}
 
 	defer file.Close()
-	// Create new file decoder
-	decoder := createNewDecoder(file)
-	// Decode file to Delta struct
-	err = decoder.Decode(&delta)
-	if err != nil {
-		return delta, errors.New(constants.UnableToDecodeDeltaFromFileError)
+	if readMagic(fileName) == rdiffDeltaMagic {
+		// Decode rdiff Delta file (EG generated by `rdiff delta`)
+		delta, err = DecodeRdiffDelta(chaosRead(file))
+		if err != nil {
+			return models.Delta{}, metadata, err
+		}
+	} else if isCBORFile(fileName) {
+		// Decode CBOR Delta file (EG written as CBORDelta)
+		delta, err = DecodeCBORDelta(chaosRead(file))
+		if err != nil {
+			return models.Delta{}, metadata, err
+		}
+	} else if isProtoFile(fileName) {
+		// Decode protobuf Delta file (EG written as ProtoDelta)
+		delta, err = DecodeProtoDelta(chaosRead(file))
+		if err != nil {
+			return models.Delta{}, metadata, err
+		}
+	} else if isJSONFile(fileName) {
+		// Decode JSON Delta file (EG written as JSONDelta)
+		err = json.NewDecoder(chaosRead(file)).Decode(&delta)
+		if err != nil {
+			return models.Delta{}, metadata, decodeError(constants.UnableToDecodeDeltaFromFileError, "wrong format: not a JSON Delta")
+		}
+	} else {
+		// Create new file decoder
+		decoder := createNewDecoder(file)
+		// Decode file to Delta struct
+		err = decoder.Decode(&delta)
+		if err != nil {
+			return models.Delta{}, metadata, decodeError(constants.UnableToDecodeDeltaFromFileError, diagnoseDecode(fileName, &models.Delta{}))
+		}
+
+		// Decode metadata recorded after the Delta (EG none for Delta files generated before metadata was recorded)
+		if err = decoder.Decode(&metadata); err == io.EOF {
+			metadata = models.DeltaMetadata{}
+		} else if err != nil {
+			return models.Delta{}, models.DeltaMetadata{}, errors.New(constants.UnableToDecodeDeltaFromFileError)
+		}
+	}
+
+	if detail := validateDelta(delta); detail != "" {
+		return models.Delta{}, models.DeltaMetadata{}, decodeError(constants.UnableToDecodeDeltaFromFileError, detail)
 	}
 
 	logger(fmt.Sprintf("File Delta: %+v\n", delta), verbose)
-	return delta, nil
+	logger(fmt.Sprintf("Delta metadata: %+v\n", metadata), verbose)
+	return delta, metadata, nil
+}
+
+// OpenCapture() will attempt to open a local file and decode a Capture of input streams from it.
+// Function will return `Capture, nil` when successfully retrieve Capture from file.
+// Function will return `emptyCapture, error` when unable to check existence of Capture file.
+// Function will return `emptyCapture, CaptureFileDoesNotExistError` when Capture file not found.
+// Function will return `emptyCapture, UnableToOpenCaptureFileError` when unable to open Capture file.
+// Function will return `emptyCapture, UnableToDecodeCaptureFromFileError` when unable to decode Capture from file (EG invalid file).
+func OpenCapture(fileName string) (models.Capture, error) {
+	capture := models.Capture{}
+	// Check if Capture file exists
+	exists, err := doesExist(fileName, true)
+	if err != nil {
+		return capture, err
+	} else if !exists {
+		return capture, errors.New(constants.CaptureFileDoesNotExistError)
+	}
+
+	// Open Capture file
+	file, err := open(fileName)
+	if err != nil {
+		return capture, errors.New(constants.UnableToOpenCaptureFileError)
+	}
+
+	defer file.Close()
+	// Decode file to Capture struct
+	if err = createNewDecoder(file).Decode(&capture); err != nil {
+		return models.Capture{}, decodeError(constants.UnableToDecodeCaptureFromFileError, diagnoseDecode(fileName, &models.Capture{}))
+	}
+
+	return capture, nil
 }
 
 // OpenFile() will attempt to open a local file and will return a file reader when successful.
+// Returned reader will be backed by a read-ahead goroutine so file reads overlap with processing of the data.
+// Note: the read-ahead goroutine + file will be released once EOF is reached, or once the returned reader is garbage collected (EG caller stopped reading early).
+// Note: reads will fail at the configured rate when fault injection is enabled (see SetChaos()).
 // Function will catch and return error when unable to access specified file.
 // Function will return `file does not exist` error when specified file does not exist.
 func OpenFile(fileName string) (*bufio.Reader, error) {
@@ -157,42 +349,111 @@ func OpenFile(fileName string) (*bufio.Reader, error) {
 	}
 
 	// Return file reader
-	return bufio.NewReader(file), nil
+	return bufio.NewReader(createReadAheadReader(chaosRead(file))), nil
 }
 
-// OpenSignature() will attempt to open a local file and decode a Signature from the file.
+// OpenSignature() will attempt to open a local file and decode a Signature from the file (see OpenSignatureMetadata()).
 // Function will return `Signature, nil` when successfully retrieve a Signature from file.
-// Function will return `emptySignature, error` when unable to check existence of Signature file.
-// Function will return `emptySignature, SignatureFileDoesNotExistError` when Signature file not found.
-// Function will return `emptySignature, UnableToOpenSignatureFileError` when unable to open Signature file.
-// Function will return `emptySignature, UnableToDecodeSignatureFromFileError` when unable to decode Signature from file (EG invalid signature file).
+// Function will return `emptySignature, error` in the same cases as OpenSignatureMetadata().
 func OpenSignature(fileName string, verbose bool) (models.Signature, error) {
+	signature, _, err := OpenSignatureMetadata(fileName, verbose)
+	return signature, err
+}
+
+// OpenSignatureMetadata() will attempt to open a local file and decode a Signature from the file, along with the metadata recorded after the Signature (see models.SignatureMetadata).
+// Note: rdiff Signature files (EG generated by `rdiff signature`) will be detected by their magic number, recording their block size + hashes as metadata (see DecodeRdiffSignature()).
+// Note: JSON Signature files (EG written as JSONSignature) will be detected by their first character, protobuf Signature files (EG written as ProtoSignature) by their version field, and CBOR Signature files (EG written as CBORSignature) by their magic number.
+// Note: compressed gob Signature files (see SetCompression()) will be decompressed using the compression method recorded in their header.
+// Function will return `Signature, metadata, nil` when successfully retrieve a Signature from file (metadata will be empty for files generated before metadata was recorded).
+// Function will return `emptySignature, emptyMetadata, error` when unable to check existence of Signature file.
+// Function will return `emptySignature, emptyMetadata, SignatureFileDoesNotExistError` when Signature file not found.
+// Function will return `emptySignature, emptyMetadata, UnableToOpenSignatureFileError` when unable to open Signature file.
+// Function will return `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode Signature or metadata from file (EG invalid signature file).
+// Note: decode errors will describe where + why decoding failed when possible (EG truncated, wrong format or corrupted file).
+func OpenSignatureMetadata(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
 	signature := models.Signature{}
 	// Check if Signature file exists
 	exists, err := doesExist(fileName, true)
 	if err != nil {
-		return signature, err
+		return signature, models.SignatureMetadata{}, err
 	} else if !exists {
-		return signature, errors.New(constants.SignatureFileDoesNotExistError)
+		return signature, models.SignatureMetadata{}, errors.New(constants.SignatureFileDoesNotExistError)
 	}
 
 	// Open Signature file
 	file, err := open(fileName)
 	if err != nil {
-		return signature, errors.New(constants.UnableToOpenSignatureFileError)
+		return signature, models.SignatureMetadata{}, errors.New(constants.UnableToOpenSignatureFileError)
 	}
 
 	defer file.Close()
-	// Create new file decoder
-	decoder := createNewDecoder(file)
-	// Decode file to Signature struct
-	err = decoder.Decode(&signature)
-	if err != nil {
-		return signature, errors.New(constants.UnableToDecodeSignatureFromFileError)
+	var metadata models.SignatureMetadata
+	if _, isRdiff := rdiffSignatureMagics[readMagic(fileName)]; isRdiff {
+		// Decode rdiff Signature file (EG generated by `rdiff signature`)
+		signature, metadata, err = DecodeRdiffSignature(chaosRead(file))
+		if err != nil {
+			return models.Signature{}, models.SignatureMetadata{}, err
+		}
+	} else if isCBORFile(fileName) || isProtoFile(fileName) {
+		// Decode CBOR or protobuf Signature file (EG written as CBORSignature or ProtoSignature)
+		if isCBORFile(fileName) {
+			signature, metadata, err = DecodeCBORSignature(chaosRead(file))
+		} else {
+			signature, metadata, err = DecodeProtoSignature(chaosRead(file))
+		}
+
+		if err != nil {
+			return models.Signature{}, models.SignatureMetadata{}, err
+		}
+
+		if detail := validateSignature(signature); detail != "" {
+			return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, detail)
+		}
+	} else if isJSONFile(fileName) {
+		// Decode JSON Signature file (EG written as JSONSignature)
+		model := JSONSignature{}
+		if err = json.NewDecoder(chaosRead(file)).Decode(&model); err != nil || model.Signature == nil {
+			return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, "wrong format: not a JSON Signature")
+		}
+
+		signature, metadata = model.Signature, model.Metadata
+		if detail := validateSignature(signature); detail != "" {
+			return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, detail)
+		}
+	} else {
+		// Decode file to Signature struct
+		reader := &GobSignatureReader{decoder: createNewDecoder(file)}
+		signature, err = reader.ReadSignature()
+		if err != nil {
+			return signature, models.SignatureMetadata{}, decodeError(err.Error(), diagnoseDecode(fileName, &models.Signature{}))
+		}
+
+		if detail := validateSignature(signature); detail != "" {
+			return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, detail)
+		}
+
+		metadata, err = reader.ReadMetadata()
+		if err != nil {
+			return models.Signature{}, models.SignatureMetadata{}, err
+		}
 	}
 
 	logger(fmt.Sprintf("File Signature: %+v\n", signature), verbose)
-	return signature, nil
+	logger(fmt.Sprintf("Signature metadata: %+v\n", metadata), verbose)
+	return signature, metadata, nil
+}
+
+// OutputFileExists() will check if a file (based on provided fileName) already exists in Outputs folder.
+// Function will return `true, nil` when file exists.
+// Function will return `false, nil` when file (or Outputs folder) does not exist.
+// Function will return `false, error` when unable to check existence of file, or found a folder dir instead of file.
+func OutputFileExists(fileName string) (bool, error) {
+	return doesExist(OutputPath(fileName), true)
+}
+
+// OutputPath() will return the path of a file (based on provided fileName) in Outputs folder.
+func OutputPath(fileName string) string {
+	return outputDir + fileName
 }
 
 // verifyOutputDirExists() will check for the existence of an `Outputs/` folder and will create if not exists.
@@ -216,7 +477,24 @@ func verifyOutputDirExists() error {
 	return nil
 }
 
+// WriteDeltaToFile() will create a Delta file in Outputs folder in the same way as WriteStructToFile(), encoding the provided metadata after the Delta.
+// Note: Delta + metadata will be encoded as a single payload (see `sequence`), so older readers can still decode the Delta.
+// Function will return `nil` when file has been created and written to successfully.
+// Function will return `UnableToCreateFileError` error when unable to create file.
+// Function will return `UnableToWriteToFileError` error when unable to write output to file after creation.
+// Function will return `error` when unable to verify if Output folder exists.
+func WriteDeltaToFile(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+	return WriteStructToFile(sequence{delta, metadata}, fileName)
+}
+
 // WriteStructToFile() will create a file in Outputs folder (based on provided fileName), and encode provided struct before writing to file.
+// Struct will be written to a `.partial` file, which will be renamed into place once complete (see FinishPartial()).
+// Note: rdiff models (EG RdiffSignature + RdiffDelta) will be written in the rdiff format, so they can be used by librsync (see WriteStructToPath()).
+// Note: BsdiffDelta will be written in the bsdiff format, so it can be applied by `bspatch`.
+// Note: JSON models (EG JSONSignature + JSONDelta) will be written as JSON, so they can be inspected or consumed by non-Go tooling.
+// Note: protobuf models (EG ProtoSignature + ProtoDelta) will be written as protobuf messages (see filediff.proto), so they can be consumed by other languages.
+// Note: CBOR models (EG CBORSignature + CBORDelta) will be written as compact CBOR, so they can be consumed by other languages.
+// Note: gob Signature + Delta files will be compressed when compression is enabled (see SetCompression()).
 // Function will return `nil` when file has been created and written to successfully.
 // Function will return `UnableToCreateFileError` error when unable to create file.
 // Function will return `UnableToWriteToFileError` error when unable to write output to file after creation.
@@ -228,13 +506,54 @@ func WriteStructToFile(model any, fileName string) error {
 		return err
 	}
 
+	// Create partial file + write struct
+	path := outputDir + fileName
+	err = WriteStructToPath(model, startPartial(path))
+	if err != nil {
+		_ = FinishPartial(path, true)
+		return err
+	}
+
+	// Rename partial file into place
+	err = FinishPartial(path, false)
+	if err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("%s created: %s%s\n", fileName, outputDir, fileName), true)
+	return nil
+}
+
+// WriteStructToPath() will create a file at the provided path, and encode provided struct before writing to file.
+// Note: unlike WriteStructToFile(), file will not be created in the Outputs folder (EG used for temp files).
+// Note: rdiff models (EG RdiffSignature + RdiffDelta), BsdiffDelta, JSON, protobuf + CBOR models (EG JSONSignature, ProtoDelta or CBORDelta) will be written in their formats instead (see encodeFormat()).
+// Function will return `nil` when file has been created and written to successfully.
+// Function will return `UnableToCreateFileError` error when unable to create file.
+// Function will return `UnableToWriteToFileError` error when unable to write output to file after creation.
+// Function will return `RdiffUnsupportedHashError` or `RdiffMissingBlockError` when an RdiffSignature can not be written in the rdiff format (see EncodeRdiffSignature()).
+func WriteStructToPath(model any, path string) error {
+	// Encode rdiff, bsdiff, JSON, protobuf + CBOR models up front, so unsupported Signatures are reported before the file is created
+	payload, isFormat, err := encodeFormat(model)
+	if err != nil {
+		return err
+	}
+
 	// Create file
-	file, err := createFile(outputDir + fileName)
+	file, err := createFile(path)
 	if err != nil {
 		return errors.New(constants.UnableToCreateFileError)
 	}
 
 	defer file.Close()
+	if isFormat {
+		writer := createNewWriter(file)
+		if _, err = writer.Write(payload); err != nil || writer.Flush() != nil {
+			return errors.New(constants.UnableToWriteToFileError)
+		}
+
+		return nil
+	}
+
 	// Create encoder
 	encoder := createNewEncoder(file)
 	// Encode struct
@@ -243,7 +562,6 @@ func WriteStructToFile(model any, fileName string) error {
 		return errors.New(constants.UnableToWriteToFileError)
 	}
 
-	logger(fmt.Sprintf("%s created: %s%s\n", fileName, outputDir, fileName), true)
 	return nil
 }
 
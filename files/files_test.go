@@ -2,11 +2,13 @@ package files
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/gob"
 	"errors"
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/curtismenmuir/go-file-diff/constants"
@@ -56,11 +58,15 @@ type fileInfoMock struct {
 	os.FileInfo
 	// Set test props
 	isDir bool
+	size  int64
 }
 
 // Overwrite fileInfoMock.IsDir() to consider test prop
 func (m fileInfoMock) IsDir() bool { return m.isDir }
 
+// Overwrite fileInfoMock.Size() to consider test prop
+func (m fileInfoMock) Size() int64 { return m.size }
+
 // Mock for io.Reader interface
 type readerMock struct{}
 
@@ -119,38 +125,96 @@ func TestCreateFolder(t *testing.T) {
 }
 
 func TestCreateDecoder(t *testing.T) {
-	t.Run("should return file decoder", func(t *testing.T) {
+	t.Run("should return checksum file decoder", func(t *testing.T) {
 		// Setup
 		file := os.File{}
-		reader := readerMock{}
-		decoder := gob.NewDecoder(reader)
-		// Mock
-		newDecoder = func(r io.Reader) *gob.Decoder {
-			return decoder
-		}
-
+		expected := &checksumDecoder{reader: &file}
 		// Run
 		result := createDecoder(&file)
 		// Verify
-		require.Equal(t, decoder, result)
+		require.Equal(t, expected, result)
 	})
 }
 
 func TestCreateEncoder(t *testing.T) {
-	t.Run("should return file encoder", func(t *testing.T) {
+	t.Run("should return checksum file encoder", func(t *testing.T) {
 		// Setup
 		file := os.File{}
-		writer := writerMock{}
-		encoder := gob.NewEncoder(writer)
+		expected := &checksumEncoder{writer: &file}
+		// Run
+		result := createEncoder(&file)
+		// Verify
+		require.Equal(t, expected, result)
+	})
+}
+
+func TestCreateOutputFile(t *testing.T) {
+	t.Run("should return `file, nil` when Output dir exists and successfully created file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		createdName := ""
 		// Mock
-		newEncoder = func(w io.Writer) *gob.Encoder {
-			return encoder
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: true}
+			return fileInfo, nil
+		}
+
+		createFile = func(name string) (*os.File, error) {
+			createdName = name
+			return &file, nil
 		}
 
 		// Run
-		result := createEncoder(&file)
+		result, err := CreateOutputFile(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, &file, result)
+		require.Equal(t, outputDir+fileName+PartialExtension, createdName)
+		require.Equal(t, true, partials[createdName])
+		delete(partials, createdName)
+	})
+
+	t.Run("should return `nil, UnableToCreateFileError` when unable to create file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToCreateFileError)
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: true}
+			return fileInfo, nil
+		}
+
+		createFile = func(name string) (*os.File, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		result, err := CreateOutputFile(fileName)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, result)
+	})
+
+	t.Run("should return `nil, UnableToCreateOutputsFolderError` when unable to create Outputs folder", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToCreateOutputsFolderError)
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		checkNotExists = func(err error) bool {
+			return true
+		}
+
+		mkdir = func(name string, perm fs.FileMode) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		result, err := CreateOutputFile(fileName)
 		// Verify
-		require.Equal(t, encoder, result)
+		require.Equal(t, expectedError, err)
+		require.Nil(t, result)
 	})
 }
 
@@ -254,6 +318,122 @@ func TestDoesExists(t *testing.T) {
 	})
 }
 
+func TestEncodedSize(t *testing.T) {
+	t.Run("should return `size, nil` matching the encoded struct + checksum trailer", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 2, IsModified: true, Value: []byte("new")}}
+		expected := bytes.Buffer{}
+		require.Equal(t, nil, (&checksumEncoder{writer: &expected}).Encode(delta))
+		// Run
+		size, err := EncodedSize(delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(expected.Len()), size)
+	})
+
+	t.Run("should return `0, error` when unable to encode struct", func(t *testing.T) {
+		// Run
+		size, err := EncodedSize(func() {})
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Equal(t, int64(0), size)
+	})
+}
+
+func TestFileSize(t *testing.T) {
+	t.Run("should return `size, nil` when file info found", func(t *testing.T) {
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: false, size: 1024}
+			return fileInfo, nil
+		}
+
+		// Run
+		size, err := FileSize(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(1024), size)
+	})
+
+	t.Run("should return `0, error` when unable to get file info", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return nil, expectedError
+		}
+
+		// Run
+		size, err := FileSize(fileName)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, int64(0), size)
+	})
+}
+
+func TestOpenCapture(t *testing.T) {
+	t.Run("should return `capture, nil` when successfully read Capture from file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return fileInfoMock{isDir: false}, nil
+		}
+
+		open = func(name string) (*os.File, error) {
+			return &file, nil
+		}
+
+		createNewDecoder = func(file *os.File) Decoder {
+			return decoderMock{isError: false}
+		}
+
+		// Run
+		capture, err := OpenCapture(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Capture{}, capture)
+	})
+
+	t.Run("should return `emptyCapture, CaptureFileDoesNotExistError` when Capture file does not exist", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.CaptureFileDoesNotExistError)
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		checkNotExists = func(err error) bool {
+			return true
+		}
+
+		// Run
+		capture, err := OpenCapture(fileName)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Capture{}, capture)
+	})
+
+	t.Run("should return `emptyCapture, UnableToOpenCaptureFileError` when unable to open Capture file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToOpenCaptureFileError)
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return fileInfoMock{isDir: false}, nil
+		}
+
+		open = func(name string) (*os.File, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		capture, err := OpenCapture(fileName)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Capture{}, capture)
+	})
+}
+
 func TestOpenDelta(t *testing.T) {
 	t.Run("should return `delta, nil` when successfully read Delta from file", func(t *testing.T) {
 		// Setup
@@ -375,6 +555,91 @@ func TestOpenDelta(t *testing.T) {
 		require.Equal(t, expectedError, err)
 		require.Equal(t, expectedDelta, delta)
 	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` with detail when unable to decode Delta from file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		decoder := decoderMock{isError: true}
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (file is empty)")
+		expectedDelta := models.Delta{}
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: false}
+			return fileInfo, nil
+		}
+
+		open = func(name string) (*os.File, error) {
+			return &file, nil
+		}
+
+		createNewDecoder = func(file *os.File) Decoder {
+			return decoder
+		}
+
+		readFile = func(name string) ([]byte, error) {
+			return []byte{}, nil
+		}
+
+		// Run
+		delta, err := OpenDelta(fileName, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, expectedDelta, delta)
+		readFile = os.ReadFile
+	})
+}
+
+func TestOpenDeltaMetadata(t *testing.T) {
+	delta := models.Delta{
+		0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")},
+		3: {Head: 3, Tail: 5, IsModified: true, Value: []byte("def")},
+	}
+
+	metadata := models.DeltaMetadata{UpdatedHash: "some-updated-hash"}
+
+	t.Run("should return `delta, metadata, nil` when metadata is recorded after the Delta (including streamed Delta files)", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		streamPath := filepath.Join(t.TempDir(), "stream")
+		var buffer bytes.Buffer
+		writer := NewDeltaStreamWriter(&buffer)
+		require.Equal(t, nil, writer.WriteBlock(0, delta[0]))
+		require.Equal(t, nil, writer.WriteBlock(3, delta[3]))
+		writer.SetMetadata(metadata)
+		require.Equal(t, nil, writer.Close())
+		require.Equal(t, nil, os.WriteFile(streamPath, buffer.Bytes(), 0644))
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createFile = createWithMode
+		createNewWriter = createWriter
+		newWriter = bufio.NewWriter
+		createNewDecoder = createDecoder
+		newEncoder = gob.NewEncoder
+		newDecoder = gob.NewDecoder
+		readMagic = rdiffMagic
+		require.Equal(t, nil, WriteStructToPath(sequence{delta, metadata}, path))
+		for _, fileName := range []string{path, streamPath} {
+			// Run
+			result, resultMetadata, err := OpenDeltaMetadata(fileName, false)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, result)
+			require.Equal(t, metadata, resultMetadata)
+		}
+	})
+
+	t.Run("should return `delta, emptyMetadata, nil` when no metadata is recorded (EG older Delta files)", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		require.Equal(t, nil, WriteStructToPath(delta, path))
+		// Run
+		result, resultMetadata, err := OpenDeltaMetadata(path, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+		require.Equal(t, models.DeltaMetadata{}, resultMetadata)
+	})
 }
 
 func TestOpenFile(t *testing.T) {
@@ -392,6 +657,10 @@ func TestOpenFile(t *testing.T) {
 			return &file, nil
 		}
 
+		createReadAheadReader = func(file io.ReadCloser) io.Reader {
+			return file
+		}
+
 		// Run
 		result, err := OpenFile(fileName)
 		// Verify
@@ -586,6 +855,84 @@ func TestOpenSignature(t *testing.T) {
 		require.Equal(t, expectedError, err)
 		require.Equal(t, expectedSignature, signature)
 	})
+
+	t.Run("should return `emptySignature, UnableToDecodeSignatureFromFileError` with detail when unable to decode Signature from file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		decoder := decoderMock{isError: true}
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError + " (file is empty)")
+		expectedSignature := models.Signature{}
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: false}
+			return fileInfo, nil
+		}
+
+		open = func(name string) (*os.File, error) {
+			return &file, nil
+		}
+
+		createNewDecoder = func(file *os.File) Decoder {
+			return decoder
+		}
+
+		readFile = func(name string) ([]byte, error) {
+			return []byte{}, nil
+		}
+
+		// Run
+		signature, err := OpenSignature(fileName, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, expectedSignature, signature)
+		readFile = os.ReadFile
+	})
+}
+
+func TestOutputFileExists(t *testing.T) {
+	t.Run("should return `true, nil` when file exists in Outputs folder", func(t *testing.T) {
+		// Setup
+		checkedPath := ""
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			checkedPath = name
+			fileInfo := fileInfoMock{isDir: false}
+			return fileInfo, nil
+		}
+
+		// Run
+		exists, err := OutputFileExists(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, exists)
+		require.Equal(t, outputDir+fileName, checkedPath)
+	})
+
+	t.Run("should return `false, nil` when file does not exist in Outputs folder", func(t *testing.T) {
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		checkNotExists = func(err error) bool {
+			return true
+		}
+
+		// Run
+		exists, err := OutputFileExists(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, exists)
+	})
+}
+
+func TestOutputPath(t *testing.T) {
+	t.Run("should return path of file in Outputs folder", func(t *testing.T) {
+		// Run
+		result := OutputPath(fileName)
+		// Verify
+		require.Equal(t, outputDir+fileName, result)
+	})
 }
 
 func TestVerifyOutputDirExists(t *testing.T) {
@@ -683,6 +1030,10 @@ func TestWriteStructToFile(t *testing.T) {
 			return encoder
 		}
 
+		renameFile = func(oldpath string, newpath string) error {
+			return nil
+		}
+
 		// Run
 		result := WriteStructToFile(signature, fileName)
 		// Verify
@@ -715,6 +1066,10 @@ func TestWriteStructToFile(t *testing.T) {
 			return encoder
 		}
 
+		renameFile = func(oldpath string, newpath string) error {
+			return nil
+		}
+
 		// Run
 		result := WriteStructToFile(signature, fileName)
 		// Verify
@@ -786,6 +1141,105 @@ func TestWriteStructToFile(t *testing.T) {
 		// Verify
 		require.Equal(t, expectedError, result)
 	})
+
+	t.Run("should return `UnableToWriteToFileError` error when unable to rename partial file into place", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		encoder := encoderMock{isError: false}
+		signature := models.Signature{}
+		expectedError := errors.New(constants.UnableToWriteToFileError)
+		removed := ""
+		// Mock
+		getFileInfo = func(name string) (fs.FileInfo, error) {
+			fileInfo := fileInfoMock{isDir: false}
+			return fileInfo, nil
+		}
+
+		createFile = func(name string) (*os.File, error) {
+			return &file, nil
+		}
+
+		createNewEncoder = func(file *os.File) Encoder {
+			return encoder
+		}
+
+		renameFile = func(oldpath string, newpath string) error {
+			return errors.New(errorMessage)
+		}
+
+		removeFile = func(name string) error {
+			removed = name
+			return nil
+		}
+
+		// Run
+		result := WriteStructToFile(signature, fileName)
+		// Verify
+		require.Equal(t, expectedError, result)
+		require.Equal(t, outputDir+fileName+PartialExtension, removed)
+	})
+}
+
+func TestWriteStructToPath(t *testing.T) {
+	t.Run("should return `nil` when successfully written Signature to file at path", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		encoder := encoderMock{isError: false}
+		signature := models.Signature{}
+		createdPath := ""
+		// Mock
+		createFile = func(name string) (*os.File, error) {
+			createdPath = name
+			return &file, nil
+		}
+
+		createNewEncoder = func(file *os.File) Encoder {
+			return encoder
+		}
+
+		// Run
+		result := WriteStructToPath(signature, "/tmp/"+fileName)
+		// Verify
+		require.Equal(t, nil, result)
+		require.Equal(t, "/tmp/"+fileName, createdPath)
+	})
+
+	t.Run("should return `UnableToCreateFileError` error when unable to create file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		signature := models.Signature{}
+		expectedError := errors.New(constants.UnableToCreateFileError)
+		// Mock
+		createFile = func(name string) (*os.File, error) {
+			return &file, errors.New(errorMessage)
+		}
+
+		// Run
+		result := WriteStructToPath(signature, "/tmp/"+fileName)
+		// Verify
+		require.Equal(t, expectedError, result)
+	})
+
+	t.Run("should return `UnableToWriteToFileError` error when unable to write to file", func(t *testing.T) {
+		// Setup
+		file := os.File{}
+		encoder := encoderMock{isError: true}
+		signature := models.Signature{}
+		expectedError := errors.New(constants.UnableToWriteToFileError)
+		// Mock
+		createFile = func(name string) (*os.File, error) {
+			return &file, nil
+		}
+
+		createNewEncoder = func(file *os.File) Encoder {
+			return encoder
+		}
+
+		// Run
+		result := WriteStructToPath(signature, "/tmp/"+fileName)
+		// Verify
+		require.Equal(t, expectedError, result)
+	})
 }
 
 func TestWriteToFile(t *testing.T) {
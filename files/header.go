@@ -0,0 +1,114 @@
+package files
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Signature + Delta files start with a header containing the file format version, and the kind of file, so decode errors can be precise (EG file written by a newer version).
+// EG: <"GFDF"><format version (big endian uint16)><kind><reserved><gob payload><checksum trailer>.
+// Files without a header (EG written before headers were added) will still be decoded.
+const (
+	headerMagic   string = "GFDF"
+	headerSize    int    = 8
+	formatVersion uint16 = 1
+)
+
+// Kinds of file recorded in the header.
+const (
+	kindUnknown byte = iota
+	kindSignature
+	kindDelta
+	kindCapture
+	kindDeltaStream
+	kindSignatureStream
+)
+
+var kindNames = map[byte]string{
+	kindSignature:       "Signature",
+	kindDelta:           "Delta",
+	kindCapture:         "Capture",
+	kindDeltaStream:     "Delta",
+	kindSignatureStream: "Signature",
+}
+
+// DecodeGobDelta() will decode a gob encoded Delta (EG a Delta file), verifying its header + checksum trailer when present.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, error` when unable to decode the Delta (EG file written by a newer version, or a Signature file).
+func DecodeGobDelta(reader io.Reader) (models.Delta, error) {
+	delta := models.Delta{}
+	if err := (&checksumDecoder{reader: reader}).Decode(&delta); err != nil {
+		return models.Delta{}, err
+	}
+
+	return delta, nil
+}
+
+// checkHeader() will check a file header can be decoded into model by this version.
+// Note: streamed Delta + Signature files (see DeltaStreamWriter + SignatureStreamWriter) will be decoded as Delta + Signature files, but must end with a checksum trailer (EG not cut short while streaming).
+// Function returns `""` when the file can be decoded (EG unknown kinds will be decoded as before).
+// Function returns `detail` describing why the file can not be decoded (EG written by a newer version, or the wrong kind of file).
+func checkHeader(version uint16, kind byte, hasTrailer bool, model any) string {
+	if version > formatVersion {
+		return fmt.Sprintf("written by a newer version: file format version %d, this version reads up to %d", version, formatVersion)
+	}
+
+	if (kind == kindDeltaStream || kind == kindSignatureStream) && !hasTrailer {
+		return fmt.Sprintf("truncated: streamed %s file has no checksum trailer", kindNames[kind])
+	}
+
+	expected := fileKind(model)
+	if kind == kindDeltaStream {
+		kind = kindDelta
+	} else if kind == kindSignatureStream {
+		kind = kindSignature
+	}
+
+	if kind != kindUnknown && expected != kindUnknown && kind != expected {
+		return fmt.Sprintf("wrong format: file is a %s file", kindNames[kind])
+	}
+
+	return ""
+}
+
+// fileKind() will return the kind of file a model (or pointer to a model) is written as.
+// Note: a `sequence` will be the kind of its first struct (EG a Signature followed by its metadata).
+func fileKind(model any) byte {
+	switch model := model.(type) {
+	case sequence:
+		if len(model) > 0 {
+			return fileKind(model[0])
+		}
+	case models.Signature, *models.Signature:
+		return kindSignature
+	case models.Delta, *models.Delta:
+		return kindDelta
+	case models.Capture, *models.Capture:
+		return kindCapture
+	}
+
+	return kindUnknown
+}
+
+// header() will return the header written at the start of a file for the provided kind (see headerMagic).
+func header(kind byte) []byte {
+	buffer := make([]byte, headerSize)
+	copy(buffer, headerMagic)
+	binary.BigEndian.PutUint16(buffer[len(headerMagic):], formatVersion)
+	buffer[len(headerMagic)+2] = kind
+	return buffer
+}
+
+// splitHeader() will split a payload into its header fields and the gob payload.
+// Function returns `payload, version, kind, true` when the payload starts with a header.
+// Function returns `data, 0, kindUnknown, false` when no header found (EG file written before headers were added).
+func splitHeader(data []byte) ([]byte, uint16, byte, bool) {
+	if len(data) < headerSize || string(data[:len(headerMagic)]) != headerMagic {
+		return data, 0, kindUnknown, false
+	}
+
+	return data[headerSize:], binary.BigEndian.Uint16(data[len(headerMagic):]), data[len(headerMagic)+2], true
+}
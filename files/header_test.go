@@ -0,0 +1,97 @@
+package files
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitHeader(t *testing.T) {
+	t.Run("should return `payload, version, kind, true` when data starts with header", func(t *testing.T) {
+		// Setup
+		data := append(header(kindSignature), []byte("some-payload")...)
+		// Run
+		payload, version, kind, found := splitHeader(data)
+		// Verify
+		require.Equal(t, []byte("some-payload"), payload)
+		require.Equal(t, formatVersion, version)
+		require.Equal(t, kindSignature, kind)
+		require.Equal(t, true, found)
+	})
+
+	t.Run("should return `data, 0, kindUnknown, false` when data does not start with header", func(t *testing.T) {
+		for _, data := range [][]byte{[]byte("some-payload"), []byte("GFDF")} {
+			// Run
+			payload, version, kind, found := splitHeader(data)
+			// Verify
+			require.Equal(t, data, payload)
+			require.Equal(t, uint16(0), version)
+			require.Equal(t, kindUnknown, kind)
+			require.Equal(t, false, found)
+		}
+	})
+}
+
+func TestCheckHeader(t *testing.T) {
+	t.Run("should return empty detail when file can be decoded into model", func(t *testing.T) {
+		require.Equal(t, "", checkHeader(formatVersion, kindDelta, true, &models.Delta{}))
+		require.Equal(t, "", checkHeader(formatVersion, kindSignature, true, &models.Signature{}))
+		require.Equal(t, "", checkHeader(formatVersion, kindSignature, true, &models.SignatureMetadata{}))
+		require.Equal(t, "", checkHeader(formatVersion, kindUnknown, true, &models.Delta{}))
+	})
+
+	t.Run("should return newer version detail before wrong format detail", func(t *testing.T) {
+		require.Equal(t, "written by a newer version: file format version 2, this version reads up to 1", checkHeader(2, kindDelta, true, &models.Signature{}))
+	})
+
+	t.Run("should return wrong format detail when file is a different kind of file", func(t *testing.T) {
+		require.Equal(t, "wrong format: file is a Signature file", checkHeader(formatVersion, kindSignature, true, &models.Delta{}))
+		require.Equal(t, "wrong format: file is a Capture file", checkHeader(formatVersion, kindCapture, true, &models.Delta{}))
+		require.Equal(t, "wrong format: file is a Delta file", checkHeader(formatVersion, kindDeltaStream, true, &models.Signature{}))
+	})
+
+	t.Run("should decode streamed Delta + Signature files as Delta + Signature files, returning truncated detail when missing checksum trailer", func(t *testing.T) {
+		require.Equal(t, "", checkHeader(formatVersion, kindDeltaStream, true, &models.Delta{}))
+		require.Equal(t, "truncated: streamed Delta file has no checksum trailer", checkHeader(formatVersion, kindDeltaStream, false, &models.Delta{}))
+		require.Equal(t, "", checkHeader(formatVersion, kindSignatureStream, true, &models.Signature{}))
+		require.Equal(t, "truncated: streamed Signature file has no checksum trailer", checkHeader(formatVersion, kindSignatureStream, false, &models.Signature{}))
+	})
+}
+
+func TestFileKind(t *testing.T) {
+	t.Run("should return kind of model, or kind of first struct of a sequence", func(t *testing.T) {
+		require.Equal(t, kindSignature, fileKind(sequence{models.Signature{}, models.SignatureMetadata{}}))
+		require.Equal(t, kindDelta, fileKind(models.Delta{}))
+		require.Equal(t, kindCapture, fileKind(&models.Capture{}))
+		require.Equal(t, kindUnknown, fileKind(sequence{}))
+		require.Equal(t, kindUnknown, fileKind([]string{}))
+	})
+}
+
+func TestDecodeGobDelta(t *testing.T) {
+	testDelta := models.Delta{0: models.Block{Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")}}
+
+	t.Run("should return `delta, nil` when Delta was written by checksumEncoder", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(testDelta))
+		// Run
+		delta, err := DecodeGobDelta(&buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, error` when file is a Signature file", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(sequence{testProtoSignature, models.SignatureMetadata{}}))
+		// Run
+		delta, err := DecodeGobDelta(&buffer)
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
@@ -0,0 +1,62 @@
+package files
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// JSONSignature type.
+// This will contain a Signature (along with the metadata it was generated with) to be written as JSON (see WriteStructToFile()), so it can be inspected or consumed by non-Go tooling.
+// EG: {"signature":[{"weak":123,"hash":"some-strong-hash","head":0,"tail":15}],"metadata":{"chunkSize":16,"weakHash":"rabin-karp"}}.
+type JSONSignature struct {
+	Signature models.Signature         `json:"signature"`
+	Metadata  models.SignatureMetadata `json:"metadata"`
+}
+
+// JSONDelta type.
+// This will contain a Delta to be written as JSON (see WriteStructToFile()), so it can be inspected or consumed by non-Go tooling.
+// EG: [{"position":0,"head":0,"tail":4,"isModified":true,"value":"YWJjZGU="}].
+type JSONDelta models.Delta
+
+// encodeJSON() will encode a JSON model (EG JSONSignature or JSONDelta), indented so it is readable.
+// Note: as the first line will only contain `{` or `[`, JSON files will not be mistaken for JSON Lines (EG `-format=jsonl`).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to write to the provided writer.
+func encodeJSON(model any, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(model)
+}
+
+// isJSONFile() will check if a local file is JSON encoded (EG starts with `{` or `[`), rather than gob encoded.
+// Note: gob files start with the length of their first type definition, which will not match for Signature + Delta files.
+// Note: the file will be opened separately, so the position of an already open file is unchanged.
+// Function returns `false` when unable to read the file.
+func isJSONFile(fileName string) bool {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for {
+		next, err := reader.ReadByte()
+		if err != nil {
+			return false
+		}
+
+		switch next {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
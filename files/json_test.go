@@ -0,0 +1,101 @@
+package files
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON(t *testing.T) {
+	t.Run("should write JSON models as indented JSON, and detect them when opening Signature + Delta files", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{123: {Hash: "some-strong-hash", Head: 0, Tail: 15}, 456: {Hash: "another-strong-hash", Head: 16, Tail: 31}}
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "adler32"}
+		delta := models.Delta{
+			0: {Head: 0, Tail: 4, IsModified: true, Value: []byte("abcde")},
+			5: {Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+		}
+
+		signaturePath := filepath.Join(t.TempDir(), "signature.json")
+		deltaPath := filepath.Join(t.TempDir(), "delta.json")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createFile = createWithMode
+		createNewWriter = createWriter
+		newWriter = bufio.NewWriter
+		readMagic = rdiffMagic
+		// Run
+		signatureErr := WriteStructToPath(JSONSignature{Signature: signature, Metadata: metadata}, signaturePath)
+		deltaErr := WriteStructToPath(JSONDelta(delta), deltaPath)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		deltaBytes, err := os.ReadFile(deltaPath)
+		require.Equal(t, nil, err)
+		expectedPrefix := "[\n  {\n    \"position\": 0,\n    \"head\": 0,\n    \"tail\": 4,\n    \"isModified\": true,\n    \"value\": \"YWJjZGU=\"\n  },"
+		require.Equal(t, expectedPrefix, string(deltaBytes[:len(expectedPrefix)]))
+		size, err := EncodedSize(JSONDelta(delta))
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(len(deltaBytes)), size)
+		decodedSignature, decodedMetadata, err := OpenSignatureMetadata(signaturePath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, signature, decodedSignature)
+		require.Equal(t, metadata, decodedMetadata)
+		decodedDelta, err := OpenDelta(deltaPath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, decodedDelta)
+	})
+
+	t.Run("should return `UnableToDecodeSignatureFromFileError` + `UnableToDecodeDeltaFromFileError` when JSON files contain the wrong model", func(t *testing.T) {
+		// Setup
+		signaturePath := filepath.Join(t.TempDir(), "signature.json")
+		deltaPath := filepath.Join(t.TempDir(), "delta.json")
+		require.Equal(t, nil, os.WriteFile(signaturePath, []byte(`[{"position":0,"head":0,"tail":4,"isModified":false,"value":""}]`), 0644))
+		require.Equal(t, nil, os.WriteFile(deltaPath, []byte(`{"signature":[],"metadata":{"chunkSize":16}}`), 0644))
+		expectedSignatureError := errors.New(constants.UnableToDecodeSignatureFromFileError + " (wrong format: not a JSON Signature)")
+		expectedDeltaError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a JSON Delta)")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		readMagic = rdiffMagic
+		// Run
+		signature, metadata, signatureErr := OpenSignatureMetadata(signaturePath, false)
+		delta, deltaErr := OpenDelta(deltaPath, false)
+		// Verify
+		require.Equal(t, expectedSignatureError, signatureErr)
+		require.Equal(t, models.Signature{}, signature)
+		require.Equal(t, models.SignatureMetadata{}, metadata)
+		require.Equal(t, expectedDeltaError, deltaErr)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
+
+func TestIsJSONFile(t *testing.T) {
+	t.Run("should return `true` only when file starts with a JSON object or array", func(t *testing.T) {
+		// Setup
+		contents := map[string]bool{
+			" \n{\"signature\":[]}": true,
+			"[]":                    true,
+			"\x16\xff\x81\x04":      false,
+			"":                      false,
+		}
+
+		for content, expected := range contents {
+			path := filepath.Join(t.TempDir(), "file")
+			require.Equal(t, nil, os.WriteFile(path, []byte(content), 0644))
+			// Run
+			isJSON := isJSONFile(path)
+			// Verify
+			require.Equal(t, expected, isJSON)
+		}
+
+		require.Equal(t, false, isJSONFile(filepath.Join(t.TempDir(), "missing")))
+	})
+}
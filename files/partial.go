@@ -0,0 +1,82 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// PartialExtension will be appended to output files while they are being written.
+// Partial files will be renamed into place once complete, so an incomplete output is never mistaken for a complete one.
+const PartialExtension string = ".partial"
+
+var (
+	renameFile   = os.Rename
+	removeFile   = os.Remove
+	keepPartial  = false
+	partials     = map[string]bool{}
+	partialsLock sync.Mutex
+)
+
+// CleanupPartials() will discard every partial output which is still being written (EG when the run is interrupted).
+// Note: partial outputs will be kept (and logged) instead when SetKeepPartial() has been enabled.
+func CleanupPartials() {
+	partialsLock.Lock()
+	defer partialsLock.Unlock()
+	for partial := range partials {
+		discardPartial(partial)
+		delete(partials, partial)
+	}
+}
+
+// discardPartial() will remove a partial output, or log its path when SetKeepPartial() has been enabled.
+func discardPartial(partial string) {
+	if keepPartial {
+		logger(fmt.Sprintf("Partial output kept: %s\n", partial), true)
+		return
+	}
+
+	_ = removeFile(partial)
+}
+
+// FinishPartial() will complete a partial output started for the provided path (EG by CreateOutputFile()).
+// When successful, the partial output will be renamed into place at path.
+// When failed, the partial output will be removed (or kept with its `.partial` suffix when SetKeepPartial() has been enabled).
+// Note: partial output file must be closed before calling FinishPartial().
+// Function returns `nil` when successful, or when failed is set.
+// Function returns `UnableToWriteToFileError` when unable to rename the partial output into place.
+func FinishPartial(path string, failed bool) error {
+	partial := path + PartialExtension
+	partialsLock.Lock()
+	defer partialsLock.Unlock()
+	delete(partials, partial)
+	if failed {
+		discardPartial(partial)
+		return nil
+	}
+
+	if err := renameFile(partial, path); err != nil {
+		discardPartial(partial)
+		return errors.New(constants.UnableToWriteToFileError)
+	}
+
+	return nil
+}
+
+// SetKeepPartial() will enable/disable keeping partial outputs (suffixed `.partial`) when a run fails or is interrupted.
+func SetKeepPartial(enabled bool) {
+	keepPartial = enabled
+}
+
+// startPartial() will record a partial output as being written for the provided path.
+// Returned partial path should be written to, then completed with FinishPartial().
+func startPartial(path string) string {
+	partial := path + PartialExtension
+	partialsLock.Lock()
+	defer partialsLock.Unlock()
+	partials[partial] = true
+	return partial
+}
@@ -0,0 +1,126 @@
+package files
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupPartials(t *testing.T) {
+	t.Run("should remove partial outputs still being written", func(t *testing.T) {
+		// Setup
+		removed := []string{}
+		partial := startPartial(fileName)
+		// Mock
+		SetKeepPartial(false)
+		removeFile = func(name string) error {
+			removed = append(removed, name)
+			return nil
+		}
+
+		// Run
+		CleanupPartials()
+		// Verify
+		require.Equal(t, []string{partial}, removed)
+		require.Equal(t, 0, len(partials))
+	})
+
+	t.Run("should keep + log partial outputs when keep partial enabled", func(t *testing.T) {
+		// Setup
+		removed := []string{}
+		logged := ""
+		partial := startPartial(fileName)
+		// Mock
+		SetKeepPartial(true)
+		removeFile = func(name string) error {
+			removed = append(removed, name)
+			return nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = message
+		}
+
+		// Run
+		CleanupPartials()
+		// Verify
+		require.Equal(t, []string{}, removed)
+		require.Equal(t, "Partial output kept: "+partial+"\n", logged)
+		require.Equal(t, 0, len(partials))
+		SetKeepPartial(false)
+	})
+}
+
+func TestFinishPartial(t *testing.T) {
+	t.Run("should return `nil` after renaming partial output into place", func(t *testing.T) {
+		// Setup
+		renamed := []string{}
+		partial := startPartial(fileName)
+		// Mock
+		renameFile = func(oldpath string, newpath string) error {
+			renamed = append(renamed, oldpath, newpath)
+			return nil
+		}
+
+		// Run
+		err := FinishPartial(fileName, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []string{partial, fileName}, renamed)
+		require.Equal(t, 0, len(partials))
+	})
+
+	t.Run("should return `nil` after removing partial output when failed", func(t *testing.T) {
+		// Setup
+		removed := ""
+		startPartial(fileName)
+		// Mock
+		SetKeepPartial(false)
+		removeFile = func(name string) error {
+			removed = name
+			return nil
+		}
+
+		// Run
+		err := FinishPartial(fileName, true)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, fileName+PartialExtension, removed)
+		require.Equal(t, 0, len(partials))
+	})
+
+	t.Run("should return `nil` and keep partial output when failed and keep partial enabled", func(t *testing.T) {
+		// Setup
+		removed := ""
+		startPartial(fileName)
+		// Mock
+		SetKeepPartial(true)
+		removeFile = func(name string) error {
+			removed = name
+			return nil
+		}
+
+		// Run
+		err := FinishPartial(fileName, true)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "", removed)
+		SetKeepPartial(false)
+	})
+
+	t.Run("should return `UnableToWriteToFileError` when unable to rename partial output into place", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWriteToFileError)
+		// Mock
+		renameFile = func(oldpath string, newpath string) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		err := FinishPartial(fileName, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
@@ -0,0 +1,74 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// Default permissions of created folders + files (before the process umask is applied).
+const (
+	defaultDirMode  os.FileMode = 0o755
+	defaultFileMode os.FileMode = 0o644
+)
+
+var (
+	openFileWithMode = os.OpenFile
+	dirMode          = defaultDirMode
+	fileMode         = defaultFileMode
+)
+
+// createWithMode() will create (or truncate) a file using the configured file permissions.
+// Note: the process umask will still be applied by the OS, so permissions can only be narrowed by it.
+func createWithMode(name string) (*os.File, error) {
+	return openFileWithMode(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileMode)
+}
+
+// DirMode() will return the permissions used when creating folders.
+func DirMode() os.FileMode {
+	return dirMode
+}
+
+// FileMode() will return the permissions used when creating files.
+func FileMode() os.FileMode {
+	return fileMode
+}
+
+// parseMode() will parse an octal permissions string (EG `0750`), returning the fallback when value is empty.
+// Function returns `mode, nil` when successful.
+// Function returns `0, InvalidPermissionsError` when value is not an octal number between 0000 and 0777.
+func parseMode(value string, fallback os.FileMode) (os.FileMode, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, errors.New(constants.InvalidPermissionsError)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// SetPermissions() will set the permissions used when creating folders + files (EG Outputs folder, Signature + Delta files).
+// Permissions are provided as octal strings (EG `0750`), and an empty string will restore the default.
+// Note: permissions will be left unchanged when either value is invalid.
+// Function returns `nil` when successful.
+// Function returns `InvalidPermissionsError` when either value is not an octal number between 0000 and 0777.
+func SetPermissions(dir string, file string) error {
+	newDirMode, err := parseMode(dir, defaultDirMode)
+	if err != nil {
+		return err
+	}
+
+	newFileMode, err := parseMode(file, defaultFileMode)
+	if err != nil {
+		return err
+	}
+
+	dirMode = newDirMode
+	fileMode = newFileMode
+	return nil
+}
@@ -0,0 +1,67 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWithMode(t *testing.T) {
+	t.Run("should create file with configured permissions", func(t *testing.T) {
+		// Setup
+		var perm os.FileMode
+		flags := 0
+		// Mock
+		fileMode = 0o600
+		openFileWithMode = func(name string, flag int, mode os.FileMode) (*os.File, error) {
+			flags = flag
+			perm = mode
+			return nil, nil
+		}
+
+		// Run
+		_, err := createWithMode(fileName)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, os.FileMode(0o600), perm)
+		require.Equal(t, os.O_RDWR|os.O_CREATE|os.O_TRUNC, flags)
+		fileMode = defaultFileMode
+		openFileWithMode = os.OpenFile
+	})
+}
+
+func TestSetPermissions(t *testing.T) {
+	t.Run("should set folder + file permissions from octal strings", func(t *testing.T) {
+		// Run
+		err := SetPermissions("0750", "640")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, os.FileMode(0o750), DirMode())
+		require.Equal(t, os.FileMode(0o640), FileMode())
+	})
+
+	t.Run("should restore default permissions when values are empty", func(t *testing.T) {
+		// Run
+		err := SetPermissions("", "")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, defaultDirMode, DirMode())
+		require.Equal(t, defaultFileMode, FileMode())
+	})
+
+	t.Run("should return `InvalidPermissionsError` and leave permissions unchanged when value is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidPermissionsError)
+		for _, values := range [][2]string{{"0750", "0899"}, {"rwx", "0640"}, {"0750", "01777"}} {
+			// Run
+			err := SetPermissions(values[0], values[1])
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, defaultDirMode, DirMode())
+			require.Equal(t, defaultFileMode, FileMode())
+		}
+	})
+}
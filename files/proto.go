@@ -0,0 +1,348 @@
+package files
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Version of the protobuf schema (see filediff.proto), written as the first field of Signature + Delta messages
+const protoVersion uint64 = 1
+
+// ProtoSignature type.
+// This will contain a Signature (along with the metadata it was generated with) to be written as a protobuf Signature message (see WriteStructToFile() + filediff.proto), so it can be consumed by other languages.
+type ProtoSignature struct {
+	Signature models.Signature
+	Metadata  models.SignatureMetadata
+}
+
+// ProtoDelta type.
+// This will contain a Delta to be written as a protobuf Delta message (see WriteStructToFile() + filediff.proto), so it can be consumed by other languages.
+type ProtoDelta models.Delta
+
+// DecodeProtoDelta() will decode a protobuf Delta message (see filediff.proto).
+// Note: unknown fields will be skipped, so files written by newer schemas can still be read.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToDecodeDeltaFromFileError` when unable to decode the message (EG wrong format, or truncated file).
+func DecodeProtoDelta(reader io.Reader) (models.Delta, error) {
+	message, err := io.ReadAll(reader)
+	if err != nil {
+		return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "unable to read protobuf Delta")
+	}
+
+	delta := models.Delta{}
+	version := uint64(0)
+	valid := protoFields(message, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.VarintType:
+			version = varint
+		case number == 2 && kind == protowire.BytesType:
+			var position int64
+			var block models.Block
+			if !protoEntry(value, &position, func(value []byte) bool { return decodeProtoBlock(value, &block) }) {
+				return false
+			}
+
+			delta[position] = block
+		case number == 1 || number == 2:
+			return false
+		}
+
+		return true
+	})
+
+	if !valid {
+		return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "wrong format: not a protobuf Delta")
+	} else if version != protoVersion {
+		return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("unsupported protobuf version %d", version))
+	}
+
+	return delta, nil
+}
+
+// DecodeProtoSignature() will decode a protobuf Signature message, along with the metadata it records (see filediff.proto).
+// Note: unknown fields will be skipped, so files written by newer schemas can still be read.
+// Function returns `signature, metadata, nil` when successful.
+// Function returns `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode the message (EG wrong format, or truncated file).
+func DecodeProtoSignature(reader io.Reader) (models.Signature, models.SignatureMetadata, error) {
+	message, err := io.ReadAll(reader)
+	if err != nil {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, "unable to read protobuf Signature")
+	}
+
+	signature := models.Signature{}
+	metadata := models.SignatureMetadata{}
+	version := uint64(0)
+	valid := protoFields(message, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.VarintType:
+			version = varint
+		case number == 2 && kind == protowire.BytesType:
+			var weakHash int64
+			var item models.StrongSignature
+			if !protoEntry(value, &weakHash, func(value []byte) bool { return decodeProtoStrongSignature(value, &item) }) {
+				return false
+			}
+
+			signature[weakHash] = item
+		case number == 3 && kind == protowire.BytesType:
+			return decodeProtoMetadata(value, &metadata)
+		case number >= 1 && number <= 3:
+			return false
+		}
+
+		return true
+	})
+
+	if !valid {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, "wrong format: not a protobuf Signature")
+	} else if version != protoVersion {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, fmt.Sprintf("unsupported protobuf version %d", version))
+	}
+
+	return signature, metadata, nil
+}
+
+// EncodeProtoDelta() will encode a Delta as a protobuf Delta message (see filediff.proto), with blocks sorted by position.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to write to the provided writer.
+func EncodeProtoDelta(delta models.Delta, writer io.Writer) error {
+	positions := make([]int64, 0, len(delta))
+	for position := range delta {
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	message := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), protoVersion)
+	for _, position := range positions {
+		message = protowire.AppendTag(message, 2, protowire.BytesType)
+		message = protowire.AppendBytes(message, appendProtoEntry(position, encodeProtoBlock(delta[position])))
+	}
+
+	_, err := writer.Write(message)
+	return err
+}
+
+// EncodeProtoSignature() will encode a Signature as a protobuf Signature message (see filediff.proto), with items sorted by Weak hash, followed by the provided metadata.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to write to the provided writer.
+func EncodeProtoSignature(signature models.Signature, metadata models.SignatureMetadata, writer io.Writer) error {
+	weakHashes := make([]int64, 0, len(signature))
+	for weakHash := range signature {
+		weakHashes = append(weakHashes, weakHash)
+	}
+
+	sort.Slice(weakHashes, func(i, j int) bool { return weakHashes[i] < weakHashes[j] })
+	message := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), protoVersion)
+	for _, weakHash := range weakHashes {
+		message = protowire.AppendTag(message, 2, protowire.BytesType)
+		message = protowire.AppendBytes(message, appendProtoEntry(weakHash, encodeProtoStrongSignature(signature[weakHash])))
+	}
+
+	encoded := appendProtoInt(nil, 1, metadata.ChunkSize)
+	encoded = appendProtoString(encoded, 2, metadata.WeakHash)
+	encoded = appendProtoString(encoded, 3, metadata.StrongHash)
+	message = protowire.AppendTag(message, 3, protowire.BytesType)
+	message = protowire.AppendBytes(message, encoded)
+	_, err := writer.Write(message)
+	return err
+}
+
+// appendProtoEntry() will encode a map entry of an int64 key (EG Weak hash or position) + encoded message value.
+func appendProtoEntry(key int64, value []byte) []byte {
+	entry := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), uint64(key))
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	return protowire.AppendBytes(entry, value)
+}
+
+// appendProtoInt() will encode an int64 field, omitting it when 0 (EG the proto3 default).
+func appendProtoInt(message []byte, number protowire.Number, value int64) []byte {
+	if value == 0 {
+		return message
+	}
+
+	return protowire.AppendVarint(protowire.AppendTag(message, number, protowire.VarintType), uint64(value))
+}
+
+// appendProtoString() will encode a string field, omitting it when empty (EG the proto3 default).
+func appendProtoString(message []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return message
+	}
+
+	return protowire.AppendString(protowire.AppendTag(message, number, protowire.BytesType), value)
+}
+
+// decodeProtoBlock() will decode a protobuf Block message into block.
+// Note: matched blocks will be decoded with an empty Value (EG the same as blocks generated by Delta mode).
+// Function returns `false` when the message is invalid.
+func decodeProtoBlock(message []byte, block *models.Block) bool {
+	block.Value = []byte{}
+	return protoFields(message, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.VarintType:
+			block.Head = int64(varint)
+		case number == 2 && kind == protowire.VarintType:
+			block.Tail = int64(varint)
+		case number == 3 && kind == protowire.VarintType:
+			block.IsModified = varint != 0
+		case number == 4 && kind == protowire.BytesType:
+			block.Value = append([]byte{}, value...)
+		case number >= 1 && number <= 4:
+			return false
+		}
+
+		return true
+	})
+}
+
+// decodeProtoMetadata() will decode a protobuf SignatureMetadata message into metadata.
+// Function returns `false` when the message is invalid.
+func decodeProtoMetadata(message []byte, metadata *models.SignatureMetadata) bool {
+	return protoFields(message, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.VarintType:
+			metadata.ChunkSize = int64(varint)
+		case number == 2 && kind == protowire.BytesType:
+			metadata.WeakHash = string(value)
+		case number == 3 && kind == protowire.BytesType:
+			metadata.StrongHash = string(value)
+		case number >= 1 && number <= 3:
+			return false
+		}
+
+		return true
+	})
+}
+
+// decodeProtoStrongSignature() will decode a protobuf StrongSignature message (including its candidates) into item.
+// Function returns `false` when the message is invalid.
+func decodeProtoStrongSignature(message []byte, item *models.StrongSignature) bool {
+	return protoFields(message, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.BytesType:
+			item.Hash = string(value)
+		case number == 2 && kind == protowire.VarintType:
+			item.Head = int64(varint)
+		case number == 3 && kind == protowire.VarintType:
+			item.Tail = int64(varint)
+		case number == 4 && kind == protowire.BytesType:
+			var candidate models.StrongSignature
+			if !decodeProtoStrongSignature(value, &candidate) {
+				return false
+			}
+
+			item.Candidates = append(item.Candidates, candidate)
+		case number == 5 && kind == protowire.BytesType:
+			item.LegacyHash = string(value)
+		case number >= 1 && number <= 5:
+			return false
+		}
+
+		return true
+	})
+}
+
+// encodeProtoBlock() will encode a Block as a protobuf Block message.
+func encodeProtoBlock(block models.Block) []byte {
+	message := appendProtoInt(nil, 1, block.Head)
+	message = appendProtoInt(message, 2, block.Tail)
+	if block.IsModified {
+		message = protowire.AppendVarint(protowire.AppendTag(message, 3, protowire.VarintType), 1)
+	}
+
+	if len(block.Value) > 0 {
+		message = protowire.AppendBytes(protowire.AppendTag(message, 4, protowire.BytesType), block.Value)
+	}
+
+	return message
+}
+
+// encodeProtoStrongSignature() will encode a StrongSignature (including its candidates) as a protobuf StrongSignature message.
+func encodeProtoStrongSignature(item models.StrongSignature) []byte {
+	message := appendProtoString(nil, 1, item.Hash)
+	message = appendProtoInt(message, 2, item.Head)
+	message = appendProtoInt(message, 3, item.Tail)
+	for _, candidate := range item.Candidates {
+		message = protowire.AppendBytes(protowire.AppendTag(message, 4, protowire.BytesType), encodeProtoStrongSignature(candidate))
+	}
+
+	return appendProtoString(message, 5, item.LegacyHash)
+}
+
+// isProtoFile() will check if a local file is protobuf encoded (EG starts with the version field, see filediff.proto), rather than gob or JSON encoded.
+// Note: the file will be opened separately, so the position of an already open file is unchanged.
+// Function returns `false` when unable to read the file.
+func isProtoFile(fileName string) bool {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return false
+	}
+
+	return header[0] == 0x08 && uint64(header[1]) == protoVersion
+}
+
+// protoEntry() will decode a map entry of an int64 key (EG Weak hash or position), passing the encoded value to decodeValue.
+// Function returns `false` when the entry (or its value) is invalid.
+func protoEntry(entry []byte, key *int64, decodeValue func(value []byte) bool) bool {
+	decoded := false
+	valid := protoFields(entry, func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool {
+		switch {
+		case number == 1 && kind == protowire.VarintType:
+			*key = int64(varint)
+		case number == 2 && kind == protowire.BytesType:
+			decoded = true
+			return decodeValue(value)
+		case number == 1 || number == 2:
+			return false
+		}
+
+		return true
+	})
+
+	// Value will be empty (EG the proto3 default) when omitted by the encoder
+	return valid && (decoded || decodeValue(nil))
+}
+
+// protoFields() will call field for every field of an encoded protobuf message, along with its value (EG varint, or bytes when length delimited).
+// Note: fields of other wire types (EG fixed32) will be skipped with an empty value.
+// Function returns `false` when the message is truncated or invalid, or field returns `false`.
+func protoFields(message []byte, field func(number protowire.Number, kind protowire.Type, varint uint64, value []byte) bool) bool {
+	for len(message) > 0 {
+		number, kind, length := protowire.ConsumeTag(message)
+		if length < 0 {
+			return false
+		}
+
+		message = message[length:]
+		var varint uint64
+		var value []byte
+		switch kind {
+		case protowire.VarintType:
+			varint, length = protowire.ConsumeVarint(message)
+		case protowire.BytesType:
+			value, length = protowire.ConsumeBytes(message)
+		default:
+			length = protowire.ConsumeFieldValue(number, kind, message)
+		}
+
+		if length < 0 || !field(number, kind, varint, value) {
+			return false
+		}
+
+		message = message[length:]
+	}
+
+	return true
+}
@@ -0,0 +1,130 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+var testProtoDelta = models.Delta{
+	0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")},
+	3: {Head: 4, Tail: 19, IsModified: false, Value: []byte{}},
+}
+
+// Version, followed by an entry per block sorted by position (see filediff.proto)
+var testProtoDeltaBytes = []byte{
+	0x08, 0x01,
+	0x12, 0x0d, 0x08, 0x00, 0x12, 0x09, 0x10, 0x02, 0x18, 0x01, 0x22, 0x03, 'a', 'b', 'c',
+	0x12, 0x08, 0x08, 0x03, 0x12, 0x04, 0x08, 0x04, 0x10, 0x13,
+}
+
+var testProtoSignature = models.Signature{
+	-5: {Hash: "ab", Head: 0, Tail: 15},
+	7:  {Hash: "cd", Head: 16, Tail: 31, LegacyHash: "ef", Candidates: []models.StrongSignature{{Hash: "gh", Head: 32, Tail: 47}}},
+}
+
+func TestEncodeProtoDelta(t *testing.T) {
+	t.Run("should encode Delta as a protobuf Delta message, sorted by position", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		// Run
+		err := EncodeProtoDelta(testProtoDelta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoDeltaBytes, buffer.Bytes())
+	})
+}
+
+func TestDecodeProtoDelta(t *testing.T) {
+	t.Run("should return `delta, nil` when successfully decoded protobuf Delta (skipping unknown fields)", func(t *testing.T) {
+		// Setup
+		data := append(append([]byte{}, testProtoDeltaBytes...), 0x1d, 0x01, 0x02, 0x03, 0x04)
+		// Run
+		delta, err := DecodeProtoDelta(bytes.NewReader(data))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when protobuf Delta is truncated or invalid", func(t *testing.T) {
+		// Setup
+		expectedErrors := map[string]error{
+			string(testProtoDeltaBytes[:len(testProtoDeltaBytes)-1]): errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a protobuf Delta)"),
+			string([]byte{0x08, 0x01, 0x10, 0x01}):                   errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not a protobuf Delta)"),
+			string([]byte{0x08, 0x02}):                               errors.New(constants.UnableToDecodeDeltaFromFileError + " (unsupported protobuf version 2)"),
+		}
+
+		for data, expectedError := range expectedErrors {
+			// Run
+			delta, err := DecodeProtoDelta(bytes.NewReader([]byte(data)))
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Delta{}, delta)
+		}
+	})
+}
+
+func TestProtoSignature(t *testing.T) {
+	t.Run("should decode the Signature + metadata which were encoded, including candidates + legacy hashes", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "adler32", StrongHash: "sha256"}
+		// Run
+		err := EncodeProtoSignature(testProtoSignature, metadata, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte{0x08, 0x01, 0x12}, buffer.Bytes()[:3])
+		signature, decodedMetadata, err := DecodeProtoSignature(&buffer)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoSignature, signature)
+		require.Equal(t, metadata, decodedMetadata)
+	})
+
+	t.Run("should return `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when file contains a protobuf Delta", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError + " (wrong format: not a protobuf Signature)")
+		// Run
+		signature, metadata, err := DecodeProtoSignature(bytes.NewReader(testProtoDeltaBytes))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+		require.Equal(t, models.SignatureMetadata{}, metadata)
+	})
+
+	t.Run("should write protobuf models as protobuf messages, and detect them when opening Signature + Delta files", func(t *testing.T) {
+		// Setup
+		metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "rabin-karp"}
+		signaturePath := filepath.Join(t.TempDir(), "signature.pb")
+		deltaPath := filepath.Join(t.TempDir(), "delta.pb")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createFile = createWithMode
+		createNewWriter = createWriter
+		newWriter = bufio.NewWriter
+		readMagic = rdiffMagic
+		// Run
+		signatureErr := WriteStructToPath(ProtoSignature{Signature: testProtoSignature, Metadata: metadata}, signaturePath)
+		deltaErr := WriteStructToPath(ProtoDelta(testProtoDelta), deltaPath)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		size, err := EncodedSize(ProtoDelta(testProtoDelta))
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(len(testProtoDeltaBytes)), size)
+		signature, decodedMetadata, err := OpenSignatureMetadata(signaturePath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoSignature, signature)
+		require.Equal(t, metadata, decodedMetadata)
+		delta, err := OpenDelta(deltaPath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, testProtoDelta, delta)
+	})
+}
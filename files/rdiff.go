@@ -0,0 +1,372 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+const (
+	RdiffRollsum   string = rolling.RollsumName        // Weak hash of rdiff Signatures generated by librsync before 2.2
+	RdiffRabinKarp string = rolling.RdiffRabinKarpName // Weak hash of rdiff Signatures generated by librsync 2.2+
+)
+
+const (
+	rdiffDeltaMagic uint32 = 0x72730236 // Magic number of rdiff Delta files
+	rdiffOpEnd      byte   = 0x00       // End of an rdiff Delta
+	rdiffOpLiteral  byte   = 0x41       // Literal, with length in the following 1, 2, 4 or 8 bytes (0x01 - 0x40 hold the length in the opcode)
+	rdiffOpCopy     byte   = 0x45       // Copy, with offset + length in the following 1, 2, 4 or 8 bytes each (0x45 - 0x54)
+	rdiffOpInvalid  byte   = 0x55       // First reserved opcode
+	rdiffMaxInline  int64  = 64         // Max literal length held in the opcode
+)
+
+// rdiffHashes type.
+// This will contain the names of the Weak + Strong hash an rdiff Signature was generated with.
+type rdiffHashes struct {
+	weak   string
+	strong string
+}
+
+// rdiffSignatureMagics will map the magic number of each rdiff Signature format to the hashes it was generated with.
+var rdiffSignatureMagics = map[uint32]rdiffHashes{
+	0x72730136: {weak: RdiffRollsum, strong: strong.MD4Name},
+	0x72730137: {weak: RdiffRollsum, strong: strong.BLAKE2bName},
+	0x72730146: {weak: RdiffRabinKarp, strong: strong.MD4Name},
+	0x72730147: {weak: RdiffRabinKarp, strong: strong.BLAKE2bName},
+}
+
+// RdiffSignature type.
+// This will contain a Signature, and the metadata it was generated with, to be written in the rdiff Signature format (see WriteStructToFile()).
+// The Signature must be generated with an rdiff Weak + Strong hash, and contain the block at every chunk of the Original file (EG `-sparse` set to the chunk size).
+// Note: the final partial block of the Original file is not hashed by `sync`, so will be sent as a literal by rdiff.
+// EG: RdiffSignature{Signature: signature, Metadata: SignatureMetadata{ChunkSize: 2048, WeakHash: "rdiff-rabin-karp", StrongHash: "blake2b"}}.
+type RdiffSignature struct {
+	Signature models.Signature
+	Metadata  models.SignatureMetadata
+}
+
+// RdiffDelta type.
+// This will contain a Delta to be written in the rdiff Delta format (see WriteStructToFile()).
+type RdiffDelta models.Delta
+
+// addRdiffBlock() will add a block of an rdiff Signature to the Signature, indexed by its Weak hash.
+// When the Weak hash already exists, the existing block will be kept as a candidate of the new block, in the same way as Signatures generated by `sync` (see models.Signature.WriteEntry()).
+func addRdiffBlock(signature models.Signature, weakHash uint32, strongHash []byte, head int64, tail int64) {
+	_ = signature.WriteEntry(int64(weakHash), models.StrongSignature{Hash: hex.EncodeToString(strongHash), Head: head, Tail: tail})
+}
+
+// DecodeRdiffDelta() will decode a Delta from the rdiff Delta format (EG generated by `rdiff delta`).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToDecodeDeltaFromFileError` when unable to decode Delta (EG truncated file or unknown command).
+func DecodeRdiffDelta(reader io.Reader) (models.Delta, error) {
+	buffered := bufio.NewReader(reader)
+	if magic, err := readRdiffInt(buffered, 4); err != nil || uint32(magic) != rdiffDeltaMagic {
+		return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "wrong format: not an rdiff Delta")
+	}
+
+	delta := models.Delta{}
+	position := int64(0)
+	for {
+		opcode, err := buffered.ReadByte()
+		if err != nil {
+			return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, "truncated: rdiff Delta has no end command")
+		} else if opcode == rdiffOpEnd {
+			return delta, nil
+		} else if opcode >= rdiffOpInvalid {
+			return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("unknown rdiff command %#x after %d bytes of output", opcode, position))
+		}
+
+		op, err := readRdiffOp(buffered, opcode, position)
+		if err != nil {
+			return models.Delta{}, decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("truncated: rdiff command %#x after %d bytes of output", opcode, position))
+		}
+
+		if op.Len() > 0 {
+			delta[position] = op.Block()
+			position += op.Len()
+		}
+	}
+}
+
+// DecodeRdiffSignature() will decode a Signature from the rdiff Signature format (EG generated by `rdiff signature`).
+// Note: the final block will be dropped, as rdiff Signatures do not record the size of the (possibly partial) final block.
+// Function returns `signature, metadata, nil` when successful (metadata will contain the block size + hashes of the rdiff format).
+// Function returns `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode Signature (EG truncated file or unknown format).
+func DecodeRdiffSignature(reader io.Reader) (models.Signature, models.SignatureMetadata, error) {
+	buffered := bufio.NewReader(reader)
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(buffered, header); err != nil {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, "truncated: rdiff Signature header")
+	}
+
+	hashes, known := rdiffSignatureMagics[binary.BigEndian.Uint32(header)]
+	blockSize := int64(binary.BigEndian.Uint32(header[4:]))
+	strongSize := int(binary.BigEndian.Uint32(header[8:]))
+	if !known || blockSize < 1 || strongSize < 1 || strongSize > rdiffStrongSize(hashes.strong) {
+		return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, "wrong format: unsupported rdiff Signature header")
+	}
+
+	signature := models.Signature{}
+	metadata := models.SignatureMetadata{ChunkSize: blockSize, WeakHash: hashes.weak, StrongHash: hashes.strong}
+	block := make([]byte, 4+strongSize)
+	previous := []byte{}
+	for index := int64(0); ; index++ {
+		if _, err := io.ReadFull(buffered, block); err == io.EOF {
+			return signature, metadata, nil
+		} else if err != nil {
+			return models.Signature{}, models.SignatureMetadata{}, decodeError(constants.UnableToDecodeSignatureFromFileError, fmt.Sprintf("truncated: rdiff Signature ends within block %d", index))
+		}
+
+		// Add the previous block, now it is known to be a full block (EG not the final block)
+		if index > 0 {
+			head := (index - 1) * blockSize
+			addRdiffBlock(signature, binary.BigEndian.Uint32(previous), previous[4:], head, head+blockSize-1)
+		}
+
+		previous = append(previous[:0], block...)
+	}
+}
+
+// EncodeRdiffDelta() will encode a Delta in the rdiff Delta format, so it can be applied by `rdiff patch`.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to write to the provided writer.
+func EncodeRdiffDelta(delta models.Delta, writer io.Writer) error {
+	output := bufio.NewWriter(writer)
+	writeRdiffInt(output, uint64(rdiffDeltaMagic), 4)
+	// Note: write errors will be returned by Flush(), so visiting Ops will not fail
+	_ = delta.Ops(func(op models.Op) error {
+		length := op.Len()
+		switch {
+		case length < 1:
+			return nil
+		case op.Kind == models.OpCopy:
+			offsetIndex, offsetSize := rdiffIntWidth(uint64(op.Head))
+			lengthIndex, lengthSize := rdiffIntWidth(uint64(length))
+			output.WriteByte(rdiffOpCopy + 4*offsetIndex + lengthIndex)
+			writeRdiffInt(output, uint64(op.Head), offsetSize)
+			writeRdiffInt(output, uint64(length), lengthSize)
+		case length <= rdiffMaxInline:
+			output.WriteByte(byte(length))
+			output.Write(op.Value)
+		default:
+			lengthIndex, lengthSize := rdiffIntWidth(uint64(length))
+			output.WriteByte(rdiffOpLiteral + lengthIndex)
+			writeRdiffInt(output, uint64(length), lengthSize)
+			output.Write(op.Value)
+		}
+
+		return nil
+	})
+
+	output.WriteByte(rdiffOpEnd)
+	return output.Flush()
+}
+
+// EncodeRdiffSignature() will encode a Signature in the rdiff Signature format, so it can be used by `rdiff delta`.
+// rdiff Signatures list the block at every chunk of the Original file in order, so only chunk-aligned blocks (including candidates) will be written.
+// Note: metadata must contain the rdiff Weak + Strong hash the Signature was generated with (EG "rdiff-rabin-karp" + "blake2b"), and a chunk size of 0 will use the default chunk size.
+// Function returns `nil` when successful.
+// Function returns `RdiffUnsupportedHashError` when the Signature was not generated with an rdiff Weak + Strong hash.
+// Function returns `RdiffMissingBlockError` when the Signature does not contain the block at every chunk of the Original file (EG pruned Signature).
+// Function returns `error` when unable to write to the provided writer.
+func EncodeRdiffSignature(signature models.Signature, metadata models.SignatureMetadata, writer io.Writer) error {
+	magic, strongSize, err := rdiffSignatureFormat(signature, metadata)
+	if err != nil {
+		return err
+	}
+
+	blockSize := metadata.ChunkSize
+	if blockSize == 0 {
+		blockSize = rolling.DefaultChunkSize
+	}
+
+	blocks, err := rdiffBlocks(signature, blockSize, strongSize)
+	if err != nil {
+		return err
+	}
+
+	output := bufio.NewWriter(writer)
+	writeRdiffInt(output, uint64(magic), 4)
+	writeRdiffInt(output, uint64(blockSize), 4)
+	writeRdiffInt(output, uint64(strongSize), 4)
+	for _, block := range blocks {
+		output.Write(block)
+	}
+
+	return output.Flush()
+}
+
+// rdiffMagic() will return the magic number at the start of a local file (EG to detect rdiff Signature + Delta files).
+// Note: the file will be opened separately, so the position of an already open file is unchanged.
+// Function returns `0` when unable to read the magic number (EG file smaller than 4 bytes).
+func rdiffMagic(fileName string) uint32 {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return 0
+	}
+
+	defer file.Close()
+	header := make([]byte, 4)
+	if _, err = io.ReadFull(file, header); err != nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(header)
+}
+
+// rdiffBlocks() will return the encoded Weak + Strong hash of the block at every chunk of the Original file, in order.
+// Function returns `blocks, nil` when successful.
+// Function returns `nil, RdiffUnsupportedHashError` when a Strong hash is not a hex encoded hash of strongSize bytes (EG generated by a different algorithm).
+// Function returns `nil, RdiffMissingBlockError` when a chunk-aligned block is missing, or a block other than the final block is smaller than a chunk.
+func rdiffBlocks(signature models.Signature, blockSize int64, strongSize int) ([][]byte, error) {
+	aligned := map[int64]models.StrongSignature{}
+	weakHashes := map[int64]int64{}
+	for weakHash, item := range signature {
+		for _, block := range append([]models.StrongSignature{item}, item.Candidates...) {
+			if block.Head%blockSize == 0 {
+				aligned[block.Head/blockSize] = block
+				weakHashes[block.Head/blockSize] = weakHash
+			}
+		}
+	}
+
+	blocks := make([][]byte, len(aligned))
+	for index := range blocks {
+		block, exists := aligned[int64(index)]
+		if !exists || (index < len(blocks)-1 && block.Tail-block.Head+1 < blockSize) {
+			return nil, errors.New(constants.RdiffMissingBlockError)
+		}
+
+		strongHash, err := hex.DecodeString(block.Hash)
+		if err != nil || len(strongHash) != strongSize {
+			return nil, errors.New(constants.RdiffUnsupportedHashError)
+		}
+
+		blocks[index] = make([]byte, 4, 4+strongSize)
+		binary.BigEndian.PutUint32(blocks[index], uint32(weakHashes[int64(index)]))
+		blocks[index] = append(blocks[index], strongHash...)
+	}
+
+	return blocks, nil
+}
+
+// rdiffIntSize() will return the size (bytes) of an rdiff integer parameter from its index in an opcode (EG 0 = 1 byte, 3 = 8 bytes).
+func rdiffIntSize(index byte) int {
+	return 1 << index
+}
+
+// rdiffIntWidth() will return the index + size (bytes) of the smallest rdiff integer parameter which can hold value.
+func rdiffIntWidth(value uint64) (byte, int) {
+	switch {
+	case value <= 0xff:
+		return 0, 1
+	case value <= 0xffff:
+		return 1, 2
+	case value <= 0xffffffff:
+		return 2, 4
+	}
+
+	return 3, 8
+}
+
+// rdiffSignatureFormat() will return the magic number + Strong hash size (bytes) of the rdiff Signature format matching the provided metadata.
+// Note: the size of the Strong hashes within the Signature will be used, so truncated Strong hashes (EG read from an rdiff Signature) are written unchanged.
+// Function returns `magic, strongSize, nil` when successful.
+// Function returns `0, 0, RdiffUnsupportedHashError` when metadata does not contain an rdiff Weak + Strong hash.
+func rdiffSignatureFormat(signature models.Signature, metadata models.SignatureMetadata) (uint32, int, error) {
+	for magic, hashes := range rdiffSignatureMagics {
+		if hashes.weak != metadata.WeakHash || hashes.strong != metadata.StrongHash {
+			continue
+		}
+
+		maxSize := rdiffStrongSize(hashes.strong)
+		strongSize := maxSize
+		for _, item := range signature {
+			strongSize = len(item.Hash) / 2
+			break
+		}
+
+		if strongSize < 1 || strongSize > maxSize {
+			return 0, 0, errors.New(constants.RdiffUnsupportedHashError)
+		}
+
+		return magic, strongSize, nil
+	}
+
+	return 0, 0, errors.New(constants.RdiffUnsupportedHashError)
+}
+
+// rdiffStrongSize() will return the size (bytes) of the registered Strong hash with the provided name, or 0 when not registered.
+func rdiffStrongSize(name string) int {
+	hash, err := strong.Lookup(name)
+	if err != nil {
+		return 0
+	}
+
+	return hash.Size()
+}
+
+// readRdiffInt() will read a big-endian integer parameter of the provided size (bytes).
+// Function returns `value, nil` when successful.
+// Function returns `0, error` when unable to read the parameter (EG truncated file).
+func readRdiffInt(reader io.Reader, size int) (int64, error) {
+	buffer := make([]byte, 8)
+	if _, err := io.ReadFull(reader, buffer[8-size:]); err != nil {
+		return 0, err
+	}
+
+	return int64(binary.BigEndian.Uint64(buffer)), nil
+}
+
+// readRdiffOp() will read the parameters (+ literal bytes) of an rdiff command, converting it into an Op at the provided position of the Updated file.
+// Function returns `op, nil` when successful.
+// Function returns `emptyOp, error` when unable to read the command (EG truncated file, or negative length).
+func readRdiffOp(reader io.Reader, opcode byte, position int64) (models.Op, error) {
+	if opcode >= rdiffOpCopy {
+		offset, err := readRdiffInt(reader, rdiffIntSize((opcode-rdiffOpCopy)/4))
+		if err != nil {
+			return models.Op{}, err
+		}
+
+		length, err := readRdiffInt(reader, rdiffIntSize((opcode-rdiffOpCopy)%4))
+		if err != nil || offset < 0 || length < 0 {
+			return models.Op{}, errors.New(constants.UnableToDecodeDeltaFromFileError)
+		}
+
+		return models.Op{Kind: models.OpCopy, Position: position, Head: offset, Tail: offset + length - 1}, nil
+	}
+
+	length := int64(opcode)
+	if opcode >= rdiffOpLiteral {
+		var err error
+		if length, err = readRdiffInt(reader, rdiffIntSize(opcode-rdiffOpLiteral)); err != nil || length < 0 {
+			return models.Op{}, errors.New(constants.UnableToDecodeDeltaFromFileError)
+		}
+	}
+
+	// Read literal bytes through a limited reader, so a corrupted length can not allocate more than the file contains
+	var value bytes.Buffer
+	if copied, err := io.Copy(&value, io.LimitReader(reader, length)); err != nil || copied != length {
+		return models.Op{}, errors.New(constants.UnableToDecodeDeltaFromFileError)
+	}
+
+	return models.Op{Kind: models.OpLiteral, Position: position, Value: value.Bytes()}, nil
+}
+
+// writeRdiffInt() will write a big-endian integer parameter of the provided size (bytes).
+// Note: write errors will be returned by the writer's Flush().
+func writeRdiffInt(writer *bufio.Writer, value uint64, size int) {
+	buffer := make([]byte, 8)
+	binary.BigEndian.PutUint64(buffer, value)
+	writer.Write(buffer[8-size:])
+}
@@ -0,0 +1,316 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testRdiffDelta = models.Delta{
+		0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")},
+		3: {Head: 300, Tail: 309, IsModified: false, Value: []byte{}},
+	}
+	testRdiffDeltaBytes = []byte{0x72, 0x73, 0x02, 0x36, 0x03, 'a', 'b', 'c', 0x49, 0x01, 0x2c, 0x0a, 0x00}
+	testRdiffMetadata   = models.SignatureMetadata{ChunkSize: 4, WeakHash: RdiffRollsum, StrongHash: strong.MD4Name}
+)
+
+// rdiffSignatureOf() will return a Signature of 3 blocks (the final block partial) generated with rollsum + MD4, and its rdiff encoding.
+func rdiffSignatureOf() (models.Signature, []byte) {
+	signature := models.Signature{
+		0x01020304: {Hash: strings.Repeat("11", 16), Head: 0, Tail: 3},
+		0x05060708: {Hash: strings.Repeat("22", 16), Head: 4, Tail: 7},
+		0x090a0b0c: {Hash: strings.Repeat("33", 16), Head: 8, Tail: 9},
+	}
+
+	encoded := []byte{0x72, 0x73, 0x01, 0x36, 0, 0, 0, 4, 0, 0, 0, 16}
+	encoded = append(append(encoded, 0x01, 0x02, 0x03, 0x04), bytes.Repeat([]byte{0x11}, 16)...)
+	encoded = append(append(encoded, 0x05, 0x06, 0x07, 0x08), bytes.Repeat([]byte{0x22}, 16)...)
+	encoded = append(append(encoded, 0x09, 0x0a, 0x0b, 0x0c), bytes.Repeat([]byte{0x33}, 16)...)
+	return signature, encoded
+}
+
+func TestEncodeRdiffDelta(t *testing.T) {
+	t.Run("should encode Delta as rdiff literal + copy commands", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		// Run
+		err := EncodeRdiffDelta(testRdiffDelta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testRdiffDeltaBytes, buffer.Bytes())
+	})
+
+	t.Run("should encode literals longer than 64 bytes with their length after the command", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		value := bytes.Repeat([]byte{'x'}, 300)
+		delta := models.Delta{0: {Head: 0, Tail: 299, IsModified: true, Value: value}}
+		expectedBytes := append([]byte{0x72, 0x73, 0x02, 0x36, 0x42, 0x01, 0x2c}, append(value, 0x00)...)
+		// Run
+		err := EncodeRdiffDelta(delta, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedBytes, buffer.Bytes())
+	})
+
+	t.Run("should return `error` when unable to write to the provided writer", func(t *testing.T) {
+		// Setup
+		var file *os.File
+		// Run
+		err := EncodeRdiffDelta(testRdiffDelta, file)
+		// Verify
+		require.NotEqual(t, nil, err)
+	})
+}
+
+func TestDecodeRdiffDelta(t *testing.T) {
+	t.Run("should return `delta, nil` when successfully decoded rdiff Delta", func(t *testing.T) {
+		// Run
+		delta, err := DecodeRdiffDelta(bytes.NewReader(testRdiffDeltaBytes))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testRdiffDelta, delta)
+	})
+
+	t.Run("should decode the Delta which was encoded, when using every parameter size", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		value := bytes.Repeat([]byte{'y'}, 70000)
+		expectedDelta := models.Delta{
+			0:      {Head: 0, Tail: 69999, IsModified: true, Value: value},
+			70000:  {Head: 1 << 33, Tail: 1<<33 + 65535, IsModified: false, Value: []byte{}},
+			135536: {Head: 5, Tail: 4 + 1<<20, IsModified: false, Value: []byte{}},
+		}
+		require.Equal(t, nil, EncodeRdiffDelta(expectedDelta, &buffer))
+		// Run
+		delta, err := DecodeRdiffDelta(&buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when file is not an rdiff Delta", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format: not an rdiff Delta)")
+		// Run
+		delta, err := DecodeRdiffDelta(bytes.NewReader([]byte("invalid")))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when rdiff Delta is truncated", func(t *testing.T) {
+		for _, size := range []int{len(testRdiffDeltaBytes) - 1, len(testRdiffDeltaBytes) - 2, 6} {
+			// Run
+			delta, err := DecodeRdiffDelta(bytes.NewReader(testRdiffDeltaBytes[:size]))
+			// Verify
+			require.Equal(t, true, strings.HasPrefix(err.Error(), constants.UnableToDecodeDeltaFromFileError+" (truncated"))
+			require.Equal(t, models.Delta{}, delta)
+		}
+	})
+
+	t.Run("should return `emptyDelta, UnableToDecodeDeltaFromFileError` when rdiff Delta contains an unknown command", func(t *testing.T) {
+		// Setup
+		data := []byte{0x72, 0x73, 0x02, 0x36, 0x01, 'a', 0x55, 0x00}
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (unknown rdiff command 0x55 after 1 bytes of output)")
+		// Run
+		delta, err := DecodeRdiffDelta(bytes.NewReader(data))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
+
+func TestEncodeRdiffSignature(t *testing.T) {
+	t.Run("should encode the block at every chunk of the Original file, in order", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		signature, expectedBytes := rdiffSignatureOf()
+		// Run
+		err := EncodeRdiffSignature(signature, testRdiffMetadata, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedBytes, buffer.Bytes())
+	})
+
+	t.Run("should encode blocks kept as candidates of a colliding Weak hash", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		signature, expectedBytes := rdiffSignatureOf()
+		// Store the first block as a candidate of the second block (EG same Weak hash)
+		first := signature[0x01020304]
+		delete(signature, 0x01020304)
+		second := signature[0x05060708]
+		second.Candidates = []models.StrongSignature{first}
+		signature[0x05060708] = second
+		copy(expectedBytes[12:], []byte{0x05, 0x06, 0x07, 0x08})
+		// Run
+		err := EncodeRdiffSignature(signature, testRdiffMetadata, &buffer)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedBytes, buffer.Bytes())
+	})
+
+	t.Run("should return `RdiffUnsupportedHashError` when Signature was not generated with an rdiff Weak + Strong hash", func(t *testing.T) {
+		// Setup
+		signature, _ := rdiffSignatureOf()
+		expectedError := errors.New(constants.RdiffUnsupportedHashError)
+		metadata := []models.SignatureMetadata{
+			{ChunkSize: 4, WeakHash: "rabin-karp", StrongHash: strong.MD4Name},
+			{ChunkSize: 4, WeakHash: RdiffRollsum, StrongHash: strong.SHA256Name},
+			{ChunkSize: 4, WeakHash: RdiffRollsum},
+		}
+
+		for _, item := range metadata {
+			// Run
+			err := EncodeRdiffSignature(signature, item, &bytes.Buffer{})
+			// Verify
+			require.Equal(t, expectedError, err)
+		}
+	})
+
+	t.Run("should return `RdiffUnsupportedHashError` when a Strong hash has a different size", func(t *testing.T) {
+		// Setup
+		signature, _ := rdiffSignatureOf()
+		signature[0x05060708] = models.StrongSignature{Hash: "some-strong-hash", Head: 4, Tail: 7}
+		expectedError := errors.New(constants.RdiffUnsupportedHashError)
+		// Run
+		err := EncodeRdiffSignature(signature, testRdiffMetadata, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `RdiffMissingBlockError` when Signature does not contain the block at every chunk", func(t *testing.T) {
+		// Setup
+		signature, _ := rdiffSignatureOf()
+		delete(signature, 0x05060708)
+		expectedError := errors.New(constants.RdiffMissingBlockError)
+		// Run
+		err := EncodeRdiffSignature(signature, testRdiffMetadata, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestDecodeRdiffSignature(t *testing.T) {
+	t.Run("should return `signature, metadata, nil` when successfully decoded rdiff Signature (dropping the final block)", func(t *testing.T) {
+		// Setup
+		expectedSignature, data := rdiffSignatureOf()
+		delete(expectedSignature, 0x090a0b0c)
+		// Run
+		signature, metadata, err := DecodeRdiffSignature(bytes.NewReader(data))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSignature, signature)
+		require.Equal(t, testRdiffMetadata, metadata)
+	})
+
+	t.Run("should decode truncated Strong hashes + keep colliding blocks as candidates", func(t *testing.T) {
+		// Setup
+		data := []byte{0x72, 0x73, 0x01, 0x47, 0, 0, 0, 8, 0, 0, 0, 2}
+		data = append(data, 0, 0, 0, 1, 0xaa, 0xaa, 0, 0, 0, 1, 0xbb, 0xbb, 0, 0, 0, 2, 0xcc, 0xcc)
+		expectedSignature := models.Signature{1: {Hash: "bbbb", Head: 8, Tail: 15, Candidates: []models.StrongSignature{{Hash: "aaaa", Head: 0, Tail: 7}}}}
+		expectedMetadata := models.SignatureMetadata{ChunkSize: 8, WeakHash: RdiffRabinKarp, StrongHash: strong.BLAKE2bName}
+		// Run
+		signature, metadata, err := DecodeRdiffSignature(bytes.NewReader(data))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSignature, signature)
+		require.Equal(t, expectedMetadata, metadata)
+	})
+
+	t.Run("should return `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when rdiff Signature header is unsupported", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError + " (wrong format: unsupported rdiff Signature header)")
+		headers := [][]byte{
+			{0x72, 0x73, 0x02, 0x36, 0, 0, 0, 4, 0, 0, 0, 16},
+			{0x72, 0x73, 0x01, 0x36, 0, 0, 0, 0, 0, 0, 0, 16},
+			{0x72, 0x73, 0x01, 0x36, 0, 0, 0, 4, 0, 0, 0, 17},
+		}
+
+		for _, header := range headers {
+			// Run
+			signature, metadata, err := DecodeRdiffSignature(bytes.NewReader(header))
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Signature{}, signature)
+			require.Equal(t, models.SignatureMetadata{}, metadata)
+		}
+	})
+
+	t.Run("should return `emptySignature, emptyMetadata, UnableToDecodeSignatureFromFileError` when rdiff Signature is truncated", func(t *testing.T) {
+		// Setup
+		_, data := rdiffSignatureOf()
+		expectedErrors := map[int]error{
+			8:             errors.New(constants.UnableToDecodeSignatureFromFileError + " (truncated: rdiff Signature header)"),
+			len(data) - 1: errors.New(constants.UnableToDecodeSignatureFromFileError + " (truncated: rdiff Signature ends within block 2)"),
+		}
+
+		for size, expectedError := range expectedErrors {
+			// Run
+			signature, metadata, err := DecodeRdiffSignature(bytes.NewReader(data[:size]))
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Signature{}, signature)
+			require.Equal(t, models.SignatureMetadata{}, metadata)
+		}
+	})
+}
+
+func TestRdiffFiles(t *testing.T) {
+	t.Run("should write rdiff models in the rdiff format, and detect them when opening Signature + Delta files", func(t *testing.T) {
+		// Setup
+		signature, expectedSignatureBytes := rdiffSignatureOf()
+		signaturePath := filepath.Join(t.TempDir(), "signature")
+		deltaPath := filepath.Join(t.TempDir(), "delta")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createFile = createWithMode
+		createNewWriter = createWriter
+		newWriter = bufio.NewWriter
+		readMagic = rdiffMagic
+		// Run
+		signatureErr := WriteStructToPath(RdiffSignature{Signature: signature, Metadata: testRdiffMetadata}, signaturePath)
+		deltaErr := WriteStructToPath(RdiffDelta(testRdiffDelta), deltaPath)
+		// Verify
+		require.Equal(t, nil, signatureErr)
+		require.Equal(t, nil, deltaErr)
+		signatureBytes, err := os.ReadFile(signaturePath)
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSignatureBytes, signatureBytes)
+		size, err := EncodedSize(RdiffDelta(testRdiffDelta))
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(len(testRdiffDeltaBytes)), size)
+		delete(signature, 0x090a0b0c)
+		decodedSignature, metadata, err := OpenSignatureMetadata(signaturePath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, signature, decodedSignature)
+		require.Equal(t, testRdiffMetadata, metadata)
+		delta, err := OpenDelta(deltaPath, false)
+		require.Equal(t, nil, err)
+		require.Equal(t, testRdiffDelta, delta)
+	})
+
+	t.Run("should return `RdiffMissingBlockError` without creating a file when RdiffSignature can not be written", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "signature")
+		expectedError := errors.New(constants.RdiffMissingBlockError)
+		// Run
+		err := WriteStructToPath(RdiffSignature{Signature: models.Signature{1: {Hash: strings.Repeat("11", 16), Head: 4, Tail: 7}}, Metadata: testRdiffMetadata}, path)
+		// Verify
+		require.Equal(t, expectedError, err)
+		_, statErr := os.Stat(path)
+		require.Equal(t, true, os.IsNotExist(statErr))
+	})
+}
@@ -0,0 +1,117 @@
+package files
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+const (
+	readAheadBufferSize int = 64 * 1024 // 64KB per buffer read from disk
+	readAheadDepth      int = 4         // Number of buffers read ahead of the consumer
+)
+
+// readAheadBuffer type.
+// This will contain a block of data read from file, or the error returned when reading from file.
+type readAheadBuffer struct {
+	data []byte
+	err  error
+}
+
+// readAheadReader type.
+// This will read a file in a producer goroutine, filling a channel of buffers ahead of the consumer.
+// This allows slow storage reads and CPU hashing to overlap instead of alternating.
+type readAheadReader struct {
+	buffers chan readAheadBuffer
+	done    chan struct{}
+	once    sync.Once
+	current []byte
+	err     error
+}
+
+// newReadAheadReader() will init a readAheadReader and start the producer goroutine for the provided file.
+// Producer will close the file once EOF (or an error) has been reached, or once the reader is closed (see Close()).
+// Note: the reader will be closed when garbage collected, so the producer + file are released when a consumer stops reading before EOF without closing it (EG callers only holding the `*bufio.Reader` returned by OpenFile()).
+// Returned reader will satisfy the `io.ReadCloser` interface.
+func newReadAheadReader(file io.ReadCloser, bufferSize int, depth int) *readAheadReader {
+	reader := &readAheadReader{buffers: make(chan readAheadBuffer, depth), done: make(chan struct{})}
+	// Producer must not reference reader, otherwise reader would never be garbage collected
+	go produce(file, bufferSize, reader.buffers, reader.done)
+	runtime.SetFinalizer(reader, (*readAheadReader).Close)
+	return reader
+}
+
+// createReadAhead() will init and return a readAheadReader for the provided file with default buffer sizes.
+func createReadAhead(file io.ReadCloser) io.Reader {
+	return newReadAheadReader(file, readAheadBufferSize, readAheadDepth)
+}
+
+// produce() will read the provided file in blocks of bufferSize and push each block onto the buffers channel.
+// Function will close the file + push the read error (including io.EOF) as the final item before closing the channel.
+// Function will close the file + return early once done is closed (EG consumer stopped reading before EOF).
+func produce(file io.ReadCloser, bufferSize int, buffers chan<- readAheadBuffer, done <-chan struct{}) {
+	defer close(buffers)
+	for {
+		data := make([]byte, bufferSize)
+		n, err := file.Read(data)
+		if n > 0 {
+			select {
+			case buffers <- readAheadBuffer{data: data[:n]}:
+			case <-done:
+				file.Close()
+				return
+			}
+		}
+
+		if err != nil {
+			// Close file before the final push, so file is closed once the consumer receives the error
+			file.Close()
+			select {
+			case buffers <- readAheadBuffer{err: err}:
+			case <-done:
+			}
+
+			return
+		}
+	}
+}
+
+// Close() will stop the producer goroutine, which closes the file.
+// Note: reads after closing will return buffers already read ahead, then `0, io.EOF`.
+// Function returns `nil` (closing more than once has no effect).
+func (r *readAheadReader) Close() error {
+	r.once.Do(func() {
+		close(r.done)
+	})
+
+	return nil
+}
+
+// Read() will copy data from the read-ahead buffers into p.
+// Function will block until the producer has filled the next buffer.
+// Function will return `0, error` once the producer has reached EOF (io.EOF) or failed to read from file.
+func (r *readAheadReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for len(r.current) == 0 {
+		// Return recorded error once producer has finished
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		buffer, ok := <-r.buffers
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+
+		r.current = buffer.data
+		r.err = buffer.err
+	}
+
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
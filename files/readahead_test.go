@@ -0,0 +1,158 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for io.ReadCloser interface
+type readCloserMock struct {
+	// Include io.Reader props to fulfill interface
+	io.Reader
+	// Set test props
+	closed bool
+}
+
+// Overwrite readCloserMock.Close() to record file has been closed
+func (r *readCloserMock) Close() error {
+	r.closed = true
+	return nil
+}
+
+// Mock for io.ReadCloser interface which always fails to read
+type failingReadCloserMock struct{}
+
+// Overwrite failingReadCloserMock.Read() to return test error
+func (r failingReadCloserMock) Read(p []byte) (int, error) {
+	return 0, errors.New(errorMessage)
+}
+
+// Implement failingReadCloserMock.Close()
+func (r failingReadCloserMock) Close() error { return nil }
+
+// Mock for io.ReadCloser interface, which signals on a channel once closed
+type signalReadCloserMock struct {
+	// Include io.Reader props to fulfill interface
+	io.Reader
+	// Set test props
+	closed chan struct{}
+}
+
+// Overwrite signalReadCloserMock.Close() to signal file has been closed
+func (r *signalReadCloserMock) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestCreateReadAhead(t *testing.T) {
+	t.Run("should return reader which produces the contents of the provided file", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader([]byte(testOutput))}
+		// Run
+		reader := createReadAhead(file)
+		result, err := io.ReadAll(reader)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte(testOutput), result)
+	})
+}
+
+func TestReadAheadReader(t *testing.T) {
+	t.Run("should return file contents across multiple read-ahead buffers", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader([]byte(testOutput))}
+		// Run
+		reader := newReadAheadReader(file, 3, 2)
+		result, err := io.ReadAll(reader)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte(testOutput), result)
+		require.Equal(t, true, file.closed)
+	})
+
+	t.Run("should return `0, io.EOF` on every read after reaching EOF", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader([]byte{})}
+		buffer := make([]byte, 4)
+		// Run
+		reader := newReadAheadReader(file, 3, 2)
+		n, err := reader.Read(buffer)
+		secondN, secondErr := reader.Read(buffer)
+		// Verify
+		require.Equal(t, 0, n)
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, secondN)
+		require.Equal(t, io.EOF, secondErr)
+	})
+
+	t.Run("should return `0, nil` when provided an empty slice", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader([]byte(testOutput))}
+		// Run
+		reader := newReadAheadReader(file, 3, 2)
+		n, err := reader.Read([]byte{})
+		// Verify
+		require.Equal(t, 0, n)
+		require.Equal(t, nil, err)
+	})
+
+	t.Run("should return `0, error` when unable to read from file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		buffer := make([]byte, 4)
+		// Run
+		reader := newReadAheadReader(failingReadCloserMock{}, 3, 2)
+		n, err := reader.Read(buffer)
+		// Verify
+		require.Equal(t, 0, n)
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should stop producer + close file when closed before reaching EOF", func(t *testing.T) {
+		// Setup
+		file := &readCloserMock{Reader: bytes.NewReader(make([]byte, 1024))}
+		buffer := make([]byte, 4)
+		reader := newReadAheadReader(file, 3, 2)
+		// Run
+		_, err := reader.Read(buffer)
+		closeErr := reader.Close()
+		// Wait for producer to exit, which closes the buffers channel after closing the file
+		for range reader.buffers {
+		}
+
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, closeErr)
+		require.Equal(t, nil, reader.Close())
+		require.Equal(t, true, file.closed)
+	})
+
+	t.Run("should stop producer + close file when reader is garbage collected before reaching EOF", func(t *testing.T) {
+		// Setup
+		file := &signalReadCloserMock{Reader: bytes.NewReader(make([]byte, 1024)), closed: make(chan struct{})}
+		// Run
+		func() {
+			_, err := newReadAheadReader(file, 3, 2).Read(make([]byte, 4))
+			require.Equal(t, nil, err)
+		}()
+
+		// Verify
+		deadline := time.After(5 * time.Second)
+		for closed := false; !closed; {
+			runtime.GC()
+			select {
+			case <-file.closed:
+				closed = true
+			case <-deadline:
+				t.Fatal("file was not closed after reader was garbage collected")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package files
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// DeltaScanner type.
+// This will decode the operations of a Delta file one at a time, in output order (see Next()), so very large Delta files can be patched without holding every block in memory.
+// Blocks of streamed Delta files (see DeltaStreamWriter) will be decoded from the file as they are scanned, with the checksum trailer verified once the last block has been scanned.
+// Note: other Delta files (EG gob, JSON, protobuf, CBOR or rdiff) will be decoded in full when opened (see OpenDeltaMetadata()), then scanned from memory.
+// DeltaScanner will satisfy the `sync.OpSource` interface.
+type DeltaScanner struct {
+	file     io.Closer
+	payload  io.Reader
+	reader   io.Closer
+	decoder  Decoder
+	metadata models.DeltaMetadata
+	checksum hash.Hash32
+	expected uint32
+	ops      []models.Op
+	op       models.Op
+	position int64
+	done     bool
+	err      error
+}
+
+// IsDeltaStream() will check if a local file is a streamed Delta file (EG written by DeltaStreamWriter), based on its header.
+// Function returns `false` when unable to read the header of the file.
+func IsDeltaStream(fileName string) bool {
+	file, err := open(fileName)
+	if err != nil {
+		return false
+	}
+
+	defer file.Close()
+	prefix := make([]byte, headerSize)
+	if _, err = io.ReadFull(file, prefix); err != nil {
+		return false
+	}
+
+	_, _, kind, found := splitHeader(prefix)
+	return found && kind == kindDeltaStream
+}
+
+// OpenDeltaScanner() will attempt to open a local Delta file, returning a DeltaScanner to decode its operations one at a time.
+// Note: caller is responsible for closing the returned scanner.
+// Function will return `scanner, nil` when successful.
+// Function will return `nil, UnableToOpenDeltaFileError` when unable to open or read the Delta file.
+// Function will return `nil, UnableToDecodeDeltaFromFileError` when a streamed Delta file can not be decoded by this version, or has no checksum trailer (EG cut short while streaming).
+// Function will return `nil, error` in the same cases as OpenDeltaMetadata() for other Delta files.
+func OpenDeltaScanner(fileName string, verbose bool) (*DeltaScanner, error) {
+	if !IsDeltaStream(fileName) {
+		delta, metadata, err := OpenDeltaMetadata(fileName, verbose)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := &DeltaScanner{metadata: metadata, ops: make([]models.Op, 0, len(delta))}
+		delta.Ops(func(op models.Op) error {
+			scanner.ops = append(scanner.ops, op)
+			return nil
+		})
+
+		return scanner, nil
+	}
+
+	file, err := open(fileName)
+	if err != nil {
+		return nil, errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	// Read header + trailer, so the payload can be decoded from between them
+	size := info.Size()
+	prefix := make([]byte, headerSize)
+	suffix := make([]byte, trailerSize)
+	if _, err = file.ReadAt(prefix, 0); err != nil {
+		file.Close()
+		return nil, errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	found := false
+	expected := uint32(0)
+	if size >= int64(headerSize+trailerSize) {
+		if _, err = file.ReadAt(suffix, size-int64(trailerSize)); err != nil {
+			file.Close()
+			return nil, errors.New(constants.UnableToOpenDeltaFileError)
+		}
+
+		_, expected, found = splitTrailer(suffix)
+	}
+
+	method := headerCompression(prefix)
+	_, version, kind, _ := splitHeader(prefix)
+	detail := checkHeader(version, kind, found, &models.Delta{})
+	if detail == "" {
+		detail = checkCompression(method)
+	}
+
+	if detail != "" {
+		file.Close()
+		return nil, decodeError(constants.UnableToDecodeDeltaFromFileError, detail)
+	}
+
+	// Checksum covers the payload as stored (EG compressed), so it will be read before decompressing
+	checksum := crc32.NewIEEE()
+	checksum.Write(prefix)
+	payload := io.TeeReader(bufio.NewReader(io.NewSectionReader(file, int64(headerSize), size-int64(headerSize+trailerSize))), checksum)
+	reader, err := newDecompressor(payload, method)
+	if err != nil {
+		file.Close()
+		return nil, decodeError(constants.UnableToDecodeDeltaFromFileError, err.Error())
+	}
+
+	return &DeltaScanner{file: file, payload: payload, reader: reader, decoder: newDecoder(reader), checksum: checksum, expected: expected}, nil
+}
+
+// Close() will close the underlying Delta file (when still open), along with the decompressor of compressed Delta files.
+// Function will return `nil` when successful.
+// Function will return `error` when unable to close the file.
+func (s *DeltaScanner) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	if s.reader != nil {
+		s.reader.Close()
+	}
+
+	file := s.file
+	s.file = nil
+	return file.Close()
+}
+
+// Err() will return the first error found while scanning, or `nil` when every operation has been scanned successfully.
+// Note: this should be checked once Next() returns `false`.
+func (s *DeltaScanner) Err() error {
+	return s.err
+}
+
+// Next() will decode the next operation of the Delta, which can then be retrieved with Op().
+// Function returns `true` when an operation has been decoded.
+// Function returns `false` when every operation has been scanned, or when an error has been found (see Err()).
+// Note: the checksum of a streamed Delta file will be verified once the last block has been scanned, so operations scanned from a corrupted file should be discarded when Err() is set.
+func (s *DeltaScanner) Next() bool {
+	if s.err != nil || s.done {
+		return false
+	}
+
+	if s.decoder == nil {
+		if len(s.ops) == 0 {
+			s.done = true
+			return false
+		}
+
+		s.op, s.ops = s.ops[0], s.ops[1:]
+		return true
+	}
+
+	block := streamBlock{}
+	if err := s.decoder.Decode(&block); err == io.EOF || (err == nil && block.End) {
+		s.done = true
+		s.finish(block.End)
+		return false
+	} else if err != nil {
+		s.err = decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("corrupted: %s", err))
+		return false
+	}
+
+	s.op = block.Block.Op(block.Position)
+	if detail := validateOp(s.op, s.position); detail != "" {
+		s.err = decodeError(constants.UnableToDecodeDeltaFromFileError, detail)
+		return false
+	}
+
+	s.position += s.op.Len()
+	return true
+}
+
+// Metadata() will return the metadata recorded by the Delta file (see models.DeltaMetadata).
+// Note: metadata of streamed Delta files is recorded after the last block, so will be empty until every operation has been scanned.
+func (s *DeltaScanner) Metadata() models.DeltaMetadata {
+	return s.metadata
+}
+
+// Op() will return the operation decoded by the last call to Next().
+func (s *DeltaScanner) Op() models.Op {
+	return s.op
+}
+
+// Ops() will call the provided function with each remaining operation of the Delta, in output order (see Next()).
+// Iteration will stop at the first error returned by the provided function.
+// Function returns `nil` when all operations have been visited.
+// Function returns `error` returned by the provided function, or found while scanning (see Err()).
+func (s *DeltaScanner) Ops(visit func(op models.Op) error) error {
+	for s.Next() {
+		if err := visit(s.Op()); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// finish() will decode the metadata following the end block of a streamed Delta file (when found), then verify the checksum of the payload.
+func (s *DeltaScanner) finish(ended bool) {
+	if ended {
+		if err := s.decoder.Decode(&s.metadata); err != nil {
+			s.err = decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("corrupted: %s", err))
+			return
+		}
+	}
+
+	// Read any bytes the decoder has not, so the checksum covers the full payload
+	if _, err := io.Copy(io.Discard, s.payload); err != nil {
+		s.err = errors.New(constants.UnableToOpenDeltaFileError)
+		return
+	}
+
+	if actual := s.checksum.Sum32(); actual != s.expected {
+		s.err = decodeError(constants.UnableToDecodeDeltaFromFileError, fmt.Sprintf("corrupted: checksum %08x does not match expected %08x", actual, s.expected))
+	}
+}
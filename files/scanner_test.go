@@ -0,0 +1,193 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// scanOps() will scan every operation of a DeltaScanner, returning the operations scanned + the scanner error.
+func scanOps(scanner *DeltaScanner) ([]models.Op, error) {
+	ops := []models.Op{}
+	for scanner.Next() {
+		ops = append(ops, scanner.Op())
+	}
+
+	return ops, scanner.Err()
+}
+
+// writeDeltaStream() will write a streamed Delta file containing the provided blocks (in order of positions) to the provided path.
+func writeDeltaStream(t *testing.T, path string, delta models.Delta, positions []int64) []byte {
+	var buffer bytes.Buffer
+	writer := NewDeltaStreamWriter(&buffer)
+	for _, position := range positions {
+		require.Equal(t, nil, writer.WriteBlock(position, delta[position]))
+	}
+
+	require.Equal(t, nil, writer.Close())
+	require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+	return buffer.Bytes()
+}
+
+func TestOpenDeltaScanner(t *testing.T) {
+	delta := models.Delta{
+		0:  {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")},
+		3:  {Head: 10, Tail: 25, IsModified: false, Value: []byte{}},
+		19: {Head: 19, Tail: 21, IsModified: true, Value: []byte("xyz")},
+	}
+
+	expected := []models.Op{
+		{Kind: models.OpLiteral, Position: 0, Value: []byte("abc")},
+		{Kind: models.OpCopy, Position: 3, Head: 10, Tail: 25},
+		{Kind: models.OpLiteral, Position: 19, Value: []byte("xyz")},
+	}
+
+	// Mock
+	getFileInfo = os.Stat
+	checkNotExists = os.IsNotExist
+	open = os.Open
+	createNewDecoder = createDecoder
+	readMagic = rdiffMagic
+	readFile = os.ReadFile
+	newEncoder = gob.NewEncoder
+	newDecoder = gob.NewDecoder
+
+	t.Run("should scan each block of a streamed Delta file in order, verifying the checksum trailer", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		writeDeltaStream(t, path, delta, []int64{0, 3, 19})
+		// Run
+		scanner, err := OpenDeltaScanner(path, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, IsDeltaStream(path))
+		ops, scanErr := scanOps(scanner)
+		require.Equal(t, nil, scanErr)
+		require.Equal(t, expected, ops)
+		require.Equal(t, false, scanner.Next())
+		require.Equal(t, nil, scanner.Close())
+	})
+
+	t.Run("should scan each block of other Delta files in output order", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		var buffer bytes.Buffer
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(delta))
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		// Run
+		scanner, err := OpenDeltaScanner(path, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, IsDeltaStream(path))
+		ops, scanErr := scanOps(scanner)
+		require.Equal(t, nil, scanErr)
+		require.Equal(t, expected, ops)
+		require.Equal(t, nil, scanner.Close())
+	})
+
+	t.Run("should return metadata recorded by streamed + other Delta files once scanned", func(t *testing.T) {
+		// Setup
+		metadata := models.DeltaMetadata{UpdatedHash: "some-updated-hash"}
+		streamPath := filepath.Join(t.TempDir(), "stream")
+		var streamBuffer bytes.Buffer
+		writer := NewDeltaStreamWriter(&streamBuffer)
+		require.Equal(t, nil, writer.WriteBlock(0, delta[0]))
+		writer.SetMetadata(metadata)
+		require.Equal(t, nil, writer.Close())
+		require.Equal(t, nil, os.WriteFile(streamPath, streamBuffer.Bytes(), 0644))
+		path := filepath.Join(t.TempDir(), "delta")
+		var buffer bytes.Buffer
+		require.Equal(t, nil, (&checksumEncoder{writer: &buffer}).Encode(sequence{delta, metadata}))
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		for _, fileName := range []string{streamPath, path} {
+			// Run
+			scanner, err := OpenDeltaScanner(fileName, false)
+			// Verify
+			require.Equal(t, nil, err)
+			_, scanErr := scanOps(scanner)
+			require.Equal(t, nil, scanErr)
+			require.Equal(t, metadata, scanner.Metadata())
+			require.Equal(t, nil, scanner.Close())
+		}
+	})
+
+	t.Run("should return `nil, DeltaFileDoesNotExistError` when Delta file not found", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.DeltaFileDoesNotExistError)
+		// Run
+		scanner, err := OpenDeltaScanner(filepath.Join(t.TempDir(), "missing"), false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, (*DeltaScanner)(nil), scanner)
+	})
+
+	t.Run("should return `nil, UnableToDecodeDeltaFromFileError` when streamed Delta file was cut short while streaming", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		data := writeDeltaStream(t, path, delta, []int64{0, 3, 19})
+		require.Equal(t, nil, os.WriteFile(path, data[:len(data)-trailerSize], 0644))
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (truncated: streamed Delta file has no checksum trailer)")
+		// Run
+		scanner, err := OpenDeltaScanner(path, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, (*DeltaScanner)(nil), scanner)
+	})
+
+	t.Run("should set `UnableToDecodeDeltaFromFileError` once scanned when streamed Delta file does not match its checksum", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		data := writeDeltaStream(t, path, delta, []int64{0, 3, 19})
+		corrupted := bytes.Replace(data, []byte("xyz"), []byte("xyy"), 1)
+		require.Equal(t, nil, os.WriteFile(path, corrupted, 0644))
+		scanner, err := OpenDeltaScanner(path, false)
+		require.Equal(t, nil, err)
+		// Run
+		ops, scanErr := scanOps(scanner)
+		// Verify
+		require.Equal(t, 3, len(ops))
+		require.NotEqual(t, nil, scanErr)
+		require.Contains(t, scanErr.Error(), constants.UnableToDecodeDeltaFromFileError+" (corrupted: checksum")
+		require.Equal(t, nil, scanner.Close())
+	})
+
+	t.Run("should set `UnableToDecodeDeltaFromFileError` when streamed blocks contain a gap", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		writeDeltaStream(t, path, delta, []int64{0, 19})
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (wrong format or corrupted: blocks contain a gap or overlap at position 3)")
+		scanner, err := OpenDeltaScanner(path, false)
+		require.Equal(t, nil, err)
+		// Run
+		ops, scanErr := scanOps(scanner)
+		// Verify
+		require.Equal(t, expected[:1], ops)
+		require.Equal(t, expectedError, scanErr)
+		require.Equal(t, nil, scanner.Close())
+	})
+
+	t.Run("should stop scanning + return `error` returned by the visit function", func(t *testing.T) {
+		// Setup
+		path := filepath.Join(t.TempDir(), "delta")
+		writeDeltaStream(t, path, delta, []int64{0, 3, 19})
+		scanner, err := OpenDeltaScanner(path, false)
+		require.Equal(t, nil, err)
+		visited := 0
+		// Run
+		err = scanner.Ops(func(op models.Op) error {
+			visited++
+			return errors.New(errorMessage)
+		})
+		// Verify
+		require.Equal(t, errors.New(errorMessage), err)
+		require.Equal(t, 1, visited)
+		require.Equal(t, nil, scanner.Close())
+	})
+}
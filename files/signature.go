@@ -0,0 +1,116 @@
+package files
+
+import (
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// SignatureReader interface for retrieving a Signature from storage.
+// Embedders can implement this to load Signatures from alternative storage (EG database rows, object storage, custom binary formats).
+type SignatureReader interface {
+	ReadSignature() (models.Signature, error)
+}
+
+// SignatureWriter interface for persisting a Signature to storage.
+// Embedders can implement this to store Signatures in alternative storage (EG database rows, object storage, custom binary formats).
+type SignatureWriter interface {
+	WriteSignature(signature models.Signature) error
+}
+
+// GobSignatureReader type.
+// This will decode a gob encoded Signature (EG the default Signature file format).
+// GobSignatureReader will satisfy the `SignatureReader` interface.
+type GobSignatureReader struct {
+	decoder Decoder
+}
+
+// GobSignatureWriter type.
+// This will gob encode a Signature (EG the default Signature file format).
+// GobSignatureWriter will satisfy the `SignatureWriter` interface.
+type GobSignatureWriter struct {
+	encoder Encoder
+}
+
+// NewGobSignatureReader() will init and return a new GobSignatureReader which decodes from the provided reader.
+// Note: the checksum trailer of Signature files will be verified when present, so Signature files can be read with or without metadata (see ReadMetadata()).
+func NewGobSignatureReader(reader io.Reader) *GobSignatureReader {
+	return &GobSignatureReader{decoder: &checksumDecoder{reader: reader}}
+}
+
+// NewGobSignatureWriter() will init and return a new GobSignatureWriter which encodes to the provided writer.
+func NewGobSignatureWriter(writer io.Writer) *GobSignatureWriter {
+	return &GobSignatureWriter{encoder: newEncoder(writer)}
+}
+
+// ReadSignature() will decode a Signature from the underlying reader.
+// Function will return `Signature, nil` when successful.
+// Function will return `emptySignature, UnableToDecodeSignatureFromFileError` when unable to decode Signature (EG invalid signature file).
+func (r *GobSignatureReader) ReadSignature() (models.Signature, error) {
+	signature := models.Signature{}
+	if err := r.decoder.Decode(&signature); err != nil {
+		return models.Signature{}, errors.New(constants.UnableToDecodeSignatureFromFileError)
+	}
+
+	return signature, nil
+}
+
+// WriteSignature() will encode the provided Signature to the underlying writer.
+// Function will return `nil` when successful.
+// Function will return `UnableToWriteToFileError` when unable to encode Signature.
+func (w *GobSignatureWriter) WriteSignature(signature models.Signature) error {
+	if err := w.encoder.Encode(signature); err != nil {
+		return errors.New(constants.UnableToWriteToFileError)
+	}
+
+	return nil
+}
+
+// ReadMetadata() will decode the metadata recorded after the Signature from the underlying reader (see models.SignatureMetadata).
+// Note: must be called after ReadSignature().
+// Function will return `metadata, nil` when successful.
+// Function will return `emptyMetadata, nil` when no metadata is recorded (EG Signature files generated before metadata was recorded).
+// Function will return `emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode metadata (EG corrupted signature file).
+func (r *GobSignatureReader) ReadMetadata() (models.SignatureMetadata, error) {
+	metadata := models.SignatureMetadata{}
+	if err := r.decoder.Decode(&metadata); err == io.EOF {
+		return models.SignatureMetadata{}, nil
+	} else if err != nil {
+		return models.SignatureMetadata{}, errors.New(constants.UnableToDecodeSignatureFromFileError)
+	}
+
+	return metadata, nil
+}
+
+// WriteMetadata() will encode the provided metadata to the underlying writer (see models.SignatureMetadata).
+// Note: must be called after WriteSignature(), so older readers (which only decode the Signature) can still read the output.
+// Function will return `nil` when successful.
+// Function will return `UnableToWriteToFileError` when unable to encode metadata.
+func (w *GobSignatureWriter) WriteMetadata(metadata models.SignatureMetadata) error {
+	if err := w.encoder.Encode(metadata); err != nil {
+		return errors.New(constants.UnableToWriteToFileError)
+	}
+
+	return nil
+}
+
+// WriteSignatureToFile() will create a Signature file in Outputs folder in the same way as WriteStructToFile(), encoding the provided metadata after the Signature.
+// Note: Signature + metadata will be encoded as a single payload (see `sequence`), so older readers can still decode the Signature.
+// Function will return `nil` when file has been created and written to successfully.
+// Function will return `UnableToCreateFileError` error when unable to create file.
+// Function will return `UnableToWriteToFileError` error when unable to write output to file after creation.
+// Function will return `error` when unable to verify if Output folder exists.
+func WriteSignatureToFile(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
+	return WriteStructToFile(sequence{signature, metadata}, fileName)
+}
+
+// WriteSignatureToPath() will create a Signature file at the provided path in the same way as WriteStructToPath(), encoding the provided metadata after the Signature.
+// Note: unlike WriteSignatureToFile(), file will not be created in the Outputs folder (EG used for temp files).
+// Function will return `nil` when file has been created and written to successfully.
+// Function will return `UnableToCreateFileError` error when unable to create file.
+// Function will return `UnableToWriteToFileError` error when unable to write output to file after creation.
+func WriteSignatureToPath(signature models.Signature, metadata models.SignatureMetadata, path string) error {
+	return WriteStructToPath(sequence{signature, metadata}, path)
+}
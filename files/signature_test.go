@@ -0,0 +1,99 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGobSignatureReadWrite(t *testing.T) {
+	t.Run("should read back Signature written by GobSignatureWriter", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		expectedSignature := models.Signature{123: models.StrongSignature{Hash: "some-strong-hash", Head: 0, Tail: 15}}
+		// Mock
+		newEncoder = gob.NewEncoder
+		newDecoder = gob.NewDecoder
+		// Run
+		err := NewGobSignatureWriter(&buffer).WriteSignature(expectedSignature)
+		require.Equal(t, nil, err)
+		signature, err := NewGobSignatureReader(&buffer).ReadSignature()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSignature, signature)
+	})
+
+	t.Run("should read back metadata written after the Signature", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		expectedMetadata := models.SignatureMetadata{ChunkSize: 4096}
+		writer := NewGobSignatureWriter(&buffer)
+		require.Equal(t, nil, writer.WriteSignature(models.Signature{}))
+		require.Equal(t, nil, writer.WriteMetadata(expectedMetadata))
+		reader := NewGobSignatureReader(&buffer)
+		_, err := reader.ReadSignature()
+		require.Equal(t, nil, err)
+		// Run
+		metadata, err := reader.ReadMetadata()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedMetadata, metadata)
+	})
+
+	t.Run("should return `emptyMetadata, nil` when no metadata is written after the Signature (EG older Signature files)", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		require.Equal(t, nil, NewGobSignatureWriter(&buffer).WriteSignature(models.Signature{}))
+		reader := NewGobSignatureReader(&buffer)
+		_, err := reader.ReadSignature()
+		require.Equal(t, nil, err)
+		// Run
+		metadata, err := reader.ReadMetadata()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.SignatureMetadata{}, metadata)
+	})
+}
+
+func TestReadSignature(t *testing.T) {
+	t.Run("should return `emptySignature, UnableToDecodeSignatureFromFileError` when unable to decode Signature", func(t *testing.T) {
+		// Setup
+		reader := &GobSignatureReader{decoder: decoderMock{isError: true}}
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError)
+		// Run
+		signature, err := reader.ReadSignature()
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+}
+
+func TestReadMetadata(t *testing.T) {
+	t.Run("should return `emptyMetadata, UnableToDecodeSignatureFromFileError` when unable to decode metadata", func(t *testing.T) {
+		// Setup
+		reader := &GobSignatureReader{decoder: decoderMock{isError: true}}
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError)
+		// Run
+		metadata, err := reader.ReadMetadata()
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.SignatureMetadata{}, metadata)
+	})
+}
+
+func TestWriteSignature(t *testing.T) {
+	t.Run("should return `UnableToWriteToFileError` when unable to encode Signature", func(t *testing.T) {
+		// Setup
+		writer := &GobSignatureWriter{encoder: encoderMock{isError: true}}
+		expectedError := errors.New(constants.UnableToWriteToFileError)
+		// Run
+		err := writer.WriteSignature(models.Signature{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package files
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// FreeSpace() will return `UnableToCheckFreeSpaceError` as checking free space is not supported on this platform.
+func FreeSpace(path string) (int64, error) {
+	return 0, errors.New(constants.UnableToCheckFreeSpaceError)
+}
@@ -0,0 +1,27 @@
+//go:build linux || darwin
+
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeSpace(t *testing.T) {
+	t.Run("should return `bytes, nil` when path exists", func(t *testing.T) {
+		// Run
+		result, err := FreeSpace(t.TempDir())
+		// Verify
+		require.Equal(t, nil, err)
+		require.Greater(t, result, int64(0))
+	})
+
+	t.Run("should return `0, error` when path does not exist", func(t *testing.T) {
+		// Run
+		result, err := FreeSpace("./does-not-exist/")
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Equal(t, int64(0), result)
+	})
+}
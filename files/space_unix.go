@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package files
+
+import "syscall"
+
+// FreeSpace() will return the bytes available to the current user on the filesystem containing the provided path.
+// Function will return `bytes, nil` when successful.
+// Function will return `0, error` when unable to check the filesystem (EG path does not exist).
+func FreeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
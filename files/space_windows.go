@@ -0,0 +1,25 @@
+package files
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// FreeSpace() will return the bytes available to the current user on the volume containing the provided path.
+// Function will return `bytes, nil` when successful.
+// Function will return `0, error` when unable to check the volume (EG path does not exist).
+func FreeSpace(path string) (int64, error) {
+	pointer, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var available uint64
+	if result, _, err := getDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(pointer)), uintptr(unsafe.Pointer(&available)), 0, 0); result == 0 {
+		return 0, err
+	}
+
+	return int64(available), nil
+}
@@ -0,0 +1,223 @@
+package files
+
+import (
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// DeltaStreamWriter type.
+// This will gob encode Delta blocks to a Delta file as they are finalised, so the full Delta never has to be held in memory (EG multi-GB Updated files).
+// Streamed Delta files contain a header (see headerMagic), a gob encoded block per write, then an end block, the metadata + a checksum trailer written by Close().
+// Note: streamed Delta files are opened in the same way as other Delta files (see OpenDeltaMetadata()).
+// DeltaStreamWriter will satisfy the `sync.DeltaWriter` interface.
+type DeltaStreamWriter struct {
+	stream   *streamEncoder
+	metadata models.DeltaMetadata
+}
+
+// SignatureStreamWriter type.
+// This will gob encode Signature entries to a Signature file as they are generated, so the full Signature never has to be held in memory (EG multi-GB Original files).
+// Streamed Signature files contain a header (see headerMagic), a gob encoded entry per write, then an end entry, the metadata + a checksum trailer written by Close().
+// Note: entries sharing a Weak hash are merged when the file is opened (see models.Signature.WriteEntry()), so streamed Signature files are opened in the same way as other Signature files (see OpenSignatureMetadata()).
+// SignatureStreamWriter will satisfy the `sync.SignatureEntryWriter` interface.
+type SignatureStreamWriter struct {
+	stream   *streamEncoder
+	metadata models.SignatureMetadata
+}
+
+// streamBlock type.
+// This will contain a Delta block, along with its position within the Updated file, as written to a streamed Delta file (see DeltaStreamWriter).
+// End will be set for the final block, which is followed by the Delta metadata.
+// Note: streamed Delta files written before metadata was recorded will end without an end block.
+type streamBlock struct {
+	Position int64
+	Block    models.Block
+	End      bool
+}
+
+// streamEncoder type.
+// This will gob encode records to a streamed file as they are produced, writing the header before the first record, and the checksum trailer once closed.
+// Note: records of streamed Signature + Delta files will be compressed when compression is enabled (see SetCompression()).
+type streamEncoder struct {
+	writer     io.Writer
+	checksum   hash.Hash32
+	compressor io.WriteCloser
+	encoder    Encoder
+	kind       byte
+	method     byte
+	started    bool
+}
+
+// streamEntry type.
+// This will contain a Signature entry (EG the Weak hash + block of a chunk), as written to a streamed Signature file (see SignatureStreamWriter).
+// End will be set for the final entry, which is followed by the Signature metadata.
+type streamEntry struct {
+	WeakHash int64
+	Item     models.StrongSignature
+	End      bool
+}
+
+// NewDeltaStreamWriter() will init and return a new DeltaStreamWriter which writes a streamed Delta file to the provided writer.
+func NewDeltaStreamWriter(writer io.Writer) *DeltaStreamWriter {
+	return &DeltaStreamWriter{stream: newStreamEncoder(writer, kindDeltaStream)}
+}
+
+// NewSignatureStreamWriter() will init and return a new SignatureStreamWriter which writes a streamed Signature file to the provided writer, recording the provided metadata once closed.
+func NewSignatureStreamWriter(writer io.Writer, metadata models.SignatureMetadata) *SignatureStreamWriter {
+	return &SignatureStreamWriter{stream: newStreamEncoder(writer, kindSignatureStream), metadata: metadata}
+}
+
+// newStreamEncoder() will init and return a new streamEncoder which writes a streamed file of the provided kind.
+func newStreamEncoder(writer io.Writer, kind byte) *streamEncoder {
+	checksum := crc32.NewIEEE()
+	method := compressionOf(kind)
+	compressor := newCompressor(io.MultiWriter(writer, checksum), method)
+	return &streamEncoder{writer: writer, checksum: checksum, compressor: compressor, encoder: newEncoder(compressor), kind: kind, method: method}
+}
+
+// Close() will finish the streamed Delta file by writing the end block, metadata + checksum trailer (along with the header when no blocks were written).
+// Note: the underlying writer will not be closed.
+// Function will return `nil` when successful.
+// Function will return `error` when unable to write the header, end block, metadata or trailer.
+func (w *DeltaStreamWriter) Close() error {
+	if err := w.stream.encode(streamBlock{End: true}); err != nil {
+		return err
+	}
+
+	if err := w.stream.encode(w.metadata); err != nil {
+		return err
+	}
+
+	return w.stream.close()
+}
+
+// SetMetadata() will set the metadata recorded once the streamed Delta file is closed (EG the SHA-256 of the Updated file, which is only known once every block has been generated).
+func (w *DeltaStreamWriter) SetMetadata(metadata models.DeltaMetadata) {
+	w.metadata = metadata
+}
+
+// WriteBlock() will gob encode a Delta block to the streamed Delta file (writing the header before the first block).
+// Function will return `nil` when successful.
+// Function will return `error` when unable to write the header or encode the block.
+func (w *DeltaStreamWriter) WriteBlock(position int64, block models.Block) error {
+	return w.stream.encode(streamBlock{Position: position, Block: block})
+}
+
+// Close() will finish the streamed Signature file by writing the end entry, metadata + checksum trailer (along with the header when no entries were written).
+// Note: the underlying writer will not be closed.
+// Function will return `nil` when successful.
+// Function will return `error` when unable to write the header, end entry, metadata or trailer.
+func (w *SignatureStreamWriter) Close() error {
+	if err := w.stream.encode(streamEntry{End: true}); err != nil {
+		return err
+	}
+
+	if err := w.stream.encode(w.metadata); err != nil {
+		return err
+	}
+
+	return w.stream.close()
+}
+
+// WriteEntry() will gob encode a Signature entry to the streamed Signature file (writing the header before the first entry).
+// Function will return `nil` when successful.
+// Function will return `error` when unable to write the header or encode the entry.
+func (w *SignatureStreamWriter) WriteEntry(weakHash int64, item models.StrongSignature) error {
+	return w.stream.encode(streamEntry{WeakHash: weakHash, Item: item})
+}
+
+// close() will flush the compressed records (when compressed), then write the checksum trailer of the streamed file (writing the header when not already written).
+func (e *streamEncoder) close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	if err := e.compressor.Close(); err != nil {
+		return err
+	}
+
+	_, err := e.writer.Write(trailer(e.checksum.Sum32()))
+	return err
+}
+
+// encode() will gob encode a record to the streamed file (writing the header when not already written).
+func (e *streamEncoder) encode(model any) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	return e.encoder.Encode(model)
+}
+
+// start() will write the header of the streamed file, when not already written.
+func (e *streamEncoder) start() error {
+	if e.started {
+		return nil
+	}
+
+	e.started = true
+	_, err := io.MultiWriter(e.writer, e.checksum).Write(compressedHeader(e.kind, e.method))
+	return err
+}
+
+// decodeDeltaStream() will decode every block of a streamed Delta file (see DeltaStreamWriter) into delta.
+// Note: the decoder will be left at the metadata following the end block (see OpenDeltaMetadata()).
+// Function returns `nil` when all blocks have been decoded.
+// Function returns `error` when unable to decode a block (EG corrupted file).
+func decodeDeltaStream(decoder Decoder, delta *models.Delta) error {
+	if *delta == nil {
+		*delta = models.Delta{}
+	}
+
+	for {
+		block := streamBlock{}
+		if err := decoder.Decode(&block); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		} else if block.End {
+			return nil
+		}
+
+		(*delta)[block.Position] = block.Block
+	}
+}
+
+// decodeSignatureStream() will decode every entry of a streamed Signature file (see SignatureStreamWriter) into signature, merging entries which share a Weak hash.
+// Note: the decoder will be left at the metadata following the end entry (see GobSignatureReader.ReadMetadata()).
+// Function returns `nil` when all entries have been decoded.
+// Function returns `error` when unable to decode an entry, or the file ends before the end entry (EG corrupted file).
+func decodeSignatureStream(decoder Decoder, signature *models.Signature) error {
+	if *signature == nil {
+		*signature = models.Signature{}
+	}
+
+	for {
+		entry := streamEntry{}
+		if err := decoder.Decode(&entry); err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		} else if entry.End {
+			return nil
+		}
+
+		_ = signature.WriteEntry(entry.WeakHash, entry.Item)
+	}
+}
+
+// decodePayload() will decode a gob payload into model, decoding every record when the header is for a streamed file of the same kind as model (EG a streamed Delta file decoded as a Delta).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to decode the payload.
+func decodePayload(decoder Decoder, kind byte, model any) error {
+	if delta, isDelta := model.(*models.Delta); isDelta && kind == kindDeltaStream {
+		return decodeDeltaStream(decoder, delta)
+	} else if signature, isSignature := model.(*models.Signature); isSignature && kind == kindSignatureStream {
+		return decodeSignatureStream(decoder, signature)
+	}
+
+	return decoder.Decode(model)
+}
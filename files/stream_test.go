@@ -0,0 +1,228 @@
+package files
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for io.Writer interface, which fails every write
+type failingStreamWriterMock struct{}
+
+// Overwrite failingStreamWriterMock.Write() to return an error
+func (w failingStreamWriterMock) Write(p []byte) (int, error) {
+	return 0, errors.New(errorMessage)
+}
+
+func TestDeltaStreamWriter(t *testing.T) {
+	delta := models.Delta{
+		0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")},
+		3: {Head: 4, Tail: 19, IsModified: true, Value: []byte("defghijklmnopqrs")},
+	}
+
+	t.Run("should write header, a gob encoded block per write, then checksum trailer", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Delta{}
+		writer := NewDeltaStreamWriter(&buffer)
+		// Mock
+		newEncoder = gob.NewEncoder
+		newDecoder = gob.NewDecoder
+		// Run
+		firstErr := writer.WriteBlock(0, delta[0])
+		secondErr := writer.WriteBlock(3, delta[3])
+		closeErr := writer.Close()
+		// Verify
+		require.Equal(t, nil, firstErr)
+		require.Equal(t, nil, secondErr)
+		require.Equal(t, nil, closeErr)
+		require.Equal(t, header(kindDeltaStream), buffer.Bytes()[:headerSize])
+		require.Equal(t, nil, (&checksumDecoder{reader: &buffer}).Decode(&result))
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should write an empty Delta when closed without writing blocks", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Delta{}
+		// Run
+		err := NewDeltaStreamWriter(&buffer).Close()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, header(kindDeltaStream), buffer.Bytes()[:headerSize])
+		require.Equal(t, nil, (&checksumDecoder{reader: &buffer}).Decode(&result))
+		require.Equal(t, models.Delta{}, result)
+	})
+
+	t.Run("should record metadata set before closing after the end block", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		metadata := models.DeltaMetadata{UpdatedHash: "some-updated-hash"}
+		result := models.Delta{}
+		decodedMetadata := models.DeltaMetadata{}
+		writer := NewDeltaStreamWriter(&buffer)
+		// Run
+		require.Equal(t, nil, writer.WriteBlock(0, delta[0]))
+		writer.SetMetadata(metadata)
+		err := writer.Close()
+		// Verify
+		require.Equal(t, nil, err)
+		decoder := &checksumDecoder{reader: &buffer}
+		require.Equal(t, nil, decoder.Decode(&result))
+		require.Equal(t, models.Delta{0: delta[0]}, result)
+		require.Equal(t, nil, decoder.Decode(&decodedMetadata))
+		require.Equal(t, metadata, decodedMetadata)
+	})
+
+	t.Run("should return `error` when unable to write to the writer", func(t *testing.T) {
+		// Setup
+		writer := NewDeltaStreamWriter(failingStreamWriterMock{})
+		// Run
+		err := writer.WriteBlock(0, delta[0])
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.NotEqual(t, nil, writer.Close())
+	})
+
+	t.Run("should open streamed Delta files, returning `UnableToDecodeDeltaFromFileError` when cut short while streaming", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		path := filepath.Join(t.TempDir(), "delta")
+		truncatedPath := filepath.Join(t.TempDir(), "truncated")
+		writer := NewDeltaStreamWriter(&buffer)
+		require.Equal(t, nil, writer.WriteBlock(0, delta[0]))
+		require.Equal(t, nil, writer.WriteBlock(3, delta[3]))
+		truncated := append([]byte{}, buffer.Bytes()...)
+		require.Equal(t, nil, writer.Close())
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		require.Equal(t, nil, os.WriteFile(truncatedPath, truncated, 0644))
+		expectedError := errors.New(constants.UnableToDecodeDeltaFromFileError + " (truncated: streamed Delta file has no checksum trailer)")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createNewDecoder = createDecoder
+		readMagic = rdiffMagic
+		readFile = os.ReadFile
+		// Run
+		result, err := OpenDelta(path, false)
+		truncatedResult, truncatedErr := OpenDelta(truncatedPath, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+		require.Equal(t, expectedError, truncatedErr)
+		require.Equal(t, models.Delta{}, truncatedResult)
+	})
+}
+
+func TestDecodeDeltaStream(t *testing.T) {
+	t.Run("should return `error` when a block can not be decoded", func(t *testing.T) {
+		// Setup
+		result := models.Delta{}
+		// Run
+		err := decodeDeltaStream(decoderMock{isError: true}, &result)
+		// Verify
+		require.Equal(t, errors.New(errorMessage), err)
+	})
+}
+
+func TestSignatureStreamWriter(t *testing.T) {
+	entries := []streamEntry{
+		{WeakHash: 7, Item: models.StrongSignature{Hash: "ab", Head: 0, Tail: 15}},
+		{WeakHash: -5, Item: models.StrongSignature{Hash: "cd", Head: 1, Tail: 16}},
+		{WeakHash: 7, Item: models.StrongSignature{Hash: "ef", Head: 2, Tail: 17}},
+	}
+
+	expected := models.Signature{
+		7:  {Hash: "ef", Head: 2, Tail: 17, Candidates: []models.StrongSignature{{Hash: "ab", Head: 0, Tail: 15}}},
+		-5: {Hash: "cd", Head: 1, Tail: 16},
+	}
+
+	metadata := models.SignatureMetadata{ChunkSize: 16, WeakHash: "adler32"}
+
+	t.Run("should write header, a gob encoded entry per write, then end entry, metadata + checksum trailer", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		writer := NewSignatureStreamWriter(&buffer, metadata)
+		// Mock
+		newEncoder = gob.NewEncoder
+		newDecoder = gob.NewDecoder
+		// Run
+		for _, entry := range entries {
+			require.Equal(t, nil, writer.WriteEntry(entry.WeakHash, entry.Item))
+		}
+
+		err := writer.Close()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, header(kindSignatureStream), buffer.Bytes()[:headerSize])
+		reader := NewGobSignatureReader(&buffer)
+		signature, err := reader.ReadSignature()
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, signature)
+		decodedMetadata, err := reader.ReadMetadata()
+		require.Equal(t, nil, err)
+		require.Equal(t, metadata, decodedMetadata)
+	})
+
+	t.Run("should return `error` when unable to write to the writer", func(t *testing.T) {
+		// Setup
+		writer := NewSignatureStreamWriter(failingStreamWriterMock{}, metadata)
+		// Run
+		err := writer.WriteEntry(entries[0].WeakHash, entries[0].Item)
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.NotEqual(t, nil, writer.Close())
+	})
+
+	t.Run("should open streamed Signature files, returning `UnableToDecodeSignatureFromFileError` when cut short while streaming", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		path := filepath.Join(t.TempDir(), "signature")
+		truncatedPath := filepath.Join(t.TempDir(), "truncated")
+		writer := NewSignatureStreamWriter(&buffer, metadata)
+		for _, entry := range entries {
+			require.Equal(t, nil, writer.WriteEntry(entry.WeakHash, entry.Item))
+		}
+
+		truncated := append([]byte{}, buffer.Bytes()...)
+		require.Equal(t, nil, writer.Close())
+		require.Equal(t, nil, os.WriteFile(path, buffer.Bytes(), 0644))
+		require.Equal(t, nil, os.WriteFile(truncatedPath, truncated, 0644))
+		expectedError := errors.New(constants.UnableToDecodeSignatureFromFileError + " (truncated: streamed Signature file has no checksum trailer)")
+		// Mock
+		getFileInfo = os.Stat
+		open = os.Open
+		createNewDecoder = createDecoder
+		readMagic = rdiffMagic
+		readFile = os.ReadFile
+		// Run
+		signature, decodedMetadata, err := OpenSignatureMetadata(path, false)
+		_, _, truncatedErr := OpenSignatureMetadata(truncatedPath, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, signature)
+		require.Equal(t, metadata, decodedMetadata)
+		require.Equal(t, expectedError, truncatedErr)
+	})
+}
+
+func TestDecodeSignatureStream(t *testing.T) {
+	t.Run("should return `ErrUnexpectedEOF` when payload ends before the end entry", func(t *testing.T) {
+		// Setup
+		var buffer bytes.Buffer
+		result := models.Signature{}
+		require.Equal(t, nil, gob.NewEncoder(&buffer).Encode(streamEntry{WeakHash: 1, Item: models.StrongSignature{Hash: "ab", Head: 0, Tail: 15}}))
+		// Run
+		err := decodeSignatureStream(gob.NewDecoder(&buffer), &result)
+		// Verify
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
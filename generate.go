@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/mutate"
+)
+
+var (
+	generateData = mutate.Generate
+	writeToFile  = files.WriteToFile
+)
+
+// runGenerate() will write a synthetic Original file (filled with the `-pattern` content) to Outputs folder.
+// When an Updated file is provided, a copy of the Original file with random mutations applied (see `-insertions`, `-deletions` + `-moves`) will also be written.
+// Original file will use `Seed` as its random seed, and Updated file `Seed + 1`, so the same inputs can be reproduced + shared by seed.
+// Function returns `nil` when successful.
+// Function returns `OverwriteDeclinedError` when user declines overwriting an existing output file.
+// Function returns `InvalidPatternError` when pattern is unknown.
+// Function returns `error` when unable to write either file.
+func runGenerate(cmd models.CMD) error {
+	for _, fileName := range []string{cmd.OriginalFile, cmd.UpdatedFile} {
+		if fileName == "" {
+			continue
+		}
+
+		if err := confirmOverwrite(cmd, fileName); err != nil {
+			return err
+		}
+	}
+
+	original, err := generateData(int(cmd.GenSize), cmd.Pattern, newRandom(cmd.Seed))
+	if err != nil {
+		return err
+	}
+
+	if err = writeToFile(cmd.OriginalFile, original); err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("%s created: %s (%d bytes, %s)\n", cmd.OriginalFile, outputPath(cmd.OriginalFile), len(original), cmd.Pattern), true)
+	if cmd.UpdatedFile == "" {
+		return nil
+	}
+
+	updated := mutateData(original, cmd.Mutations, newRandom(cmd.Seed+1))
+	if err = writeToFile(cmd.UpdatedFile, updated); err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("%s created: %s (%d bytes, %d insertions, %d deletions, %d moves)\n", cmd.UpdatedFile, outputPath(cmd.UpdatedFile), len(updated), cmd.Mutations.Insertions, cmd.Mutations.Deletions, cmd.Mutations.Moves), true)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/mutate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGenerate(t *testing.T) {
+	t.Run("should return `nil` after writing Original + mutated Updated files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			GenMode:      true,
+			OriginalFile: "original.bin",
+			UpdatedFile:  "updated.bin",
+			GenSize:      64,
+			Pattern:      constants.PatternText,
+			Mutations:    models.Mutations{Insertions: 1, Deletions: 1, Moves: 1, MaxSize: 8},
+			Seed:         3,
+			Yes:          true,
+		}
+
+		written := map[string][]byte{}
+		// Mock
+		logger = func(message string, verbose bool) {}
+		generateData = mutate.Generate
+		mutateData = mutate.Mutate
+		newRandom = mutate.NewRandom
+		writeToFile = func(fileName string, output []byte) error {
+			written[fileName] = output
+			return nil
+		}
+
+		expectedOriginal, _ := mutate.Generate(64, constants.PatternText, mutate.NewRandom(3))
+		expectedUpdated := mutate.Mutate(expectedOriginal, cmd.Mutations, mutate.NewRandom(4))
+		// Run
+		err := runGenerate(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedOriginal, written["original.bin"])
+		require.Equal(t, expectedUpdated, written["updated.bin"])
+	})
+
+	t.Run("should return `nil` after writing only Original file when no Updated file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GenMode: true, OriginalFile: "original.bin", GenSize: 16, Pattern: constants.PatternRandom, Yes: true}
+		written := []string{}
+		// Mock
+		writeToFile = func(fileName string, output []byte) error {
+			written = append(written, fileName)
+			return nil
+		}
+
+		// Run
+		err := runGenerate(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []string{"original.bin"}, written)
+	})
+
+	t.Run("should return `InvalidPatternError` when pattern is unknown", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GenMode: true, OriginalFile: "original.bin", GenSize: 16, Pattern: "zeros", Yes: true}
+		expectedError := errors.New(constants.InvalidPatternError)
+		// Run
+		err := runGenerate(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `error` when unable to write Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GenMode: true, OriginalFile: "original.bin", UpdatedFile: "updated.bin", GenSize: 16, Pattern: constants.PatternRandom, Yes: true}
+		expectedError := errors.New(constants.UnableToCreateFileError)
+		// Mock
+		writeToFile = func(fileName string, output []byte) error {
+			return expectedError
+		}
+
+		// Run
+		err := runGenerate(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	// Restore, so later tests write real files
+	writeToFile = files.WriteToFile
+}
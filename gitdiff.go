@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const gitShortHash int = 7 // Length of abbreviated git object hashes
+
+// formatGitDiffRegion() will format a Delta operation as a changed region of the Updated file for the git diff driver report.
+// Literal operations will be reported as changed, and copies from a different position in the Original file will be reported as moved.
+// Function returns `region, true` when the operation changes the file.
+// Function returns `"", false` when the operation copies bytes from the same position in the Original file (EG unchanged).
+func formatGitDiffRegion(op models.Op) (string, bool) {
+	if op.Kind == models.OpLiteral {
+		return fmt.Sprintf("  changed  new[%d:%d] (%d bytes)", op.Position, op.Position+op.Len(), op.Len()), true
+	}
+
+	if op.Head != op.Position {
+		return fmt.Sprintf("  moved    old[%d:%d] -> new[%d:%d] (%d bytes)", op.Head, op.Tail+1, op.Position, op.Position+op.Len(), op.Len()), true
+	}
+
+	return "", false
+}
+
+// gitDiffDelta() will generate a Delta of how to update the old blob file to match the new blob file.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when new blob has no block-level changes.
+// Function returns `emptyDelta, error` when unable to open either blob file.
+// Function returns `emptyDelta, UnableToGenerateSignatureError` when unable to generate Signature of old blob.
+// Function returns `emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta of new blob.
+func gitDiffDelta(oldFile string, newFile string, verbose bool) (models.Delta, error) {
+	reader, err := openOriginal(oldFile)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	signature, err := generateSignature(reader, verbose)
+	if err != nil {
+		return models.Delta{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	reader, err = openUpdated(newFile)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	delta, err := generateDelta(reader, signature, verbose)
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return models.Delta{}, err
+		}
+
+		return models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	return delta, nil
+}
+
+// runGitDiffDriver() will report the changed regions between two blob files, when invoked by git as an external diff driver.
+// Git will provide seven arguments: path old-file old-hex old-mode new-file new-hex new-mode.
+// EG: `git config diff.gofilediff.command "go-file-diff -gitDiffDriver"` + `*.bin diff=gofilediff` in `.gitattributes`.
+// Function returns `nil` when report has been logged (including when blobs have no block-level changes).
+// Function returns `error` when unable to generate Delta between blob files.
+func runGitDiffDriver(cmd models.CMD) error {
+	path, oldFile, oldHex, newFile, newHex := cmd.Args[0], cmd.Args[1], cmd.Args[2], cmd.Args[4], cmd.Args[5]
+	logger(utils.Stat(fmt.Sprintf("diff --go-file-diff a/%s b/%s", path, path)), true)
+	logger(fmt.Sprintf("index %s..%s", shortHash(oldHex), shortHash(newHex)), true)
+	delta, err := gitDiffDelta(oldFile, newFile, cmd.Verbose)
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			logger("  no block-level changes", true)
+			return nil
+		}
+
+		return err
+	}
+
+	matched, literal := deltaStats(delta)
+	logger(fmt.Sprintf("  %d bytes matched, %d bytes literal", matched, literal), true)
+	if len(delta) == 0 {
+		logger("  all content removed", true)
+		return nil
+	}
+
+	return delta.Ops(func(op models.Op) error {
+		if region, changed := formatGitDiffRegion(op); changed {
+			logger(region, true)
+		}
+
+		return nil
+	})
+}
+
+// shortHash() will abbreviate a git object hash for the git diff driver report.
+func shortHash(hash string) string {
+	if len(hash) > gitShortHash {
+		return hash[:gitShortHash]
+	}
+
+	return hash
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var gitDiffArgs = []string{"assets/image.bin", "/tmp/old", "0123456789abcdef", "100644", "/tmp/new", "fedcba9876543210", "100644"}
+
+func TestFormatGitDiffRegion(t *testing.T) {
+	t.Run("should return changed region for literal operation", func(t *testing.T) {
+		// Setup
+		op := models.Op{Kind: models.OpLiteral, Position: 16, Value: []byte{'a', 'b', 'c'}}
+		// Run
+		region, changed := formatGitDiffRegion(op)
+		// Verify
+		require.Equal(t, true, changed)
+		require.Equal(t, "  changed  new[16:19] (3 bytes)", region)
+	})
+
+	t.Run("should return moved region for copy from different position in Original file", func(t *testing.T) {
+		// Setup
+		op := models.Op{Kind: models.OpCopy, Position: 32, Head: 0, Tail: 15}
+		// Run
+		region, changed := formatGitDiffRegion(op)
+		// Verify
+		require.Equal(t, true, changed)
+		require.Equal(t, "  moved    old[0:16] -> new[32:48] (16 bytes)", region)
+	})
+
+	t.Run("should return false for copy from same position in Original file", func(t *testing.T) {
+		// Setup
+		op := models.Op{Kind: models.OpCopy, Position: 16, Head: 16, Tail: 31}
+		// Run
+		region, changed := formatGitDiffRegion(op)
+		// Verify
+		require.Equal(t, false, changed)
+		require.Equal(t, "", region)
+	})
+}
+
+func TestGitDiffDelta(t *testing.T) {
+	t.Run("should return `delta, nil` when blob files differ", func(t *testing.T) {
+		// Setup
+		expectedDelta := models.Delta{0: models.Block{IsModified: true, Value: []byte("new")}}
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		// Run
+		delta, err := gitDiffDelta(file, file, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileDoesNotExistError` when new blob file cannot be found", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UpdatedFileDoesNotExistError)
+		calls := 0
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			calls++
+			if calls > 1 {
+				return nil, errors.New(constants.FileDoesNotExistError)
+			}
+
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		// Run
+		delta, err := gitDiffDelta(file, file, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToGenerateSignatureError` when unable to generate Signature of old blob", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		delta, err := gitDiffDelta(file, file, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta of new blob", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(errorMessage)
+		}
+
+		// Run
+		delta, err := gitDiffDelta(file, file, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
+
+func TestRunGitDiffDriver(t *testing.T) {
+	t.Run("should log changed regions between blob files", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GitDiffDriver: true, Args: gitDiffArgs}
+		logged := []string{}
+		expectedLogs := []string{
+			"index 0123456..fedcba9",
+			"  16 bytes matched, 3 bytes literal",
+			"  changed  new[0:3] (3 bytes)",
+		}
+
+		// Mock
+		utils.SetColor(false)
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{
+				0: models.Block{IsModified: true, Value: []byte("new")},
+				3: models.Block{Head: 3, Tail: 18},
+			}, nil
+		}
+
+		// Run
+		err := runGitDiffDriver(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "diff --go-file-diff a/assets/image.bin b/assets/image.bin", logged[0])
+		require.Equal(t, expectedLogs, logged[1:])
+	})
+
+	t.Run("should log no changes when blob files have no block-level changes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GitDiffDriver: true, Args: gitDiffArgs}
+		logged := []string{}
+		// Mock
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(constants.UpdatedFileHasNoChangesError)
+		}
+
+		// Run
+		err := runGitDiffDriver(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "  no block-level changes", logged[len(logged)-1])
+	})
+
+	t.Run("should return error when unable to open old blob file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{GitDiffDriver: true, Args: gitDiffArgs}
+		expectedError := errors.New(constants.OriginalFileDoesNotExistError)
+		// Mock
+		logger = func(message string, verbose bool) {}
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.FileDoesNotExistError)
+		}
+
+		// Run
+		err := runGitDiffDriver(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestShortHash(t *testing.T) {
+	t.Run("should abbreviate long git object hash", func(t *testing.T) {
+		// Run
+		result := shortHash("0123456789abcdef")
+		// Verify
+		require.Equal(t, "0123456", result)
+	})
+
+	t.Run("should return short hash unchanged (EG `.` for missing blob)", func(t *testing.T) {
+		// Run
+		result := shortHash(".")
+		// Verify
+		require.Equal(t, ".", result)
+	})
+}
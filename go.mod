@@ -2,10 +2,20 @@ module github.com/curtismenmuir/go-file-diff
 
 go 1.18
 
-require github.com/stretchr/testify v1.7.5
+require (
+	github.com/dsnet/compress v0.0.1
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/klauspost/compress v1.17.2
+	github.com/stretchr/testify v1.7.5
+	golang.org/x/crypto v0.24.0
+	golang.org/x/term v0.21.0
+	google.golang.org/protobuf v1.33.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/sys v0.21.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
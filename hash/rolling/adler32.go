@@ -0,0 +1,47 @@
+package rolling
+
+// Adler32 type.
+// This will generate a `weak` hash of a byte array based on the rolling checksum of rsync (EG an Adler-32 style checksum, with a 2^16 modulus instead of a prime).
+// EG a = (array[0] + array[1] + ... + array[n]) % 2^16; b = ((n+1 * array[0]) + (n * array[1]) + ... + (1 * array[n])) % 2^16; hash = a + (b * 2^16);
+// Note: hashing only requires additions + multiplications of small integers (EG no big.Int modulus), so is faster than Rabin–Karp but may collide more often.
+// Adler32 will satisfy the `Hash` interface.
+type Adler32 struct{}
+
+// NewAdler32() will create an Adler-32 style rolling checksum.
+func NewAdler32() Adler32 {
+	return Adler32{}
+}
+
+// Sum() will generate a `weak` hash of a byte array based on the rolling checksum of rsync.
+// Note: buffers smaller than chunkSize will be hashed as though padded with zeros (EG the same as Rabin–Karp).
+// Function returns `hash`.
+func (Adler32) Sum(buffer []byte, chunkSize int64) int64 {
+	var a, b uint32
+	for index, value := range buffer {
+		// Sum of bytes -> a + buffer[i]
+		a += uint32(value)
+		// Sum of bytes weighted by distance from end of chunk -> b + ((chunkSize - i) * buffer[i])
+		b += uint32(chunkSize-int64(index)) * uint32(value)
+	}
+
+	return checksum(a, b)
+}
+
+// Roll() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
+// EG a = a - initialByte + nextByte; b = b - (n * initialByte) + a;
+// This function will return `updatedHash` once complete.
+func (Adler32) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	a := uint32(hash) & 0xffff
+	b := uint32(hash) >> 16
+	// Remove initialByte + add nextByte to sum of bytes
+	a = a - uint32(initialByte) + uint32(nextByte)
+	// Remove weighted initialByte, then increase weight of remaining bytes by adding the updated sum of bytes
+	b = b - uint32(chunkSize)*uint32(initialByte) + a
+	return checksum(a, b)
+}
+
+// checksum() will combine both sums into a single hash, keeping the low 16 bits of each.
+// Function returns `hash` -> EG (a % 2^16) + ((b % 2^16) * 2^16);
+func checksum(a uint32, b uint32) int64 {
+	return int64(a&0xffff | b<<16)
+}
@@ -0,0 +1,79 @@
+package rolling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var adler32 = NewAdler32()
+
+func TestAdler32Sum(t *testing.T) {
+	t.Run("should return a consistent `resultHash` after hashing the provided buffer", func(t *testing.T) {
+		// Setup
+		expectedHash := int64(0x36300688) // a = 1672, b = 13872
+		// Run
+		resultHash := adler32.Sum(testBuffer, testChunk)
+		// Verify
+		require.Equal(t, expectedHash, resultHash)
+	})
+
+	t.Run("should generate a different `resultHash` for hashes which have been reversed (EG byte order important)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'p', 'o', 'n', 'm', 'l', 'k', 'j', 'i', 'h', 'g', 'f', 'e', 'd', 'c', 'b', 'a'}
+		// Run
+		resultHash := adler32.Sum(testBuffer, testChunk)
+		differentHash := adler32.Sum(buffer, testChunk)
+		// Verify
+		require.NotEqual(t, differentHash, resultHash)
+	})
+
+	t.Run("should hash buffers smaller than chunk size as though padded with zeros", func(t *testing.T) {
+		// Setup
+		padded := append(append([]byte{}, testBuffer[:10]...), make([]byte, 6)...)
+		// Run
+		resultHash := adler32.Sum(testBuffer[:10], testChunk)
+		// Verify
+		require.Equal(t, adler32.Sum(padded, testChunk), resultHash)
+	})
+}
+
+func TestAdler32Roll(t *testing.T) {
+	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		hash := adler32.Sum(testBuffer, testChunk)
+		// Run
+		result := adler32.Roll(hash, testBuffer[0], testBufferNextChar, testChunk)
+		// Verify
+		require.NotEqual(t, hash, result)
+		require.Equal(t, adler32.Sum(buffer, testChunk), result)
+	})
+
+	t.Run("should roll to correct hash when sums wrap (EG max byte size + max chunk size)", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, MaxChunkSize+1)
+		for index := range buffer {
+			buffer[index] = byte(255 - index%3)
+		}
+
+		hash := adler32.Sum(buffer[:MaxChunkSize], MaxChunkSize)
+		// Run
+		result := adler32.Roll(hash, buffer[0], buffer[MaxChunkSize], MaxChunkSize)
+		// Verify
+		require.Equal(t, adler32.Sum(buffer[1:], MaxChunkSize), result)
+	})
+}
+
+func BenchmarkAdler32Sum(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		adler32.Sum(testBuffer, testChunk)
+	}
+}
+
+func BenchmarkAdler32Roll(b *testing.B) {
+	hash := adler32.Sum(testBuffer, testChunk)
+	for i := 0; i < b.N; i++ {
+		hash = adler32.Roll(hash, byte(i), byte(int64(i)+testChunk), testChunk)
+	}
+}
@@ -0,0 +1,59 @@
+package rolling
+
+import "math/bits"
+
+// buzhashSeed is the seed of the byte table used by Buzhash.
+// Note: Signatures record only the name of the Weak hash, so the seed (and table generation) must never change.
+const buzhashSeed uint64 = 0x9e3779b97f4a7c15
+
+// buzhashTable will map each byte to a pseudo-random 64 bit value (see newBuzhashTable()).
+var buzhashTable = newBuzhashTable(buzhashSeed)
+
+// Buzhash type.
+// This will generate a `weak` hash of a byte array based on the Buzhash algorithm (EG a cyclic polynomial of a random value per byte).
+// EG hash = rotate(table[array[0]], n-1) ^ rotate(table[array[1]], n-2) ^ ... ^ rotate(table[array[n]], 0);
+// Note: hashing only requires bit rotations + XOR (EG no modulus), so is the fastest Weak hash when throughput matters more than rsync compatibility.
+// Buzhash will satisfy the `Hash` interface.
+type Buzhash struct{}
+
+// NewBuzhash() will create a Buzhash rolling hash.
+func NewBuzhash() Buzhash {
+	return Buzhash{}
+}
+
+// Sum() will generate a `weak` hash of a byte array based on the Buzhash algorithm.
+// Note: buffers smaller than chunkSize will be hashed as though at the start of a chunk (EG the same as Rabin–Karp).
+// Function returns `hash`.
+func (Buzhash) Sum(buffer []byte, chunkSize int64) int64 {
+	var hash uint64
+	for index, value := range buffer {
+		// Rotate table value by distance from end of chunk -> hash ^ rotate(table[buffer[i]], chunkSize-1-i)
+		hash ^= bits.RotateLeft64(buzhashTable[value], int((chunkSize-1-int64(index))%64))
+	}
+
+	return int64(hash)
+}
+
+// Roll() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
+// EG newHash = rotate(hash, 1) ^ rotate(table[initialByte], n) ^ table[nextByte];
+// This function will return `updatedHash` once complete.
+func (Buzhash) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	updatedHash := bits.RotateLeft64(uint64(hash), 1)
+	// Remove initialByte (rotated a further position by the roll) + add nextByte
+	updatedHash ^= bits.RotateLeft64(buzhashTable[initialByte], int(chunkSize%64)) ^ buzhashTable[nextByte]
+	return int64(updatedHash)
+}
+
+// newBuzhashTable() will generate a pseudo-random 64 bit value for each byte, using SplitMix64 from the provided seed.
+func newBuzhashTable(seed uint64) [256]uint64 {
+	var table [256]uint64
+	for index := range table {
+		seed += 0x9e3779b97f4a7c15
+		value := seed
+		value = (value ^ (value >> 30)) * 0xbf58476d1ce4e5b9
+		value = (value ^ (value >> 27)) * 0x94d049bb133111eb
+		table[index] = value ^ (value >> 31)
+	}
+
+	return table
+}
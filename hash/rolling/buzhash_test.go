@@ -0,0 +1,81 @@
+package rolling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var buzhash = NewBuzhash()
+
+func TestBuzhashSum(t *testing.T) {
+	t.Run("should return a consistent `resultHash` after hashing the provided buffer", func(t *testing.T) {
+		// Setup
+		expectedHash := buzhash.Sum(testBuffer, testChunk)
+		// Run
+		resultHash := buzhash.Sum(append([]byte{}, testBuffer...), testChunk)
+		// Verify
+		require.Equal(t, expectedHash, resultHash)
+		require.NotEqual(t, int64(0), resultHash)
+	})
+
+	t.Run("should generate a different `resultHash` for hashes which have been reversed (EG byte order important)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'p', 'o', 'n', 'm', 'l', 'k', 'j', 'i', 'h', 'g', 'f', 'e', 'd', 'c', 'b', 'a'}
+		// Run
+		resultHash := buzhash.Sum(testBuffer, testChunk)
+		differentHash := buzhash.Sum(buffer, testChunk)
+		// Verify
+		require.NotEqual(t, differentHash, resultHash)
+	})
+
+	t.Run("should generate a different `resultHash` for repeated bytes an even distance apart (EG XOR does not cancel out)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a', 'a'}
+		// Run
+		resultHash := buzhash.Sum(buffer, testChunk)
+		// Verify
+		require.NotEqual(t, int64(0), resultHash)
+		require.NotEqual(t, buzhash.Sum(buffer[:14], testChunk), resultHash)
+	})
+}
+
+func TestBuzhashRoll(t *testing.T) {
+	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		hash := buzhash.Sum(testBuffer, testChunk)
+		// Run
+		result := buzhash.Roll(hash, testBuffer[0], testBufferNextChar, testChunk)
+		// Verify
+		require.NotEqual(t, hash, result)
+		require.Equal(t, buzhash.Sum(buffer, testChunk), result)
+	})
+
+	t.Run("should roll to correct hash when chunk size is larger than 64 (EG rotations wrap)", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, MaxChunkSize+1)
+		for index := range buffer {
+			buffer[index] = byte(index % 251)
+		}
+
+		hash := buzhash.Sum(buffer[:MaxChunkSize], MaxChunkSize)
+		// Run
+		result := buzhash.Roll(hash, buffer[0], buffer[MaxChunkSize], MaxChunkSize)
+		// Verify
+		require.Equal(t, buzhash.Sum(buffer[1:], MaxChunkSize), result)
+	})
+}
+
+func BenchmarkBuzhashSum(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		buzhash.Sum(testBuffer, testChunk)
+	}
+}
+
+func BenchmarkBuzhashRoll(b *testing.B) {
+	hash := buzhash.Sum(testBuffer, testChunk)
+	for i := 0; i < b.N; i++ {
+		hash = buzhash.Roll(hash, byte(i), byte(int64(i)+testChunk), testChunk)
+	}
+}
@@ -0,0 +1,97 @@
+package rolling
+
+const (
+	RollsumName        string = "rollsum"          // Weak hash of rdiff Signatures generated by librsync before 2.2
+	RdiffRabinKarpName string = "rdiff-rabin-karp" // Weak hash of rdiff Signatures generated by librsync 2.2+
+)
+
+const (
+	rollsumCharOffset uint32 = 31         // Added to every byte by rollsum (EG ROLLSUM_CHAR_OFFSET of librsync)
+	rdiffSeed         uint32 = 1          // Initial hash of librsync's RabinKarp (EG RABINKARP_SEED)
+	rdiffMult         uint32 = 0x08104225 // Multiplier of librsync's RabinKarp (EG RABINKARP_MULT)
+)
+
+// Rollsum type.
+// This will generate a `weak` hash of a byte array in the same way as the rollsum of librsync (EG rdiff Signatures generated by librsync before 2.2).
+// EG s1 = (array[0] + 31) + ... + (array[n] + 31); s2 = running total of s1; hash = s2 << 16 | s1 (both mod 2^16);
+// Note: unlike Adler32, buffers smaller than chunkSize will be hashed by their own length (EG the final block of an rdiff Signature).
+// Rollsum will satisfy the `Hash` interface.
+type Rollsum struct{}
+
+// RdiffRabinKarp type.
+// This will generate a `weak` hash of a byte array in the same way as the RabinKarp hash of librsync (EG rdiff Signatures generated by librsync 2.2+).
+// EG hash = seed * m^n + array[0] * m^(n-1) + ... + array[n] (mod 2^32);
+// Note: this differs from RabinKarp (which uses a prime modulus), so both can be selected independently.
+// RdiffRabinKarp will satisfy the `Hash` interface.
+type RdiffRabinKarp struct{}
+
+// NewRollsum() will create a rollsum rolling hash.
+func NewRollsum() Rollsum {
+	return Rollsum{}
+}
+
+// NewRdiffRabinKarp() will create a librsync RabinKarp rolling hash.
+func NewRdiffRabinKarp() RdiffRabinKarp {
+	return RdiffRabinKarp{}
+}
+
+// Sum() will generate a `weak` hash of a byte array based on librsync's rollsum.
+// Function returns `hash`.
+func (Rollsum) Sum(buffer []byte, chunkSize int64) int64 {
+	var a, b uint32
+	for _, value := range buffer {
+		a += uint32(value)
+		b += a
+	}
+
+	// Add the char offset of every byte -> a + n*31, b + (n*(n+1)/2)*31
+	size := uint32(len(buffer))
+	a += size * rollsumCharOffset
+	b += size * (size + 1) / 2 * rollsumCharOffset
+	return rollsumDigest(a, b)
+}
+
+// Roll() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
+// EG a = a - initialByte + nextByte; b = b + a - chunkSize*(initialByte + 31);
+// This function will return `updatedHash` once complete.
+func (Rollsum) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	a := uint32(hash) & 0xffff
+	b := uint32(hash) >> 16
+	a = a - uint32(initialByte) + uint32(nextByte)
+	b = b + a - uint32(chunkSize)*(uint32(initialByte)+rollsumCharOffset)
+	return rollsumDigest(a, b)
+}
+
+// rollsumDigest() will combine both sums of rollsum into a hash (EG RollsumDigest of librsync).
+func rollsumDigest(a uint32, b uint32) int64 {
+	return int64((b&0xffff)<<16 | a&0xffff)
+}
+
+// Sum() will generate a `weak` hash of a byte array based on librsync's RabinKarp.
+// Function returns `hash`.
+func (RdiffRabinKarp) Sum(buffer []byte, chunkSize int64) int64 {
+	hash := rdiffSeed
+	for _, value := range buffer {
+		hash = hash*rdiffMult + uint32(value)
+	}
+
+	return int64(hash)
+}
+
+// Roll() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
+// EG newHash = hash*m + nextByte - m^n*(initialByte + m - 1), which also removes the seed's contribution from the previous position;
+// This function will return `updatedHash` once complete.
+func (RdiffRabinKarp) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	// Calculate m^n (mod 2^32) using square-and-multiply
+	power, base := uint32(1), rdiffMult
+	for exponent := chunkSize; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			power *= base
+		}
+
+		base *= base
+	}
+
+	updatedHash := uint32(hash)*rdiffMult + uint32(nextByte) - power*(uint32(initialByte)+rdiffMult-1)
+	return int64(updatedHash)
+}
@@ -0,0 +1,96 @@
+package rolling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	rollsum        = NewRollsum()
+	rdiffRabinKarp = NewRdiffRabinKarp()
+)
+
+func TestRollsumSum(t *testing.T) {
+	t.Run("should return the same `resultHash` as librsync's rollsum", func(t *testing.T) {
+		// Run
+		resultHash := rollsum.Sum(testBuffer, testChunk)
+		// Verify
+		require.Equal(t, int64(0x46a80878), resultHash)
+	})
+
+	t.Run("should hash buffers smaller than chunk size by their own length (EG final block of an rdiff Signature)", func(t *testing.T) {
+		// Setup
+		// EG s1 = 97+98+99 + 3*31 = 0x183, s2 = 97+195+294 + 6*31 = 0x304
+		expectedHash := int64(0x03040183)
+		// Run
+		resultHash := rollsum.Sum([]byte{'a', 'b', 'c'}, testChunk)
+		// Verify
+		require.Equal(t, expectedHash, resultHash)
+	})
+}
+
+func TestRollsumRoll(t *testing.T) {
+	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		hash := rollsum.Sum(testBuffer, testChunk)
+		// Run
+		result := rollsum.Roll(hash, testBuffer[0], testBufferNextChar, testChunk)
+		// Verify
+		require.NotEqual(t, hash, result)
+		require.Equal(t, rollsum.Sum(buffer, testChunk), result)
+	})
+
+	t.Run("should roll to correct hash when using max chunk size (eg sums wrap)", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, MaxChunkSize+1)
+		for index := range buffer {
+			buffer[index] = byte(255 - index%7)
+		}
+
+		hash := rollsum.Sum(buffer[:MaxChunkSize], MaxChunkSize)
+		// Run
+		result := rollsum.Roll(hash, buffer[0], buffer[MaxChunkSize], MaxChunkSize)
+		// Verify
+		require.Equal(t, rollsum.Sum(buffer[1:], MaxChunkSize), result)
+	})
+}
+
+func TestRdiffRabinKarpSum(t *testing.T) {
+	t.Run("should return the same `resultHash` as librsync's RabinKarp", func(t *testing.T) {
+		// Run
+		resultHash := rdiffRabinKarp.Sum(testBuffer, testChunk)
+		shortHash := rdiffRabinKarp.Sum([]byte{'a', 'b', 'c'}, testChunk)
+		// Verify
+		require.Equal(t, int64(0xe52b8fa9), resultHash)
+		require.Equal(t, int64(0x66298923), shortHash)
+	})
+}
+
+func TestRdiffRabinKarpRoll(t *testing.T) {
+	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		hash := rdiffRabinKarp.Sum(testBuffer, testChunk)
+		// Run
+		result := rdiffRabinKarp.Roll(hash, testBuffer[0], testBufferNextChar, testChunk)
+		// Verify
+		require.NotEqual(t, hash, result)
+		require.Equal(t, rdiffRabinKarp.Sum(buffer, testChunk), result)
+	})
+
+	t.Run("should roll to correct hash when using max chunk size (eg no overflow)", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, MaxChunkSize+1)
+		for index := range buffer {
+			buffer[index] = byte(255 - index%7)
+		}
+
+		hash := rdiffRabinKarp.Sum(buffer[:MaxChunkSize], MaxChunkSize)
+		// Run
+		result := rdiffRabinKarp.Roll(hash, buffer[0], buffer[MaxChunkSize], MaxChunkSize)
+		// Verify
+		require.Equal(t, rdiffRabinKarp.Sum(buffer[1:], MaxChunkSize), result)
+	})
+}
@@ -0,0 +1,119 @@
+// Package rolling provides rolling (Weak) hash algorithms, which can be moved along a buffer one byte at a time without rehashing the whole buffer.
+// Rolling hashes are used to find blocks of an Original file at any offset of an Updated file, so are fast but may collide (see `sync` for confirming matches with a Strong hash).
+package rolling
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+const (
+	DefaultSeed      int64 = 11           // Prime number
+	DefaultMod       int64 = 100000000009 // 10^11 + 9
+	DefaultChunkSize int64 = 16           // 16 (bytes) is the default chunk size (EG the only chunk size before it was configurable)
+	MaxChunkSize     int64 = 131072       // 128 KiB (EG the max block size of rsync)
+)
+
+// Hash interface.
+// Sum will hash a buffer of (up to) chunkSize bytes.
+// Roll will update a hash when the buffer rolls forward one byte (EG initialByte removed + nextByte added).
+// Roll must return the same value as calling Sum on the rolled buffer.
+type Hash interface {
+	Sum(buffer []byte, chunkSize int64) int64
+	Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64
+}
+
+// RabinKarp type.
+// This will generate a `weak` hash of a byte array based on the Rabin–Karp algorithm, using the provided Seed (prime number) + Mod.
+// Note: Seed * Mod must fit within an int64, as the hash is multiplied by Seed before the mod is applied when rolling.
+// RabinKarp will satisfy the `Hash` interface.
+type RabinKarp struct {
+	Seed int64
+	Mod  int64
+}
+
+// Funcs type.
+// This will satisfy the `Hash` interface with a pair of functions (EG an algorithm which is not defined as a type).
+type Funcs struct {
+	SumFunc  func(buffer []byte, chunkSize int64) int64
+	RollFunc func(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64
+}
+
+// NewRabinKarp() will create a Rabin–Karp hash using DefaultSeed + DefaultMod.
+func NewRabinKarp() RabinKarp {
+	return RabinKarp{Seed: DefaultSeed, Mod: DefaultMod}
+}
+
+// Sum() will generate a `weak` hash of a byte array based on the Rabin–Karp algorithm.
+// EG hash = ((array[0] * seed^n-1) + (array[1] * seed^n-2) + ... + (array[n] * seed^0)) % mod;
+// Hash is classed as `weak` as there is potential for collisions.
+// Function returns `hash`.
+func (r RabinKarp) Sum(buffer []byte, chunkSize int64) int64 {
+	var hash int64 = 0
+	for index := range buffer {
+		// Shift hash by seed + add buffer item -> (hash * seed) + buffer[i] (EG Horner's method, so each item is multiplied by seed^multiplier)
+		hash = (mulMod(hash, r.Seed, r.Mod) + int64(buffer[index])) % r.Mod
+	}
+
+	// Shift hash of buffers smaller than chunk size, so items keep the same multiplier -> hash * seed^(chunkSize-len)
+	hash = mulMod(hash, powMod(r.Seed, chunkSize-int64(len(buffer)), r.Mod), r.Mod)
+	// Mod output for final hash
+	hash = Modulo(hash, r.Mod)
+	return hash
+}
+
+// Roll() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
+// EG newHash = ((((hash - ((initialByte * seed^n-1) % mod)) * seed) % mod) + nextByte) % mod;
+// This function will return `updatedHash` once complete.
+func (r RabinKarp) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	// Hash initialByte -> initialByte * seed^n-1
+	hashedInitialByte := mulMod(int64(initialByte), powMod(r.Seed, chunkSize-1, r.Mod), r.Mod)
+	// Mod hashedInitialByte and remove from hash -> hash - (hashedInitialByte % mod)
+	updatedHash := hash - Modulo(hashedInitialByte, r.Mod)
+	// Multiply seed -> result * seed
+	updatedHash = updatedHash * r.Seed
+	// Mod + add new byte -> result % mod + int64(nextByte)
+	updatedHash = Modulo(updatedHash, r.Mod) + int64(nextByte)
+	// Mod output to get final updated hash -> result % mod
+	return Modulo(updatedHash, r.Mod)
+}
+
+// Sum() will hash a buffer by calling SumFunc.
+func (f Funcs) Sum(buffer []byte, chunkSize int64) int64 {
+	return f.SumFunc(buffer, chunkSize)
+}
+
+// Roll() will roll a hash by calling RollFunc.
+func (f Funcs) Roll(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
+	return f.RollFunc(hash, initialByte, nextByte, chunkSize)
+}
+
+// mulMod() will multiply 2 (non-negative) numbers, applying the mod to the 128 bit product so large chunk sizes cannot overflow int64.
+// Function returns `result` -> EG (x * y) % mod;
+func mulMod(x int64, y int64, mod int64) int64 {
+	high, low := bits.Mul64(uint64(x), uint64(y))
+	return int64(bits.Rem64(high, low, uint64(mod)))
+}
+
+// powMod() will raise a (non-negative) base to the provided exponent by repeated squaring, applying the mod at each step.
+// Function returns `result` -> EG base^exponent % mod;
+func powMod(base int64, exponent int64, mod int64) int64 {
+	result := int64(1) % mod
+	base = base % mod
+	for ; exponent > 0; exponent >>= 1 {
+		if exponent&1 == 1 {
+			result = mulMod(result, base, mod)
+		}
+
+		base = mulMod(base, base, mod)
+	}
+
+	return result
+}
+
+// Modulo() will run a mod operation on 2 numbers and return the result.
+// math/big is used over the built-in mod operator as `%` does not implement Euclidean modulus.
+// Function returns `result` -> EG x % y;
+func Modulo(x int64, y int64) int64 {
+	return new(big.Int).Mod(big.NewInt(x), big.NewInt(y)).Int64()
+}
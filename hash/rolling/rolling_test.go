@@ -0,0 +1,146 @@
+package rolling
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testChunk             int64 = 16
+	testBuffer                  = []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p'}
+	testBufferHash              = int64(76935130210)
+	testBufferNextChar    byte  = 'q'
+	testBufferUpdatedHash       = int64(49921073876)
+	rabinKarp                   = NewRabinKarp()
+)
+
+func TestRabinKarpSum(t *testing.T) {
+	t.Run("should return a consistent `resultHash` after hashing the provided buffer", func(t *testing.T) {
+		// Run
+		resultHash := rabinKarp.Sum(testBuffer, testChunk)
+		// Verify
+		require.Equal(t, testBufferHash, resultHash)
+	})
+
+	t.Run("should generate a different `resultHash` for different buffers", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'f', 'b', 'c', 'e', 'e', 'f', 'g', 4, 'i', 2, 'k', 'l', 'm', '£', 'o', 'p'}
+		// Run
+		resultHash := rabinKarp.Sum(testBuffer, testChunk)
+		differentHash := rabinKarp.Sum(buffer, testChunk)
+		// Verify
+		require.Equal(t, testBufferHash, resultHash)
+		require.NotEqual(t, differentHash, resultHash)
+	})
+
+	t.Run("should generate a different `resultHash` for hashes which have been reversed (EG byte order important)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'p', 'o', 'n', 'm', 'l', 'k', 'j', 'i', 'h', 'g', 'f', 'e', 'd', 'c', 'b', 'a'}
+		anotherBuffer := []byte{'b', 'a', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p'}
+		// Run
+		resultHash := rabinKarp.Sum(testBuffer, testChunk)
+		differentHash := rabinKarp.Sum(buffer, testChunk)
+		anotherHash := rabinKarp.Sum(anotherBuffer, testChunk)
+		// Verify
+		require.Equal(t, testBufferHash, resultHash)
+		require.NotEqual(t, differentHash, resultHash)
+		require.NotEqual(t, differentHash, anotherHash)
+		require.NotEqual(t, anotherHash, resultHash)
+	})
+
+	t.Run("should generate a valid `resultHash` when using max byte size (255)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}
+		expectedHash := int64(11415635451)
+		// Run
+		resultHash := rabinKarp.Sum(buffer, testChunk)
+		// Verify
+		require.Equal(t, expectedHash, resultHash)
+	})
+}
+
+func TestModulo(t *testing.T) {
+	t.Run("should return `result` when calculated the remainder between 2 values (mod)", func(t *testing.T) {
+		// Setup
+		x := int64(10)
+		y := int64(4)
+		expectedResult := int64(2)
+		// Run
+		result := Modulo(x, y)
+		// Verify
+		require.Equal(t, expectedResult, result)
+	})
+
+	t.Run("should implement Euclidean modulus, which differs from Go's mod operator", func(t *testing.T) {
+		// Setup
+		x := int64(-10)
+		y := int64(4)
+		expectedResult := int64(2)
+		goModResult := x % y
+		// Run
+		result := Modulo(x, y)
+		// Verify
+		require.Equal(t, expectedResult, result)
+		require.NotEqual(t, goModResult, result)
+	})
+}
+
+func TestRabinKarpRoll(t *testing.T) {
+	t.Run("should return a consistent `updatedHash` after rolling hash to next position", func(t *testing.T) {
+		// Run
+		result := rabinKarp.Roll(testBufferHash, testBuffer[0], testBufferNextChar, testChunk)
+		// Verify
+		require.NotEqual(t, testBufferHash, result)
+		require.Equal(t, testBufferUpdatedHash, result)
+	})
+
+	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+		// Setup
+		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		// Run
+		result := rabinKarp.Roll(testBufferHash, testBuffer[0], testBufferNextChar, testChunk)
+		expectedResult := rabinKarp.Sum(buffer, testChunk)
+		// Verify
+		require.NotEqual(t, testBufferHash, result)
+		require.Equal(t, testBufferUpdatedHash, result)
+		require.Equal(t, expectedResult, result)
+	})
+
+	t.Run("should roll to correct hash when using max chunk size (eg no overflow)", func(t *testing.T) {
+		// Setup
+		buffer := make([]byte, MaxChunkSize+1)
+		for index := range buffer {
+			buffer[index] = byte(255 - index%7)
+		}
+
+		hash := rabinKarp.Sum(buffer[:MaxChunkSize], MaxChunkSize)
+		// Run
+		result := rabinKarp.Roll(hash, buffer[0], buffer[MaxChunkSize], MaxChunkSize)
+		// Verify
+		require.Equal(t, rabinKarp.Sum(buffer[1:], MaxChunkSize), result)
+	})
+}
+
+func TestFuncs(t *testing.T) {
+	t.Run("should satisfy `Hash` interface by calling the provided functions", func(t *testing.T) {
+		// Setup
+		var hash Hash = Funcs{SumFunc: rabinKarp.Sum, RollFunc: rabinKarp.Roll}
+		// Run + Verify
+		require.Equal(t, testBufferHash, hash.Sum(testBuffer, testChunk))
+		require.Equal(t, testBufferUpdatedHash, hash.Roll(testBufferHash, testBuffer[0], testBufferNextChar, testChunk))
+	})
+}
+
+func BenchmarkRabinKarpSum(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rabinKarp.Sum(testBuffer, testChunk)
+	}
+}
+
+func BenchmarkRabinKarpRoll(b *testing.B) {
+	hash := testBufferHash
+	for i := 0; i < b.N; i++ {
+		hash = rabinKarp.Roll(hash, byte(i), byte(int64(i)+testChunk), testChunk)
+	}
+}
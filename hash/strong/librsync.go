@@ -0,0 +1,65 @@
+package strong
+
+import (
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/md4"
+)
+
+const (
+	BLAKE2bName string = "blake2b" // BLAKE2b-256 Strong hash (EG rdiff Signatures)
+	MD4Name     string = "md4"     // MD4 Strong hash, only for reading + writing older rdiff Signatures (not collision resistant)
+)
+
+// blake2bHash type.
+// This will hash blocks with BLAKE2b-256 (EG the Strong hash of rdiff Signatures generated by librsync 1.0+).
+// blake2bHash will satisfy the `Hash` interface.
+type blake2bHash struct{}
+
+// md4Hash type.
+// This will hash blocks with MD4 (EG the Strong hash of rdiff Signatures generated by librsync before 1.0).
+// Note: MD4 is not collision resistant, so should only be selected to exchange Signatures with older rdiff deployments.
+// md4Hash will satisfy the `Hash` interface.
+type md4Hash struct{}
+
+// Sum() will hash a block with BLAKE2b-256.
+func (blake2bHash) Sum(block []byte) []byte {
+	sum := blake2b.Sum256(block)
+	return sum[:]
+}
+
+// Size() will return the size of a BLAKE2b-256 hash (32 bytes).
+func (blake2bHash) Size() int {
+	return blake2b.Size256
+}
+
+// Name() will return the registered name of BLAKE2b-256.
+func (blake2bHash) Name() string {
+	return BLAKE2bName
+}
+
+// Sum() will hash a block with MD4.
+func (md4Hash) Sum(block []byte) []byte {
+	hash := md4.New()
+	hash.Write(block)
+	return hash.Sum(nil)
+}
+
+// Size() will return the size of an MD4 hash (16 bytes).
+func (md4Hash) Size() int {
+	return md4.Size
+}
+
+// Name() will return the registered name of MD4.
+func (md4Hash) Name() string {
+	return MD4Name
+}
+
+// BLAKE2b() will return the BLAKE2b-256 Strong hash algorithm.
+func BLAKE2b() Hash {
+	return blake2bHash{}
+}
+
+// MD4() will return the MD4 Strong hash algorithm.
+func MD4() Hash {
+	return md4Hash{}
+}
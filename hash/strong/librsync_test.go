@@ -0,0 +1,34 @@
+package strong
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBLAKE2b(t *testing.T) {
+	t.Run("should return BLAKE2b-256 `hash` of provided block", func(t *testing.T) {
+		// Setup
+		hash := BLAKE2b()
+		// Run
+		sum := hash.Sum([]byte("abc"))
+		// Verify
+		require.Equal(t, "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319", hex.EncodeToString(sum))
+		require.Equal(t, 32, hash.Size())
+		require.Equal(t, BLAKE2bName, hash.Name())
+	})
+}
+
+func TestMD4(t *testing.T) {
+	t.Run("should return MD4 `hash` of provided block", func(t *testing.T) {
+		// Setup
+		hash := MD4()
+		// Run
+		sum := hash.Sum([]byte("abc"))
+		// Verify
+		require.Equal(t, "a448017aaf21d8525fc10ae87aa6729d", hex.EncodeToString(sum))
+		require.Equal(t, 16, hash.Size())
+		require.Equal(t, MD4Name, hash.Name())
+	})
+}
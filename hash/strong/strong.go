@@ -0,0 +1,92 @@
+// Package strong provides Strong (collision resistant) hash algorithms, used to confirm blocks matched by a rolling Weak hash (see `hash/rolling`).
+// Algorithms are held in a registry, so additional algorithms (EG SM3, GOST) can be added without patching core code.
+package strong
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+const SHA256Name string = "sha256" // SHA-256 Strong hash
+
+// Hash interface.
+// Sum will hash a block of data, returning the raw hash bytes.
+// Size will return the number of bytes returned by Sum.
+// Name will return the name the algorithm is registered with (EG "sha256").
+type Hash interface {
+	Sum(block []byte) []byte
+	Size() int
+	Name() string
+}
+
+// sha256Hash type.
+// This will hash blocks with SHA-256.
+// sha256Hash will satisfy the `Hash` interface.
+type sha256Hash struct{}
+
+var registry = map[string]Hash{SHA256Name: sha256Hash{}, BLAKE2bName: blake2bHash{}, MD4Name: md4Hash{}}
+
+// Sum() will hash a block with SHA-256.
+func (sha256Hash) Sum(block []byte) []byte {
+	sum := sha256.Sum256(block)
+	return sum[:]
+}
+
+// Size() will return the size of a SHA-256 hash (32 bytes).
+func (sha256Hash) Size() int {
+	return sha256.Size
+}
+
+// Name() will return the registered name of SHA-256.
+func (sha256Hash) Name() string {
+	return SHA256Name
+}
+
+// Lookup() will return the registered Strong hash algorithm with the provided name.
+// Function returns `hash, nil` when successful.
+// Function returns `nil, HashNotRegisteredError` when no algorithm has been registered with the provided name.
+func Lookup(name string) (Hash, error) {
+	hash, exists := registry[name]
+	if !exists {
+		return nil, errors.New(constants.HashNotRegisteredError)
+	}
+
+	return hash, nil
+}
+
+// Names() will return the sorted names of all registered Strong hash algorithms.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Register() will register an additional Strong hash algorithm, using the name returned by hash.Name().
+// Note: registration is not safe for concurrent use, so should happen during `init()` (EG in a file behind a build tag).
+// Function returns `nil` when successful.
+// Function returns `InvalidHashError` when hash is nil, or its name is empty.
+// Function returns `HashAlreadyRegisteredError` when an algorithm is already registered with the same name.
+func Register(hash Hash) error {
+	if hash == nil || hash.Name() == "" {
+		return errors.New(constants.InvalidHashError)
+	}
+
+	if _, exists := registry[hash.Name()]; exists {
+		return errors.New(constants.HashAlreadyRegisteredError)
+	}
+
+	registry[hash.Name()] = hash
+	return nil
+}
+
+// SHA256() will return the SHA-256 Strong hash algorithm.
+func SHA256() Hash {
+	return sha256Hash{}
+}
@@ -0,0 +1,99 @@
+package strong
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+var testBlock = []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p'}
+
+// hashMock type.
+// This will return the block reversed as its hash, registered with the provided name.
+type hashMock struct {
+	name string
+}
+
+func (h hashMock) Sum(block []byte) []byte {
+	sum := make([]byte, len(block))
+	for index := range block {
+		sum[len(block)-1-index] = block[index]
+	}
+
+	return sum
+}
+
+func (h hashMock) Size() int {
+	return len(testBlock)
+}
+
+func (h hashMock) Name() string {
+	return h.name
+}
+
+func TestSHA256(t *testing.T) {
+	t.Run("should return SHA-256 `hash` of provided block", func(t *testing.T) {
+		// Setup
+		hash := SHA256()
+		// Run
+		sum := hash.Sum(testBlock)
+		// Verify
+		require.Equal(t, "f39dac6cbaba535e2c207cd0cd8f154974223c848f727f98b3564cea569b41cf", hex.EncodeToString(sum))
+		require.Equal(t, 32, hash.Size())
+		require.Equal(t, SHA256Name, hash.Name())
+	})
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("should return `hash, nil` when algorithm registered", func(t *testing.T) {
+		// Run
+		hash, err := Lookup(SHA256Name)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, SHA256(), hash)
+	})
+
+	t.Run("should return `nil, HashNotRegisteredError` when algorithm not registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		hash, err := Lookup("unknown")
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, nil, hash)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("should register Strong hash when name not already registered", func(t *testing.T) {
+		// Setup
+		hash := hashMock{name: "test-strong"}
+		// Run
+		err := Register(hash)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []string{BLAKE2bName, MD4Name, SHA256Name, "test-strong"}, Names())
+		registered, _ := Lookup("test-strong")
+		require.Equal(t, []byte("ponmlkjihgfedcba"), registered.Sum(testBlock))
+	})
+
+	t.Run("should return `HashAlreadyRegisteredError` when name already registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashAlreadyRegisteredError)
+		// Run
+		err := Register(hashMock{name: SHA256Name})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `InvalidHashError` when hash is nil or name is empty", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidHashError)
+		// Run + Verify
+		require.Equal(t, expectedError, Register(nil))
+		require.Equal(t, expectedError, Register(hashMock{}))
+	})
+}
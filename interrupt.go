@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/curtismenmuir/go-file-diff/files"
+)
+
+const exitInterrupted int = 130 // 128 + SIGINT
+
+var (
+	notifySignals   = signal.Notify
+	cleanupPartials = files.CleanupPartials
+)
+
+// handleInterrupt() will listen for interrupt (EG Ctrl+C) + terminate signals in the background.
+// When received, partial outputs still being written will be discarded (or kept when `-keepPartial` is set) before exiting.
+func handleInterrupt() {
+	signals := make(chan os.Signal, 1)
+	notifySignals(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cleanupPartials()
+		exit(exitInterrupted)
+	}()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInterrupt(t *testing.T) {
+	t.Run("should clean up partial outputs + exit when interrupted", func(t *testing.T) {
+		// Setup
+		signals := make(chan chan<- os.Signal, 1)
+		exited := make(chan int, 1)
+		cleaned := false
+		// Mock
+		notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+			signals <- c
+		}
+
+		cleanupPartials = func() {
+			cleaned = true
+		}
+
+		exit = func(code int) {
+			exited <- code
+		}
+
+		// Run
+		handleInterrupt()
+		(<-signals) <- os.Interrupt
+		// Verify
+		require.Equal(t, exitInterrupted, <-exited)
+		require.Equal(t, true, cleaned)
+		// Avoid later tests registering real signal handlers via main()
+		notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {}
+	})
+}
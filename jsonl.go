@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+const deltaStdout string = "-" // Stream JSON Lines Delta to stdout
+
+var (
+	createOutputFile           = files.CreateOutputFile
+	finishPartial              = files.FinishPartial
+	stdout           io.Writer = os.Stdout
+)
+
+// deltaStream interface for Delta writers used by streamDelta(), which count matched + literal bytes as blocks are written.
+type deltaStream interface {
+	sync.DeltaWriter
+	finish(metadata models.DeltaMetadata) (transferCounter, error)
+}
+
+// gobStreamWriter type.
+// This will gob encode each Delta block to a streamed Delta file (see files.DeltaStreamWriter), counting matched + literal bytes as they are written.
+// The first error returned by the encoder will be recorded.
+// gobStreamWriter will satisfy the `sync.DeltaWriter` interface.
+type gobStreamWriter struct {
+	writer  *files.DeltaStreamWriter
+	counter transferCounter
+	err     error
+}
+
+// WriteBlock() will encode a Delta block to the streamed Delta file.
+func (w *gobStreamWriter) WriteBlock(position int64, block models.Block) error {
+	if w.err = w.writer.WriteBlock(position, block); w.err != nil {
+		return w.err
+	}
+
+	return w.counter.WriteBlock(position, block)
+}
+
+// finish() will write the metadata + checksum trailer of the streamed Delta file, returning the counted blocks.
+// Function returns `counter, nil` when successful.
+// Function returns `counter, error` when a block, the metadata or the trailer could not be written.
+func (w *gobStreamWriter) finish(metadata models.DeltaMetadata) (transferCounter, error) {
+	if w.err != nil {
+		return w.counter, w.err
+	}
+
+	w.writer.SetMetadata(metadata)
+	return w.counter, w.writer.Close()
+}
+
+// jsonlWriter type.
+// This will encode each Delta block as a JSON object on its own line (see `models.Op`), counting matched + literal bytes as they are written.
+// The first error returned by the encoder will be recorded.
+// jsonlWriter will satisfy the `sync.DeltaWriter` interface.
+type jsonlWriter struct {
+	encoder *json.Encoder
+	counter transferCounter
+	err     error
+}
+
+// WriteBlock() will encode a Delta block as a JSON Lines operation.
+func (w *jsonlWriter) WriteBlock(position int64, block models.Block) error {
+	if w.err = w.encoder.Encode(block.Op(position)); w.err != nil {
+		return w.err
+	}
+
+	return w.counter.WriteBlock(position, block)
+}
+
+// finish() will return the counted blocks, along with the first error returned by the encoder.
+// Note: metadata will not be recorded, as JSON Lines only contain Delta operations.
+func (w *jsonlWriter) finish(metadata models.DeltaMetadata) (transferCounter, error) {
+	return w.counter, w.err
+}
+
+// closeDeltaFile() will close a streamed Delta file, then rename it into place (or discard it when failed is set).
+// Note: nothing will be done when streaming to stdout (EG file is nil).
+// Function returns `nil` when successful.
+// Function returns `UnableToWriteToDeltaFileError` when unable to rename the Delta file into place.
+func closeDeltaFile(cmd models.CMD, file *os.File, failed bool) error {
+	if file == nil {
+		return nil
+	}
+
+	file.Close()
+	if err := finishPartial(outputPath(cmd.DeltaFile), failed); err != nil {
+		return errors.New(constants.UnableToWriteToDeltaFileError)
+	}
+
+	return nil
+}
+
+// isStreamingToStdout() will check if CMD flags request the JSON Lines Delta to be written to stdout.
+// Note: informational output should be suppressed in this case, so stdout only contains Delta operations.
+func isStreamingToStdout(cmd models.CMD) bool {
+	return cmd.DeltaMode && cmd.DeltaFormat == constants.DeltaFormatJSONL && cmd.DeltaFile == deltaStdout
+}
+
+// newDeltaStream() will create the Delta writer used by streamDelta() for the format selected by CMD flags.
+// JSON Lines writers will be returned for `-format=jsonl`, otherwise gob writers of streamed Delta files (see `-streamDelta`).
+func newDeltaStream(cmd models.CMD, writer io.Writer) deltaStream {
+	if cmd.DeltaFormat == constants.DeltaFormatJSONL {
+		return &jsonlWriter{encoder: json.NewEncoder(writer)}
+	}
+
+	return &gobStreamWriter{writer: files.NewDeltaStreamWriter(writer)}
+}
+
+// streamDelta() will generate a Delta as JSON Lines (or a streamed gob Delta file with `-streamDelta`), writing each block as it is finalised.
+// This allows downstream tools (EG jq or a custom applier) to consume the Delta without waiting for the full run, and avoids holding the full Delta in memory.
+// Delta will be written to stdout when the Delta file is `-` (JSON Lines only), otherwise to the Delta file in the Outputs folder.
+// Note: operations already written to stdout will remain when Delta generation fails or no changes are found.
+// Note: the SHA-256 hash of the Updated file is recorded once every block has been written (streamed gob Delta files only, see models.DeltaMetadata).
+// Note: streamed gob Delta files will be discarded when no changes are found, matching Delta files which are not streamed.
+// Note: Delta file will be written as a `.partial` file, which is renamed into place once complete (or discarded on failure unless `-keepPartial` is set).
+// Function returns `counter, nil` when successful.
+// Function returns `counter, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
+// Function returns `emptyCounter, OverwriteDeclinedError` when user declines overwriting an existing Delta file.
+// Function returns `emptyCounter, UpdatedFileDoesNotExistError` when unable to find Updated file.
+// Function returns `emptyCounter, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `emptyCounter, UnableToCreateDeltaFileError` when unable to create Delta file.
+// Function returns `emptyCounter, UnableToWriteToDeltaFileError` when unable to write to Delta file.
+// Function returns `emptyCounter, UnableToGenerateDeltaError` when unable to generate Delta.
+func streamDelta(cmd models.CMD, signature models.Signature) (transferCounter, error) {
+	var file *os.File
+	out := stdout
+	toStdout := isStreamingToStdout(cmd)
+	if !toStdout {
+		// Confirm overwrite of existing Delta file
+		if err := confirmOverwrite(cmd, cmd.DeltaFile); err != nil {
+			return transferCounter{}, err
+		}
+	}
+
+	// Create FileReader for Updated file
+	reader, err := openUpdated(cmd.UpdatedFile)
+	if err != nil {
+		return transferCounter{}, err
+	}
+
+	if !toStdout {
+		file, err = createOutputFile(cmd.DeltaFile)
+		if err != nil {
+			// Replace generic `UnableToCreateFileError` error with specific Delta File error
+			if err.Error() == constants.UnableToCreateFileError {
+				return transferCounter{}, errors.New(constants.UnableToCreateDeltaFileError)
+			}
+
+			return transferCounter{}, err
+		}
+
+		out = file
+	}
+
+	// Stream Delta, hashing the Updated file as it is read so Patch mode can verify its output
+	buffered := bufio.NewWriter(out)
+	writer := newDeltaStream(cmd, buffered)
+	updatedHash := sha256.New()
+	reader, progress := trackProgress(cmd, reader, "Delta", cmd.UpdatedFile)
+	err = generateDeltaTo(bufio.NewReader(io.TeeReader(reader, updatedHash)), signature, writer, sync.Hooks{}, cmd.Verbose)
+	progress.Finish()
+	counter, writeErr := writer.finish(models.DeltaMetadata{UpdatedHash: hex.EncodeToString(updatedHash.Sum(nil))})
+	writeFailed := writeErr != nil || buffered.Flush() != nil
+	noChanges := err != nil && err.Error() == constants.UpdatedFileHasNoChangesError
+	generateFailed := err != nil && (!noChanges || cmd.DeltaFormat != constants.DeltaFormatJSONL)
+	if closeErr := closeDeltaFile(cmd, file, writeFailed || generateFailed); closeErr != nil {
+		writeFailed = true
+	}
+
+	if writeFailed {
+		return transferCounter{}, errors.New(constants.UnableToWriteToDeltaFileError)
+	}
+
+	if err != nil {
+		// Return err when no changes detected in Updated file
+		if noChanges {
+			return counter, err
+		}
+
+		// Return generic unable to generate Delta error
+		return transferCounter{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	return counter, nil
+}
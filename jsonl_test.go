@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for io.Writer interface which always fails
+type failingWriterMock struct{}
+
+// Overwrite failingWriterMock.Write() to return an error
+func (w failingWriterMock) Write(p []byte) (int, error) {
+	return 0, errors.New(errorMessage)
+}
+
+func TestJSONLWriterWriteBlock(t *testing.T) {
+	t.Run("should encode each block as a JSON Lines operation + count bytes", func(t *testing.T) {
+		// Setup
+		var out bytes.Buffer
+		writer := &jsonlWriter{encoder: json.NewEncoder(&out)}
+		expectedOutput := `{"kind":"literal","position":0,"value":"bmV3"}` + "\n" + `{"kind":"copy","position":3,"head":16,"tail":31}` + "\n"
+		// Run
+		require.Equal(t, nil, writer.WriteBlock(0, models.Block{IsModified: true, Value: []byte("new")}))
+		require.Equal(t, nil, writer.WriteBlock(3, models.Block{Head: 16, Tail: 31}))
+		// Verify
+		require.Equal(t, expectedOutput, out.String())
+		require.Equal(t, transferCounter{matchedBlocks: 1, literalBlocks: 1, matchedBytes: 16, literalBytes: 3}, writer.counter)
+	})
+
+	t.Run("should record + return error when unable to encode block", func(t *testing.T) {
+		// Setup
+		writer := &jsonlWriter{encoder: json.NewEncoder(failingWriterMock{})}
+		// Run
+		err := writer.WriteBlock(0, models.Block{Head: 0, Tail: 15})
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Equal(t, err, writer.err)
+		require.Equal(t, transferCounter{}, writer.counter)
+	})
+}
+
+func TestIsStreamingToStdout(t *testing.T) {
+	t.Run("should return true when JSON Lines Delta written to `-`", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, DeltaFile: deltaStdout}
+		// Run + Verify
+		require.Equal(t, true, isStreamingToStdout(cmd))
+	})
+
+	t.Run("should return false when gob Delta written to `-`", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatGob, DeltaFile: deltaStdout}
+		// Run + Verify
+		require.Equal(t, false, isStreamingToStdout(cmd))
+	})
+
+	t.Run("should return false when JSON Lines Delta written to file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, DeltaFile: file}
+		// Run + Verify
+		require.Equal(t, false, isStreamingToStdout(cmd))
+	})
+}
+
+func TestStreamDelta(t *testing.T) {
+	t.Run("should return `counter, nil` after streaming operations to stdout", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: deltaStdout}
+		var out bytes.Buffer
+		// Mock
+		stdout = &out
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return writer.WriteBlock(0, models.Block{IsModified: true, Value: []byte("new")})
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, transferCounter{literalBlocks: 1, literalBytes: 3}, counter)
+		require.Equal(t, `{"kind":"literal","position":0,"value":"bmV3"}`+"\n", out.String())
+	})
+
+	t.Run("should return `counter, nil` after streaming operations to Delta file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "delta-*.jsonl")
+		require.Equal(t, nil, err)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, nil
+		}
+
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finished := false
+		finishPartial = func(path string, failed bool) error {
+			finished = !failed
+			return nil
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		require.Equal(t, transferCounter{literalBlocks: 1, literalBytes: 3}, counter)
+		contents, _ := os.ReadFile(output.Name())
+		require.Equal(t, `{"kind":"literal","position":0,"value":"bmV3"}`+"\n", string(contents))
+	})
+
+	t.Run("should return `counter, nil` after streaming a gob Delta file when `-streamDelta` is set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, StreamDelta: true, SignatureFile: file, UpdatedFile: file, DeltaFile: deltaStdout}
+		output, err := os.CreateTemp(t.TempDir(), "delta-*")
+		require.Equal(t, nil, err)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finished := false
+		finishPartial = func(path string, failed bool) error {
+			finished = !failed
+			return nil
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		require.Equal(t, transferCounter{literalBlocks: 1, literalBytes: 3}, counter)
+		delta, err := files.OpenDelta(output.Name(), false)
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Delta{0: models.Block{IsModified: true, Value: []byte("new")}}, delta)
+	})
+
+	t.Run("should discard streamed gob Delta file when Updated file has no changes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, StreamDelta: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "delta-*")
+		require.Equal(t, nil, err)
+		discarded := false
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finishPartial = func(path string, failed bool) error {
+			discarded = failed
+			return nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			require.Equal(t, nil, writer.WriteBlock(0, models.Block{Head: 0, Tail: 15}))
+			return expectedError
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{matchedBlocks: 1, matchedBytes: 16}, counter)
+		require.Equal(t, true, discarded)
+	})
+
+	t.Run("should return `counter, UpdatedFileHasNoChangesError` when Updated file has no changes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: deltaStdout}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		stdout = &bytes.Buffer{}
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			require.Equal(t, nil, writer.WriteBlock(0, models.Block{Head: 0, Tail: 15}))
+			return expectedError
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{matchedBlocks: 1, matchedBytes: 16}, counter)
+	})
+
+	t.Run("should return `emptyCounter, UnableToGenerateDeltaError` when Delta generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: deltaStdout}
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+
+	t.Run("should return `emptyCounter, UnableToWriteToDeltaFileError` when unable to write operations", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: deltaStdout}
+		expectedError := errors.New(constants.UnableToWriteToDeltaFileError)
+		// Mock
+		stdout = failingWriterMock{}
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return writer.WriteBlock(0, models.Block{IsModified: true, Value: []byte("new")})
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+
+	t.Run("should return `emptyCounter, UnableToCreateDeltaFileError` when unable to create Delta file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		expectedError := errors.New(constants.UnableToCreateDeltaFileError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return nil, errors.New(constants.UnableToCreateFileError)
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+		stdout = os.Stdout
+	})
+
+	t.Run("should discard partial Delta file when Delta generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, DeltaFormat: constants.DeltaFormatJSONL, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "delta-*.jsonl")
+		require.Equal(t, nil, err)
+		discarded := false
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finishPartial = func(path string, failed bool) error {
+			discarded = failed
+			return nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		counter, err := streamDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+		require.Equal(t, true, discarded)
+	})
+}
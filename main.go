@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
 
 	"github.com/curtismenmuir/go-file-diff/cmd"
 	"github.com/curtismenmuir/go-file-diff/constants"
@@ -12,51 +15,192 @@ import (
 )
 
 var (
-	logger            = utils.Logger
-	parseCMD          = cmd.ParseCMD
-	verifyCMD         = cmd.VerifyCMD
-	openFile          = files.OpenFile
-	writeStructToFile = files.WriteStructToFile
-	generateSignature = sync.GenerateSignature
-	openSignature     = files.OpenSignature
-	generateDelta     = sync.GenerateDelta
+	logger               = utils.Logger
+	errorLogger          = utils.ErrorLogger
+	parseCMD             = cmd.ParseCMD
+	verifyCMD            = cmd.VerifyCMD
+	openFile             = files.OpenFile
+	writeStructToFile    = files.WriteStructToFile
+	writeDeltaToFile     = files.WriteDeltaToFile
+	generateSignature    = sync.GenerateSignature
+	openSignature        = files.OpenSignature
+	openSignatureMeta    = files.OpenSignatureMetadata
+	writeSignatureToFile = files.WriteSignatureToFile
+	generateDelta        = sync.GenerateDelta
+	applyDelta           = sync.Apply
+	applyDeltaStrict     = sync.ApplyStrict
+	applyDeltaWithReport = sync.ApplyWithReport
+	applyDeltaWithAudit  = sync.ApplyWithAudit
+	applyOps             = sync.ApplyOps
+	openDeltaScanner     = files.OpenDeltaScanner
+	isDeltaStream        = files.IsDeltaStream
+	generateDeltaTo      = sync.GenerateDeltaTo
+	readAll              = io.ReadAll
+	outputFileExists     = files.OutputFileExists
+	confirm              = utils.Confirm
+	setColor             = utils.SetColor
+	setQuiet             = utils.SetQuiet
+	setLogSampling       = utils.SetLogSampling
+	fileSize             = files.FileSize
+	outputPath           = files.OutputPath
+	deltaStats           = sync.Stats
+	useHashes            = sync.UseHashes
+	useLegacyHash        = sync.UseLegacyHash
+	setPermissions       = files.SetPermissions
+	setKeepPartial       = files.SetKeepPartial
+	setReadRetries       = sync.SetReadRetries
+	setChaos             = files.SetChaos
+	setCompression       = files.SetCompression
+	setChunkSize         = sync.SetChunkSize
+	setSignatureWorkers  = sync.SetSignatureWorkers
+	useSignatureMetadata = sync.UseSignatureMetadata
 )
 
+// confirmOverwrite() will ask the user to confirm overwriting an output file when it already exists.
+// Prompt will be skipped when user has set the `-yes` flag.
+// Function returns `nil` when output file does not exist, or user confirms overwrite.
+// Function returns `OverwriteDeclinedError` when user declines overwrite.
+// Function returns `error` when unable to check existence of output file.
+func confirmOverwrite(cmd models.CMD, fileName string) error {
+	if cmd.Yes {
+		return nil
+	}
+
+	exists, err := outputFileExists(fileName)
+	if err != nil {
+		return err
+	} else if !exists {
+		return nil
+	}
+
+	if !confirm(fmt.Sprintf("Output file `%s` already exists. Overwrite?", fileName)) {
+		return errors.New(constants.OverwriteDeclinedError)
+	}
+
+	return nil
+}
+
+// generateUpdatedDelta() will generate a Delta of the Updated file (or the region of Updated file provided by `-range`) using the provided Signature.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileDoesNotExistError` when unable to find Updated file.
+// Function returns `emptyDelta, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
+// Function returns `emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta.
+// Function returns `emptyDelta, error` when range is invalid, or outside the Original + Updated files.
+func generateUpdatedDelta(cmd models.CMD, signature models.Signature) (models.Delta, error) {
+	// Create FileReader for Updated file (or the region of Updated file provided by `-range`)
+	var reader *bufio.Reader
+	var err error
+	if cmd.Range != "" {
+		reader, err = openUpdatedRange(cmd)
+	} else {
+		reader, err = openUpdated(cmd.UpdatedFile)
+	}
+
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	// Generate Delta
+	reader, progress := trackProgress(cmd, reader, "Delta", cmd.UpdatedFile)
+	var delta models.Delta
+	if cmd.MinSimilarity > 0 {
+		delta, err = generateSimilarDelta(cmd, reader, signature)
+	} else {
+		delta, err = generateDelta(reader, signature, cmd.Verbose)
+	}
+
+	progress.Finish()
+	if err != nil {
+		// Return err when Updated file cannot be read for a full copy
+		if err.Error() == constants.UnableToReadUpdatedFileError {
+			return models.Delta{}, err
+		}
+
+		// Return err when no changes detected in Updated file
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return models.Delta{}, err
+		}
+
+		// Return generic unable to generate Delta error
+		return models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	// Copy bytes outside of the range from the Original file
+	if cmd.Range != "" {
+		return expandRange(cmd, signature, delta)
+	}
+
+	return delta, nil
+}
+
 // getSignature() will generate a Signature of a specified file and write the Signature output to a file.
 // Function returns `Signature, nil` when successful.
+// Function returns `EmptySignature, OverwriteDeclinedError` when user declines overwriting an existing Signature file.
 // Function returns `EmptySignature, OriginalFileNotExistError` when Original file cannot be found.
 // Function returns `EmptySignature, OriginalFileIsFolderError` when found a folder dir instead of Original file.
 // Function returns `EmptySignature, UnableToGenerateSignatureError` when unable to generate file Signature.
 // Function returns `EmptySignature, UnableToWriteToSignatureFileError` when unable to write Signature to output file.
+// Note: when `-sparse` is set, only every Nth window of the Original file will be hashed (see sync.GenerateSparseSignature()).
+// Note: when `-format=rdiff` is set, only the block at every chunk will be hashed (unless `-sparse` is set), and Signature will be written in the rdiff format (see rdiffModel()).
+// Note: when `-encoding` is set to json, protobuf or cbor, Signature will be written with that encoding (see encodingModel()).
+// Note: when `-streamSignature` is set, Signature entries will be written to the Signature file as they are generated (see streamSignature()).
+// Function returns `EmptySignature, RdiffUnsupportedHashError` when `-format=rdiff` is set and Signature was not generated with an rdiff Weak + Strong hash.
+// Function returns `EmptySignature, RdiffMissingBlockError` when `-format=rdiff` is set and Signature does not contain the block at every chunk (EG pruned Signature).
 func getSignature(cmd models.CMD) (models.Signature, error) {
-	// Create FileReader for Original file
-	reader, err := openFile(cmd.OriginalFile)
+	// Confirm overwrite of existing Signature file
+	err := confirmOverwrite(cmd, cmd.SignatureFile)
 	if err != nil {
-		// Replace generic `file not exist` error with specific Original File error
-		if err.Error() == constants.FileDoesNotExistError {
-			return models.Signature{}, errors.New(constants.OriginalFileDoesNotExistError)
-		}
+		return models.Signature{}, err
+	}
 
-		// Replace generic `file is folder dir` error with specific Original File error
-		if err.Error() == constants.SearchingForFileButFoundDirError {
-			return models.Signature{}, errors.New(constants.OriginalFileIsFolderError)
-		}
+	// Stream Signature entries to the Signature file as they are generated
+	if cmd.StreamSig {
+		return streamSignature(cmd)
+	}
 
+	// Create FileReader for Original file
+	reader, err := openOriginal(cmd.OriginalFile)
+	if err != nil {
 		return models.Signature{}, err
 	}
 
 	// Generate Signature
-	signature, err := generateSignature(reader, cmd.Verbose)
+	reader, progress := trackProgress(cmd, reader, "Signature", cmd.OriginalFile)
+	defer progress.Finish()
+	var signature models.Signature
+	stride := int64(cmd.Sparse)
+	if cmd.DeltaFormat == constants.DeltaFormatRdiff && stride <= 1 {
+		// rdiff Signatures list the block at every chunk of the Original file
+		stride = sync.ChunkSize()
+	}
+
+	if stride > 1 {
+		signature, err = generateSparseSignature(reader, stride, cmd.Verbose)
+	} else {
+		signature, err = generateSignature(reader, cmd.Verbose)
+	}
+
 	if err != nil {
 		return models.Signature{}, errors.New(constants.UnableToGenerateSignatureError)
 	}
 
-	// Write Signature to file
-	err = writeStructToFile(signature, cmd.SignatureFile)
+	signature = pruneSignature(cmd, signature)
+	// Write Signature to file, recording the chunk size used
+	if cmd.DeltaFormat == constants.DeltaFormatRdiff {
+		err = writeStructToFile(rdiffModel(cmd, signature, sync.Metadata()), cmd.SignatureFile)
+	} else if cmd.Encoding != "" && cmd.Encoding != constants.EncodingGob {
+		err = writeStructToFile(encodingModel(cmd, signature, sync.Metadata()), cmd.SignatureFile)
+	} else {
+		err = writeSignatureToFile(signature, sync.Metadata(), cmd.SignatureFile)
+	}
+
 	if err != nil {
 		// Replace generic `UnableToCreateFileError` error with specific Signature File error
 		if err.Error() == constants.UnableToCreateFileError {
 			return models.Signature{}, errors.New(constants.UnableToCreateSignatureFileError)
+		} else if err.Error() == constants.RdiffUnsupportedHashError || err.Error() == constants.RdiffMissingBlockError {
+			return models.Signature{}, err
 		}
 
 		return models.Signature{}, errors.New(constants.UnableToWriteToSignatureFileError)
@@ -65,65 +209,419 @@ func getSignature(cmd models.CMD) (models.Signature, error) {
 	return signature, nil
 }
 
+// openDeltaSignature() will open the Signature file used to generate a Delta, switching to the chunk size + Weak hash recorded in the Signature file (see sync.UseSignatureMetadata()).
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, ChunkSizeMismatchError` when `-chunk` is set and does not match the chunk size recorded in the Signature file.
+// Function returns `emptySignature, WeakHashMismatchError` when `-weakHash` is set and does not match the Weak hash recorded in the Signature file.
+// Function returns `emptySignature, HashNotRegisteredError` when the Weak hash recorded in the Signature file has not been registered.
+// Function returns `emptySignature, error` when unable to open Signature file (see files.OpenSignatureMetadata()).
+func openDeltaSignature(cmd models.CMD) (models.Signature, error) {
+	signature, metadata, err := openSignatureMeta(cmd.SignatureFile, cmd.Verbose)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	if err = useSignatureMetadata(metadata, models.SignatureMetadata{ChunkSize: cmd.Chunk, WeakHash: cmd.WeakHash}); err != nil {
+		return models.Signature{}, err
+	}
+
+	return signature, nil
+}
+
 // getDelta() will attempt to generate a Delta changeset for syncing 2 files.
 // Delta changeset can be applied to the Original file to sync latest updates.
 // Delta generation will use a Signature of the original file to compare against Updated file.
 // Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, OverwriteDeclinedError` when user declines overwriting an existing Delta file.
 // Function returns `emptyDelta, UpdatedFileDoesNotExistError` when unable to find Updated file.
 // Function returns `emptyDelta, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
 // Function returns `emptyDelta, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
 // Function returns `emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta.
+// Note: when `-deltaCache` is set, a cached Delta for the same Original + Updated pair will be reused instead of generating a new Delta.
+// Note: when `-range` is set, Delta will only be generated for the provided region of Updated file (see expandRange()).
+// Note: when `-sparse` is set, matched blocks will be expanded against the Original file when available (see expandSparseDelta()).
+// Note: when `-refine` is set, literal blocks will be re-scanned against the Original file when available (see refineSparseDelta()).
+// Note: when `-fineChunk` is set, literal blocks will be re-scanned with a smaller chunk size against the Original file when available (see refineFineDelta()).
 // Function returns `emptyDelta, UnableToCreateDeltaFileError` when unable to create Delta file.
 // Function returns `emptyDelta, UnableToWriteToDeltaFileError` when unable to write to Delta file.
 func getDelta(cmd models.CMD, signature models.Signature) (models.Delta, error) {
-	// Create FileReader for Updated file
-	reader, err := openFile(cmd.UpdatedFile)
+	// Confirm overwrite of existing Delta file
+	err := confirmOverwrite(cmd, cmd.DeltaFile)
 	if err != nil {
-		// Replace generic `file not exist` error with specific Updated File error
-		if err.Error() == constants.FileDoesNotExistError {
-			return models.Delta{}, errors.New(constants.UpdatedFileDoesNotExistError)
-		}
+		return models.Delta{}, err
+	}
 
-		// Replace generic `file is folder dir` error with specific Updated File error
-		if err.Error() == constants.SearchingForFileButFoundDirError {
-			return models.Delta{}, errors.New(constants.UpdatedFileIsFolderError)
+	// Reuse a previously generated Delta when Delta cache enabled
+	// Note: cache is keyed by the Original + Updated files only, so is skipped when generating a Delta for a range
+	cachePath := ""
+	if cmd.DeltaCache != "" && cmd.Range == "" {
+		delta, path, found := getCachedDelta(cmd)
+		if found {
+			return writeDelta(cmd, delta)
 		}
 
-		return models.Delta{}, err
+		cachePath = path
 	}
 
-	// Generate Delta
-	delta, err := generateDelta(reader, signature, cmd.Verbose)
+	// Skip the rolling scan when Updated file only appends data to, or truncates, the Original file
+	delta, appended, err := prefixDelta(cmd, signature)
 	if err != nil {
-		// Return err when no changes detected in Updated file
-		if err.Error() == constants.UpdatedFileHasNoChangesError {
+		return models.Delta{}, err
+	} else if !appended {
+		delta, err = generateUpdatedDelta(cmd, signature)
+		if err != nil {
 			return models.Delta{}, err
 		}
 
-		// Return generic unable to generate Delta error
-		return models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+		// Recover exact block boundaries around matches of a sparse Signature, then re-scan changed regions
+		delta, err = expandSparseDelta(cmd, signature, delta)
+		if err != nil {
+			return models.Delta{}, err
+		}
+
+		delta, err = refineSparseDelta(cmd, signature, delta)
+		if err != nil {
+			return models.Delta{}, err
+		}
+
+		delta, err = refineFineDelta(cmd, signature, delta)
+		if err != nil {
+			return models.Delta{}, err
+		}
 	}
 
-	// Write Delta to file
-	err = writeStructToFile(delta, cmd.DeltaFile)
+	// Replace Delta with a full copy of the Updated file when it would be larger than the file
+	delta, err = checkDeltaSize(cmd, delta)
 	if err != nil {
-		// Replace generic `UnableToCreateFileError` error with specific Delta File error
-		if err.Error() == constants.UnableToCreateFileError {
-			return models.Delta{}, errors.New(constants.UnableToCreateDeltaFileError)
-		}
+		return models.Delta{}, err
+	}
 
-		return models.Delta{}, errors.New(constants.UnableToWriteToDeltaFileError)
+	// Store Delta for repeat requests
+	if cachePath != "" {
+		cacheDelta(cachePath, delta)
 	}
 
-	return delta, nil
+	return writeDelta(cmd, delta)
+}
+
+// logError() will log an error message in red to stderr.
+// Note: `UpdatedFileHasNoChangesError` will be logged in green to stdout, as an unchanged file is not a failure.
+func logError(err error) {
+	if err.Error() == constants.UpdatedFileHasNoChangesError {
+		logger(utils.Success(err.Error()), true)
+		return
+	}
+
+	errorLogger(utils.Failure(err.Error()))
+}
+
+// openOriginal() will create a FileReader for the Original file.
+// Function returns `reader, nil` when successful.
+// Function returns `nil, OriginalFileDoesNotExistError` when Original file cannot be found.
+// Function returns `nil, OriginalFileIsFolderError` when found a folder dir instead of Original file.
+// Function returns `nil, error` when unable to open Original file.
+func openOriginal(fileName string) (*bufio.Reader, error) {
+	reader, err := openFile(fileName)
+	if err != nil {
+		// Replace generic file errors with specific Original File errors
+		return nil, sync.OriginalFileError(err)
+	}
+
+	return captureReader("original", fileName, reader), nil
+}
+
+// openUpdated() will create a FileReader for the Updated file.
+// Function returns `reader, nil` when successful.
+// Function returns `nil, UpdatedFileDoesNotExistError` when Updated file cannot be found.
+// Function returns `nil, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `nil, error` when unable to open Updated file.
+func openUpdated(fileName string) (*bufio.Reader, error) {
+	reader, err := openFile(fileName)
+	if err != nil {
+		// Replace generic file errors with specific Updated File errors
+		return nil, sync.UpdatedFileError(err)
+	}
+
+	return captureReader("updated", fileName, reader), nil
+}
+
+// readOriginal() will read the full contents of the Original file into memory.
+// Function returns `data, nil` when successful.
+// Function returns `nil, OriginalFileDoesNotExistError` when Original file cannot be found.
+// Function returns `nil, OriginalFileIsFolderError` when found a folder dir instead of Original file.
+// Function returns `nil, UnableToReadOriginalFileError` when unable to read the Original file.
+func readOriginal(fileName string) ([]byte, error) {
+	// Create FileReader for Original file
+	reader, err := openOriginal(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readAll(reader)
+	if err != nil {
+		return nil, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	return data, nil
+}
+
+// reportProgress() will log a progress message as a stat.
+func reportProgress(message string) {
+	logger(utils.Stat(message), true)
+}
+
+// trackProgress() will wrap a FileReader to report progress (rate + ETA) of a phase when `-progress` flag set.
+// Progress total will be the size of the file, obtained when the file is opened.
+// Function returns `reader, progress`, where progress will be `nil` when `-progress` flag not set.
+// Note: calling Finish() on a `nil` progress is a no-op.
+func trackProgress(cmd models.CMD, reader *bufio.Reader, phase string, fileName string) (*bufio.Reader, *utils.Progress) {
+	if !cmd.Progress {
+		return reader, nil
+	}
+
+	// Report progress without percentage + ETA when file size is unknown
+	size, err := fileSize(fileName)
+	if err != nil {
+		size = 0
+	}
+
+	progress := utils.NewProgress(phase, size, reportProgress)
+	return bufio.NewReader(utils.NewProgressReader(reader, progress)), progress
 }
 
 func main() {
 	// Parse CMD flags
 	cmd := parseCMD()
+	if cmd.NoColor {
+		setColor(false)
+	}
+
+	// Keep stdout free for Delta operations when streaming JSON Lines to stdout (or Signature entries in Cat Signature mode)
+	if cmd.Quiet || isStreamingToStdout(cmd) || cmd.CatSignature {
+		setQuiet(true)
+	}
+
+	setLogSampling(cmd.LogEvery, cmd.LogRate)
+	setKeepPartial(cmd.KeepPartial)
+	handleInterrupt()
+	// Lower priority before any worker goroutines start, so threads created for them inherit it
+	if cmd.Background {
+		enterBackground()
+	}
+
+	summary := newSummary(cmd)
+	status := 0
+	var sampler *memSampler
+	if cmd.MemStats {
+		sampler = startMemSampler(&summary)
+	}
+
+	// Record input streams for replay when requested
+	startCapture(cmd)
 	// Verify valid CMD flags provided
 	if !verifyCMD(cmd) {
-		return
+		status = exitInvalidFlags
+	} else if err := useHashes(cmd.StrongHash, cmd.WeakHash); err != nil {
+		// Unknown hash algorithms are treated as invalid CMD flags
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := useLegacyHash(cmd.LegacyHash); err != nil {
+		// Unknown Legacy hash algorithm is treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setPermissions(cmd.DirMode, cmd.FileMode); err != nil {
+		// Invalid permissions are treated as invalid CMD flags
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setReadRetries(cmd.ReadRetries); err != nil {
+		// Negative read retries are treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setChunkSize(cmd.Chunk); err != nil {
+		// Chunk size out of range is treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setSignatureWorkers(cmd.Workers); err != nil {
+		// Negative Signature workers are treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setChaos(cmd.Chaos, cmd.Seed); err != nil {
+		// Chaos rate outside 0-1 is treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := setCompression(cmd.Compress); err != nil {
+		// Unknown compression method is treated as an invalid CMD flag
+		logError(err)
+		status = exitInvalidFlags
+		summary.Error = err.Error()
+	} else if err := run(cmd, &summary); err != nil {
+		logError(err)
+		// Updated file with no changes is not a failure
+		if err.Error() != constants.UpdatedFileHasNoChangesError {
+			status = exitFailure
+			summary.Error = err.Error()
+		}
+	}
+
+	// Write captured input streams when requested (including failed runs)
+	if err := finishCapture(cmd); err != nil {
+		logError(err)
+		if status == 0 {
+			status = exitFailure
+		}
+	}
+
+	// Report peak memory usage when requested
+	reportMemStats(sampler, &summary)
+	// Write run summary when requested
+	summary.ExitStatus = status
+	if cmd.SummaryJSON != "" {
+		if err := writeSummary(summary, cmd.SummaryJSON); err != nil {
+			logError(err)
+			status = exitFailure
+		}
+	}
+
+	if status != 0 {
+		exit(status)
+	}
+}
+
+// run() will run the mode(s) enabled by CMD flags, recording inputs, outputs, and durations in the provided Summary.
+// Function returns `nil` when successful.
+// Function returns `error` when the enabled mode fails.
+func run(cmd models.CMD, summary *models.Summary) error {
+	// Run Doctor mode instead of the mode(s) it checks
+	if cmd.Doctor {
+		return timePhase(summary, "doctor", func() error {
+			_, err := runDoctor(cmd)
+			return err
+		})
+	}
+
+	// Run Benchmark mode in isolation from other modes
+	if cmd.BenchMode {
+		return timePhase(summary, "bench", func() error {
+			_, err := runBenchmark(cmd)
+			return err
+		})
+	}
+
+	// Run Generate mode in isolation from other modes
+	if cmd.GenMode {
+		err := timePhase(summary, "gen", func() error {
+			return runGenerate(cmd)
+		})
+
+		summary.Outputs = addSummaryFile(summary.Outputs, "original", outputPath(cmd.OriginalFile))
+		if cmd.UpdatedFile != "" {
+			summary.Outputs = addSummaryFile(summary.Outputs, "updated", outputPath(cmd.UpdatedFile))
+		}
+
+		return err
+	}
+
+	// Run Replay mode in isolation from other modes
+	if cmd.Replay != "" {
+		summary.Inputs = addSummaryFile(summary.Inputs, "capture", cmd.Replay)
+		return timePhase(summary, "replay", func() error {
+			return runReplay(cmd)
+		})
+	}
+
+	// Run Simulate mode in isolation from other modes
+	if cmd.SimulateMode {
+		summary.Inputs = addSummaryFile(summary.Inputs, "original", cmd.OriginalFile)
+		return timePhase(summary, "simulate", func() error {
+			return runSimulation(cmd)
+		})
+	}
+
+	// Fail early when output + temp files would not fit in the Outputs + temp folders
+	if err := verifyFreeSpace(cmd); err != nil {
+		return err
+	}
+
+	// Run Selftest mode in isolation from other modes
+	if cmd.SelftestMode {
+		summary.Inputs = addSummaryFile(summary.Inputs, "original", cmd.OriginalFile)
+		summary.Inputs = addSummaryFile(summary.Inputs, "updated", cmd.UpdatedFile)
+		return timePhase(summary, "selftest", func() error {
+			return runSelftest(cmd)
+		})
+	}
+
+	// Run Analyze mode in isolation from other modes
+	if cmd.AnalyzeMode {
+		summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
+		summary.Inputs = addSummaryFile(summary.Inputs, "updated", cmd.UpdatedFile)
+		return timePhase(summary, "analyze", func() error {
+			counter, err := runAnalysis(cmd)
+			summary.MatchedBytes, summary.LiteralBytes = counter.matchedBytes, counter.literalBytes
+			return err
+		})
+	}
+
+	// Run Convert mode in isolation from other modes
+	if cmd.ConvertMode {
+		summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
+		summary.Inputs = addSummaryFile(summary.Inputs, "delta", cmd.DeltaFile)
+		summary.Outputs = addSummaryFile(summary.Outputs, "converted", outputPath(cmd.ConvertTo))
+		return timePhase(summary, "convert", func() error {
+			return runConvert(cmd)
+		})
+	}
+
+	// Run Similarity mode in isolation from other modes
+	if cmd.Similarity {
+		summary.Inputs = addSummaryFile(summary.Inputs, "original", cmd.OriginalFile)
+		summary.Inputs = addSummaryFile(summary.Inputs, "updated", cmd.UpdatedFile)
+		return timePhase(summary, "similarity", func() error {
+			counter, err := runSimilarity(cmd)
+			summary.MatchedBytes, summary.LiteralBytes = counter.matchedBytes, counter.literalBytes
+			return err
+		})
+	}
+
+	// Run Batch mode in isolation from other modes
+	if cmd.FilesFrom != "" {
+		summary.Inputs = addSummaryFile(summary.Inputs, "filesFrom", cmd.FilesFrom)
+		return timePhase(summary, "batch", func() error {
+			return runBatch(cmd, summary)
+		})
+	}
+
+	// Run Cat Signature mode in isolation from other modes
+	if cmd.CatSignature {
+		summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
+		return timePhase(summary, "catSig", func() error {
+			return runCatSignature(cmd)
+		})
+	}
+
+	// Run Stats mode in isolation from other modes
+	if cmd.StatsMode {
+		summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
+		return timePhase(summary, "stats", func() error {
+			_, err := runSignatureStats(cmd)
+			return err
+		})
+	}
+
+	// Run Git diff driver mode in isolation from other modes
+	if cmd.GitDiffDriver {
+		summary.Inputs = addSummaryFile(summary.Inputs, "old", cmd.Args[1])
+		summary.Inputs = addSummaryFile(summary.Inputs, "new", cmd.Args[4])
+		return timePhase(summary, "gitDiffDriver", func() error {
+			return runGitDiffDriver(cmd)
+		})
 	}
 
 	var signature models.Signature
@@ -131,28 +629,209 @@ func main() {
 
 	if cmd.SignatureMode {
 		// Generate Signature
-		signature, err = getSignature(cmd)
+		summary.Inputs = addSummaryFile(summary.Inputs, "original", cmd.OriginalFile)
+		err = timePhase(summary, "signature", func() error {
+			signature, err = getSignature(cmd)
+			return err
+		})
+
 		if err != nil {
-			logger(err.Error(), true)
-			return
+			return err
 		}
+
+		recordSignatureSize(summary, signature)
+		summary.Outputs = addSummaryFile(summary.Outputs, "signature", outputPath(cmd.SignatureFile))
 	}
 
+	var delta models.Delta
 	if cmd.DeltaMode {
 		// Get signature from file when running delta mode only
 		if !cmd.SignatureMode {
+			summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
+			signature, err = openDeltaSignature(cmd)
+			if err != nil {
+				return err
+			}
+
+			recordSignatureSize(summary, signature)
+		}
+
+		summary.Inputs = addSummaryFile(summary.Inputs, "updated", cmd.UpdatedFile)
+		if cmd.DeltaFormat == constants.DeltaFormatJSONL || cmd.StreamDelta {
+			// Stream Delta as JSON Lines (or a streamed gob Delta file)
+			err = timePhase(summary, "delta", func() error {
+				counter, err := streamDelta(cmd, signature)
+				summary.MatchedBytes, summary.LiteralBytes = counter.matchedBytes, counter.literalBytes
+				return err
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if !isStreamingToStdout(cmd) {
+				summary.Outputs = addSummaryFile(summary.Outputs, "delta", outputPath(cmd.DeltaFile))
+			}
+		} else {
+			// Generate Delta
+			err = timePhase(summary, "delta", func() error {
+				delta, err = getDelta(cmd, signature)
+				return err
+			})
+
+			if err != nil {
+				return err
+			}
+
+			summary.MatchedBytes, summary.LiteralBytes = deltaStats(delta)
+			summary.Outputs = addSummaryFile(summary.Outputs, "delta", outputPath(cmd.DeltaFile))
+		}
+	}
+
+	if cmd.Remote != "" {
+		// Stream Delta file to the remote host, patching the Original file there (EG patched file never written locally)
+		err = timePhase(summary, "remote", func() error {
+			return runRemotePatch(cmd)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.PatchMode {
+		// Get signature from file when verifying patched blocks in patch mode only
+		if !cmd.SignatureMode && !cmd.DeltaMode && cmd.SignatureFile != "" {
+			summary.Inputs = addSummaryFile(summary.Inputs, "signature", cmd.SignatureFile)
 			signature, err = openSignature(cmd.SignatureFile, cmd.Verbose)
 			if err != nil {
-				logger(err.Error(), true)
-				return
+				return err
+			}
+		}
+
+		// Patch Original file with the generated Delta (or the Delta file when not generated in memory)
+		summary.Inputs = addSummaryFile(summary.Inputs, "original", cmd.OriginalFile)
+		if !cmd.DeltaMode {
+			summary.Inputs = addSummaryFile(summary.Inputs, "delta", cmd.DeltaFile)
+		}
+
+		// Read Delta from stdin into a temp file (EG streamed over SSH by `-remote`), so it is applied in the same way as a Delta file
+		if !cmd.DeltaMode && cmd.DeltaFile == patchStdin {
+			cmd.DeltaFile, err = spoolPatchDelta(cmd)
+			if err != nil {
+				return err
 			}
+
+			defer removeFile(cmd.DeltaFile)
 		}
 
-		// Generate Delta
-		_, err = getDelta(cmd, signature)
+		err = timePhase(summary, "patch", func() error {
+			return runPatch(cmd, signature, delta)
+		})
+
 		if err != nil {
-			logger(err.Error(), true)
-			return
+			return err
 		}
+
+		summary.Outputs = addSummaryFile(summary.Outputs, "patched", outputPath(cmd.OutputFile))
+	}
+
+	return nil
+}
+
+// writeDelta() will write a Delta to the Delta file provided in CMD.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToCreateDeltaFileError` when unable to create Delta file.
+// Function returns `emptyDelta, UnableToWriteToDeltaFileError` when unable to write to Delta file.
+// Note: when `-format=rdiff` or `-format=bsdiff` is set, Delta will be written in the rdiff or bsdiff format (see rdiffModel() + files.BsdiffDelta).
+// Note: when `-encoding` is set to json, protobuf or cbor, Delta will be written with that encoding (see encodingModel()).
+// Note: gob Delta files will record the SHA-256 of the Updated file after the Delta, so Patch mode can verify its output (see models.DeltaMetadata).
+// Note: no hash will be recorded when `-range` is set, as the Delta copies bytes outside the region from the Original file, so does not reconstruct the Updated file.
+// Function returns `emptyDelta, UnableToReadUpdatedFileError` when unable to hash the Updated file for a gob Delta file.
+func writeDelta(cmd models.CMD, delta models.Delta) (models.Delta, error) {
+	var model any = delta
+	if cmd.DeltaFormat == constants.DeltaFormatRdiff {
+		model = rdiffModel(cmd, delta, models.SignatureMetadata{})
+	} else if cmd.DeltaFormat == constants.DeltaFormatBsdiff {
+		model = files.BsdiffDelta(delta)
+	} else {
+		model = encodingModel(cmd, delta, models.SignatureMetadata{})
 	}
+
+	var err error
+	if _, isGob := model.(models.Delta); isGob {
+		metadata := models.DeltaMetadata{}
+		if cmd.Range == "" {
+			updatedHash, hashErr := hashFile(cmd.UpdatedFile)
+			if hashErr != nil {
+				return models.Delta{}, errors.New(constants.UnableToReadUpdatedFileError)
+			}
+
+			metadata.UpdatedHash = updatedHash
+		}
+
+		err = writeDeltaToFile(delta, metadata, cmd.DeltaFile)
+	} else {
+		err = writeStructToFile(model, cmd.DeltaFile)
+	}
+
+	if err != nil {
+		// Replace generic `UnableToCreateFileError` error with specific Delta File error
+		if err.Error() == constants.UnableToCreateFileError {
+			return models.Delta{}, errors.New(constants.UnableToCreateDeltaFileError)
+		}
+
+		return models.Delta{}, errors.New(constants.UnableToWriteToDeltaFileError)
+	}
+
+	return delta, nil
+}
+
+// encodingModel() will wrap a Signature (along with its metadata) or Delta, so it is written with the encoding selected by `-encoding` by files.WriteStructToFile().
+// JSON models (see files.JSONSignature + files.JSONDelta) will be returned for `-encoding=json`, protobuf models (see files.ProtoSignature + files.ProtoDelta) for `-encoding=protobuf`, and CBOR models (see files.CBORSignature + files.CBORDelta) for `-encoding=cbor`.
+// Note: model will be returned unchanged for gob encoding.
+func encodingModel(cmd models.CMD, model any, metadata models.SignatureMetadata) any {
+	delta, isDelta := model.(models.Delta)
+	switch {
+	case cmd.Encoding == constants.EncodingJSON && isDelta:
+		return files.JSONDelta(delta)
+	case cmd.Encoding == constants.EncodingJSON:
+		return files.JSONSignature{Signature: model.(models.Signature), Metadata: metadata}
+	case cmd.Encoding == constants.EncodingProtobuf && isDelta:
+		return files.ProtoDelta(delta)
+	case cmd.Encoding == constants.EncodingProtobuf:
+		return files.ProtoSignature{Signature: model.(models.Signature), Metadata: metadata}
+	case cmd.Encoding == constants.EncodingCBOR && isDelta:
+		return files.CBORDelta(delta)
+	case cmd.Encoding == constants.EncodingCBOR:
+		return files.CBORSignature{Signature: model.(models.Signature), Metadata: metadata}
+	}
+
+	return model
+}
+
+// rdiffModel() will wrap a Signature or Delta, so it is written in the rdiff format by files.WriteStructToFile() (see files.RdiffSignature + files.RdiffDelta).
+// Signatures will be written with the provided metadata, using the current settings for any setting it does not record (EG `-chunk`, `-weakHash` + `-strongHash` for gob Signature files).
+func rdiffModel(cmd models.CMD, model any, metadata models.SignatureMetadata) any {
+	if delta, isDelta := model.(models.Delta); isDelta {
+		return files.RdiffDelta(delta)
+	}
+
+	if metadata.ChunkSize == 0 {
+		metadata.ChunkSize = sync.ChunkSize()
+	}
+
+	if metadata.WeakHash == "" {
+		metadata.WeakHash = sync.Metadata().WeakHash
+	}
+
+	if metadata.StrongHash == "" {
+		metadata.StrongHash = cmd.StrongHash
+	}
+
+	if metadata.StrongHash == "" {
+		metadata.StrongHash = sync.DefaultStrongHash
+	}
+
+	return files.RdiffSignature{Signature: model.(models.Signature), Metadata: metadata}
 }
@@ -2,22 +2,130 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"flag"
+	"io"
+	"math/rand"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
 	"github.com/curtismenmuir/go-file-diff/models"
 	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
 	"github.com/stretchr/testify/require"
 )
 
+const cliChildEnv string = "GFD_CLI_ARGS" // Set (to newline delimited CMD flags) when the test binary is re-run as the CLI
+
 var (
 	file          string           = "some-file.txt"
 	errorMessage  string           = "Some Error"
 	testSignature models.Signature = models.Signature{123: models.StrongSignature{Hash: "some-hash", Head: 0, Tail: 15}}
 )
 
+func TestConfirmOverwrite(t *testing.T) {
+	t.Run("should return `nil` without checking output file when `-yes` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Yes: true}
+		checked := false
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			checked = true
+			return true, nil
+		}
+
+		// Run
+		err := confirmOverwrite(cmd, file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, checked)
+	})
+
+	t.Run("should return `nil` when user confirms overwriting existing output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{}
+		prompt := ""
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return true, nil
+		}
+
+		confirm = func(message string) bool {
+			prompt = message
+			return true
+		}
+
+		// Run
+		err := confirmOverwrite(cmd, file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "Output file `some-file.txt` already exists. Overwrite?", prompt)
+	})
+
+	t.Run("should return `OverwriteDeclinedError` when user declines overwriting existing output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{}
+		expectedError := errors.New(constants.OverwriteDeclinedError)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return true, nil
+		}
+
+		confirm = func(message string) bool {
+			return false
+		}
+
+		// Run
+		err := confirmOverwrite(cmd, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `error` when unable to check existence of output file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{}
+		expectedError := errors.New(constants.SearchingForFileButFoundDirError)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, expectedError
+		}
+
+		// Run
+		err := confirmOverwrite(cmd, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `nil` without prompting when output file does not exist", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{}
+		prompted := false
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, nil
+		}
+
+		confirm = func(message string) bool {
+			prompted = true
+			return false
+		}
+
+		// Run
+		err := confirmOverwrite(cmd, file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, false, prompted)
+	})
+}
+
 func TestGetSignature(t *testing.T) {
 	t.Run("should return `Signature, nil` when Signature generated successfully", func(t *testing.T) {
 		// Setup
@@ -41,7 +149,78 @@ func TestGetSignature(t *testing.T) {
 			return testSignature, nil
 		}
 
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
+			return nil
+		}
+
+		// Run
+		signature, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testSignature, signature)
+	})
+
+	t.Run("should return sparse `Signature, nil` when `-sparse` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, Sparse: 64}
+		stride := int64(0)
+		// Mock
+		generateSparseSignature = func(reader sync.Reader, sampleStride int64, verbose bool) (models.Signature, error) {
+			stride = sampleStride
+			return testSignature, nil
+		}
+
+		// Run
+		signature, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testSignature, signature)
+		require.Equal(t, int64(64), stride)
+		// Restore, so later tests generate sparse Signatures for real
+		generateSparseSignature = sync.GenerateSparseSignature
+	})
+
+	t.Run("should write an rdiff Signature of the block at every chunk when `-format=rdiff` set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, DeltaFormat: constants.DeltaFormatRdiff, StrongHash: strong.MD4Name}
+		stride := int64(0)
+		var written any
+		// Strong hash selected by `-strongHash` (see main())
+		require.Equal(t, nil, sync.UseHashes(strong.MD4Name, ""))
+		// Mock
+		generateSparseSignature = func(reader sync.Reader, sampleStride int64, verbose bool) (models.Signature, error) {
+			stride = sampleStride
+			return testSignature, nil
+		}
+
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		signature, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testSignature, signature)
+		require.Equal(t, sync.ChunkSize(), stride)
+		require.Equal(t, files.RdiffSignature{Signature: testSignature, Metadata: models.SignatureMetadata{ChunkSize: sync.ChunkSize(), WeakHash: sync.DefaultWeakHash, StrongHash: strong.MD4Name}}, written)
+		require.Equal(t, nil, sync.UseHashes("", ""))
+		// Restore, so later tests generate sparse Signatures for real
+		generateSparseSignature = sync.GenerateSparseSignature
+	})
+
+	t.Run("should write a JSON Signature along with its metadata when `-encoding=json` set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, Encoding: constants.EncodingJSON}
+		var written any
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
 		writeStructToFile = func(model any, fileName string) error {
+			written = model
 			return nil
 		}
 
@@ -50,6 +229,60 @@ func TestGetSignature(t *testing.T) {
 		// Verify
 		require.Equal(t, nil, err)
 		require.Equal(t, testSignature, signature)
+		require.Equal(t, files.JSONSignature{Signature: testSignature, Metadata: sync.Metadata()}, written)
+	})
+
+	t.Run("should return `EmptySignature, RdiffUnsupportedHashError` when rdiff Signature can not be written", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, DeltaFormat: constants.DeltaFormatRdiff}
+		expectedError := errors.New(constants.RdiffUnsupportedHashError)
+		// Mock
+		generateSparseSignature = func(reader sync.Reader, sampleStride int64, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		writeStructToFile = func(model any, fileName string) error {
+			return expectedError
+		}
+
+		// Run
+		signature, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+		// Restore, so later tests generate sparse Signatures for real
+		generateSparseSignature = sync.GenerateSparseSignature
+	})
+
+	t.Run("should return `EmptySignature, OverwriteDeclinedError` when user declines overwriting Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file}
+		opened := false
+		expectedError := errors.New(constants.OverwriteDeclinedError)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return true, nil
+		}
+
+		confirm = func(message string) bool {
+			return false
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			opened = true
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		signature, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+		require.Equal(t, false, opened)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, nil
+		}
 	})
 
 	t.Run("should return `EmptySignature, OriginalFileNotExistError` when Original file cannot be found", func(t *testing.T) {
@@ -157,6 +390,42 @@ func TestGetSignature(t *testing.T) {
 		require.Equal(t, models.Signature{}, signature)
 	})
 
+	t.Run("should finish progress reporting when fails to generate Signature of Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			Progress:      true,
+			OriginalFile:  file,
+			SignatureFile: file,
+		}
+
+		messages := []string{}
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("hello"))), nil
+		}
+
+		fileSize = func(fileName string) (int64, error) {
+			return 5, nil
+		}
+
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		_, err := getSignature(cmd)
+		// Verify
+		require.Equal(t, errors.New(constants.UnableToGenerateSignatureError), err)
+		require.Equal(t, 1, len(messages))
+		require.Contains(t, messages[0], "Signature: 0.0%")
+		fileSize = files.FileSize
+	})
+
 	t.Run("should return `EmptySignature, UnableToCreateSignatureFileError` when unable to create Signature file", func(t *testing.T) {
 		// Setup
 		cmd := models.CMD{
@@ -180,7 +449,7 @@ func TestGetSignature(t *testing.T) {
 			return nil, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
 			return errors.New(constants.UnableToCreateFileError)
 		}
 
@@ -214,7 +483,7 @@ func TestGetSignature(t *testing.T) {
 			return nil, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
 			return expectedError
 		}
 
@@ -242,15 +511,16 @@ func TestGetDelta(t *testing.T) {
 		expectedDelta := models.Delta{}
 		// Mock
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
 			return expectedDelta, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		var written models.DeltaMetadata
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+			written = metadata
 			return nil
 		}
 
@@ -259,6 +529,32 @@ func TestGetDelta(t *testing.T) {
 		// Verify
 		require.Equal(t, expectedDelta, delta)
 		require.Equal(t, nil, err)
+		// SHA-256 of the Updated file contents (EG the mocked file name)
+		require.Equal(t, models.DeltaMetadata{UpdatedHash: "ed1d5d371f533dd5b060e9da32cd58795b5552290c13d46bc0ebc8e6ec1e6aad"}, written)
+	})
+
+	t.Run("should return `emptyDelta, OverwriteDeclinedError` when user declines overwriting Delta file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+		expectedError := errors.New(constants.OverwriteDeclinedError)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return true, nil
+		}
+
+		confirm = func(message string) bool {
+			return false
+		}
+
+		// Run
+		delta, err := getDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+		// Mock
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, nil
+		}
 	})
 
 	t.Run("should return `emptyDelta, UpdatedFileDoesNotExistError` when unable to find Updated file", func(t *testing.T) {
@@ -355,8 +651,7 @@ func TestGetDelta(t *testing.T) {
 		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
 		// Mock
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
@@ -386,8 +681,7 @@ func TestGetDelta(t *testing.T) {
 		expectedError := errors.New(constants.UnableToGenerateDeltaError)
 		// Mock
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
@@ -401,6 +695,60 @@ func TestGetDelta(t *testing.T) {
 		require.Equal(t, expectedError, err)
 	})
 
+	t.Run("should write an rdiff Delta when `-format=rdiff` set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, DeltaFormat: constants.DeltaFormatRdiff}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")}}
+		var written any
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		delta, err := getDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, files.RdiffDelta(expectedDelta), written)
+	})
+
+	t.Run("should write a JSON Delta when `-encoding=json` set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, Encoding: constants.EncodingJSON}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")}}
+		var written any
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		writeStructToFile = func(model any, fileName string) error {
+			written = model
+			return nil
+		}
+
+		// Run
+		delta, err := getDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, files.JSONDelta(expectedDelta), written)
+	})
+
 	t.Run("should return `emptyDelta, UnableToCreateDeltaFileError` when unable to create Delta file", func(t *testing.T) {
 		// Setup
 		cmd := models.CMD{
@@ -417,15 +765,14 @@ func TestGetDelta(t *testing.T) {
 		expectedError := errors.New(constants.UnableToCreateDeltaFileError)
 		// Mock
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
 			return expectedDelta, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
 			return errors.New(constants.UnableToCreateFileError)
 		}
 
@@ -452,15 +799,14 @@ func TestGetDelta(t *testing.T) {
 		expectedError := errors.New(constants.UnableToWriteToDeltaFileError)
 		// Mock
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
 			return expectedDelta, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
 			return expectedError
 		}
 
@@ -470,47 +816,119 @@ func TestGetDelta(t *testing.T) {
 		require.Equal(t, expectedDelta, delta)
 		require.Equal(t, expectedError, err)
 	})
-}
-
-func TestMain(t *testing.T) {
-	t.Run("should not throw error when successfully generated Signature", func(t *testing.T) {
+	t.Run("should return `cachedDelta, nil` without generating Delta when found in Delta cache", func(t *testing.T) {
 		// Setup
-		cmd := models.CMD{
-			Verbose:       false,
-			SignatureMode: true,
-			DeltaMode:     false,
-			OriginalFile:  file,
-			SignatureFile: file,
-			UpdatedFile:   file,
-			DeltaFile:     file,
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, DeltaCache: "cache"}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 15}}
+		generated := false
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
-		logged := false
-		// Mock
-		logger = func(message string, verbose bool) {
-			logged = true
+		openDelta = func(fileName string, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
 		}
 
-		parseCMD = func() models.CMD {
-			return cmd
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			generated = true
+			return models.Delta{}, nil
 		}
 
-		verifyCMD = func(cmd models.CMD) bool {
-			return true
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
+			return nil
 		}
 
-		openFile = func(fileName string) (*bufio.Reader, error) {
+		// Run
+		delta, err := getDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, nil, err)
+		require.Equal(t, false, generated)
+	})
+
+	t.Run("should store generated Delta in Delta cache when not found in Delta cache", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file, DeltaCache: "cache"}
+		expectedDelta := models.Delta{0: {Head: 0, Tail: 15}}
+		cached := ""
+		// Mock
+		openDelta = func(fileName string, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(constants.DeltaFileDoesNotExistError)
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		makeDir = func(path string, perm os.FileMode) error {
+			return nil
+		}
+
+		writeStructToPath = func(model any, path string) error {
+			return nil
+		}
+
+		renameFile = func(oldPath string, newPath string) error {
+			cached = newPath
+			return nil
+		}
+
+		// Run
+		delta, err := getDelta(cmd, testSignature)
+		// Verify
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, nil, err)
+		expectedPath, _ := deltaCachePath(cmd)
+		require.Equal(t, expectedPath, cached)
+	})
+}
+
+func TestMain(t *testing.T) {
+	t.Run("should not throw error when successfully generated Signature", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			Verbose:       false,
+			SignatureMode: true,
+			DeltaMode:     false,
+			OriginalFile:  file,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+		}
+
+		logged := false
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = true
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
 			file := os.File{}
 			return bufio.NewReader(&file), nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
 			return nil
 		}
 
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 0, exitCode)
 		require.Equal(t, false, logged)
 	})
 
@@ -529,8 +947,13 @@ func TestMain(t *testing.T) {
 		logged := false
 		loggedMessage := ""
 		expectedError := constants.UnableToWriteToSignatureFileError
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 			loggedMessage = message
 		}
@@ -548,13 +971,14 @@ func TestMain(t *testing.T) {
 			return bufio.NewReader(&file), nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
 			return errors.New(expectedError)
 		}
 
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 1, exitCode)
 		require.Equal(t, true, logged)
 		require.Equal(t, expectedError, loggedMessage)
 	})
@@ -572,8 +996,13 @@ func TestMain(t *testing.T) {
 		}
 
 		logged := false
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 		}
 
@@ -585,26 +1014,26 @@ func TestMain(t *testing.T) {
 			return true
 		}
 
-		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
-			return testSignature, nil
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return testSignature, models.SignatureMetadata{}, nil
 		}
 
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
 		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
 			return nil, nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
 			return nil
 		}
 
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 0, exitCode)
 		require.Equal(t, false, logged)
 	})
 
@@ -621,8 +1050,13 @@ func TestMain(t *testing.T) {
 		}
 
 		logged := false
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 		}
 
@@ -635,11 +1069,14 @@ func TestMain(t *testing.T) {
 		}
 
 		openFile = func(fileName string) (*bufio.Reader, error) {
-			file := os.File{}
-			return bufio.NewReader(&file), nil
+			return bufio.NewReader(bytes.NewReader([]byte(fileName))), nil
 		}
 
-		writeStructToFile = func(model any, fileName string) error {
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
+			return nil
+		}
+
+		writeDeltaToFile = func(delta models.Delta, metadata models.DeltaMetadata, fileName string) error {
 			return nil
 		}
 
@@ -650,6 +1087,7 @@ func TestMain(t *testing.T) {
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 0, exitCode)
 		require.Equal(t, false, logged)
 	})
 
@@ -668,8 +1106,13 @@ func TestMain(t *testing.T) {
 		logged := false
 		loggedMessage := ""
 		expectedError := constants.UnableToGenerateDeltaError
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 			loggedMessage = message
 		}
@@ -682,8 +1125,8 @@ func TestMain(t *testing.T) {
 			return true
 		}
 
-		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
-			return testSignature, nil
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return testSignature, models.SignatureMetadata{}, nil
 		}
 
 		openFile = func(fileName string) (*bufio.Reader, error) {
@@ -698,6 +1141,7 @@ func TestMain(t *testing.T) {
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 1, exitCode)
 		require.Equal(t, true, logged)
 		require.Equal(t, expectedError, loggedMessage)
 	})
@@ -717,8 +1161,13 @@ func TestMain(t *testing.T) {
 		logged := false
 		loggedMessage := ""
 		expectedError := constants.UnableToOpenSignatureFileError
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 			loggedMessage = message
 		}
@@ -731,13 +1180,14 @@ func TestMain(t *testing.T) {
 			return true
 		}
 
-		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
-			return nil, errors.New(expectedError)
+		openSignatureMeta = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+			return nil, models.SignatureMetadata{}, errors.New(expectedError)
 		}
 
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 1, exitCode)
 		require.Equal(t, true, logged)
 		require.Equal(t, expectedError, loggedMessage)
 	})
@@ -755,8 +1205,13 @@ func TestMain(t *testing.T) {
 		}
 
 		logged := false
+		exitCode := 0
 		// Mock
-		logger = func(message string, verbose bool) {
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
 			logged = true
 		}
 
@@ -771,6 +1226,824 @@ func TestMain(t *testing.T) {
 		// Run
 		main()
 		// Verify
+		require.Equal(t, 2, exitCode)
 		require.Equal(t, false, logged)
 	})
+
+	t.Run("should throw `HashNotRegisteredError` when unknown hash algorithm selected", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, StrongHash: "unknown"}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.HashNotRegisteredError), logged)
+	})
+
+	t.Run("should throw `HashNotRegisteredError` when unknown Legacy hash algorithm selected", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, LegacyHash: "unknown"}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.HashNotRegisteredError), logged)
+	})
+
+	t.Run("should throw `InvalidPermissionsError` when invalid permissions provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, FileMode: "0999"}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.InvalidPermissionsError), logged)
+	})
+
+	t.Run("should throw `InvalidReadRetriesError` when negative read retries provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, ReadRetries: -1}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.InvalidReadRetriesError), logged)
+	})
+
+	t.Run("should throw `InvalidSignatureWorkersError` when negative Signature workers provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, Workers: -1}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.InvalidSignatureWorkersError), logged)
+	})
+
+	t.Run("should throw `InvalidChaosRateError` when chaos rate outside 0-1 provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, Chaos: 2}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.InvalidChaosRateError), logged)
+	})
+
+	t.Run("should throw `InvalidCompressionError` when unknown compression method provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, OriginalFile: file, SignatureFile: file, Compress: "lz4"}
+		logged := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, utils.Failure(constants.InvalidCompressionError), logged)
+	})
+
+	t.Run("should throw error when Benchmark mode fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			BenchMode:    true,
+			OriginalFile: file,
+		}
+
+		logged := false
+		loggedMessage := ""
+		expectedError := constants.OriginalFileDoesNotExistError
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = true
+			loggedMessage = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.FileDoesNotExistError)
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 1, exitCode)
+		require.Equal(t, true, logged)
+		require.Equal(t, expectedError, loggedMessage)
+	})
+	t.Run("should throw error when Simulate mode fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+			OriginalFile: file,
+		}
+
+		logged := false
+		loggedMessage := ""
+		expectedError := constants.OriginalFileIsFolderError
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = true
+			loggedMessage = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.SearchingForFileButFoundDirError)
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 1, exitCode)
+		require.Equal(t, true, logged)
+		require.Equal(t, expectedError, loggedMessage)
+	})
+	t.Run("should throw error when Selftest mode fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SelftestMode: true,
+			OriginalFile: file,
+			UpdatedFile:  file,
+		}
+
+		logged := false
+		loggedMessage := ""
+		expectedError := constants.UnableToCreateTempFolderError
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {
+			logged = true
+			loggedMessage = message
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		makeTempDir = func(dir string, pattern string) (string, error) {
+			return "", errors.New(errorMessage)
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 1, exitCode)
+		require.Equal(t, true, logged)
+		require.Equal(t, expectedError, loggedMessage)
+	})
+	t.Run("should disable color output when `-noColor` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{NoColor: true}
+		colorEnabled := true
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		logger = func(message string, verbose bool) {}
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return false
+		}
+
+		setColor = func(enabled bool) {
+			colorEnabled = enabled
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, false, colorEnabled)
+	})
+	t.Run("should enable quiet mode when `-quiet` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Quiet: true}
+		quiet := false
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return false
+		}
+
+		setQuiet = func(enabled bool) {
+			quiet = enabled
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 2, exitCode)
+		require.Equal(t, true, quiet)
+	})
+	t.Run("should write run summary with matched + literal bytes when `-summaryJSON` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SignatureMode: true,
+			DeltaMode:     true,
+			OriginalFile:  file,
+			SignatureFile: file,
+			UpdatedFile:   file,
+			DeltaFile:     file,
+			SummaryJSON:   "-",
+		}
+
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+			16: models.Block{Head: 16, Tail: 17, IsModified: true, Value: []byte("ab")},
+		}
+
+		printed := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		outputFileExists = func(fileName string) (bool, error) {
+			return false, nil
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte{})), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return delta, nil
+		}
+
+		writeSignatureToFile = func(signature models.Signature, metadata models.SignatureMetadata, fileName string) error {
+			return nil
+		}
+
+		writeStructToFile = func(model any, fileName string) error {
+			return nil
+		}
+
+		fileSize = func(fileName string) (int64, error) {
+			return 18, nil
+		}
+
+		printSummary = func(a ...any) (int, error) {
+			printed = a[0].(string)
+			return 0, nil
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 0, exitCode)
+		summary := models.Summary{}
+		require.Equal(t, nil, json.Unmarshal([]byte(printed), &summary))
+		require.Equal(t, "signature+delta", summary.Mode)
+		require.Equal(t, int64(16), summary.MatchedBytes)
+		require.Equal(t, int64(2), summary.LiteralBytes)
+		require.Equal(t, []models.SummaryFile{{Name: "original", Path: file, Size: 18}, {Name: "updated", Path: file, Size: 18}}, summary.Inputs)
+		require.Equal(t, 2, len(summary.Outputs))
+		require.Equal(t, 0, summary.ExitStatus)
+	})
+
+	t.Run("should write run summary with exit status + error when run fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SelftestMode: true,
+			OriginalFile: file,
+			UpdatedFile:  file,
+			SummaryJSON:  "-",
+		}
+
+		printed := ""
+		exitCode := 0
+		// Mock
+		exit = func(code int) {
+			exitCode = code
+		}
+
+		errorLogger = func(message string) {}
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return true
+		}
+
+		makeTempDir = func(dir string, pattern string) (string, error) {
+			return "", errors.New(errorMessage)
+		}
+
+		printSummary = func(a ...any) (int, error) {
+			printed = a[0].(string)
+			return 0, nil
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 1, exitCode)
+		summary := models.Summary{}
+		require.Equal(t, nil, json.Unmarshal([]byte(printed), &summary))
+		require.Equal(t, "selftest", summary.Mode)
+		require.Equal(t, 1, summary.ExitStatus)
+		require.Equal(t, constants.UnableToCreateTempFolderError, summary.Error)
+	})
+	t.Run("should configure log sampling from `-logEvery` + `-logRate` flags", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{LogEvery: 100, LogRate: 10}
+		every := 0
+		perSecond := 0
+		// Mock
+		exit = func(code int) {}
+		parseCMD = func() models.CMD {
+			return cmd
+		}
+
+		verifyCMD = func(cmd models.CMD) bool {
+			return false
+		}
+
+		setLogSampling = func(logEvery int, logRate int) {
+			every = logEvery
+			perSecond = logRate
+		}
+
+		// Run
+		main()
+		// Verify
+		require.Equal(t, 100, every)
+		require.Equal(t, 10, perSecond)
+	})
+}
+
+func TestEncodingModel(t *testing.T) {
+	t.Run("should wrap Signatures + Deltas in the models of the encoding selected by `-encoding`", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: {Head: 0, Tail: 2, IsModified: true, Value: []byte("abc")}}
+		metadata := models.SignatureMetadata{ChunkSize: 16}
+		expectedModels := map[string][]any{
+			constants.EncodingGob:      {testSignature, delta},
+			constants.EncodingJSON:     {files.JSONSignature{Signature: testSignature, Metadata: metadata}, files.JSONDelta(delta)},
+			constants.EncodingProtobuf: {files.ProtoSignature{Signature: testSignature, Metadata: metadata}, files.ProtoDelta(delta)},
+			constants.EncodingCBOR:     {files.CBORSignature{Signature: testSignature, Metadata: metadata}, files.CBORDelta(delta)},
+		}
+
+		for encoding, expected := range expectedModels {
+			cmd := models.CMD{Encoding: encoding}
+			// Run
+			signatureModel := encodingModel(cmd, testSignature, metadata)
+			deltaModel := encodingModel(cmd, delta, models.SignatureMetadata{})
+			// Verify
+			require.Equal(t, expected[0], signatureModel)
+			require.Equal(t, expected[1], deltaModel)
+		}
+	})
+}
+
+func TestLogError(t *testing.T) {
+	t.Run("should log error in red to stderr", func(t *testing.T) {
+		// Setup
+		loggedMessage := ""
+		utils.SetColor(true)
+		// Mock
+		errorLogger = func(message string) {
+			loggedMessage = message
+		}
+
+		// Run
+		logError(errors.New(errorMessage))
+		// Verify
+		require.Equal(t, utils.Failure(errorMessage), loggedMessage)
+		require.NotEqual(t, errorMessage, loggedMessage)
+		utils.SetColor(false)
+	})
+
+	t.Run("should log `UpdatedFileHasNoChangesError` in green to stdout", func(t *testing.T) {
+		// Setup
+		loggedMessage := ""
+		utils.SetColor(true)
+		// Mock
+		logger = func(message string, verbose bool) {
+			loggedMessage = message
+		}
+
+		// Run
+		logError(errors.New(constants.UpdatedFileHasNoChangesError))
+		// Verify
+		require.Equal(t, utils.Success(constants.UpdatedFileHasNoChangesError), loggedMessage)
+		utils.SetColor(false)
+	})
+}
+
+func TestReadOriginal(t *testing.T) {
+	t.Run("should return `data, nil` when Original file read successfully", func(t *testing.T) {
+		// Setup
+		expectedData := []byte("some file contents")
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader(expectedData)), nil
+		}
+
+		readAll = io.ReadAll
+		// Run
+		data, err := readOriginal(file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedData, data)
+	})
+
+	t.Run("should return `nil, OriginalFileDoesNotExistError` when Original file cannot be found", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.OriginalFileDoesNotExistError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.FileDoesNotExistError)
+		}
+
+		// Run
+		data, err := readOriginal(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, data)
+	})
+
+	t.Run("should return `nil, OriginalFileIsFolderError` when user provides a folder dir instead of Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.OriginalFileIsFolderError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.SearchingForFileButFoundDirError)
+		}
+
+		// Run
+		data, err := readOriginal(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, data)
+	})
+
+	t.Run("should return `nil, error` when unable to open Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, expectedError
+		}
+
+		// Run
+		data, err := readOriginal(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, data)
+	})
+
+	t.Run("should return `nil, UnableToReadOriginalFileError` when unable to read Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte{})), nil
+		}
+
+		readAll = func(reader io.Reader) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		data, err := readOriginal(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, data)
+	})
+}
+
+func TestTrackProgress(t *testing.T) {
+	t.Run("should return original reader + nil progress when `-progress` flag not set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{}
+		reader := bufio.NewReader(bytes.NewReader([]byte("hello")))
+		// Run
+		result, progress := trackProgress(cmd, reader, "Signature", file)
+		// Verify
+		require.Equal(t, reader, result)
+		require.Nil(t, progress)
+	})
+
+	t.Run("should report progress against file size when `-progress` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Progress: true}
+		reader := bufio.NewReader(bytes.NewReader([]byte("hello")))
+		messages := []string{}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 10, nil
+		}
+
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		// Run
+		result, progress := trackProgress(cmd, reader, "Signature", file)
+		data, err := io.ReadAll(result)
+		progress.Finish()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte("hello"), data)
+		require.Equal(t, 1, len(messages))
+		require.Contains(t, messages[0], "Signature: 50.0%")
+	})
+
+	t.Run("should report progress without total when unable to get file size", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Progress: true}
+		reader := bufio.NewReader(bytes.NewReader([]byte("hello")))
+		messages := []string{}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		// Run
+		result, progress := trackProgress(cmd, reader, "Delta", file)
+		_, err := io.ReadAll(result)
+		progress.Finish()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Contains(t, messages[0], "Delta: 0.00 MB")
+		require.NotContains(t, messages[0], "ETA")
+	})
+}
+
+// runCLI() will re-run the test binary as the CLI with the provided CMD flags, from the provided folder (EG writing to its `Outputs/` folder).
+// Note: the CLI runs in a child process, so every mock + setting starts from its default (see TestCLI()).
+// Function returns `output, nil` when the CLI exits with status 0.
+// Function returns `output, error` when the CLI exits with any other status.
+func runCLI(t *testing.T, dir string, args ...string) (string, error) {
+	child := exec.Command(os.Args[0], "-test.run=^TestCLI$")
+	child.Env = append(os.Environ(), cliChildEnv+"="+strings.Join(args, "\n"))
+	child.Dir = dir
+	output, err := child.CombinedOutput()
+	return string(output), err
+}
+
+// writeCLIFiles() will create an Original file of random data + an Updated file changing a single byte in a temp folder, and return the folder.
+func writeCLIFiles(t *testing.T, size int) string {
+	dir := t.TempDir()
+	original := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(original)
+	updated := append([]byte{}, original...)
+	updated[size/2]++
+	require.Equal(t, nil, os.WriteFile(filepath.Join(dir, "original.bin"), original, 0o600))
+	require.Equal(t, nil, os.WriteFile(filepath.Join(dir, "updated.bin"), updated, 0o600))
+	return dir
+}
+
+func TestCLI(t *testing.T) {
+	// Run the CLI with the provided flags when re-run by runCLI()
+	if args := os.Getenv(cliChildEnv); args != "" {
+		os.Args = append([]string{"go-file-diff"}, strings.Split(args, "\n")...)
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		main()
+		return
+	}
+
+	t.Run("should generate a Delta with the Strong hash recorded in the Signature file, when `-strongHash` differs between Signature + Delta mode", func(t *testing.T) {
+		// Setup
+		dir := writeCLIFiles(t, 300*1024)
+		output, err := runCLI(t, dir, "-signatureMode", "-original=original.bin", "-signature=sig.bin", "-strongHash="+strong.MD4Name)
+		require.Equal(t, nil, err, output)
+		for _, flags := range [][]string{{}, {"-strongHash=" + sync.DefaultStrongHash}} {
+			// Run
+			output, err := runCLI(t, dir, append([]string{"-deltaMode", "-signature=Outputs/sig.bin", "-updated=updated.bin", "-delta=delta.bin", "-yes"}, flags...)...)
+			// Verify
+			require.Equal(t, nil, err, output)
+			info, err := os.Stat(filepath.Join(dir, "Outputs", "delta.bin"))
+			require.Equal(t, nil, err)
+			// A single changed byte should only send the literal data of its block
+			require.Less(t, info.Size(), int64(4096))
+		}
+	})
+	t.Run("should patch a Delta generated for a `-range` when Updated file also changes outside the range", func(t *testing.T) {
+		// Setup
+		dir := writeCLIFiles(t, 4096)
+		original, err := os.ReadFile(filepath.Join(dir, "original.bin"))
+		require.Equal(t, nil, err)
+		updated, err := os.ReadFile(filepath.Join(dir, "updated.bin"))
+		require.Equal(t, nil, err)
+		// Change bytes within the range, while the byte changed by writeCLIFiles() is outside it
+		copy(updated[2500:], "changed within range")
+		require.Equal(t, nil, os.WriteFile(filepath.Join(dir, "updated.bin"), updated, 0o600))
+		expected := append(append(append([]byte{}, original[:2500]...), updated[2500:3500]...), original[3500:]...)
+		// Run
+		output, err := runCLI(t, dir, "-original=original.bin", "-signature=sig.bin", "-updated=updated.bin", "-delta=delta.bin", "-range=2500:3500")
+		require.Equal(t, nil, err, output)
+		output, err = runCLI(t, dir, "-patchMode", "-original=original.bin", "-delta=Outputs/delta.bin", "-output=patched.bin")
+		// Verify
+		require.Equal(t, nil, err, output)
+		patched, err := os.ReadFile(filepath.Join(dir, "Outputs", "patched.bin"))
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, patched)
+	})
+	t.Run("should overwrite existing output files without prompting when stdin is `/dev/null` (EG cron + CI)", func(t *testing.T) {
+		// Setup
+		dir := writeCLIFiles(t, 4096)
+		output, err := runCLI(t, dir, "-signatureMode", "-original=original.bin", "-signature=sig.bin")
+		require.Equal(t, nil, err, output)
+		// Run (child stdin is `/dev/null` when not set)
+		output, err = runCLI(t, dir, "-signatureMode", "-original=original.bin", "-signature=sig.bin")
+		// Verify
+		require.Equal(t, nil, err, output)
+		require.NotContains(t, output, constants.OverwriteDeclinedError)
+	})
 }
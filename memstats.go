@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// Interval between heap samples when tracking peak heap usage (see `-memStats`)
+const memSampleInterval = 50 * time.Millisecond
+
+// memSampler type.
+// This will sample heap usage in the background, tracking the peak heap usage of a run.
+type memSampler struct {
+	done chan struct{}
+	peak chan uint64
+}
+
+// formatMemStats() will format MemoryStats as human readable lines for logging.
+// EG: `Peak heap: 12.50 MB`, `Peak RSS: 20.00 MB`, `Signature: 65536 entries (~6.00 MB)`, `Delta literals: 1.25 MB`.
+func formatMemStats(stats models.MemoryStats) []string {
+	return []string{
+		fmt.Sprintf("Peak heap: %.2f MB", float64(stats.PeakHeap)/float64(megabyte)),
+		fmt.Sprintf("Peak RSS: %.2f MB", float64(stats.PeakRSS)/float64(megabyte)),
+		fmt.Sprintf("Signature: %d entries (~%.2f MB)", stats.SignatureEntries, float64(stats.SignatureBytes)/float64(megabyte)),
+		fmt.Sprintf("Delta literals: %.2f MB", float64(stats.LiteralBytes)/float64(megabyte)),
+	}
+}
+
+// heapInUse() will return the number of bytes currently allocated on the heap.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	readMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// recordSignatureSize() will record the number of entries + approximate size (bytes) of a Signature held in memory.
+// Size will include the Signature map entries, their Strong hashes, and any candidate blocks (map bucket overhead is not included).
+// Note: this will do nothing when `-memStats` is not enabled (EG summary has no MemoryStats).
+func recordSignatureSize(summary *models.Summary, signature models.Signature) {
+	if summary.Memory == nil {
+		return
+	}
+
+	entry := int64(unsafe.Sizeof(int64(0)) + unsafe.Sizeof(models.StrongSignature{}))
+	size := int64(0)
+	for _, item := range signature {
+		size += entry + int64(len(item.Hash)+len(item.LegacyHash))
+		for _, candidate := range item.Candidates {
+			size += int64(unsafe.Sizeof(candidate)) + int64(len(candidate.Hash)+len(candidate.LegacyHash))
+		}
+	}
+
+	summary.Memory.SignatureEntries = len(signature)
+	summary.Memory.SignatureBytes = size
+}
+
+// reportMemStats() will stop the provided sampler, then record + log the peak memory usage of the run and the sizes of its major structures.
+// Note: this will do nothing when `-memStats` is not enabled (EG sampler is nil).
+func reportMemStats(sampler *memSampler, summary *models.Summary) {
+	if sampler == nil {
+		return
+	}
+
+	summary.Memory.PeakHeap = sampler.stop()
+	summary.Memory.PeakRSS = peakRSS()
+	summary.Memory.LiteralBytes = summary.LiteralBytes
+	for _, line := range formatMemStats(*summary.Memory) {
+		logger(utils.Stat(line), true)
+	}
+}
+
+// startMemSampler() will start sampling heap usage every memSampleInterval, until stopped.
+// Note: MemoryStats will be added to the Summary, so structure sizes can be recorded during the run (see recordSignatureSize()).
+func startMemSampler(summary *models.Summary) *memSampler {
+	summary.Memory = &models.MemoryStats{}
+	sampler := &memSampler{done: make(chan struct{}), peak: make(chan uint64)}
+	go func() {
+		ticker := time.NewTicker(memSampleInterval)
+		defer ticker.Stop()
+		peak := heapInUse()
+		for {
+			select {
+			case <-ticker.C:
+				if heap := heapInUse(); heap > peak {
+					peak = heap
+				}
+			case <-sampler.done:
+				if heap := heapInUse(); heap > peak {
+					peak = heap
+				}
+
+				sampler.peak <- peak
+				return
+			}
+		}
+	}()
+
+	return sampler
+}
+
+// stop() will stop sampling heap usage, and return the peak heap usage (bytes) sampled.
+func (s *memSampler) stop() uint64 {
+	close(s.done)
+	return <-s.peak
+}
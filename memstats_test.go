@@ -0,0 +1,111 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMemStats(t *testing.T) {
+	t.Run("should return formatted peak memory + structure sizes", func(t *testing.T) {
+		// Setup
+		stats := models.MemoryStats{PeakHeap: uint64(2 * megabyte), PeakRSS: int64(3 * megabyte), SignatureEntries: 10, SignatureBytes: int64(megabyte), LiteralBytes: int64(megabyte / 2)}
+		expected := []string{
+			"Peak heap: 2.00 MB",
+			"Peak RSS: 3.00 MB",
+			"Signature: 10 entries (~1.00 MB)",
+			"Delta literals: 0.50 MB",
+		}
+
+		// Run
+		output := formatMemStats(stats)
+		// Verify
+		require.Equal(t, expected, output)
+	})
+}
+
+func TestRecordSignatureSize(t *testing.T) {
+	t.Run("should do nothing when memory stats are not enabled", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{}
+		signature := models.Signature{1: models.StrongSignature{Hash: "abc", Head: 0, Tail: 15}}
+		// Run
+		recordSignatureSize(&summary, signature)
+		// Verify
+		require.Nil(t, summary.Memory)
+	})
+
+	t.Run("should record Signature entries + approximate size", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{Memory: &models.MemoryStats{}}
+		candidate := models.StrongSignature{Hash: "de", Head: 16, Tail: 31}
+		signature := models.Signature{
+			1: models.StrongSignature{Hash: "abc", Head: 0, Tail: 15, Candidates: []models.StrongSignature{candidate}},
+			2: models.StrongSignature{Hash: "fgh", LegacyHash: "ij", Head: 32, Tail: 47},
+		}
+
+		entry := int64(unsafe.Sizeof(int64(0)) + unsafe.Sizeof(models.StrongSignature{}))
+		expectedSize := 2*entry + 3 + 5 + int64(unsafe.Sizeof(candidate)) + 2
+		// Run
+		recordSignatureSize(&summary, signature)
+		// Verify
+		require.Equal(t, 2, summary.Memory.SignatureEntries)
+		require.Equal(t, expectedSize, summary.Memory.SignatureBytes)
+	})
+}
+
+func TestReportMemStats(t *testing.T) {
+	t.Run("should do nothing when memory stats are not enabled", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{}
+		logged := []string{}
+		// Mock
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		// Run
+		reportMemStats(nil, &summary)
+		// Verify
+		require.Nil(t, summary.Memory)
+		require.Equal(t, []string{}, logged)
+	})
+
+	t.Run("should record + log peak heap, peak RSS, and structure sizes", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{LiteralBytes: int64(megabyte)}
+		logged := []string{}
+		heap := uint64(megabyte)
+		// Mock
+		readMemStats = func(stats *runtime.MemStats) {
+			stats.HeapAlloc = heap
+		}
+
+		peakRSS = func() int64 {
+			return int64(4 * megabyte)
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		// Run
+		sampler := startMemSampler(&summary)
+		recordSignatureSize(&summary, models.Signature{})
+		reportMemStats(sampler, &summary)
+		// Verify
+		require.Equal(t, models.MemoryStats{PeakHeap: uint64(megabyte), PeakRSS: int64(4 * megabyte), LiteralBytes: int64(megabyte)}, *summary.Memory)
+		require.Equal(t, []string{
+			utils.Stat("Peak heap: 1.00 MB"),
+			utils.Stat("Peak RSS: 4.00 MB"),
+			utils.Stat("Signature: 0 entries (~0.00 MB)"),
+			utils.Stat("Delta literals: 1.00 MB"),
+		}, logged)
+		readMemStats = runtime.ReadMemStats
+		peakRSS = getPeakRSS
+	})
+}
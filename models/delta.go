@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// OpKind type.
+// This will define whether an Op copies bytes from the Original file, or writes new bytes.
+type OpKind int
+
+const (
+	OpCopy    OpKind = iota // Copy a range of bytes from the Original file
+	OpLiteral               // Write new bytes contained in the Delta
+)
+
+// Op type.
+// This will describe a single operation required to reconstruct the Updated file.
+// Position will be the offset the operation writes to within the Updated file.
+// A copy operation will use Head + Tail to define the range of bytes within the Original file (EG position of first + last characters).
+// EG: Op{Kind: OpCopy, Position: 5, Head: 0, Tail: 4}.
+// A literal operation will use Value to define the bytes to write.
+// EG: Op{Kind: OpLiteral, Position: 0, Value: []byte{'a', 'b', 'c', 'd', 'e'}}.
+type Op struct {
+	Kind     OpKind
+	Position int64
+	Head     int64
+	Tail     int64
+	Value    []byte
+}
+
+// MarshalJSON() will encode an Op with only the fields used by its kind, so encoded Ops are readable (EG JSON Lines output).
+// EG: {"kind":"copy","position":5,"head":0,"tail":4} or {"kind":"literal","position":0,"value":"YWJjZGU="}.
+func (op Op) MarshalJSON() ([]byte, error) {
+	if op.Kind == OpLiteral {
+		return json.Marshal(struct {
+			Kind     OpKind `json:"kind"`
+			Position int64  `json:"position"`
+			Value    []byte `json:"value"`
+		}{op.Kind, op.Position, op.Value})
+	}
+
+	return json.Marshal(struct {
+		Kind     OpKind `json:"kind"`
+		Position int64  `json:"position"`
+		Head     int64  `json:"head"`
+		Tail     int64  `json:"tail"`
+	}{op.Kind, op.Position, op.Head, op.Tail})
+}
+
+// MarshalText() will encode an OpKind as its name (EG `copy` or `literal`), so encoded Ops are readable (EG JSON Lines output).
+func (kind OpKind) MarshalText() ([]byte, error) {
+	if kind == OpLiteral {
+		return []byte("literal"), nil
+	}
+
+	return []byte("copy"), nil
+}
+
+// Block() will convert an Op back into a Delta block (EG when reading JSON Lines output).
+// Note: a literal block will use Head + Tail to define its position within the Updated file.
+func (op Op) Block() Block {
+	if op.Kind == OpLiteral {
+		return Block{Head: op.Position, Tail: op.Position + int64(len(op.Value)) - 1, IsModified: true, Value: op.Value}
+	}
+
+	return Block{Head: op.Head, Tail: op.Tail, IsModified: false, Value: []byte{}}
+}
+
+// Len() will return the number of bytes the Op writes to the Updated file.
+func (op Op) Len() int64 {
+	if op.Kind == OpLiteral {
+		return int64(len(op.Value))
+	}
+
+	return op.Tail - op.Head + 1
+}
+
+// Op() will convert a block at the provided position of the Updated file into an Op.
+func (block Block) Op(position int64) Op {
+	if block.IsModified {
+		return Op{Kind: OpLiteral, Position: position, Value: block.Value}
+	}
+
+	return Op{Kind: OpCopy, Position: position, Head: block.Head, Tail: block.Tail}
+}
+
+// Ops() will call the provided function with each operation of the Delta, in output order (EG sorted by Position).
+// This allows callers to stream-apply or transform a Delta without sorting the Delta keys themselves.
+// Iteration will stop at the first error returned by the provided function.
+// Function returns `nil` when all operations have been visited.
+// Function returns `error` returned by the provided function.
+func (delta Delta) Ops(visit func(op Op) error) error {
+	positions := make([]int64, 0, len(delta))
+	for position := range delta {
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	for _, position := range positions {
+		if err := visit(delta[position].Op(position)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON() will decode an Op encoded by MarshalJSON().
+// Function returns `nil` when successful.
+// Function returns `error` when data is not a valid JSON object, or contains an unknown kind.
+func (op *Op) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Kind     OpKind `json:"kind"`
+		Position int64  `json:"position"`
+		Head     int64  `json:"head"`
+		Tail     int64  `json:"tail"`
+		Value    []byte `json:"value"`
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*op = Op{Kind: fields.Kind, Position: fields.Position, Head: fields.Head, Tail: fields.Tail, Value: fields.Value}
+	if op.Kind == OpLiteral && op.Value == nil {
+		op.Value = []byte{}
+	}
+
+	return nil
+}
+
+// UnmarshalText() will decode an OpKind from its name (EG `copy` or `literal`).
+// Function returns `nil` when successful.
+// Function returns `InvalidOpKindError` when name is unknown.
+func (kind *OpKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "copy":
+		*kind = OpCopy
+	case "literal":
+		*kind = OpLiteral
+	default:
+		return errors.New(constants.InvalidOpKindError)
+	}
+
+	return nil
+}
+
+// WriteBlock() will add a block to the Delta at the provided position.
+// This allows a Delta to be used as an in-memory collector when streaming Delta generation.
+// Function returns `nil` as adding to a map cannot fail.
+func (delta Delta) WriteBlock(position int64, block Block) error {
+	delta[position] = block
+	return nil
+}
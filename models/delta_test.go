@@ -0,0 +1,219 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpLen(t *testing.T) {
+	t.Run("should return size of copied range for copy Op", func(t *testing.T) {
+		// Setup
+		op := Op{Kind: OpCopy, Position: 0, Head: 4, Tail: 19}
+		// Run
+		result := op.Len()
+		// Verify
+		require.Equal(t, int64(16), result)
+	})
+
+	t.Run("should return size of Value for literal Op", func(t *testing.T) {
+		// Setup
+		op := Op{Kind: OpLiteral, Position: 0, Value: []byte("abc")}
+		// Run
+		result := op.Len()
+		// Verify
+		require.Equal(t, int64(3), result)
+	})
+}
+
+func TestOpMarshalJSON(t *testing.T) {
+	t.Run("should encode only the fields used by each Op kind", func(t *testing.T) {
+		// Setup
+		ops := []Op{
+			{Kind: OpCopy, Position: 5, Head: 0, Tail: 10},
+			{Kind: OpLiteral, Position: 16, Value: []byte(", ")},
+		}
+
+		expectedJSON := `[{"kind":"copy","position":5,"head":0,"tail":10},{"kind":"literal","position":16,"value":"LCA="}]`
+		// Run
+		output, err := json.Marshal(ops)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedJSON, string(output))
+	})
+}
+
+func TestOpUnmarshalJSON(t *testing.T) {
+	t.Run("should decode Ops encoded by MarshalJSON", func(t *testing.T) {
+		// Setup
+		ops := []Op{
+			{Kind: OpCopy, Position: 5, Head: 0, Tail: 10},
+			{Kind: OpLiteral, Position: 16, Value: []byte(", ")},
+		}
+
+		encoded, _ := json.Marshal(ops)
+		decoded := []Op{}
+		// Run
+		err := json.Unmarshal(encoded, &decoded)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, ops, decoded)
+	})
+
+	t.Run("should return `InvalidOpKindError` when kind is unknown", func(t *testing.T) {
+		// Setup
+		var op Op
+		// Run
+		err := json.Unmarshal([]byte(`{"kind":"move","position":0}`), &op)
+		// Verify
+		require.Equal(t, constants.InvalidOpKindError, err.Error())
+	})
+}
+
+func TestOpBlock(t *testing.T) {
+	t.Run("should return matched block for copy Op", func(t *testing.T) {
+		// Setup
+		op := Op{Kind: OpCopy, Position: 3, Head: 6, Tail: 10}
+		// Run
+		block := op.Block()
+		// Verify
+		require.Equal(t, Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}}, block)
+		require.Equal(t, op, block.Op(3))
+	})
+
+	t.Run("should return modified block positioned in Updated file for literal Op", func(t *testing.T) {
+		// Setup
+		op := Op{Kind: OpLiteral, Position: 5, Value: []byte(", ")}
+		// Run
+		block := op.Block()
+		// Verify
+		require.Equal(t, Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")}, block)
+		require.Equal(t, op, block.Op(5))
+	})
+}
+
+func TestBlockOp(t *testing.T) {
+	t.Run("should return copy Op for matched block", func(t *testing.T) {
+		// Setup
+		block := Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}}
+		// Run
+		op := block.Op(3)
+		// Verify
+		require.Equal(t, Op{Kind: OpCopy, Position: 3, Head: 6, Tail: 10}, op)
+	})
+
+	t.Run("should return literal Op for modified block", func(t *testing.T) {
+		// Setup
+		block := Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")}
+		// Run
+		op := block.Op(5)
+		// Verify
+		require.Equal(t, Op{Kind: OpLiteral, Position: 5, Value: []byte(", ")}, op)
+	})
+}
+
+func TestDeltaOps(t *testing.T) {
+	t.Run("should visit each Op in output order", func(t *testing.T) {
+		// Setup
+		delta := Delta{
+			7: Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+			0: Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}},
+			5: Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")},
+		}
+
+		expectedOps := []Op{
+			{Kind: OpCopy, Position: 0, Head: 6, Tail: 10},
+			{Kind: OpLiteral, Position: 5, Value: []byte(", ")},
+			{Kind: OpCopy, Position: 7, Head: 0, Tail: 4},
+		}
+
+		ops := []Op{}
+		// Run
+		err := delta.Ops(func(op Op) error {
+			ops = append(ops, op)
+			return nil
+		})
+
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedOps, ops)
+	})
+
+	t.Run("should visit each Op in output order when positions exceed 4GB", func(t *testing.T) {
+		// Setup
+		large := int64(5) << 30
+		delta := Delta{
+			large + 1: Block{Head: large, Tail: large + 15, IsModified: false, Value: []byte{}},
+			large:     Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a'}},
+			0:         Block{Head: 0, Tail: large - 1, IsModified: false, Value: []byte{}},
+		}
+
+		expectedOps := []Op{
+			{Kind: OpCopy, Position: 0, Head: 0, Tail: large - 1},
+			{Kind: OpLiteral, Position: large, Value: []byte{'a'}},
+			{Kind: OpCopy, Position: large + 1, Head: large, Tail: large + 15},
+		}
+
+		ops := []Op{}
+		// Run
+		err := delta.Ops(func(op Op) error {
+			ops = append(ops, op)
+			return nil
+		})
+
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedOps, ops)
+		require.Equal(t, large, ops[0].Len())
+	})
+
+	t.Run("should stop visiting + return error returned by function", func(t *testing.T) {
+		// Setup
+		delta := Delta{
+			0: Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a'}},
+			1: Block{Head: 1, Tail: 1, IsModified: true, Value: []byte{'b'}},
+		}
+
+		expectedError := errors.New("Some Error")
+		visited := 0
+		// Run
+		err := delta.Ops(func(op Op) error {
+			visited++
+			return expectedError
+		})
+
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, visited)
+	})
+
+	t.Run("should not visit any Op when Delta is empty", func(t *testing.T) {
+		// Setup
+		visited := 0
+		// Run
+		err := Delta{}.Ops(func(op Op) error {
+			visited++
+			return nil
+		})
+
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 0, visited)
+	})
+}
+
+func TestDeltaWriteBlock(t *testing.T) {
+	t.Run("should add block to Delta at provided position", func(t *testing.T) {
+		// Setup
+		delta := Delta{}
+		block := Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}}
+		// Run
+		err := delta.WriteBlock(5, block)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, Delta{5: block}, delta)
+	})
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// signatureEntry type.
+// This will contain a single Signature item along with the Weak hash it is indexed by, so Signatures encode as an array of entries.
+// EG: {"weak":123,"hash":"some-strong-hash","head":0,"tail":15}.
+type signatureEntry struct {
+	Weak int64 `json:"weak"`
+	StrongSignature
+}
+
+// deltaEntry type.
+// This will contain a single Delta block along with the position it is indexed by, so Deltas encode as an array of entries.
+// EG: {"position":5,"head":0,"tail":4,"isModified":false,"value":""}.
+type deltaEntry struct {
+	Position int64 `json:"position"`
+	Block
+}
+
+// MarshalJSON() will encode a Signature as an array of entries sorted by Weak hash, with the Weak hash as an explicit field.
+// This avoids encoding int64 map keys as JSON object keys (EG strings), so the Signature round-trips exactly.
+// EG: [{"weak":123,"hash":"some-strong-hash","head":0,"tail":15}].
+func (signature Signature) MarshalJSON() ([]byte, error) {
+	entries := make([]signatureEntry, 0, len(signature))
+	for weakHash, item := range signature {
+		entries = append(entries, signatureEntry{Weak: weakHash, StrongSignature: item})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Weak < entries[j].Weak })
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON() will decode a Signature encoded by MarshalJSON().
+// Note: a later entry will replace an earlier entry with the same Weak hash.
+// Function returns `nil` when successful.
+// Function returns `error` when data is not a valid array of entries.
+func (signature *Signature) UnmarshalJSON(data []byte) error {
+	var entries []signatureEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	*signature = make(Signature, len(entries))
+	for _, entry := range entries {
+		(*signature)[entry.Weak] = entry.StrongSignature
+	}
+
+	return nil
+}
+
+// MarshalJSON() will encode a Delta as an array of entries sorted by position, with the position as an explicit field.
+// This avoids encoding int64 map keys as JSON object keys (EG strings), so the Delta round-trips exactly.
+// EG: [{"position":0,"head":0,"tail":4,"isModified":true,"value":"YWJjZGU="}].
+func (delta Delta) MarshalJSON() ([]byte, error) {
+	entries := make([]deltaEntry, 0, len(delta))
+	for position, block := range delta {
+		entries = append(entries, deltaEntry{Position: position, Block: block})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Position < entries[j].Position })
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON() will decode a Delta encoded by MarshalJSON().
+// Note: a later entry will replace an earlier entry with the same position.
+// Function returns `nil` when successful.
+// Function returns `error` when data is not a valid array of entries.
+func (delta *Delta) UnmarshalJSON(data []byte) error {
+	var entries []deltaEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	*delta = make(Delta, len(entries))
+	for _, entry := range entries {
+		(*delta)[entry.Position] = entry.Block
+	}
+
+	return nil
+}
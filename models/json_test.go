@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureJSON(t *testing.T) {
+	t.Run("should encode Signature as an array of entries sorted by Weak hash", func(t *testing.T) {
+		// Setup
+		signature := Signature{
+			456:  {Hash: "b", Head: 16, Tail: 31},
+			-123: {Hash: "a", LegacyHash: "c", Head: 0, Tail: 15, Candidates: []StrongSignature{{Hash: "d", Head: 32, Tail: 47}}},
+		}
+
+		expectedJSON := `[{"weak":-123,"hash":"a","legacyHash":"c","head":0,"tail":15,"candidates":[{"hash":"d","head":32,"tail":47}]},{"weak":456,"hash":"b","head":16,"tail":31}]`
+		// Run
+		data, err := json.Marshal(signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedJSON, string(data))
+	})
+
+	t.Run("should decode Signature encoded by MarshalJSON()", func(t *testing.T) {
+		// Setup
+		signature := Signature{
+			9223372036854775807: {Hash: "a", Head: 0, Tail: 15},
+			-1:                  {Hash: "b", Head: 16, Tail: 31, Candidates: []StrongSignature{{Hash: "b", Head: 48, Tail: 63}}},
+		}
+
+		data, err := json.Marshal(signature)
+		require.Equal(t, nil, err)
+		// Run
+		var result Signature
+		err = json.Unmarshal(data, &result)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, signature, result)
+	})
+
+	t.Run("should return `error` when data is not an array of entries", func(t *testing.T) {
+		// Run
+		var result Signature
+		err := json.Unmarshal([]byte(`{"123":{"hash":"a"}}`), &result)
+		// Verify
+		require.NotEqual(t, nil, err)
+	})
+}
+
+func TestDeltaJSON(t *testing.T) {
+	t.Run("should encode Delta as an array of entries sorted by position", func(t *testing.T) {
+		// Setup
+		delta := Delta{
+			5: {Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+			0: {Head: 0, Tail: 4, IsModified: true, Value: []byte("abcde")},
+		}
+
+		expectedJSON := `[{"position":0,"head":0,"tail":4,"isModified":true,"value":"YWJjZGU="},{"position":5,"head":0,"tail":4,"isModified":false,"value":""}]`
+		// Run
+		data, err := json.Marshal(delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedJSON, string(data))
+	})
+
+	t.Run("should decode Delta encoded by MarshalJSON()", func(t *testing.T) {
+		// Setup
+		delta := Delta{
+			0:  {Head: 0, Tail: 4, IsModified: true, Value: []byte("abcde")},
+			5:  {Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+			10: {Head: 20, Tail: 29, IsModified: false, Value: nil},
+		}
+
+		data, err := json.Marshal(delta)
+		require.Equal(t, nil, err)
+		// Run
+		var result Delta
+		err = json.Unmarshal(data, &result)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `error` when data is not an array of entries", func(t *testing.T) {
+		// Run
+		var result Delta
+		err := json.Unmarshal([]byte(`{"0":{"head":0}}`), &result)
+		// Verify
+		require.NotEqual(t, nil, err)
+	})
+}
@@ -1,24 +1,102 @@
 package models
 
+import "time"
+
 // CMD type.
 // This will contain the CMD Flags set by user.
 type CMD struct {
-	Verbose       bool   `json:"verbose"`
-	SignatureMode bool   `json:"signatureMode"`
-	DeltaMode     bool   `json:"deltaMode"`
-	OriginalFile  string `json:"originalFile"`
-	SignatureFile string `json:"signatureFile"`
-	UpdatedFile   string `json:"updatedFile"`
-	DeltaFile     string `json:"deltaFile"`
+	Verbose       bool      `json:"verbose"`
+	SignatureMode bool      `json:"signatureMode"`
+	DeltaMode     bool      `json:"deltaMode"`
+	OriginalFile  string    `json:"originalFile"`
+	SignatureFile string    `json:"signatureFile"`
+	UpdatedFile   string    `json:"updatedFile"`
+	DeltaFile     string    `json:"deltaFile"`
+	BenchMode     bool      `json:"benchMode"`
+	BenchSize     int       `json:"benchSize"`
+	SimulateMode  bool      `json:"simulateMode"`
+	Simulations   int       `json:"simulations"`
+	Mutations     Mutations `json:"mutations"`
+	Seed          int64     `json:"seed"`
+	SelftestMode  bool      `json:"selftestMode"`
+	Yes           bool      `json:"yes"`
+	NoColor       bool      `json:"noColor"`
+	Progress      bool      `json:"progress"`
+	Quiet         bool      `json:"quiet"`
+	SummaryJSON   string    `json:"summaryJSON"`
+	LogEvery      int       `json:"logEvery"`
+	LogRate       int       `json:"logRate"`
+	GitDiffDriver bool      `json:"gitDiffDriver"`
+	Args          []string  `json:"args"`
+	StrongHash    string    `json:"strongHash"`
+	WeakHash      string    `json:"weakHash"`
+	Strict        bool      `json:"strict"`
+	AnalyzeMode   bool      `json:"analyzeMode"`
+	Background    bool      `json:"background"`
+	DeltaCache    string    `json:"deltaCache"`
+	StatsMode     bool      `json:"statsMode"`
+	DeltaFormat   string    `json:"deltaFormat"`
+	FilesFrom     string    `json:"filesFrom"`
+	PatchReport   string    `json:"patchReport"`
+	CatSignature  bool      `json:"catSignature"`
+	DirMode       string    `json:"dirMode"`
+	FileMode      string    `json:"fileMode"`
+	KeepPartial   bool      `json:"keepPartial"`
+	FullCopy      bool      `json:"fullCopy"`
+	MinSimilarity int       `json:"minSimilarity"`
+	Similarity    bool      `json:"similarityMode"`
+	LegacyHash    string    `json:"legacyStrongHash"`
+	ConvertMode   bool      `json:"convertMode"`
+	ConvertTo     string    `json:"convertTo"`
+	ReadRetries   int       `json:"readRetries"`
+	MemStats      bool      `json:"memStats"`
+	Range         string    `json:"range"`
+	MinSize       int64     `json:"minSize"`
+	MaxSize       int64     `json:"maxSize"`
+	NewerThan     string    `json:"newerThan"`
+	BatchWorkers  int       `json:"batchWorkers"`
+	GenMode       bool      `json:"genMode"`
+	GenSize       int64     `json:"genSize"`
+	Pattern       string    `json:"pattern"`
+	Chaos         float64   `json:"chaos"`
+	Capture       string    `json:"capture"`
+	CaptureLimit  int64     `json:"captureLimit"`
+	CaptureHashes bool      `json:"captureHashOnly"`
+	Replay        string    `json:"replay"`
+	AuditLog      string    `json:"auditLog"`
+	Stride        int       `json:"signatureStride"`
+	MaxEntries    int64     `json:"maxSignatureEntries"`
+	Sparse        int       `json:"sparse"`
+	Refine        bool      `json:"refine"`
+	FineChunk     int64     `json:"fineChunk"`
+	Doctor        bool      `json:"doctor"`
+	NoSpaceCheck  bool      `json:"noSpaceCheck"`
+	TempDirs      string    `json:"tempDirs"`
+	PatchMode     bool      `json:"patchMode"`
+	OutputFile    string    `json:"outputFile"`
+	Chunk         int64     `json:"chunk"`
+	Encoding      string    `json:"encoding"`
+	StreamDelta   bool      `json:"streamDelta"`
+	StreamSig     bool      `json:"streamSignature"`
+	Compress      string    `json:"compress"`
+	Workers       int       `json:"signatureWorkers"`
+	Remote        string    `json:"remote"`
+	RemoteBin     string    `json:"remoteBin"`
+	SSHKey        string    `json:"sshKey"`
+	KnownHosts    string    `json:"knownHosts"`
 }
 
 // StrongSignature type.
 // This will be used to contain a SHA-256 hash of the block of data, as well as the Head and Tail position of the bytes in the Original file (EG position of first + last characters).
+// Candidates will contain earlier blocks of the Original file which share the same Weak hash, so Delta generation can prefer contiguous matches.
+// LegacyHash will contain a hash of the block from a second Strong hash algorithm (EG while migrating algorithms), or be empty.
 // EG: StrongSignature{Hash: "some-strong-hash", Head: 0, Tail: 15}.
 type StrongSignature struct {
-	Hash string `json:"hash"`
-	Head int    `json:"head"`
-	Tail int    `json:"tail"`
+	Hash       string            `json:"hash"`
+	LegacyHash string            `json:"legacyHash,omitempty"`
+	Head       int64             `json:"head"`
+	Tail       int64             `json:"tail"`
+	Candidates []StrongSignature `json:"candidates,omitempty"`
 }
 
 // Signature type.
@@ -28,6 +106,28 @@ type StrongSignature struct {
 // signature[456]{Hash: "another-strong-hash", Head: 0, Tail: 15}.
 type Signature map[int64]StrongSignature
 
+// DeltaMetadata type.
+// This will describe the Updated file a Delta was generated from, so patching can verify the reconstructed output.
+// Metadata will be encoded after the Delta within gob Delta files, so older readers (which only decode the Delta) can still read them.
+// UpdatedHash will be the hex encoded SHA-256 of the complete Updated file, or empty for Delta files generated before it was recorded (EG patching will not be verified).
+// EG: DeltaMetadata{UpdatedHash: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"}.
+type DeltaMetadata struct {
+	UpdatedHash string `json:"updatedHash,omitempty"`
+}
+
+// SignatureMetadata type.
+// This will describe the settings a Signature was generated with, so Deltas can be generated with the same settings.
+// Metadata will be encoded after the Signature within Signature files, so older readers (which only decode the Signature) can still read them.
+// ChunkSize will be 0 for Signature files generated before metadata was recorded (EG the default chunk size).
+// WeakHash will be the name of the Weak hash algorithm (EG "adler32"), or empty for Signature files generated before it was recorded.
+// StrongHash will be the name of the Strong hash algorithm for formats which define it (EG "blake2b" for rdiff Signature files), otherwise empty.
+// EG: SignatureMetadata{ChunkSize: 4096, WeakHash: "rabin-karp"}.
+type SignatureMetadata struct {
+	ChunkSize  int64  `json:"chunkSize"`
+	WeakHash   string `json:"weakHash,omitempty"`
+	StrongHash string `json:"strongHash,omitempty"`
+}
+
 // Block type.
 // This will be used to store the data for each block to be written to final output file (after patch).
 // A matching block from Signature file will use Head + Tail to define the blocks position within the Signature file (EG position of first + last characters).
@@ -36,8 +136,8 @@ type Signature map[int64]StrongSignature
 // EG: Block{Head: 0, Tail: 4, IsModified: true, Value: []bytes{'a', 'b', 'c', 'd', 'e'}}.
 
 type Block struct {
-	Head       int    `json:"head"`
-	Tail       int    `json:"tail"`
+	Head       int64  `json:"head"`
+	Tail       int64  `json:"tail"`
 	IsModified bool   `json:"isModified"`
 	Value      []byte `json:"value"`
 }
@@ -47,4 +147,110 @@ type Block struct {
 // EG:
 // delta[0]{Head: 0, Tail: 4, IsModified: true, Value: []bytes{'a', 'b', 'c', 'd', 'e'}}.
 // delta[5]{Head: 0, Tail: 4, IsModified: false, Value: []bytes{}}.
-type Delta map[int]Block
+type Delta map[int64]Block
+
+// BenchmarkResult type.
+// This will contain the throughput + allocation stats recorded for a single phase of Benchmark mode.
+// EG: BenchmarkResult{Phase: "Signature", Bytes: 1048576, Duration: 2s, Allocations: 1024, AllocatedBytes: 65536}.
+type BenchmarkResult struct {
+	Phase          string        `json:"phase"`
+	Bytes          int64         `json:"bytes"`
+	Duration       time.Duration `json:"duration"`
+	Allocations    uint64        `json:"allocations"`
+	AllocatedBytes uint64        `json:"allocatedBytes"`
+}
+
+// Mutations type.
+// This will define the number of random mutations applied to a file by the Simulate mode.
+// MaxSize will limit the number of bytes inserted, deleted, or moved by each mutation.
+// EG: Mutations{Insertions: 2, Deletions: 1, Moves: 1, MaxSize: 64}.
+type Mutations struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+	Moves      int `json:"moves"`
+	MaxSize    int `json:"maxSize"`
+}
+
+// Summary type.
+// This will contain a machine-readable summary of a run, written when the `-summaryJSON` flag is set.
+// Durations will contain the time (ms) spent in each phase. EG: {"signature": 12, "delta": 30}.
+// ExitStatus will be `0` when successful, `1` when the run failed, or `2` when invalid CMD flags were provided.
+type Summary struct {
+	Mode         string           `json:"mode"`
+	Inputs       []SummaryFile    `json:"inputs"`
+	Outputs      []SummaryFile    `json:"outputs"`
+	MatchedBytes int64            `json:"matchedBytes"`
+	LiteralBytes int64            `json:"literalBytes"`
+	Durations    map[string]int64 `json:"durationsMs"`
+	ExitStatus   int              `json:"exitStatus"`
+	Error        string           `json:"error,omitempty"`
+	Memory       *MemoryStats     `json:"memory,omitempty"`
+	Skipped      []SkippedFile    `json:"skipped,omitempty"`
+}
+
+// Capture type.
+// This will contain the input streams read during a run (EG Original + Updated files), so Delta generation can be replayed from a bug report.
+// EG: Capture{Streams: []CaptureStream{{Name: "original", ...}, {Name: "updated", ...}}}.
+type Capture struct {
+	Streams []CaptureStream `json:"streams"`
+}
+
+// CaptureStream type.
+// This will contain the SHA256 hash + size of every byte read from an input stream, and the bytes themselves (up to the capture limit).
+// Data will be empty when captured with `-captureHashOnly`, and Truncated will be true when the stream was larger than the capture limit.
+// EG: CaptureStream{Name: "updated", Path: "v2/app.bin", Size: 1024, Hash: "some-sha256-hash", Data: []byte{...}}.
+type CaptureStream struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Hash      string `json:"hash"`
+	Data      []byte `json:"data,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// SkippedFile type.
+// This will contain the path of a file skipped by Batch mode filters, and the reason it was skipped.
+// EG: SkippedFile{Path: "v2/app.iso", Reason: "larger than -maxSize (1048576 bytes)"}.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// MemoryStats type.
+// This will contain the peak memory usage of a run, and the (approximate) sizes of its major structures.
+// EG: the Signature map held in memory, and the literal bytes held by a pending Delta.
+type MemoryStats struct {
+	PeakHeap         uint64 `json:"peakHeapBytes"`
+	PeakRSS          int64  `json:"peakRSSBytes"`
+	SignatureEntries int    `json:"signatureEntries"`
+	SignatureBytes   int64  `json:"signatureBytes"`
+	LiteralBytes     int64  `json:"deltaLiteralBytes"`
+}
+
+// SummaryFile type.
+// This will contain the role (EG `original`), path, and size (bytes) of a file read or written during a run.
+type SummaryFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// BsdiffControl type.
+// This will contain a single control of a bsdiff patch.
+// Add bytes of the diff block will be added to the Original file (from the current position), then Extra bytes of the extra block will be appended, before moving the Original file position by Seek bytes.
+// EG: BsdiffControl{Add: 16, Extra: 4, Seek: -8}.
+type BsdiffControl struct {
+	Add   int64
+	Extra int64
+	Seek  int64
+}
+
+// BsdiffPatch type.
+// This will contain a decoded bsdiff patch (see files.DecodeBsdiff()), which can be applied to the Original file with sync.BsdiffDelta().
+// EG: BsdiffPatch{Controls: []BsdiffControl{{Add: 3, Extra: 1}}, Diff: []byte{0, 0, 0}, Extra: []byte{'!'}, Size: 4}.
+type BsdiffPatch struct {
+	Controls []BsdiffControl
+	Diff     []byte
+	Extra    []byte
+	Size     int64
+}
@@ -0,0 +1,47 @@
+package models
+
+// MaxCandidates will be the max earlier blocks stored per Weak hash of a Signature while any of them repeat content (see Signature.WriteEntry()).
+// Note: blocks with different content (EG a different Strong hash) sharing a Weak hash are always kept, so only repeated content is limited.
+const MaxCandidates int = 8
+
+// WriteEntry() will add a block to the Signature, indexed by its Weak hash.
+// When the Weak hash already exists, the existing block will be kept as a candidate of the new block (see dropCandidate() once more than `MaxCandidates` are kept).
+// This allows a Signature to be used as an in-memory collector when streaming Signature generation (EG rebuilding a streamed Signature file).
+// Note: the latest block is always stored at the top level, so older Signature readers see the same item as before candidates were added.
+// Function returns `nil` as adding to a map cannot fail.
+func (signature Signature) WriteEntry(weakHash int64, item StrongSignature) error {
+	if previous, exists := signature[weakHash]; exists {
+		candidates := previous.Candidates
+		previous.Candidates = nil
+		item.Candidates = append(candidates, previous)
+		if len(item.Candidates) > MaxCandidates {
+			item.Candidates = dropCandidate(item.Candidates, item.Hash)
+		}
+	}
+
+	signature[weakHash] = item
+	return nil
+}
+
+// dropCandidate() will remove the oldest candidate repeating the content of a later block (EG the same Strong hash), so earlier positions of repeated content are limited to `MaxCandidates`.
+// Note: candidates will be returned unchanged when every candidate has different content, so blocks with different content sharing a Weak hash are never lost.
+func dropCandidate(candidates []StrongSignature, latestHash string) []StrongSignature {
+	drop := -1
+	for index, candidate := range candidates {
+		repeated := candidate.Hash == latestHash
+		for _, later := range candidates[index+1:] {
+			repeated = repeated || later.Hash == candidate.Hash
+		}
+
+		if repeated {
+			drop = index
+			break
+		}
+	}
+
+	if drop < 0 {
+		return candidates
+	}
+
+	return append(candidates[:drop:drop], candidates[drop+1:]...)
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureWriteEntry(t *testing.T) {
+	t.Run("should keep existing block as candidate when Weak hash already exists", func(t *testing.T) {
+		// Setup
+		signature := Signature{}
+		first := StrongSignature{Hash: "some-hash", Head: 0, Tail: 15}
+		second := StrongSignature{Hash: "another-hash", Head: 16, Tail: 31}
+		// Run
+		firstErr := signature.WriteEntry(1, first)
+		secondErr := signature.WriteEntry(1, second)
+		// Verify
+		require.Equal(t, nil, firstErr)
+		require.Equal(t, nil, secondErr)
+		require.Equal(t, Signature{1: {Hash: "another-hash", Head: 16, Tail: 31, Candidates: []StrongSignature{first}}}, signature)
+	})
+
+	t.Run("should keep at most `MaxCandidates` earlier blocks", func(t *testing.T) {
+		// Setup
+		signature := Signature{}
+		// Run
+		for index := 0; index <= MaxCandidates+1; index++ {
+			require.Equal(t, nil, signature.WriteEntry(1, StrongSignature{Hash: "some-hash", Head: int64(index), Tail: int64(index) + 15}))
+		}
+
+		// Verify
+		require.Equal(t, MaxCandidates, len(signature[1].Candidates))
+		require.Equal(t, int64(1), signature[1].Candidates[0].Head)
+	})
+
+	t.Run("should keep every block with different content when more than `MaxCandidates` share a Weak hash", func(t *testing.T) {
+		// Setup
+		signature := Signature{}
+		blocks := MaxCandidates * 2
+		// Run
+		for index := 0; index < blocks; index++ {
+			require.Equal(t, nil, signature.WriteEntry(1, StrongSignature{Hash: fmt.Sprintf("hash-%d", index), Head: int64(index) * 16, Tail: int64(index)*16 + 15}))
+		}
+
+		// Verify
+		require.Equal(t, blocks-1, len(signature[1].Candidates))
+		for index, candidate := range signature[1].Candidates {
+			require.Equal(t, fmt.Sprintf("hash-%d", index), candidate.Hash)
+		}
+	})
+
+	t.Run("should drop repeated content before blocks with different content once more than `MaxCandidates` share a Weak hash", func(t *testing.T) {
+		// Setup
+		signature := Signature{}
+		// Run
+		for index := 0; index <= MaxCandidates*2; index++ {
+			hash := fmt.Sprintf("hash-%d", index)
+			if index%2 == 1 {
+				hash = "repeated-hash"
+			}
+
+			require.Equal(t, nil, signature.WriteEntry(1, StrongSignature{Hash: hash, Head: int64(index) * 16, Tail: int64(index)*16 + 15}))
+		}
+
+		// Verify
+		hashes := []string{}
+		for _, candidate := range signature[1].Candidates {
+			hashes = append(hashes, candidate.Hash)
+		}
+
+		// Only the latest position of the repeated content is kept, along with every other block
+		require.Equal(t, []string{"hash-0", "hash-2", "hash-4", "hash-6", "hash-8", "hash-10", "hash-12", "hash-14", "repeated-hash"}, hashes)
+		require.Equal(t, int64(15*16), signature[1].Candidates[MaxCandidates].Head)
+	})
+}
@@ -0,0 +1,65 @@
+package mutate
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+const (
+	maxRun       int = 256 // Max length of each run of repeated bytes in compressible data
+	wordsPerLine int = 12  // Words written to each line of text data
+)
+
+// Words used to fill text data
+var words = []string{"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog", "file", "delta", "signature", "block", "chunk", "hash", "patch", "sync"}
+
+// compressible() will fill data with runs of between 1 and maxRun repeated random bytes.
+func compressible(data []byte, random Random) {
+	value := make([]byte, 1)
+	for position := 0; position < len(data); {
+		_, _ = random.Read(value)
+		run := size(maxRun, random)
+		for ; run > 0 && position < len(data); run-- {
+			data[position] = value[0]
+			position++
+		}
+	}
+}
+
+// Generate() will create size bytes of synthetic data filled with the provided content pattern.
+// EG: `random` (incompressible bytes), `compressible` (runs of repeated bytes), or `text` (lines of space separated words).
+// Data is generated from the provided random source, so the same seed will always produce the same data.
+// Function returns `data, nil` when successful.
+// Function returns `nil, InvalidPatternError` when pattern is unknown.
+func Generate(size int, pattern string, random Random) ([]byte, error) {
+	data := make([]byte, size)
+	switch pattern {
+	case constants.PatternRandom:
+		_, _ = random.Read(data)
+	case constants.PatternCompressible:
+		compressible(data, random)
+	case constants.PatternText:
+		text(data, random)
+	default:
+		return nil, errors.New(constants.InvalidPatternError)
+	}
+
+	return data, nil
+}
+
+// text() will fill data with lines of wordsPerLine random words, separated by spaces.
+// Note: the final word will be cut short when data is full.
+func text(data []byte, random Random) {
+	line := []byte{}
+	for position, count := 0, 0; position < len(data); count++ {
+		line = append(line[:0], words[random.Intn(len(words))]...)
+		if (count+1)%wordsPerLine == 0 {
+			line = append(line, '\n')
+		} else {
+			line = append(line, ' ')
+		}
+
+		position += copy(data[position:], line)
+	}
+}
@@ -0,0 +1,63 @@
+package mutate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("should return random data when pattern is random", func(t *testing.T) {
+		// Setup
+		random := &randomMock{fill: 'x'}
+		// Run
+		data, err := Generate(4, constants.PatternRandom, random)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte("xxxx"), data)
+	})
+
+	t.Run("should return runs of repeated bytes when pattern is compressible", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{2, 4}, fill: 'y'}
+		// Run
+		data, err := Generate(6, constants.PatternCompressible, random)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte("yyyyyy"), data)
+		require.Equal(t, 0, len(random.values))
+	})
+
+	t.Run("should return lines of words when pattern is text", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{0, 1, 2}}
+		// Run
+		data, err := Generate(14, constants.PatternText, random)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte("the quick brow"), data)
+	})
+
+	t.Run("should return `nil, InvalidPatternError` when pattern is unknown", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidPatternError)
+		// Run
+		data, err := Generate(4, "zeros", &randomMock{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, data)
+	})
+
+	t.Run("should return the same data for the same seed", func(t *testing.T) {
+		for _, pattern := range []string{constants.PatternRandom, constants.PatternCompressible, constants.PatternText} {
+			// Run
+			first, _ := Generate(1024, pattern, NewRandom(7))
+			second, _ := Generate(1024, pattern, NewRandom(7))
+			// Verify
+			require.Equal(t, first, second)
+			require.Equal(t, 1024, len(first))
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package mutate
+
+import (
+	"math/rand"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Random interface for mocking rand.Rand.
+type Random interface {
+	Intn(n int) int
+	Read(p []byte) (int, error)
+}
+
+// insert() will insert between 1 and maxSize random bytes at a random position in the provided data.
+// Function returns `updatedData`.
+func insert(data []byte, maxSize int, random Random) []byte {
+	value := make([]byte, size(maxSize, random))
+	_, _ = random.Read(value)
+	position := random.Intn(len(data) + 1)
+	updated := make([]byte, 0, len(data)+len(value))
+	updated = append(updated, data[:position]...)
+	updated = append(updated, value...)
+	return append(updated, data[position:]...)
+}
+
+// move() will remove a block of between 1 and maxSize bytes and re-insert it at a random position in the provided data.
+// Function returns `updatedData`.
+// Note: data will be returned unchanged when it is too small to contain a block to move.
+func move(data []byte, maxSize int, random Random) []byte {
+	if len(data) < 2 {
+		return data
+	}
+
+	length := size(maxSize, random)
+	if length >= len(data) {
+		length = len(data) - 1
+	}
+
+	// Cut block from data
+	head := random.Intn(len(data) - length + 1)
+	block := make([]byte, length)
+	copy(block, data[head:head+length])
+	remaining := make([]byte, 0, len(data))
+	remaining = append(remaining, data[:head]...)
+	remaining = append(remaining, data[head+length:]...)
+	// Re-insert block at new position
+	position := random.Intn(len(remaining) + 1)
+	updated := make([]byte, 0, len(data))
+	updated = append(updated, remaining[:position]...)
+	updated = append(updated, block...)
+	return append(updated, remaining[position:]...)
+}
+
+// Mutate() will apply random insertions, deletions, and block moves to a copy of the provided data.
+// Mutations are applied in order: insertions; deletions; moves;
+// Function returns `mutatedData`, leaving the provided data unmodified.
+func Mutate(data []byte, mutations models.Mutations, random Random) []byte {
+	mutated := make([]byte, len(data))
+	copy(mutated, data)
+	for count := 0; count < mutations.Insertions; count++ {
+		mutated = insert(mutated, mutations.MaxSize, random)
+	}
+
+	for count := 0; count < mutations.Deletions; count++ {
+		mutated = remove(mutated, mutations.MaxSize, random)
+	}
+
+	for count := 0; count < mutations.Moves; count++ {
+		mutated = move(mutated, mutations.MaxSize, random)
+	}
+
+	return mutated
+}
+
+// NewRandom() will init and return a new seeded random source.
+// Returned random source will satisfy the `Random` interface.
+func NewRandom(seed int64) Random {
+	return rand.New(rand.NewSource(seed))
+}
+
+// remove() will delete a block of between 1 and maxSize bytes from a random position in the provided data.
+// Function returns `updatedData`.
+// Note: data will be returned unchanged when empty.
+func remove(data []byte, maxSize int, random Random) []byte {
+	if len(data) == 0 {
+		return data
+	}
+
+	length := size(maxSize, random)
+	if length > len(data) {
+		length = len(data)
+	}
+
+	head := random.Intn(len(data) - length + 1)
+	updated := make([]byte, 0, len(data)-length)
+	updated = append(updated, data[:head]...)
+	return append(updated, data[head+length:]...)
+}
+
+// size() will return a random mutation size between 1 and maxSize (inclusive).
+// Note: maxSize values below 1 will be treated as 1.
+func size(maxSize int, random Random) int {
+	if maxSize < 1 {
+		return 1
+	}
+
+	return random.Intn(maxSize) + 1
+}
@@ -0,0 +1,135 @@
+package mutate
+
+import (
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for Random interface which returns values from a fixed sequence
+type randomMock struct {
+	// Set test props
+	values []int
+	fill   byte
+}
+
+// Overwrite randomMock.Intn() to return the next value in the sequence (bounded by n)
+func (r *randomMock) Intn(n int) int {
+	value := r.values[0]
+	r.values = r.values[1:]
+	return value % n
+}
+
+// Overwrite randomMock.Read() to fill p with test prop
+func (r *randomMock) Read(p []byte) (int, error) {
+	for index := range p {
+		p[index] = r.fill
+	}
+
+	return len(p), nil
+}
+
+func TestInsert(t *testing.T) {
+	t.Run("should return data with random bytes inserted at random position", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{1, 2}, fill: 'x'}
+		// Run
+		updated := insert([]byte("abcd"), 4, random)
+		// Verify
+		require.Equal(t, []byte("abxxcd"), updated)
+	})
+
+	t.Run("should return inserted bytes when data is empty", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{0, 0}, fill: 'x'}
+		// Run
+		updated := insert([]byte{}, 4, random)
+		// Verify
+		require.Equal(t, []byte("x"), updated)
+	})
+}
+
+func TestMove(t *testing.T) {
+	t.Run("should return data with block moved to random position", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{1, 0, 4}}
+		// Run
+		updated := move([]byte("abcdef"), 4, random)
+		// Verify
+		require.Equal(t, []byte("cdefab"), updated)
+	})
+
+	t.Run("should return data unchanged when data is too small to move a block", func(t *testing.T) {
+		// Setup
+		random := &randomMock{}
+		// Run
+		updated := move([]byte("a"), 4, random)
+		// Verify
+		require.Equal(t, []byte("a"), updated)
+	})
+}
+
+func TestMutate(t *testing.T) {
+	t.Run("should return mutated copy of data without modifying the provided data", func(t *testing.T) {
+		// Setup
+		data := []byte("some file contents")
+		mutations := models.Mutations{Insertions: 2, Deletions: 2, Moves: 2, MaxSize: 4}
+		// Run
+		mutated := Mutate(data, mutations, NewRandom(1))
+		anotherMutated := Mutate(data, mutations, NewRandom(1))
+		// Verify
+		require.Equal(t, []byte("some file contents"), data)
+		require.NotEqual(t, data, mutated)
+		require.Equal(t, mutated, anotherMutated)
+	})
+
+	t.Run("should return copy of data when no mutations requested", func(t *testing.T) {
+		// Setup
+		data := []byte("some file contents")
+		// Run
+		mutated := Mutate(data, models.Mutations{}, NewRandom(1))
+		// Verify
+		require.Equal(t, data, mutated)
+	})
+}
+
+func TestRemove(t *testing.T) {
+	t.Run("should return data with block removed from random position", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{1, 2}}
+		// Run
+		updated := remove([]byte("abcdef"), 4, random)
+		// Verify
+		require.Equal(t, []byte("abef"), updated)
+	})
+
+	t.Run("should return data unchanged when data is empty", func(t *testing.T) {
+		// Setup
+		random := &randomMock{}
+		// Run
+		updated := remove([]byte{}, 4, random)
+		// Verify
+		require.Equal(t, []byte{}, updated)
+	})
+}
+
+func TestSize(t *testing.T) {
+	t.Run("should return random size between 1 and maxSize", func(t *testing.T) {
+		// Setup
+		random := &randomMock{values: []int{3}}
+		// Run
+		result := size(4, random)
+		// Verify
+		require.Equal(t, 4, result)
+	})
+
+	t.Run("should return 1 when maxSize is below 1", func(t *testing.T) {
+		// Setup
+		random := &randomMock{}
+		// Run
+		result := size(0, random)
+		// Verify
+		require.Equal(t, 1, result)
+	})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// readPatchDelta() will read the Delta applied by Patch mode, detecting whether it is gob, JSON Lines, rdiff or bsdiff encoded.
+// Delta will be read from the `-delta` file, or from the Outputs folder when Delta mode streamed it there as JSON Lines.
+// Note: bsdiff patches will be converted into a Delta against the Original file (see readBsdiffDelta()).
+// Note: metadata will only be recorded by gob Delta files (see files.OpenDeltaMetadata()), so will be empty for other formats.
+// Function returns `delta, metadata, nil` when successful.
+// Function returns `emptyDelta, emptyMetadata, UnableToOpenDeltaFileError` when unable to read the Delta file.
+// Function returns `emptyDelta, emptyMetadata, error` when unable to decode the Delta file.
+func readPatchDelta(cmd models.CMD) (models.Delta, models.DeltaMetadata, error) {
+	path := patchDeltaPath(cmd)
+	data, err := readFile(path)
+	if err != nil {
+		return models.Delta{}, models.DeltaMetadata{}, errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	var delta models.Delta
+	if isJSONL(data) {
+		delta, err = decodeDeltaJSONL(data)
+	} else if bytes.HasPrefix(data, []byte(files.BsdiffMagic)) {
+		delta, err = readBsdiffDelta(cmd, data)
+	} else {
+		return openDeltaMeta(path, cmd.Verbose)
+	}
+
+	return delta, models.DeltaMetadata{}, err
+}
+
+// patchDeltaPath() will return the path of the Delta file applied by Patch mode (EG in the Outputs folder when generated by Delta mode).
+func patchDeltaPath(cmd models.CMD) string {
+	if cmd.DeltaMode {
+		return outputPath(cmd.DeltaFile)
+	}
+
+	return cmd.DeltaFile
+}
+
+// readBsdiffDelta() will decode a bsdiff patch (EG generated by `bsdiff`), converting it into a Delta against the Original file (see sync.BsdiffDelta()).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UnableToReadOriginalFileError` when unable to open or read the Original file.
+// Function returns `emptyDelta, error` when unable to decode the patch, or the patch is corrupt.
+func readBsdiffDelta(cmd models.CMD, data []byte) (models.Delta, error) {
+	patch, err := files.DecodeBsdiff(bytes.NewReader(data))
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	original, err := openFileAt(cmd.OriginalFile)
+	if err != nil {
+		return models.Delta{}, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	defer original.Close()
+	return sync.BsdiffDelta(original, patch)
+}
+
+// runPatch() will apply a Delta to the Original file, writing the reconstructed Updated file to the `-output` file in the Outputs folder (see writePatch()).
+// The Delta generated by Delta mode will be applied when provided, otherwise it will be read from the Delta file (see readPatchDelta()).
+// Note: output will be written to a `.partial` file, which is renamed into place once complete.
+// Function returns `nil` when successful.
+// Function returns `OverwriteDeclinedError` when user declines overwriting an existing output file.
+// Function returns `UnableToReadOriginalFileError` when unable to open the Original file.
+// Function returns `UnableToCreateFileError` when unable to create the output file.
+// Function returns `UnableToWriteOutputFileError` when unable to rename the output file into place.
+// Note: streamed Delta files will be applied one block at a time as they are scanned (see scanPatchDelta()).
+// Note: output will be verified against the SHA-256 of the Updated file when recorded by the Delta file (see verifyPatch()).
+// Function returns `PatchedFileHashMismatchError` when output does not match the SHA-256 of the Updated file recorded by the Delta file.
+// Function returns `error` when unable to read the Delta file, or in the same cases as writePatch().
+func runPatch(cmd models.CMD, signature models.Signature, delta models.Delta) error {
+	var err error
+	var metadata models.DeltaMetadata
+	var scanner *files.DeltaScanner
+	if delta == nil && scanPatchDelta(cmd) {
+		scanner, err = openDeltaScanner(patchDeltaPath(cmd), cmd.Verbose)
+		if err != nil {
+			return err
+		}
+
+		defer scanner.Close()
+	} else if delta == nil {
+		delta, metadata, err = readPatchDelta(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = confirmOverwrite(cmd, cmd.OutputFile); err != nil {
+		return err
+	}
+
+	original, err := openFileAt(cmd.OriginalFile)
+	if err != nil {
+		return errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	defer original.Close()
+	file, err := createOutputFile(cmd.OutputFile)
+	if err != nil {
+		return err
+	}
+
+	// Hash output as it is written, so it can be verified against the Updated file
+	updatedHash := sha256.New()
+	output := io.MultiWriter(file, updatedHash)
+	if scanner != nil {
+		err = writeScannedPatch(cmd, scanner, original, output)
+		metadata = scanner.Metadata()
+	} else {
+		err = writePatch(cmd, signature, delta, original, output)
+	}
+
+	if err == nil {
+		err = verifyPatch(cmd, metadata, hex.EncodeToString(updatedHash.Sum(nil)))
+	}
+
+	file.Close()
+	if finishErr := finishPartial(outputPath(cmd.OutputFile), err != nil); err == nil && finishErr != nil {
+		err = errors.New(constants.UnableToWriteOutputFileError)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("%s created: %s\n", cmd.OutputFile, outputPath(cmd.OutputFile)), true)
+	return nil
+}
+
+// scanPatchDelta() will check whether Patch mode can apply the Delta file one block at a time as it is scanned (see files.DeltaScanner), rather than decoding the full Delta first.
+// Only streamed Delta files (see `-streamDelta`) will be scanned, as other Delta files are decoded in full, and `-strict`, `-patchReport` + `-auditLog` need the full Delta.
+func scanPatchDelta(cmd models.CMD) bool {
+	return !cmd.Strict && cmd.PatchReport == "" && cmd.AuditLog == "" && isDeltaStream(patchDeltaPath(cmd))
+}
+
+// verifyPatch() will compare the SHA-256 of the patched output with the SHA-256 of the Updated file recorded by the Delta file (see models.DeltaMetadata).
+// Function returns `nil` when hashes match, or when no hash is recorded (EG Delta files generated before it was recorded, or formats other than gob).
+// Function returns `PatchedFileHashMismatchError` when hashes do not match (EG corrupted Delta, or patching a different Original file).
+func verifyPatch(cmd models.CMD, metadata models.DeltaMetadata, patchedHash string) error {
+	if metadata.UpdatedHash == "" {
+		return nil
+	}
+
+	if patchedHash != metadata.UpdatedHash {
+		return errors.New(constants.PatchedFileHashMismatchError)
+	}
+
+	logger(fmt.Sprintf("Patched file matches SHA-256 of Updated file: %s\n", patchedHash), cmd.Verbose)
+	return nil
+}
+
+// writeScannedPatch() will apply the operations of a scanned Delta file to the Original file as they are decoded, writing the reconstructed Updated file to the provided file.
+// Note: progress will be reported without a total, as the size of the Updated file is unknown until every block has been scanned.
+// Function returns `nil` when successful.
+// Function returns `UnableToWriteOutputFileError` when unable to write to the patched file.
+// Function returns `UnableToDecodeDeltaFromFileError` when unable to decode a block of the Delta file (EG corrupted file).
+// Function returns `error` when unable to apply Delta to Original file.
+func writeScannedPatch(cmd models.CMD, source sync.OpSource, original io.ReaderAt, file io.Writer) error {
+	writer := bufio.NewWriter(file)
+	var output io.Writer = writer
+	var progress *utils.Progress
+	if cmd.Progress {
+		progress = utils.NewProgress("Patch", 0, reportProgress)
+		output = utils.NewProgressWriter(writer, progress)
+	}
+
+	defer progress.Finish()
+	if err := applyOps(original, source, output); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return errors.New(constants.UnableToWriteOutputFileError)
+	}
+
+	return nil
+}
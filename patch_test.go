@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+var patchDelta = models.Delta{
+	0: models.Block{Head: 0, Tail: 3, IsModified: false, Value: []byte{}},
+	4: models.Block{Head: 4, Tail: 11, IsModified: true, Value: []byte("-updated")},
+}
+
+// setupPatch() will create an Original file + patched output file in a temp folder, mocking the Outputs folder so output is written to the patched file.
+// Function returns `cmd, patched` where patched will be the path of the patched output file.
+func setupPatch(t *testing.T, finished *bool) (models.CMD, string) {
+	dir := t.TempDir()
+	originalFile := filepath.Join(dir, "original.txt")
+	require.Equal(t, nil, os.WriteFile(originalFile, []byte("original"), 0o600))
+	output, err := os.Create(filepath.Join(dir, "patched.txt"))
+	require.Equal(t, nil, err)
+	// Mock
+	resetSelftestMocks()
+	readFile = os.ReadFile
+	createOutputFile = func(fileName string) (*os.File, error) {
+		return output, nil
+	}
+
+	finishPartial = func(path string, failed bool) error {
+		*finished = !failed
+		return nil
+	}
+
+	return models.CMD{PatchMode: true, OriginalFile: originalFile, DeltaFile: filepath.Join(dir, "delta"), OutputFile: file, Yes: true}, output.Name()
+}
+
+// SHA-256 of the Updated file reconstructed by patchDelta (EG `orig-updated`)
+const patchedHash string = "24184ea753fafe55a6d529c7bbc909eee46d792a43fbf47c3794276cd6a4c5ad"
+
+// writeStreamedPatchDelta() will write patchDelta as a streamed Delta file to the provided path, recording the provided metadata, and corrupting a literal block when requested.
+func writeStreamedPatchDelta(t *testing.T, path string, metadata models.DeltaMetadata, corrupt bool) {
+	var buffer bytes.Buffer
+	writer := files.NewDeltaStreamWriter(&buffer)
+	require.Equal(t, nil, writer.WriteBlock(0, patchDelta[0]))
+	require.Equal(t, nil, writer.WriteBlock(4, patchDelta[4]))
+	writer.SetMetadata(metadata)
+	require.Equal(t, nil, writer.Close())
+	data := buffer.Bytes()
+	if corrupt {
+		data = bytes.Replace(data, []byte("-updated"), []byte("-upgrade"), 1)
+	}
+
+	require.Equal(t, nil, os.WriteFile(path, data, 0o600))
+}
+
+func TestRunPatch(t *testing.T) {
+	t.Run("should patch Original file with gob Delta file", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, patched := setupPatch(t, &finished)
+		require.Equal(t, nil, files.WriteStructToPath(patchDelta, cmd.DeltaFile))
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(patched)
+		require.Equal(t, "orig-updated", string(contents))
+	})
+
+	t.Run("should patch Original file with JSON Lines Delta streamed to the Outputs folder by Delta mode", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, patched := setupPatch(t, &finished)
+		cmd.DeltaMode = true
+		out := bytes.Buffer{}
+		require.Equal(t, nil, encodeJSONL(patchDelta, &out))
+		require.Equal(t, nil, os.WriteFile(cmd.DeltaFile, out.Bytes(), 0o600))
+		// Mock
+		outputPath = func(fileName string) string {
+			if fileName == file {
+				return patched
+			}
+
+			return cmd.DeltaFile
+		}
+
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(patched)
+		require.Equal(t, "orig-updated", string(contents))
+		// Restore, so later tests write to the Outputs folder
+		outputPath = files.OutputPath
+	})
+
+	t.Run("should patch Original file with bsdiff patch", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, patched := setupPatch(t, &finished)
+		require.Equal(t, nil, files.WriteStructToPath(files.BsdiffDelta(patchDelta), cmd.DeltaFile))
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(patched)
+		require.Equal(t, "orig-updated", string(contents))
+	})
+
+	t.Run("should patch Original file with streamed Delta file, applying blocks as they are scanned", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, patched := setupPatch(t, &finished)
+		writeStreamedPatchDelta(t, cmd.DeltaFile, models.DeltaMetadata{UpdatedHash: patchedHash}, false)
+		// Mock
+		readFile = func(path string) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(patched)
+		require.Equal(t, "orig-updated", string(contents))
+	})
+
+	t.Run("should discard output + return `UnableToDecodeDeltaFromFileError` when streamed Delta file is corrupted", func(t *testing.T) {
+		// Setup
+		finished := true
+		cmd, _ := setupPatch(t, &finished)
+		writeStreamedPatchDelta(t, cmd.DeltaFile, models.DeltaMetadata{}, true)
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.NotEqual(t, nil, err)
+		require.Contains(t, err.Error(), constants.UnableToDecodeDeltaFromFileError+" (corrupted: checksum")
+		require.Equal(t, false, finished)
+	})
+
+	t.Run("should discard output + return `PatchedFileHashMismatchError` when output does not match SHA-256 recorded by gob Delta file", func(t *testing.T) {
+		// Setup
+		finished := true
+		cmd, _ := setupPatch(t, &finished)
+		require.Equal(t, nil, files.WriteStructToPath(patchDelta, cmd.DeltaFile))
+		expectedError := errors.New(constants.PatchedFileHashMismatchError)
+		// Mock
+		openDeltaMeta = func(fileName string, verbose bool) (models.Delta, models.DeltaMetadata, error) {
+			return patchDelta, models.DeltaMetadata{UpdatedHash: "some-other-hash"}, nil
+		}
+
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, false, finished)
+	})
+
+	t.Run("should discard output + return `PatchedFileHashMismatchError` when output does not match SHA-256 recorded by streamed Delta file", func(t *testing.T) {
+		// Setup
+		finished := true
+		cmd, _ := setupPatch(t, &finished)
+		writeStreamedPatchDelta(t, cmd.DeltaFile, models.DeltaMetadata{UpdatedHash: "some-other-hash"}, false)
+		expectedError := errors.New(constants.PatchedFileHashMismatchError)
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, false, finished)
+	})
+
+	t.Run("should patch Original file with the Delta generated by Delta mode without reading Delta file", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, patched := setupPatch(t, &finished)
+		// Run
+		err := runPatch(cmd, models.Signature{}, patchDelta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		contents, _ := os.ReadFile(patched)
+		require.Equal(t, "orig-updated", string(contents))
+	})
+
+	t.Run("should return `UnableToOpenDeltaFileError` when unable to read Delta file", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, _ := setupPatch(t, &finished)
+		expectedError := errors.New(constants.UnableToOpenDeltaFileError)
+		// Run
+		err := runPatch(cmd, models.Signature{}, nil)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, false, finished)
+	})
+
+	t.Run("should return `UnableToReadOriginalFileError` when unable to open Original file", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, _ := setupPatch(t, &finished)
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := runPatch(cmd, models.Signature{}, patchDelta)
+		// Verify
+		require.Equal(t, expectedError, err)
+		// Restore, so later tests open the Original file
+		openFileAt = openReaderAt
+	})
+
+	t.Run("should return `UnableToWriteOutputFileError` when unable to rename output into place", func(t *testing.T) {
+		// Setup
+		finished := false
+		cmd, _ := setupPatch(t, &finished)
+		expectedError := errors.New(constants.UnableToWriteOutputFileError)
+		// Mock
+		finishPartial = func(path string, failed bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		err := runPatch(cmd, models.Signature{}, patchDelta)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	// Restore, so later tests write to the Outputs folder
+	createOutputFile = files.CreateOutputFile
+	finishPartial = files.FinishPartial
+}
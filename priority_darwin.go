@@ -0,0 +1,13 @@
+package main
+
+import "syscall"
+
+const lowestNice int = 19 // Lowest CPU scheduling priority
+
+// setLowPriority() will set the lowest CPU priority (EG `nice -n 19`) for the current process.
+// Note: macOS lowers I/O priority of low priority processes, so no separate I/O priority is set.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to set priority.
+func setLowPriority() error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, 0, lowestNice)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+const (
+	lowestNice      int     = 19 // Lowest CPU scheduling priority
+	ioprioWhoProc   uintptr = 1  // IOPRIO_WHO_PROCESS
+	ioprioIdleClass uintptr = 3  // IOPRIO_CLASS_IDLE
+	ioprioClassBits uintptr = 13 // IOPRIO_CLASS_SHIFT
+)
+
+var taskDir = "/proc/self/task" // Lists a folder per thread (TID) of the current process
+
+// setLowPriority() will set the lowest CPU priority (EG `nice -n 19`) + idle I/O priority (EG `ionice -c 3`) for every thread of the current process.
+// Note: Linux applies both priorities per thread, so each thread listed in /proc/self/task is lowered (repeating until no new threads are found).
+// Threads created afterwards inherit the priority of the thread creating them, so this should be called before worker goroutines start (EG Signature + Batch workers).
+// Function returns `nil` when successful.
+// Function returns `error` when unable to list threads, or unable to set either priority of a thread.
+func setLowPriority() error {
+	lowered := map[int]bool{}
+	for {
+		tids, err := threadIDs()
+		if err != nil {
+			return err
+		}
+
+		pending := false
+		for _, tid := range tids {
+			if lowered[tid] {
+				continue
+			}
+
+			// Threads may exit between being listed + lowered
+			if err := lowerThread(tid); err != nil && err != syscall.ESRCH {
+				return err
+			}
+
+			lowered[tid] = true
+			pending = true
+		}
+
+		if !pending {
+			return nil
+		}
+	}
+}
+
+// lowerThread() will set the lowest CPU priority + idle I/O priority for the provided thread.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to set either priority.
+func lowerThread(tid int) error {
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, lowestNice); err != nil {
+		return err
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProc, uintptr(tid), ioprioIdleClass<<ioprioClassBits); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// threadIDs() will list the thread IDs (TIDs) of the current process.
+// Function returns `tids, nil` when successful.
+// Function returns `nil, error` when unable to read /proc/self/task.
+func threadIDs() ([]int, error) {
+	entries, err := os.ReadDir(taskDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if tid, err := strconv.Atoi(entry.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+
+	return tids, nil
+}
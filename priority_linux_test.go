@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const lowPriorityChildEnv string = "GFD_LOW_PRIORITY_CHILD" // Set when the test binary is re-run to lower its own priority
+
+// threadPriorities() will return the nice value + I/O priority class of every thread of the current process.
+func threadPriorities(t *testing.T) map[int][2]int {
+	tids, err := threadIDs()
+	require.Equal(t, nil, err)
+	priorities := map[int][2]int{}
+	for _, tid := range tids {
+		stat, err := os.ReadFile(filepath.Join(taskDir, strconv.Itoa(tid), "stat"))
+		require.Equal(t, nil, err)
+		// Fields following the command name (which may contain spaces), where nice is field 19 of stat
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		nice, err := strconv.Atoi(fields[16])
+		require.Equal(t, nil, err)
+		ioprio, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioprioWhoProc, uintptr(tid), 0)
+		require.Equal(t, syscall.Errno(0), errno)
+		priorities[tid] = [2]int{nice, int(ioprio >> ioprioClassBits)}
+	}
+
+	return priorities
+}
+
+// lockThreads() will start goroutines locked to their own threads, forcing Go to create new threads.
+// Returned channel will release the goroutines once closed.
+func lockThreads(count int) chan struct{} {
+	release := make(chan struct{})
+	for index := 0; index < count; index++ {
+		go func() {
+			runtime.LockOSThread()
+			<-release
+		}()
+	}
+
+	return release
+}
+
+func TestSetLowPriority(t *testing.T) {
+	// Lowered priority can not be raised again, so run in a child process to keep the remaining tests at normal priority
+	if os.Getenv(lowPriorityChildEnv) != "" {
+		t.Run("should lower CPU + I/O priority of every thread, including threads created afterwards", func(t *testing.T) {
+			// Setup
+			before := lockThreads(4)
+			defer close(before)
+			// Run
+			err := setLowPriority()
+			after := lockThreads(4)
+			defer close(after)
+			// Verify
+			require.Equal(t, nil, err)
+			priorities := threadPriorities(t)
+			require.Greater(t, len(priorities), 1)
+			for tid, priority := range priorities {
+				require.Equal(t, [2]int{lowestNice, int(ioprioIdleClass)}, priority, "thread %d", tid)
+			}
+		})
+
+		return
+	}
+
+	t.Run("should lower priority of every thread in a child process", func(t *testing.T) {
+		// Setup
+		child := exec.Command(os.Args[0], "-test.run=^TestSetLowPriority$", "-test.v")
+		child.Env = append(os.Environ(), lowPriorityChildEnv+"=1")
+		// Run
+		output, err := child.CombinedOutput()
+		// Verify
+		require.Equal(t, nil, err, string(output))
+		require.Equal(t, true, strings.Contains(string(output), "--- PASS: TestSetLowPriority/should_lower_CPU"), string(output))
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// setLowPriority() will return `UnableToLowerPriorityError` as lowering priority is not supported on this platform.
+func setLowPriority() error {
+	return errors.New(constants.UnableToLowerPriorityError)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "syscall"
+
+const processModeBackgroundBegin uintptr = 0x00100000 // PROCESS_MODE_BACKGROUND_BEGIN (lowers CPU, I/O, and memory priority)
+
+var setPriorityClass = syscall.NewLazyDLL("kernel32.dll").NewProc("SetPriorityClass")
+
+// setLowPriority() will enter background processing mode for the current process.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to set priority.
+func setLowPriority() error {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return err
+	}
+
+	if result, _, err := setPriorityClass.Call(uintptr(process), processModeBackgroundBegin); result == 0 {
+		return err
+	}
+
+	return nil
+}
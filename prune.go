@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+var (
+	pruneBlocks     = sync.PruneSignature
+	signatureStride = sync.SignatureStride
+)
+
+// pruneSignature() will reduce a Signature for memory-constrained receivers when `-signatureStride` or `-maxSignatureEntries` flags are set (see sync.PruneSignature()).
+// When both flags are set, the larger stride will be used.
+// Function returns `signature` unchanged when neither flag is set.
+func pruneSignature(cmd models.CMD, signature models.Signature) models.Signature {
+	stride := int64(cmd.Stride)
+	if limit := signatureStride(signature, cmd.MaxEntries); limit > stride {
+		stride = limit
+	}
+
+	if stride <= 1 {
+		return signature
+	}
+
+	pruned := pruneBlocks(signature, stride)
+	logger(fmt.Sprintf("Signature pruned to every %d offsets (%d of %d Weak hashes kept)", stride, len(pruned), len(signature)), cmd.Verbose)
+	return pruned
+}
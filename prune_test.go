@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneSignature(t *testing.T) {
+	signature := models.Signature{1: models.StrongSignature{Hash: "a", Head: 0, Tail: 15}, 2: models.StrongSignature{Hash: "b", Head: 1, Tail: 16}}
+	pruned := models.Signature{1: models.StrongSignature{Hash: "a", Head: 0, Tail: 15}}
+	t.Run("should return `prunedSignature` using the larger of `-signatureStride` + `-maxSignatureEntries` strides", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, Stride: 4, MaxEntries: 1}
+		stride := int64(0)
+		// Mock
+		signatureStride = func(signature models.Signature, maxEntries int64) int64 {
+			return 8
+		}
+
+		pruneBlocks = func(signature models.Signature, blockStride int64) models.Signature {
+			stride = blockStride
+			return pruned
+		}
+
+		// Run
+		result := pruneSignature(cmd, signature)
+		// Verify
+		require.Equal(t, pruned, result)
+		require.Equal(t, int64(8), stride)
+	})
+
+	t.Run("should return `signature` unchanged when neither flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true}
+		// Mock
+		signatureStride = sync.SignatureStride
+		// Run
+		result := pruneSignature(cmd, signature)
+		// Verify
+		require.Equal(t, signature, result)
+		// Restore, so later tests prune Signatures for real
+		pruneBlocks = sync.PruneSignature
+	})
+}
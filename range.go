@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+var rangeDelta = sync.RangeDelta
+
+// expandRange() will build a Delta for the full Updated file from a Delta generated for the region provided by `-range`.
+// Bytes outside the region will be copied from the Original file (see sync.RangeDelta()).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileDoesNotExistError` when unable to get size of Updated file.
+// Function returns `emptyDelta, error` when range is invalid, or outside the Original + Updated files.
+func expandRange(cmd models.CMD, signature models.Signature, region models.Delta) (models.Delta, error) {
+	start, end, err := utils.ParseRange(cmd.Range)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	size, err := fileSize(cmd.UpdatedFile)
+	if err != nil {
+		return models.Delta{}, errors.New(constants.UpdatedFileDoesNotExistError)
+	}
+
+	return rangeDelta(region, signature, start, end, size)
+}
+
+// openUpdatedRange() will create a FileReader for the region of the Updated file provided by `-range`.
+// Function returns `reader, nil` when successful.
+// Function returns `nil, UpdatedFileDoesNotExistError` when unable to get size of Updated file.
+// Function returns `nil, RangeOutsideFilesError` when range ends after the Updated file.
+// Function returns `nil, UnableToReadUpdatedFileError` when unable to open Updated file.
+// Function returns `nil, InvalidRangeError` when range is invalid.
+func openUpdatedRange(cmd models.CMD) (*bufio.Reader, error) {
+	start, end, err := utils.ParseRange(cmd.Range)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := fileSize(cmd.UpdatedFile)
+	if err != nil {
+		return nil, errors.New(constants.UpdatedFileDoesNotExistError)
+	} else if end > size {
+		return nil, errors.New(constants.RangeOutsideFilesError)
+	}
+
+	file, err := openFileAt(cmd.UpdatedFile)
+	if err != nil {
+		return nil, errors.New(constants.UnableToReadUpdatedFileError)
+	}
+
+	return bufio.NewReader(io.NewSectionReader(file, start, end-start)), nil
+}
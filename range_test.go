@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for ReaderAtCloser interface backed by in-memory data
+type readerAtCloserMock struct {
+	*bytes.Reader
+}
+
+// Implement readerAtCloserMock.Close()
+func (r readerAtCloserMock) Close() error { return nil }
+
+func TestExpandRange(t *testing.T) {
+	t.Run("should return `delta, nil` covering the full Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:8"}
+		signature := models.Signature{1: models.StrongSignature{Hash: "a", Head: 0, Tail: 15}}
+		region := models.Delta{0: models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("abcd")}}
+		expectedDelta := models.Delta{
+			0: models.Block{Head: 0, Tail: 3, IsModified: false, Value: []byte{}},
+			4: models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("abcd")},
+			8: models.Block{Head: 8, Tail: 15, IsModified: false, Value: []byte{}},
+		}
+
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 16, nil
+		}
+
+		rangeDelta = sync.RangeDelta
+		// Run
+		delta, err := expandRange(cmd, signature, region)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, InvalidRangeError` when range is invalid", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "8:4"}
+		expectedError := errors.New(constants.InvalidRangeError)
+		// Run
+		delta, err := expandRange(cmd, models.Signature{}, models.Delta{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileDoesNotExistError` when unable to get size of Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:8"}
+		expectedError := errors.New(constants.UpdatedFileDoesNotExistError)
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		// Run
+		delta, err := expandRange(cmd, models.Signature{}, models.Delta{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+		fileSize = files.FileSize
+	})
+}
+
+func TestOpenUpdatedRange(t *testing.T) {
+	t.Run("should return reader for the region of Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:8"}
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 16, nil
+		}
+
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return readerAtCloserMock{bytes.NewReader([]byte("0123456789abcdef"))}, nil
+		}
+
+		// Run
+		reader, err := openUpdatedRange(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		contents, err := io.ReadAll(reader)
+		require.Equal(t, nil, err)
+		require.Equal(t, "4567", string(contents))
+	})
+
+	t.Run("should return `nil, RangeOutsideFilesError` when range ends after Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:32"}
+		expectedError := errors.New(constants.RangeOutsideFilesError)
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 16, nil
+		}
+
+		// Run
+		reader, err := openUpdatedRange(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, reader)
+	})
+
+	t.Run("should return `nil, UnableToReadUpdatedFileError` when unable to open Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:8"}
+		expectedError := errors.New(constants.UnableToReadUpdatedFileError)
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 16, nil
+		}
+
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		reader, err := openUpdatedRange(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, reader)
+	})
+
+	t.Run("should return `nil, UpdatedFileDoesNotExistError` when unable to get size of Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{UpdatedFile: file, Range: "4:8"}
+		expectedError := errors.New(constants.UpdatedFileDoesNotExistError)
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		// Run
+		reader, err := openUpdatedRange(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, reader)
+	})
+
+	// Restore, so later tests open + size real files
+	openFileAt = openReaderAt
+	fileSize = files.FileSize
+}
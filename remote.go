@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	remoteDefaultPort string        = "22"
+	remoteTimeout     time.Duration = 30 * time.Second
+	patchStdin        string        = "-" // Read the Delta applied by Patch mode from stdin (EG streamed over SSH by `-remote`)
+)
+
+var (
+	runRemote              = runSSH
+	userHomeDir            = os.UserHomeDir
+	stderr       io.Writer = os.Stderr
+	dialSSHAgent           = func(socket string) (net.Conn, error) { return net.Dial("unix", socket) }
+)
+
+// remoteTarget type.
+// This will describe the host + Original file patched by `-remote`.
+// EG: remoteTarget{User: "deploy", Address: "example.com:22", Path: "/srv/app.bin"}.
+type remoteTarget struct {
+	User    string
+	Address string
+	Path    string
+}
+
+// parseRemote() will parse the `-remote` flag in the format `user@host[:port]:/path/to/original` (EG `deploy@example.com:2222:/srv/app.bin`).
+// Note: port will default to 22 when not provided.
+// Function returns `target, nil` when successful.
+// Function returns `emptyTarget, InvalidRemoteError` when user, host or path is missing, or the port is not a number.
+func parseRemote(remote string) (remoteTarget, error) {
+	user, rest, found := strings.Cut(remote, "@")
+	if !found || user == "" {
+		return remoteTarget{}, errors.New(constants.InvalidRemoteError)
+	}
+
+	host, path, found := strings.Cut(rest, ":")
+	if !found || host == "" {
+		return remoteTarget{}, errors.New(constants.InvalidRemoteError)
+	}
+
+	port := remoteDefaultPort
+	if head, tail, found := strings.Cut(path, ":"); found && head != "" && strings.Trim(head, "0123456789") == "" {
+		port, path = head, tail
+	}
+
+	if path == "" {
+		return remoteTarget{}, errors.New(constants.InvalidRemoteError)
+	}
+
+	return remoteTarget{User: user, Address: net.JoinHostPort(host, port), Path: path}, nil
+}
+
+// shellQuote() will quote an argument for the remote shell, so paths containing spaces or quotes are passed as a single argument.
+// EG `it's` will be quoted as `'it'` + `\'` + `'s'`, closing the quotes around the escaped quote.
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// remotePatchCommand() will build the command run on the remote host, applying the Delta streamed to stdin to the Original file with Patch mode.
+// EG `'go-file-diff' '-patchMode' '-original=/srv/app.bin' '-delta=-' '-output=app.bin'`.
+// Note: the patched file will be written to the `-output` file in the Outputs folder of the remote host (EG relative to the home folder of the SSH user).
+func remotePatchCommand(cmd models.CMD, target remoteTarget) string {
+	args := []string{cmd.RemoteBin, "-patchMode", "-original=" + target.Path, "-delta=" + patchStdin, "-output=" + cmd.OutputFile}
+	if cmd.Verbose {
+		args = append(args, "-v")
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// runRemotePatch() will stream the Delta file written by Delta mode to the remote host provided by `-remote`, patching the Original file there with a remote copy of go-file-diff (see remotePatchCommand()).
+// The patched file will only be written on the remote host, so does not need to exist (or fit) locally.
+// Output of the remote command will be written to stdout + stderr.
+// Function returns `nil` when successful.
+// Function returns `InvalidRemoteError` when `-remote` is not in the format `user@host[:port]:/path/to/original`.
+// Function returns `UnableToOpenDeltaFileError` when unable to open the Delta file.
+// Function returns `error` in the same cases as runSSH().
+func runRemotePatch(cmd models.CMD) error {
+	target, err := parseRemote(cmd.Remote)
+	if err != nil {
+		return err
+	}
+
+	delta, err := openInput(outputPath(cmd.DeltaFile))
+	if err != nil {
+		return errors.New(constants.UnableToOpenDeltaFileError)
+	}
+
+	defer delta.Close()
+	logger(fmt.Sprintf("Streaming Delta to %s@%s, patching %s\n", target.User, target.Address, target.Path), true)
+	return runRemote(cmd, target, remotePatchCommand(cmd, target), delta, stdout, stderr)
+}
+
+// runSSH() will connect to the remote host over SSH and run a command, streaming stdin to the command + its output to stdout and stderr.
+// Note: the remote host key will be verified against the `-knownHosts` file (defaults to `~/.ssh/known_hosts`).
+// Function returns `nil` when the command succeeds.
+// Function returns `error` in the same cases as sshConfig().
+// Function returns `UnableToConnectRemoteError` (with the reason) when unable to connect or authenticate (EG unknown host key).
+// Function returns `RemotePatchFailedError` when the command fails (EG remote Patch mode returned an error).
+func runSSH(cmd models.CMD, target remoteTarget, command string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	config, err := sshConfig(cmd, target)
+	if err != nil {
+		return err
+	}
+
+	client, err := ssh.Dial("tcp", target.Address, config)
+	if err != nil {
+		return fmt.Errorf("%s: %s", constants.UnableToConnectRemoteError, err)
+	}
+
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("%s: %s", constants.UnableToConnectRemoteError, err)
+	}
+
+	defer session.Close()
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if err = session.Run(command); err != nil {
+		return errors.New(constants.RemotePatchFailedError)
+	}
+
+	return nil
+}
+
+// sshConfig() will build the SSH client config for `-remote`, authenticating with the `-sshKey` private key, and the keys of the SSH agent (when SSH_AUTH_SOCK is set).
+// Function returns `config, nil` when successful.
+// Function returns `nil, UnableToReadSSHKeyError` when unable to read or parse the private key (EG passphrase protected keys, which should be added to the SSH agent).
+// Function returns `nil, SSHAuthMissingError` when no private key is provided and no SSH agent is running.
+// Function returns `nil, UnableToReadKnownHostsError` when unable to read the known_hosts file.
+func sshConfig(cmd models.CMD, target remoteTarget) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+	if cmd.SSHKey != "" {
+		key, err := readFile(cmd.SSHKey)
+		if err != nil {
+			return nil, errors.New(constants.UnableToReadSSHKeyError)
+		}
+
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, errors.New(constants.UnableToReadSSHKeyError)
+		}
+
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := dialSSHAgent(socket); err == nil {
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	if len(auth) == 0 {
+		return nil, errors.New(constants.SSHAuthMissingError)
+	}
+
+	knownHostsFile := cmd.KnownHosts
+	if knownHostsFile == "" {
+		home, err := userHomeDir()
+		if err != nil {
+			return nil, errors.New(constants.UnableToReadKnownHostsError)
+		}
+
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, errors.New(constants.UnableToReadKnownHostsError)
+	}
+
+	return &ssh.ClientConfig{User: target.User, Auth: auth, HostKeyCallback: hostKeyCallback, Timeout: remoteTimeout}, nil
+}
+
+// spoolPatchDelta() will copy the Delta applied by Patch mode from stdin (EG streamed over SSH by `-remote`) to a temp file (see chooseTempDir()), so its format can be detected + decoded in the same way as a Delta file.
+// Note: the temp file should be removed once patched.
+// Function returns `path, nil` when successful.
+// Function returns `"", UnableToReadDeltaStdinError` when unable to read stdin, or write the temp file.
+func spoolPatchDelta(cmd models.CMD) (string, error) {
+	file, err := createTemp(chooseTempDir(cmd), "go-file-diff-delta-")
+	if err != nil {
+		return "", errors.New(constants.UnableToReadDeltaStdinError)
+	}
+
+	_, err = io.Copy(file, stdin)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = removeFile(file.Name())
+		return "", errors.New(constants.UnableToReadDeltaStdinError)
+	}
+
+	return file.Name(), nil
+}
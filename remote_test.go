@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshTestServer() will start an SSH server accepting the returned client key, running `handle` for each command (EG `exec` request).
+// Function returns the address of the server, plus the paths of the client private key + known_hosts files in the provided folder.
+func sshTestServer(t *testing.T, dir string, handle func(command string, channel ssh.Channel) uint32) (string, string, string) {
+	_, hostPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.Equal(t, nil, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivate)
+	require.Equal(t, nil, err)
+	clientPublic, clientPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.Equal(t, nil, err)
+	clientKey, err := ssh.NewPublicKey(clientPublic)
+	require.Equal(t, nil, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if !bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, errors.New("unknown key")
+			}
+
+			return nil, nil
+		},
+	}
+
+	config.AddHostKey(hostSigner)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Equal(t, nil, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config, handle)
+		}
+	}()
+
+	address := listener.Addr().String()
+	block, err := ssh.MarshalPrivateKey(clientPrivate, "")
+	require.Equal(t, nil, err)
+	keyFile := filepath.Join(dir, "id_ed25519")
+	require.Equal(t, nil, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	require.Equal(t, nil, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{address}, hostSigner.PublicKey())+"\n"), 0o600))
+	return address, keyFile, knownHostsFile
+}
+
+// serveSSH() will handle the session channels of an SSH connection for sshTestServer().
+func serveSSH(conn net.Conn, config *ssh.ServerConfig, handle func(command string, channel ssh.Channel) uint32) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for request := range channelRequests {
+				var payload struct{ Command string }
+				if request.Type != "exec" || ssh.Unmarshal(request.Payload, &payload) != nil {
+					_ = request.Reply(false, nil)
+					continue
+				}
+
+				_ = request.Reply(true, nil)
+				status := handle(payload.Command, channel)
+				_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+				channel.Close()
+			}
+		}()
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	t.Run("should return target with the default port when port not provided", func(t *testing.T) {
+		// Run
+		target, err := parseRemote("deploy@example.com:/srv/app.bin")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, remoteTarget{User: "deploy", Address: "example.com:22", Path: "/srv/app.bin"}, target)
+	})
+
+	t.Run("should return target with the provided port", func(t *testing.T) {
+		// Run
+		target, err := parseRemote("deploy@example.com:2222:/srv/app.bin")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, remoteTarget{User: "deploy", Address: "example.com:2222", Path: "/srv/app.bin"}, target)
+	})
+
+	t.Run("should return target with a relative path containing `:`", func(t *testing.T) {
+		// Run
+		target, err := parseRemote("deploy@example.com:app:v2.bin")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, remoteTarget{User: "deploy", Address: "example.com:22", Path: "app:v2.bin"}, target)
+	})
+
+	t.Run("should return `InvalidRemoteError` when user, host or path missing", func(t *testing.T) {
+		for _, remote := range []string{"example.com:/srv/app.bin", "@example.com:/srv/app.bin", "deploy@:/srv/app.bin", "deploy@example.com", "deploy@example.com:", "deploy@example.com:2222:"} {
+			// Run
+			target, err := parseRemote(remote)
+			// Verify
+			require.Equal(t, errors.New(constants.InvalidRemoteError), err, remote)
+			require.Equal(t, remoteTarget{}, target)
+		}
+	})
+}
+
+func TestRemotePatchCommand(t *testing.T) {
+	t.Run("should return Patch mode command reading Delta from stdin, with quoted arguments", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{RemoteBin: "/opt/go-file-diff", OutputFile: "app's.bin", Verbose: true}
+		target := remoteTarget{Path: "/srv/my app.bin"}
+		// Run
+		command := remotePatchCommand(cmd, target)
+		// Verify
+		require.Equal(t, `'/opt/go-file-diff' '-patchMode' '-original=/srv/my app.bin' '-delta=-' '-output=app'\''s.bin' '-v'`, command)
+	})
+}
+
+func TestRunRemotePatch(t *testing.T) {
+	t.Run("should stream Delta file to the remote Patch mode command", func(t *testing.T) {
+		// Setup
+		dir := t.TempDir()
+		deltaFile := filepath.Join(dir, "delta.bin")
+		require.Equal(t, nil, os.WriteFile(deltaFile, []byte("some-delta"), 0o600))
+		cmd := models.CMD{Remote: "deploy@example.com:/srv/app.bin", RemoteBin: "go-file-diff", DeltaFile: "delta.bin", OutputFile: "app.bin"}
+		var streamed []byte
+		var command string
+		// Mock
+		outputPath = func(fileName string) string {
+			return filepath.Join(dir, fileName)
+		}
+
+		runRemote = func(cmd models.CMD, target remoteTarget, remoteCommand string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+			command = remoteCommand
+			streamed, _ = io.ReadAll(stdin)
+			return nil
+		}
+
+		// Run
+		err := runRemotePatch(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "some-delta", string(streamed))
+		require.Equal(t, `'go-file-diff' '-patchMode' '-original=/srv/app.bin' '-delta=-' '-output=app.bin'`, command)
+		// Mock
+		outputPath = files.OutputPath
+		runRemote = runSSH
+	})
+
+	t.Run("should return `InvalidRemoteError` when `-remote` is invalid", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Remote: "example.com"}
+		// Run
+		err := runRemotePatch(cmd)
+		// Verify
+		require.Equal(t, errors.New(constants.InvalidRemoteError), err)
+	})
+
+	t.Run("should return `UnableToOpenDeltaFileError` when unable to open Delta file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Remote: "deploy@example.com:/srv/app.bin", DeltaFile: filepath.Join(t.TempDir(), "missing.bin")}
+		// Mock
+		outputPath = func(fileName string) string {
+			return fileName
+		}
+
+		// Run
+		err := runRemotePatch(cmd)
+		// Verify
+		require.Equal(t, errors.New(constants.UnableToOpenDeltaFileError), err)
+		// Mock
+		outputPath = files.OutputPath
+	})
+
+	t.Run("should patch Original file on the remote host, without writing the patched file locally", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		dir := writeCLIFiles(t, 64*1024)
+		remoteDir := t.TempDir()
+		original, err := os.ReadFile(filepath.Join(dir, "original.bin"))
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, os.WriteFile(filepath.Join(remoteDir, "app.bin"), original, 0o600))
+		// Run remote command as the CLI (see TestCLI), with the streamed Delta as stdin
+		address, keyFile, knownHostsFile := sshTestServer(t, dir, func(command string, channel ssh.Channel) uint32 {
+			args := strings.Fields(command)[1:]
+			for i, arg := range args {
+				args[i] = strings.Trim(arg, "'")
+			}
+
+			child := exec.Command(os.Args[0], "-test.run=^TestCLI$")
+			child.Env = append(os.Environ(), cliChildEnv+"="+strings.Join(args, "\n"))
+			child.Dir = remoteDir
+			child.Stdin = channel
+			child.Stdout = channel
+			child.Stderr = channel.Stderr()
+			if err := child.Run(); err != nil {
+				return 1
+			}
+
+			return 0
+		})
+
+		host, port, err := net.SplitHostPort(address)
+		require.Equal(t, nil, err)
+		remote := fmt.Sprintf("deploy@%s:%s:%s", host, port, filepath.Join(remoteDir, "app.bin"))
+		// Run
+		output, err := runCLI(t, dir, "-signatureMode", "-deltaMode", "-original=original.bin", "-signature=sig.bin", "-updated=updated.bin", "-delta=delta.bin", "-output=patched.bin", "-remote="+remote, "-sshKey="+keyFile, "-knownHosts="+knownHostsFile)
+		// Verify
+		require.Equal(t, nil, err, output)
+		updated, err := os.ReadFile(filepath.Join(dir, "updated.bin"))
+		require.Equal(t, nil, err)
+		patched, err := os.ReadFile(filepath.Join(remoteDir, "Outputs", "patched.bin"))
+		require.Equal(t, nil, err, output)
+		require.Equal(t, updated, patched)
+		_, err = os.Stat(filepath.Join(dir, "Outputs", "patched.bin"))
+		require.Equal(t, true, os.IsNotExist(err))
+	})
+}
+
+func TestRunSSH(t *testing.T) {
+	t.Run("should run command on the remote host, streaming stdin + output", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		dir := t.TempDir()
+		address, keyFile, knownHostsFile := sshTestServer(t, dir, func(command string, channel ssh.Channel) uint32 {
+			input, _ := io.ReadAll(channel)
+			fmt.Fprintf(channel, "%s < %s", command, input)
+			fmt.Fprint(channel.Stderr(), "some-log")
+			return 0
+		})
+
+		cmd := models.CMD{SSHKey: keyFile, KnownHosts: knownHostsFile}
+		var stdout, stderr bytes.Buffer
+		// Run
+		err := runSSH(cmd, remoteTarget{User: "deploy", Address: address}, "some-command", strings.NewReader("some-delta"), &stdout, &stderr)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "some-command < some-delta", stdout.String())
+		require.Equal(t, "some-log", stderr.String())
+	})
+
+	t.Run("should return `RemotePatchFailedError` when command fails on the remote host", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		dir := t.TempDir()
+		address, keyFile, knownHostsFile := sshTestServer(t, dir, func(command string, channel ssh.Channel) uint32 {
+			return 1
+		})
+
+		cmd := models.CMD{SSHKey: keyFile, KnownHosts: knownHostsFile}
+		// Run
+		err := runSSH(cmd, remoteTarget{User: "deploy", Address: address}, "some-command", strings.NewReader(""), io.Discard, io.Discard)
+		// Verify
+		require.Equal(t, errors.New(constants.RemotePatchFailedError), err)
+	})
+
+	t.Run("should return `UnableToConnectRemoteError` when remote host key is not in the known_hosts file", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		dir := t.TempDir()
+		address, keyFile, _ := sshTestServer(t, dir, func(command string, channel ssh.Channel) uint32 {
+			return 0
+		})
+
+		knownHostsFile := filepath.Join(dir, "empty_known_hosts")
+		require.Equal(t, nil, os.WriteFile(knownHostsFile, []byte{}, 0o600))
+		cmd := models.CMD{SSHKey: keyFile, KnownHosts: knownHostsFile}
+		// Run
+		err := runSSH(cmd, remoteTarget{User: "deploy", Address: address}, "some-command", strings.NewReader(""), io.Discard, io.Discard)
+		// Verify
+		require.Contains(t, err.Error(), constants.UnableToConnectRemoteError)
+		require.Contains(t, err.Error(), "key is unknown")
+	})
+}
+
+func TestSSHConfig(t *testing.T) {
+	t.Run("should return `SSHAuthMissingError` when no private key set and no SSH agent running", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		// Run
+		config, err := sshConfig(models.CMD{}, remoteTarget{})
+		// Verify
+		require.Nil(t, config)
+		require.Equal(t, errors.New(constants.SSHAuthMissingError), err)
+	})
+
+	t.Run("should return `UnableToReadSSHKeyError` when unable to parse private key", func(t *testing.T) {
+		// Setup
+		keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+		require.Equal(t, nil, os.WriteFile(keyFile, []byte("not-a-key"), 0o600))
+		// Run
+		config, err := sshConfig(models.CMD{SSHKey: keyFile}, remoteTarget{})
+		// Verify
+		require.Nil(t, config)
+		require.Equal(t, errors.New(constants.UnableToReadSSHKeyError), err)
+	})
+
+	t.Run("should return `UnableToReadKnownHostsError` when unable to read known_hosts file", func(t *testing.T) {
+		// Setup
+		t.Setenv("SSH_AUTH_SOCK", "")
+		dir := t.TempDir()
+		_, keyFile, _ := sshTestServer(t, dir, func(command string, channel ssh.Channel) uint32 {
+			return 0
+		})
+
+		cmd := models.CMD{SSHKey: keyFile, KnownHosts: filepath.Join(dir, "missing")}
+		// Run
+		config, err := sshConfig(cmd, remoteTarget{})
+		// Verify
+		require.Nil(t, config)
+		require.Equal(t, errors.New(constants.UnableToReadKnownHostsError), err)
+	})
+}
+
+func TestSpoolPatchDelta(t *testing.T) {
+	t.Run("should copy Delta from stdin to a temp file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{TempDirs: t.TempDir()}
+		// Mock
+		stdin = strings.NewReader("some-delta")
+		// Run
+		path, err := spoolPatchDelta(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		data, err := os.ReadFile(path)
+		require.Equal(t, nil, err)
+		require.Equal(t, "some-delta", string(data))
+		require.Equal(t, cmd.TempDirs, filepath.Dir(path))
+		// Mock
+		stdin = os.Stdin
+	})
+
+	t.Run("should return `UnableToReadDeltaStdinError` + remove temp file when unable to read stdin", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{TempDirs: t.TempDir()}
+		// Mock
+		stdin = iotest.ErrReader(errors.New(errorMessage))
+		// Run
+		path, err := spoolPatchDelta(cmd)
+		// Verify
+		require.Equal(t, "", path)
+		require.Equal(t, errors.New(constants.UnableToReadDeltaStdinError), err)
+		entries, _ := os.ReadDir(cmd.TempDirs)
+		require.Equal(t, 0, len(entries))
+		// Mock
+		stdin = os.Stdin
+	})
+}
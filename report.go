@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+var marshalReport = json.MarshalIndent
+
+// writePatchReport() will encode a patch verification report as JSON and write it to the provided path.
+// Function returns `nil` when successful.
+// Function returns `UnableToWritePatchReportError` when unable to encode or write the report.
+func writePatchReport(report []sync.BlockReport, path string) error {
+	output, err := marshalReport(report, "", "  ")
+	if err != nil {
+		return errors.New(constants.UnableToWritePatchReportError)
+	}
+
+	if err = writeFile(path, append(output, '\n'), files.FileMode()); err != nil {
+		return errors.New(constants.UnableToWritePatchReportError)
+	}
+
+	return nil
+}
+
+// writeAuditLog() will encode patch audit events as NDJSON (one JSON object per line) and write them to the provided path.
+// Function returns `nil` when successful.
+// Function returns `UnableToWriteAuditLogError` when unable to encode or write the audit log.
+func writeAuditLog(events []sync.AuditEvent, path string) error {
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return errors.New(constants.UnableToWriteAuditLogError)
+		}
+	}
+
+	if err := writeFile(path, output.Bytes(), files.FileMode()); err != nil {
+		return errors.New(constants.UnableToWriteAuditLogError)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePatchReport(t *testing.T) {
+	t.Run("should return `nil` after writing report as JSON to file", func(t *testing.T) {
+		// Setup
+		report := []sync.BlockReport{{Kind: "literal", Position: 0, Size: 1, Checksum: "some-hash", Status: sync.BlockLiteral}}
+		written := []byte{}
+		// Mock
+		marshalReport = json.MarshalIndent
+		writeFile = func(name string, data []byte, perm os.FileMode) error {
+			written = data
+			return nil
+		}
+
+		// Run
+		err := writePatchReport(report, file)
+		// Verify
+		require.Equal(t, nil, err)
+		decoded := []sync.BlockReport{}
+		require.Equal(t, nil, json.Unmarshal(written, &decoded))
+		require.Equal(t, report, decoded)
+	})
+
+	t.Run("should return `UnableToWritePatchReportError` when unable to write report", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWritePatchReportError)
+		// Mock
+		writeFile = func(name string, data []byte, perm os.FileMode) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		err := writePatchReport([]sync.BlockReport{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToWritePatchReportError` when unable to encode report", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWritePatchReportError)
+		// Mock
+		marshalReport = func(v any, prefix, indent string) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := writePatchReport([]sync.BlockReport{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		marshalReport = json.MarshalIndent
+		writeFile = os.WriteFile
+	})
+}
+
+func TestWriteAuditLog(t *testing.T) {
+	t.Run("should return `nil` after writing each event as a line of JSON to file", func(t *testing.T) {
+		// Setup
+		events := []sync.AuditEvent{
+			{BlockReport: sync.BlockReport{Kind: "copy", Position: 0, Head: 0, Tail: 15, Size: 16, Checksum: "some-hash", Status: sync.BlockVerified}, Entries: []sync.AuditEntry{{Weak: 123, Hash: "some-strong-hash", Head: 0, Tail: 15, Verified: true}}},
+			{BlockReport: sync.BlockReport{Kind: "literal", Position: 16, Size: 1, Checksum: "another-hash", Status: sync.BlockLiteral}},
+		}
+
+		written := []byte{}
+		// Mock
+		writeFile = func(name string, data []byte, perm os.FileMode) error {
+			written = data
+			return nil
+		}
+
+		// Run
+		err := writeAuditLog(events, file)
+		// Verify
+		require.Equal(t, nil, err)
+		lines := strings.Split(strings.TrimSuffix(string(written), "\n"), "\n")
+		require.Equal(t, 2, len(lines))
+		for index, line := range lines {
+			decoded := sync.AuditEvent{}
+			require.Equal(t, nil, json.Unmarshal([]byte(line), &decoded))
+			require.Equal(t, events[index], decoded)
+		}
+	})
+
+	t.Run("should return `UnableToWriteAuditLogError` when unable to write audit log", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWriteAuditLogError)
+		// Mock
+		writeFile = func(name string, data []byte, perm os.FileMode) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		err := writeAuditLog([]sync.AuditEvent{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		writeFile = os.WriteFile
+	})
+}
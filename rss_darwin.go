@@ -0,0 +1,14 @@
+package main
+
+import "syscall"
+
+// getPeakRSS() will return the peak resident set size (bytes) of the current process.
+// Note: macOS reports `Maxrss` in bytes.
+func getPeakRSS() int64 {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+
+	return usage.Maxrss
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package main
+
+// getPeakRSS() will return 0 as peak resident set size is not available on this platform.
+func getPeakRSS() int64 {
+	return 0
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+const selftestFolderPrefix string = "go-file-diff-selftest-"
+
+var (
+	makeTempDir       = os.MkdirTemp
+	removeAll         = os.RemoveAll
+	writeStructToPath = files.WriteStructToPath
+	openDelta         = files.OpenDelta
+	openDeltaMeta     = files.OpenDeltaMetadata
+	openFileAt        = openReaderAt
+	createFile        = os.Create
+)
+
+// ReaderAtCloser interface for mocking the Original file when patching.
+type ReaderAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// hashFile() will generate a SHA256 hash of the full contents of a file.
+// Function returns `hash, nil` when successful.
+// Function returns `"", error` when unable to open or read the file.
+func hashFile(fileName string) (string, error) {
+	reader, err := openFile(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// openReaderAt() will open a local file for random access reads.
+// Returned file will satisfy the `ReaderAtCloser` interface.
+func openReaderAt(fileName string) (ReaderAtCloser, error) {
+	return os.Open(fileName)
+}
+
+// runSelftest() will run Signature -> Delta -> Patch for an Original + Updated file pair entirely within a temp folder (see chooseTempDir()).
+// Signature + Delta will be written to (and read back from) temp files, so the full file format is exercised.
+// Patched output will be verified by comparing its SHA256 hash with the Updated file.
+// A pass/fail line will be logged for each step, followed by an overall result.
+// Temp folder will be removed once the self-test completes.
+// Function returns `nil` when reconstructed file matches the Updated file.
+// Function returns `UnableToCreateTempFolderError` when unable to create temp folder.
+// Function returns `SelftestFailedError` when any step fails, or reconstructed file does not match the Updated file.
+func runSelftest(cmd models.CMD) error {
+	dir, err := makeTempDir(chooseTempDir(cmd), selftestFolderPrefix)
+	if err != nil {
+		return errors.New(constants.UnableToCreateTempFolderError)
+	}
+
+	defer removeAll(dir)
+	err = selftest(cmd, dir)
+	if err != nil {
+		logger(utils.Failure("Self-test: FAIL"), true)
+		return errors.New(constants.SelftestFailedError)
+	}
+
+	logger(utils.Success("Self-test: PASS"), true)
+	return nil
+}
+
+// selftest() will run each step of the self-test within the provided folder, logging a pass/fail line per step.
+// Function returns `nil` when all steps pass.
+// Function returns `error` from the first step which fails.
+func selftest(cmd models.CMD, dir string) error {
+	// Generate Signature
+	signature, err := selftestSignature(cmd, filepath.Join(dir, "signature"))
+	if err != nil {
+		logger(utils.Failure(fmt.Sprintf("Signature: FAIL - %s", err.Error())), true)
+		return err
+	}
+
+	logger(utils.Success(fmt.Sprintf("Signature: PASS - %d weak hashes", len(signature))), true)
+	// Generate Delta
+	patchedFile := cmd.OriginalFile
+	delta, err := selftestDelta(cmd, signature, filepath.Join(dir, "delta"))
+	if err != nil && err.Error() != constants.UpdatedFileHasNoChangesError {
+		logger(utils.Failure(fmt.Sprintf("Delta: FAIL - %s", err.Error())), true)
+		return err
+	}
+
+	if err != nil {
+		// Original file is expected to match Updated file when no changes detected
+		logger(utils.Success("Delta: PASS - no changes detected"), true)
+		logger(utils.Warning("Patch: SKIPPED - no changes to apply"), true)
+	} else {
+		logger(utils.Success(fmt.Sprintf("Delta: PASS - %d blocks", len(delta))), true)
+		// Patch Original file
+		patchedFile = filepath.Join(dir, "patched")
+		err = selftestPatch(cmd, signature, delta, patchedFile)
+		if err != nil {
+			logger(utils.Failure(fmt.Sprintf("Patch: FAIL - %s", err.Error())), true)
+			return err
+		}
+
+		logger(utils.Success("Patch: PASS"), true)
+	}
+
+	// Verify patched file matches Updated file
+	err = selftestVerify(cmd, patchedFile)
+	if err != nil {
+		logger(utils.Failure(fmt.Sprintf("Verify: FAIL - %s", err.Error())), true)
+		return err
+	}
+
+	logger(utils.Success("Verify: PASS - SHA256 hashes match"), true)
+	return nil
+}
+
+// selftestDelta() will generate a Delta of the Updated file, then write the Delta to (and read back from) the provided path.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
+// Function returns `emptyDelta, error` when unable to generate, write, or read the Delta.
+func selftestDelta(cmd models.CMD, signature models.Signature, path string) (models.Delta, error) {
+	reader, err := openUpdated(cmd.UpdatedFile)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	reader, progress := trackProgress(cmd, reader, "Delta", cmd.UpdatedFile)
+	delta, err := generateDelta(reader, signature, cmd.Verbose)
+	progress.Finish()
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return models.Delta{}, err
+		}
+
+		return models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	err = writeStructToPath(delta, path)
+	if err != nil {
+		return models.Delta{}, errors.New(constants.UnableToWriteToDeltaFileError)
+	}
+
+	return openDelta(path, cmd.Verbose)
+}
+
+// selftestPatch() will apply a Delta to the Original file, writing the reconstructed Updated file to the provided path (see writePatch()).
+// Function returns `nil` when successful.
+// Function returns `UnableToReadOriginalFileError` when unable to open the Original file.
+// Function returns `UnableToCreateFileError` when unable to create the patched file.
+// Function returns `error` in the same cases as writePatch().
+func selftestPatch(cmd models.CMD, signature models.Signature, delta models.Delta, path string) error {
+	original, err := openFileAt(cmd.OriginalFile)
+	if err != nil {
+		return errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	defer original.Close()
+	file, err := createFile(path)
+	if err != nil {
+		return errors.New(constants.UnableToCreateFileError)
+	}
+
+	defer file.Close()
+	return writePatch(cmd, signature, delta, original, file)
+}
+
+// writePatch() will apply a Delta to the Original file, writing the reconstructed Updated file to the provided file.
+// When `-strict` flag is set, matched blocks will be verified against the Signature before writing.
+// When `-patchReport` flag is set, matched blocks will be verified against the Signature, and a report of every operation will be written to the provided file.
+// When `-auditLog` flag is set, matched blocks will be verified in the same way, and an NDJSON event for every operation (including each Signature block checked) will be written to the provided file.
+// Function returns `nil` when successful.
+// Function returns `UnableToWriteOutputFileError` when unable to write to the patched file.
+// Function returns `OriginalFileChangedError` when `-strict` flag is set and a matched block does not match the Signature.
+// Function returns `UnableToWritePatchReportError` when `-patchReport` flag is set and unable to write the report.
+// Function returns `UnableToWriteAuditLogError` when `-auditLog` flag is set and unable to write the audit log.
+// Function returns `error` when unable to apply Delta to Original file.
+func writePatch(cmd models.CMD, signature models.Signature, delta models.Delta, original io.ReaderAt, file io.Writer) error {
+	var err error
+	writer := bufio.NewWriter(file)
+	var output io.Writer = writer
+	var progress *utils.Progress
+	if cmd.Progress {
+		progress = utils.NewProgress("Patch", sync.OutputSize(delta), reportProgress)
+		output = utils.NewProgressWriter(writer, progress)
+	}
+
+	defer progress.Finish()
+
+	if cmd.AuditLog != "" {
+		// Audit log (+ report) is written even when verification fails, so mismatched blocks can be inspected
+		var events []sync.AuditEvent
+		events, err = applyDeltaWithAudit(original, delta, signature, output)
+		if auditErr := writeAuditLog(events, cmd.AuditLog); auditErr != nil && err == nil {
+			err = auditErr
+		}
+
+		if cmd.PatchReport != "" {
+			if reportErr := writePatchReport(sync.Reports(events), cmd.PatchReport); reportErr != nil && err == nil {
+				err = reportErr
+			}
+		}
+	} else if cmd.PatchReport != "" {
+		// Report is written even when verification fails, so mismatched blocks can be inspected
+		var report []sync.BlockReport
+		report, err = applyDeltaWithReport(original, delta, signature, output)
+		if reportErr := writePatchReport(report, cmd.PatchReport); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	} else if cmd.Strict {
+		err = applyDeltaStrict(original, delta, signature, output)
+	} else {
+		err = applyDelta(original, delta, output)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = writer.Flush(); err != nil {
+		return errors.New(constants.UnableToWriteOutputFileError)
+	}
+
+	return nil
+}
+
+// selftestSignature() will generate a Signature of the Original file, then write the Signature to (and read back from) the provided path.
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, error` when unable to generate, write, or read the Signature.
+func selftestSignature(cmd models.CMD, path string) (models.Signature, error) {
+	reader, err := openOriginal(cmd.OriginalFile)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	reader, progress := trackProgress(cmd, reader, "Signature", cmd.OriginalFile)
+	signature, err := generateSignature(reader, cmd.Verbose)
+	progress.Finish()
+	if err != nil {
+		return models.Signature{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	err = writeStructToPath(signature, path)
+	if err != nil {
+		return models.Signature{}, errors.New(constants.UnableToWriteToSignatureFileError)
+	}
+
+	return openSignature(path, cmd.Verbose)
+}
+
+// selftestVerify() will compare the SHA256 hash of the patched file with the Updated file.
+// Function returns `nil` when hashes match.
+// Function returns `SelftestHashMismatchError` when hashes do not match.
+// Function returns `error` when unable to hash either file.
+func selftestVerify(cmd models.CMD, patchedFile string) error {
+	patchedHash, err := hashFile(patchedFile)
+	if err != nil {
+		return err
+	}
+
+	updatedHash, err := hashFile(cmd.UpdatedFile)
+	if err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("Patched SHA256: %s\nUpdated SHA256: %s", patchedHash, updatedHash), cmd.Verbose)
+	if patchedHash != updatedHash {
+		return errors.New(constants.SelftestHashMismatchError)
+	}
+
+	return nil
+}
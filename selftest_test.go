@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// resetSelftestMocks() will restore the real file + sync functions used by the self-test.
+func resetSelftestMocks() {
+	openFile = files.OpenFile
+	openSignature = files.OpenSignature
+	openDelta = files.OpenDelta
+	openDeltaMeta = files.OpenDeltaMetadata
+	writeStructToPath = files.WriteStructToPath
+	generateSignature = sync.GenerateSignature
+	generateDelta = sync.GenerateDelta
+	applyDelta = sync.Apply
+	applyDeltaStrict = sync.ApplyStrict
+	applyDeltaWithReport = sync.ApplyWithReport
+	applyDeltaWithAudit = sync.ApplyWithAudit
+	makeTempDir = os.MkdirTemp
+	removeAll = os.RemoveAll
+	openFileAt = openReaderAt
+	createFile = os.Create
+}
+
+// writeSelftestFiles() will create Original + Updated files in a temp folder and return their paths.
+func writeSelftestFiles(t *testing.T, original []byte, updated []byte) (string, string) {
+	dir := t.TempDir()
+	originalFile := filepath.Join(dir, "original.txt")
+	updatedFile := filepath.Join(dir, "updated.txt")
+	require.Equal(t, nil, os.WriteFile(originalFile, original, 0o600))
+	require.Equal(t, nil, os.WriteFile(updatedFile, updated, 0o600))
+	return originalFile, updatedFile
+}
+
+func TestHashFile(t *testing.T) {
+	t.Run("should return SHA256 `hash, nil` of file contents", func(t *testing.T) {
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("hello"))), nil
+		}
+
+		// Run
+		hash, err := hashFile(file)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+	})
+
+	t.Run("should return `\"\", error` when unable to open file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, expectedError
+		}
+
+		// Run
+		hash, err := hashFile(file)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "", hash)
+	})
+}
+
+func TestRunSelftest(t *testing.T) {
+	t.Run("should return `nil` when reconstructed file matches Updated file", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("the quick brown fox jumps over the lazy dog"), []byte("the quick brown cat jumps over the lazy dog!"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		tempDir := ""
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			if verbose {
+				messages = append(messages, message)
+			}
+		}
+
+		makeTempDir = func(dir string, pattern string) (string, error) {
+			tempDir = t.TempDir()
+			return tempDir, nil
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "Patch: PASS", messages[2])
+		require.Equal(t, "Verify: PASS - SHA256 hashes match", messages[3])
+		require.Equal(t, "Self-test: PASS", messages[4])
+		_, err = os.Stat(tempDir)
+		require.Equal(t, true, os.IsNotExist(err))
+	})
+
+	t.Run("should return `nil` after skipping Patch when Updated file has no changes", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("the quick brown fox jumps over the lazy dog"), []byte("the quick brown fox jumps over the lazy dog"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "Delta: PASS - no changes detected", messages[1])
+		require.Equal(t, "Patch: SKIPPED - no changes to apply", messages[2])
+		require.Equal(t, "Self-test: PASS", messages[len(messages)-1])
+	})
+
+	t.Run("should return `UnableToCreateTempFolderError` when unable to create temp folder", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SelftestMode: true, OriginalFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.UnableToCreateTempFolderError)
+		// Mock
+		makeTempDir = func(dir string, pattern string) (string, error) {
+			return "", errors.New(errorMessage)
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `SelftestFailedError` when unable to generate Signature", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		expectedError := errors.New(constants.SelftestFailedError)
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []string{"Signature: FAIL - " + constants.UnableToGenerateSignatureError, "Self-test: FAIL"}, messages)
+	})
+
+	t.Run("should return `SelftestFailedError` when unable to generate Delta", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		expectedError := errors.New(constants.SelftestFailedError)
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(errorMessage)
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "Delta: FAIL - "+constants.UnableToGenerateDeltaError, messages[1])
+	})
+
+	t.Run("should return `SelftestFailedError` when unable to apply Delta", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		expectedError := errors.New(constants.SelftestFailedError)
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		applyDelta = func(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+			return errors.New(constants.InvalidDeltaError)
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "Patch: FAIL - "+constants.InvalidDeltaError, messages[2])
+	})
+
+	t.Run("should return `SelftestFailedError` when reconstructed file does not match Updated file", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		messages := []string{}
+		expectedError := errors.New(constants.SelftestFailedError)
+		// Mock
+		resetSelftestMocks()
+		logger = func(message string, verbose bool) {
+			if verbose {
+				messages = append(messages, message)
+			}
+		}
+
+		applyDelta = func(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+			_, err := out.Write([]byte("corrupt"))
+			return err
+		}
+
+		// Run
+		err := runSelftest(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "Verify: FAIL - "+constants.SelftestHashMismatchError, messages[3])
+	})
+}
+
+func TestSelftestPatch(t *testing.T) {
+	t.Run("should return `UnableToReadOriginalFileError` when unable to open Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SelftestMode: true, OriginalFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := selftestPatch(cmd, models.Signature{}, models.Delta{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToCreateFileError` when unable to create patched file", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile}
+		expectedError := errors.New(constants.UnableToCreateFileError)
+		// Mock
+		openFileAt = openReaderAt
+		createFile = func(name string) (*os.File, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := selftestPatch(cmd, models.Signature{}, models.Delta{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+	t.Run("should verify matched blocks against Signature when `-strict` flag set", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile, Strict: true}
+		expectedError := errors.New(constants.OriginalFileChangedError)
+		verified := false
+		// Mock
+		resetSelftestMocks()
+		applyDeltaStrict = func(original io.ReaderAt, delta models.Delta, signature models.Signature, out io.Writer) error {
+			verified = true
+			return expectedError
+		}
+
+		// Run
+		err := selftestPatch(cmd, testSignature, models.Delta{}, filepath.Join(t.TempDir(), "patched"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, true, verified)
+	})
+
+	t.Run("should write patch report when `-patchReport` flag set", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		reportFile := filepath.Join(t.TempDir(), "report.json")
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile, PatchReport: reportFile}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 3, IsModified: false, Value: []byte{}}}
+		// Mock
+		resetSelftestMocks()
+		writeFile = os.WriteFile
+		marshalReport = json.MarshalIndent
+		// Run
+		err := selftestPatch(cmd, testSignature, delta, filepath.Join(t.TempDir(), "patched"))
+		// Verify
+		require.Equal(t, nil, err)
+		report := []sync.BlockReport{}
+		contents, _ := os.ReadFile(reportFile)
+		require.Equal(t, nil, json.Unmarshal(contents, &report))
+		require.Equal(t, 1, len(report))
+		require.Equal(t, sync.BlockUnverified, report[0].Status)
+		require.Equal(t, int64(4), report[0].Size)
+	})
+
+	t.Run("should write audit log + patch report when `-auditLog` + `-patchReport` flags set", func(t *testing.T) {
+		// Setup
+		originalFile, updatedFile := writeSelftestFiles(t, []byte("original"), []byte("updated"))
+		auditFile := filepath.Join(t.TempDir(), "audit.ndjson")
+		reportFile := filepath.Join(t.TempDir(), "report.json")
+		cmd := models.CMD{SelftestMode: true, OriginalFile: originalFile, UpdatedFile: updatedFile, AuditLog: auditFile, PatchReport: reportFile}
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 3, IsModified: false, Value: []byte{}},
+			4: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+		}
+
+		// Mock
+		resetSelftestMocks()
+		writeFile = os.WriteFile
+		marshalReport = json.MarshalIndent
+		// Run
+		err := selftestPatch(cmd, testSignature, delta, filepath.Join(t.TempDir(), "patched"))
+		// Verify
+		require.Equal(t, nil, err)
+		contents, _ := os.ReadFile(auditFile)
+		lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+		require.Equal(t, 2, len(lines))
+		event := sync.AuditEvent{}
+		require.Equal(t, nil, json.Unmarshal([]byte(lines[1]), &event))
+		require.Equal(t, sync.BlockLiteral, event.Status)
+		report := []sync.BlockReport{}
+		contents, _ = os.ReadFile(reportFile)
+		require.Equal(t, nil, json.Unmarshal(contents, &report))
+		require.Equal(t, 2, len(report))
+	})
+}
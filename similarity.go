@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// similarityWriter type.
+// This will collect Delta blocks, failing once the literal bytes written exceed the budget (EG minimum similarity can no longer be reached).
+// similarityWriter will satisfy the `sync.DeltaWriter` interface.
+type similarityWriter struct {
+	delta   models.Delta
+	budget  int64
+	literal int64
+}
+
+// WriteBlock() will add a block to the Delta, returning `SimilarityBelowThresholdError` when literal bytes exceed the budget.
+func (w *similarityWriter) WriteBlock(position int64, block models.Block) error {
+	if block.IsModified {
+		w.literal += int64(len(block.Value))
+		if w.literal > w.budget {
+			return errors.New(constants.SimilarityBelowThresholdError)
+		}
+	}
+
+	return w.delta.WriteBlock(position, block)
+}
+
+// generateSimilarDelta() will generate a Delta of the Updated file, falling back to a full copy of the Updated file when less than `-minSimilarity` percent of its bytes match the Original file.
+// Delta generation will stop as soon as the literal bytes written make the minimum similarity unreachable, avoiding the cost of generating a Delta which would be discarded.
+// Note: Delta will be generated without a threshold when unable to get the Updated file size.
+// Function returns `delta, nil` when Updated file meets the minimum similarity.
+// Function returns `fullCopyDelta, nil` when Updated file is below the minimum similarity.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
+// Function returns `emptyDelta, UnableToReadUpdatedFileError` when unable to read the Updated file for the full copy.
+// Function returns `emptyDelta, error` when unable to generate Delta.
+func generateSimilarDelta(cmd models.CMD, reader *bufio.Reader, signature models.Signature) (models.Delta, error) {
+	size, err := fileSize(cmd.UpdatedFile)
+	if err != nil || size == 0 {
+		return generateDelta(reader, signature, cmd.Verbose)
+	}
+
+	required := (size*int64(cmd.MinSimilarity) + 99) / 100
+	writer := &similarityWriter{delta: models.Delta{}, budget: size - required}
+	err = generateDeltaTo(reader, signature, writer, sync.Hooks{}, cmd.Verbose)
+	if err != nil && err.Error() != constants.SimilarityBelowThresholdError {
+		return models.Delta{}, err
+	}
+
+	matched, _ := deltaStats(writer.delta)
+	if err == nil && matched >= required {
+		return writer.delta, nil
+	}
+
+	logger(fmt.Sprintf("Similarity below %d%%, using full copy of Updated file", cmd.MinSimilarity), cmd.Verbose)
+	return readFullCopy(cmd)
+}
+
+// formatSimilarity() will format the counted blocks as the percentage of Updated file bytes shared with the Original file.
+// EG: `Similarity: 75.00% (75 of 100 bytes shared)`.
+func formatSimilarity(counter transferCounter) string {
+	total := counter.matchedBytes + counter.literalBytes
+	percentage := 0.0
+	if total > 0 {
+		percentage = float64(counter.matchedBytes) / float64(total) * 100
+	}
+
+	return fmt.Sprintf("Similarity: %.2f%% (%d of %d bytes shared)", percentage, counter.matchedBytes, total)
+}
+
+// runSimilarity() will report the percentage of bytes in the Updated file which are shared with the Original file.
+// Signature of the Original file will be generated in memory, and the Delta will be counted block by block, so neither is written to file.
+// Function returns `counter, nil` when successful (including when Updated file has no changes).
+// Function returns `emptyCounter, error` when unable to open the Original or Updated file.
+// Function returns `emptyCounter, UnableToGenerateSignatureError` when unable to generate Signature.
+// Function returns `emptyCounter, UnableToGenerateDeltaError` when unable to generate Delta.
+func runSimilarity(cmd models.CMD) (transferCounter, error) {
+	original, err := openOriginal(cmd.OriginalFile)
+	if err != nil {
+		return transferCounter{}, err
+	}
+
+	signature, err := generateSignature(original, cmd.Verbose)
+	if err != nil {
+		return transferCounter{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	updated, err := openUpdated(cmd.UpdatedFile)
+	if err != nil {
+		return transferCounter{}, err
+	}
+
+	counter := transferCounter{}
+	err = generateDeltaTo(updated, signature, &counter, sync.Hooks{}, cmd.Verbose)
+	if err != nil && err.Error() != constants.UpdatedFileHasNoChangesError {
+		return transferCounter{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	logger(utils.Stat(formatSimilarity(counter)), true)
+	return counter, nil
+}
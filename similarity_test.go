@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSimilarity(t *testing.T) {
+	t.Run("should return percentage of bytes shared", func(t *testing.T) {
+		// Setup
+		counter := transferCounter{matchedBlocks: 1, literalBlocks: 1, matchedBytes: 75, literalBytes: 25}
+		// Run
+		line := formatSimilarity(counter)
+		// Verify
+		require.Equal(t, "Similarity: 75.00% (75 of 100 bytes shared)", line)
+	})
+
+	t.Run("should return 0% when Updated file is empty", func(t *testing.T) {
+		// Run
+		line := formatSimilarity(transferCounter{})
+		// Verify
+		require.Equal(t, "Similarity: 0.00% (0 of 0 bytes shared)", line)
+	})
+}
+
+func TestGenerateSimilarDelta(t *testing.T) {
+	contents := []byte("0123456789")
+	matchedBlock := models.Block{Head: 0, Tail: 4, Value: []byte{}}
+	literalBlock := models.Block{Head: 5, Tail: 9, IsModified: true, Value: []byte("56789")}
+	t.Run("should return `delta, nil` when Updated file meets minimum similarity", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, MinSimilarity: 50}
+		// Mock
+		deltaStats = sync.Stats
+		fileSize = func(fileName string) (int64, error) {
+			return int64(len(contents)), nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			require.Equal(t, nil, writer.WriteBlock(0, matchedBlock))
+			return writer.WriteBlock(5, literalBlock)
+		}
+
+		// Run
+		delta, err := generateSimilarDelta(cmd, bufio.NewReader(bytes.NewReader(contents)), testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Delta{0: matchedBlock, 5: literalBlock}, delta)
+	})
+
+	t.Run("should return `fullCopyDelta, nil` when literal bytes make minimum similarity unreachable", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, MinSimilarity: 60}
+		written := 0
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			if err := writer.WriteBlock(0, literalBlock); err != nil {
+				return err
+			}
+
+			written++
+			return writer.WriteBlock(5, matchedBlock)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader(contents)), nil
+		}
+
+		readAll = io.ReadAll
+		// Run
+		delta, err := generateSimilarDelta(cmd, bufio.NewReader(bytes.NewReader(contents)), testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, sync.FullCopy(contents), delta)
+		require.Equal(t, 0, written)
+	})
+
+	t.Run("should return `fullCopyDelta, nil` when generated Delta is below minimum similarity", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, MinSimilarity: 60}
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return writer.WriteBlock(0, matchedBlock)
+		}
+
+		// Run
+		delta, err := generateSimilarDelta(cmd, bufio.NewReader(bytes.NewReader(contents)), testSignature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, sync.FullCopy(contents), delta)
+	})
+
+	t.Run("should return `emptyDelta, error` when Delta generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, UpdatedFile: file, MinSimilarity: 60}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return expectedError
+		}
+
+		// Run
+		delta, err := generateSimilarDelta(cmd, bufio.NewReader(bytes.NewReader(contents)), testSignature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+		fileSize = files.FileSize
+		generateDeltaTo = sync.GenerateDeltaTo
+	})
+}
+
+func TestRunSimilarity(t *testing.T) {
+	t.Run("should log + return `counter, nil` for bytes shared between files", func(t *testing.T) {
+		// Setup
+		original := []byte("the quick brown fox jumps over the lazy dog")
+		updated := []byte("the quick brown cat jumps over the lazy dog")
+		cmd := models.CMD{Similarity: true, OriginalFile: "original", UpdatedFile: "updated"}
+		logged := ""
+		// Mock
+		generateSignature = sync.GenerateSignature
+		generateDeltaTo = sync.GenerateDeltaTo
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			if fileName == "original" {
+				return bufio.NewReader(bytes.NewReader(original)), nil
+			}
+
+			return bufio.NewReader(bytes.NewReader(updated)), nil
+		}
+
+		logger = func(message string, verbose bool) {
+			logged = message
+		}
+
+		// Run
+		counter, err := runSimilarity(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(len(updated)), counter.matchedBytes+counter.literalBytes)
+		require.Equal(t, true, counter.matchedBytes > 0 && counter.literalBytes > 0)
+		require.Equal(t, utils.Stat(formatSimilarity(counter)), logged)
+	})
+
+	t.Run("should return `emptyCounter, UnableToGenerateSignatureError` when unable to generate Signature", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Similarity: true, OriginalFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		counter, err := runSimilarity(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+	})
+
+	t.Run("should return `emptyCounter, UnableToGenerateDeltaError` when unable to generate Delta", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{Similarity: true, OriginalFile: file, UpdatedFile: file}
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDeltaTo = func(reader sync.Reader, signature models.Signature, writer sync.DeltaWriter, hooks sync.Hooks, verbose bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		counter, err := runSimilarity(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, transferCounter{}, counter)
+		generateSignature = sync.GenerateSignature
+		generateDeltaTo = sync.GenerateDeltaTo
+	})
+}
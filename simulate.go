@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/mutate"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+var (
+	mutateData = mutate.Mutate
+	newRandom  = mutate.NewRandom
+)
+
+// roundTrip() will run the full Signature -> Delta -> Patch pipeline for an Original + Updated file held in memory.
+// Function returns `patchedData, delta, nil` when successful.
+// Note: when Updated file contains no changes, patched data will be a copy of the Original file and Delta will be empty.
+// Function returns `nil, emptyDelta, UnableToGenerateSignatureError` when unable to generate Signature of Original file.
+// Function returns `nil, emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta of Updated file.
+// Function returns `nil, delta, error` when unable to apply Delta to Original file.
+func roundTrip(original []byte, updated []byte) ([]byte, models.Delta, error) {
+	// Generate Signature of Original
+	signature, err := generateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+	if err != nil {
+		return nil, models.Delta{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	// Generate Delta of Updated
+	delta, err := generateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+	if err != nil {
+		// Patched file will match Original when no changes detected
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return append([]byte{}, original...), models.Delta{}, nil
+		}
+
+		return nil, models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	// Patch Original with Delta
+	var patched bytes.Buffer
+	err = applyDelta(bytes.NewReader(original), delta, &patched)
+	if err != nil {
+		return nil, delta, err
+	}
+
+	return patched.Bytes(), delta, nil
+}
+
+// runSimulation() will apply random mutations to the Original file and verify the Signature -> Delta -> Patch pipeline reproduces each mutated file.
+// Each simulation will use `Seed + index` as its random seed, so failed simulations can be reproduced individually.
+// Function returns `nil` when all simulations pass.
+// Function returns `error` when unable to read the Original file.
+// Function returns `SimulationFailedError` when any simulation fails.
+func runSimulation(cmd models.CMD) error {
+	original, err := readOriginal(cmd.OriginalFile)
+	if err != nil {
+		return err
+	}
+
+	failures := 0
+	for index := 0; index < cmd.Simulations; index++ {
+		seed := cmd.Seed + int64(index)
+		updated := mutateData(original, cmd.Mutations, newRandom(seed))
+		patched, delta, err := roundTrip(original, updated)
+		if err != nil {
+			failures++
+			logger(utils.Failure(fmt.Sprintf("Simulation %d (seed %d): FAIL - %s", index+1, seed, err.Error())), true)
+			continue
+		}
+
+		if !bytes.Equal(patched, updated) {
+			failures++
+			logger(utils.Failure(fmt.Sprintf("Simulation %d (seed %d): FAIL - patched file does not match Updated file", index+1, seed)), true)
+			continue
+		}
+
+		logger(utils.Success(fmt.Sprintf("Simulation %d (seed %d): PASS - %d Delta blocks", index+1, seed, len(delta))), cmd.Verbose)
+	}
+
+	logger(utils.Stat(fmt.Sprintf("Simulations passed: %d/%d", cmd.Simulations-failures, cmd.Simulations)), true)
+	if failures > 0 {
+		return errors.New(constants.SimulationFailedError)
+	}
+
+	return nil
+}
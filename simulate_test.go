@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/mutate"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Run("should return `patchedData, delta, nil` when Updated file reconstructed successfully", func(t *testing.T) {
+		// Setup
+		original := []byte("the quick brown fox jumps over the lazy dog")
+		updated := []byte("the quick brown cat jumps over the lazy dog!")
+		// Mock
+		generateSignature = sync.GenerateSignature
+		generateDelta = sync.GenerateDelta
+		applyDelta = sync.Apply
+		// Run
+		patched, delta, err := roundTrip(original, updated)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, updated, patched)
+		require.NotEqual(t, 0, len(delta))
+	})
+
+	t.Run("should return `originalData, emptyDelta, nil` when Updated file has no changes", func(t *testing.T) {
+		// Setup
+		original := []byte("the quick brown fox jumps over the lazy dog")
+		// Mock
+		generateSignature = sync.GenerateSignature
+		generateDelta = sync.GenerateDelta
+		// Run
+		patched, delta, err := roundTrip(original, original)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, original, patched)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `nil, emptyDelta, UnableToGenerateSignatureError` when Signature generation fails", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		patched, delta, err := roundTrip([]byte("original"), []byte("updated"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, patched)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `nil, emptyDelta, UnableToGenerateDeltaError` when Delta generation fails", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToGenerateDeltaError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return models.Delta{}, errors.New(errorMessage)
+		}
+
+		// Run
+		patched, delta, err := roundTrip([]byte("original"), []byte("updated"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, patched)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `nil, delta, error` when unable to apply Delta", func(t *testing.T) {
+		// Setup
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a'}}}
+		expectedError := errors.New(constants.InvalidDeltaError)
+		// Mock
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		generateDelta = func(reader sync.Reader, signature models.Signature, verbose bool) (models.Delta, error) {
+			return expectedDelta, nil
+		}
+
+		applyDelta = func(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+			return expectedError
+		}
+
+		// Run
+		patched, delta, err := roundTrip([]byte("original"), []byte("updated"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Nil(t, patched)
+		require.Equal(t, expectedDelta, delta)
+	})
+}
+
+func TestRunSimulation(t *testing.T) {
+	t.Run("should return `nil` when all simulations pass", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+			OriginalFile: file,
+			Simulations:  5,
+			Mutations:    models.Mutations{Insertions: 1, Deletions: 1, Moves: 1, MaxSize: 8},
+			Seed:         1,
+		}
+
+		messages := []string{}
+		// Mock
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("the quick brown fox jumps over the lazy dog"))), nil
+		}
+
+		readAll = io.ReadAll
+		generateSignature = sync.GenerateSignature
+		generateDelta = sync.GenerateDelta
+		applyDelta = sync.Apply
+		mutateData = mutate.Mutate
+		newRandom = mutate.NewRandom
+		// Run
+		err := runSimulation(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "Simulations passed: 5/5", messages[len(messages)-1])
+	})
+
+	t.Run("should return `SimulationFailedError` when patched file does not match Updated file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+			OriginalFile: file,
+			Simulations:  2,
+			Seed:         7,
+		}
+
+		messages := []string{}
+		expectedError := errors.New(constants.SimulationFailedError)
+		// Mock
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("the quick brown fox jumps over the lazy dog"))), nil
+		}
+
+		mutateData = func(data []byte, mutations models.Mutations, random mutate.Random) []byte {
+			return []byte("the quick brown cat jumps over the lazy dog")
+		}
+
+		applyDelta = func(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+			_, err := out.Write([]byte("corrupt"))
+			return err
+		}
+
+		// Run
+		err := runSimulation(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []string{
+			"Simulation 1 (seed 7): FAIL - patched file does not match Updated file",
+			"Simulation 2 (seed 8): FAIL - patched file does not match Updated file",
+			"Simulations passed: 0/2",
+		}, messages)
+	})
+
+	t.Run("should return `SimulationFailedError` when unable to complete round trip", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{
+			SimulateMode: true,
+			OriginalFile: file,
+			Simulations:  1,
+			Seed:         1,
+		}
+
+		messages := []string{}
+		expectedError := errors.New(constants.SimulationFailedError)
+		// Mock
+		logger = func(message string, verbose bool) {
+			messages = append(messages, message)
+		}
+
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader([]byte("some file contents"))), nil
+		}
+
+		generateSignature = func(reader sync.Reader, verbose bool) (models.Signature, error) {
+			return models.Signature{}, errors.New(errorMessage)
+		}
+
+		// Run
+		err := runSimulation(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, "Simulation 1 (seed 1): FAIL - "+constants.UnableToGenerateSignatureError, messages[0])
+	})
+
+	t.Run("should return error when unable to read Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SimulateMode: true, OriginalFile: file, Simulations: 1}
+		expectedError := errors.New(constants.OriginalFileDoesNotExistError)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return nil, errors.New(constants.FileDoesNotExistError)
+		}
+
+		// Run
+		err := runSimulation(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
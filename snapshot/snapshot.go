@@ -0,0 +1,222 @@
+// Package snapshot provides incremental file versioning built on Signature, Delta, and Patch (EG embedding in backup tools).
+// Each version will be stored as a Delta against the previous version, so only changed data is kept for each Snapshot.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+const (
+	versionsRecord  string = "versions"  // History of every version
+	signatureRecord string = "signature" // Signature of the latest version
+)
+
+var now = time.Now
+
+// Version type.
+// This will describe a single version of a Snapshot history, numbered from 1 in the order created.
+// Checksum will be the SHA256 hash of the version's data, verified when the version is restored.
+// EG: Version{Number: 2, Created: time.Time{...}, Size: 1024, Checksum: "some-sha256-hash", DeltaSize: 120}.
+type Version struct {
+	Number    int       `json:"number"`
+	Created   time.Time `json:"created"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	DeltaSize int64     `json:"deltaSize"`
+}
+
+// Snapshot interface for incremental file versioning.
+// CreateSnapshot() will store a new version of a file as a Delta against the previous version.
+// ListVersions() will return every stored version, oldest first.
+// Restore() will reconstruct a stored version, writing its data to the provided writer.
+type Snapshot interface {
+	CreateSnapshot(reader io.Reader) (Version, error)
+	ListVersions() ([]Version, error)
+	Restore(version int, writer io.Writer) error
+}
+
+// History type.
+// This will keep the versions of a single file within a Store, with the Signature of the latest version so new versions only need a single pass.
+// Note: versions will be read into memory when created + restored, and a History must not be written by concurrent CreateSnapshot() calls.
+// History will satisfy the `Snapshot` interface.
+type History struct {
+	store Store
+}
+
+// New() will create a History keeping its versions within the provided Store.
+func New(store Store) *History {
+	return &History{store: store}
+}
+
+// deltaRecord() will return the name of the record containing the Delta of a version.
+func deltaRecord(version int) string {
+	return fmt.Sprintf("delta-%d", version)
+}
+
+// CreateSnapshot() will read a new version of the file, storing it as a Delta against the latest version (or as literal data for the first version).
+// Function returns `version, nil` when successful (including when the data has no changes from the latest version).
+// Function returns `emptyVersion, UnableToReadSnapshotError` when unable to read the data, or the stored history.
+// Function returns `emptyVersion, UnableToGenerateDeltaError` when unable to generate the Delta or Signature.
+// Function returns `emptyVersion, UnableToWriteSnapshotError` when unable to store the version.
+func (h *History) CreateSnapshot(reader io.Reader) (Version, error) {
+	versions, err := h.ListVersions()
+	if err != nil {
+		return Version{}, err
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Version{}, errors.New(constants.UnableToReadSnapshotError)
+	}
+
+	// Diff against the latest version (an empty Signature sends every byte of the first version as literal data)
+	previous, previousSize := models.Signature{}, int64(0)
+	if len(versions) > 0 {
+		if err := h.read(signatureRecord, &previous); err != nil {
+			return Version{}, errors.New(constants.UnableToReadSnapshotError)
+		}
+
+		previousSize = versions[len(versions)-1].Size
+	}
+
+	delta, err := sync.GenerateDelta(bufio.NewReader(bytes.NewReader(data)), previous, false)
+	if err != nil && err.Error() == constants.UpdatedFileHasNoChangesError {
+		delta = copyAll(previousSize)
+	} else if err != nil {
+		return Version{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	signature, err := sync.GenerateSignature(bufio.NewReader(bytes.NewReader(data)), false)
+	if err != nil {
+		return Version{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	checksum := sha256.Sum256(data)
+	version := Version{Number: len(versions) + 1, Created: now().UTC(), Size: int64(len(data)), Checksum: hex.EncodeToString(checksum[:])}
+	if version.DeltaSize, err = h.write(deltaRecord(version.Number), delta); err != nil {
+		return Version{}, err
+	}
+
+	if _, err = h.write(signatureRecord, signature); err != nil {
+		return Version{}, err
+	}
+
+	// Versions are written last, so an interrupted snapshot is never listed
+	if _, err = h.write(versionsRecord, append(versions, version)); err != nil {
+		return Version{}, err
+	}
+
+	return version, nil
+}
+
+// ListVersions() will return every stored version, oldest first.
+// Function returns `versions, nil` when successful (empty when no versions have been created).
+// Function returns `nil, UnableToReadSnapshotError` when unable to read the stored history.
+func (h *History) ListVersions() ([]Version, error) {
+	versions := []Version{}
+	if err := h.read(versionsRecord, &versions); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []Version{}, nil
+		}
+
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// Restore() will reconstruct a stored version by applying the Delta of every version up to it in order, then write its data to the provided writer.
+// Function returns `nil` when successful.
+// Function returns `SnapshotVersionNotFoundError` when the version has not been created.
+// Function returns `UnableToReadSnapshotError` when unable to read the stored history (EG a missing Delta).
+// Function returns `SnapshotChecksumMismatchError` when the reconstructed data does not match the version's checksum (EG a corrupted record).
+// Function returns `error` when unable to apply a Delta, or write to the writer.
+func (h *History) Restore(version int, writer io.Writer) error {
+	versions, err := h.ListVersions()
+	if err != nil {
+		return err
+	}
+
+	if version < 1 || version > len(versions) {
+		return errors.New(constants.SnapshotVersionNotFoundError)
+	}
+
+	data := []byte{}
+	for number := 1; number <= version; number++ {
+		delta := models.Delta{}
+		if err := h.read(deltaRecord(number), &delta); err != nil {
+			return errors.New(constants.UnableToReadSnapshotError)
+		}
+
+		var output bytes.Buffer
+		if err := sync.Apply(bytes.NewReader(data), delta, &output); err != nil {
+			return err
+		}
+
+		data = output.Bytes()
+	}
+
+	if checksum := sha256.Sum256(data); hex.EncodeToString(checksum[:]) != versions[version-1].Checksum {
+		return errors.New(constants.SnapshotChecksumMismatchError)
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
+// copyAll() will return a Delta copying every byte of a previous version of the provided size (EG when a version has no changes).
+func copyAll(size int64) models.Delta {
+	if size == 0 {
+		return models.Delta{}
+	}
+
+	return models.Delta{0: models.Block{Head: 0, Tail: size - 1, IsModified: false, Value: []byte{}}}
+}
+
+// read() will decode a gob encoded record from the Store.
+// Function returns `nil` when successful.
+// Function returns `error` wrapping `fs.ErrNotExist` when the record does not exist.
+// Function returns `UnableToReadSnapshotError` when unable to read or decode the record.
+func (h *History) read(name string, model any) error {
+	data, err := h.store.Read(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return err
+	} else if err != nil {
+		return errors.New(constants.UnableToReadSnapshotError)
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(model); err != nil {
+		return errors.New(constants.UnableToReadSnapshotError)
+	}
+
+	return nil
+}
+
+// write() will gob encode a record and write it to the Store.
+// Function returns `size, nil` when successful.
+// Function returns `0, UnableToWriteSnapshotError` when unable to encode or write the record.
+func (h *History) write(name string, model any) (int64, error) {
+	var output bytes.Buffer
+	if err := gob.NewEncoder(&output).Encode(model); err != nil {
+		return 0, errors.New(constants.UnableToWriteSnapshotError)
+	}
+
+	if err := h.store.Write(name, output.Bytes()); err != nil {
+		return 0, errors.New(constants.UnableToWriteSnapshotError)
+	}
+
+	return int64(output.Len()), nil
+}
@@ -0,0 +1,206 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+var testVersions = [][]byte{
+	[]byte("The quick brown fox jumps over the lazy dog, and runs far away.."),
+	[]byte("The quick brown fox leaps over the lazy dog, and runs far away!!"),
+	[]byte("The quick brown fox leaps over the lazy dog, and runs far away!!"),
+	[]byte(""),
+	[]byte("A new start: the quick brown fox leaps over the lazy dog"),
+}
+
+// failingStore type.
+// This will fail every read + write of a Store.
+type failingStore struct{}
+
+func (failingStore) Read(name string) ([]byte, error) {
+	return nil, errors.New("some-error")
+}
+
+func (failingStore) Write(name string, data []byte) error {
+	return errors.New("some-error")
+}
+
+// writeOnlyFailingStore type.
+// This will read from the wrapped Store, failing every write.
+type writeOnlyFailingStore struct {
+	*MemoryStore
+}
+
+func (writeOnlyFailingStore) Write(name string, data []byte) error {
+	return errors.New("some-error")
+}
+
+// createHistory() will create a History containing every test version.
+func createHistory(t *testing.T, store Store) *History {
+	history := New(store)
+	for _, data := range testVersions {
+		_, err := history.CreateSnapshot(bytes.NewReader(data))
+		require.Equal(t, nil, err)
+	}
+
+	return history
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	t.Run("should return `version, nil` numbering versions in the order created", func(t *testing.T) {
+		// Setup
+		created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		history := New(NewMemoryStore())
+		// Mock
+		now = func() time.Time {
+			return created
+		}
+
+		// Run
+		first, err := history.CreateSnapshot(bytes.NewReader(testVersions[0]))
+		require.Equal(t, nil, err)
+		second, err := history.CreateSnapshot(bytes.NewReader(testVersions[1]))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 1, first.Number)
+		require.Equal(t, 2, second.Number)
+		require.Equal(t, created, second.Created)
+		require.Equal(t, int64(len(testVersions[1])), second.Size)
+		// Only the changed data is stored for later versions
+		require.Less(t, second.DeltaSize, first.DeltaSize)
+		// Restore, so later tests record the current time
+		now = time.Now
+	})
+
+	t.Run("should return `emptyVersion, UnableToWriteSnapshotError` when unable to write to the Store", func(t *testing.T) {
+		// Setup
+		history := New(writeOnlyFailingStore{NewMemoryStore()})
+		expectedError := errors.New(constants.UnableToWriteSnapshotError)
+		// Run
+		version, err := history.CreateSnapshot(bytes.NewReader(testVersions[0]))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, Version{}, version)
+	})
+
+	t.Run("should return `emptyVersion, UnableToReadSnapshotError` when unable to read from the Store", func(t *testing.T) {
+		// Setup
+		history := New(failingStore{})
+		expectedError := errors.New(constants.UnableToReadSnapshotError)
+		// Run
+		version, err := history.CreateSnapshot(bytes.NewReader(testVersions[0]))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, Version{}, version)
+	})
+}
+
+func TestListVersions(t *testing.T) {
+	t.Run("should return `versions, nil` oldest first", func(t *testing.T) {
+		// Setup
+		history := createHistory(t, NewMemoryStore())
+		// Run
+		versions, err := history.ListVersions()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, len(testVersions), len(versions))
+		for index, version := range versions {
+			require.Equal(t, index+1, version.Number)
+			require.Equal(t, int64(len(testVersions[index])), version.Size)
+		}
+	})
+
+	t.Run("should return `emptyVersions, nil` when no versions have been created", func(t *testing.T) {
+		// Run
+		versions, err := New(NewMemoryStore()).ListVersions()
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []Version{}, versions)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("should restore every version when stored in memory or in a folder", func(t *testing.T) {
+		for _, store := range []Store{NewMemoryStore(), NewDirStore(t.TempDir())} {
+			// Setup
+			history := createHistory(t, store)
+			for index, expected := range testVersions {
+				var output bytes.Buffer
+				// Run
+				err := history.Restore(index+1, &output)
+				// Verify
+				require.Equal(t, nil, err)
+				require.Equal(t, string(expected), output.String())
+			}
+		}
+	})
+
+	t.Run("should return `SnapshotVersionNotFoundError` when version has not been created", func(t *testing.T) {
+		// Setup
+		history := createHistory(t, NewMemoryStore())
+		expectedError := errors.New(constants.SnapshotVersionNotFoundError)
+		for _, version := range []int{0, len(testVersions) + 1} {
+			// Run
+			err := history.Restore(version, &bytes.Buffer{})
+			// Verify
+			require.Equal(t, expectedError, err)
+		}
+	})
+
+	t.Run("should return `UnableToReadSnapshotError` when a Delta is missing", func(t *testing.T) {
+		// Setup
+		store := NewMemoryStore()
+		history := createHistory(t, store)
+		delete(store.records, deltaRecord(2))
+		expectedError := errors.New(constants.UnableToReadSnapshotError)
+		// Run
+		err := history.Restore(3, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `SnapshotChecksumMismatchError` when restored data does not match the version", func(t *testing.T) {
+		// Setup
+		store := NewMemoryStore()
+		history := createHistory(t, store)
+		// Literal Delta of the final version (created after an empty version)
+		delta, err := store.Read(deltaRecord(len(testVersions)))
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, store.Write(deltaRecord(1), delta))
+		expectedError := errors.New(constants.SnapshotChecksumMismatchError)
+		// Run
+		err = history.Restore(1, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestStore(t *testing.T) {
+	t.Run("should return `nil, fs.ErrNotExist` when record does not exist", func(t *testing.T) {
+		for _, store := range []Store{NewMemoryStore(), NewDirStore(t.TempDir())} {
+			// Run
+			data, err := store.Read("missing")
+			// Verify
+			require.Equal(t, true, errors.Is(err, fs.ErrNotExist))
+			require.Equal(t, 0, len(data))
+		}
+	})
+
+	t.Run("should replace existing records", func(t *testing.T) {
+		for _, store := range []Store{NewMemoryStore(), NewDirStore(t.TempDir() + "/history")} {
+			// Run
+			require.Equal(t, nil, store.Write("record", []byte("first")))
+			require.Equal(t, nil, store.Write("record", []byte("second")))
+			data, err := store.Read("record")
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, []byte("second"), data)
+		}
+	})
+}
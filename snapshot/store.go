@@ -0,0 +1,91 @@
+package snapshot
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/curtismenmuir/go-file-diff/files"
+)
+
+// Store interface.
+// This will persist the named records of a Snapshot history (EG a folder, object storage or a database).
+// Read() must return an error wrapping `fs.ErrNotExist` when no record has been written with the provided name.
+type Store interface {
+	Read(name string) ([]byte, error)
+	Write(name string, data []byte) error
+}
+
+// MemoryStore type.
+// This will keep Snapshot records in memory (EG tests, or short lived histories).
+// MemoryStore will satisfy the `Store` interface, and is safe for concurrent use.
+type MemoryStore struct {
+	lock    sync.Mutex
+	records map[string][]byte
+}
+
+// NewMemoryStore() will create an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string][]byte{}}
+}
+
+// Read() will return a copy of the record with the provided name.
+// Function returns `data, nil` when successful.
+// Function returns `nil, fs.ErrNotExist` when no record exists.
+func (s *MemoryStore) Read(name string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, exists := s.records[name]
+	if !exists {
+		return nil, fs.ErrNotExist
+	}
+
+	return append([]byte{}, data...), nil
+}
+
+// Write() will store a copy of the record with the provided name, replacing any existing record.
+func (s *MemoryStore) Write(name string, data []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records[name] = append([]byte{}, data...)
+	return nil
+}
+
+// DirStore type.
+// This will keep each Snapshot record as a file within a folder (created on first write).
+// Records will be written to a temp file within the same folder before being renamed into place, so an interrupted write never replaces a complete record.
+// DirStore will satisfy the `Store` interface.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore() will create a DirStore keeping records within the provided folder.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Read() will read the record with the provided name from the folder.
+// Function returns `data, nil` when successful.
+// Function returns `nil, error` wrapping `fs.ErrNotExist` when no record exists.
+func (s *DirStore) Read(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.dir, name))
+}
+
+// Write() will write the record with the provided name to the folder, replacing any existing record.
+// Function returns `nil` when successful.
+// Function returns `error` when unable to create the folder, or write + rename the record into place.
+func (s *DirStore) Write(name string, data []byte) error {
+	if err := os.MkdirAll(s.dir, files.DirMode()); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, name)
+	temp := path + files.PartialExtension
+	if err := os.WriteFile(temp, data, files.FileMode()); err != nil {
+		_ = os.Remove(temp)
+		return err
+	}
+
+	return os.Rename(temp, path)
+}
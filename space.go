@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Approximate encoded size of each Signature block (Weak hash key, Strong hash + Head / Tail positions)
+const signatureBlockSize int64 = 80
+
+var (
+	freeSpace = files.FreeSpace
+	tempDir   = os.TempDir
+)
+
+// estimateSignatureSize() will estimate the size of the Signature file generated from an Original file of the provided size.
+// Note: a Signature contains a block at every offset of the Original file, unless pruned or sampled (see `-signatureStride`, `-maxSignatureEntries` + `-sparse`).
+func estimateSignatureSize(cmd models.CMD, originalSize int64) int64 {
+	blocks := originalSize
+	if cmd.Sparse > 1 {
+		blocks = originalSize/int64(cmd.Sparse) + 1
+	} else if cmd.Stride > 1 {
+		blocks = originalSize/int64(cmd.Stride) + 1
+	}
+
+	if cmd.MaxEntries > 0 && blocks > cmd.MaxEntries {
+		blocks = cmd.MaxEntries + 1
+	}
+
+	return blocks * signatureBlockSize
+}
+
+// requiredSpace() will estimate the space required by the output files of the enabled mode(s) (EG Signature, Delta + patched files), and by temp files (EG Selftest mode).
+// Note: the Updated file size will be used as an upper bound of the Delta (EG every byte sent as literal data), and missing files will count as empty.
+// Note: the patched file size is only known when the Updated file is provided, so is not counted when patching from a Delta file alone.
+func requiredSpace(cmd models.CMD) (outputs int64, temp int64) {
+	originalSize, _ := fileSize(cmd.OriginalFile)
+	updatedSize, _ := fileSize(cmd.UpdatedFile)
+	if cmd.SignatureMode {
+		outputs += estimateSignatureSize(cmd, originalSize)
+	}
+
+	if cmd.DeltaMode && !isStreamingToStdout(cmd) {
+		outputs += updatedSize
+	}
+
+	if cmd.PatchMode {
+		outputs += updatedSize
+	}
+
+	// Selftest mode writes the Signature, Delta + patched file to a temp folder
+	if cmd.SelftestMode {
+		temp += estimateSignatureSize(cmd, originalSize) + 2*updatedSize
+	}
+
+	return outputs, temp
+}
+
+// spaceCheck type.
+// This will describe the space required within a folder by the output or temp files of a run.
+type spaceCheck struct {
+	name     string
+	folder   string
+	required int64
+}
+
+// spaceChecks() will return the space required within the Outputs folder + temp folder by the enabled mode(s), skipping folders where no space is required.
+func spaceChecks(cmd models.CMD) []spaceCheck {
+	outputs, temp := requiredSpace(cmd)
+	checks := []spaceCheck{}
+	if outputs > 0 {
+		checks = append(checks, spaceCheck{"Disk space", outputFolder(), outputs})
+	}
+
+	if temp > 0 {
+		checks = append(checks, spaceCheck{"Temp space", chooseTempDir(cmd), temp})
+	}
+
+	return checks
+}
+
+// verifyFreeSpace() will check the Outputs folder + temp folder have enough free space for the estimated output + temp files, before any are written.
+// This allows a run to fail early, rather than running out of space part way through writing a large output file.
+// Note: the check will be skipped when `-noSpaceCheck` flag set, or free space can not be checked (EG unsupported platform).
+// Function returns `nil` when there is enough free space.
+// Function returns `InsufficientSpaceError` (describing the free + required space) when a folder does not have enough free space.
+func verifyFreeSpace(cmd models.CMD) error {
+	if cmd.NoSpaceCheck {
+		return nil
+	}
+
+	for _, check := range spaceChecks(cmd) {
+		free, err := freeSpace(check.folder)
+		if err != nil || free >= check.required {
+			continue
+		}
+
+		return fmt.Errorf("%s (`%s` has %d bytes free, but up to %d bytes are needed, see -noSpaceCheck)", constants.InsufficientSpaceError, check.folder, free, check.required)
+	}
+
+	return nil
+}
+
+// chooseTempDir() will choose the folder with the most free space from the comma separated `-tempDirs` flag (or the system temp folder), for temp files which are not renamed into place (EG Selftest mode).
+// Note: temp files which are renamed into place (EG partial outputs + Delta cache entries) are always written alongside their final path, so the rename stays on the same filesystem.
+// Note: folders where free space can not be checked (EG missing folders) will be skipped, unless no folder can be checked (first folder returned).
+func chooseTempDir(cmd models.CMD) string {
+	folders := []string{}
+	for _, folder := range strings.Split(cmd.TempDirs, ",") {
+		if folder = strings.TrimSpace(folder); folder != "" {
+			folders = append(folders, folder)
+		}
+	}
+
+	if len(folders) == 0 {
+		return tempDir()
+	}
+
+	chosen, most := folders[0], int64(-1)
+	for _, folder := range folders {
+		if free, err := freeSpace(folder); err == nil && free > most {
+			chosen, most = folder, free
+		}
+	}
+
+	return chosen
+}
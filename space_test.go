@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSignatureSize(t *testing.T) {
+	t.Run("should estimate a block at every offset when Signature is not pruned or sampled", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, 1000*signatureBlockSize, estimateSignatureSize(models.CMD{}, 1000))
+	})
+
+	t.Run("should estimate fewer blocks when Signature is pruned or sampled", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, 11*signatureBlockSize, estimateSignatureSize(models.CMD{Sparse: 100}, 1000))
+		require.Equal(t, 101*signatureBlockSize, estimateSignatureSize(models.CMD{Stride: 10}, 1000))
+		require.Equal(t, 6*signatureBlockSize, estimateSignatureSize(models.CMD{MaxEntries: 5}, 1000))
+	})
+}
+
+func TestRequiredSpace(t *testing.T) {
+	// Mock
+	fileSize = func(fileName string) (int64, error) {
+		return 1000, nil
+	}
+
+	t.Run("should return space for the Signature + Delta files when Signature + Delta modes set", func(t *testing.T) {
+		// Run
+		outputs, temp := requiredSpace(models.CMD{SignatureMode: true, DeltaMode: true, OriginalFile: file, UpdatedFile: file})
+		// Verify
+		require.Equal(t, 1000*signatureBlockSize+1000, outputs)
+		require.Equal(t, int64(0), temp)
+	})
+
+	t.Run("should return temp space for the Signature, Delta + patched files when Selftest mode set", func(t *testing.T) {
+		// Run
+		outputs, temp := requiredSpace(models.CMD{SelftestMode: true, OriginalFile: file, UpdatedFile: file})
+		// Verify
+		require.Equal(t, int64(0), outputs)
+		require.Equal(t, 1000*signatureBlockSize+2000, temp)
+	})
+
+	// Restore, so later tests read real file sizes
+	fileSize = files.FileSize
+}
+
+func TestVerifyFreeSpace(t *testing.T) {
+	cmd := models.CMD{DeltaMode: true, SignatureFile: file, UpdatedFile: file, DeltaFile: file}
+	// Mock
+	fileSize = func(fileName string) (int64, error) {
+		return 1000, nil
+	}
+
+	t.Run("should return `nil` when there is enough free space for output files", func(t *testing.T) {
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 1000, nil
+		}
+
+		// Run + Verify
+		require.Equal(t, nil, verifyFreeSpace(cmd))
+	})
+
+	t.Run("should return `InsufficientSpaceError` when there is not enough free space for output files", func(t *testing.T) {
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 999, nil
+		}
+
+		// Run
+		err := verifyFreeSpace(cmd)
+		// Verify
+		require.Contains(t, err.Error(), constants.InsufficientSpaceError)
+		require.Contains(t, err.Error(), "999 bytes free, but up to 1000 bytes are needed")
+	})
+
+	t.Run("should return `nil` when `-noSpaceCheck` flag set or free space can not be checked", func(t *testing.T) {
+		// Setup
+		skipped := cmd
+		skipped.NoSpaceCheck = true
+		// Run + Verify
+		require.Equal(t, nil, verifyFreeSpace(skipped))
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 0, errors.New(constants.UnableToCheckFreeSpaceError)
+		}
+
+		// Run + Verify
+		require.Equal(t, nil, verifyFreeSpace(cmd))
+	})
+
+	// Restore, so later tests read real file sizes + free space
+	fileSize = files.FileSize
+	freeSpace = files.FreeSpace
+}
+
+func TestChooseTempDir(t *testing.T) {
+	t.Run("should return the folder with the most free space when `-tempDirs` flag set", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{TempDirs: "/small, /large ,/missing"}
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			switch path {
+			case "/small":
+				return 10, nil
+			case "/large":
+				return 20, nil
+			default:
+				return 0, errors.New(errorMessage)
+			}
+		}
+
+		// Run + Verify
+		require.Equal(t, "/large", chooseTempDir(cmd))
+	})
+
+	t.Run("should return the first folder when free space can not be checked for any folder", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{TempDirs: "/missing,/other"}
+		// Mock
+		freeSpace = func(path string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		// Run + Verify
+		require.Equal(t, "/missing", chooseTempDir(cmd))
+		// Restore, so later tests read real free space
+		freeSpace = files.FreeSpace
+	})
+
+	t.Run("should return the system temp folder when `-tempDirs` flag not set", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, os.TempDir(), chooseTempDir(models.CMD{TempDirs: " , "}))
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+var (
+	generateSparseSignature = sync.GenerateSparseSignature
+	expandDelta             = sync.ExpandDelta
+	refineDelta             = sync.RefineDelta
+	refineDeltaWithChunk    = sync.RefineDeltaWithChunk
+)
+
+// expandSparseDelta() will extend the matched blocks of a Delta generated from a sparse Signature into neighbouring literal data, when `-sparse` flag set.
+// Expansion compares literal data against the Original file, so requires the Original file to be provided (EG Signature + Delta modes run together).
+// Note: Delta will be returned unchanged when the Original file is not provided or cannot be read (EG a longer literal tail is sent instead).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the expanded Delta copies the Original file unchanged.
+func expandSparseDelta(cmd models.CMD, signature models.Signature, delta models.Delta) (models.Delta, error) {
+	if cmd.Sparse <= 1 {
+		return delta, nil
+	}
+
+	return rescanDelta(cmd, delta, "expanded", func(original io.ReaderAt) (models.Delta, error) {
+		return expandDelta(original, delta, signature)
+	})
+}
+
+// refineSparseDelta() will re-scan the literal blocks of a Delta against a full Signature of the mismatched regions of the Original file, when `-refine` flag set.
+// This allows a coarse first pass (EG `-sparse`) to be followed by a fine pass over only the changed regions, recovering matches missed by the coarse Signature.
+// Note: Delta will be returned unchanged when the Original file is not provided or cannot be read.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the refined Delta copies the Original file unchanged.
+func refineSparseDelta(cmd models.CMD, signature models.Signature, delta models.Delta) (models.Delta, error) {
+	if !cmd.Refine {
+		return delta, nil
+	}
+
+	return rescanDelta(cmd, delta, "refined", func(original io.ReaderAt) (models.Delta, error) {
+		return refineDelta(original, delta, signature)
+	})
+}
+
+// refineFineDelta() will re-scan the literal blocks of a Delta against a finer-grained Signature (smaller chunk size) of the mismatched regions of the Original file, when `-fineChunk` flag set.
+// This recovers short matches within changed regions (EG files with many small edits), keeping them only when they shrink the Delta.
+// Note: Delta will be returned unchanged when the Original file is not provided or cannot be read.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the refined Delta copies the Original file unchanged.
+func refineFineDelta(cmd models.CMD, signature models.Signature, delta models.Delta) (models.Delta, error) {
+	if cmd.FineChunk <= 0 {
+		return delta, nil
+	}
+
+	return rescanDelta(cmd, delta, fmt.Sprintf("refined with %d byte chunks", cmd.FineChunk), func(original io.ReaderAt) (models.Delta, error) {
+		return refineDeltaWithChunk(original, delta, signature, cmd.FineChunk)
+	})
+}
+
+// rescanDelta() will open the Original file and re-scan a Delta against it with the provided function (EG expandDelta()).
+// Note: Delta will be returned unchanged when the Original file is not provided, cannot be opened, or the re-scan fails.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the re-scanned Delta copies the Original file unchanged.
+func rescanDelta(cmd models.CMD, delta models.Delta, action string, rescan func(original io.ReaderAt) (models.Delta, error)) (models.Delta, error) {
+	if cmd.OriginalFile == "" {
+		return delta, nil
+	}
+
+	original, err := openFileAt(cmd.OriginalFile)
+	if err != nil {
+		return delta, nil
+	}
+
+	defer original.Close()
+	result, err := rescan(original)
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return models.Delta{}, err
+		}
+
+		return delta, nil
+	}
+
+	logger(fmt.Sprintf("Delta %s against Original file (%d to %d blocks)", action, len(delta), len(result)), cmd.Verbose)
+	return result, nil
+}
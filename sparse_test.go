@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandSparseDelta(t *testing.T) {
+	delta := models.Delta{
+		0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+		16: models.Block{Head: 16, Tail: 19, IsModified: true, Value: []byte("tail")},
+	}
+
+	expanded := models.Delta{0: models.Block{Head: 0, Tail: 19, IsModified: false, Value: []byte{}}}
+	t.Run("should return `expandedDelta, nil` when `-sparse` flag set and Original file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Sparse: 64}
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return readerAtCloserMock{bytes.NewReader(make([]byte, 20))}, nil
+		}
+
+		expandDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return expanded, nil
+		}
+
+		// Run
+		result, err := expandSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expanded, result)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when expanded Delta copies Original file unchanged", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Sparse: 64}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		expandDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return models.Delta{}, expectedError
+		}
+
+		// Run
+		result, err := expandSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+	})
+
+	t.Run("should return `delta, nil` unchanged when unable to expand Delta", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Sparse: 64}
+		// Mock
+		expandDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return models.Delta{}, errors.New(constants.UnableToReadOriginalFileError)
+		}
+
+		// Run
+		result, err := expandSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `delta, nil` unchanged when `-sparse` flag not set or Original file not provided", func(t *testing.T) {
+		for _, cmd := range []models.CMD{{DeltaMode: true, OriginalFile: file}, {DeltaMode: true, Sparse: 64}} {
+			// Run
+			result, err := expandSparseDelta(cmd, testSignature, delta)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, result)
+		}
+	})
+
+	t.Run("should return `delta, nil` unchanged when unable to open Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Sparse: 64}
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		result, err := expandSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+		// Restore, so later tests expand Deltas against real files
+		expandDelta = sync.ExpandDelta
+		openFileAt = openReaderAt
+	})
+}
+
+func TestRefineSparseDelta(t *testing.T) {
+	delta := models.Delta{
+		0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+		16: models.Block{Head: 16, Tail: 35, IsModified: true, Value: []byte("some-changed-content")},
+	}
+
+	refined := models.Delta{
+		0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+		16: models.Block{Head: 16, Tail: 19, IsModified: true, Value: []byte("some")},
+		20: models.Block{Head: 20, Tail: 35, IsModified: false, Value: []byte{}},
+	}
+
+	t.Run("should return `refinedDelta, nil` when `-refine` flag set and Original file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Refine: true}
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return readerAtCloserMock{bytes.NewReader(make([]byte, 36))}, nil
+		}
+
+		refineDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return refined, nil
+		}
+
+		// Run
+		result, err := refineSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, refined, result)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when refined Delta copies Original file unchanged", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Refine: true}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		refineDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return models.Delta{}, expectedError
+		}
+
+		// Run
+		result, err := refineSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+	})
+
+	t.Run("should return `delta, nil` unchanged when unable to refine Delta", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Refine: true}
+		// Mock
+		refineDelta = func(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+			return models.Delta{}, errors.New(constants.UnableToReadOriginalFileError)
+		}
+
+		// Run
+		result, err := refineSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `delta, nil` unchanged when `-refine` flag not set or Original file not provided", func(t *testing.T) {
+		for _, cmd := range []models.CMD{{DeltaMode: true, OriginalFile: file}, {DeltaMode: true, Refine: true}} {
+			// Run
+			result, err := refineSparseDelta(cmd, testSignature, delta)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, result)
+		}
+	})
+
+	t.Run("should return `delta, nil` unchanged when unable to open Original file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, Refine: true}
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		result, err := refineSparseDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+		// Restore, so later tests refine Deltas against real files
+		refineDelta = sync.RefineDelta
+		openFileAt = openReaderAt
+	})
+}
+
+func TestRefineFineDelta(t *testing.T) {
+	delta := models.Delta{0: models.Block{Head: 0, Tail: 19, IsModified: true, Value: []byte("some-changed-content")}}
+	refined := models.Delta{
+		0: models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+		5: models.Block{Head: 5, Tail: 19, IsModified: true, Value: []byte("changed-content")},
+	}
+
+	t.Run("should return `refinedDelta, nil` when `-fineChunk` flag set and Original file provided", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, FineChunk: 4}
+		var chunkSize int64
+		// Mock
+		openFileAt = func(fileName string) (ReaderAtCloser, error) {
+			return readerAtCloserMock{bytes.NewReader(make([]byte, 20))}, nil
+		}
+
+		refineDeltaWithChunk = func(original io.ReaderAt, delta models.Delta, signature models.Signature, size int64) (models.Delta, error) {
+			chunkSize = size
+			return refined, nil
+		}
+
+		// Run
+		result, err := refineFineDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, refined, result)
+		require.Equal(t, int64(4), chunkSize)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when refined Delta copies Original file unchanged", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{DeltaMode: true, OriginalFile: file, FineChunk: 4}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		refineDeltaWithChunk = func(original io.ReaderAt, delta models.Delta, signature models.Signature, size int64) (models.Delta, error) {
+			return models.Delta{}, expectedError
+		}
+
+		// Run
+		result, err := refineFineDelta(cmd, testSignature, delta)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+	})
+
+	t.Run("should return `delta, nil` unchanged when `-fineChunk` flag not set or Original file not provided", func(t *testing.T) {
+		for _, cmd := range []models.CMD{{DeltaMode: true, OriginalFile: file}, {DeltaMode: true, FineChunk: 4}} {
+			// Run
+			result, err := refineFineDelta(cmd, testSignature, delta)
+			// Verify
+			require.Equal(t, nil, err)
+			require.Equal(t, delta, result)
+		}
+
+		// Restore, so later tests refine Deltas against real files
+		refineDeltaWithChunk = sync.RefineDeltaWithChunk
+		openFileAt = openReaderAt
+	})
+}
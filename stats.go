@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// formatSignatureStats() will format Signature stats as a human readable report.
+// EG: `Weak hash buckets: 10 (12 blocks, 1.20 blocks per bucket)`.
+func formatSignatureStats(stats sync.SignatureStats) []string {
+	return []string{
+		fmt.Sprintf("Weak hash buckets: %d (%d blocks, %.2f blocks per bucket)", stats.Buckets, stats.Blocks, stats.AverageBucketSize()),
+		fmt.Sprintf("Single block buckets: %d", stats.SingleBuckets),
+		fmt.Sprintf("Shared buckets: %d (largest: %d blocks)", stats.SharedBuckets, stats.MaxBucketSize),
+		fmt.Sprintf("Full buckets: %d (earlier positions of repeated content may be dropped)", stats.FullBuckets),
+		fmt.Sprintf("Duplicate blocks: %d", stats.DuplicateBlocks),
+	}
+}
+
+// runSignatureStats() will report how the blocks of a Signature file are distributed across Weak hash buckets.
+// Large shared buckets slow down Delta generation, and full buckets / duplicate blocks can make Deltas unexpectedly large.
+// Function returns `stats, nil` when successful.
+// Function returns `emptyStats, error` when unable to open the Signature file.
+func runSignatureStats(cmd models.CMD) (sync.SignatureStats, error) {
+	signature, err := openSignature(cmd.SignatureFile, cmd.Verbose)
+	if err != nil {
+		return sync.SignatureStats{}, err
+	}
+
+	stats := sync.GetSignatureStats(signature)
+	for _, line := range formatSignatureStats(stats) {
+		logger(utils.Stat(line), true)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/curtismenmuir/go-file-diff/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSignatureStats(t *testing.T) {
+	t.Run("should return bucket occupancy + duplicate block counts", func(t *testing.T) {
+		// Setup
+		stats := sync.SignatureStats{Buckets: 4, Blocks: 6, SingleBuckets: 3, SharedBuckets: 1, FullBuckets: 0, MaxBucketSize: 3, DuplicateBlocks: 2}
+		expectedLines := []string{
+			"Weak hash buckets: 4 (6 blocks, 1.50 blocks per bucket)",
+			"Single block buckets: 3",
+			"Shared buckets: 1 (largest: 3 blocks)",
+			"Full buckets: 0 (earlier positions of repeated content may be dropped)",
+			"Duplicate blocks: 2",
+		}
+
+		// Run
+		lines := formatSignatureStats(stats)
+		// Verify
+		require.Equal(t, expectedLines, lines)
+	})
+}
+
+func TestRunSignatureStats(t *testing.T) {
+	t.Run("should log + return stats of Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{StatsMode: true, SignatureFile: file}
+		logged := []string{}
+		// Mock
+		logger = func(message string, verbose bool) {
+			logged = append(logged, message)
+		}
+
+		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
+			return testSignature, nil
+		}
+
+		// Run
+		stats, err := runSignatureStats(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, sync.SignatureStats{Buckets: 1, Blocks: 1, SingleBuckets: 1, MaxBucketSize: 1}, stats)
+		require.Equal(t, 5, len(logged))
+		require.Equal(t, utils.Stat("Single block buckets: 1"), logged[1])
+	})
+
+	t.Run("should return error when unable to open Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{StatsMode: true, SignatureFile: file}
+		expectedError := errors.New(errorMessage)
+		// Mock
+		openSignature = func(fileName string, verbose bool) (models.Signature, error) {
+			return models.Signature{}, expectedError
+		}
+
+		// Run
+		stats, err := runSignatureStats(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, sync.SignatureStats{}, stats)
+	})
+}
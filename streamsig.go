@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+)
+
+var generateSignatureTo = sync.GenerateSignatureTo
+
+// signatureStream type.
+// This will gob encode each Signature entry to a streamed Signature file (see files.SignatureStreamWriter), also collecting entries into a Signature when one is provided.
+// The first error returned by the encoder will be recorded.
+// signatureStream will satisfy the `sync.SignatureEntryWriter` interface.
+type signatureStream struct {
+	writer    *files.SignatureStreamWriter
+	signature models.Signature
+	err       error
+}
+
+// WriteEntry() will encode a Signature entry to the streamed Signature file, adding it to the collected Signature (when provided).
+func (s *signatureStream) WriteEntry(weakHash int64, item models.StrongSignature) error {
+	if s.err = s.writer.WriteEntry(weakHash, item); s.err != nil {
+		return s.err
+	}
+
+	if s.signature != nil {
+		return s.signature.WriteEntry(weakHash, item)
+	}
+
+	return nil
+}
+
+// streamSignature() will generate a Signature of the Original file, writing each entry to the Signature file as it is generated (see `-streamSignature`).
+// The Signature will only be collected in memory when Delta or Patch mode also need it, otherwise an empty Signature will be returned.
+// Note: Signature file will be written as a `.partial` file, which is renamed into place once complete (or discarded on failure unless `-keepPartial` is set).
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, OriginalFileDoesNotExistError` when unable to find Original file.
+// Function returns `emptySignature, OriginalFileIsFolderError` when found a folder dir instead of Original file.
+// Function returns `emptySignature, UnableToCreateSignatureFileError` when unable to create Signature file.
+// Function returns `emptySignature, UnableToWriteToSignatureFileError` when unable to write to Signature file.
+// Function returns `emptySignature, UnableToGenerateSignatureError` when unable to generate Signature.
+func streamSignature(cmd models.CMD) (models.Signature, error) {
+	// Create FileReader for Original file
+	reader, err := openOriginal(cmd.OriginalFile)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	file, err := createOutputFile(cmd.SignatureFile)
+	if err != nil {
+		// Replace generic `UnableToCreateFileError` error with specific Signature File error
+		if err.Error() == constants.UnableToCreateFileError {
+			return models.Signature{}, errors.New(constants.UnableToCreateSignatureFileError)
+		}
+
+		return models.Signature{}, err
+	}
+
+	// Stream Signature, recording the chunk size used
+	buffered := bufio.NewWriter(file)
+	stream := &signatureStream{writer: files.NewSignatureStreamWriter(buffered, sync.Metadata())}
+	if cmd.DeltaMode || cmd.PatchMode {
+		stream.signature = models.Signature{}
+	}
+
+	reader, progress := trackProgress(cmd, reader, "Signature", cmd.OriginalFile)
+	err = generateSignatureTo(reader, stream, cmd.Verbose)
+	progress.Finish()
+	writeFailed := stream.err != nil || stream.writer.Close() != nil || buffered.Flush() != nil
+	file.Close()
+	if finishErr := finishPartial(outputPath(cmd.SignatureFile), writeFailed || err != nil); finishErr != nil {
+		writeFailed = true
+	}
+
+	if writeFailed {
+		return models.Signature{}, errors.New(constants.UnableToWriteToSignatureFileError)
+	}
+
+	if err != nil {
+		return models.Signature{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	logger(fmt.Sprintf("%s created: %s\n", cmd.SignatureFile, outputPath(cmd.SignatureFile)), true)
+	if stream.signature == nil {
+		return models.Signature{}, nil
+	}
+
+	return stream.signature, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamSignature(t *testing.T) {
+	original := bytes.Repeat([]byte("some streamed file contents "), 4)
+	expected, err := sync.GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+	require.Equal(t, nil, err)
+
+	t.Run("should return `emptySignature, nil` after streaming entries to Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "signature-*")
+		require.Equal(t, nil, err)
+		// Mock
+		openFile = func(fileName string) (*bufio.Reader, error) {
+			return bufio.NewReader(bytes.NewReader(original)), nil
+		}
+
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		finished := false
+		finishPartial = func(path string, failed bool) error {
+			finished = !failed
+			return nil
+		}
+
+		generateSignatureTo = sync.GenerateSignatureTo
+		// Run
+		signature, err := streamSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, true, finished)
+		require.Equal(t, models.Signature{}, signature)
+		decoded, metadata, err := files.OpenSignatureMetadata(output.Name(), false)
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, decoded)
+		require.Equal(t, sync.Metadata(), metadata)
+	})
+
+	t.Run("should return `signature, nil` when Delta mode also needs the Signature", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, DeltaMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "signature-*")
+		require.Equal(t, nil, err)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		// Run
+		signature, err := streamSignature(cmd)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, signature)
+	})
+
+	t.Run("should return `emptySignature, UnableToCreateSignatureFileError` when unable to create Signature file", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file}
+		expectedError := errors.New(constants.UnableToCreateSignatureFileError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return nil, errors.New(constants.UnableToCreateFileError)
+		}
+
+		// Run
+		signature, err := streamSignature(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+
+	t.Run("should discard partial Signature file + return `emptySignature, UnableToGenerateSignatureError` when Signature generation fails", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, StreamSig: true, OriginalFile: file, SignatureFile: file}
+		output, err := os.CreateTemp(t.TempDir(), "signature-*")
+		require.Equal(t, nil, err)
+		expectedError := errors.New(constants.UnableToGenerateSignatureError)
+		// Mock
+		createOutputFile = func(fileName string) (*os.File, error) {
+			return output, nil
+		}
+
+		discarded := false
+		finishPartial = func(path string, failed bool) error {
+			discarded = failed
+			return nil
+		}
+
+		generateSignatureTo = func(reader sync.Reader, writer sync.SignatureEntryWriter, verbose bool) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		signature, err := streamSignature(cmd)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+		require.Equal(t, true, discarded)
+		generateSignatureTo = sync.GenerateSignatureTo
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+const (
+	exitFailure      int    = 1
+	exitInvalidFlags int    = 2
+	summaryStdout    string = "-"
+)
+
+var (
+	exit           = os.Exit
+	printSummary   = fmt.Println
+	marshalSummary = json.MarshalIndent
+	writeFile      = os.WriteFile
+)
+
+// addSummaryFile() will record a file (role, path, and size) in the provided list of Summary files.
+// Note: size will be recorded as `0` when unable to get file size (EG file does not exist).
+func addSummaryFile(list []models.SummaryFile, name string, path string) []models.SummaryFile {
+	size, err := fileSize(path)
+	if err != nil {
+		size = 0
+	}
+
+	return append(list, models.SummaryFile{Name: name, Path: path, Size: size})
+}
+
+// getMode() will return the name of the mode(s) enabled by CMD flags, in the order they run.
+// EG: `signature+delta`.
+func getMode(cmd models.CMD) string {
+	modes := []string{}
+	for _, mode := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"doctor", cmd.Doctor},
+		{"bench", cmd.BenchMode},
+		{"gen", cmd.GenMode},
+		{"replay", cmd.Replay != ""},
+		{"simulate", cmd.SimulateMode},
+		{"selftest", cmd.SelftestMode},
+		{"gitDiffDriver", cmd.GitDiffDriver},
+		{"analyze", cmd.AnalyzeMode},
+		{"similarity", cmd.Similarity},
+		{"convert", cmd.ConvertMode},
+		{"stats", cmd.StatsMode},
+		{"catSig", cmd.CatSignature},
+		{"batch", cmd.FilesFrom != ""},
+		{"signature", cmd.SignatureMode},
+		{"delta", cmd.DeltaMode},
+		{"patch", cmd.PatchMode},
+	} {
+		if mode.enabled {
+			modes = append(modes, mode.name)
+		}
+	}
+
+	return strings.Join(modes, "+")
+}
+
+// newSummary() will init a Summary for the mode(s) enabled by CMD flags.
+func newSummary(cmd models.CMD) models.Summary {
+	return models.Summary{
+		Mode:      getMode(cmd),
+		Inputs:    []models.SummaryFile{},
+		Outputs:   []models.SummaryFile{},
+		Durations: map[string]int64{},
+	}
+}
+
+// timePhase() will run the provided function and record its duration (ms) in the Summary.
+// Function returns the error returned from the provided function.
+func timePhase(summary *models.Summary, phase string, run func() error) error {
+	start := now()
+	err := run()
+	summary.Durations[phase] = now().Sub(start).Milliseconds()
+	return err
+}
+
+// writeSummary() will encode the Summary as JSON and write it to the provided path.
+// Summary will be printed to stdout when path is `-` (including when quiet mode is enabled).
+// Function returns `nil` when successful.
+// Function returns `UnableToWriteSummaryError` when unable to encode or write the Summary.
+func writeSummary(summary models.Summary, path string) error {
+	output, err := marshalSummary(summary, "", "  ")
+	if err != nil {
+		return errors.New(constants.UnableToWriteSummaryError)
+	}
+
+	if path == summaryStdout {
+		_, err = printSummary(string(output))
+	} else {
+		err = writeFile(path, append(output, '\n'), files.FileMode())
+	}
+
+	if err != nil {
+		return errors.New(constants.UnableToWriteSummaryError)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSummaryFile(t *testing.T) {
+	t.Run("should record file with size", func(t *testing.T) {
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 1024, nil
+		}
+
+		// Run
+		result := addSummaryFile([]models.SummaryFile{}, "original", file)
+		// Verify
+		require.Equal(t, []models.SummaryFile{{Name: "original", Path: file, Size: 1024}}, result)
+	})
+
+	t.Run("should record file with zero size when unable to get file size", func(t *testing.T) {
+		// Mock
+		fileSize = func(fileName string) (int64, error) {
+			return 0, errors.New(errorMessage)
+		}
+
+		// Run
+		result := addSummaryFile([]models.SummaryFile{}, "original", file)
+		// Verify
+		require.Equal(t, []models.SummaryFile{{Name: "original", Path: file, Size: 0}}, result)
+	})
+}
+
+func TestGetMode(t *testing.T) {
+	t.Run("should return enabled modes in the order they run", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SignatureMode: true, DeltaMode: true}
+		// Run
+		result := getMode(cmd)
+		// Verify
+		require.Equal(t, "signature+delta", result)
+	})
+
+	t.Run("should return single enabled mode", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{SelftestMode: true}
+		// Run
+		result := getMode(cmd)
+		// Verify
+		require.Equal(t, "selftest", result)
+	})
+}
+
+func TestNewSummary(t *testing.T) {
+	t.Run("should return empty Summary for enabled modes", func(t *testing.T) {
+		// Setup
+		cmd := models.CMD{BenchMode: true}
+		// Run
+		summary := newSummary(cmd)
+		// Verify
+		require.Equal(t, "bench", summary.Mode)
+		require.Equal(t, []models.SummaryFile{}, summary.Inputs)
+		require.Equal(t, []models.SummaryFile{}, summary.Outputs)
+		require.Equal(t, map[string]int64{}, summary.Durations)
+	})
+}
+
+func TestTimePhase(t *testing.T) {
+	t.Run("should record duration of phase + return error from function", func(t *testing.T) {
+		// Setup
+		summary := newSummary(models.CMD{})
+		start := time.Unix(0, 0)
+		calls := 0
+		expectedError := errors.New(errorMessage)
+		// Mock
+		now = func() time.Time {
+			calls++
+			return start.Add(time.Duration(calls-1) * 1500 * time.Millisecond)
+		}
+
+		// Run
+		err := timePhase(&summary, "signature", func() error {
+			return expectedError
+		})
+
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, int64(1500), summary.Durations["signature"])
+		// Mock
+		now = time.Now
+	})
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Run("should print Summary JSON to stdout when path is `-`", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{Mode: "delta", MatchedBytes: 10, LiteralBytes: 2, Durations: map[string]int64{"delta": 5}}
+		printed := ""
+		// Mock
+		marshalSummary = json.MarshalIndent
+		printSummary = func(a ...any) (int, error) {
+			printed = a[0].(string)
+			return 0, nil
+		}
+
+		// Run
+		err := writeSummary(summary, summaryStdout)
+		// Verify
+		require.Equal(t, nil, err)
+		decoded := models.Summary{}
+		require.Equal(t, nil, json.Unmarshal([]byte(printed), &decoded))
+		require.Equal(t, summary, decoded)
+	})
+
+	t.Run("should write Summary JSON to file", func(t *testing.T) {
+		// Setup
+		summary := models.Summary{Mode: "signature", ExitStatus: 1, Error: errorMessage}
+		path := filepath.Join(t.TempDir(), "summary.json")
+		// Mock
+		writeFile = os.WriteFile
+		// Run
+		err := writeSummary(summary, path)
+		// Verify
+		require.Equal(t, nil, err)
+		data, err := os.ReadFile(path)
+		require.Equal(t, nil, err)
+		require.Contains(t, string(data), `"exitStatus": 1`)
+		require.Contains(t, string(data), `"error": "Some Error"`)
+	})
+
+	t.Run("should return `UnableToWriteSummaryError` when unable to write Summary to file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWriteSummaryError)
+		// Mock
+		writeFile = func(name string, data []byte, perm os.FileMode) error {
+			return errors.New(errorMessage)
+		}
+
+		// Run
+		err := writeSummary(models.Summary{}, file)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToWriteSummaryError` when unable to encode Summary", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToWriteSummaryError)
+		// Mock
+		marshalSummary = func(v any, prefix string, indent string) ([]byte, error) {
+			return nil, errors.New(errorMessage)
+		}
+
+		// Run
+		err := writeSummary(models.Summary{}, summaryStdout)
+		// Verify
+		require.Equal(t, expectedError, err)
+		// Mock
+		marshalSummary = json.MarshalIndent
+	})
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// AppendDelta() will check whether the Updated file is the Original file with data appended (EG log rotation, journals), without a rolling scan.
+// The start of the Updated file will be split into chunks (plus the final chunk of the Original file), each verified against its Signature block.
+// Function returns `delta, true` when the Updated file starts with the Original file (Delta will be a single matched block, followed by a literal block of any appended data).
+// Function returns `emptyDelta, false` when the Updated file is smaller than the Original file, a chunk does not match (or is missing from the Signature), or the Updated file cannot be read.
+// Note: a Delta should be generated as normal when returning false.
+func AppendDelta(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+	size := originalSize(signature)
+	if size < chunk || updatedSize < size || !matchesPrefix(updated, size, signature) {
+		return models.Delta{}, false
+	}
+
+	delta := models.Delta{0: models.Block{Head: 0, Tail: size - 1, IsModified: false, Value: []byte{}}}
+	if updatedSize > size {
+		value := make([]byte, updatedSize-size)
+		if _, err := io.ReadFull(io.NewSectionReader(updated, size, updatedSize-size), value); err != nil {
+			return models.Delta{}, false
+		}
+
+		delta[size] = models.Block{Head: 0, Tail: int64(len(value)) - 1, IsModified: true, Value: value}
+	}
+
+	return delta, true
+}
+
+// matchesPrefix() will check the first length bytes of the Updated file match the first length bytes of the Original file.
+// Bytes will be split into chunks (plus the final chunk when length is not aligned to chunk size), each verified against the Signature block at the same Head.
+// Note: length must be at least one chunk, as the Signature contains no blocks smaller than a chunk.
+// Function returns `false` when a chunk does not match (or is missing from the Signature), or the Updated file cannot be read.
+func matchesPrefix(updated io.ReaderAt, length int64, signature models.Signature) bool {
+	blocks := prefixBlocks(signature, length)
+	reader := bufio.NewReader(io.NewSectionReader(updated, 0, length))
+	buffer := make([]byte, chunk)
+	for head := int64(0); head+chunk <= length; head += chunk {
+		if _, err := io.ReadFull(reader, buffer); err != nil || !prefixMatches(blocks, head, buffer) {
+			return false
+		}
+	}
+
+	// Verify final chunk when length is not aligned to chunk size
+	if length%chunk != 0 {
+		if _, err := io.ReadFull(io.NewSectionReader(updated, length-chunk, chunk), buffer); err != nil || !prefixMatches(blocks, length-chunk, buffer) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// prefixBlocks() will index the Signature blocks verified by matchesPrefix() by their Head position in the Original file.
+// EG: blocks aligned to chunk size, plus the final block of the prefix (indexing every rolled block would be far larger).
+func prefixBlocks(signature models.Signature, length int64) map[int64]models.StrongSignature {
+	blocks := map[int64]models.StrongSignature{}
+	for _, item := range signature {
+		for _, block := range append([]models.StrongSignature{item}, item.Candidates...) {
+			if block.Head%chunk == 0 || block.Head == length-chunk {
+				blocks[block.Head] = models.StrongSignature{Hash: block.Hash, LegacyHash: block.LegacyHash, Head: block.Head, Tail: block.Tail}
+			}
+		}
+	}
+
+	return blocks
+}
+
+// prefixMatches() will check a chunk read from the provided head of the Updated file matches the Signature block at the same head of the Original file.
+// Function returns `false` when no Signature block exists at head (EG dropped candidate), or the block does not match.
+func prefixMatches(blocks map[int64]models.StrongSignature, head int64, buffer []byte) bool {
+	block, exists := blocks[head]
+	return exists && hashMatches(block, activeStrongHash(buffer, chunk), legacyHash(buffer))
+}
+
+// TruncateDelta() will check whether the Updated file is the Original file truncated (EG data removed from the end), without a rolling scan.
+// The Updated file will be verified in the same way as AppendDelta(), against the Signature blocks at the start of the Original file.
+// Function returns `delta, true` when the Original file starts with the Updated file (Delta will be a single matched block, or empty when Updated file is empty).
+// Function returns `emptyDelta, false` when the Updated file is not smaller than the Original file, is smaller than a chunk, a chunk does not match, or the Updated file cannot be read.
+// Note: applying the Delta will write exactly the Updated file size, so patched output is truncated correctly.
+// Note: a Delta should be generated as normal when returning false.
+func TruncateDelta(updated io.ReaderAt, updatedSize int64, signature models.Signature) (models.Delta, bool) {
+	size := originalSize(signature)
+	if updatedSize >= size {
+		return models.Delta{}, false
+	} else if updatedSize == 0 {
+		return models.Delta{}, true
+	} else if updatedSize < chunk || !matchesPrefix(updated, updatedSize, signature) {
+		return models.Delta{}, false
+	}
+
+	return models.Delta{0: models.Block{Head: 0, Tail: updatedSize - 1, IsModified: false, Value: []byte{}}}, true
+}
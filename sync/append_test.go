@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendDelta(t *testing.T) {
+	original := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+
+	t.Run("should return `delta, true` when Updated file is Original file with data appended", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := append(append([]byte{}, original...), []byte("appended")...)
+		expectedDelta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 35, IsModified: false, Value: []byte{}},
+			36: models.Block{Head: 0, Tail: 7, IsModified: true, Value: []byte("appended")},
+		}
+
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, true, found)
+		require.Equal(t, expectedDelta, delta)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return single matched block when Updated file matches Original file", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 35, IsModified: false, Value: []byte{}}}
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(original), int64(len(original)), signature)
+		// Verify
+		require.Equal(t, true, found)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when final chunk of Original file was modified", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := []byte("abcdefghijklmnopqrstuvwxyz012345678!appended")
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when start of Original file was modified", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := []byte("Abcdefghijklmnopqrstuvwxyz0123456789appended")
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when Updated file is smaller than Original file", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := original[:20]
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when Signature is empty", func(t *testing.T) {
+		// Run
+		delta, found := AppendDelta(bytes.NewReader(original), int64(len(original)), models.Signature{})
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
+
+func TestTruncateDelta(t *testing.T) {
+	original := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+
+	t.Run("should return `delta, true` when Updated file is Original file truncated", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := original[:21]
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 20, IsModified: false, Value: []byte{}}}
+		// Run
+		delta, found := TruncateDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, true, found)
+		require.Equal(t, expectedDelta, delta)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return `emptyDelta, true` when Updated file is empty", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		// Run
+		delta, found := TruncateDelta(bytes.NewReader([]byte{}), 0, signature)
+		// Verify
+		require.Equal(t, true, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when end of truncated Updated file was modified", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := []byte("abcdefghijklmnopqrstU")
+		// Run
+		delta, found := TruncateDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when Updated file is smaller than a chunk", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		updated := original[:10]
+		// Run
+		delta, found := TruncateDelta(bytes.NewReader(updated), int64(len(updated)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, false` when Updated file is not smaller than Original file", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, original)
+		// Run
+		delta, found := TruncateDelta(bytes.NewReader(original), int64(len(original)), signature)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
@@ -0,0 +1,199 @@
+package sync
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Delay between re-reads of a matched block which does not match the Signature (see SetReadRetries())
+const retryDelay = 100 * time.Millisecond
+
+var (
+	sleep = time.Sleep
+	// Number of times a mismatched block will be re-read from the Original file before failing
+	readRetries = 0
+)
+
+// OpSource interface for providing the operations of a Delta in output order (EG sorted by Position).
+// This allows a Delta to be applied as its operations are decoded, without holding every block in memory.
+// `models.Delta` + `files.DeltaScanner` will satisfy the `OpSource` interface.
+type OpSource interface {
+	Ops(visit func(op models.Op) error) error
+}
+
+// Apply() will patch an Original file with a Delta changeset, writing the reconstructed Updated file to the provided writer.
+// Blocks will be applied in order of their position in the Updated file (see Delta.Ops()).
+// Matched blocks will be copied from the Original file, while modified blocks will write their Value.
+// Function will return `nil` when Delta has been applied successfully.
+// Function will return `InvalidDeltaError` when Delta blocks contain gaps or overlap (EG corrupt Delta).
+// Function will return `UnableToReadOriginalFileError` when unable to read a matched block from Original file.
+// Function will return `UnableToWriteOutputFileError` when unable to write to the provided writer.
+func Apply(original io.ReaderAt, delta models.Delta, out io.Writer) error {
+	return apply(original, delta, out, nil)
+}
+
+// ApplyOps() will patch an Original file in the same way as Apply(), applying each operation as it is provided by source (EG scanned from a Delta file, see files.DeltaScanner).
+// Function will return `nil` when every operation has been applied successfully.
+// Function will return `error` in the same cases as Apply(), or returned by source (EG unable to decode the Delta file).
+// Note: output written before an error is found should be discarded.
+func ApplyOps(original io.ReaderAt, source OpSource, out io.Writer) error {
+	return apply(original, source, out, nil)
+}
+
+// apply() will patch an Original file with the operations of source, calling check (when provided) with each operation + its data before writing.
+// See Apply() for returned errors, plus any error returned by check or source.
+func apply(original io.ReaderAt, source OpSource, out io.Writer, check func(op models.Op, value []byte) error) error {
+	position := int64(0)
+	return source.Ops(func(op models.Op) error {
+		// Verify operation starts where the previous operation finished
+		if op.Position != position {
+			return errors.New(constants.InvalidDeltaError)
+		}
+
+		value := op.Value
+		if op.Kind == models.OpCopy {
+			// Read matched block from Original file
+			if op.Head < 0 || op.Tail < op.Head {
+				return errors.New(constants.InvalidDeltaError)
+			}
+
+			value = make([]byte, op.Len())
+			if _, err := original.ReadAt(value, op.Head); err != nil {
+				return errors.New(constants.UnableToReadOriginalFileError)
+			}
+		}
+
+		if check != nil {
+			if err := check(op, value); err != nil {
+				return err
+			}
+		}
+
+		if _, err := out.Write(value); err != nil {
+			return errors.New(constants.UnableToWriteOutputFileError)
+		}
+
+		position += int64(len(value))
+		return nil
+	})
+}
+
+// ApplyStrict() will patch an Original file in the same way as Apply(), verifying each matched block against the Signature of the Original file before writing.
+// Strong hashes will be recomputed for every Signature block contained within a matched block, guaranteeing bit-exact output even if the Original file has drifted.
+// Function will return `nil` when Delta has been applied successfully.
+// Function will return `OriginalFileChangedError` when a matched block does not match the Signature (EG Original file modified since Signature was generated).
+// Note: mismatched blocks will be re-read before failing when retries have been set (see SetReadRetries()).
+// Function will return `error` in the same cases as Apply().
+// Note: output written before a mismatch is found should be discarded.
+func ApplyStrict(original io.ReaderAt, delta models.Delta, signature models.Signature, out io.Writer) error {
+	return applyStrict(original, delta, signature, activeSettings(), out)
+}
+
+// applyStrict() will patch an Original file in the same way as ApplyStrict(), verifying matched blocks with the provided chunk size + Strong hash.
+// Function will return `error` in the same cases as ApplyStrict().
+func applyStrict(original io.ReaderAt, delta models.Delta, signature models.Signature, s settings, out io.Writer) error {
+	blocks := signatureBlocks(signature)
+	return apply(original, delta, out, func(op models.Op, value []byte) error {
+		if op.Kind != models.OpCopy {
+			return nil
+		}
+
+		if _, matches := verifyBlock(original, blocks, op.Head, value, s); !matches {
+			return errors.New(constants.OriginalFileChangedError)
+		}
+
+		return nil
+	})
+}
+
+// matchSignature() will compare every Signature block contained within a matched block (read from the provided head of the Original file) against its Strong hash.
+// Note: a block will also match its Legacy hash when a Legacy hash algorithm has been selected (see UseLegacyHash()).
+// Function returns `checked, true` when all contained Signature blocks match (checked will be 0 when no blocks are contained, EG a matched block smaller than a chunk).
+// Function returns `checked, false` at the first Signature block which does not match.
+func matchSignature(blocks map[int64]models.StrongSignature, head int64, value []byte, s settings) (int, bool) {
+	checked := 0
+	for offset := range value {
+		block, exists := blocks[head+int64(offset)]
+		if !exists || block.Tail >= head+int64(len(value)) {
+			continue
+		}
+
+		checked++
+		buffer := value[offset : block.Tail-head+1]
+		if !hashMatches(block, s.strongHash(buffer, s.chunk), s.legacy(buffer)) {
+			return checked, false
+		}
+	}
+
+	return checked, true
+}
+
+// OutputSize() will return the size (bytes) of the Updated file reconstructed when applying a Delta.
+// Note: this can be used as the expected total when reporting progress of the `patch` process.
+func OutputSize(delta models.Delta) int64 {
+	matched, literal := Stats(delta)
+	return matched + literal
+}
+
+// signatureBlocks() will index every block of a Signature (including candidates) by its Head position in the Original file.
+func signatureBlocks(signature models.Signature) map[int64]models.StrongSignature {
+	blocks := map[int64]models.StrongSignature{}
+	for _, item := range signature {
+		for _, block := range append([]models.StrongSignature{item}, item.Candidates...) {
+			blocks[block.Head] = models.StrongSignature{Hash: block.Hash, LegacyHash: block.LegacyHash, Head: block.Head, Tail: block.Tail}
+		}
+	}
+
+	return blocks
+}
+
+// SetReadRetries() will set the number of times a matched block which does not match the Signature will be re-read from the Original file before failing.
+// This allows strict patching (see ApplyStrict() + ApplyWithReport()) to ride over transient read glitches (EG network filesystems).
+// Function returns `nil` when successful.
+// Function returns `InvalidReadRetriesError` when retries is negative (retries will be unchanged).
+func SetReadRetries(retries int) error {
+	if retries < 0 {
+		return errors.New(constants.InvalidReadRetriesError)
+	}
+
+	readRetries = retries
+	return nil
+}
+
+// Stats() will return the number of bytes a Delta copies from the Original file (matched), and the number of new bytes it contains (literal).
+func Stats(delta models.Delta) (int64, int64) {
+	matched := int64(0)
+	literal := int64(0)
+	for _, block := range delta {
+		if block.IsModified {
+			literal += int64(len(block.Value))
+		} else {
+			matched += block.Tail - block.Head + 1
+		}
+	}
+
+	return matched, literal
+}
+
+// verifyBlock() will compare a matched block against the Signature (see matchSignature()), re-reading the block from the Original file when it does not match.
+// Block will be re-read up to the number of times set by SetReadRetries(), waiting between each attempt.
+// Note: value will be updated in place with the re-read bytes, so the bytes which were verified are the bytes written.
+// Function returns `checked, true` when the block (or a re-read of the block) matches.
+// Function returns `checked, false` when the block still does not match after all retries.
+func verifyBlock(original io.ReaderAt, blocks map[int64]models.StrongSignature, head int64, value []byte, s settings) (int, bool) {
+	checked, matches := matchSignature(blocks, head, value, s)
+	for attempt := 0; !matches && attempt < readRetries; attempt++ {
+		sleep(retryDelay)
+		if _, err := original.ReadAt(value, head); err != nil {
+			continue
+		}
+
+		checked, matches = matchSignature(blocks, head, value, s)
+	}
+
+	return checked, matches
+}
@@ -0,0 +1,370 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for io.ReaderAt interface which always fails
+type failingReaderAtMock struct{}
+
+// Overwrite failingReaderAtMock.ReadAt() to return an error
+func (r failingReaderAtMock) ReadAt(p []byte, offset int64) (int, error) {
+	return 0, errors.New("Some Error")
+}
+
+// Mock for io.ReaderAt interface representing a sparse Original file (EG >4GB without allocating it)
+type sparseReaderAtMock struct {
+	// Set test props
+	offsets []int64
+}
+
+// Overwrite sparseReaderAtMock.ReadAt() to record offsets + return bytes derived from the offset
+func (r *sparseReaderAtMock) ReadAt(p []byte, offset int64) (int, error) {
+	r.offsets = append(r.offsets, offset)
+	for index := range p {
+		p[index] = byte((offset + int64(index)) % 251)
+	}
+
+	return len(p), nil
+}
+
+// Mock for io.ReaderAt interface which returns drifted data for the first N reads (EG transient network filesystem glitch)
+type flakyReaderAtMock struct {
+	data    []byte
+	drifted []byte
+	glitchy int
+	reads   int
+}
+
+// Overwrite flakyReaderAtMock.ReadAt() to return drifted data until glitchy reads have been made
+func (r *flakyReaderAtMock) ReadAt(p []byte, offset int64) (int, error) {
+	r.reads++
+	if r.reads <= r.glitchy {
+		return bytes.NewReader(r.drifted).ReadAt(p, offset)
+	}
+
+	return bytes.NewReader(r.data).ReadAt(p, offset)
+}
+
+// Mock for io.Writer interface which always fails
+type failingWriterMock struct{}
+
+// Overwrite failingWriterMock.Write() to return an error
+func (w failingWriterMock) Write(p []byte) (int, error) {
+	return 0, errors.New("Some Error")
+}
+
+// signatureOf() will generate a Signature of the provided data.
+func signatureOf(t *testing.T, data []byte) models.Signature {
+	initialiseBuffer = populateBuffer
+	rollBuffer = roll
+	signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(data)), false)
+	require.Equal(t, nil, err)
+	return signature
+}
+
+func TestApply(t *testing.T) {
+	t.Run("should return `nil` after writing Updated file reconstructed from matched + modified blocks", func(t *testing.T) {
+		// Setup
+		original := bytes.NewReader([]byte("hello world"))
+		delta := models.Delta{
+			0: models.Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}},
+			5: models.Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")},
+			7: models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+		}
+
+		var out bytes.Buffer
+		// Run
+		err := Apply(original, delta, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "world, hello", out.String())
+	})
+
+	t.Run("should return `nil` after copying matched blocks beyond 4GB in Original file", func(t *testing.T) {
+		// Setup
+		large := int64(5) << 30
+		original := &sparseReaderAtMock{}
+		delta := models.Delta{
+			0: models.Block{Head: large, Tail: large + 2, IsModified: false, Value: []byte{}},
+			3: models.Block{Head: 3, Tail: 3, IsModified: true, Value: []byte{'!'}},
+		}
+
+		expected := []byte{byte(large % 251), byte((large + 1) % 251), byte((large + 2) % 251), '!'}
+		var out bytes.Buffer
+		// Run
+		err := Apply(original, delta, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expected, out.Bytes())
+		require.Equal(t, []int64{large}, original.offsets)
+	})
+
+	t.Run("should return `nil` without writing when Delta is empty", func(t *testing.T) {
+		// Setup
+		var out bytes.Buffer
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), models.Delta{}, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 0, out.Len())
+	})
+
+	t.Run("should return `InvalidDeltaError` when Delta contains a gap between blocks", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 1, IsModified: false, Value: []byte{}},
+			5: models.Block{Head: 5, Tail: 5, IsModified: true, Value: []byte{'a'}},
+		}
+
+		var out bytes.Buffer
+		expectedError := errors.New(constants.InvalidDeltaError)
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), delta, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `InvalidDeltaError` when matched block Head is negative", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: -1, Tail: 3, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), delta, &out)
+		// Verify
+		require.Equal(t, errors.New(constants.InvalidDeltaError), err)
+	})
+
+	t.Run("should return `InvalidDeltaError` when matched block Tail is before Head", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 3, Tail: 1, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		expectedError := errors.New(constants.InvalidDeltaError)
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), delta, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToReadOriginalFileError` when matched block is outside of Original file", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 3, Tail: 10, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), delta, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToReadOriginalFileError` when unable to read from Original file", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 1, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Run
+		err := Apply(failingReaderAtMock{}, delta, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `UnableToWriteOutputFileError` when unable to write to output", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a'}}}
+		expectedError := errors.New(constants.UnableToWriteOutputFileError)
+		// Run
+		err := Apply(bytes.NewReader([]byte("hello")), delta, failingWriterMock{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+// Mock for OpSource interface, which provides ops before returning an error
+type opSourceMock struct {
+	// Set test props
+	ops []models.Op
+	err error
+}
+
+// Overwrite opSourceMock.Ops() to visit the provided ops, then return the provided error
+func (s opSourceMock) Ops(visit func(op models.Op) error) error {
+	for _, op := range s.ops {
+		if err := visit(op); err != nil {
+			return err
+		}
+	}
+
+	return s.err
+}
+
+func TestApplyOps(t *testing.T) {
+	t.Run("should return `nil` after writing Updated file reconstructed from each op of the source", func(t *testing.T) {
+		// Setup
+		source := opSourceMock{ops: []models.Op{
+			{Kind: models.OpCopy, Position: 0, Head: 6, Tail: 10},
+			{Kind: models.OpLiteral, Position: 5, Value: []byte(", ")},
+			{Kind: models.OpCopy, Position: 7, Head: 0, Tail: 4},
+		}}
+
+		var out bytes.Buffer
+		// Run
+		err := ApplyOps(bytes.NewReader([]byte("hello world")), source, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "world, hello", out.String())
+	})
+
+	t.Run("should return `error` returned by the source after applying its ops", func(t *testing.T) {
+		// Setup
+		source := opSourceMock{ops: []models.Op{{Kind: models.OpLiteral, Position: 0, Value: []byte("abc")}}, err: errors.New("Some Error")}
+		var out bytes.Buffer
+		// Run
+		err := ApplyOps(bytes.NewReader([]byte("hello")), source, &out)
+		// Verify
+		require.Equal(t, errors.New("Some Error"), err)
+		require.Equal(t, "abc", out.String())
+	})
+
+	t.Run("should return `InvalidDeltaError` when ops of the source contain a gap", func(t *testing.T) {
+		// Setup
+		source := opSourceMock{ops: []models.Op{{Kind: models.OpLiteral, Position: 2, Value: []byte("abc")}}}
+		expectedError := errors.New(constants.InvalidDeltaError)
+		// Run
+		err := ApplyOps(bytes.NewReader([]byte("hello")), source, &bytes.Buffer{})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestApplyStrict(t *testing.T) {
+	t.Run("should return `nil` after writing Updated file when matched blocks match Signature", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+			1: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}},
+		}
+
+		var out bytes.Buffer
+		// Run
+		err := ApplyStrict(bytes.NewReader(data), delta, signature, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "!abcdefghijklmnopqrstuvwxyz", out.String())
+	})
+
+	t.Run("should return `OriginalFileChangedError` when Original file has drifted from Signature", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, []byte("abcdefghijklmnopqrstuvwxyz"))
+		drifted := []byte("abcdefghijklmnoPqrstuvwxyz")
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}}}
+		expectedError := errors.New(constants.OriginalFileChangedError)
+		var out bytes.Buffer
+		// Run
+		err := ApplyStrict(bytes.NewReader(drifted), delta, signature, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 0, out.Len())
+	})
+
+	t.Run("should return `nil` after re-reading a matched block which did not match Signature", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		original := &flakyReaderAtMock{data: data, drifted: []byte("abcdefghijklmnoPqrstuvwxyz"), glitchy: 2}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		// Mock
+		sleep = func(d time.Duration) {}
+		readRetries = 2
+		// Run
+		err := ApplyStrict(original, delta, signature, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, string(data), out.String())
+		require.Equal(t, 3, original.reads)
+		readRetries = 0
+	})
+
+	t.Run("should return `OriginalFileChangedError` when matched block does not match Signature after all retries", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		original := &flakyReaderAtMock{data: data, drifted: []byte("abcdefghijklmnoPqrstuvwxyz"), glitchy: 3}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}}}
+		expectedError := errors.New(constants.OriginalFileChangedError)
+		var out bytes.Buffer
+		// Mock
+		sleep = func(d time.Duration) {}
+		readRetries = 2
+		// Run
+		err := ApplyStrict(original, delta, signature, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 0, out.Len())
+		require.Equal(t, 3, original.reads)
+		readRetries = 0
+	})
+}
+
+func TestOutputSize(t *testing.T) {
+	t.Run("should return size of matched + modified blocks", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}},
+			5: models.Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")},
+		}
+
+		// Run
+		size := OutputSize(delta)
+		// Verify
+		require.Equal(t, int64(7), size)
+	})
+}
+
+func TestSetReadRetries(t *testing.T) {
+	t.Run("should return `nil` and set read retries", func(t *testing.T) {
+		// Run
+		err := SetReadRetries(3)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 3, readRetries)
+		readRetries = 0
+	})
+
+	t.Run("should return `InvalidReadRetriesError` when read retries is negative", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidReadRetriesError)
+		// Run
+		err := SetReadRetries(-1)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 0, readRetries)
+	})
+}
+
+func TestStats(t *testing.T) {
+	t.Run("should return matched + literal byte counts of Delta", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 6, Tail: 10, IsModified: false, Value: []byte{}},
+			5: models.Block{Head: 5, Tail: 6, IsModified: true, Value: []byte(", ")},
+			7: models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}},
+		}
+
+		// Run
+		matched, literal := Stats(delta)
+		// Verify
+		require.Equal(t, int64(10), matched)
+		require.Equal(t, int64(2), literal)
+	})
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// AuditEntry type.
+// This will describe a single Signature block contained within a copied range, and the result of verifying its Strong hash against the bytes written.
+// Weak will be the Weak hash the block is indexed by within the Signature.
+// EG: AuditEntry{Weak: 123, Hash: "some-strong-hash", Head: 0, Tail: 15, Verified: true}.
+type AuditEntry struct {
+	Weak     int64  `json:"weak"`
+	Hash     string `json:"hash"`
+	Head     int64  `json:"head"`
+	Tail     int64  `json:"tail"`
+	Verified bool   `json:"verified"`
+}
+
+// AuditEvent type.
+// This will describe how a single operation of the reconstructed Updated file was produced (see BlockReport), along with every Signature block used to verify it.
+// EG: AuditEvent{BlockReport: BlockReport{Kind: "copy", ...}, Entries: []AuditEntry{{Weak: 123, ...}}}.
+type AuditEvent struct {
+	BlockReport
+	Entries []AuditEntry `json:"entries,omitempty"`
+}
+
+// ApplyWithAudit() will patch an Original file in the same way as ApplyWithReport(), additionally recording the Signature blocks checked for each matched operation.
+// This allows the provenance of every byte of the output to be demonstrated (EG compliance audits).
+// Function will return `events, nil` when Delta has been applied successfully.
+// Function will return `events, OriginalFileChangedError` when any matched block does not match the Signature (output should be discarded).
+// Function will return `events, error` in the same cases as Apply() (events will contain the operations written before the error).
+func ApplyWithAudit(original io.ReaderAt, delta models.Delta, signature models.Signature, out io.Writer) ([]AuditEvent, error) {
+	blocks := signatureBlocks(signature)
+	weaks := signatureWeaks(signature)
+	s := activeSettings()
+	events := []AuditEvent{}
+	mismatched := false
+	err := apply(original, delta, out, func(op models.Op, value []byte) error {
+		checksum := sha256.Sum256(value)
+		event := AuditEvent{BlockReport: BlockReport{Kind: "literal", Position: op.Position, Size: int64(len(value)), Checksum: hex.EncodeToString(checksum[:]), Status: BlockLiteral}}
+		if op.Kind == models.OpCopy {
+			event.Kind, event.Head, event.Tail, event.Status = "copy", op.Head, op.Tail, BlockUnverified
+			if checked, matches := verifyBlock(original, blocks, op.Head, value, s); !matches {
+				event.Status = BlockMismatch
+				mismatched = true
+			} else if checked > 0 {
+				event.Status = BlockVerified
+			}
+
+			event.Entries = auditEntries(blocks, weaks, op.Head, value)
+		}
+
+		events = append(events, event)
+		return nil
+	})
+
+	if err != nil {
+		return events, err
+	}
+
+	if mismatched {
+		return events, errors.New(constants.OriginalFileChangedError)
+	}
+
+	return events, nil
+}
+
+// auditEntries() will verify every Signature block contained within a matched block (read from the provided head of the Original file), in order of position.
+// Note: unlike matchSignature(), every contained block will be checked, rather than stopping at the first mismatch.
+func auditEntries(blocks map[int64]models.StrongSignature, weaks map[int64]int64, head int64, value []byte) []AuditEntry {
+	var entries []AuditEntry
+	for offset := range value {
+		block, exists := blocks[head+int64(offset)]
+		if !exists || block.Tail >= head+int64(len(value)) {
+			continue
+		}
+
+		buffer := value[offset : block.Tail-head+1]
+		verified := hashMatches(block, activeStrongHash(buffer, chunk), legacyHash(buffer))
+		entries = append(entries, AuditEntry{Weak: weaks[block.Head], Hash: block.Hash, Head: block.Head, Tail: block.Tail, Verified: verified})
+	}
+
+	return entries
+}
+
+// signatureWeaks() will index the Weak hash of every block of a Signature (including candidates) by its Head position in the Original file.
+func signatureWeaks(signature models.Signature) map[int64]int64 {
+	weaks := map[int64]int64{}
+	for weak, item := range signature {
+		weaks[item.Head] = weak
+		for _, candidate := range item.Candidates {
+			weaks[candidate.Head] = weak
+		}
+	}
+
+	return weaks
+}
+
+// Reports() will return the BlockReport of each audit event (see ApplyWithReport()).
+func Reports(events []AuditEvent) []BlockReport {
+	report := make([]BlockReport, 0, len(events))
+	for _, event := range events {
+		report = append(report, event.BlockReport)
+	}
+
+	return report
+}
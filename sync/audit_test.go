@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWithAudit(t *testing.T) {
+	t.Run("should return `events, nil` recording each Signature block verified when matched blocks match Signature", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}},
+			26: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+		}
+
+		var out bytes.Buffer
+		// Run
+		events, err := ApplyWithAudit(bytes.NewReader(data), delta, signature, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 2, len(events))
+		require.Equal(t, BlockVerified, events[0].Status)
+		require.Equal(t, 11, len(events[0].Entries)) // Signature contains a block at every offset which fits within the copied range
+		entry := events[0].Entries[0]
+		require.Equal(t, signature[entry.Weak].Hash, entry.Hash)
+		require.Equal(t, int64(0), entry.Head)
+		require.Equal(t, int64(15), entry.Tail)
+		require.Equal(t, true, entry.Verified)
+		require.Equal(t, BlockReport{Kind: "literal", Position: 26, Size: 1, Checksum: checksumOf("!"), Status: BlockLiteral}, events[1].BlockReport)
+		require.Equal(t, []AuditEntry(nil), events[1].Entries)
+		require.Equal(t, "abcdefghijklmnopqrstuvwxyz!", out.String())
+	})
+
+	t.Run("should return `events, OriginalFileChangedError` recording unverified Signature blocks when Original file has drifted from Signature", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, []byte("abcdefghijklmnopqrstuvwxyz"))
+		drifted := []byte("abcdefghijklmnoPqrstuvwxyz")
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}}}
+		expectedError := errors.New(constants.OriginalFileChangedError)
+		var out bytes.Buffer
+		// Run
+		events, err := ApplyWithAudit(bytes.NewReader(drifted), delta, signature, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, len(events))
+		require.Equal(t, BlockMismatch, events[0].Status)
+		require.Equal(t, false, events[0].Entries[0].Verified)
+	})
+}
+
+func TestReports(t *testing.T) {
+	t.Run("should return the BlockReport of each audit event", func(t *testing.T) {
+		// Setup
+		report := BlockReport{Kind: "literal", Position: 0, Size: 1, Checksum: checksumOf("!"), Status: BlockLiteral}
+		events := []AuditEvent{{BlockReport: report}}
+		// Run + Verify
+		require.Equal(t, []BlockReport{report}, Reports(events))
+		require.Equal(t, []BlockReport{}, Reports(nil))
+	})
+}
@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Min unchanged bytes converted into a matched block, shorter runs are kept within the surrounding literal (EG to avoid a block per unchanged byte)
+const bsdiffMinMatch int64 = 8
+
+// BsdiffDelta() will convert a bsdiff patch (see files.DecodeBsdiff()) into a Delta against the Original file, so it can be applied in the same way as other Deltas (EG Apply() or ApplyStrict()).
+// bsdiff adds a diff to bytes of the Original file, so unchanged bytes (EG zeros in the diff) will become matched blocks, while changed + extra bytes will become literal blocks.
+// Note: bytes added beyond either end of the Original file will be added to 0, in the same way as `bspatch`.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, InvalidBsdiffPatchError` when controls read beyond the diff or extra blocks, or do not match the size of the Updated file (EG corrupt patch).
+// Function returns `emptyDelta, UnableToReadOriginalFileError` when unable to read from the Original file.
+func BsdiffDelta(original io.ReaderAt, patch models.BsdiffPatch) (models.Delta, error) {
+	ops := []models.Op{}
+	literal := []byte{}
+	offset, size, diffOffset, extraOffset := int64(0), int64(0), int64(0), int64(0)
+	for _, control := range patch.Controls {
+		if control.Add > int64(len(patch.Diff))-diffOffset || control.Extra > int64(len(patch.Extra))-extraOffset || control.Add > patch.Size-size || control.Extra > patch.Size-size-control.Add {
+			return models.Delta{}, errors.New(constants.InvalidBsdiffPatchError)
+		}
+
+		value, first, last, err := readBsdiffOriginal(original, offset, control.Add)
+		if err != nil {
+			return models.Delta{}, err
+		}
+
+		diff := patch.Diff[diffOffset : diffOffset+control.Add]
+		for index := int64(0); index < control.Add; {
+			// Match unchanged bytes within the Original file
+			end := index
+			for end >= first && end < last && diff[end] == 0 {
+				end++
+			}
+
+			if end-index >= bsdiffMinMatch {
+				if len(literal) > 0 {
+					ops = append(ops, models.Op{Kind: models.OpLiteral, Value: literal})
+					literal = []byte{}
+				}
+
+				ops = append(ops, models.Op{Kind: models.OpCopy, Head: offset + index, Tail: offset + end - 1})
+				index = end
+				continue
+			}
+
+			// Keep short runs of unchanged bytes (or a single changed byte) within the literal
+			if end == index {
+				end++
+			}
+
+			for ; index < end; index++ {
+				literal = append(literal, value[index]+diff[index])
+			}
+		}
+
+		literal = append(literal, patch.Extra[extraOffset:extraOffset+control.Extra]...)
+		offset += control.Add + control.Seek
+		size += control.Add + control.Extra
+		diffOffset += control.Add
+		extraOffset += control.Extra
+	}
+
+	if size != patch.Size {
+		return models.Delta{}, errors.New(constants.InvalidBsdiffPatchError)
+	}
+
+	if len(literal) > 0 {
+		ops = append(ops, models.Op{Kind: models.OpLiteral, Value: literal})
+	}
+
+	return mergeOps(ops), nil
+}
+
+// readBsdiffOriginal() will read the bytes of the Original file which a bsdiff control adds its diff to, from the provided offset.
+// Note: bytes before the start or beyond the end of the Original file will be 0.
+// Function returns `value, first, last, nil` when successful, where value[first:last] was read from the Original file.
+// Function returns `nil, 0, 0, UnableToReadOriginalFileError` when unable to read from the Original file.
+func readBsdiffOriginal(original io.ReaderAt, offset int64, length int64) ([]byte, int64, int64, error) {
+	value := make([]byte, length)
+	first := -offset
+	if first < 0 {
+		first = 0
+	} else if first > length {
+		first = length
+	}
+
+	if first == length {
+		return value, first, first, nil
+	}
+
+	read, err := original.ReadAt(value[first:], offset+first)
+	if err != nil && err != io.EOF {
+		return nil, 0, 0, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	return value, first, first + int64(read), nil
+}
@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBsdiffDelta(t *testing.T) {
+	t.Run("should convert unchanged bytes into matched blocks + changed bytes into literal blocks", func(t *testing.T) {
+		// Setup
+		original := []byte("The quick brown fox jumps over the lazy dog")
+		diff := make([]byte, 19)
+		// Change `f` to `b` (EG adding 0xfc, which wraps to -4), after 16 unchanged bytes
+		diff[16] = 0xfc
+		patch := models.BsdiffPatch{
+			Controls: []models.BsdiffControl{{Add: 19, Extra: 1, Seek: 16}, {Add: 0, Extra: 0, Seek: 0}},
+			Diff:     diff,
+			Extra:    []byte("!"),
+			Size:     20,
+		}
+
+		expectedDelta := models.Delta{
+			0:  {Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+			16: {Head: 16, Tail: 19, IsModified: true, Value: []byte("box!")},
+		}
+
+		var output bytes.Buffer
+		// Run
+		delta, err := BsdiffDelta(bytes.NewReader(original), patch)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &output))
+		require.Equal(t, "The quick brown box!", output.String())
+	})
+
+	t.Run("should seek backwards, adding to 0 beyond the end of the Original file in the same way as bspatch", func(t *testing.T) {
+		// Setup
+		original := []byte("0123456789abcdef")
+		diff := append(make([]byte, 8), append(make([]byte, 8), 'x', 'y')...)
+		patch := models.BsdiffPatch{
+			Controls: []models.BsdiffControl{{Add: 8, Extra: 0, Seek: 0}, {Add: 10, Extra: 0, Seek: 0}},
+			Diff:     diff,
+			Size:     18,
+		}
+
+		// Second control starts at offset 8, reading 2 bytes beyond the end of the Original file
+		var output bytes.Buffer
+		// Run
+		delta, err := BsdiffDelta(bytes.NewReader(original), patch)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Delta{0: {Head: 0, Tail: 15, IsModified: false, Value: []byte{}}, 16: {Head: 16, Tail: 17, IsModified: true, Value: []byte("xy")}}, delta)
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &output))
+		require.Equal(t, "0123456789abcdefxy", output.String())
+		// Seek backwards before the start of the Original file
+		patch.Controls = []models.BsdiffControl{{Add: 0, Extra: 0, Seek: -2}, {Add: 18, Extra: 0, Seek: 0}}
+		patch.Diff = append([]byte{'<', '<'}, make([]byte, 16)...)
+		delta, err = BsdiffDelta(bytes.NewReader(original), patch)
+		require.Equal(t, nil, err)
+		output.Reset()
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &output))
+		require.Equal(t, "<<0123456789abcdef", output.String())
+	})
+
+	t.Run("should keep short runs of unchanged bytes within literal blocks", func(t *testing.T) {
+		// Setup
+		original := []byte("aaaaaaaa")
+		patch := models.BsdiffPatch{
+			Controls: []models.BsdiffControl{{Add: 8, Extra: 0, Seek: 0}},
+			Diff:     []byte{1, 0, 0, 1, 0, 0, 0, 1},
+			Size:     8,
+		}
+
+		// Run
+		delta, err := BsdiffDelta(bytes.NewReader(original), patch)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Delta{0: {Head: 0, Tail: 7, IsModified: true, Value: []byte("baabaaab")}}, delta)
+	})
+
+	t.Run("should return `emptyDelta, InvalidBsdiffPatchError` when controls read beyond the patch or Updated file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidBsdiffPatchError)
+		patches := []models.BsdiffPatch{
+			{Controls: []models.BsdiffControl{{Add: 4}}, Diff: make([]byte, 2), Size: 4},
+			{Controls: []models.BsdiffControl{{Extra: 4}}, Extra: make([]byte, 2), Size: 4},
+			{Controls: []models.BsdiffControl{{Add: 4}}, Diff: make([]byte, 4), Size: 2},
+			{Controls: []models.BsdiffControl{{Add: 2}}, Diff: make([]byte, 2), Size: 4},
+		}
+
+		for _, patch := range patches {
+			// Run
+			delta, err := BsdiffDelta(bytes.NewReader([]byte("original")), patch)
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Delta{}, delta)
+		}
+	})
+
+	t.Run("should return `emptyDelta, UnableToReadOriginalFileError` when unable to read the Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		patch := models.BsdiffPatch{Controls: []models.BsdiffControl{{Add: 4}}, Diff: make([]byte, 4), Size: 4}
+		// Run
+		delta, err := BsdiffDelta(failingReaderAtMock{}, patch)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
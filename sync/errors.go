@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// OriginalFileError() will replace a generic error returned when opening a file (see files.OpenFile()) with the specific Original file error.
+// EG: `FileDoesNotExistError` -> `OriginalFileDoesNotExistError`, `SearchingForFileButFoundDirError` -> `OriginalFileIsFolderError`.
+// Note: other errors will be returned unchanged.
+func OriginalFileError(err error) error {
+	switch err.Error() {
+	case constants.FileDoesNotExistError:
+		return errors.New(constants.OriginalFileDoesNotExistError)
+	case constants.SearchingForFileButFoundDirError:
+		return errors.New(constants.OriginalFileIsFolderError)
+	}
+
+	return err
+}
+
+// UpdatedFileError() will replace a generic error returned when opening a file (see files.OpenFile()) with the specific Updated file error.
+// EG: `FileDoesNotExistError` -> `UpdatedFileDoesNotExistError`, `SearchingForFileButFoundDirError` -> `UpdatedFileIsFolderError`.
+// Note: other errors will be returned unchanged.
+func UpdatedFileError(err error) error {
+	switch err.Error() {
+	case constants.FileDoesNotExistError:
+		return errors.New(constants.UpdatedFileDoesNotExistError)
+	case constants.SearchingForFileButFoundDirError:
+		return errors.New(constants.UpdatedFileIsFolderError)
+	}
+
+	return err
+}
@@ -0,0 +1,35 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginalFileError(t *testing.T) {
+	t.Run("should return specific Original file errors", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, errors.New(constants.OriginalFileDoesNotExistError), OriginalFileError(errors.New(constants.FileDoesNotExistError)))
+		require.Equal(t, errors.New(constants.OriginalFileIsFolderError), OriginalFileError(errors.New(constants.SearchingForFileButFoundDirError)))
+	})
+
+	t.Run("should return other errors unchanged", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, errors.New("other"), OriginalFileError(errors.New("other")))
+	})
+}
+
+func TestUpdatedFileError(t *testing.T) {
+	t.Run("should return specific Updated file errors", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, errors.New(constants.UpdatedFileDoesNotExistError), UpdatedFileError(errors.New(constants.FileDoesNotExistError)))
+		require.Equal(t, errors.New(constants.UpdatedFileIsFolderError), UpdatedFileError(errors.New(constants.SearchingForFileButFoundDirError)))
+	})
+
+	t.Run("should return other errors unchanged", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, errors.New("other"), UpdatedFileError(errors.New("other")))
+	})
+}
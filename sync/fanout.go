@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// PatchTarget type.
+// This will describe one copy of the Original file to be patched, and where its reconstructed Updated file should be written.
+// Name will be used to identify the target in results (EG the path of an install on a shared filesystem).
+type PatchTarget struct {
+	Name     string
+	Original io.ReaderAt
+	Out      io.Writer
+}
+
+// PatchResult type.
+// This will contain the outcome of patching a single PatchTarget (Err will be nil when successful).
+type PatchResult struct {
+	Name string
+	Err  error
+}
+
+// ApplyAll() will apply one Delta to many identical Original files in a single call, returning a result per target (in target order).
+// When a Signature is provided, each target will be verified in the same way as ApplyStrict() (EG detecting targets which have drifted), otherwise Apply() will be used.
+// Note: a failed target will not stop the remaining targets from being patched, and output written for a failed target should be discarded.
+// Function returns `results, failed` where failed is the number of targets which could not be patched.
+func ApplyAll(delta models.Delta, signature models.Signature, targets []PatchTarget) ([]PatchResult, int) {
+	results := make([]PatchResult, 0, len(targets))
+	failed := 0
+	for _, target := range targets {
+		var err error
+		if signature != nil {
+			err = ApplyStrict(target.Original, delta, signature, target.Out)
+		} else {
+			err = Apply(target.Original, delta, target.Out)
+		}
+
+		if err != nil {
+			failed++
+		}
+
+		results = append(results, PatchResult{Name: target.Name, Err: err})
+	}
+
+	return results, failed
+}
@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyAll(t *testing.T) {
+	t.Run("should return a result per target after patching every target", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+			1: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}},
+		}
+
+		var first, second bytes.Buffer
+		targets := []PatchTarget{
+			{Name: "first", Original: bytes.NewReader(data), Out: &first},
+			{Name: "second", Original: bytes.NewReader(data), Out: &second},
+		}
+
+		expectedResults := []PatchResult{{Name: "first"}, {Name: "second"}}
+		// Run
+		results, failed := ApplyAll(delta, nil, targets)
+		// Verify
+		require.Equal(t, expectedResults, results)
+		require.Equal(t, 0, failed)
+		require.Equal(t, "!abcdefghijklmnopqrstuvwxyz", first.String())
+		require.Equal(t, "!abcdefghijklmnopqrstuvwxyz", second.String())
+	})
+
+	t.Run("should continue patching + count failed targets when a target has drifted from Signature", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}}}
+		var drifted, valid bytes.Buffer
+		targets := []PatchTarget{
+			{Name: "drifted", Original: bytes.NewReader([]byte("abcdefghijklmnoPqrstuvwxyz")), Out: &drifted},
+			{Name: "valid", Original: bytes.NewReader(data), Out: &valid},
+		}
+
+		expectedResults := []PatchResult{
+			{Name: "drifted", Err: errors.New(constants.OriginalFileChangedError)},
+			{Name: "valid"},
+		}
+
+		// Run
+		results, failed := ApplyAll(delta, signature, targets)
+		// Verify
+		require.Equal(t, expectedResults, results)
+		require.Equal(t, 1, failed)
+		require.Equal(t, string(data), valid.String())
+	})
+
+	t.Run("should return no results when no targets provided", func(t *testing.T) {
+		// Run
+		results, failed := ApplyAll(models.Delta{}, nil, []PatchTarget{})
+		// Verify
+		require.Equal(t, []PatchResult{}, results)
+		require.Equal(t, 0, failed)
+	})
+}
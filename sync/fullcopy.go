@@ -0,0 +1,14 @@
+package sync
+
+import "github.com/curtismenmuir/go-file-diff/models"
+
+// FullCopy() will create a Delta which contains the full contents of the Updated file as a single literal block.
+// Note: this can be used in place of a generated Delta when the Updated file has been heavily rewritten (EG Delta larger than the file).
+// Function returns `emptyDelta` when contents are empty.
+func FullCopy(contents []byte) models.Delta {
+	if len(contents) == 0 {
+		return models.Delta{}
+	}
+
+	return models.Delta{0: models.Block{Head: 0, Tail: int64(len(contents)) - 1, IsModified: true, Value: contents}}
+}
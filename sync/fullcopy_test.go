@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullCopy(t *testing.T) {
+	t.Run("should return single literal block containing full contents", func(t *testing.T) {
+		// Setup
+		contents := []byte("heavily rewritten file")
+		expected := models.Delta{0: models.Block{Head: 0, Tail: int64(len(contents)) - 1, IsModified: true, Value: contents}}
+		// Run
+		delta := FullCopy(contents)
+		// Verify
+		require.Equal(t, expected, delta)
+	})
+
+	t.Run("should reconstruct Updated file when applied to any Original file", func(t *testing.T) {
+		// Setup
+		contents := []byte("heavily rewritten file")
+		out := bytes.Buffer{}
+		// Run
+		err := Apply(bytes.NewReader([]byte("unrelated original")), FullCopy(contents), &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, contents, out.Bytes())
+	})
+
+	t.Run("should return empty Delta when contents are empty", func(t *testing.T) {
+		// Run
+		delta := FullCopy([]byte{})
+		// Verify
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
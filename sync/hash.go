@@ -0,0 +1,206 @@
+package sync
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+const (
+	DefaultStrongHash string = strong.SHA256Name // SHA-256 Strong hash
+	DefaultWeakHash   string = "rabin-karp"      // Rolling Rabin–Karp Weak hash
+	Adler32WeakHash   string = "adler32"         // Rolling checksum of rsync (Adler-32 style)
+	BuzhashWeakHash   string = "buzhash"         // Rolling Buzhash (cyclic polynomial) Weak hash, for throughput over rsync compatibility
+	// Weak hashes of rdiff Signature files (see files.DecodeRdiffSignature())
+	RollsumWeakHash        string = rolling.RollsumName        // Rolling checksum of librsync before 2.2
+	RdiffRabinKarpWeakHash string = rolling.RdiffRabinKarpName // Rolling RabinKarp of librsync 2.2+
+)
+
+// StrongHash type.
+// This will hash a buffer of (up to) chunkSize bytes, returning the hash encoded as a string.
+// Strong hashes are used to confirm a Weak hash match, so should be collision resistant (EG SHA-256, SM3, GOST).
+// Algorithms registered within `hash/strong` can also be selected, with each hash encoded as a hex string.
+type StrongHash func(buffer []byte, chunkSize int64) string
+
+// WeakHash type.
+// This will hash a buffer of (up to) chunkSize bytes, and roll the hash forward one byte at a time (see `rolling.Hash`).
+// Functions can be registered as a Weak hash with `rolling.Funcs`.
+type WeakHash = rolling.Hash
+
+var (
+	// Strong hashes registered with RegisterStrongHash() (see `hash/strong` for the default algorithms)
+	strongHashes = map[string]StrongHash{}
+	weakHashes   = map[string]WeakHash{
+		DefaultWeakHash:        rolling.NewRabinKarp(),
+		Adler32WeakHash:        rolling.NewAdler32(),
+		BuzhashWeakHash:        rolling.NewBuzhash(),
+		RollsumWeakHash:        rolling.NewRollsum(),
+		RdiffRabinKarpWeakHash: rolling.NewRdiffRabinKarp(),
+	}
+	generateStrongHash = hexHash(strong.SHA256())
+	// Hash algorithms used when generating Signatures + Deltas
+	activeStrongHash     = generateStrongHash
+	activeStrongHashName = DefaultStrongHash // Selected by Signature files which record their Strong hash (see UseSignatureMetadata())
+	activeWeakHash       = weakHashes[DefaultWeakHash]
+	activeWeakHashName   = DefaultWeakHash // Recorded within Signature files (see Metadata())
+	// Optional second Strong hash algorithm, used while migrating Signatures between algorithms (nil when disabled)
+	activeLegacyHash StrongHash
+)
+
+// hashMatches() will check if a Signature block matches the Strong hash (or Legacy hash) of a buffer.
+// When migrating algorithms, a block generated by either algorithm will match (EG a Signature containing only Legacy hashes, or both).
+// Note: legacyHash will be empty when no Legacy hash algorithm has been selected.
+// Note: a Strong hash truncated within the Signature (EG rdiff Signatures) will match the start of the Strong hash of the buffer.
+func hashMatches(block models.StrongSignature, strongHash string, legacyHash string) bool {
+	if block.Hash == strongHash || (block.Hash != "" && len(block.Hash) < len(strongHash) && strings.HasPrefix(strongHash, block.Hash)) {
+		return true
+	}
+
+	return legacyHash != "" && (block.Hash == legacyHash || block.LegacyHash == legacyHash)
+}
+
+// hashNames() will return the sorted names of the provided hash algorithms, plus any additional names provided.
+func hashNames[T any](hashes map[string]T, additional ...string) []string {
+	names := append(make([]string, 0, len(hashes)+len(additional)), additional...)
+	for name := range hashes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// hexHash() will adapt a Strong hash algorithm from `hash/strong` to a StrongHash, encoding each hash as a hex string.
+func hexHash(hash strong.Hash) StrongHash {
+	return func(buffer []byte, chunkSize int64) string {
+		return hex.EncodeToString(hash.Sum(buffer))
+	}
+}
+
+// legacyHash() will hash a buffer with the selected Legacy hash algorithm.
+// Function returns `""` when no Legacy hash algorithm has been selected.
+func legacyHash(buffer []byte) string {
+	if activeLegacyHash == nil {
+		return ""
+	}
+
+	return activeLegacyHash(buffer, chunk)
+}
+
+// lookupStrongHash() will return the Strong hash registered with the provided name, either with RegisterStrongHash() or within `hash/strong`.
+// Function returns `hash, true` when registered.
+// Function returns `nil, false` when not registered.
+func lookupStrongHash(name string) (StrongHash, bool) {
+	if hash, exists := strongHashes[name]; exists {
+		return hash, true
+	}
+
+	hash, err := strong.Lookup(name)
+	if err != nil {
+		return nil, false
+	}
+
+	return hexHash(hash), true
+}
+
+// RegisterStrongHash() will register an additional Strong hash algorithm which can be selected with UseHashes().
+// Note: registration is not safe for concurrent use, so should happen during `init()` (EG in a file behind a build tag).
+// Function returns `nil` when successful.
+// Function returns `InvalidHashError` when name is empty or hash is nil.
+// Function returns `HashAlreadyRegisteredError` when a Strong hash is already registered with the provided name.
+func RegisterStrongHash(name string, hash StrongHash) error {
+	if name == "" || hash == nil {
+		return errors.New(constants.InvalidHashError)
+	}
+
+	if _, exists := lookupStrongHash(name); exists {
+		return errors.New(constants.HashAlreadyRegisteredError)
+	}
+
+	strongHashes[name] = hash
+	return nil
+}
+
+// RegisterWeakHash() will register an additional Weak hash algorithm which can be selected with UseHashes().
+// Note: registration is not safe for concurrent use, so should happen during `init()` (EG in a file behind a build tag).
+// Function returns `nil` when successful.
+// Function returns `InvalidHashError` when name is empty, hash is nil, or either function of `rolling.Funcs` is nil.
+// Function returns `HashAlreadyRegisteredError` when a Weak hash is already registered with the provided name.
+func RegisterWeakHash(name string, hash WeakHash) error {
+	if name == "" || hash == nil {
+		return errors.New(constants.InvalidHashError)
+	}
+
+	if funcs, ok := hash.(rolling.Funcs); ok && (funcs.SumFunc == nil || funcs.RollFunc == nil) {
+		return errors.New(constants.InvalidHashError)
+	}
+
+	if _, exists := weakHashes[name]; exists {
+		return errors.New(constants.HashAlreadyRegisteredError)
+	}
+
+	weakHashes[name] = hash
+	return nil
+}
+
+// StrongHashes() will return the names of all registered Strong hash algorithms.
+func StrongHashes() []string {
+	return hashNames(strongHashes, strong.Names()...)
+}
+
+// UseHashes() will select the registered Strong + Weak hash algorithms used when generating Signatures + Deltas.
+// An empty name will select the default algorithm.
+// Note: a Delta must be generated with the same algorithms used to generate the Signature (the Weak hash is recorded within Signature files, see UseSignatureMetadata()).
+// Function returns `nil` when successful.
+// Function returns `HashNotRegisteredError` when either algorithm has not been registered (active algorithms will be unchanged).
+func UseHashes(strongName string, weakName string) error {
+	if strongName == "" {
+		strongName = DefaultStrongHash
+	}
+
+	if weakName == "" {
+		weakName = DefaultWeakHash
+	}
+
+	strongHash, strongExists := lookupStrongHash(strongName)
+	weakHash, weakExists := weakHashes[weakName]
+	if !strongExists || !weakExists {
+		return errors.New(constants.HashNotRegisteredError)
+	}
+
+	activeStrongHash, activeStrongHashName = strongHash, strongName
+	activeWeakHash, activeWeakHashName = weakHash, weakName
+	return nil
+}
+
+// UseLegacyHash() will select a second registered Strong hash algorithm, used while migrating Signatures between algorithms.
+// Generated Signatures will carry both hashes per block, and Delta generation + strict patching will accept a match on either.
+// Once all Signatures carry the new Strong hash, the Legacy hash can be dropped by regenerating Signatures without a Legacy hash algorithm.
+// An empty name will disable the Legacy hash.
+// Function returns `nil` when successful.
+// Function returns `HashNotRegisteredError` when the algorithm has not been registered (Legacy hash will be unchanged).
+func UseLegacyHash(name string) error {
+	if name == "" {
+		activeLegacyHash = nil
+		return nil
+	}
+
+	hash, exists := lookupStrongHash(name)
+	if !exists {
+		return errors.New(constants.HashNotRegisteredError)
+	}
+
+	activeLegacyHash = hash
+	return nil
+}
+
+// WeakHashes() will return the names of all registered Weak hash algorithms.
+func WeakHashes() []string {
+	return hashNames(weakHashes)
+}
@@ -0,0 +1,251 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// strongHashMock type.
+// This will return the block reversed as its hash (see `strong.Hash`).
+type strongHashMock struct{}
+
+func (strongHashMock) Sum(block []byte) []byte {
+	sum := make([]byte, len(block))
+	for index := range block {
+		sum[len(block)-1-index] = block[index]
+	}
+
+	return sum
+}
+
+func (strongHashMock) Size() int {
+	return 16
+}
+
+func (strongHashMock) Name() string {
+	return "reverse"
+}
+
+func TestHashMatches(t *testing.T) {
+	block := models.StrongSignature{Hash: "new", LegacyHash: "old", Head: 0, Tail: 15}
+	t.Run("should return true when Strong hash matches", func(t *testing.T) {
+		require.Equal(t, true, hashMatches(block, "new", ""))
+	})
+
+	t.Run("should return true when Legacy hash matches", func(t *testing.T) {
+		require.Equal(t, true, hashMatches(block, "other", "old"))
+	})
+
+	t.Run("should return true when Legacy hash matches a Signature containing only Legacy hashes", func(t *testing.T) {
+		require.Equal(t, true, hashMatches(models.StrongSignature{Hash: "old"}, "new", "old"))
+	})
+
+	t.Run("should return true when a truncated Strong hash (EG rdiff Signatures) matches the start of the Strong hash", func(t *testing.T) {
+		require.Equal(t, true, hashMatches(models.StrongSignature{Hash: "ne"}, "new", ""))
+		require.Equal(t, false, hashMatches(models.StrongSignature{Hash: "ew"}, "new", ""))
+	})
+
+	t.Run("should return false when neither hash matches", func(t *testing.T) {
+		require.Equal(t, false, hashMatches(block, "other", "another"))
+		require.Equal(t, false, hashMatches(models.StrongSignature{Hash: "new"}, "other", ""))
+		require.Equal(t, false, hashMatches(models.StrongSignature{Hash: "newer"}, "new", ""))
+	})
+}
+
+func TestRegisterStrongHash(t *testing.T) {
+	t.Run("should register Strong hash when name not already registered", func(t *testing.T) {
+		// Setup
+		hash := func(buffer []byte, chunkSize int64) string {
+			return string(buffer)
+		}
+
+		// Run
+		err := RegisterStrongHash("test-strong", hash)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []string{strong.BLAKE2bName, strong.MD4Name, DefaultStrongHash, "test-strong"}, StrongHashes())
+	})
+
+	t.Run("should return `HashAlreadyRegisteredError` when name already registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashAlreadyRegisteredError)
+		// Run
+		err := RegisterStrongHash(DefaultStrongHash, generateStrongHash)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `InvalidHashError` when hash is nil", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidHashError)
+		// Run
+		err := RegisterStrongHash("nil-strong", nil)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestRegisterWeakHash(t *testing.T) {
+	t.Run("should register Weak hash when name not already registered", func(t *testing.T) {
+		// Setup
+		hash := rolling.Funcs{SumFunc: rolling.NewRabinKarp().Sum, RollFunc: rolling.NewRabinKarp().Roll}
+		// Run
+		err := RegisterWeakHash("test-weak", hash)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []string{Adler32WeakHash, BuzhashWeakHash, DefaultWeakHash, RdiffRabinKarpWeakHash, RollsumWeakHash, "test-weak"}, WeakHashes())
+	})
+
+	t.Run("should return `HashAlreadyRegisteredError` when name already registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashAlreadyRegisteredError)
+		// Run
+		err := RegisterWeakHash(DefaultWeakHash, rolling.NewRabinKarp())
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return `InvalidHashError` when Roll is nil", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidHashError)
+		// Run
+		err := RegisterWeakHash("nil-weak", rolling.Funcs{SumFunc: rolling.NewRabinKarp().Sum})
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
+
+func TestUseHashes(t *testing.T) {
+	t.Run("should select registered hash algorithms", func(t *testing.T) {
+		// Setup
+		strongHashes["use-strong"] = func(buffer []byte, chunkSize int64) string {
+			return "some-strong-hash"
+		}
+
+		// Run
+		err := UseHashes("use-strong", "")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "some-strong-hash", activeStrongHash(testBuffer, testChunk))
+	})
+
+	t.Run("should select hash algorithms registered within `hash/strong`", func(t *testing.T) {
+		// Setup
+		require.Equal(t, nil, strong.Register(strongHashMock{}))
+		// Run
+		err := UseHashes("reverse", "")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, "706f6e6d6c6b6a696867666564636261", activeStrongHash(testBuffer, testChunk))
+		require.Contains(t, StrongHashes(), "reverse")
+	})
+
+	t.Run("should select default hash algorithms when names are empty", func(t *testing.T) {
+		// Run
+		err := UseHashes("", "")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, testBufferStrongHash, activeStrongHash(testBuffer, testChunk))
+		require.Equal(t, testBufferHash, activeWeakHash.Sum(testBuffer, testChunk))
+	})
+
+	t.Run("should generate Signature + Delta which reconstruct the Updated file when Adler-32 Weak hash selected", func(t *testing.T) {
+		// Setup
+		original := []byte("the quick brown fox jumps over the lazy dog, then runs far away from the farm")
+		updated := []byte("the quick brown fox leaps over the lazy dog, then runs far away from the old farm")
+		require.Equal(t, nil, UseHashes("", Adler32WeakHash))
+		signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		require.Equal(t, nil, err)
+		// Run
+		delta, err := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+		require.Equal(t, Adler32WeakHash, Metadata().WeakHash)
+		// Restore, so later tests use the default algorithms
+		require.Equal(t, nil, UseHashes("", ""))
+	})
+
+	t.Run("should generate Signature + Delta which reconstruct the Updated file when Buzhash Weak hash selected", func(t *testing.T) {
+		// Setup
+		original := []byte("the quick brown fox jumps over the lazy dog, then runs far away from the farm")
+		updated := []byte("the quick brown fox leaps over the lazy dog, then runs far away from the old farm")
+		require.Equal(t, nil, UseHashes("", BuzhashWeakHash))
+		signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		require.Equal(t, nil, err)
+		// Run
+		delta, err := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+		require.Equal(t, BuzhashWeakHash, Metadata().WeakHash)
+		// Restore, so later tests use the default algorithms
+		require.Equal(t, nil, UseHashes("", ""))
+	})
+
+	t.Run("should return `HashNotRegisteredError` and keep active algorithms when name not registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		err := UseHashes(DefaultStrongHash, "unknown")
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, testBufferHash, activeWeakHash.Sum(testBuffer, testChunk))
+	})
+}
+
+func TestUseLegacyHash(t *testing.T) {
+	original := []byte("the quick brown fox jumps over the lazy dog")
+	t.Run("should generate Signature carrying Strong + Legacy hashes when Legacy hash selected", func(t *testing.T) {
+		// Setup
+		require.Equal(t, nil, UseLegacyHash("test-strong"))
+		// Run
+		signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		// Verify
+		require.Equal(t, nil, err)
+		for _, item := range signature {
+			require.Equal(t, generateStrongHash(original[item.Head:item.Tail+1], chunk), item.Hash)
+			require.Equal(t, string(original[item.Head:item.Tail+1]), item.LegacyHash)
+		}
+	})
+
+	t.Run("should match blocks of a Signature generated with only the Legacy hash", func(t *testing.T) {
+		// Setup
+		require.Equal(t, nil, UseHashes("test-strong", ""))
+		require.Equal(t, nil, UseLegacyHash(""))
+		signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, UseHashes("", ""))
+		require.Equal(t, nil, UseLegacyHash("test-strong"))
+		// Run
+		delta, err := GenerateDelta(bufio.NewReader(bytes.NewReader(append(original, '!'))), signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		matched, literal := Stats(delta)
+		require.Equal(t, int64(len(original)), matched)
+		require.Equal(t, int64(1), literal)
+	})
+
+	t.Run("should return `HashNotRegisteredError` when algorithm not registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		err := UseLegacyHash("unknown")
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, nil, UseLegacyHash(""))
+		require.Equal(t, true, activeLegacyHash == nil)
+	})
+}
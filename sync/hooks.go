@@ -0,0 +1,85 @@
+package sync
+
+import "github.com/curtismenmuir/go-file-diff/models"
+
+// BlockEvent type.
+// This will describe a block-level decision made during Delta generation.
+// Position will be the offset of the block within the Updated file.
+// Head + Tail will define the position of a matched block within the Original file (unused for literal blocks).
+// Size will be the number of bytes in the block (0 when a literal block has just started).
+// EG: BlockEvent{Position: 16, Head: 0, Tail: 15, Size: 16}.
+type BlockEvent struct {
+	Position int64
+	Head     int64
+	Tail     int64
+	Size     int64
+}
+
+// Hooks type.
+// This will contain optional callbacks fired during Delta generation (nil callbacks are skipped).
+// BlockMatched will fire when a matched block (EG bytes copied from the Original file) is added to Delta.
+// LiteralStarted will fire when a literal block (EG new bytes not found in the Original file) is started.
+// LiteralFlushed will fire when a literal block is added to Delta.
+// Note: a started literal block will not be flushed when all of its bytes are covered by the following matched block.
+type Hooks struct {
+	BlockMatched   func(event BlockEvent)
+	LiteralStarted func(event BlockEvent)
+	LiteralFlushed func(event BlockEvent)
+}
+
+// deltaBuilder type.
+// This will pass finalised blocks to a DeltaWriter, firing any provided Hooks.
+// The first error returned by the writer will be recorded, and any following blocks will be skipped.
+// Blocks + first will be used to check whether the written Delta contains changes.
+type deltaBuilder struct {
+	writer DeltaWriter
+	hooks  Hooks
+	err    error
+	blocks int
+	first  models.Block
+}
+
+// hasChanges() will check the blocks written contain modifications for an Original file of the provided size.
+// Written blocks contain no changes when they are a single matched block covering the full Original file (or both files are empty).
+func (b *deltaBuilder) hasChanges(size int64) bool {
+	if b.blocks == 0 {
+		return size != 0
+	}
+
+	return b.blocks != 1 || b.first.IsModified || b.first.Head != 0 || b.first.Tail != size-1
+}
+
+// startLiteral() will fire the LiteralStarted hook for a literal block starting at the provided position.
+func (b *deltaBuilder) startLiteral(position int64) {
+	if b.hooks.LiteralStarted != nil {
+		b.hooks.LiteralStarted(BlockEvent{Position: position})
+	}
+}
+
+// writeBlock() will pass a block to the DeltaWriter at the provided position, firing the BlockMatched or LiteralFlushed hook.
+func (b *deltaBuilder) writeBlock(position int64, block models.Block) {
+	if b.err != nil {
+		return
+	}
+
+	if b.err = b.writer.WriteBlock(position, block); b.err != nil {
+		return
+	}
+
+	if position == 0 {
+		b.first = block
+	}
+
+	b.blocks++
+	if block.IsModified {
+		if b.hooks.LiteralFlushed != nil {
+			b.hooks.LiteralFlushed(BlockEvent{Position: position, Size: int64(len(block.Value))})
+		}
+
+		return
+	}
+
+	if b.hooks.BlockMatched != nil {
+		b.hooks.BlockMatched(BlockEvent{Position: position, Head: block.Head, Tail: block.Tail, Size: block.Tail - block.Head + 1})
+	}
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaBuilderWriteBlock(t *testing.T) {
+	t.Run("should add block to Delta when no hooks provided", func(t *testing.T) {
+		// Setup
+		builder := &deltaBuilder{writer: models.Delta{}}
+		block := models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}
+		// Run
+		builder.writeBlock(4, block)
+		builder.startLiteral(20)
+		// Verify
+		require.Equal(t, models.Delta{4: block}, builder.writer)
+	})
+
+	t.Run("should fire `LiteralFlushed` hook when literal block added to Delta", func(t *testing.T) {
+		// Setup
+		events := []BlockEvent{}
+		builder := &deltaBuilder{writer: models.Delta{}, hooks: Hooks{LiteralFlushed: func(event BlockEvent) {
+			events = append(events, event)
+		}}}
+
+		block := models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a', 'b'}}
+		// Run
+		builder.writeBlock(7, block)
+		// Verify
+		require.Equal(t, models.Delta{7: block}, builder.writer)
+		require.Equal(t, []BlockEvent{{Position: 7, Size: 2}}, events)
+	})
+}
+
+func TestGenerateDeltaWithHooks(t *testing.T) {
+	t.Run("should fire hooks for each block-level decision when generating Delta", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		rollCount := 0
+		newBlock := []byte{'1', '2', '3'}
+		initialBuffer := []byte{newBlock[0], newBlock[1], newBlock[2], 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm'}
+		modifiedBlock := []byte{'n', 'o', 'p'}
+		events := []string{}
+		matched := []BlockEvent{}
+		flushed := []BlockEvent{}
+		started := []BlockEvent{}
+		hooks := Hooks{
+			BlockMatched: func(event BlockEvent) {
+				events = append(events, "matched")
+				matched = append(matched, event)
+			},
+			LiteralStarted: func(event BlockEvent) {
+				events = append(events, "started")
+				started = append(started, event)
+			},
+			LiteralFlushed: func(event BlockEvent) {
+				events = append(events, "flushed")
+				flushed = append(flushed, event)
+			},
+		}
+
+		// Initialise Signature
+		signature := models.Signature{}
+		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return initialBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			// Return EOF to simulate reaching EOF
+			if rollCount == len(modifiedBlock) {
+				return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+			}
+
+			// Roll buffer
+			initialByte := initialBuffer[0]
+			nextByte := modifiedBlock[rollCount]
+			buf := make([]byte, 0)
+			buf = append(buf, initialBuffer[1:]...)
+			buf = append(buf, nextByte)
+			initialBuffer = buf
+			rollCount++
+			return initialBuffer, initialByte, nextByte, nil
+		}
+
+		// Run
+		delta, err := GenerateDeltaWithHooks(reader, signature, hooks, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 2, len(delta))
+		require.Equal(t, []string{"started", "flushed", "matched"}, events)
+		require.Equal(t, []BlockEvent{{Position: 0}}, started)
+		require.Equal(t, []BlockEvent{{Position: 0, Size: 3}}, flushed)
+		require.Equal(t, []BlockEvent{{Position: 3, Head: 0, Tail: 15, Size: 16}}, matched)
+	})
+}
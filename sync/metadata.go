@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// ChunkSize() will return the chunk size (bytes) used when generating Signatures + Deltas.
+func ChunkSize() int64 {
+	return chunk
+}
+
+// Metadata() will return the metadata recorded within Signature files generated with the current settings (EG chunk size, Weak hash + Strong hash).
+// Note: the Strong hash is recorded so Deltas select it (see UseSignatureMetadata()), as blocks would never match when generated with a different Strong hash.
+func Metadata() models.SignatureMetadata {
+	return models.SignatureMetadata{ChunkSize: chunk, WeakHash: activeWeakHashName, StrongHash: activeStrongHashName}
+}
+
+// restoreMetadata() will restore the settings described by the provided metadata (EG once resolved by UseSignatureMetadata()).
+// Note: the Strong hash will only be restored when set (EG Signature files written before the Strong hash was recorded).
+func restoreMetadata(metadata models.SignatureMetadata) {
+	chunk = metadata.ChunkSize
+	activeWeakHash, activeWeakHashName = weakHashes[metadata.WeakHash], metadata.WeakHash
+	if strongHash, exists := lookupStrongHash(metadata.StrongHash); exists {
+		activeStrongHash, activeStrongHashName = strongHash, metadata.StrongHash
+	}
+}
+
+// SetChunkSize() will set the chunk size (bytes) used when generating Signatures + Deltas.
+// Larger chunks generate Signatures + Deltas of large files faster, at the cost of sending more literal data around each change.
+// Note: a Delta must be generated with the same chunk size as its Signature (see UseSignatureMetadata()).
+// Function returns `nil` when successful (size 0 will restore the default chunk size).
+// Function returns `ChunkSizeOutOfRangeError` when size is negative or larger than rolling.MaxChunkSize (chunk size will be unchanged).
+func SetChunkSize(size int64) error {
+	if size < 0 || size > rolling.MaxChunkSize {
+		return errors.New(constants.ChunkSizeOutOfRangeError)
+	}
+
+	if size == 0 {
+		size = rolling.DefaultChunkSize
+	}
+
+	chunk = size
+	return nil
+}
+
+// UseSignatureMetadata() will select the chunk size, Weak hash + Strong hash recorded within a Signature file, so Deltas are generated with the same settings as the Signature.
+// Note: Signature files without metadata were generated with the default chunk size, and the Weak hash will be unchanged.
+// Note: requested will contain the settings requested by the user (EG `-chunk` + `-weakHash`), with empty fields accepting any recorded setting.
+// Note: the recorded Strong hash will be selected over `-strongHash` (EG while migrating with `-legacyStrongHash`), and will be unchanged when not recorded (EG older Signature files).
+// Function returns `nil` when successful.
+// Function returns `ChunkSizeMismatchError` when the requested chunk size does not match the recorded chunk size (settings will be unchanged).
+// Function returns `WeakHashMismatchError` when the requested Weak hash does not match the recorded Weak hash (settings will be unchanged).
+// Function returns `HashNotRegisteredError` when the recorded Weak (or Strong) hash has not been registered (settings will be unchanged).
+// Function returns `ChunkSizeOutOfRangeError` when the recorded chunk size is invalid (settings will be unchanged).
+func UseSignatureMetadata(metadata models.SignatureMetadata, requested models.SignatureMetadata) error {
+	resolved, err := signatureMetadata(metadata, requested)
+	if err != nil {
+		return err
+	}
+
+	restoreMetadata(resolved)
+	return nil
+}
+
+// signatureMetadata() will resolve the chunk size + Weak hash (+ Strong hash) recorded within a Signature file in the same way as UseSignatureMetadata(), without changing the settings of the whole process.
+// Function returns `metadata, nil` when successful (EG every field set, using the current settings for fields not recorded).
+// Function returns `emptyMetadata, error` in the same cases as UseSignatureMetadata().
+func signatureMetadata(metadata models.SignatureMetadata, requested models.SignatureMetadata) (models.SignatureMetadata, error) {
+	size := metadata.ChunkSize
+	if size == 0 {
+		size = rolling.DefaultChunkSize
+	}
+
+	if requested.ChunkSize != 0 && requested.ChunkSize != size {
+		return models.SignatureMetadata{}, errors.New(constants.ChunkSizeMismatchError)
+	}
+
+	weakName := metadata.WeakHash
+	if weakName == "" {
+		weakName = activeWeakHashName
+	}
+
+	if requested.WeakHash != "" && requested.WeakHash != weakName {
+		return models.SignatureMetadata{}, errors.New(constants.WeakHashMismatchError)
+	}
+
+	if _, exists := weakHashes[weakName]; !exists {
+		return models.SignatureMetadata{}, errors.New(constants.HashNotRegisteredError)
+	}
+
+	strongName := activeStrongHashName
+	if metadata.StrongHash != "" {
+		if _, exists := lookupStrongHash(metadata.StrongHash); !exists {
+			return models.SignatureMetadata{}, errors.New(constants.HashNotRegisteredError)
+		}
+
+		strongName = metadata.StrongHash
+	}
+
+	if size < 0 || size > rolling.MaxChunkSize {
+		return models.SignatureMetadata{}, errors.New(constants.ChunkSizeOutOfRangeError)
+	}
+
+	return models.SignatureMetadata{ChunkSize: size, WeakHash: weakName, StrongHash: strongName}, nil
+}
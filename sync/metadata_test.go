@@ -0,0 +1,154 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/hash/strong"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetChunkSize(t *testing.T) {
+	t.Run("should set chunk size, restoring the default chunk size when size is 0", func(t *testing.T) {
+		// Run + Verify
+		require.Equal(t, nil, SetChunkSize(4096))
+		require.Equal(t, int64(4096), ChunkSize())
+		require.Equal(t, models.SignatureMetadata{ChunkSize: 4096, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash}, Metadata())
+		require.Equal(t, nil, SetChunkSize(0))
+		require.Equal(t, rolling.DefaultChunkSize, ChunkSize())
+	})
+
+	t.Run("should return `ChunkSizeOutOfRangeError` when size is negative or larger than the max chunk size", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.ChunkSizeOutOfRangeError)
+		for _, size := range []int64{-1, rolling.MaxChunkSize + 1} {
+			// Run
+			err := SetChunkSize(size)
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, rolling.DefaultChunkSize, ChunkSize())
+		}
+	})
+}
+
+func TestUseSignatureMetadata(t *testing.T) {
+	t.Run("should use the chunk size + Weak hash recorded in the Signature metadata", func(t *testing.T) {
+		// Setup
+		metadata := models.SignatureMetadata{ChunkSize: 64, WeakHash: Adler32WeakHash, StrongHash: DefaultStrongHash}
+		// Run
+		err := UseSignatureMetadata(metadata, models.SignatureMetadata{ChunkSize: 64})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, metadata, Metadata())
+		require.Equal(t, rolling.NewAdler32().Sum(testBuffer, 64), activeWeakHash.Sum(testBuffer, 64))
+		// Restore, so later tests use the default settings
+		restoreMetadata(models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash})
+	})
+
+	t.Run("should use the default chunk size + keep the Weak hash when Signature has no metadata", func(t *testing.T) {
+		// Setup
+		chunk = 64
+		// Run
+		err := UseSignatureMetadata(models.SignatureMetadata{}, models.SignatureMetadata{WeakHash: DefaultWeakHash})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash}, Metadata())
+	})
+
+	t.Run("should return `ChunkSizeMismatchError` when requested chunk size does not match the Signature metadata", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.ChunkSizeMismatchError)
+		// Run
+		err := UseSignatureMetadata(models.SignatureMetadata{}, models.SignatureMetadata{ChunkSize: 64})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, rolling.DefaultChunkSize, ChunkSize())
+	})
+
+	t.Run("should return `WeakHashMismatchError` when requested Weak hash does not match the Signature metadata", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.WeakHashMismatchError)
+		metadata := models.SignatureMetadata{ChunkSize: 64, WeakHash: Adler32WeakHash}
+		// Run
+		err := UseSignatureMetadata(metadata, models.SignatureMetadata{WeakHash: DefaultWeakHash})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash}, Metadata())
+	})
+
+	t.Run("should return `HashNotRegisteredError` when the recorded Weak hash has not been registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		err := UseSignatureMetadata(models.SignatureMetadata{WeakHash: "unknown"}, models.SignatureMetadata{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, DefaultWeakHash, activeWeakHashName)
+	})
+
+	t.Run("should use the Strong hash recorded in the Signature metadata (EG rdiff Signatures)", func(t *testing.T) {
+		// Setup
+		metadata := models.SignatureMetadata{ChunkSize: 64, WeakHash: RollsumWeakHash, StrongHash: strong.BLAKE2bName}
+		// Run
+		err := UseSignatureMetadata(metadata, models.SignatureMetadata{})
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, metadata, Metadata())
+		require.Equal(t, hex.EncodeToString(strong.BLAKE2b().Sum(testBuffer)), activeStrongHash(testBuffer, 64))
+		// Restore, so later tests use the default settings
+		restoreMetadata(models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash})
+		require.Equal(t, DefaultStrongHash, activeStrongHashName)
+	})
+
+	t.Run("should return `HashNotRegisteredError` when the recorded Strong hash has not been registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		err := UseSignatureMetadata(models.SignatureMetadata{ChunkSize: 64, StrongHash: "unknown"}, models.SignatureMetadata{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash}, Metadata())
+	})
+
+	t.Run("should generate a Delta from an rdiff Signature which reconstructs the Updated file", func(t *testing.T) {
+		// Setup
+		original := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 8)
+		updated := append(append([]byte("Hello! "), original[:100]...), original[120:]...)
+		require.Equal(t, nil, UseHashes(strong.MD4Name, RdiffRabinKarpWeakHash))
+		require.Equal(t, nil, SetChunkSize(16))
+		signature := sparseSignatureOf(t, original, 16)
+		var encoded bytes.Buffer
+		require.Equal(t, nil, files.EncodeRdiffSignature(signature, Metadata(), &encoded))
+		restoreMetadata(models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash})
+		decoded, metadata, err := files.DecodeRdiffSignature(&encoded)
+		require.Equal(t, nil, err)
+		var output bytes.Buffer
+		// Run
+		err = UseSignatureMetadata(metadata, models.SignatureMetadata{})
+		delta, deltaErr := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), decoded, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, deltaErr)
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &output))
+		require.Equal(t, updated, output.Bytes())
+		// Restore, so later tests use the default settings
+		restoreMetadata(models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash})
+	})
+
+	t.Run("should return `ChunkSizeOutOfRangeError` when Signature metadata is invalid", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.ChunkSizeOutOfRangeError)
+		// Run
+		err := UseSignatureMetadata(models.SignatureMetadata{ChunkSize: -1, WeakHash: Adler32WeakHash}, models.SignatureMetadata{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.SignatureMetadata{ChunkSize: rolling.DefaultChunkSize, WeakHash: DefaultWeakHash, StrongHash: DefaultStrongHash}, Metadata())
+	})
+}
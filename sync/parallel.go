@@ -0,0 +1,171 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+var (
+	signatureSegmentSize int64 = 64 * 1024 // Bytes of the Original file hashed by a Signature worker at a time
+	signatureWorkers           = 1         // Goroutines hashing segments of the Original file when generating Signatures (see SetSignatureWorkers())
+)
+
+// signatureEntry type.
+// This will contain the Weak hash + block of a chunk of the Original file, as generated by a Signature worker.
+type signatureEntry struct {
+	weakHash int64
+	item     models.StrongSignature
+}
+
+// signatureSegment type.
+// This will contain a segment of the Original file (starting at head), along with the channel its entries are returned on once hashed.
+// Note: data will overlap the next segment by chunk size - 1 bytes, so every offset of the Original file is hashed by exactly one segment.
+type signatureSegment struct {
+	data   []byte
+	head   int64
+	result chan []signatureEntry
+}
+
+// generateSegmentedSignature() will generate a Signature in the same way as generateSignature(), hashing segments of the Original file in parallel across the provided number of workers.
+// Segments will be read from the Original file in order, and their entries passed to the writer in order of the Original file once hashed, so the writer receives the same entries as generateSignature().
+// Note: the number of segments read ahead will be bounded by the number of workers, so memory will not grow with the size of the Original file.
+// Function returns `nil` when all blocks have been passed to the writer (no blocks passed when Original file is empty).
+// Function will return `error` when unable to read from file, or when the writer is unable to write a block.
+func generateSegmentedSignature(reader Reader, writer SignatureEntryWriter, s settings, workers int, verbose bool) error {
+	size := s.chunk
+	buffer, err := initialiseBuffer(reader, size)
+	if err != nil {
+		// Empty Original file will produce an empty Signature
+		if err.Error() == constants.EndOfFileError {
+			return nil
+		}
+
+		return err
+	}
+
+	// Original file smaller than a chunk will produce a single block (see generateSignature())
+	if int64(len(buffer)) < size {
+		return writer.WriteEntry(s.weakHash.Sum(buffer, size), models.StrongSignature{Hash: s.strongHash(buffer, size), LegacyHash: s.legacy(buffer), Head: 0, Tail: size - 1})
+	}
+
+	logger(fmt.Sprintf("Signature workers: %d\n", workers), verbose)
+	segments := make(chan signatureSegment)
+	results := make(chan chan []signatureEntry, workers)
+	done := make(chan struct{})
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			for segment := range segments {
+				segment.result <- hashSegment(segment.data, segment.head, s)
+			}
+		}()
+	}
+
+	// Read segments on a separate goroutine, so entries can be written while later segments are hashed
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(results)
+		defer close(segments)
+		readErr <- readSegments(reader, buffer, size, segments, results, done)
+	}()
+
+	// Write entries in order of the Original file, draining remaining segments after a write error
+	for result := range results {
+		entries := <-result
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if err = writer.WriteEntry(entry.weakHash, entry.item); err != nil {
+				close(done)
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return <-readErr
+}
+
+// hashSegment() will generate the Weak + Strong hash of every block starting within a segment of the Original file (EG every offset with a full chunk of data following it).
+// Function returns `entries` in order of the Original file.
+func hashSegment(data []byte, head int64, s settings) []signatureEntry {
+	size := s.chunk
+	count := int64(len(data)) - size + 1
+	entries := make([]signatureEntry, 0, count)
+	weakHash := s.weakHash.Sum(data[:size], size)
+	for offset := int64(0); offset < count; offset++ {
+		if offset > 0 {
+			weakHash = s.weakHash.Roll(weakHash, data[offset-1], data[offset+size-1], size)
+		}
+
+		buffer := data[offset : offset+size]
+		entries = append(entries, signatureEntry{weakHash: weakHash, item: models.StrongSignature{Hash: s.strongHash(buffer, size), LegacyHash: s.legacy(buffer), Head: head + offset, Tail: head + offset + size - 1}})
+	}
+
+	return entries
+}
+
+// readSegments() will read the Original file in segments (starting with the provided initial chunk), passing each to a Signature worker + queuing its result to be written in order.
+// Reading will stop early (without error) once done is closed (EG the writer failed).
+// Function returns `nil` when the full Original file has been read.
+// Function returns `error` when unable to read from file.
+func readSegments(reader Reader, data []byte, size int64, segments chan<- signatureSegment, results chan<- chan []signatureEntry, done <-chan struct{}) error {
+	head := int64(0)
+	for {
+		next, err := initialiseBuffer(reader, signatureSegmentSize)
+		if err != nil && err.Error() != constants.EndOfFileError {
+			return err
+		}
+
+		data = append(data, next...)
+		if int64(len(data)) >= size {
+			// Pass segment to a worker before queuing its result, so every queued result will be returned
+			segment := signatureSegment{data: data, head: head, result: make(chan []signatureEntry, 1)}
+			select {
+			case segments <- segment:
+			case <-done:
+				return nil
+			}
+
+			select {
+			case results <- segment.result:
+			case <-done:
+				return nil
+			}
+		}
+
+		if err != nil {
+			return nil
+		}
+
+		// Start the next segment with the last chunk size - 1 bytes, which begin blocks not yet hashed
+		head += int64(len(data)) - size + 1
+		data = append(make([]byte, 0, size-1+signatureSegmentSize), data[int64(len(data))-size+1:]...)
+	}
+}
+
+// SetSignatureWorkers() will set the number of goroutines hashing segments of the Original file when generating Signatures (see generateSegmentedSignature()).
+// Note: 0 will use a worker per CPU core, and 1 will generate Signatures on the calling goroutine.
+// Note: Strong + Weak hashes registered with RegisterStrongHash() + RegisterWeakHash() must be safe for concurrent use when using more than 1 worker.
+// Function returns `nil` when successful.
+// Function returns `InvalidSignatureWorkersError` when workers is negative (workers will be unchanged).
+func SetSignatureWorkers(workers int) error {
+	if workers < 0 {
+		return errors.New(constants.InvalidSignatureWorkersError)
+	}
+
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+
+	signatureWorkers = workers
+	return nil
+}
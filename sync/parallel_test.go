@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for SignatureEntryWriter interface, which records every entry
+type signatureEntriesMock struct {
+	entries []signatureEntry
+}
+
+// Overwrite signatureEntriesMock.WriteEntry() to record entry
+func (w *signatureEntriesMock) WriteEntry(weakHash int64, item models.StrongSignature) error {
+	w.entries = append(w.entries, signatureEntry{weakHash: weakHash, item: item})
+	return nil
+}
+
+// signatureEntriesOf() will generate the Signature entries of the provided data with the provided number of workers.
+func signatureEntriesOf(t *testing.T, data []byte, workers int) []signatureEntry {
+	writer := &signatureEntriesMock{}
+	require.Equal(t, nil, SetSignatureWorkers(workers))
+	err := GenerateSignatureTo(bufio.NewReader(bytes.NewReader(data)), writer, false)
+	require.Equal(t, nil, SetSignatureWorkers(1))
+	require.Equal(t, nil, err)
+	return writer.entries
+}
+
+func TestGenerateSegmentedSignature(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(data)
+	// Mock
+	initialiseBuffer = populateBuffer
+	rollBuffer = roll
+	signatureSegmentSize = 64
+
+	t.Run("should write the same entries as a single worker, in order of the Original file", func(t *testing.T) {
+		for _, weakHash := range WeakHashes() {
+			require.Equal(t, nil, UseHashes("", weakHash))
+			for _, size := range []int64{0, 5, chunk, signatureSegmentSize, signatureSegmentSize + chunk - 1, signatureSegmentSize + chunk, int64(len(data))} {
+				// Run
+				expected := signatureEntriesOf(t, data[:size], 1)
+				entries := signatureEntriesOf(t, data[:size], 4)
+				// Verify
+				require.Equal(t, expected, entries, "weak hash %s, %d bytes", weakHash, size)
+			}
+		}
+
+		require.Equal(t, nil, UseHashes("", ""))
+	})
+
+	t.Run("should write the same entries as a single worker when segments are smaller than a chunk", func(t *testing.T) {
+		// Setup
+		signatureSegmentSize = 5
+		// Run
+		expected := signatureEntriesOf(t, data, 1)
+		entries := signatureEntriesOf(t, data, 3)
+		// Verify
+		signatureSegmentSize = 64
+		require.Equal(t, expected, entries)
+	})
+
+	t.Run("should write the same entries as a single worker when chunk size is 1", func(t *testing.T) {
+		// Setup
+		require.Equal(t, nil, SetChunkSize(1))
+		// Run
+		expected := signatureEntriesOf(t, data, 1)
+		entries := signatureEntriesOf(t, data, 3)
+		// Verify
+		require.Equal(t, nil, SetChunkSize(0))
+		require.Equal(t, expected, entries)
+	})
+
+	t.Run("should return `error` when the writer is unable to write an entry", func(t *testing.T) {
+		// Setup
+		writer := &signatureWriterMock{mockError: errors.New("Some Error")}
+		// Run
+		err := generateSegmentedSignature(bufio.NewReader(bytes.NewReader(data)), writer, activeSettings(), 4, false)
+		// Verify
+		require.Equal(t, errors.New("Some Error"), err)
+	})
+
+	t.Run("should return `error` when unable to read from file", func(t *testing.T) {
+		// Setup
+		writer := &signatureWriterMock{}
+		reader := bufio.NewReader(io.MultiReader(bytes.NewReader(data), iotestErrReader{}))
+		// Run
+		err := generateSegmentedSignature(reader, writer, activeSettings(), 4, false)
+		// Verify
+		require.Equal(t, errors.New("Some Error"), err)
+	})
+
+	signatureSegmentSize = 64 * 1024
+}
+
+// Mock for io.Reader interface, which fails every read
+type iotestErrReader struct{}
+
+// Overwrite iotestErrReader.Read() to return an error
+func (r iotestErrReader) Read(p []byte) (int, error) {
+	return 0, errors.New("Some Error")
+}
+
+func TestSetSignatureWorkers(t *testing.T) {
+	t.Run("should return `nil` and set Signature workers, using a worker per CPU core when 0", func(t *testing.T) {
+		// Run
+		err := SetSignatureWorkers(0)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, runtime.NumCPU(), signatureWorkers)
+		require.Equal(t, nil, SetSignatureWorkers(1))
+		require.Equal(t, 1, signatureWorkers)
+	})
+
+	t.Run("should return `InvalidSignatureWorkersError` when Signature workers is negative", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.InvalidSignatureWorkersError)
+		// Run
+		err := SetSignatureWorkers(-1)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, signatureWorkers)
+	})
+}
@@ -0,0 +1,163 @@
+//go:build !(js && wasm)
+
+package sync
+
+import (
+	"bufio"
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Path-based functions open + decode files with the files package, so are excluded from WebAssembly builds (keeping the core of sync free of file access).
+var (
+	openFile              = files.OpenFile
+	openSignatureMetadata = files.OpenSignatureMetadata
+)
+
+// Option type.
+// This will configure the path-based functions (see SignatureFromPath() + DeltaFromPaths()).
+// EG: sync.SignatureFromPath("original.txt", sync.WithVerbose(true)).
+type Option func(*options)
+
+// options type.
+// This will contain the settings applied by each Option.
+type options struct {
+	verbose  bool
+	metadata models.SignatureMetadata
+}
+
+// applyOptions() will apply the provided Options to the default settings.
+func applyOptions(opts []Option) options {
+	config := options{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
+// DeltaFromPaths() will decode a Signature file, and generate a Delta of how to update the Original file it describes to match the Updated file.
+// Function returns `delta, nil` when successful.
+// Note: Delta will be generated with the chunk size + Weak hash (+ Strong hash of rdiff Signatures) recorded in the Signature file, without changing the settings of the whole process.
+// Function returns `emptyDelta, error` when unable to open Signature file (see files.OpenSignatureMetadata()).
+// Function returns `emptyDelta, ChunkSizeMismatchError` when WithChunkSize() is set and does not match the chunk size recorded in the Signature file.
+// Function returns `emptyDelta, WeakHashMismatchError` when WithWeakHash() is set and does not match the Weak hash recorded in the Signature file.
+// Function returns `emptyDelta, UpdatedFileDoesNotExistError` when unable to find Updated file.
+// Function returns `emptyDelta, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when Delta generation finds no changes in Updated file.
+// Function returns `emptyDelta, UnableToGenerateDeltaError` when unable to generate Delta.
+func DeltaFromPaths(signaturePath string, updatedPath string, opts ...Option) (models.Delta, error) {
+	config := applyOptions(opts)
+	signature, metadata, err := openSignatureMetadata(signaturePath, config.verbose)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	if metadata, err = signatureMetadata(metadata, config.metadata); err != nil {
+		return models.Delta{}, err
+	}
+
+	s, err := activeSettings().with(metadata)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	reader, err := OpenUpdated(updatedPath)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	delta, err := generateDeltaModel(reader, signature, Hooks{}, s, config.verbose)
+	if err != nil {
+		if err.Error() == constants.UpdatedFileHasNoChangesError {
+			return models.Delta{}, err
+		}
+
+		return models.Delta{}, errors.New(constants.UnableToGenerateDeltaError)
+	}
+
+	return delta, nil
+}
+
+// OpenOriginal() will create a FileReader for the Original file.
+// Function returns `reader, nil` when successful.
+// Function returns `nil, OriginalFileDoesNotExistError` when Original file cannot be found.
+// Function returns `nil, OriginalFileIsFolderError` when found a folder dir instead of Original file.
+// Function returns `nil, error` when unable to open Original file.
+func OpenOriginal(path string) (*bufio.Reader, error) {
+	reader, err := openFile(path)
+	if err != nil {
+		return nil, OriginalFileError(err)
+	}
+
+	return reader, nil
+}
+
+// OpenUpdated() will create a FileReader for the Updated file.
+// Function returns `reader, nil` when successful.
+// Function returns `nil, UpdatedFileDoesNotExistError` when Updated file cannot be found.
+// Function returns `nil, UpdatedFileIsFolderError` when found a folder dir instead of Updated file.
+// Function returns `nil, error` when unable to open Updated file.
+func OpenUpdated(path string) (*bufio.Reader, error) {
+	reader, err := openFile(path)
+	if err != nil {
+		return nil, UpdatedFileError(err)
+	}
+
+	return reader, nil
+}
+
+// SignatureFromPath() will generate a Signature of the Original file.
+// Note: Signature will be generated with the chunk size + Weak hash set by WithChunkSize() + WithWeakHash() (when set), without changing the settings of the whole process.
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, ChunkSizeOutOfRangeError` when WithChunkSize() is out of range.
+// Function returns `emptySignature, HashNotRegisteredError` when WithWeakHash() has not been registered.
+// Function returns `emptySignature, OriginalFileDoesNotExistError` when unable to find Original file.
+// Function returns `emptySignature, OriginalFileIsFolderError` when found a folder dir instead of Original file.
+// Function returns `emptySignature, UnableToGenerateSignatureError` when unable to generate Signature.
+func SignatureFromPath(path string, opts ...Option) (models.Signature, error) {
+	config := applyOptions(opts)
+	s, err := activeSettings().with(config.metadata)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	reader, err := OpenOriginal(path)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	signature, err := generateSignatureModel(reader, s, config.verbose)
+	if err != nil {
+		return models.Signature{}, errors.New(constants.UnableToGenerateSignatureError)
+	}
+
+	return signature, nil
+}
+
+// WithChunkSize() will set the chunk size (bytes) of Signatures generated by SignatureFromPath() (see SetChunkSize()).
+// DeltaFromPaths() will verify the chunk size matches the chunk size recorded in the Signature file.
+// Note: Signature files should record the chunk size (EG files.WriteSignatureToFile()), otherwise the default chunk size is assumed.
+func WithChunkSize(size int64) Option {
+	return func(config *options) {
+		config.metadata.ChunkSize = size
+	}
+}
+
+// WithWeakHash() will set the Weak hash algorithm of Signatures generated by SignatureFromPath() (EG sync.Adler32WeakHash, see UseHashes()).
+// DeltaFromPaths() will verify the Weak hash matches the Weak hash recorded in the Signature file.
+func WithWeakHash(name string) Option {
+	return func(config *options) {
+		config.metadata.WeakHash = name
+	}
+}
+
+// WithVerbose() will enable extended logging while generating Signatures + Deltas.
+func WithVerbose(verbose bool) Option {
+	return func(config *options) {
+		config.verbose = verbose
+	}
+}
@@ -0,0 +1,178 @@
+//go:build !(js && wasm)
+
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/files"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTempFile() will write contents to a file within a temporary folder, returning the path of the file.
+func writeTempFile(t *testing.T, name string, contents []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Equal(t, nil, os.WriteFile(path, contents, 0o644))
+	return path
+}
+
+func TestSignatureFromPath(t *testing.T) {
+	t.Run("should return `signature, nil` when Original file exists", func(t *testing.T) {
+		// Setup
+		original := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+		path := writeTempFile(t, "original.txt", original)
+		expectedSignature := signatureOf(t, original)
+		// Run
+		signature, err := SignatureFromPath(path, WithVerbose(false))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedSignature, signature)
+	})
+
+	t.Run("should return `signature, nil` with blocks of the chunk size set by WithChunkSize()", func(t *testing.T) {
+		// Setup
+		path := writeTempFile(t, "original.txt", []byte("abcdefghijklmnopqrstuvwxyz0123456789"))
+		// Run
+		signature, err := SignatureFromPath(path, WithChunkSize(32))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 5, len(signature))
+		for _, item := range signature {
+			require.Equal(t, int64(31), item.Tail-item.Head)
+		}
+
+		// Default chunk size is restored once complete
+		require.Equal(t, rolling.DefaultChunkSize, chunk)
+	})
+
+	t.Run("should return `signature, nil` indexed by the Weak hash set by WithWeakHash()", func(t *testing.T) {
+		// Setup
+		original := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+		path := writeTempFile(t, "original.txt", original)
+		// Run
+		signature, err := SignatureFromPath(path, WithWeakHash(Adler32WeakHash))
+		// Verify
+		require.Equal(t, nil, err)
+		_, exists := signature[rolling.NewAdler32().Sum(original[:testChunk], testChunk)]
+		require.Equal(t, true, exists)
+		// Default Weak hash is restored once complete
+		require.Equal(t, DefaultWeakHash, activeWeakHashName)
+	})
+
+	t.Run("should return `emptySignature, HashNotRegisteredError` when WithWeakHash() has not been registered", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.HashNotRegisteredError)
+		// Run
+		signature, err := SignatureFromPath("original.txt", WithWeakHash("unknown"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+
+	t.Run("should return `emptySignature, OriginalFileDoesNotExistError` when Original file does not exist", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.OriginalFileDoesNotExistError)
+		// Run
+		signature, err := SignatureFromPath(filepath.Join(t.TempDir(), "missing.txt"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+
+	t.Run("should return `emptySignature, OriginalFileIsFolderError` when Original file is a folder", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.OriginalFileIsFolderError)
+		// Run
+		signature, err := SignatureFromPath(t.TempDir())
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+}
+
+func TestDeltaFromPaths(t *testing.T) {
+	original := []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+	signature := signatureOf(t, original)
+	// Mock
+	openSignatureMetadata = func(fileName string, verbose bool) (models.Signature, models.SignatureMetadata, error) {
+		if fileName == "missing.sig" {
+			return models.Signature{}, models.SignatureMetadata{}, errors.New(constants.SignatureFileDoesNotExistError)
+		}
+
+		return signature, models.SignatureMetadata{}, nil
+	}
+
+	t.Run("should return `delta, nil` when Updated file changes Original file", func(t *testing.T) {
+		// Setup
+		updated := []byte("abcdefghijklmnopXXqrstuvwxyz0123456789")
+		path := writeTempFile(t, "updated.txt", updated)
+		// Run
+		delta, err := DeltaFromPaths("original.sig", path)
+		// Verify
+		require.Equal(t, nil, err)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when Updated file matches Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		path := writeTempFile(t, "updated.txt", original)
+		// Run
+		delta, err := DeltaFromPaths("original.sig", path)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileDoesNotExistError` when Updated file does not exist", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UpdatedFileDoesNotExistError)
+		// Run
+		delta, err := DeltaFromPaths("original.sig", filepath.Join(t.TempDir(), "missing.txt"))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, ChunkSizeMismatchError` when WithChunkSize() does not match the Signature file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.ChunkSizeMismatchError)
+		// Run
+		delta, err := DeltaFromPaths("original.sig", "updated.txt", WithChunkSize(32))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, WeakHashMismatchError` when WithWeakHash() does not match the Signature file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.WeakHashMismatchError)
+		// Run
+		delta, err := DeltaFromPaths("original.sig", "updated.txt", WithWeakHash(Adler32WeakHash))
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, error` when unable to open Signature file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.SignatureFileDoesNotExistError)
+		// Run
+		delta, err := DeltaFromPaths("missing.sig", "updated.txt")
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	// Restore, so later tests decode real Signature files
+	openSignatureMetadata = files.OpenSignatureMetadata
+}
@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// PruneSignature() will reduce a Signature to the blocks starting at every Nth offset (stride) of the Original file, for receivers with limited memory.
+// Accuracy trade-off:
+// A stride up to the chunk size (EG 16 keeps only chunk-aligned blocks) will still match all unchanged data, only sending a few extra bytes around each change as literal data.
+// A stride larger than the chunk size will leave gaps between blocks which can never be matched, so gaps will be sent as literal data (EG approximate sync).
+// The final block will always be kept, so the size of the Original file can still be derived from the Signature.
+// Note: Signature will be returned unchanged when stride <= 1.
+func PruneSignature(signature models.Signature, stride int64) models.Signature {
+	if stride <= 1 {
+		return signature
+	}
+
+	last := originalSize(signature) - chunk
+	weaks := signatureWeaks(signature)
+	blocks := []models.StrongSignature{}
+	for _, block := range signatureBlocks(signature) {
+		if block.Head%stride == 0 || block.Head >= last {
+			blocks = append(blocks, block)
+		}
+	}
+
+	// Re-add blocks in order of position, so candidates are kept in the same order as GenerateSignature()
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Head < blocks[j].Head
+	})
+
+	pruned := make(models.Signature, len(blocks))
+	for _, block := range blocks {
+		addSignatureItem(pruned, weaks[block.Head], block)
+	}
+
+	return pruned
+}
+
+// SignatureStride() will return the smallest stride which keeps a pruned Signature within maxEntries blocks, plus the final block (see PruneSignature()).
+// Note: stride will be 1 (EG no pruning) when maxEntries <= 0, or the Signature is already within the limit.
+func SignatureStride(signature models.Signature, maxEntries int64) int64 {
+	// Signature contains a block at every offset up to the final block (EG Original file size - chunk + 1 blocks)
+	offsets := originalSize(signature) - chunk + 1
+	if maxEntries <= 0 || offsets <= maxEntries {
+		return 1
+	}
+
+	return (offsets + maxEntries - 1) / maxEntries
+}
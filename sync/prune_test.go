@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneSignature(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun")
+	t.Run("should keep only blocks at every Nth offset, plus the final block", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, data)
+		// Run
+		pruned := PruneSignature(signature, chunk)
+		// Verify
+		heads := []int64{}
+		for _, block := range signatureBlocks(pruned) {
+			heads = append(heads, block.Head)
+		}
+
+		require.ElementsMatch(t, []int64{0, 16, 32, 48, 59}, heads)
+		require.Equal(t, originalSize(signature), originalSize(pruned))
+	})
+
+	t.Run("should still match unchanged data when stride is the chunk size", func(t *testing.T) {
+		// Setup
+		pruned := PruneSignature(signatureOf(t, data), chunk)
+		updated := append([]byte("!"), data...)
+		// Run
+		delta, err := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), pruned, false)
+		// Verify
+		require.Equal(t, nil, err)
+		matched, literal := Stats(delta)
+		require.Equal(t, int64(len(data)), matched)
+		require.Equal(t, int64(1), literal)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(data), delta, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return Signature unchanged when stride <= 1", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, data)
+		// Run + Verify
+		require.Equal(t, signature, PruneSignature(signature, 1))
+		require.Equal(t, signature, PruneSignature(signature, 0))
+	})
+
+	t.Run("should return empty Signature when Signature is empty", func(t *testing.T) {
+		require.Equal(t, models.Signature{}, PruneSignature(models.Signature{}, chunk))
+	})
+}
+
+func TestSignatureStride(t *testing.T) {
+	// Signature of a 75 byte file contains 60 blocks
+	signature := signatureOf(t, []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun"))
+	t.Run("should return smallest stride keeping Signature within max entries", func(t *testing.T) {
+		require.Equal(t, int64(2), SignatureStride(signature, 30))
+		require.Equal(t, int64(3), SignatureStride(signature, 29))
+		require.Equal(t, int64(60), SignatureStride(signature, 1))
+	})
+
+	t.Run("should return `1` when max entries disabled or Signature within limit", func(t *testing.T) {
+		require.Equal(t, int64(1), SignatureStride(signature, 0))
+		require.Equal(t, int64(1), SignatureStride(signature, 60))
+	})
+}
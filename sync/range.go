@@ -0,0 +1,37 @@
+package sync
+
+import (
+	"errors"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// RangeDelta() will build a Delta for an Updated file where only the region [start, end) has changed, from a Delta generated for that region alone.
+// Bytes before the region will be copied from the same position of the Original file.
+// Bytes after the region will be copied from the end of the Original file, so the region may have grown or shrunk.
+// EG: an Updated file where only an embedded resource section can change.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, RangeOutsideFilesError` when the region is outside the Updated file, or the bytes outside the region are not present in the Original file.
+func RangeDelta(region models.Delta, signature models.Signature, start int64, end int64, updatedSize int64) (models.Delta, error) {
+	size := originalSize(signature)
+	suffix := updatedSize - end
+	if start < 0 || end <= start || end > updatedSize || start+suffix > size {
+		return models.Delta{}, errors.New(constants.RangeOutsideFilesError)
+	}
+
+	delta := models.Delta{}
+	if start > 0 {
+		delta[0] = models.Block{Head: 0, Tail: start - 1, IsModified: false, Value: []byte{}}
+	}
+
+	for position, block := range region {
+		delta[start+position] = block
+	}
+
+	if suffix > 0 {
+		delta[end] = models.Block{Head: size - suffix, Tail: size - 1, IsModified: false, Value: []byte{}}
+	}
+
+	return delta, nil
+}
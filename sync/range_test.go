@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeDelta(t *testing.T) {
+	// Original file is 32 bytes
+	signature := models.Signature{
+		1: models.StrongSignature{Hash: "a", Head: 0, Tail: 15},
+		2: models.StrongSignature{Hash: "b", Head: 16, Tail: 31},
+	}
+
+	t.Run("should copy bytes before + after region from Original file", func(t *testing.T) {
+		// Setup
+		region := models.Delta{
+			0: models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("abcd")},
+			4: models.Block{Head: 12, Tail: 15, IsModified: false, Value: []byte{}},
+		}
+
+		expectedDelta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 7, IsModified: false, Value: []byte{}},
+			8:  models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("abcd")},
+			12: models.Block{Head: 12, Tail: 15, IsModified: false, Value: []byte{}},
+			16: models.Block{Head: 16, Tail: 31, IsModified: false, Value: []byte{}},
+		}
+
+		// Run
+		delta, err := RangeDelta(region, signature, 8, 16, 32)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+		require.Equal(t, int64(32), OutputSize(delta))
+	})
+
+	t.Run("should copy bytes after region from end of Original file when region has grown", func(t *testing.T) {
+		// Setup
+		region := models.Delta{0: models.Block{Head: 0, Tail: 11, IsModified: true, Value: []byte("abcdefghijkl")}}
+		expectedDelta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 7, IsModified: false, Value: []byte{}},
+			8:  models.Block{Head: 0, Tail: 11, IsModified: true, Value: []byte("abcdefghijkl")},
+			20: models.Block{Head: 16, Tail: 31, IsModified: false, Value: []byte{}},
+		}
+
+		// Run
+		delta, err := RangeDelta(region, signature, 8, 20, 36)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should not add copy blocks when region covers the Updated file", func(t *testing.T) {
+		// Setup
+		region := models.Delta{0: models.Block{Head: 0, Tail: 3, IsModified: true, Value: []byte("abcd")}}
+		// Run
+		delta, err := RangeDelta(region, signature, 0, 4, 4)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, region, delta)
+	})
+
+	t.Run("should return `emptyDelta, RangeOutsideFilesError` when region ends after Updated file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.RangeOutsideFilesError)
+		// Run
+		delta, err := RangeDelta(models.Delta{}, signature, 8, 40, 32)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
+	t.Run("should return `emptyDelta, RangeOutsideFilesError` when bytes outside region are not present in Original file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.RangeOutsideFilesError)
+		// Run
+		delta, err := RangeDelta(models.Delta{}, signature, 24, 28, 48)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+}
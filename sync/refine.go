@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// Approximate encoded size of a Delta block (EG gob Head + Tail + flags), so literal data is only split when the matched blocks outweigh the added blocks
+var blockOverhead int64 = 16
+
+// RefineDelta() will re-scan each literal block of a Delta against a full Signature of the mismatched region of the Original file, recovering matches missed by a coarse first pass.
+// The mismatched region of a literal block will be the Original file bytes between its neighbouring matched blocks (or the start / end of the Original file).
+// This allows a two-pass Delta: a fast coarse pass (EG a sparse Signature, see GenerateSparseSignature()) to localise changes, then a fine pass only inside changed regions.
+// Note: requires local access to the Original file, and literal blocks whose neighbouring matched blocks are out of order (EG moved data) will be kept unchanged.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the refined Delta copies the Original file (described by the Signature) unchanged.
+// Function returns `emptyDelta, InvalidDeltaError` when Delta blocks contain gaps or overlap (EG corrupt Delta).
+// Function returns `emptyDelta, UnableToReadOriginalFileError` when unable to read from the Original file.
+func RefineDelta(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+	return RefineDeltaWithChunk(original, delta, signature, chunk)
+}
+
+// RefineDeltaWithChunk() will re-scan each literal block of a Delta in the same way as RefineDelta(), using a finer-grained Signature (smaller chunk size) of each mismatched region.
+// A smaller chunk size recovers shorter matches within changed regions (EG files with many small edits), at the cost of more (shorter) matched blocks.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, InvalidChunkSizeError` when chunk size is not between 1 and the current chunk size (see SetChunkSize()).
+// Function returns `emptyDelta, error` in the same cases as RefineDelta().
+func RefineDeltaWithChunk(original io.ReaderAt, delta models.Delta, signature models.Signature, chunkSize int64) (models.Delta, error) {
+	if chunkSize < 1 || chunkSize > chunk {
+		return models.Delta{}, errors.New(constants.InvalidChunkSizeError)
+	}
+
+	ops, err := deltaOps(delta)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	size := originalSize(signature)
+	refined := []models.Op{}
+	for index, op := range ops {
+		if op.Kind != models.OpLiteral {
+			refined = append(refined, op)
+			continue
+		}
+
+		// Find the mismatched region of the Original file
+		start, end := int64(0), size
+		if index > 0 && ops[index-1].Kind == models.OpCopy {
+			start = ops[index-1].Tail + 1
+		}
+
+		if index+1 < len(ops) && ops[index+1].Kind == models.OpCopy {
+			end = ops[index+1].Head
+		}
+
+		regionOps, err := refineLiteral(original, start, end, op.Value, chunkSize)
+		if err != nil {
+			return models.Delta{}, err
+		}
+
+		refined = append(refined, regionOps...)
+	}
+
+	return verifyDeltaHasChanges(mergeOps(refined), signature)
+}
+
+// refineLiteral() will generate the operations reconstructing a literal block from the region [start, end) of the Original file, using the provided chunk size.
+// Function returns `ops, nil` when successful (EG a single literal operation when the region or literal block is smaller than a chunk, or the matched blocks would not shrink the Delta).
+// Function returns `nil, UnableToReadOriginalFileError` when unable to read the region from the Original file.
+// Function returns `nil, error` when unable to generate the Signature or Delta of the region.
+func refineLiteral(original io.ReaderAt, start int64, end int64, value []byte, chunkSize int64) ([]models.Op, error) {
+	literal := []models.Op{{Kind: models.OpLiteral, Value: value}}
+	if end-start < chunkSize || int64(len(value)) < chunkSize {
+		return literal, nil
+	}
+
+	region := make([]byte, end-start)
+	if read, _ := original.ReadAt(region, start); read < len(region) {
+		return nil, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	// Generate the Signature + Delta of the region with the finer chunk size
+	s := activeSettings()
+	s.chunk = chunkSize
+	signature, err := generateSignatureModel(bufio.NewReader(bytes.NewReader(region)), s, false)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := make(models.Delta)
+	if err = generateDelta(bufio.NewReader(bytes.NewReader(value)), signature, &deltaBuilder{writer: delta}, s, false); err != nil {
+		return nil, err
+	}
+
+	ops := []models.Op{}
+	delta.Ops(func(op models.Op) error {
+		// Region Signature positions are relative to the start of the region
+		if op.Kind == models.OpCopy {
+			op.Head += start
+			op.Tail += start
+		}
+
+		ops = append(ops, op)
+		return nil
+	})
+
+	if encodedSize(ops) >= encodedSize(literal) {
+		return literal, nil
+	}
+
+	return ops, nil
+}
+
+// encodedSize() will estimate the size of the provided operations within a Delta file (literal data plus the overhead of each block).
+func encodedSize(ops []models.Op) int64 {
+	size := int64(0)
+	for _, op := range ops {
+		size += int64(len(op.Value)) + blockOverhead
+	}
+
+	return size
+}
@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefineDelta(t *testing.T) {
+	original := []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun")
+	t.Run("should return `delta, nil` recovering matches within literal blocks from the mismatched region of the Original file", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		updated := append(append(append([]byte{}, original[:40]...), []byte("!!")...), original[40:]...)
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+			16: models.Block{Head: 16, Tail: 76, IsModified: true, Value: updated[16:]},
+		}
+
+		// Run
+		refined, err := RefineDelta(bytes.NewReader(original), delta, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		matched, literal := Stats(refined)
+		require.Equal(t, int64(len(original)), matched)
+		require.Equal(t, int64(2), literal)
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), refined, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return `delta, nil` unchanged when literal blocks are smaller than a chunk", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 29, IsModified: false, Value: []byte{}},
+			30: models.Block{Head: 30, Tail: 31, IsModified: true, Value: []byte("!!")},
+			32: models.Block{Head: 30, Tail: 74, IsModified: false, Value: []byte{}},
+		}
+
+		// Run
+		refined, err := RefineDelta(bytes.NewReader(original), delta, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, refined)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when refined Delta copies Original file unchanged", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 74, IsModified: true, Value: original}}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Run
+		refined, err := RefineDelta(bytes.NewReader(original), delta, signature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, refined)
+	})
+
+	t.Run("should return `emptyDelta, UnableToReadOriginalFileError` when unable to read from Original file", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 74, IsModified: true, Value: original}}
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Run
+		refined, err := RefineDelta(failingReaderAtMock{}, delta, signature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, refined)
+	})
+}
+
+func TestRefineDeltaWithChunk(t *testing.T) {
+	original := []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun")
+	// Change every 10th byte, so no unchanged run is as long as the default chunk size
+	updated := append([]byte{}, original...)
+	for index := 5; index < len(updated); index += 10 {
+		updated[index] = '#'
+	}
+
+	delta := models.Delta{0: models.Block{Head: 0, Tail: 74, IsModified: true, Value: updated}}
+	t.Run("should return `delta, nil` recovering short matches within literal blocks when chunk size is smaller than the default", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		// Mock
+		blockOverhead = 0
+		// Run
+		coarse, err := RefineDelta(bytes.NewReader(original), delta, signature)
+		require.Equal(t, nil, err)
+		fine, err := RefineDeltaWithChunk(bytes.NewReader(original), delta, signature, 4)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, coarse)
+		matched, _ := Stats(fine)
+		require.Greater(t, matched, int64(0))
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), fine, &out))
+		require.Equal(t, updated, out.Bytes())
+		// Default chunk size is restored after the re-scan
+		require.Equal(t, rolling.DefaultChunkSize, chunk)
+		// Restore, so later tests only split literal data when it shrinks the Delta
+		blockOverhead = 16
+	})
+
+	t.Run("should return `delta, nil` unchanged when short matches would not outweigh the overhead of the added blocks", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		// Run
+		refined, err := RefineDeltaWithChunk(bytes.NewReader(original), delta, signature, 4)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, refined)
+	})
+
+	t.Run("should return `emptyDelta, InvalidChunkSizeError` when chunk size is not between 1 and the current chunk size", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 64)
+		expectedError := errors.New(constants.InvalidChunkSizeError)
+		for _, chunkSize := range []int64{0, rolling.DefaultChunkSize + 1} {
+			// Run
+			refined, err := RefineDeltaWithChunk(bytes.NewReader(original), delta, signature, chunkSize)
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, models.Delta{}, refined)
+		}
+	})
+}
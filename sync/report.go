@@ -0,0 +1,41 @@
+package sync
+
+import (
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+const (
+	BlockVerified   string = "verified"   // Every Signature block within the copied range matched its Strong hash
+	BlockUnverified string = "unverified" // No Signature blocks within the copied range (EG no Signature, or copy smaller than a chunk)
+	BlockMismatch   string = "mismatch"   // A Signature block within the copied range did not match (EG Original file has drifted)
+	BlockLiteral    string = "literal"    // Data written from the Delta (EG nothing to verify against the Original file)
+)
+
+// BlockReport type.
+// This will describe how a single operation of the reconstructed Updated file was produced.
+// Head + Tail will define the copied range within the Original file (unused for literal blocks).
+// Checksum will be the SHA-256 hash of the bytes written by the operation, encoded as a hex string.
+// EG: BlockReport{Kind: "copy", Position: 5, Head: 0, Tail: 4, Size: 5, Checksum: "...", Status: "verified"}.
+type BlockReport struct {
+	Kind     string `json:"kind"`
+	Position int64  `json:"position"`
+	Head     int64  `json:"head"`
+	Tail     int64  `json:"tail"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+	Status   string `json:"status"`
+}
+
+// ApplyWithReport() will patch an Original file in the same way as Apply(), returning a report of every operation written (in output order).
+// When a Signature is provided, each matched block will be verified in the same way as ApplyStrict(), recording a status per block instead of stopping at the first mismatch.
+// Mismatched blocks will be re-read before being reported as mismatched when retries have been set (see SetReadRetries()).
+// This allows auditors to prove exactly how the output was reconstructed.
+// Function will return `report, nil` when Delta has been applied successfully.
+// Function will return `report, OriginalFileChangedError` when any matched block does not match the Signature (output should be discarded).
+// Function will return `report, error` in the same cases as Apply() (report will contain the operations written before the error).
+func ApplyWithReport(original io.ReaderAt, delta models.Delta, signature models.Signature, out io.Writer) ([]BlockReport, error) {
+	events, err := ApplyWithAudit(original, delta, signature, out)
+	return Reports(events), err
+}
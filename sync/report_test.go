@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// checksumOf() will return the hex encoded SHA-256 hash of the provided data.
+func checksumOf(data string) string {
+	checksum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(checksum[:])
+}
+
+func TestApplyWithReport(t *testing.T) {
+	t.Run("should return `report, nil` describing each operation when matched blocks match Signature", func(t *testing.T) {
+		// Setup
+		data := []byte("abcdefghijklmnopqrstuvwxyz")
+		signature := signatureOf(t, data)
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+			1:  models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}},
+			27: models.Block{Head: 0, Tail: 2, IsModified: false, Value: []byte{}},
+		}
+
+		expectedReport := []BlockReport{
+			{Kind: "literal", Position: 0, Size: 1, Checksum: checksumOf("!"), Status: BlockLiteral},
+			{Kind: "copy", Position: 1, Head: 0, Tail: 25, Size: 26, Checksum: checksumOf(string(data)), Status: BlockVerified},
+			{Kind: "copy", Position: 27, Head: 0, Tail: 2, Size: 3, Checksum: checksumOf("abc"), Status: BlockUnverified},
+		}
+
+		var out bytes.Buffer
+		// Run
+		report, err := ApplyWithReport(bytes.NewReader(data), delta, signature, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedReport, report)
+		require.Equal(t, "!abcdefghijklmnopqrstuvwxyzabc", out.String())
+	})
+
+	t.Run("should return `report, OriginalFileChangedError` recording mismatched blocks when Original file has drifted from Signature", func(t *testing.T) {
+		// Setup
+		signature := signatureOf(t, []byte("abcdefghijklmnopqrstuvwxyz"))
+		drifted := []byte("abcdefghijklmnoPqrstuvwxyz")
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 25, IsModified: false, Value: []byte{}},
+			26: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+		}
+
+		expectedError := errors.New(constants.OriginalFileChangedError)
+		var out bytes.Buffer
+		// Run
+		report, err := ApplyWithReport(bytes.NewReader(drifted), delta, signature, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 2, len(report))
+		require.Equal(t, BlockMismatch, report[0].Status)
+		require.Equal(t, BlockLiteral, report[1].Status)
+	})
+
+	t.Run("should return unverified matched blocks when no Signature provided", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 4, IsModified: false, Value: []byte{}}}
+		var out bytes.Buffer
+		// Run
+		report, err := ApplyWithReport(bytes.NewReader([]byte("hello")), delta, nil, &out)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []BlockReport{{Kind: "copy", Position: 0, Head: 0, Tail: 4, Size: 5, Checksum: checksumOf("hello"), Status: BlockUnverified}}, report)
+	})
+
+	t.Run("should return `report, InvalidDeltaError` when Delta contains a gap between blocks", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+			5: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("?")},
+		}
+
+		expectedError := errors.New(constants.InvalidDeltaError)
+		var out bytes.Buffer
+		// Run
+		report, err := ApplyWithReport(bytes.NewReader([]byte("hello")), delta, nil, &out)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, 1, len(report))
+	})
+}
@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"errors"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// settings type.
+// This will contain the chunk size + hash algorithms used to generate a single Signature or Delta, passed through generation rather than read from the settings of the whole process.
+// This allows Signatures + Deltas with different settings to be generated at the same time (EG library callers, see GenerateSignatureWith()).
+type settings struct {
+	chunk      int64
+	weakHash   WeakHash
+	strongHash StrongHash
+	legacyHash StrongHash // nil when no Legacy hash algorithm has been selected
+}
+
+// activeSettings() will return the settings selected for the whole process (see SetChunkSize(), UseHashes() + UseLegacyHash()).
+func activeSettings() settings {
+	return settings{chunk: chunk, weakHash: activeWeakHash, strongHash: activeStrongHash, legacyHash: activeLegacyHash}
+}
+
+// defaultSettings() will return the default chunk size + hash algorithms, ignoring the settings selected for the whole process.
+func defaultSettings() settings {
+	return settings{chunk: rolling.DefaultChunkSize, weakHash: weakHashes[DefaultWeakHash], strongHash: generateStrongHash}
+}
+
+// with() will return a copy of the settings using the chunk size + hash algorithms described by metadata, keeping the current value of empty fields.
+// Function returns `settings, nil` when successful.
+// Function returns `emptySettings, ChunkSizeOutOfRangeError` when the chunk size is negative or larger than rolling.MaxChunkSize.
+// Function returns `emptySettings, HashNotRegisteredError` when the Weak (or Strong) hash has not been registered.
+func (s settings) with(metadata models.SignatureMetadata) (settings, error) {
+	if metadata.ChunkSize < 0 || metadata.ChunkSize > rolling.MaxChunkSize {
+		return settings{}, errors.New(constants.ChunkSizeOutOfRangeError)
+	}
+
+	if metadata.ChunkSize != 0 {
+		s.chunk = metadata.ChunkSize
+	}
+
+	if metadata.WeakHash != "" {
+		weakHash, exists := weakHashes[metadata.WeakHash]
+		if !exists {
+			return settings{}, errors.New(constants.HashNotRegisteredError)
+		}
+
+		s.weakHash = weakHash
+	}
+
+	if metadata.StrongHash != "" {
+		strongHash, exists := lookupStrongHash(metadata.StrongHash)
+		if !exists {
+			return settings{}, errors.New(constants.HashNotRegisteredError)
+		}
+
+		s.strongHash = strongHash
+	}
+
+	return s, nil
+}
+
+// legacy() will hash a buffer with the selected Legacy hash algorithm.
+// Function returns `""` when no Legacy hash algorithm has been selected.
+func (s settings) legacy(buffer []byte) string {
+	if s.legacyHash == nil {
+		return ""
+	}
+
+	return s.legacyHash(buffer, s.chunk)
+}
+
+// ApplyStrictWith() will patch an Original file in the same way as ApplyStrict(), verifying matched blocks with the chunk size + Strong hash described by metadata instead of the settings selected for the whole process.
+// Note: empty fields of metadata will use the defaults (see GenerateSignatureWith()).
+// Function will return `nil` when Delta has been applied successfully.
+// Function will return `ChunkSizeOutOfRangeError` or `HashNotRegisteredError` when metadata is invalid (nothing written).
+// Function will return `error` in the same cases as ApplyStrict().
+func ApplyStrictWith(original io.ReaderAt, delta models.Delta, signature models.Signature, metadata models.SignatureMetadata, out io.Writer) error {
+	s, err := defaultSettings().with(metadata)
+	if err != nil {
+		return err
+	}
+
+	return applyStrict(original, delta, signature, s, out)
+}
+
+// GenerateDeltaWith() will create a Delta in the same way as GenerateDelta(), using the chunk size + hash algorithms described by metadata (EG recorded within the Signature file) instead of the settings selected for the whole process.
+// Note: empty fields of metadata will use the defaults (see GenerateSignatureWith()).
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, ChunkSizeOutOfRangeError` or `HashNotRegisteredError` when metadata is invalid.
+// Function returns `emptyDelta, error` in the same cases as GenerateDelta().
+func GenerateDeltaWith(reader Reader, signature models.Signature, metadata models.SignatureMetadata, verbose bool) (models.Delta, error) {
+	s, err := defaultSettings().with(metadata)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	return generateDeltaModel(reader, signature, Hooks{}, s, verbose)
+}
+
+// GenerateSignatureWith() will create a Signature in the same way as GenerateSignature(), using the chunk size + hash algorithms described by metadata instead of the settings selected for the whole process.
+// Note: empty fields of metadata will use the defaults (EG rolling.DefaultChunkSize + DefaultWeakHash), so concurrent callers never share settings.
+// Function returns `signature, nil` when successful.
+// Function returns `emptySignature, ChunkSizeOutOfRangeError` or `HashNotRegisteredError` when metadata is invalid.
+// Function returns `emptySignature, error` in the same cases as GenerateSignature().
+func GenerateSignatureWith(reader Reader, metadata models.SignatureMetadata, verbose bool) (models.Signature, error) {
+	s, err := defaultSettings().with(metadata)
+	if err != nil {
+		return models.Signature{}, err
+	}
+
+	return generateSignatureModel(reader, s, verbose)
+}
@@ -0,0 +1,218 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// GenerateSparseSignature() will create a file Signature containing only the blocks starting at every Nth offset (stride) of the Original file, plus the final block.
+// Unlike PruneSignature(), skipped offsets are never hashed, giving a much faster first pass over huge files (EG stride 64 hashes 1/64th of the blocks).
+// Blocks between samples can only be matched when stride <= chunk size, so larger strides send a longer literal tail unless the Delta is expanded (see ExpandDelta()).
+// Note: strides smaller than the chunk size will be generated in the same way as GenerateSignature() + PruneSignature() (EG overlapping blocks).
+// Function returns `Signature, nil` when successful.
+// Function returns `emptySignature, nil` when Original file is empty.
+// Function returns `emptySignature, error` when unable to read from file.
+func GenerateSparseSignature(reader Reader, stride int64, verbose bool) (models.Signature, error) {
+	if stride < chunk {
+		signature, err := GenerateSignature(reader, verbose)
+		if err != nil {
+			return models.Signature{}, err
+		}
+
+		return PruneSignature(signature, stride), nil
+	}
+
+	signature := make(models.Signature)
+	head := int64(0)
+	sampled := int64(-1)
+	// Most recent bytes read, used to hash the final block of the Original file
+	recent := []byte{}
+	for {
+		// Read the next sample, plus the skipped bytes up to the following sample
+		segment, err := initialiseBuffer(reader, stride)
+		if err != nil {
+			if err.Error() == constants.EndOfFileError {
+				break
+			}
+
+			return models.Signature{}, err
+		}
+
+		// Hash sample when the segment contains a full block (or the Original file is smaller than a block)
+		if int64(len(segment)) >= chunk || head == 0 {
+			size := chunk
+			if int64(len(segment)) < size {
+				size = int64(len(segment))
+			}
+
+			addSparseBlock(signature, segment[:size], head, verbose)
+			sampled = head
+		}
+
+		recent = append(recent, segment...)
+		if int64(len(recent)) > chunk {
+			recent = recent[int64(len(recent))-chunk:]
+		}
+
+		head += int64(len(segment))
+	}
+
+	// Always include the final block, so the size of the Original file can be derived from the Signature
+	if final := head - chunk; final > sampled && final >= 0 {
+		addSparseBlock(signature, recent, final, verbose)
+	}
+
+	logger(fmt.Sprintf("Signature: %+v\n", signature), verbose)
+	return signature, nil
+}
+
+// addSparseBlock() will hash a block starting at the provided head of the Original file, and add it to the Signature.
+func addSparseBlock(signature models.Signature, buffer []byte, head int64, verbose bool) {
+	weakHash := activeWeakHash.Sum(buffer, chunk)
+	strongHash := activeStrongHash(buffer, chunk)
+	if sampleLog(verbose) {
+		logger(fmt.Sprintf("Sampled Buffer = %q", buffer[:]), true)
+		logger(fmt.Sprintf("Weak hash = %d", weakHash), true)
+		logger(fmt.Sprintf("Strong hash = %s\n", strongHash), true)
+	}
+
+	addSignatureItem(signature, weakHash, models.StrongSignature{Hash: strongHash, LegacyHash: legacyHash(buffer), Head: head, Tail: head + chunk - 1})
+}
+
+// ExpandDelta() will extend the matched blocks of a Delta into neighbouring literal data, by comparing the literal data against the Original file.
+// This recovers exact block boundaries around each match when the Signature did not contain every offset (see GenerateSparseSignature()), so requires local access to the Original file.
+// Literal data fully covered by neighbouring matched blocks will be removed, and contiguous matched blocks will be merged.
+// Function returns `delta, nil` when successful.
+// Function returns `emptyDelta, UpdatedFileHasNoChangesError` when the expanded Delta copies the Original file (described by the Signature) unchanged.
+// Function returns `emptyDelta, InvalidDeltaError` when Delta blocks contain gaps or overlap (EG corrupt Delta).
+// Function returns `emptyDelta, UnableToReadOriginalFileError` when unable to read from the Original file.
+func ExpandDelta(original io.ReaderAt, delta models.Delta, signature models.Signature) (models.Delta, error) {
+	ops, err := deltaOps(delta)
+	if err != nil {
+		return models.Delta{}, err
+	}
+
+	for index := range ops {
+		if ops[index].Kind != models.OpLiteral {
+			continue
+		}
+
+		// Extend previous matched block forwards
+		if index > 0 && ops[index-1].Kind == models.OpCopy {
+			matched, err := matchForward(original, ops[index-1].Tail+1, ops[index].Value)
+			if err != nil {
+				return models.Delta{}, err
+			}
+
+			ops[index-1].Tail += matched
+			ops[index].Value = ops[index].Value[matched:]
+		}
+
+		// Extend next matched block backwards
+		if index+1 < len(ops) && ops[index+1].Kind == models.OpCopy {
+			matched, err := matchBackward(original, ops[index+1].Head, ops[index].Value)
+			if err != nil {
+				return models.Delta{}, err
+			}
+
+			ops[index+1].Head -= matched
+			ops[index].Value = ops[index].Value[:int64(len(ops[index].Value))-matched]
+		}
+	}
+
+	return verifyDeltaHasChanges(mergeOps(ops), signature)
+}
+
+// deltaOps() will return the operations of a Delta in order of position in the Updated file.
+// Function returns `ops, nil` when successful.
+// Function returns `nil, InvalidDeltaError` when Delta blocks contain gaps or overlap (EG corrupt Delta).
+func deltaOps(delta models.Delta) ([]models.Op, error) {
+	ops := []models.Op{}
+	position := int64(0)
+	err := delta.Ops(func(op models.Op) error {
+		if op.Position != position {
+			return errors.New(constants.InvalidDeltaError)
+		}
+
+		position += op.Len()
+		ops = append(ops, op)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+// matchForward() will count the bytes at the start of value which match the Original file from the provided offset.
+// Function returns `matched, nil` when successful (EG 0 at the end of the Original file).
+// Function returns `0, UnableToReadOriginalFileError` when unable to read from the Original file.
+func matchForward(original io.ReaderAt, offset int64, value []byte) (int64, error) {
+	buffer := make([]byte, len(value))
+	read, err := original.ReadAt(buffer, offset)
+	if err != nil && err != io.EOF {
+		return 0, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	matched := int64(0)
+	for matched < int64(read) && buffer[matched] == value[matched] {
+		matched++
+	}
+
+	return matched, nil
+}
+
+// matchBackward() will count the bytes at the end of value which match the Original file before the provided offset.
+// Function returns `matched, nil` when successful (EG 0 at the start of the Original file).
+// Function returns `0, UnableToReadOriginalFileError` when unable to read from the Original file.
+func matchBackward(original io.ReaderAt, offset int64, value []byte) (int64, error) {
+	start := offset - int64(len(value))
+	if start < 0 {
+		start = 0
+	}
+
+	buffer := make([]byte, offset-start)
+	if _, err := original.ReadAt(buffer, start); err != nil {
+		return 0, errors.New(constants.UnableToReadOriginalFileError)
+	}
+
+	matched := int64(0)
+	for matched < int64(len(buffer)) && buffer[int64(len(buffer))-1-matched] == value[int64(len(value))-1-matched] {
+		matched++
+	}
+
+	return matched, nil
+}
+
+// mergeOps() will convert operations back into a Delta, dropping empty literal operations and merging contiguous matched blocks.
+func mergeOps(ops []models.Op) models.Delta {
+	delta := make(models.Delta)
+	position := int64(0)
+	var previous *models.Op
+	for index := range ops {
+		op := ops[index]
+		if op.Kind == models.OpLiteral && len(op.Value) == 0 {
+			continue
+		}
+
+		if previous != nil && previous.Kind == models.OpCopy && op.Kind == models.OpCopy && previous.Tail+1 == op.Head {
+			previous.Tail = op.Tail
+			delta[previous.Position] = previous.Block()
+			position += op.Len()
+			continue
+		}
+
+		op.Position = position
+		delta[position] = op.Block()
+		position += op.Len()
+		previous = &op
+	}
+
+	return delta
+}
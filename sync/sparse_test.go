@@ -0,0 +1,138 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// sparseSignatureOf() will generate a sparse Signature of the provided data, restoring buffer mocks from earlier tests.
+func sparseSignatureOf(t *testing.T, data []byte, stride int64) models.Signature {
+	initialiseBuffer = populateBuffer
+	rollBuffer = roll
+	signature, err := GenerateSparseSignature(bufio.NewReader(bytes.NewReader(data)), stride, false)
+	require.Equal(t, nil, err)
+	return signature
+}
+
+func TestGenerateSparseSignature(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun")
+	t.Run("should return `signature, nil` containing blocks at every Nth offset, plus the final block", func(t *testing.T) {
+		// Run
+		signature := sparseSignatureOf(t, data, 32)
+		// Verify
+		require.Equal(t, PruneSignature(signatureOf(t, data), 32), signature)
+		require.Equal(t, int64(len(data)), originalSize(signature))
+	})
+
+	t.Run("should return the same Signature as pruning when stride smaller than chunk size", func(t *testing.T) {
+		// Run
+		signature := sparseSignatureOf(t, data, 4)
+		// Verify
+		require.Equal(t, PruneSignature(signatureOf(t, data), 4), signature)
+	})
+
+	t.Run("should return a single block when Original file smaller than a chunk", func(t *testing.T) {
+		// Run
+		signature := sparseSignatureOf(t, []byte("tiny"), 32)
+		// Verify
+		require.Equal(t, signatureOf(t, []byte("tiny")), signature)
+	})
+
+	t.Run("should return `emptySignature, nil` when Original file is empty", func(t *testing.T) {
+		// Run
+		signature := sparseSignatureOf(t, []byte{}, 32)
+		// Verify
+		require.Equal(t, models.Signature{}, signature)
+	})
+
+	t.Run("should return `emptySignature, error` when unable to read from file", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return []byte{}, expectedError
+		}
+
+		// Run
+		signature, err := GenerateSparseSignature(bufio.NewReader(bytes.NewReader(data)), 32, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Signature{}, signature)
+		// Restore, so later tests read from files
+		initialiseBuffer = populateBuffer
+	})
+}
+
+func TestExpandDelta(t *testing.T) {
+	original := []byte("the quick brown fox jumps over the lazy dog, then naps in the afternoon sun")
+	t.Run("should return `delta, nil` extending matched blocks into literal data which matches the Original file", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 32)
+		updated := append(append(append([]byte{}, original[:40]...), '!'), original[40:]...)
+		delta, err := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+		require.Equal(t, nil, err)
+		// Run
+		expanded, err := ExpandDelta(bytes.NewReader(original), delta, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		matched, literal := Stats(expanded)
+		require.Equal(t, int64(len(original)), matched)
+		require.Equal(t, int64(1), literal)
+		require.Equal(t, 3, len(expanded))
+		var out bytes.Buffer
+		require.Equal(t, nil, Apply(bytes.NewReader(original), expanded, &out))
+		require.Equal(t, updated, out.Bytes())
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when expanded Delta copies Original file unchanged", func(t *testing.T) {
+		// Setup
+		signature := sparseSignatureOf(t, original, 32)
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}},
+			16: models.Block{Head: 16, Tail: 74, IsModified: true, Value: original[16:]},
+		}
+
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Run
+		expanded, err := ExpandDelta(bytes.NewReader(original), delta, signature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, expanded)
+	})
+
+	t.Run("should return `emptyDelta, InvalidDeltaError` when Delta contains a gap between blocks", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0:  models.Block{Head: 0, Tail: 3, IsModified: false, Value: []byte{}},
+			10: models.Block{Head: 10, Tail: 10, IsModified: true, Value: []byte("!")},
+		}
+
+		expectedError := errors.New(constants.InvalidDeltaError)
+		// Run
+		expanded, err := ExpandDelta(bytes.NewReader(original), delta, models.Signature{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, expanded)
+	})
+
+	t.Run("should return `emptyDelta, UnableToReadOriginalFileError` when unable to read from Original file", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{
+			0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte("!")},
+			1: models.Block{Head: 4, Tail: 8, IsModified: false, Value: []byte{}},
+		}
+
+		expectedError := errors.New(constants.UnableToReadOriginalFileError)
+		// Run
+		expanded, err := ExpandDelta(failingReaderAtMock{}, delta, models.Signature{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, expanded)
+	})
+}
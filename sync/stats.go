@@ -0,0 +1,61 @@
+package sync
+
+import "github.com/curtismenmuir/go-file-diff/models"
+
+// SignatureStats type.
+// This will describe how the blocks of a Signature are distributed across Weak hash buckets.
+// Shared buckets contain blocks with the same Weak hash, which must be separated by comparing Strong hashes during Delta generation.
+// Full buckets have reached the candidate limit, so earlier positions of repeated content may have been dropped (blocks with different content are always kept, see models.MaxCandidates).
+// Duplicate blocks share a Strong hash with an earlier block (EG repeated content in the Original file).
+type SignatureStats struct {
+	Buckets         int
+	Blocks          int
+	SingleBuckets   int
+	SharedBuckets   int
+	FullBuckets     int
+	MaxBucketSize   int
+	DuplicateBlocks int
+}
+
+// AverageBucketSize() will return the mean number of blocks stored per Weak hash bucket.
+func (stats SignatureStats) AverageBucketSize() float64 {
+	if stats.Buckets == 0 {
+		return 0
+	}
+
+	return float64(stats.Blocks) / float64(stats.Buckets)
+}
+
+// GetSignatureStats() will count the Weak hash bucket occupancy + duplicate blocks of a Signature.
+// Note: a bucket's size includes the top level block + its candidates.
+func GetSignatureStats(signature models.Signature) SignatureStats {
+	stats := SignatureStats{Buckets: len(signature)}
+	strongHashes := map[string]bool{}
+	for _, item := range signature {
+		size := len(item.Candidates) + 1
+		stats.Blocks += size
+		if size > stats.MaxBucketSize {
+			stats.MaxBucketSize = size
+		}
+
+		if size == 1 {
+			stats.SingleBuckets++
+		} else {
+			stats.SharedBuckets++
+		}
+
+		if len(item.Candidates) >= maxCandidates {
+			stats.FullBuckets++
+		}
+
+		for _, block := range append([]models.StrongSignature{item}, item.Candidates...) {
+			if strongHashes[block.Hash] {
+				stats.DuplicateBlocks++
+			}
+
+			strongHashes[block.Hash] = true
+		}
+	}
+
+	return stats
+}
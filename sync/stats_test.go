@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAverageBucketSize(t *testing.T) {
+	t.Run("should return mean blocks per bucket", func(t *testing.T) {
+		// Run
+		average := SignatureStats{Buckets: 4, Blocks: 10}.AverageBucketSize()
+		// Verify
+		require.Equal(t, 2.5, average)
+	})
+
+	t.Run("should return 0 when Signature is empty", func(t *testing.T) {
+		// Run
+		average := SignatureStats{}.AverageBucketSize()
+		// Verify
+		require.Equal(t, 0.0, average)
+	})
+}
+
+func TestGetSignatureStats(t *testing.T) {
+	t.Run("should count bucket occupancy + duplicate blocks", func(t *testing.T) {
+		// Setup
+		full := make([]models.StrongSignature, maxCandidates)
+		for i := range full {
+			full[i] = models.StrongSignature{Hash: "full", Head: int64(i) * 16, Tail: int64(i)*16 + 15}
+		}
+
+		signature := models.Signature{
+			1: {Hash: "single", Head: 0, Tail: 15},
+			2: {Hash: "shared", Head: 32, Tail: 47, Candidates: []models.StrongSignature{{Hash: "other", Head: 16, Tail: 31}}},
+			3: {Hash: "full", Head: 400, Tail: 415, Candidates: full},
+		}
+
+		expectedStats := SignatureStats{
+			Buckets:         3,
+			Blocks:          maxCandidates + 4,
+			SingleBuckets:   1,
+			SharedBuckets:   2,
+			FullBuckets:     1,
+			MaxBucketSize:   maxCandidates + 1,
+			DuplicateBlocks: maxCandidates,
+		}
+
+		// Run
+		stats := GetSignatureStats(signature)
+		// Verify
+		require.Equal(t, expectedStats, stats)
+	})
+
+	t.Run("should return empty stats when Signature is empty", func(t *testing.T) {
+		// Run
+		stats := GetSignatureStats(models.Signature{})
+		// Verify
+		require.Equal(t, SignatureStats{}, stats)
+	})
+}
@@ -1,26 +1,24 @@
 package sync
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"math"
-	"math/big"
 
 	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
 	"github.com/curtismenmuir/go-file-diff/models"
 	"github.com/curtismenmuir/go-file-diff/utils"
 )
 
 var (
-	logger                 = utils.Logger
-	initialiseBuffer       = populateBuffer
-	rollBuffer             = roll
-	chunk            int64 = 16           // 16 (bytes) is max chunk size for seed == 11
-	seed             int64 = 11           // Prime number
-	mod              int64 = 100000000009 // 10^11 + 9
+	logger           = utils.Logger
+	sampleLog        = utils.SampleLog
+	initialiseBuffer = populateBuffer
+	rollBuffer       = roll
+	peekBuffer       = peek
+	chunk            = rolling.DefaultChunkSize // Chunk size used when generating Signatures + Deltas (see SetChunkSize())
+	maxCandidates    = models.MaxCandidates     // Max earlier positions stored per Weak hash while any repeat content
 )
 
 // FileReader interface for mocking bufio.Reader.
@@ -29,24 +27,38 @@ type Reader interface {
 	ReadByte() (byte, error)
 }
 
+// peekReader interface for readers which can look ahead without consuming bytes (EG bufio.Reader).
+type peekReader interface {
+	Peek(n int) ([]byte, error)
+	Discard(n int) (int, error)
+}
+
+// addSignatureItem() will add a block to the Signature, indexed by its Weak hash (see models.Signature.WriteEntry()).
+// When the Weak hash already exists, the existing block will be kept as a candidate of the new block (up to `maxCandidates` earlier blocks, unless every block has different content).
+func addSignatureItem(signature models.Signature, weakHash int64, item models.StrongSignature) {
+	_ = signature.WriteEntry(weakHash, item)
+}
+
 // compareChecksums() will search for a Weak hash in provided Signature.
-// When match is found with Weak hash, function will generate Strong hash and compare against Signature item.
+// When match is found with Weak hash, function will generate Strong hash and compare against Signature item (and its candidates).
+// Note: the Legacy hash will also be compared when a Legacy hash algorithm has been selected (see UseLegacyHash()).
+// When multiple blocks match, the block whose Tail is nearest to preferTail will be selected (EG continuing the previous copy run).
 // Function will return `true, item.Head, item.Tail` when successfully found block in Signature (EG When Weak & Strong hashes match Signature item).
 // Function will return `false, -1, -1` when unable to find block in Signature.
-func compareChecksums(signature models.Signature, buffer []byte, weakHash int64, verbose bool) (bool, int, int) {
+func compareChecksums(signature models.Signature, buffer []byte, weakHash int64, preferTail int64, s settings, verbose bool) (bool, int64, int64) {
 	// Search Signature for Weak hash
 	if item, exists := signature[weakHash]; exists {
 		// Generate Strong hash of buffer
-		strongHash := generateStrongHash(buffer, chunk)
+		strongHash := s.strongHash(buffer, s.chunk)
 		logger(fmt.Sprintf("Strong hash = %s", strongHash), verbose)
-		// Verify if Strong hash also matches Signature item
-		if strongHash == item.Hash {
-			logger("Block found\n", verbose)
-			return true, item.Head, item.Tail
+		// Verify if Strong (or Legacy) hash also matches Signature item
+		if match, found := selectCandidate(item, strongHash, s.legacy(buffer), preferTail); found {
+			logger(utils.Success("Block found\n"), verbose)
+			return true, match.Head, match.Tail
 		}
 	}
 
-	logger("Block missing\n", verbose)
+	logger(utils.Warning("Block missing\n"), verbose)
 	return false, -1, -1
 }
 
@@ -54,28 +66,65 @@ func compareChecksums(signature models.Signature, buffer []byte, weakHash int64,
 // Delta will contain a list of reusable blocks from the original file, and where they should be added to match the Updated file.
 // Delta will also contain a list of new blocks which can be applied to the file to sync latest modifications.
 // Function will return `delta, nil` when generated Delta successfully.
+// Function will return `emptyDelta, nil` when Updated file is empty (EG all content removed from Original).
 // Function will return `emptyDelta, UpdatedFileHasNoChangesError` when Updated file has no changes from Original.
 // Function will return `emptyDelta, error` when unable to populate buffer from file.
 // Function will return `emptyDelta, error` when unable to read data from file to roll buffer.
+// Note: when the reader supports peeking (EG bufio.Reader), matched regions will be skipped a chunk at a time rather than rolled byte by byte (see skipMatchedChunk()).
 func GenerateDelta(reader Reader, signature models.Signature, verbose bool) (models.Delta, error) {
-	blockHead := 0
-	deltaHead := 0
-	deltaTail := int(chunk) - 1
+	return GenerateDeltaWithHooks(reader, signature, Hooks{}, verbose)
+}
+
+// GenerateDeltaWithHooks() will create a Delta changeset in the same way as GenerateDelta(), firing the provided Hooks for each block-level decision.
+// Hooks allow callers (EG GUIs + monitoring wrappers) to visualise Delta generation in real time without parsing logs.
+// Function returns `delta, nil` when successful.
+// Function will return `emptyDelta, UpdatedFileHasNoChangesError` when no changes found in Updated file.
+// Function will return `emptyDelta, error` when unable to read data from file to roll buffer.
+func GenerateDeltaWithHooks(reader Reader, signature models.Signature, hooks Hooks, verbose bool) (models.Delta, error) {
+	return generateDeltaModel(reader, signature, hooks, activeSettings(), verbose)
+}
+
+// generateDeltaModel() will create a Delta changeset in the same way as GenerateDeltaWithHooks(), with the provided chunk size + hash algorithms.
+// Function returns `delta, nil` when successful.
+// Function will return `emptyDelta, error` in the same cases as GenerateDeltaWithHooks().
+func generateDeltaModel(reader Reader, signature models.Signature, hooks Hooks, s settings, verbose bool) (models.Delta, error) {
 	delta := make(models.Delta)
+	if err := generateDelta(reader, signature, &deltaBuilder{writer: delta, hooks: hooks}, s, verbose); err != nil {
+		return models.Delta{}, err
+	}
+
+	logger(fmt.Sprintf("Delta: %+v\n", delta), verbose)
+	return verifyDeltaHasChanges(delta, signature)
+}
+
+// generateDelta() will roll through the Updated file, passing each finalised block of the Delta to the provided builder.
+// Function returns `nil` when all blocks have been passed to the builder (no blocks passed when Updated file is empty).
+// Function will return `error` when unable to populate buffer from file.
+// Function will return `error` when unable to read data from file to roll buffer.
+// Function will return `error` when the builder is unable to write a block.
+func generateDelta(reader Reader, signature models.Signature, builder *deltaBuilder, s settings, verbose bool) error {
+	blockHead := int64(0)
+	deltaHead := int64(0)
+	deltaTail := s.chunk - 1
 	initialBlockMatches := true
 	var block models.Block
 	// Create buffer based on chunk size
-	buffer, err := initialiseBuffer(reader, chunk)
+	buffer, err := initialiseBuffer(reader, s.chunk)
 	if err != nil {
-		return models.Delta{}, err
+		// Empty Updated file will produce an empty Delta
+		if err.Error() == constants.EndOfFileError {
+			return nil
+		}
+
+		return err
 	}
 
 	logger(fmt.Sprintf("Initial Buffer = %q", buffer[:]), verbose)
 	// Generate Weak hash of initial buffer
-	weakHash := generateWeakHash(buffer, chunk)
+	weakHash := s.weakHash.Sum(buffer, s.chunk)
 	logger(fmt.Sprintf("Weak hash = %d", weakHash), verbose)
 	// Search Signature for match on initial buffer
-	exists, head, tail := compareChecksums(signature, buffer, weakHash, verbose)
+	exists, head, tail := compareChecksums(signature, buffer, weakHash, deltaTail, s, verbose)
 	if exists {
 		// Create new matched block
 		block = models.Block{Head: head, Tail: tail, IsModified: !exists, Value: []byte{}}
@@ -83,20 +132,39 @@ func GenerateDelta(reader Reader, signature models.Signature, verbose bool) (mod
 		// Create new missing block and record initial block does not match
 		block = models.Block{Head: deltaHead, Tail: deltaHead, IsModified: !exists, Value: []byte{buffer[0]}}
 		initialBlockMatches = false
+		builder.startLiteral(deltaHead)
 	}
 
 	// Loop until EOF
 	for {
 		var initialByte, nextByte byte
 		var rollExists bool
-		var rollHead, rollTail int
+		var rollHead, rollTail int64
+		var rolledBuffer []byte
+		// Skip ahead a full chunk while the next chunk continues the matched block, instead of rolling through it byte by byte
+		if exists {
+			if skippedBuffer, skippedHash, skipped := skipMatchedChunk(reader, signature, block, s); skipped {
+				buffer, weakHash = skippedBuffer, skippedHash
+				block.Tail += s.chunk
+				deltaHead += s.chunk
+				deltaTail += s.chunk
+				continue
+			}
+		}
+
 		// Roll buffer to next position
-		buffer, initialByte, nextByte, err = rollBuffer(reader, buffer)
+		rolledBuffer, initialByte, nextByte, err = rollBuffer(reader, buffer)
 		if err != nil {
 			// Break loop when EOF returned
 			if err.Error() == constants.EndOfFileError {
+				// Initial missing block only contains the first byte of each buffer, add remaining bytes from final buffer
+				if !initialBlockMatches {
+					block.Value = append(block.Value, buffer[1:]...)
+					block.Tail += int64(len(buffer)) - 1
+				}
+
 				// Add final block to Delta
-				delta[blockHead] = block
+				builder.writeBlock(blockHead, block)
 				logger(fmt.Sprintf("Final Block added to Delta: %+v\n", block), verbose)
 				if block.IsModified {
 					logger(fmt.Sprintf("Final Block Value = %q\n", block.Value[:]), verbose)
@@ -106,54 +174,107 @@ func GenerateDelta(reader Reader, signature models.Signature, verbose bool) (mod
 			}
 
 			// Handle errors
-			return models.Delta{}, err
+			return err
+		}
+
+		buffer = rolledBuffer
+		// Sample per-roll debug output, so verbose runs on large files stay usable
+		logRoll := sampleLog(verbose)
+		if logRoll {
+			logger(fmt.Sprintf("Rolled Buffer = %q", buffer[:]), true)
 		}
 
-		logger(fmt.Sprintf("Rolled Buffer = %q", buffer[:]), verbose)
 		// Increment Delta position
 		deltaHead++
 		deltaTail++
 		// Roll Weak hash
-		weakHash = rollWeakHash(weakHash, initialByte, nextByte, chunk)
-		logger(fmt.Sprintf("Rolled hash = %d", weakHash), verbose)
+		weakHash = s.weakHash.Roll(weakHash, initialByte, nextByte, s.chunk)
+		if logRoll {
+			logger(fmt.Sprintf("Rolled hash = %d", weakHash), true)
+		}
+
 		// Search Signature for match on rolled buffer
-		rollExists, rollHead, rollTail = compareChecksums(signature, buffer, weakHash, verbose)
+		// Prefer a match continuing the previous copy run, otherwise the same offset in the Original file
+		preferTail := deltaTail
+		if exists {
+			preferTail = block.Tail + 1
+		}
+
+		rollExists, rollHead, rollTail = compareChecksums(signature, buffer, weakHash, preferTail, s, logRoll)
 		if rollExists {
 			// Match found in Signature, generate matched block
-			block, blockHead, initialBlockMatches = generateMatchedBlock(delta, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, verbose)
+			block, blockHead, initialBlockMatches = generateMatchedBlock(builder, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, s.chunk, verbose)
 		} else {
 			// No match found in Signature, generate missing block
-			block, blockHead = generateMissingBlock(delta, block, exists, initialBlockMatches, blockHead, nextByte, buffer, verbose)
+			block, blockHead = generateMissingBlock(builder, block, exists, initialBlockMatches, blockHead, nextByte, buffer, verbose)
+		}
+
+		// Stop generating when the builder is unable to write a block
+		if builder.err != nil {
+			return builder.err
 		}
 
 		// Record if match found for next iteration
 		exists = rollExists
 	}
 
-	logger(fmt.Sprintf("Delta: %+v\n", delta), verbose)
+	return builder.err
+}
 
-	// Verify if Delta contains any modifications for Original file
-	if len(delta) == 1 && !delta[0].IsModified {
-		return models.Delta{}, errors.New(constants.UpdatedFileHasNoChangesError)
+// skipMatchedChunk() will look ahead at the next chunk of the Updated file, consuming it when it matches the Original file directly after the matched block (EG rsync jumping to the next block boundary after a match).
+// This avoids rolling through (+ Strong hashing) every offset of long unchanged regions.
+// Note: the next chunk will not be consumed when it does not continue the matched block, so rolling resumes from the current buffer.
+// Function returns `buffer, weakHash, true` when the next chunk continues the matched block (buffer + Weak hash of the skipped chunk).
+// Function returns `nil, 0, false` when the next chunk does not continue the matched block, or can not be read ahead (EG fewer than `chunk` bytes remain).
+func skipMatchedChunk(reader Reader, signature models.Signature, block models.Block, s settings) ([]byte, int64, bool) {
+	next, ok := peekBuffer(reader, s.chunk)
+	if !ok {
+		return nil, 0, false
 	}
 
-	return delta, nil
+	weakHash := s.weakHash.Sum(next, s.chunk)
+	if exists, _, tail := compareChecksums(signature, next, weakHash, block.Tail+s.chunk, s, false); !exists || tail != block.Tail+s.chunk {
+		return nil, 0, false
+	}
+
+	if peeker, ok := reader.(peekReader); !ok {
+		return nil, 0, false
+	} else if _, err := peeker.Discard(len(next)); err != nil {
+		return nil, 0, false
+	}
+
+	return next, weakHash, true
 }
 
 // generateMatchedBlock() will generate a new matched block after adding previous missing block to Delta (only added to delta when applicable).
 // If previous roll was a match, then function will increase blocks tail position.
+// If previous roll was a match at a non-contiguous position in the Original file, then function will add the previous matched block to Delta and return a new matched block.
 // If previous roll was a missing block at the start of the file, then function will add provided block to Delta and return a new matched block.
 // Note: Missing initial block will be found at start of buffer (EG not rolled in).
 // If previous roll was a missing block but not found at beginning of file, then function will reduce block to remove any matched bytes, add block to Delta, and return a new matched block.
 // Note: Function reduces block as final roll will include 15 bytes of next match (EG rolling 16 byte buffer).
+// When the new match overlaps bytes already covered by the previous matched block, the new block will start after the overlap.
 // Function returns `block, blockHead, initialBlockMatches` upon completion.
-// Note: Function will update original instance of provided `Delta` as maps are reference types.
-func generateMatchedBlock(delta models.Delta, block models.Block, exists bool, initialBlockMatches bool, blockHead int, deltaHead int, rollHead int, rollTail int, rollExists bool, verbose bool) (models.Block, int, bool) {
+// Note: Function will add blocks to the Delta held by the provided builder, firing any Hooks.
+func generateMatchedBlock(builder *deltaBuilder, block models.Block, exists bool, initialBlockMatches bool, blockHead int64, deltaHead int64, rollHead int64, rollTail int64, rollExists bool, size int64, verbose bool) (models.Block, int64, bool) {
 	// Verify if previous block matched
 	if exists {
-		// Increase blocks tail position when rolled buffer still matches
-		block.Tail++
+		// Verify rolled buffer continues the previous match in the Original file
+		if rollTail == block.Tail+1 {
+			// Increase blocks tail position when rolled buffer still matches
+			block.Tail++
+		} else {
+			// Rolled buffer matches a different position in Original file, add matched block to Delta
+			builder.writeBlock(blockHead, block)
+			logger(utils.Success(fmt.Sprintf("Matched Block added to Delta: %+v\n", block)), verbose)
+			// Update position for next matching block
+			blockHead = blockHead + block.Tail - block.Head + 1
+			// Create new matching block containing only the final byte of rolled buffer (EG previous bytes already covered)
+			block = models.Block{Head: rollTail, Tail: rollTail, IsModified: !rollExists, Value: []byte{}}
+		}
 	} else {
+		// Number of bytes at the start of the new match which are already covered by the previous matched block
+		overlap := int64(0)
 		// Verify if updating initial missing block
 		if !initialBlockMatches {
 			// If initial block is missing then block will contain only updated values
@@ -161,22 +282,30 @@ func generateMatchedBlock(delta models.Delta, block models.Block, exists bool, i
 		} else {
 			// Reduce block to remove following matched characters
 			// EG last 15 characters of buffer will contain start of next matched block due to rolling function (EG buffer size == 16)
-			block.Tail = block.Tail + 1 - int(chunk)
+			block.Tail = block.Tail + 1 - size
+			if block.Tail < 0 {
+				// New match starts before the end of the previous matched block (EG weak hash collision caused a roll to be missed)
+				overlap = -(block.Tail + 1)
+				block.Tail = -1
+			}
+
 			missingValues := make([]byte, 0)
 			missingValues = append(missingValues, block.Value[0:block.Tail+1]...)
 			block.Value = missingValues
 		}
 
-		// Add missing block to Delta
-		delta[blockHead] = block
-		logger(fmt.Sprintf("Missing Block added to Delta: %+v", block), verbose)
-		logger(fmt.Sprintf("Missing Block Position: %d", blockHead), verbose)
-		logger(fmt.Sprintf("Missing Block Value = %q\n", block.Value[:]), verbose)
-		// Update position for next matching block
-		blockHead = deltaHead
-		// Create new matching block
-		block = models.Block{Head: rollHead, Tail: rollTail, IsModified: !rollExists, Value: []byte{}}
+		// Add missing block to Delta (skip when reduced block contains no missing values)
+		if len(block.Value) > 0 {
+			builder.writeBlock(blockHead, block)
+			logger(utils.Warning(fmt.Sprintf("Missing Block added to Delta: %+v", block)), verbose)
+			logger(fmt.Sprintf("Missing Block Position: %d", blockHead), verbose)
+			logger(fmt.Sprintf("Missing Block Value = %q\n", block.Value[:]), verbose)
+		}
 
+		// Update position for next matching block (skipping any bytes already covered)
+		blockHead = deltaHead + overlap
+		// Create new matching block
+		block = models.Block{Head: rollHead + overlap, Tail: rollTail, IsModified: !rollExists, Value: []byte{}}
 	}
 
 	return block, blockHead, initialBlockMatches
@@ -189,17 +318,18 @@ func generateMatchedBlock(delta models.Delta, block models.Block, exists bool, i
 // If previous roll was a missing block but not at beginning of file, the function will add next rolled byte to block Value & increment block Tail position.
 // Note: Use nextByte as missing block will be added to end of buffer (EG rolling 16 byte buffer).
 // Function returns `block, blockHead` upon completion.
-// Note: Function will update original instance of provided `Delta` as maps are reference types.
-func generateMissingBlock(delta models.Delta, block models.Block, exists bool, initialBlockMatches bool, blockHead int, nextByte byte, buffer []byte, verbose bool) (models.Block, int) {
+// Note: Function will add blocks to the Delta held by the provided builder, firing any Hooks.
+func generateMissingBlock(builder *deltaBuilder, block models.Block, exists bool, initialBlockMatches bool, blockHead int64, nextByte byte, buffer []byte, verbose bool) (models.Block, int64) {
 	// Verify if previous block matched
 	if exists {
 		// Add matching block to Delta
-		delta[blockHead] = block
-		logger(fmt.Sprintf("Matched Block added to Delta: %+v\n", block), verbose)
+		builder.writeBlock(blockHead, block)
+		logger(utils.Success(fmt.Sprintf("Matched Block added to Delta: %+v\n", block)), verbose)
 		// Update position for next missing block
 		blockHead = blockHead + block.Tail - block.Head + 1
 		// Create new missing block
 		block = models.Block{Head: 0, Tail: 0, IsModified: exists, Value: []byte{nextByte}}
+		builder.startLiteral(blockHead)
 	} else {
 		// Verify if updating initial missing block
 		if !initialBlockMatches {
@@ -218,29 +348,62 @@ func generateMissingBlock(delta models.Delta, block models.Block, exists bool, i
 }
 
 // GenerateSignature() will create a file Signature from a provided file reader.
-// Signature will contain a `weak` rolling hash of the file in 16 byte chunks.
+// Signature will contain a `weak` rolling hash of the file in chunks (16 bytes by default, see SetChunkSize()).
 // Signature will also contain a strong hash of each chunk to avoid collisions when generating Delta.
 // Function returns `Signature, nil` when successful.
+// Function returns `emptySignature, nil` when Original file is empty.
 // Function returns `emptySignature, error` when unsuccessful.
 func GenerateSignature(reader Reader, verbose bool) (models.Signature, error) {
-	head := 0
-	tail := int(chunk) - 1
+	return generateSignatureModel(reader, activeSettings(), verbose)
+}
+
+// generateSignatureModel() will create a file Signature in the same way as GenerateSignature(), with the provided chunk size + hash algorithms.
+// Function returns `Signature, nil` when successful.
+// Function returns `emptySignature, error` in the same cases as GenerateSignature().
+func generateSignatureModel(reader Reader, s settings, verbose bool) (models.Signature, error) {
 	signature := make(models.Signature, 0)
+	if err := generateSignature(reader, signature, s, verbose); err != nil {
+		return models.Signature{}, err
+	}
+
+	logger(fmt.Sprintf("Signature: %+v\n", signature), verbose)
+	return signature, nil
+}
+
+// generateSignature() will roll through the Original file, passing the Weak hash + block of every chunk to the provided writer.
+// Note: segments of the Original file will be hashed in parallel when using more than 1 Signature worker (see SetSignatureWorkers()).
+// Function returns `nil` when all blocks have been passed to the writer (no blocks passed when Original file is empty).
+// Function will return `error` when unable to read from file, or when the writer is unable to write a block.
+func generateSignature(reader Reader, writer SignatureEntryWriter, s settings, verbose bool) error {
+	if signatureWorkers > 1 {
+		return generateSegmentedSignature(reader, writer, s, signatureWorkers, verbose)
+	}
+
+	head := int64(0)
+	tail := s.chunk - 1
 	// Create buffer based on chunk size
-	buffer, err := initialiseBuffer(reader, chunk)
+	buffer, err := initialiseBuffer(reader, s.chunk)
 	if err != nil {
-		return models.Signature{}, err
+		// Empty Original file will produce an empty Signature
+		if err.Error() == constants.EndOfFileError {
+			return nil
+		}
+
+		return err
 	}
 
 	logger(fmt.Sprintf("Initial Buffer = %q", buffer[:]), verbose)
 	// Generate Weak hash of initial buffer
-	weakHash := generateWeakHash(buffer, chunk)
+	weakHash := s.weakHash.Sum(buffer, s.chunk)
 	logger(fmt.Sprintf("Weak hash = %d", weakHash), verbose)
 	// Generate Strong hash of buffer
-	strongHash := generateStrongHash(buffer, chunk)
+	strongHash := s.strongHash(buffer, s.chunk)
 	logger(fmt.Sprintf("Strong hash = %s\n", strongHash), verbose)
 	// Store values in Signature
-	signature[weakHash] = models.StrongSignature{Hash: strongHash, Head: head, Tail: tail}
+	if err = writer.WriteEntry(weakHash, models.StrongSignature{Hash: strongHash, LegacyHash: s.legacy(buffer), Head: head, Tail: tail}); err != nil {
+		return err
+	}
+
 	// Loop until EOF
 	for {
 		var initialByte byte
@@ -256,58 +419,41 @@ func GenerateSignature(reader Reader, verbose bool) (models.Signature, error) {
 			}
 
 			// Handle errors
-			return models.Signature{}, err
+			return err
 		}
 
-		logger(fmt.Sprintf("Rolled Buffer = %q", buffer[:]), verbose)
+		// Sample per-roll debug output, so verbose runs on large files stay usable
+		logRoll := sampleLog(verbose)
 		// Roll Weak hash
-		weakHash = rollWeakHash(weakHash, initialByte, nextByte, chunk)
-		logger(fmt.Sprintf("Rolled hash = %d", weakHash), verbose)
+		weakHash = s.weakHash.Roll(weakHash, initialByte, nextByte, s.chunk)
 		// Generate Strong hash of updated buffer
-		strongHash = generateStrongHash(buffer, chunk)
-		logger(fmt.Sprintf("Strong hash = %s\n", strongHash), verbose)
+		strongHash = s.strongHash(buffer, s.chunk)
+		if logRoll {
+			logger(fmt.Sprintf("Rolled Buffer = %q", buffer[:]), true)
+			logger(fmt.Sprintf("Rolled hash = %d", weakHash), true)
+			logger(fmt.Sprintf("Strong hash = %s\n", strongHash), true)
+		}
 		// Add hashes to Signature
-		signature[weakHash] = models.StrongSignature{Hash: strongHash, Head: head, Tail: tail}
+		if err = writer.WriteEntry(weakHash, models.StrongSignature{Hash: strongHash, LegacyHash: s.legacy(buffer), Head: head, Tail: tail}); err != nil {
+			return err
+		}
 	}
 
-	logger(fmt.Sprintf("Signature: %+v\n", signature), verbose)
-	return signature, nil
+	return nil
 }
 
-// generateStrongHash() will hash a provided buffer with SHA-256.
-// Function returns final `hash` value encoded as a hex string.
-func generateStrongHash(buffer []byte, chunkSize int64) string {
-	sha := sha256.New()
-	sha.Write(buffer)
-	return hex.EncodeToString(sha.Sum(nil))
-}
-
-// generateWeakHash() will generate a `weak` hash of a byte array based on the Rabin–Karp algorithm.
-// EG hash = ((array[0] * seed^n-1) + (array[1] * seed^n-2) + ... + (array[n] * seed^0)) % mod;
-// Hash is classed as `weak` as there is potential for collisions.
-// Function returns `hash`.
-func generateWeakHash(buffer []byte, chunkSize int64) int64 {
-	multiplier := chunkSize - 1
-	var hash int64 = 0
-	for index := range buffer {
-		// Generate hash value for buffer item -> (buffer[i] * (seed^multiplier))
-		value := int64(buffer[index]) * int64(math.Pow(float64(seed), float64(multiplier)))
-		// Add value to hash
-		hash = hash + value
-		// Reduce multiplier for next iteration
-		multiplier--
+// originalSize() will calculate the size of the Original file from the provided Signature.
+// EG the last byte of the final buffer added to Signature will be the last byte of the Original file.
+// Function returns `size`, or `0` when Signature is empty.
+func originalSize(signature models.Signature) int64 {
+	size := int64(0)
+	for _, item := range signature {
+		if item.Tail+1 > size {
+			size = item.Tail + 1
+		}
 	}
 
-	// Mod output for final hash
-	hash = modulo(hash, mod)
-	return hash
-}
-
-// modulo() will run a mod operation on 2 numbers and return the result.
-// math/big is used over the built-in mod operator as `%` does not implement Euclidean modulus.
-// Function returns `result` -> EG x % y;
-func modulo(x int64, y int64) int64 {
-	return new(big.Int).Mod(big.NewInt(x), big.NewInt(y)).Int64()
+	return size
 }
 
 // pop() will remove the first item from a provided buffer.
@@ -324,29 +470,38 @@ func pop(buffer []byte) ([]byte, byte) {
 }
 
 // populateBuffer() will create a new buffer and populate it, based on `chuck` size, from the provided file reader.
+// Buffer will be reduced to the number of bytes read when file is smaller than `chunk` size.
 // Function will return `buffer, nil` when successful.
 // Function will return `emptyBuffer, EOF` error when reader reaches end of file.
 // Function will return `emptyBuffer, error` when unable to read from file.
 func populateBuffer(reader Reader, chunkSize int64) ([]byte, error) {
 	// Create buffer based on chunk size
 	buffer := make([]byte, chunkSize)
-	// Fill buffer from file reader
-	n, err := reader.Read(buffer)
-	if err != nil {
-		// Handle EOF error
-		if err == io.EOF {
-			return []byte{}, errors.New(constants.EndOfFileError)
+	size := 0
+	// Fill buffer from file reader (EG reader may return fewer bytes than requested)
+	for size < len(buffer) {
+		n, err := reader.Read(buffer[size:])
+		size += n
+		if err != nil {
+			// Stop reading when EOF reached
+			if err == io.EOF {
+				break
+			}
+
+			return []byte{}, err
 		}
 
-		return []byte{}, err
+		if n == 0 {
+			break
+		}
 	}
 
-	if n == 0 {
+	if size == 0 {
 		// Handle EOF error
 		return []byte{}, errors.New(constants.EndOfFileError)
 	}
 
-	return buffer, nil
+	return buffer[:size], nil
 }
 
 // push() will append the provided byte to the end of the provided buffer.
@@ -355,6 +510,23 @@ func push(buffer []byte, item byte) []byte {
 	return append(buffer, item)
 }
 
+// peek() will read ahead the provided number of bytes without consuming them, when the reader supports peeking (EG bufio.Reader).
+// Function returns `buffer, true` when the bytes were read ahead (buffer will be a copy, so later reads do not overwrite it).
+// Function returns `nil, false` when the reader does not support peeking, or fewer bytes remain.
+func peek(reader Reader, size int64) ([]byte, bool) {
+	peeker, ok := reader.(peekReader)
+	if !ok {
+		return nil, false
+	}
+
+	next, err := peeker.Peek(int(size))
+	if err != nil || int64(len(next)) != size {
+		return nil, false
+	}
+
+	return append([]byte{}, next...), true
+}
+
 // roll() will move the rolling hash function to the next position.
 // This will include: read next item from file; popping 1st item from buffer; pushing new item to end of buffer;
 // Function will return `updatedBuffer, initialByte, nextByte, nil` when successful.
@@ -381,18 +553,47 @@ func roll(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
 	return buf, initialByte, nextByte, nil
 }
 
-// rollWeakHash() will roll a hash value to the next position based on initial byte of hash + new byte to roll in.
-// EG newHash = ((((hash - ((initialByte * seed^n-1) % mod)) * seed) % mod) + nextByte) % mod;
-// This function will return `updatedHash` once complete.
-func rollWeakHash(hash int64, initialByte byte, nextByte byte, chunkSize int64) int64 {
-	// Hash initialByte -> initialByte * seed^n-1
-	hashedInitialByte := int64(initialByte) * int64(math.Pow(float64(seed), float64(chunkSize-1)))
-	// Mod hashedInitialByte and remove from hash -> hash - (hashedInitialByte % mod)
-	updatedHash := hash - modulo(hashedInitialByte, mod)
-	// Multiply seed -> result * seed
-	updatedHash = updatedHash * seed
-	// Mod + add new byte -> result % mod + int64(nextByte)
-	updatedHash = modulo(updatedHash, mod) + int64(nextByte)
-	// Mod output to get final updated hash -> result % mod
-	return modulo(updatedHash, mod)
+// selectCandidate() will select the block (from a Signature item + its candidates) matching the provided Strong (or Legacy) hash.
+// When multiple blocks match, the block whose Tail is nearest to preferTail will be selected (ties keep the latest block).
+// Function returns `block, true` when a block matches.
+// Function returns `emptyBlock, false` when no blocks match.
+func selectCandidate(item models.StrongSignature, strongHash string, legacyHash string, preferTail int64) (models.StrongSignature, bool) {
+	var match models.StrongSignature
+	found := false
+	distance := int64(0)
+	for _, candidate := range append([]models.StrongSignature{item}, item.Candidates...) {
+		if !hashMatches(candidate, strongHash, legacyHash) {
+			continue
+		}
+
+		candidateDistance := candidate.Tail - preferTail
+		if candidateDistance < 0 {
+			candidateDistance = -candidateDistance
+		}
+
+		if !found || candidateDistance < distance {
+			match = models.StrongSignature{Hash: candidate.Hash, LegacyHash: candidate.LegacyHash, Head: candidate.Head, Tail: candidate.Tail}
+			found = true
+			distance = candidateDistance
+		}
+	}
+
+	return match, found
+}
+
+// verifyDeltaHasChanges() will check a generated Delta contains modifications for the Original file.
+// Delta contains no changes when it is a single matched block covering the full Original file (or both files are empty).
+// Function will return `delta, nil` when Delta contains changes.
+// Function will return `emptyDelta, UpdatedFileHasNoChangesError` when Updated file has no changes from Original.
+func verifyDeltaHasChanges(delta models.Delta, signature models.Signature) (models.Delta, error) {
+	size := originalSize(signature)
+	if len(delta) == 0 && size == 0 {
+		return models.Delta{}, errors.New(constants.UpdatedFileHasNoChangesError)
+	}
+
+	if block, exists := delta[0]; exists && len(delta) == 1 && !block.IsModified && block.Head == 0 && block.Tail == size-1 {
+		return models.Delta{}, errors.New(constants.UpdatedFileHasNoChangesError)
+	}
+
+	return delta, nil
 }
@@ -1,12 +1,17 @@
 package sync
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"testing"
 
 	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/hash/rolling"
 	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/curtismenmuir/go-file-diff/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,6 +24,7 @@ var (
 	testBufferNextChar    byte   = 'q'
 	testBufferUpdatedHash        = int64(49921073876)
 	testBufferStrongHash  string = "f39dac6cbaba535e2c207cd0cd8f154974223c848f727f98b3564cea569b41cf"
+	rabinKarp                    = rolling.NewRabinKarp()
 )
 
 // Mock for Reader interface
@@ -50,21 +56,140 @@ func (r readerMock) ReadByte() (byte, error) {
 	return testByte, nil
 }
 
+// Mock for Reader interface which returns data in small reads before returning EOF
+type shortReaderMock struct {
+	// Set test props
+	data    []byte
+	maxRead int
+}
+
+// Overwrite shortReaderMock.Read() to return at most maxRead bytes per read
+func (r *shortReaderMock) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	size := len(p)
+	if r.maxRead > 0 && size > r.maxRead {
+		size = r.maxRead
+	}
+
+	n := copy(p[:size], r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Overwrite shortReaderMock.ReadByte() to return EOF
+func (r *shortReaderMock) ReadByte() (byte, error) {
+	return 0, io.EOF
+}
+
+func TestAddSignatureItem(t *testing.T) {
+	t.Run("should add block to Signature when Weak hash does not exist", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{}
+		item := models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		// Run
+		addSignatureItem(signature, testBufferHash, item)
+		// Verify
+		require.Equal(t, models.Signature{testBufferHash: item}, signature)
+	})
+
+	t.Run("should keep existing block as candidate when Weak hash already exists", func(t *testing.T) {
+		// Setup
+		first := models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		second := models.StrongSignature{Hash: testBufferStrongHash, Head: 16, Tail: 31}
+		third := models.StrongSignature{Hash: "another-strong-hash", Head: 32, Tail: 47}
+		signature := models.Signature{}
+		// Run
+		addSignatureItem(signature, testBufferHash, first)
+		addSignatureItem(signature, testBufferHash, second)
+		addSignatureItem(signature, testBufferHash, third)
+		// Verify
+		require.Equal(t, models.StrongSignature{Hash: "another-strong-hash", Head: 32, Tail: 47, Candidates: []models.StrongSignature{first, second}}, signature[testBufferHash])
+	})
+
+	t.Run("should drop oldest candidates when exceeding `maxCandidates`", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{}
+		// Run
+		for index := 0; index <= maxCandidates+1; index++ {
+			addSignatureItem(signature, testBufferHash, models.StrongSignature{Hash: testBufferStrongHash, Head: int64(index), Tail: int64(index) + 15})
+		}
+
+		// Verify
+		item := signature[testBufferHash]
+		require.Equal(t, int64(maxCandidates+1), item.Head)
+		require.Equal(t, maxCandidates, len(item.Candidates))
+		require.Equal(t, int64(1), item.Candidates[0].Head)
+	})
+
+	t.Run("should drop candidates repeating the content of later blocks before blocks with different content", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{}
+		collision := models.StrongSignature{Hash: "another-strong-hash", Head: 0, Tail: 15}
+		// Run
+		addSignatureItem(signature, testBufferHash, collision)
+		for index := 1; index <= maxCandidates+1; index++ {
+			addSignatureItem(signature, testBufferHash, models.StrongSignature{Hash: testBufferStrongHash, Head: int64(index), Tail: int64(index) + 15})
+		}
+
+		// Verify
+		item := signature[testBufferHash]
+		require.Equal(t, maxCandidates, len(item.Candidates))
+		require.Equal(t, collision, item.Candidates[0])
+		require.Equal(t, int64(2), item.Candidates[1].Head)
+	})
+
+	t.Run("should keep + match every block with different content when more than `maxCandidates` share a Weak hash", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{}
+		addSignatureItem(signature, testBufferHash, models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15})
+		// Run
+		for index := 1; index <= maxCandidates*2; index++ {
+			addSignatureItem(signature, testBufferHash, models.StrongSignature{Hash: fmt.Sprintf("colliding-strong-hash-%d", index), Head: int64(index) * 16, Tail: int64(index)*16 + 15})
+		}
+
+		// Verify
+		require.Equal(t, maxCandidates*2, len(signature[testBufferHash].Candidates))
+		result, head, tail := compareChecksums(signature, testBuffer, testBufferHash, -1, activeSettings(), false)
+		require.Equal(t, true, result)
+		require.Equal(t, int64(0), head)
+		require.Equal(t, int64(15), tail)
+	})
+}
+
 func TestCompareChecksums(t *testing.T) {
 	t.Run("should return `true, item.Head, item.Tail` when weak and strong hashes match block in Signature", func(t *testing.T) {
 		// Setup
-		expectedHead := 0
-		expectedTail := int(testChunk) - 1
+		expectedHead := int64(0)
+		expectedTail := testChunk - 1
 		signature := models.Signature{}
 		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: expectedHead, Tail: expectedTail}
 		// Run
-		result, head, tail := compareChecksums(signature, testBuffer, testBufferHash, false)
+		result, head, tail := compareChecksums(signature, testBuffer, testBufferHash, 15, activeSettings(), false)
 		// Verify
 		require.Equal(t, true, result)
 		require.Equal(t, expectedHead, head)
 		require.Equal(t, expectedTail, tail)
 	})
 
+	t.Run("should return candidate nearest to preferred tail when multiple blocks match", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{}
+		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 64, Tail: 79, Candidates: []models.StrongSignature{
+			{Hash: testBufferStrongHash, Head: 0, Tail: 15},
+			{Hash: testBufferStrongHash, Head: 32, Tail: 47},
+		}}
+
+		// Run
+		result, head, tail := compareChecksums(signature, testBuffer, testBufferHash, 48, activeSettings(), false)
+		// Verify
+		require.Equal(t, true, result)
+		require.Equal(t, int64(32), head)
+		require.Equal(t, int64(47), tail)
+	})
+
 	t.Run("should return `false, -1, -1` when weak hash match block in Signature but not strong hash", func(t *testing.T) {
 		// Setup
 		buffer := make([]byte, 0)
@@ -73,11 +198,11 @@ func TestCompareChecksums(t *testing.T) {
 		signature := models.Signature{}
 		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
 		// Run
-		result, head, tail := compareChecksums(signature, buffer, testBufferHash, false)
+		result, head, tail := compareChecksums(signature, buffer, testBufferHash, 15, activeSettings(), false)
 		// Verify
 		require.Equal(t, false, result)
-		require.Equal(t, -1, head)
-		require.Equal(t, -1, tail)
+		require.Equal(t, int64(-1), head)
+		require.Equal(t, int64(-1), tail)
 	})
 
 	t.Run("should return `false, -1, -1` when weak hash does not match any blocks in Signature", func(t *testing.T) {
@@ -85,11 +210,11 @@ func TestCompareChecksums(t *testing.T) {
 		signature := models.Signature{}
 		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
 		// Run
-		result, head, tail := compareChecksums(signature, testBuffer, 123, false)
+		result, head, tail := compareChecksums(signature, testBuffer, 123, 15, activeSettings(), false)
 		// Verify
 		require.Equal(t, false, result)
-		require.Equal(t, -1, head)
-		require.Equal(t, -1, tail)
+		require.Equal(t, int64(-1), head)
+		require.Equal(t, int64(-1), tail)
 	})
 }
 
@@ -150,9 +275,9 @@ func TestGenerateDelta(t *testing.T) {
 		// Initialise Signature
 		signature := models.Signature{}
 		signatureBuffer := testBuffer
-		head := 0
-		tail := int(testChunk) - 1
-		signature[generateWeakHash(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
+		head := int64(0)
+		tail := testChunk - 1
+		signature[rabinKarp.Sum(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
 		for index := range modifiedBlock {
 			head++
 			tail++
@@ -160,7 +285,7 @@ func TestGenerateDelta(t *testing.T) {
 			buf = append(buf, signatureBuffer[1:]...)
 			buf = append(buf, modifiedBlock[index])
 			signatureBuffer = buf
-			signature[generateWeakHash(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
+			signature[rabinKarp.Sum(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
 		}
 
 		// Add new block to modified items for Updated file
@@ -257,9 +382,9 @@ func TestGenerateDelta(t *testing.T) {
 		// Initialise Signature
 		signature := models.Signature{}
 		signatureBuffer := testBuffer
-		head := 0
-		tail := int(testChunk) - 1
-		signature[generateWeakHash(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
+		head := int64(0)
+		tail := testChunk - 1
+		signature[rabinKarp.Sum(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
 		for index := range modifiedBlock {
 			head++
 			tail++
@@ -267,7 +392,7 @@ func TestGenerateDelta(t *testing.T) {
 			buf = append(buf, signatureBuffer[1:]...)
 			buf = append(buf, modifiedBlock[index])
 			signatureBuffer = buf
-			signature[generateWeakHash(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
+			signature[rabinKarp.Sum(signatureBuffer, testChunk)] = models.StrongSignature{Hash: generateStrongHash(signatureBuffer, testChunk), Head: head, Tail: tail}
 		}
 
 		// Remove first item from modified block to simulate deleted item in Updated file
@@ -316,10 +441,10 @@ func TestGenerateDelta(t *testing.T) {
 		var finalModifiedBlock byte = '5'
 		initialBuffer := []byte{initialModifiedBlock, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o'}
 		modifiedBlock := []byte{'p', '2', '3', '4', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'a', 'b', 'c', 'd', 'e', 'f', 'g', finalModifiedBlock}
-		initialMatchHead := 0
-		initialMatchTail := 15
-		secondMatchHead := 16
-		secondMatchTail := 31
+		initialMatchHead := int64(0)
+		initialMatchTail := int64(15)
+		secondMatchHead := int64(16)
+		secondMatchTail := int64(31)
 		// Initialise Signature
 		signature := models.Signature{}
 		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: initialMatchHead, Tail: initialMatchTail}
@@ -398,6 +523,84 @@ func TestGenerateDelta(t *testing.T) {
 		require.Equal(t, models.Delta{}, delta)
 	})
 
+	t.Run("should return `delta, nil` containing every byte when Updated file has no matches in Original file", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		rollCount := 0
+		initialBuffer := []byte{'1', '2', '3', '4', '5', '6', '7', '8', '9', '0', '!', '@', '#', '$', '%', '^'}
+		modifiedBlock := []byte{'&', '*'}
+		signature := models.Signature{}
+		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		expectedValue := append(append([]byte{}, initialBuffer...), modifiedBlock...)
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 17, IsModified: true, Value: expectedValue}}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return initialBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			// Return EOF to simulate reaching EOF
+			if rollCount == len(modifiedBlock) {
+				return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+			}
+
+			// Roll buffer
+			initialByte := buffer[0]
+			nextByte := modifiedBlock[rollCount]
+			buf := make([]byte, 0)
+			buf = append(buf, buffer[1:]...)
+			buf = append(buf, nextByte)
+			rollCount++
+			return buf, initialByte, nextByte, nil
+		}
+
+		// Run
+		delta, err := GenerateDelta(reader, signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `delta, nil` when Updated file is a truncated copy of Original file", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		signature := models.Signature{}
+		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		signature[16426995555] = models.StrongSignature{Hash: "2c9d26566889bcb66e96d74b97b14bc36cfd8c2949ab289fff2caeb0422e91b0", Head: 1, Tail: 16}
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return testBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		delta, err := GenerateDelta(reader, signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, delta)
+	})
+
+	t.Run("should return `emptyDelta, nil` when Updated file is empty", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: 0}
+		signature := models.Signature{}
+		signature[testBufferHash] = models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return []byte{}, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		delta, err := GenerateDelta(reader, signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Delta{}, delta)
+	})
+
 	t.Run("should return `emptyDelta, error` when unable to populate buffer from file", func(t *testing.T) {
 		// Setup
 		reader := readerMock{isReadError: false, readSize: int(testChunk)}
@@ -445,17 +648,17 @@ func TestGenerateMatchedBlock(t *testing.T) {
 		delta := models.Delta{}
 		exists := true
 		initialBlockMatches := true
-		blockHead := 0
-		deltaHead := 1
-		rollHead := 2
-		rollTail := 17
+		blockHead := int64(0)
+		deltaHead := int64(1)
+		rollHead := int64(1)
+		rollTail := int64(16)
 		rollExists := initialBlockMatches
-		block := models.Block{Head: blockHead, Tail: blockHead, IsModified: false, Value: []byte{}}
-		expectedBlock := models.Block{Head: blockHead, Tail: blockHead + 1, IsModified: false, Value: []byte{}}
+		block := models.Block{Head: blockHead, Tail: blockHead + 15, IsModified: false, Value: []byte{}}
+		expectedBlock := models.Block{Head: blockHead, Tail: blockHead + 16, IsModified: false, Value: []byte{}}
 		expectedInitialBlockMatches := true
-		expectedBlockHead := 0
+		expectedBlockHead := int64(0)
 		// Run
-		block, blockHead, initialBlockMatches = generateMatchedBlock(delta, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, false)
+		block, blockHead, initialBlockMatches = generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
 		// Verify
 		require.Equal(t, 0, len(delta))
 		require.Equal(t, expectedBlock, block)
@@ -463,23 +666,46 @@ func TestGenerateMatchedBlock(t *testing.T) {
 		require.Equal(t, expectedInitialBlockMatches, initialBlockMatches)
 	})
 
+	t.Run("should return `matchingBlock, blockHead, initialBlockMatches` after adding previous matched block to Delta when rolled buffer matches a non-contiguous position in Original file", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{}
+		exists := true
+		initialBlockMatches := true
+		blockHead := int64(0)
+		deltaHead := int64(1)
+		rollHead := int64(40)
+		rollTail := int64(55)
+		rollExists := true
+		block := models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}
+		expectedBlock := models.Block{Head: rollTail, Tail: rollTail, IsModified: false, Value: []byte{}}
+		expectedBlockHead := int64(16)
+		// Run
+		block, blockHead, initialBlockMatches = generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
+		// Verify
+		require.Equal(t, 1, len(delta))
+		require.Equal(t, models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}, delta[0])
+		require.Equal(t, expectedBlock, block)
+		require.Equal(t, expectedBlockHead, blockHead)
+		require.Equal(t, true, initialBlockMatches)
+	})
+
 	t.Run("should return `matchingBlock, blockHead, initialBlockMatches` after adding initial missing block to Delta (EG new block added to start of file)", func(t *testing.T) {
 		// Setup
 		delta := models.Delta{}
 		exists := false
 		initialBlockMatches := false
-		blockHead := 0
-		deltaHead := 1
-		rollHead := 2
-		rollTail := 17
+		blockHead := int64(0)
+		deltaHead := int64(1)
+		rollHead := int64(2)
+		rollTail := int64(17)
 		rollExists := true
 		value := []byte{'a'}
 		block := models.Block{Head: blockHead, Tail: blockHead, IsModified: true, Value: value}
 		expectedBlock := models.Block{Head: rollHead, Tail: rollTail, IsModified: false, Value: []byte{}}
 		expectedInitialBlockMatches := !initialBlockMatches
-		expectedBlockHead := 1
+		expectedBlockHead := int64(1)
 		// Run
-		block, blockHead, initialBlockMatches = generateMatchedBlock(delta, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, false)
+		block, blockHead, initialBlockMatches = generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
 		// Verify
 		require.Equal(t, 1, len(delta))
 		require.Equal(t, value, delta[0].Value)
@@ -493,11 +719,11 @@ func TestGenerateMatchedBlock(t *testing.T) {
 		delta := models.Delta{}
 		exists := false
 		initialBlockMatches := true
-		blockHead := 0
-		blockTail := 16
-		deltaHead := 1
-		rollHead := 2
-		rollTail := 17
+		blockHead := int64(0)
+		blockTail := int64(16)
+		deltaHead := int64(1)
+		rollHead := int64(2)
+		rollTail := int64(17)
 		rollExists := true
 		value := testBuffer
 		value = append(value, testBufferNextChar)
@@ -505,9 +731,9 @@ func TestGenerateMatchedBlock(t *testing.T) {
 		expectedValue := []byte{testBuffer[0], testBuffer[1]}
 		expectedBlock := models.Block{Head: rollHead, Tail: rollTail, IsModified: false, Value: []byte{}}
 		expectedInitialBlockMatches := initialBlockMatches
-		expectedBlockHead := 1
+		expectedBlockHead := int64(1)
 		// Run
-		block, blockHead, initialBlockMatches = generateMatchedBlock(delta, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, false)
+		block, blockHead, initialBlockMatches = generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
 		// Verify
 		require.Equal(t, 1, len(delta))
 		require.Equal(t, expectedValue, delta[0].Value)
@@ -515,6 +741,53 @@ func TestGenerateMatchedBlock(t *testing.T) {
 		require.Equal(t, expectedBlockHead, blockHead)
 		require.Equal(t, expectedInitialBlockMatches, initialBlockMatches)
 	})
+
+	t.Run("should return `matchingBlock, blockHead, initialBlockMatches` with positions beyond 4GB when rolled buffer matches a non-contiguous position in a large Original file", func(t *testing.T) {
+		// Setup
+		large := int64(5) << 30
+		delta := models.Delta{}
+		exists := true
+		initialBlockMatches := true
+		blockHead := large
+		deltaHead := large + 17
+		rollHead := 2 * large
+		rollTail := 2*large + 15
+		rollExists := true
+		block := models.Block{Head: large, Tail: large + 16, IsModified: false, Value: []byte{}}
+		expectedBlock := models.Block{Head: rollTail, Tail: rollTail, IsModified: false, Value: []byte{}}
+		expectedBlockHead := large + 17
+		// Run
+		newBlock, blockHead, initialBlockMatches := generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
+		// Verify
+		require.Equal(t, models.Delta{large: block}, delta)
+		require.Equal(t, expectedBlock, newBlock)
+		require.Equal(t, expectedBlockHead, blockHead)
+		require.Equal(t, true, initialBlockMatches)
+	})
+
+	t.Run("should return `matchingBlock, blockHead, initialBlockMatches` starting after the overlap when new match overlaps previous matched block (EG roll missed due to weak hash collision)", func(t *testing.T) {
+		// Setup
+		delta := models.Delta{}
+		exists := false
+		initialBlockMatches := true
+		blockHead := int64(16)
+		deltaHead := int64(3)
+		rollHead := int64(20)
+		rollTail := int64(35)
+		rollExists := true
+		// Missing block containing a single rolled byte (EG previous roll missed then next roll matched)
+		block := models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{testBufferNextChar}}
+		expectedOverlap := int64(14)
+		expectedBlock := models.Block{Head: rollHead + expectedOverlap, Tail: rollTail, IsModified: false, Value: []byte{}}
+		expectedBlockHead := deltaHead + expectedOverlap
+		// Run
+		block, blockHead, initialBlockMatches = generateMatchedBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, deltaHead, rollHead, rollTail, rollExists, chunk, false)
+		// Verify
+		require.Equal(t, 0, len(delta))
+		require.Equal(t, expectedBlock, block)
+		require.Equal(t, expectedBlockHead, blockHead)
+		require.Equal(t, true, initialBlockMatches)
+	})
 }
 
 func TestGenerateMissingBlock(t *testing.T) {
@@ -523,15 +796,15 @@ func TestGenerateMissingBlock(t *testing.T) {
 		delta := models.Delta{}
 		exists := true
 		initialBlockMatches := true
-		blockHead := 0
+		blockHead := int64(0)
 		nextByte := testBufferNextChar
 		buffer := testBuffer
 		expectedValue := []byte{}
 		block := models.Block{Head: blockHead, Tail: blockHead, IsModified: false, Value: expectedValue}
 		expectedBlock := models.Block{Head: blockHead, Tail: blockHead, IsModified: exists, Value: []byte{nextByte}}
-		expectedBlockHead := 1
+		expectedBlockHead := int64(1)
 		// Run
-		block, blockHead = generateMissingBlock(delta, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
+		block, blockHead = generateMissingBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
 		// Verify
 		require.Equal(t, 1, len(delta))
 		require.Equal(t, expectedValue, delta[0].Value)
@@ -544,14 +817,14 @@ func TestGenerateMissingBlock(t *testing.T) {
 		delta := models.Delta{}
 		exists := false
 		initialBlockMatches := false
-		blockHead := 0
+		blockHead := int64(0)
 		nextByte := testBufferNextChar
 		buffer := testBuffer
 		block := models.Block{Head: blockHead, Tail: blockHead, IsModified: true, Value: []byte{testBufferNextChar}}
 		expectedBlock := models.Block{Head: blockHead, Tail: blockHead + 1, IsModified: true, Value: []byte{testBufferNextChar, buffer[0]}}
-		expectedBlockHead := 0
+		expectedBlockHead := int64(0)
 		// Run
-		block, blockHead = generateMissingBlock(delta, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
+		block, blockHead = generateMissingBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
 		// Verify
 		require.Equal(t, 0, len(delta))
 		require.Equal(t, expectedBlock, block)
@@ -563,14 +836,14 @@ func TestGenerateMissingBlock(t *testing.T) {
 		delta := models.Delta{}
 		exists := false
 		initialBlockMatches := true
-		blockHead := 0
+		blockHead := int64(0)
 		buffer := testBuffer
 		nextByte := testBuffer[15]
 		block := models.Block{Head: blockHead, Tail: blockHead, IsModified: true, Value: []byte{testBufferNextChar}}
 		expectedBlock := models.Block{Head: blockHead, Tail: blockHead + 1, IsModified: true, Value: []byte{testBufferNextChar, nextByte}}
-		expectedBlockHead := 0
+		expectedBlockHead := int64(0)
 		// Run
-		block, blockHead = generateMissingBlock(delta, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
+		block, blockHead = generateMissingBlock(&deltaBuilder{writer: delta}, block, exists, initialBlockMatches, blockHead, nextByte, buffer, false)
 		// Verify
 		require.Equal(t, 0, len(delta))
 		require.Equal(t, expectedBlock, block)
@@ -578,6 +851,93 @@ func TestGenerateMissingBlock(t *testing.T) {
 	})
 }
 
+// byteReader type.
+// This will read from the wrapped reader without supporting peeking (EG the Reader interface only).
+type byteReader struct {
+	reader *bytes.Reader
+}
+
+func (r byteReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	return r.reader.ReadByte()
+}
+
+func TestSkipMatchedChunk(t *testing.T) {
+	// Setup
+	initialiseBuffer = populateBuffer
+	rollBuffer = roll
+	original := make([]byte, 256)
+	for index := range original {
+		original[index] = byte(index * 7 % 251)
+	}
+
+	updated := append([]byte{}, original...)
+	updated[100] = '!'
+	signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+	require.Equal(t, nil, err)
+
+	t.Run("should return the same Delta with fewer Strong hashes when skipping matched chunks", func(t *testing.T) {
+		// Setup
+		strongHashes := 0
+		// Mock
+		activeStrongHash = func(buffer []byte, chunkSize int64) string {
+			strongHashes++
+			return generateStrongHash(buffer, chunkSize)
+		}
+
+		// Run
+		rolled, err := GenerateDelta(byteReader{bytes.NewReader(updated)}, signature, false)
+		require.Equal(t, nil, err)
+		rolledHashes := strongHashes
+		strongHashes = 0
+		skipped, err := GenerateDelta(bufio.NewReader(bytes.NewReader(updated)), signature, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, rolled, skipped)
+		require.Less(t, strongHashes*4, rolledHashes)
+		// Restore, so later tests use the default Strong hash
+		activeStrongHash = generateStrongHash
+	})
+
+	t.Run("should return `nil, 0, false` without consuming the next chunk when it does not continue the matched block", func(t *testing.T) {
+		// Setup
+		reader := bufio.NewReader(bytes.NewReader(updated[85:]))
+		block := models.Block{Head: 0, Tail: 84, IsModified: false, Value: []byte{}}
+		// Run
+		buffer, weakHash, skipped := skipMatchedChunk(reader, signature, block, activeSettings())
+		// Verify
+		require.Equal(t, false, skipped)
+		require.Equal(t, []byte(nil), buffer)
+		require.Equal(t, int64(0), weakHash)
+		require.Equal(t, len(updated)-85, reader.Buffered())
+	})
+
+	t.Run("should return `buffer, weakHash, true` after consuming the next chunk when it continues the matched block", func(t *testing.T) {
+		// Setup
+		reader := bufio.NewReader(bytes.NewReader(updated[16:]))
+		block := models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}
+		// Run
+		buffer, weakHash, skipped := skipMatchedChunk(reader, signature, block, activeSettings())
+		// Verify
+		require.Equal(t, true, skipped)
+		require.Equal(t, original[16:32], buffer)
+		require.Equal(t, rabinKarp.Sum(original[16:32], testChunk), weakHash)
+		require.Equal(t, len(updated)-32, reader.Buffered())
+	})
+
+	t.Run("should return `nil, 0, false` when the reader does not support peeking", func(t *testing.T) {
+		// Setup
+		block := models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}
+		// Run
+		_, _, skipped := skipMatchedChunk(byteReader{bytes.NewReader(updated[16:])}, signature, block, activeSettings())
+		// Verify
+		require.Equal(t, false, skipped)
+	})
+}
+
 func TestGenerateSignature(t *testing.T) {
 	t.Run("should return `Signature, nil` when successfully processed all file data for Signature", func(t *testing.T) {
 		// Setup
@@ -610,6 +970,65 @@ func TestGenerateSignature(t *testing.T) {
 		require.Equal(t, expectedSignature, signature)
 	})
 
+	t.Run("should skip per-roll debug output when roll is not selected by log sampling", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		hasReadByte := false
+		updatedBuffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		messages := []string{}
+		// Mock
+		logger = func(message string, verbose bool) {
+			if verbose {
+				messages = append(messages, message)
+			}
+		}
+
+		sampleLog = func(verbose bool) bool {
+			return false
+		}
+
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return testBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			if !hasReadByte {
+				hasReadByte = true
+				return updatedBuffer, 1, 5, nil
+			}
+
+			return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		_, err := GenerateSignature(reader, true)
+		// Verify
+		require.Equal(t, nil, err)
+		for _, message := range messages {
+			require.NotContains(t, message, "Rolled")
+		}
+
+		require.Equal(t, 4, len(messages))
+		// Mock
+		logger = func(message string, verbose bool) {}
+		sampleLog = utils.SampleLog
+	})
+
+	t.Run("should return `emptySignature, nil` when Original file is empty", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: 0}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return []byte{}, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		signature, err := GenerateSignature(reader, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, models.Signature{}, signature)
+	})
+
 	t.Run("should return `emptySignature, error` when unable to populate buffer from file", func(t *testing.T) {
 		// Setup
 		expectedError := errors.New(errorMessage)
@@ -647,51 +1066,6 @@ func TestGenerateSignature(t *testing.T) {
 	})
 }
 
-func TestGenerateWeakHash(t *testing.T) {
-	t.Run("should return a consistent `resultHash` after hashing the provided buffer", func(t *testing.T) {
-		// Run
-		resultHash := generateWeakHash(testBuffer, testChunk)
-		// Verify
-		require.Equal(t, testBufferHash, resultHash)
-	})
-
-	t.Run("should generate a different `resultHash` for different buffers", func(t *testing.T) {
-		// Setup
-		buffer := []byte{'f', 'b', 'c', 'e', 'e', 'f', 'g', 4, 'i', 2, 'k', 'l', 'm', '£', 'o', 'p'}
-		// Run
-		resultHash := generateWeakHash(testBuffer, testChunk)
-		differentHash := generateWeakHash(buffer, testChunk)
-		// Verify
-		require.Equal(t, testBufferHash, resultHash)
-		require.NotEqual(t, differentHash, resultHash)
-	})
-
-	t.Run("should generate a different `resultHash` for hashes which have been reversed (EG byte order important)", func(t *testing.T) {
-		// Setup
-		buffer := []byte{'p', 'o', 'n', 'm', 'l', 'k', 'j', 'i', 'h', 'g', 'f', 'e', 'd', 'c', 'b', 'a'}
-		anotherBuffer := []byte{'b', 'a', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p'}
-		// Run
-		resultHash := generateWeakHash(testBuffer, testChunk)
-		differentHash := generateWeakHash(buffer, testChunk)
-		anotherHash := generateWeakHash(anotherBuffer, testChunk)
-		// Verify
-		require.Equal(t, testBufferHash, resultHash)
-		require.NotEqual(t, differentHash, resultHash)
-		require.NotEqual(t, differentHash, anotherHash)
-		require.NotEqual(t, anotherHash, resultHash)
-	})
-
-	t.Run("should generate a valid `resultHash` when using max byte size (255)", func(t *testing.T) {
-		// Setup
-		buffer := []byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}
-		expectedHash := int64(11415635451)
-		// Run
-		resultHash := generateWeakHash(buffer, testChunk)
-		// Verify
-		require.Equal(t, expectedHash, resultHash)
-	})
-}
-
 func TestGenerateStrongHash(t *testing.T) {
 	t.Run("should return SHA-256 `hash` of provided buffer as a Hex string", func(t *testing.T) {
 		// Run
@@ -701,29 +1075,23 @@ func TestGenerateStrongHash(t *testing.T) {
 	})
 }
 
-func TestModulo(t *testing.T) {
-	t.Run("should return `result` when calculated the remainder between 2 values (mod)", func(t *testing.T) {
+func TestOriginalSize(t *testing.T) {
+	t.Run("should return `size` based on the final Tail position in Signature", func(t *testing.T) {
 		// Setup
-		x := int64(10)
-		y := int64(4)
-		expectedResult := int64(2)
+		signature := models.Signature{}
+		signature[1] = models.StrongSignature{Hash: "some-hash", Head: 0, Tail: 15}
+		signature[2] = models.StrongSignature{Hash: "another-hash", Head: 4, Tail: 19}
 		// Run
-		result := modulo(x, y)
+		size := originalSize(signature)
 		// Verify
-		require.Equal(t, expectedResult, result)
+		require.Equal(t, int64(20), size)
 	})
 
-	t.Run("should implement Euclidean modulus, which differs from Go's mod operator", func(t *testing.T) {
-		// Setup
-		x := int64(-10)
-		y := int64(4)
-		expectedResult := int64(2)
-		goModResult := x % y
+	t.Run("should return `0` when Signature is empty", func(t *testing.T) {
 		// Run
-		result := modulo(x, y)
+		size := originalSize(models.Signature{})
 		// Verify
-		require.Equal(t, expectedResult, result)
-		require.NotEqual(t, goModResult, result)
+		require.Equal(t, int64(0), size)
 	})
 }
 
@@ -753,6 +1121,27 @@ func TestPopulateBuffer(t *testing.T) {
 		require.Equal(t, nil, err)
 	})
 
+	t.Run("should return reduced `buffer, nil` when file is smaller than chunk size", func(t *testing.T) {
+		// Setup
+		reader := shortReaderMock{data: []byte{'a', 'b', 'c'}}
+		expectedBuffer := []byte{'a', 'b', 'c'}
+		// Run
+		buffer, err := populateBuffer(&reader, testChunk)
+		// Verify
+		require.Equal(t, expectedBuffer, buffer)
+		require.Equal(t, nil, err)
+	})
+
+	t.Run("should return full `buffer, nil` when reader returns fewer bytes than requested", func(t *testing.T) {
+		// Setup
+		reader := shortReaderMock{data: testBuffer, maxRead: 5}
+		// Run
+		buffer, err := populateBuffer(&reader, testChunk)
+		// Verify
+		require.Equal(t, testBuffer, buffer)
+		require.Equal(t, nil, err)
+	})
+
 	t.Run("should return `emptyBuffer, EOF error` reader returns EOF error", func(t *testing.T) {
 		// Setup
 		expectedError := errors.New(constants.EndOfFileError)
@@ -850,24 +1239,81 @@ func TestRoll(t *testing.T) {
 	})
 }
 
-func TestRollWeakHash(t *testing.T) {
-	t.Run("should return a consistent `updatedHash` after rolling hash to next position", func(t *testing.T) {
+func TestSelectCandidate(t *testing.T) {
+	t.Run("should return candidate continuing previous copy run", func(t *testing.T) {
+		// Setup
+		continuing := models.StrongSignature{Hash: testBufferStrongHash, Head: 1, Tail: 16}
+		item := models.StrongSignature{Hash: testBufferStrongHash, Head: 100, Tail: 115, Candidates: []models.StrongSignature{continuing}}
 		// Run
-		result := rollWeakHash(testBufferHash, testBuffer[0], testBufferNextChar, testChunk)
+		match, found := selectCandidate(item, testBufferStrongHash, "", 16)
 		// Verify
-		require.NotEqual(t, testBufferHash, result)
-		require.Equal(t, testBufferUpdatedHash, result)
+		require.Equal(t, true, found)
+		require.Equal(t, continuing, match)
 	})
 
-	t.Run("should return an `updatedHash` which matches generating hash with full buffer (eg rolls to correct hash)", func(t *testing.T) {
+	t.Run("should skip candidates with different strong hash", func(t *testing.T) {
 		// Setup
-		buffer := []byte{'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', 'p', testBufferNextChar}
+		item := models.StrongSignature{Hash: testBufferStrongHash, Head: 100, Tail: 115, Candidates: []models.StrongSignature{{Hash: "another-strong-hash", Head: 1, Tail: 16}}}
 		// Run
-		result := rollWeakHash(testBufferHash, testBuffer[0], testBufferNextChar, testChunk)
-		expectedResult := generateWeakHash(buffer, testChunk)
+		match, found := selectCandidate(item, testBufferStrongHash, "", 16)
 		// Verify
-		require.NotEqual(t, testBufferHash, result)
-		require.Equal(t, testBufferUpdatedHash, result)
-		require.Equal(t, expectedResult, result)
+		require.Equal(t, true, found)
+		require.Equal(t, models.StrongSignature{Hash: testBufferStrongHash, Head: 100, Tail: 115}, match)
+	})
+
+	t.Run("should return `emptyBlock, false` when no blocks match strong hash", func(t *testing.T) {
+		// Setup
+		item := models.StrongSignature{Hash: "another-strong-hash", Head: 0, Tail: 15}
+		// Run
+		match, found := selectCandidate(item, testBufferStrongHash, "", 15)
+		// Verify
+		require.Equal(t, false, found)
+		require.Equal(t, models.StrongSignature{}, match)
+	})
+}
+
+func TestVerifyDeltaHasChanges(t *testing.T) {
+	t.Run("should return `delta, nil` when Delta contains modified blocks", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{testBufferHash: models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 0, IsModified: true, Value: []byte{'a'}}}
+		// Run
+		result, err := verifyDeltaHasChanges(delta, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `delta, nil` when Delta is a single matched block covering part of Original file", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{testBufferHash: models.StrongSignature{Hash: testBufferStrongHash, Head: 4, Tail: 19}}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}}
+		// Run
+		result, err := verifyDeltaHasChanges(delta, signature)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, delta, result)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when Delta is a single matched block covering Original file", func(t *testing.T) {
+		// Setup
+		signature := models.Signature{testBufferHash: models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}}
+		delta := models.Delta{0: models.Block{Head: 0, Tail: 15, IsModified: false, Value: []byte{}}}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Run
+		result, err := verifyDeltaHasChanges(delta, signature)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
+	})
+
+	t.Run("should return `emptyDelta, UpdatedFileHasNoChangesError` when Original + Updated files are both empty", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Run
+		result, err := verifyDeltaHasChanges(models.Delta{}, models.Signature{})
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, models.Delta{}, result)
 	})
 }
@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+)
+
+// DeltaWriter interface for receiving finalised Delta blocks as they are produced.
+// Implementations could encode blocks to a file, send them over a network, or collect them in memory.
+// `models.Delta` will satisfy the `DeltaWriter` interface.
+type DeltaWriter interface {
+	WriteBlock(position int64, block models.Block) error
+}
+
+// SignatureEntryWriter interface for receiving Signature entries (EG the Weak hash + block of every chunk of the Original file) as they are produced.
+// Entries will be written in the order of the Original file, without merging blocks which share a Weak hash (see models.Signature.WriteEntry()).
+// `models.Signature` will satisfy the `SignatureEntryWriter` interface.
+type SignatureEntryWriter interface {
+	WriteEntry(weakHash int64, item models.StrongSignature) error
+}
+
+// GenerateDeltaTo() will create a Delta changeset in the same way as GenerateDeltaWithHooks(), passing each finalised block to the provided DeltaWriter instead of returning a Delta.
+// This allows callers to stream a Delta without holding every block in memory.
+// Note: the writer will already have received all blocks when UpdatedFileHasNoChangesError is returned.
+// Function returns `nil` when successful.
+// Function will return `UpdatedFileHasNoChangesError` when no changes found in Updated file.
+// Function will return `error` when unable to read data from file to roll buffer.
+// Function will return `error` when the writer is unable to write a block.
+func GenerateDeltaTo(reader Reader, signature models.Signature, writer DeltaWriter, hooks Hooks, verbose bool) error {
+	builder := &deltaBuilder{writer: writer, hooks: hooks}
+	if err := generateDelta(reader, signature, builder, activeSettings(), verbose); err != nil {
+		return err
+	}
+
+	logger(fmt.Sprintf("Delta blocks written: %d\n", builder.blocks), verbose)
+	if !builder.hasChanges(originalSize(signature)) {
+		return errors.New(constants.UpdatedFileHasNoChangesError)
+	}
+
+	return nil
+}
+
+// GenerateSignatureTo() will create a file Signature in the same way as GenerateSignature(), passing each entry to the provided SignatureEntryWriter instead of returning a Signature.
+// This allows callers to stream a Signature (EG to a Signature file) without holding every entry in memory.
+// Function returns `nil` when successful (no entries written when Original file is empty).
+// Function will return `error` when unable to read from file.
+// Function will return `error` when the writer is unable to write an entry.
+func GenerateSignatureTo(reader Reader, writer SignatureEntryWriter, verbose bool) error {
+	return generateSignature(reader, writer, activeSettings(), verbose)
+}
@@ -0,0 +1,184 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/models"
+	"github.com/stretchr/testify/require"
+)
+
+// Mock for DeltaWriter interface
+type deltaWriterMock struct {
+	// Set test props
+	mockError error
+	positions []int64
+}
+
+// Overwrite deltaWriterMock.WriteBlock() to record positions + consider test prop
+func (w *deltaWriterMock) WriteBlock(position int64, block models.Block) error {
+	if w.mockError != nil {
+		return w.mockError
+	}
+
+	w.positions = append(w.positions, position)
+	return nil
+}
+
+// Mock for SignatureEntryWriter interface
+type signatureWriterMock struct {
+	// Set test props
+	mockError error
+	heads     []int64
+}
+
+// Overwrite signatureWriterMock.WriteEntry() to record heads + consider test prop
+func (w *signatureWriterMock) WriteEntry(weakHash int64, item models.StrongSignature) error {
+	if w.mockError != nil {
+		return w.mockError
+	}
+
+	w.heads = append(w.heads, item.Head)
+	return nil
+}
+
+func TestDeltaBuilderHasChanges(t *testing.T) {
+	t.Run("should return false when no blocks written for empty Original file", func(t *testing.T) {
+		// Setup
+		builder := &deltaBuilder{}
+		// Run + Verify
+		require.Equal(t, false, builder.hasChanges(0))
+	})
+
+	t.Run("should return true when no blocks written for non-empty Original file", func(t *testing.T) {
+		// Setup
+		builder := &deltaBuilder{}
+		// Run + Verify
+		require.Equal(t, true, builder.hasChanges(16))
+	})
+
+	t.Run("should return false when single matched block covers full Original file", func(t *testing.T) {
+		// Setup
+		builder := &deltaBuilder{blocks: 1, first: models.Block{Head: 0, Tail: 15}}
+		// Run + Verify
+		require.Equal(t, false, builder.hasChanges(16))
+	})
+
+	t.Run("should return true when single matched block covers part of Original file", func(t *testing.T) {
+		// Setup
+		builder := &deltaBuilder{blocks: 1, first: models.Block{Head: 0, Tail: 15}}
+		// Run + Verify
+		require.Equal(t, true, builder.hasChanges(32))
+	})
+}
+
+func TestGenerateDeltaTo(t *testing.T) {
+	t.Run("should pass finalised blocks to writer when Updated file contains changes", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		writer := models.Delta{}
+		expectedDelta := models.Delta{0: models.Block{Head: 0, Tail: 15, IsModified: true, Value: testBuffer}}
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return testBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		err := GenerateDeltaTo(reader, models.Signature{}, writer, Hooks{}, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, expectedDelta, writer)
+	})
+
+	t.Run("should return `UpdatedFileHasNoChangesError` when written blocks match Original file", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		writer := &deltaWriterMock{}
+		signature := models.Signature{testBufferHash: models.StrongSignature{Hash: testBufferStrongHash, Head: 0, Tail: 15}}
+		expectedError := errors.New(constants.UpdatedFileHasNoChangesError)
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return testBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		err := GenerateDeltaTo(reader, signature, writer, Hooks{}, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, []int64{0}, writer.positions)
+	})
+
+	t.Run("should return `error` when writer is unable to write block", func(t *testing.T) {
+		// Setup
+		reader := readerMock{isReadError: false, readSize: int(testChunk)}
+		expectedError := errors.New(errorMessage)
+		writer := &deltaWriterMock{mockError: expectedError}
+		flushed := false
+		hooks := Hooks{LiteralFlushed: func(event BlockEvent) {
+			flushed = true
+		}}
+
+		// Mock
+		initialiseBuffer = func(reader Reader, chunkSize int64) ([]byte, error) {
+			return testBuffer, nil
+		}
+
+		rollBuffer = func(reader Reader, buffer []byte) ([]byte, byte, byte, error) {
+			return []byte{}, 0, 0, errors.New(constants.EndOfFileError)
+		}
+
+		// Run
+		err := GenerateDeltaTo(reader, models.Signature{}, writer, hooks, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+		require.Equal(t, false, flushed)
+	})
+}
+
+func TestGenerateSignatureTo(t *testing.T) {
+	original := bytes.Repeat([]byte("some-repeated-data-"), 8)
+
+	t.Run("should pass every entry to writer in order, matching GenerateSignature() once collected", func(t *testing.T) {
+		// Setup
+		writer := &signatureWriterMock{}
+		collected := models.Signature{}
+		// Mock
+		initialiseBuffer = populateBuffer
+		rollBuffer = roll
+		// Run
+		err := GenerateSignatureTo(bufio.NewReader(bytes.NewReader(original)), writer, false)
+		collectedErr := GenerateSignatureTo(bufio.NewReader(bytes.NewReader(original)), collected, false)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, nil, collectedErr)
+		require.Equal(t, len(original)-int(chunk)+1, len(writer.heads))
+		for index, head := range writer.heads {
+			require.Equal(t, int64(index), head)
+		}
+
+		signature, err := GenerateSignature(bufio.NewReader(bytes.NewReader(original)), false)
+		require.Equal(t, nil, err)
+		require.Equal(t, signature, collected)
+	})
+
+	t.Run("should return `error` when writer is unable to write entry", func(t *testing.T) {
+		// Setup
+		expectedError := errors.New(errorMessage)
+		writer := &signatureWriterMock{mockError: expectedError}
+		// Run
+		err := GenerateSignatureTo(bufio.NewReader(bytes.NewReader(original)), writer, false)
+		// Verify
+		require.Equal(t, expectedError, err)
+	})
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	progressInterval  time.Duration = time.Second // Minimum time between progress reports
+	progressSmoothing float64       = 0.3         // Weight of the latest sample in the moving average rate
+	megabyte          float64       = 1024 * 1024
+)
+
+var clock = time.Now
+
+// Progress type.
+// This will track bytes processed for a phase (EG hashing a file, or patching a file) against the total expected bytes.
+// Progress will report percentage, a moving average rate, and estimated time remaining at a fixed interval.
+type Progress struct {
+	phase         string
+	total         int64
+	processed     int64
+	rate          float64 // Moving average rate (bytes/s)
+	lastReport    time.Time
+	lastProcessed int64
+	report        func(message string)
+}
+
+// progressReader type.
+// This will record bytes read from the wrapped reader against a Progress.
+type progressReader struct {
+	reader   io.Reader
+	progress *Progress
+}
+
+// progressWriter type.
+// This will record bytes written to the wrapped writer against a Progress.
+type progressWriter struct {
+	writer   io.Writer
+	progress *Progress
+}
+
+// NewProgress will init a Progress for the provided phase.
+// Total should be the expected number of bytes (EG file size obtained at open time), or 0 when unknown.
+// Report will be called with a formatted progress message once per `progressInterval`.
+func NewProgress(phase string, total int64, report func(message string)) *Progress {
+	return &Progress{phase: phase, total: total, lastReport: clock(), report: report}
+}
+
+// NewProgressReader will wrap a reader, recording all bytes read against the provided Progress.
+func NewProgressReader(reader io.Reader, progress *Progress) io.Reader {
+	return &progressReader{reader: reader, progress: progress}
+}
+
+// NewProgressWriter will wrap a writer, recording all bytes written against the provided Progress.
+func NewProgressWriter(writer io.Writer, progress *Progress) io.Writer {
+	return &progressWriter{writer: writer, progress: progress}
+}
+
+// Add() will record processed bytes, and will report progress when `progressInterval` has elapsed since the last report.
+func (p *Progress) Add(n int) {
+	p.processed += int64(n)
+	current := clock()
+	elapsed := current.Sub(p.lastReport)
+	if elapsed < progressInterval {
+		return
+	}
+
+	p.sample(elapsed, current)
+	p.report(p.String())
+}
+
+// ETA() will return the estimated time remaining based on the moving average rate.
+// Function will return `0` when total is unknown, or no rate has been recorded yet.
+func (p *Progress) ETA() time.Duration {
+	if p.total <= 0 || p.rate <= 0 || p.processed >= p.total {
+		return 0
+	}
+
+	return time.Duration(float64(p.total-p.processed) / p.rate * float64(time.Second))
+}
+
+// Finish() will report the final progress of the phase.
+// Note: function is a no-op on a `nil` Progress, so callers do not need to check if progress is enabled.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+
+	current := clock()
+	if elapsed := current.Sub(p.lastReport); elapsed > 0 {
+		p.sample(elapsed, current)
+	}
+
+	p.report(p.String())
+}
+
+// Rate() will return the moving average rate (bytes/s).
+func (p *Progress) Rate() float64 {
+	return p.rate
+}
+
+// sample() will update the moving average rate with the bytes processed since the last sample.
+func (p *Progress) sample(elapsed time.Duration, current time.Time) {
+	latest := float64(p.processed-p.lastProcessed) / elapsed.Seconds()
+	if p.rate == 0 {
+		p.rate = latest
+	} else {
+		p.rate = progressSmoothing*latest + (1-progressSmoothing)*p.rate
+	}
+
+	p.lastReport = current
+	p.lastProcessed = p.processed
+}
+
+// String() will format the Progress as a human readable string.
+// EG: `Signature: 45.2% (12.00 of 26.55 MB) at 3.21 MB/s, ETA 4s`.
+// Note: percentage + ETA will be excluded when total is unknown.
+func (p *Progress) String() string {
+	rate := p.rate / megabyte
+	if p.total <= 0 {
+		return fmt.Sprintf("%s: %.2f MB at %.2f MB/s", p.phase, float64(p.processed)/megabyte, rate)
+	}
+
+	percent := float64(p.processed) / float64(p.total) * 100
+	return fmt.Sprintf("%s: %.1f%% (%.2f of %.2f MB) at %.2f MB/s, ETA %s", p.phase, percent, float64(p.processed)/megabyte, float64(p.total)/megabyte, rate, p.ETA().Round(time.Second))
+}
+
+// Read() will read from the wrapped reader and record bytes read.
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.progress.Add(n)
+	return n, err
+}
+
+// Write() will write to the wrapped writer and record bytes written.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.progress.Add(n)
+	return n, err
+}
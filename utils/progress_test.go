@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockClock() will mock the clock to return the provided times in order.
+func mockClock(times ...time.Time) {
+	clock = func() time.Time {
+		current := times[0]
+		if len(times) > 1 {
+			times = times[1:]
+		}
+
+		return current
+	}
+}
+
+func TestProgressAdd(t *testing.T) {
+	t.Run("should report rate + ETA once interval has elapsed", func(t *testing.T) {
+		// Setup
+		start := time.Unix(0, 0)
+		reports := []string{}
+		mockClock(start, start.Add(500*time.Millisecond), start.Add(time.Second))
+		progress := NewProgress("Signature", int64(4*megabyte), func(message string) {
+			reports = append(reports, message)
+		})
+
+		// Run
+		progress.Add(int(megabyte) / 2)
+		progress.Add(int(megabyte) / 2)
+		// Verify
+		require.Equal(t, []string{"Signature: 25.0% (1.00 of 4.00 MB) at 1.00 MB/s, ETA 3s"}, reports)
+		require.Equal(t, megabyte, progress.Rate())
+		require.Equal(t, 3*time.Second, progress.ETA())
+	})
+
+	t.Run("should smooth rate using moving average", func(t *testing.T) {
+		// Setup
+		start := time.Unix(0, 0)
+		mockClock(start, start.Add(time.Second), start.Add(2*time.Second))
+		progress := NewProgress("Delta", 0, func(message string) {})
+		// Run
+		progress.Add(int(megabyte))
+		progress.Add(int(2 * megabyte))
+		// Verify
+		require.InDelta(t, 1.3*megabyte, progress.Rate(), 1)
+		require.Equal(t, time.Duration(0), progress.ETA())
+		require.Equal(t, "Delta: 3.00 MB at 1.30 MB/s", progress.String())
+	})
+}
+
+func TestProgressFinish(t *testing.T) {
+	t.Run("should report final progress", func(t *testing.T) {
+		// Setup
+		start := time.Unix(0, 0)
+		reports := []string{}
+		mockClock(start, start, start.Add(2*time.Second))
+		progress := NewProgress("Patch", int64(2*megabyte), func(message string) {
+			reports = append(reports, message)
+		})
+
+		progress.Add(int(2 * megabyte))
+		// Run
+		progress.Finish()
+		// Verify
+		require.Equal(t, []string{"Patch: 100.0% (2.00 of 2.00 MB) at 1.00 MB/s, ETA 0s"}, reports)
+	})
+}
+
+func TestProgressFinishNil(t *testing.T) {
+	t.Run("should not panic when Progress is nil", func(t *testing.T) {
+		// Setup
+		var progress *Progress
+		// Run + Verify
+		require.NotPanics(t, progress.Finish)
+	})
+}
+
+func TestProgressReader(t *testing.T) {
+	t.Run("should record bytes read against Progress", func(t *testing.T) {
+		// Setup
+		mockClock(time.Unix(0, 0))
+		progress := NewProgress("Signature", 5, func(message string) {})
+		reader := NewProgressReader(bytes.NewReader([]byte("hello")), progress)
+		// Run
+		data, err := io.ReadAll(reader)
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, []byte("hello"), data)
+		require.Equal(t, int64(5), progress.processed)
+	})
+}
+
+func TestProgressWriter(t *testing.T) {
+	t.Run("should record bytes written against Progress", func(t *testing.T) {
+		// Setup
+		mockClock(time.Unix(0, 0))
+		progress := NewProgress("Patch", 5, func(message string) {})
+		var out bytes.Buffer
+		writer := NewProgressWriter(&out, progress)
+		// Run
+		n, err := writer.Write([]byte("hello"))
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, 5, n)
+		require.Equal(t, "hello", out.String())
+		require.Equal(t, int64(5), progress.processed)
+	})
+}
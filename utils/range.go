@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+)
+
+// ParseRange will parse a byte range in the format `start:end` (EG `1024:4096`), where start is inclusive and end is exclusive.
+// Function returns `start, end, nil` when successful.
+// Function returns `0, 0, InvalidRangeError` when range is not in the format `start:end`, or does not satisfy 0 <= start < end.
+func ParseRange(value string) (int64, int64, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, errors.New(constants.InvalidRangeError)
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New(constants.InvalidRangeError)
+	}
+
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || start < 0 || end <= start {
+		return 0, 0, errors.New(constants.InvalidRangeError)
+	}
+
+	return start, end, nil
+}
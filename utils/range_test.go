@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	t.Run("should return `start, end, nil` when range is valid", func(t *testing.T) {
+		// Run
+		start, end, err := ParseRange("1024:4096")
+		// Verify
+		require.Equal(t, nil, err)
+		require.Equal(t, int64(1024), start)
+		require.Equal(t, int64(4096), end)
+	})
+
+	for _, value := range []string{"", "1024", "1024:", ":4096", "a:b", "1:2:3", "-1:10", "10:10", "10:5"} {
+		t.Run("should return `0, 0, InvalidRangeError` when range is "+value, func(t *testing.T) {
+			// Setup
+			expectedError := errors.New(constants.InvalidRangeError)
+			// Run
+			start, end, err := ParseRange(value)
+			// Verify
+			require.Equal(t, expectedError, err)
+			require.Equal(t, int64(0), start)
+			require.Equal(t, int64(0), end)
+		})
+	}
+}
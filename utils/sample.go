@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// logSampler type.
+// This will select which high-volume debug log events (EG each rolled buffer) are logged.
+// Every Nth event will be selected, capped at perSecond events per second (0 = no cap).
+type logSampler struct {
+	every       int
+	perSecond   int
+	count       int
+	windowStart time.Time
+	windowCount int
+}
+
+var (
+	sampler     = logSampler{every: 1}
+	samplerLock sync.Mutex // Prevents parallel callers (EG Batch workers) updating the sampler at the same time
+)
+
+// SampleLog will return `true` when a high-volume debug log event should be logged.
+// Function will return `false` when verbose flag is not set, quiet mode is enabled, or the event is skipped by log sampling.
+// Note: callers should check SampleLog() before formatting messages, so skipped events do not pay formatting costs.
+// Note: this is safe to call from multiple goroutines.
+func SampleLog(verbose bool) bool {
+	if !verbose || quiet {
+		return false
+	}
+
+	samplerLock.Lock()
+	defer samplerLock.Unlock()
+	// Log every Nth event
+	sampler.count++
+	if sampler.count < sampler.every {
+		return false
+	}
+
+	sampler.count = 0
+	if sampler.perSecond <= 0 {
+		return true
+	}
+
+	// Cap events logged per second
+	current := clock()
+	if current.Sub(sampler.windowStart) >= time.Second {
+		sampler.windowStart = current
+		sampler.windowCount = 0
+	}
+
+	if sampler.windowCount >= sampler.perSecond {
+		return false
+	}
+
+	sampler.windowCount++
+	return true
+}
+
+// SetLogSampling will configure sampling of high-volume debug log events.
+// Every Nth event will be logged (values below 1 will be treated as 1), capped at perSecond events per second (0 = no cap).
+func SetLogSampling(every int, perSecond int) {
+	if every < 1 {
+		every = 1
+	}
+
+	samplerLock.Lock()
+	sampler = logSampler{every: every, perSecond: perSecond}
+	samplerLock.Unlock()
+}
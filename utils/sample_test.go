@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countSampled() will return the number of events selected by SampleLog() out of the provided total.
+func countSampled(total int, verbose bool) int {
+	sampled := 0
+	for index := 0; index < total; index++ {
+		if SampleLog(verbose) {
+			sampled++
+		}
+	}
+
+	return sampled
+}
+
+func TestSampleLog(t *testing.T) {
+	t.Run("should select every event by default", func(t *testing.T) {
+		// Setup
+		SetLogSampling(1, 0)
+		// Run
+		sampled := countSampled(10, true)
+		// Verify
+		require.Equal(t, 10, sampled)
+	})
+
+	t.Run("should select every Nth event", func(t *testing.T) {
+		// Setup
+		SetLogSampling(4, 0)
+		// Run
+		sampled := countSampled(10, true)
+		// Verify
+		require.Equal(t, 2, sampled)
+	})
+
+	t.Run("should cap events selected per second", func(t *testing.T) {
+		// Setup
+		start := time.Unix(0, 0)
+		SetLogSampling(1, 3)
+		// Mock
+		mockClock(start, start, start, start, start.Add(time.Second), start.Add(time.Second))
+		// Run
+		sampled := countSampled(6, true)
+		// Verify
+		require.Equal(t, 5, sampled)
+	})
+
+	t.Run("should not select events when verbose flag not set", func(t *testing.T) {
+		// Setup
+		SetLogSampling(1, 0)
+		// Run
+		sampled := countSampled(10, false)
+		// Verify
+		require.Equal(t, 0, sampled)
+	})
+
+	t.Run("should not select events when quiet mode enabled", func(t *testing.T) {
+		// Setup
+		SetLogSampling(1, 0)
+		SetQuiet(true)
+		// Run
+		sampled := countSampled(10, true)
+		// Verify
+		require.Equal(t, 0, sampled)
+		SetQuiet(false)
+	})
+}
+
+func TestSetLogSampling(t *testing.T) {
+	t.Run("should treat values below 1 as logging every event", func(t *testing.T) {
+		// Run
+		SetLogSampling(0, 0)
+		// Verify
+		require.Equal(t, 1, sampler.every)
+		require.Equal(t, 10, countSampled(10, true))
+	})
+}
@@ -1,15 +1,136 @@
 package utils
 
-import "fmt"
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
 
-var log = fmt.Println
+	"golang.org/x/term"
+)
+
+// Color type.
+// This will contain the ANSI escape code used to color terminal output.
+type Color string
+
+const (
+	Green  Color = "\033[32m" // Matched / unchanged / passed
+	Red    Color = "\033[31m" // Errors / failed
+	Yellow Color = "\033[33m" // Warnings
+	Cyan   Color = "\033[36m" // Stats
+	reset  Color = "\033[0m"
+)
+
+var (
+	log         = fmt.Println
+	logError    = printError
+	quiet       = false
+	prompt      = fmt.Print
+	stdin       = io.Reader(os.Stdin)
+	isTerminal  = IsTerminal
+	stdoutColor = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "" // Color output of Logger()
+	stderrColor = isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == "" // Color output of ErrorLogger()
+	colorCodes  = strings.NewReplacer(string(Green), "", string(Red), "", string(Yellow), "", string(Cyan), "", string(reset), "")
+)
+
+// Colorize will wrap a message in the provided Color when color output is enabled for stdout or stderr.
+// Message will be returned unchanged when color output is disabled (EG `-noColor` flag, `NO_COLOR` env, or neither stdout nor stderr is a TTY).
+// Note: color will be removed again when the message is logged to a stream which is not a TTY (see Logger() + ErrorLogger()).
+func Colorize(message string, color Color) string {
+	if !stdoutColor && !stderrColor {
+		return message
+	}
+
+	return string(color) + message + string(reset)
+}
+
+// Confirm will ask the user a yes/no question and return `true` when the user answers `y` or `yes`.
+// Any other answer (including an empty answer) will return `false`, so actions default to `N`.
+// Note: when stdin is not a TTY (EG scripts, pipes + `</dev/null`) the user cannot be prompted, and function will return `true`.
+// Note: stdin closed before an answer (EOF) will also be treated as unable to prompt, and function will return `true`.
+func Confirm(message string) bool {
+	if !isTerminal(os.Stdin) {
+		return true
+	}
+
+	_, _ = prompt(streamColor(Warning(message+" [y/N]:")+" ", stdoutColor))
+	answer, err := bufio.NewReader(stdin).ReadString('\n')
+	if errors.Is(err, io.EOF) && answer == "" {
+		return true
+	} else if err != nil && answer == "" {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// ErrorLogger will print an error message to stderr.
+// Note: errors will always be logged, including when quiet mode is enabled.
+func ErrorLogger(message string) {
+	_, _ = logError(streamColor(message, stderrColor))
+}
+
+// Failure will color a message red (EG errors).
+func Failure(message string) string {
+	return Colorize(message, Red)
+}
+
+// IsTerminal will return `true` when the provided file is an interactive terminal (TTY).
+// Note: other character devices (EG `/dev/null`) are not terminals.
+func IsTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
 
 // Logger will print a string to console when verbose flag is set.
 // Verbose flag can be overwritten (true) to log to console.
+// Note: nothing will be logged when quiet mode is enabled.
 func Logger(message string, verbose bool) {
-	if !verbose {
+	if !verbose || quiet {
 		return
 	}
 
-	_, _ = log(message)
+	_, _ = log(streamColor(message, stdoutColor))
+}
+
+// printError will print to stderr, matching the signature of fmt.Println.
+func printError(a ...any) (int, error) {
+	return fmt.Fprintln(os.Stderr, a...)
+}
+
+// SetColor will enable or disable color output for both stdout + stderr.
+func SetColor(enabled bool) {
+	stdoutColor, stderrColor = enabled, enabled
+}
+
+// SetQuiet will enable or disable quiet mode.
+// Quiet mode will suppress all informational output, leaving only errors (see ErrorLogger).
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// streamColor() will return the message unchanged when color is enabled for the stream it is logged to, otherwise the message with color removed.
+func streamColor(message string, enabled bool) string {
+	if enabled {
+		return message
+	}
+
+	return colorCodes.Replace(message)
+}
+
+// Stat will color a message cyan (EG stats).
+func Stat(message string) string {
+	return Colorize(message, Cyan)
+}
+
+// Success will color a message green (EG matched, unchanged, or passed).
+func Success(message string) string {
+	return Colorize(message, Green)
+}
+
+// Warning will color a message yellow (EG warnings).
+func Warning(message string) string {
+	return Colorize(message, Yellow)
 }
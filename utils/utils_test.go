@@ -1,11 +1,216 @@
 package utils
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
+func TestColorize(t *testing.T) {
+	t.Run("should wrap message in color codes when color enabled", func(t *testing.T) {
+		// Setup
+		SetColor(true)
+		// Run
+		result := Colorize("Some Message", Green)
+		// Verify
+		require.Equal(t, "\033[32mSome Message\033[0m", result)
+	})
+
+	t.Run("should return message unchanged when color disabled", func(t *testing.T) {
+		// Setup
+		SetColor(false)
+		// Run
+		result := Colorize("Some Message", Green)
+		// Verify
+		require.Equal(t, "Some Message", result)
+	})
+}
+
+func TestConfirm(t *testing.T) {
+	t.Run("should return true when user answers yes", func(t *testing.T) {
+		// Setup
+		SetColor(false)
+		prompted := ""
+		// Mock
+		isTerminal = func(file *os.File) bool {
+			return true
+		}
+
+		prompt = func(a ...interface{}) (n int, err error) {
+			prompted = a[0].(string)
+			return 0, nil
+		}
+
+		stdin = strings.NewReader("Y\n")
+		// Run
+		result := Confirm("Overwrite file?")
+		// Verify
+		require.Equal(t, true, result)
+		require.Equal(t, "Overwrite file? [y/N]: ", prompted)
+	})
+
+	t.Run("should return false when user answers no", func(t *testing.T) {
+		// Mock
+		isTerminal = func(file *os.File) bool {
+			return true
+		}
+
+		stdin = strings.NewReader("no\n")
+		// Run
+		result := Confirm("Overwrite file?")
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when user provides empty answer", func(t *testing.T) {
+		// Mock
+		isTerminal = func(file *os.File) bool {
+			return true
+		}
+
+		stdin = strings.NewReader("\n")
+		// Run
+		result := Confirm("Overwrite file?")
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return true when stdin is closed before an answer (EG unable to prompt)", func(t *testing.T) {
+		// Mock
+		isTerminal = func(file *os.File) bool {
+			return true
+		}
+
+		stdin = strings.NewReader("")
+		// Run
+		result := Confirm("Overwrite file?")
+		// Verify
+		require.Equal(t, true, result)
+	})
+
+	t.Run("should return true without prompting when stdin is not a terminal", func(t *testing.T) {
+		// Setup
+		prompted := false
+		// Mock
+		isTerminal = func(file *os.File) bool {
+			return false
+		}
+
+		prompt = func(a ...interface{}) (n int, err error) {
+			prompted = true
+			return 0, nil
+		}
+
+		// Run
+		result := Confirm("Overwrite file?")
+		// Verify
+		require.Equal(t, true, result)
+		require.Equal(t, false, prompted)
+	})
+}
+
+func TestErrorLogger(t *testing.T) {
+	t.Run("should call error log function", func(t *testing.T) {
+		// Setup
+		logged := ""
+		// Mock
+		logError = func(a ...any) (n int, err error) {
+			logged = a[0].(string)
+			return 0, nil
+		}
+
+		// Run
+		ErrorLogger("Some Error")
+		// Verify
+		require.Equal(t, "Some Error", logged)
+	})
+
+	t.Run("should decide color per stream, removing color from stdout only when stdout is not a TTY (EG redirected)", func(t *testing.T) {
+		// Setup
+		stdoutColor, stderrColor = false, true
+		loggedError, logged := "", ""
+		// Mock
+		logError = func(a ...any) (n int, err error) {
+			loggedError = a[0].(string)
+			return 0, nil
+		}
+
+		log = func(a ...any) (n int, err error) {
+			logged = a[0].(string)
+			return 0, nil
+		}
+
+		// Run
+		ErrorLogger(Failure("Some Error"))
+		Logger(Success("Some Message"), true)
+		// Verify
+		require.Equal(t, "\033[31mSome Error\033[0m", loggedError)
+		require.Equal(t, "Some Message", logged)
+		// Run
+		stdoutColor, stderrColor = true, false
+		ErrorLogger(Failure("Some Error"))
+		Logger(Success("Some Message"), true)
+		// Verify
+		require.Equal(t, "Some Error", loggedError)
+		require.Equal(t, "\033[32mSome Message\033[0m", logged)
+		SetColor(false)
+	})
+
+	t.Run("should call error log function when quiet mode enabled", func(t *testing.T) {
+		// Setup
+		invoked := false
+		SetQuiet(true)
+		// Mock
+		logError = func(a ...any) (n int, err error) {
+			invoked = true
+			return 0, nil
+		}
+
+		// Run
+		ErrorLogger("Some Error")
+		// Verify
+		require.Equal(t, true, invoked)
+		SetQuiet(false)
+	})
+}
+
+func TestFailure(t *testing.T) {
+	t.Run("should color message red", func(t *testing.T) {
+		// Setup
+		SetColor(true)
+		// Run
+		result := Failure("Some Message")
+		// Verify
+		require.Equal(t, string(Red)+"Some Message"+string(reset), result)
+	})
+}
+
+func TestIsTerminal(t *testing.T) {
+	t.Run("should return false when file is not a terminal", func(t *testing.T) {
+		// Setup
+		file, err := os.CreateTemp(t.TempDir(), "file")
+		require.Equal(t, nil, err)
+		defer file.Close()
+		// Run
+		result := IsTerminal(file)
+		// Verify
+		require.Equal(t, false, result)
+	})
+
+	t.Run("should return false when file is a character device which is not a terminal (EG `/dev/null`)", func(t *testing.T) {
+		// Setup
+		file, err := os.Open(os.DevNull)
+		require.Equal(t, nil, err)
+		defer file.Close()
+		// Run
+		result := IsTerminal(file)
+		// Verify
+		require.Equal(t, false, result)
+	})
+}
+
 func TestLogger(t *testing.T) {
 	t.Run("should call log function when verbose flag set to true", func(t *testing.T) {
 		// Setup
@@ -34,4 +239,53 @@ func TestLogger(t *testing.T) {
 		// Verify
 		require.Equal(t, false, invoked)
 	})
+
+	t.Run("should not call log function when quiet mode enabled", func(t *testing.T) {
+		// Setup
+		invoked := false
+		SetQuiet(true)
+		// Mock
+		log = func(a ...interface{}) (n int, err error) {
+			invoked = true
+			return 0, nil
+		}
+		// Run
+		Logger("Some Message", true)
+		// Verify
+		require.Equal(t, false, invoked)
+		SetQuiet(false)
+	})
+}
+
+func TestStat(t *testing.T) {
+	t.Run("should color message cyan", func(t *testing.T) {
+		// Setup
+		SetColor(true)
+		// Run
+		result := Stat("Some Message")
+		// Verify
+		require.Equal(t, string(Cyan)+"Some Message"+string(reset), result)
+	})
+}
+
+func TestSuccess(t *testing.T) {
+	t.Run("should color message green", func(t *testing.T) {
+		// Setup
+		SetColor(true)
+		// Run
+		result := Success("Some Message")
+		// Verify
+		require.Equal(t, string(Green)+"Some Message"+string(reset), result)
+	})
+}
+
+func TestWarning(t *testing.T) {
+	t.Run("should color message yellow", func(t *testing.T) {
+		// Setup
+		SetColor(true)
+		// Run
+		result := Warning("Some Message")
+		// Verify
+		require.Equal(t, string(Yellow)+"Some Message"+string(reset), result)
+	})
 }
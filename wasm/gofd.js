@@ -0,0 +1,34 @@
+// gofd.js - browser wrapper for the go-file-diff WebAssembly build.
+// Requires Go's `wasm_exec.js` (copy from `$(go env GOROOT)/misc/wasm/` or `lib/wasm/`) to be loaded first.
+//
+// EG:
+//   const gofd = await loadGoFileDiff("gofd.wasm");
+//   const signature = gofd.signature(originalBytes);
+//   const delta = gofd.delta(signature, updatedBytes); // `null` when Updated file has no changes
+//   upload(delta);
+
+async function loadGoFileDiff(url) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  // Runs until the page is closed, exported functions are registered on `globalThis.gofd`
+  go.run(instance);
+
+  // Convert engine results into return values + exceptions
+  const unwrap = (result) => {
+    if (result.noChanges) {
+      return null;
+    }
+
+    if (result.error) {
+      throw new Error(result.error);
+    }
+
+    return result.data;
+  };
+
+  return {
+    signature: (original) => unwrap(globalThis.gofd.signature(original)),
+    delta: (signature, updated) => unwrap(globalThis.gofd.delta(signature, updated)),
+    patch: (original, delta) => unwrap(globalThis.gofd.patch(original, delta)),
+  };
+}
@@ -0,0 +1,70 @@
+//go:build js && wasm
+
+// Package main exposes the Signature, Delta, and Patch functions to browsers via WebAssembly.
+// Build: `GOOS=js GOARCH=wasm go build -o gofd.wasm ./wasm`, then load with `gofd.js` (see README).
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/curtismenmuir/go-file-diff/constants"
+	"github.com/curtismenmuir/go-file-diff/engine"
+	"github.com/curtismenmuir/go-file-diff/utils"
+)
+
+// goBytes() will copy a JS Uint8Array into a byte slice.
+func goBytes(value js.Value) []byte {
+	data := make([]byte, value.Get("length").Int())
+	js.CopyBytesToGo(data, value)
+	return data
+}
+
+// result() will convert the output of an engine function into a JS object.
+// EG: `{data: Uint8Array}` when successful, `{error: "...", noChanges: true}` when Updated file has no changes.
+func result(data []byte, err error) any {
+	if err != nil {
+		return map[string]any{
+			"error":     err.Error(),
+			"noChanges": err.Error() == constants.UpdatedFileHasNoChangesError,
+		}
+	}
+
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	return map[string]any{"data": array}
+}
+
+// wrap() will create a JS function which passes its Uint8Array arguments to the provided engine function.
+func wrap(args int, run func(inputs [][]byte) ([]byte, error)) js.Func {
+	return js.FuncOf(func(this js.Value, values []js.Value) any {
+		if len(values) != args {
+			return map[string]any{"error": constants.InvalidArgumentsError}
+		}
+
+		inputs := make([][]byte, len(values))
+		for index := range values {
+			inputs[index] = goBytes(values[index])
+		}
+
+		return result(run(inputs))
+	})
+}
+
+func main() {
+	// Browser console is owned by the embedding page, so suppress all logging
+	utils.SetQuiet(true)
+	js.Global().Set("gofd", map[string]any{
+		"signature": wrap(1, func(inputs [][]byte) ([]byte, error) {
+			return engine.Signature(inputs[0])
+		}),
+		"delta": wrap(2, func(inputs [][]byte) ([]byte, error) {
+			return engine.Delta(inputs[0], inputs[1])
+		}),
+		"patch": wrap(2, func(inputs [][]byte) ([]byte, error) {
+			return engine.Patch(inputs[0], inputs[1])
+		}),
+	})
+
+	// Keep running so exported functions remain callable
+	select {}
+}